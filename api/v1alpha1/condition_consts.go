@@ -1,4 +1,4 @@
-// Copyright (c) 2021 VMware, Inc. All Rights Reserved.
+// Copyright (c) 2021-2023 VMware, Inc. All Rights Reserved.
 // SPDX-License-Identifier: Apache-2.0
 
 package v1alpha1
@@ -72,6 +72,63 @@ const (
 	VirtualMachineToolsRunningReason = "VirtualMachineToolsRunning"
 )
 
+const (
+	// VirtualMachineTopologySpreadCondition documents whether the zone the VirtualMachine is placed in satisfies
+	// the VirtualMachine's topology spread constraints.
+	VirtualMachineTopologySpreadCondition ConditionType = "VirtualMachineTopologySpread"
+
+	// TopologySpreadConstraintsNotSatisfiedReason (Severity=Error) documents that the VirtualMachine cannot be placed
+	// since no zone satisfies its DoNotSchedule topology spread constraints.
+	TopologySpreadConstraintsNotSatisfiedReason = "TopologySpreadConstraintsNotSatisfied"
+
+	// TopologySpreadConstraintsSkewedReason (Severity=Warning) documents that the VirtualMachine was placed in a zone
+	// that exceeds the maximum skew of its ScheduleAnyway topology spread constraints.
+	TopologySpreadConstraintsSkewedReason = "TopologySpreadConstraintsSkewed"
+)
+
+// Conditions related to the placement of the VirtualMachine's PCI devices.
+const (
+	// VirtualMachinePCIDevicePlacementCondition documents whether the VirtualMachine is placed on a host that has
+	// its vGPU and Dynamic DirectPath I/O devices available.
+	VirtualMachinePCIDevicePlacementCondition ConditionType = "VirtualMachinePCIDevicePlacement"
+
+	// NoHostWithVGPUProfileReason (Severity=Error) documents that the VirtualMachine cannot be placed since no host
+	// supports the profile of one of its vGPU devices.
+	NoHostWithVGPUProfileReason = "NoHostWithVGPUProfile"
+
+	// NoHostWithPCIDeviceReason (Severity=Error) documents that the VirtualMachine cannot be placed since no host
+	// has enough free passthrough devices for one of its Dynamic DirectPath I/O devices.
+	NoHostWithPCIDeviceReason = "NoHostWithPCIDevice"
+
+	// NoHostWithAllPCIDevicesReason (Severity=Error) documents that the VirtualMachine cannot be placed since no
+	// host has all of its vGPU and Dynamic DirectPath I/O devices available.
+	NoHostWithAllPCIDevicesReason = "NoHostWithAllPCIDevices"
+)
+
+// Conditions related to changes to the VirtualMachineClass of the VirtualMachine.
+const (
+	// VirtualMachineClassUpToDateCondition documents whether the VirtualMachine's hardware is configured from the
+	// current generation of its VirtualMachineClass.
+	VirtualMachineClassUpToDateCondition ConditionType = "VirtualMachineClassUpToDate"
+
+	// VirtualMachineClassUpdateNeverReason (Severity=Info) documents that the changes to the VirtualMachineClass are
+	// not applied to the VirtualMachine since the class's update strategy is Never.
+	VirtualMachineClassUpdateNeverReason = "ClassUpdateNever"
+
+	// VirtualMachineClassUpdatePendingPowerCycleReason (Severity=Info) documents that the changes to the
+	// VirtualMachineClass are applied the next time the VirtualMachine is powered on.
+	VirtualMachineClassUpdatePendingPowerCycleReason = "ClassUpdatePendingPowerCycle"
+
+	// VirtualMachineClassUpdatePendingRestartReason (Severity=Info) documents that the VirtualMachine waits to be
+	// restarted to apply the changes to the VirtualMachineClass since the maximum number of VirtualMachines of the
+	// class in the namespace are already being restarted.
+	VirtualMachineClassUpdatePendingRestartReason = "ClassUpdatePendingRestart"
+
+	// VirtualMachineClassUpdateRestartingReason (Severity=Info) documents that the VirtualMachine is restarted to
+	// apply the changes to the VirtualMachineClass.
+	VirtualMachineClassUpdateRestartingReason = "ClassUpdateRestarting"
+)
+
 // Common Condition.Reason used by VM Operator API objects.
 const (
 	// DeletingReason (Severity=Info) documents a condition not in Status=True because the underlying object it is currently being deleted.
@@ -107,6 +164,11 @@ const (
 
 	// VirtualMachineImageProviderSecurityComplianceCondition denotes security compliance of the library item provider.
 	VirtualMachineImageProviderSecurityComplianceCondition ConditionType = "VirtualMachineImageProviderSecurityCompliance"
+
+	// VirtualMachineImageTrustVerifiedCondition denotes that the checksums of the files of the library item and
+	// the detached signature of its OVF manifest were verified against the image trust policy. The condition is
+	// only set when an image trust policy is configured.
+	VirtualMachineImageTrustVerifiedCondition ConditionType = "VirtualMachineImageTrustVerified"
 )
 
 // Condition.Reason for Conditions related to VirtualMachineImages.
@@ -131,4 +193,57 @@ const (
 	// VirtualMachineImageProviderSecurityNotCompliantReason (Severity=Error) documents that the
 	// VirtualMachineImage provider doesn't meet security compliance requirements.
 	VirtualMachineImageProviderSecurityNotCompliantReason = "VirtualMachineImageProviderSecurityNotCompliant"
+
+	// VirtualMachineImageNotTrustedReason (Severity=Error) documents that the library item of the
+	// VirtualMachineImage failed the verification of the image trust policy, for example because it has no
+	// OVF manifest, a file does not match its checksum in the manifest, or the manifest signature is invalid.
+	VirtualMachineImageNotTrustedReason = "VirtualMachineImageNotTrusted"
+
+	// VirtualMachineImageTrustNotVerifiedReason (Severity=Warning) documents that the library item of the
+	// VirtualMachineImage could not be verified yet, for example because the image trust policy could not be
+	// read or the library item files could not be downloaded. The verification will be retried.
+	VirtualMachineImageTrustNotVerifiedReason = "VirtualMachineImageTrustNotVerified"
+)
+
+// Conditions and condition Reasons for the VirtualMachineSetResourcePolicy object. These conditions are set
+// for each availability zone in the VirtualMachineSetResourcePolicy's Status.Zones.
+
+const (
+	// ResourcePolicyFolderReadyCondition documents that the Folder of the VirtualMachineSetResourcePolicy exists.
+	ResourcePolicyFolderReadyCondition ConditionType = "ResourcePolicyFolderReady"
+
+	// ResourcePolicyFolderFailedReason (Severity=Error) documents that the Folder could not be created.
+	ResourcePolicyFolderFailedReason = "ResourcePolicyFolderFailed"
+)
+
+const (
+	// ResourcePolicyResourcePoolReadyCondition documents that the child ResourcePool of the
+	// VirtualMachineSetResourcePolicy exists and has the reservations and limits of its ResourcePoolSpec.
+	ResourcePolicyResourcePoolReadyCondition ConditionType = "ResourcePolicyResourcePoolReady"
+
+	// ResourcePolicyResourcePoolFailedReason (Severity=Error) documents that the child ResourcePool could not be
+	// created or its reservations and limits could not be updated.
+	ResourcePolicyResourcePoolFailedReason = "ResourcePolicyResourcePoolFailed"
+)
+
+const (
+	// ResourcePolicyClusterModulesReadyCondition documents that the ClusterModules and DRS VM-VM rules of the
+	// ClusterModule Groups of the VirtualMachineSetResourcePolicy exist.
+	ResourcePolicyClusterModulesReadyCondition ConditionType = "ResourcePolicyClusterModulesReady"
+
+	// ResourcePolicyClusterModulesFailedReason (Severity=Error) documents that the ClusterModules or DRS VM-VM
+	// rules could not be created or updated.
+	ResourcePolicyClusterModulesFailedReason = "ResourcePolicyClusterModulesFailed"
+)
+
+// Conditions and condition Reasons of the VM provider. These conditions are not set on an object, and are
+// reported by the controller manager's healthz endpoint.
+
+const (
+	// VCenterReachableCondition documents that the vCenters of the VM provider are reachable.
+	VCenterReachableCondition ConditionType = "VCenterReachable"
+
+	// VCenterUnreachableReason (Severity=Error) documents that the calls to a vCenter fail fast because it could
+	// not be reached, or did not respond in time, on consecutive calls.
+	VCenterUnreachableReason = "VCenterUnreachable"
 )
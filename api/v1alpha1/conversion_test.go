@@ -72,6 +72,9 @@ func TestFuzzyConversion(t *testing.T) {
 		Scheme: scheme,
 		Hub:    &nextver.VirtualMachineSetResourcePolicy{},
 		Spoke:  &v1alpha1.VirtualMachineSetResourcePolicy{},
+		FuzzerFuncs: []fuzzer.FuzzerFuncs{
+			overrideVirtualMachineSetResourcePolicyFieldsFuncs,
+		},
 	}))
 }
 
@@ -215,6 +218,34 @@ func overrideVirtualMachinePublishRequestFieldsFuncs(codecs runtimeserializer.Co
 	}
 }
 
+func overrideVirtualMachineSetResourcePolicyFieldsFuncs(codecs runtimeserializer.CodecFactory) []interface{} {
+	return []interface{}{
+		func(rpSpec *v1alpha1.VirtualMachineSetResourcePolicySpec, c fuzz.Continue) {
+			c.FuzzNoCustom(rpSpec)
+
+			// TODO: Need corresponding fields in v1a2.
+			for i := range rpSpec.ClusterModules {
+				rpSpec.ClusterModules[i].Type = ""
+				rpSpec.ClusterModules[i].Enforcement = ""
+			}
+		},
+		func(rpStatus *v1alpha1.VirtualMachineSetResourcePolicyStatus, c fuzz.Continue) {
+			c.FuzzNoCustom(rpStatus)
+			overrideConditionsSeverity(rpStatus.Conditions)
+			for i := range rpStatus.Zones {
+				overrideConditionsSeverity(rpStatus.Zones[i].Conditions)
+			}
+		},
+		func(rpStatus *nextver.VirtualMachineSetResourcePolicyStatus, c fuzz.Continue) {
+			c.FuzzNoCustom(rpStatus)
+			overrideConditionsObservedGeneration(rpStatus.Conditions)
+			for i := range rpStatus.Zones {
+				overrideConditionsObservedGeneration(rpStatus.Zones[i].Conditions)
+			}
+		},
+	}
+}
+
 func overrideConditionsSeverity(conditions []v1alpha1.Condition) {
 	// metav1.Conditions do not have this field, so on down conversions it will always be empty.
 	for i := range conditions {
@@ -0,0 +1,69 @@
+// Copyright (c) 2023 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// SerialConsoleRequestSpec describes the specification used to request access to the serial console of a VM.
+type SerialConsoleRequestSpec struct {
+	// VirtualMachineName is the VM in the same namespace, for which the serial console is requested.
+	VirtualMachineName string `json:"virtualMachineName"`
+	// PublicKey is used to encrypt the status.response. This is expected to be a RSA OAEP public key in X.509 PEM format.
+	PublicKey string `json:"publicKey"`
+}
+
+// SerialConsoleRequestStatus defines the observed state, which includes the token used to connect to the serial
+// console stream.
+type SerialConsoleRequestStatus struct {
+	// Response is the token, encrypted with spec.publicKey, that authenticates the connection to the serial console
+	// stream of the VM.
+	Response string `json:"response,omitempty"`
+	// ExpiryTime is when the token referenced in Response will expire.
+	ExpiryTime metav1.Time `json:"expiryTime,omitempty"`
+	// ProxyAddr describes the host address and optional port used to access the VM's serial console stream.
+	// The value may be set to any value that is valid for WebConsoleRequestStatus.ProxyAddr, except that an IPv6
+	// address is enclosed in brackets, e.g. "[fd00::1]", so it may be used as the host of a URL.
+	ProxyAddr string `json:"proxyAddr,omitempty"`
+	// TokenHash is the hex encoded SHA-256 hash of the token referenced in Response.
+	TokenHash string `json:"tokenHash,omitempty"`
+	// SerialPortURI is the service URI of the VM's serial port that connects to the virtual serial port concentrator
+	// of the web-console-validator. It identifies the VM's serial console stream to the concentrator.
+	SerialPortURI string `json:"serialPortURI,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:resource:scope=Namespaced
+// +kubebuilder:storageversion
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="VirtualMachine",type="string",JSONPath=".spec.virtualMachineName"
+// +kubebuilder:printcolumn:name="Expiry",type="string",JSONPath=".status.expiryTime"
+
+// SerialConsoleRequest allows the creation of a time-limited token that can be used to connect to the serial
+// console of the VM.
+type SerialConsoleRequest struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   SerialConsoleRequestSpec   `json:"spec,omitempty"`
+	Status SerialConsoleRequestStatus `json:"status,omitempty"`
+}
+
+func (s *SerialConsoleRequest) NamespacedName() string {
+	return s.Namespace + "/" + s.Name
+}
+
+// +kubebuilder:object:root=true
+
+// SerialConsoleRequestList contains a list of SerialConsoleRequests.
+type SerialConsoleRequestList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []SerialConsoleRequest `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&SerialConsoleRequest{}, &SerialConsoleRequestList{})
+}
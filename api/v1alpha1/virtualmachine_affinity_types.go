@@ -0,0 +1,83 @@
+// Copyright (c) 2023 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// VirtualMachineAffinityZoneTopologyKey is the topology key of an affinity
+	// term that is evaluated over the zones of VMs.
+	VirtualMachineAffinityZoneTopologyKey = "topology.kubernetes.io/zone"
+
+	// VirtualMachineAffinityHostTopologyKey is the topology key of an affinity
+	// term that is evaluated over the ESXi hosts of VMs.
+	VirtualMachineAffinityHostTopologyKey = "kubernetes.io/hostname"
+)
+
+// VirtualMachineAffinitySpec describes the affinity and anti-affinity of a VM
+// to other VMs in the same namespace.
+type VirtualMachineAffinitySpec struct {
+	// VMAffinity describes the rules to place the VM in the same zone or on
+	// the same host as other VMs.
+	// +optional
+	VMAffinity *VirtualMachineAffinityRules `json:"vmAffinity,omitempty"`
+
+	// VMAntiAffinity describes the rules to place the VM in a different zone
+	// or on a different host than other VMs.
+	// +optional
+	VMAntiAffinity *VirtualMachineAffinityRules `json:"vmAntiAffinity,omitempty"`
+}
+
+// VirtualMachineAffinityRules describes the required and preferred affinity
+// or anti-affinity terms of a VM.
+type VirtualMachineAffinityRules struct {
+	// RequiredDuringSchedulingIgnoredDuringExecution are the terms that must
+	// be satisfied when the VM is placed. The VM is not created if they cannot
+	// be satisfied, and they are not enforced after the VM is placed.
+	// +optional
+	RequiredDuringSchedulingIgnoredDuringExecution []VirtualMachineAffinityTerm `json:"requiredDuringSchedulingIgnoredDuringExecution,omitempty"`
+
+	// RequiredDuringSchedulingRequiredDuringExecution are the terms that must
+	// be satisfied when the VM is placed, and that continue to be enforced by
+	// vSphere DRS after the VM is placed, such as when DRS migrates VMs. Only
+	// the host topology key is supported since the zone of a VM does not
+	// change after it is placed.
+	// +optional
+	RequiredDuringSchedulingRequiredDuringExecution []VirtualMachineAffinityTerm `json:"requiredDuringSchedulingRequiredDuringExecution,omitempty"`
+
+	// PreferredDuringSchedulingIgnoredDuringExecution are the terms that are
+	// preferred when the VM is placed. The placement that satisfies the
+	// highest sum of the weights of the terms is chosen.
+	// +optional
+	PreferredDuringSchedulingIgnoredDuringExecution []VirtualMachineWeightedAffinityTerm `json:"preferredDuringSchedulingIgnoredDuringExecution,omitempty"`
+}
+
+// VirtualMachineAffinityTerm selects a set of VMs in the same namespace, and
+// the topology domain, a zone or host, of the VMs to evaluate the term over.
+type VirtualMachineAffinityTerm struct {
+	// LabelSelector selects the VMs. A nil or empty selector does not select
+	// any VMs.
+	// +optional
+	LabelSelector *metav1.LabelSelector `json:"labelSelector,omitempty"`
+
+	// TopologyKey is the topology domain of the term: the VM is co-located
+	// with, or separated from, the selected VMs by zone when the key is
+	// topology.kubernetes.io/zone, or by host when the key is
+	// kubernetes.io/hostname.
+	// +kubebuilder:validation:Enum=topology.kubernetes.io/zone;kubernetes.io/hostname
+	TopologyKey string `json:"topologyKey"`
+}
+
+// VirtualMachineWeightedAffinityTerm is an affinity term with a weight.
+type VirtualMachineWeightedAffinityTerm struct {
+	// Weight is the weight of the term, in the range 1-100.
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=100
+	Weight int32 `json:"weight"`
+
+	// AffinityTerm is the affinity term.
+	AffinityTerm VirtualMachineAffinityTerm `json:"affinityTerm"`
+}
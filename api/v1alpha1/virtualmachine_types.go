@@ -1,4 +1,4 @@
-// Copyright (c) 2020 VMware, Inc. All Rights Reserved.
+// Copyright (c) 2020-2023 VMware, Inc. All Rights Reserved.
 // SPDX-License-Identifier: Apache-2.0
 
 package v1alpha1
@@ -61,6 +61,34 @@ const (
 	// this annotation to skip adding a default nic. VM Operator won't add default NIC to any existing VMs or new VMs
 	// with VirtualMachineNetworkInterfaces specified. This annotation is not required for such VMs.
 	NoDefaultNicAnnotation = GroupName + "/no-default-nic"
+
+	// ClassUpdateRestartAnnotation is an annotation that VM Operator applies to a VirtualMachine that is restarted to
+	// apply changes to its VirtualMachineClass under the RollingRestart update strategy. The guest OS of the
+	// VirtualMachine is shut down first, and the value of the annotation is the time the shutdown was requested.
+	// The VirtualMachine is powered off if the guest OS does not shut down in time. The annotation is removed once
+	// the VirtualMachine is powered on again with the class's hardware.
+	ClassUpdateRestartAnnotation = GroupName + "/class-update-restart"
+
+	// PriorityClassAnnotation is an annotation that can be applied to a VirtualMachine to set the priority class of
+	// its create, reconfigure, power and publish operations when they are queued behind the operations of other
+	// VirtualMachines. The value is one of High, Normal or Low, and defaults to Normal. Only privileged accounts
+	// may set or change this annotation.
+	//
+	// This can be used so control plane VMs are created before worker VMs.
+	PriorityClassAnnotation = GroupName + "/priority-class"
+
+	// SchedulingSharesAnnotation is an annotation that can be applied to a Namespace to set the shares of the
+	// queued operations of its VirtualMachines. A namespace with twice the shares of another namespace runs twice as
+	// many concurrent operations when both have queued operations. The value is a positive integer, and defaults
+	// to 1.
+	SchedulingSharesAnnotation = GroupName + "/scheduling-shares"
+)
+
+// The priority classes of the PriorityClassAnnotation.
+const (
+	PriorityClassHigh   = "High"
+	PriorityClassNormal = "Normal"
+	PriorityClassLow    = "Low"
 )
 
 // VirtualMachinePort is unused and can be considered deprecated.
@@ -313,6 +341,10 @@ type VirtualMachineSpec struct {
 	// ImageName describes the name of a VirtualMachineImage that is to be used as the base Operating System image of
 	// the desired VirtualMachine instances.  The VirtualMachineImage resources can be introspected to discover identifying
 	// attributes that may help users to identify the desired image to use.
+	//
+	// When a VirtualMachine is created, ImageName may instead be a selector in the form "<channel>@latest", where
+	// <channel> is the value of an image's channel label (e.g. "ubuntu-22.04") or product label (e.g. "ubuntu").
+	// The selector is resolved to, and replaced by, the name of the latest non-deprecated image that matches it.
 	ImageName string `json:"imageName"`
 
 	// ClassName describes the name of a VirtualMachineClass that is to be used as the overlaid resource configuration
@@ -362,6 +394,16 @@ type VirtualMachineSpec struct {
 
 	// AdvancedOptions describes a set of optional, advanced options for configuring a VirtualMachine
 	AdvancedOptions *VirtualMachineAdvancedOptions `json:"advancedOptions,omitempty"`
+
+	// Affinity describes the affinity and anti-affinity of the VirtualMachine to other VirtualMachines in the
+	// same namespace, by zone or by host, that is considered when the VirtualMachine is placed.
+	// +optional
+	Affinity *VirtualMachineAffinitySpec `json:"affinity,omitempty"`
+
+	// TopologySpreadConstraints describes how the VirtualMachine and the other VirtualMachines in the same namespace
+	// that are selected by each constraint are spread across zones when the VirtualMachine is placed.
+	// +optional
+	TopologySpreadConstraints []VirtualMachineTopologySpreadConstraint `json:"topologySpreadConstraints,omitempty"`
 }
 
 // VirtualMachineAdvancedOptions describes a set of optional, advanced options for configuring a VirtualMachine.
@@ -418,6 +460,67 @@ type NetworkInterfaceStatus struct {
 	IpAddresses []string `json:"ipAddresses,omitempty"` //nolint:revive,stylecheck
 }
 
+// VirtualMachineTaskState describes the state of a vSphere task.
+type VirtualMachineTaskState string
+
+const (
+	// VirtualMachineTaskStateQueued indicates the task is waiting to be run by vSphere.
+	VirtualMachineTaskStateQueued VirtualMachineTaskState = "Queued"
+
+	// VirtualMachineTaskStateRunning indicates the task is being run by vSphere.
+	VirtualMachineTaskStateRunning VirtualMachineTaskState = "Running"
+
+	// VirtualMachineTaskStateSuccess indicates the task completed successfully.
+	VirtualMachineTaskStateSuccess VirtualMachineTaskState = "Success"
+
+	// VirtualMachineTaskStateError indicates the task failed.
+	VirtualMachineTaskStateError VirtualMachineTaskState = "Error"
+)
+
+// VirtualMachineTaskFault describes the fault a vSphere task failed with.
+type VirtualMachineTaskFault struct {
+	// Type is the type of the fault, such as InvalidPowerState or InsufficientResourcesFault.
+	Type string `json:"type"`
+
+	// Message is the localized message of the fault.
+	// +optional
+	Message string `json:"message,omitempty"`
+}
+
+// VirtualMachineTaskInfo describes a vSphere task that was started on the VirtualMachine.
+type VirtualMachineTaskInfo struct {
+	// TaskRef is the managed object ID of the task, such as task-123. For the deploy of a content library item,
+	// which is not a vSphere task, it is the ID of the library item.
+	TaskRef string `json:"taskRef"`
+
+	// DescriptionID identifies the operation of the task, such as VirtualMachine.powerOn.
+	// +optional
+	DescriptionID string `json:"descriptionID,omitempty"`
+
+	// Description describes the current step of the task, when it is reported by vSphere.
+	// +optional
+	Description string `json:"description,omitempty"`
+
+	// State describes the state of the task.
+	State VirtualMachineTaskState `json:"state"`
+
+	// Progress is the percentage of the task that is complete, when it is reported by vSphere.
+	// +optional
+	Progress int32 `json:"progress,omitempty"`
+
+	// StartTime is the time the task was started by vSphere.
+	// +optional
+	StartTime *metav1.Time `json:"startTime,omitempty"`
+
+	// CompleteTime is the time the task completed.
+	// +optional
+	CompleteTime *metav1.Time `json:"completeTime,omitempty"`
+
+	// Fault describes the fault the task failed with.
+	// +optional
+	Fault *VirtualMachineTaskFault `json:"fault,omitempty"`
+}
+
 // VirtualMachineStatus defines the observed state of a VirtualMachine instance.
 type VirtualMachineStatus struct {
 	// Host describes the hostname or IP address of the infrastructure host that the VirtualMachine is executing on.
@@ -474,6 +577,16 @@ type VirtualMachineStatus struct {
 	// Please note this field may be empty when the cluster is not zone-aware.
 	// +optional
 	Zone string `json:"zone,omitempty"`
+
+	// ClassGeneration is the generation of the VirtualMachineClass that the VirtualMachine's hardware was last
+	// configured from.
+	// +optional
+	ClassGeneration int64 `json:"classGeneration,omitempty"`
+
+	// LastTask describes the last vSphere task that was started on the VirtualMachine. A task that is still running
+	// is checked by the following reconciles of the VirtualMachine, instead of being waited for.
+	// +optional
+	LastTask *VirtualMachineTaskInfo `json:"lastTask,omitempty"`
 }
 
 func (vm *VirtualMachine) GetConditions() Conditions {
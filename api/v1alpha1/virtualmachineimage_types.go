@@ -8,6 +8,32 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
+const (
+	// VirtualMachineImageProductLabel is the label of a VirtualMachineImage whose value is derived from the
+	// image's Spec.ProductInfo.Product.
+	VirtualMachineImageProductLabel = GroupName + "/image-product"
+
+	// VirtualMachineImageVersionLabel is the label of a VirtualMachineImage whose value is derived from the
+	// image's Spec.ProductInfo.Version.
+	VirtualMachineImageVersionLabel = GroupName + "/image-version"
+
+	// VirtualMachineImageChannelLabel is the label of a VirtualMachineImage whose value is derived from the
+	// image's product and version, e.g. "ubuntu-22.04". Successive builds of an image share the same channel.
+	VirtualMachineImageChannelLabel = GroupName + "/image-channel"
+
+	// VirtualMachineImageDeprecatedLabel is the label that marks a VirtualMachineImage as deprecated when its
+	// value is "true". Deprecated images are never selected by the VirtualMachineImageLatestSelectorSuffix,
+	// and a warning is returned when a VirtualMachine that references a deprecated image is created or updated.
+	//
+	// With the image registry, the label is propagated to the image from its ContentLibraryItem or
+	// ClusterContentLibraryItem.
+	VirtualMachineImageDeprecatedLabel = GroupName + "/image-deprecated"
+
+	// VirtualMachineImageLatestSelectorSuffix is the suffix of a VirtualMachine's Spec.ImageName that selects
+	// the latest non-deprecated image of a channel or product, e.g. "ubuntu-22.04@latest".
+	VirtualMachineImageLatestSelectorSuffix = "@latest"
+)
+
 // VirtualMachineImageProductInfo describes optional product-related information that can be added to an image
 // template.  This information can be used by the image author to communicate details of the product contained in the
 // image.
@@ -142,6 +168,11 @@ type VirtualMachineImageStatus struct {
 	// eg: bios, efi.
 	// +optional
 	Firmware string `json:"firmware,omitempty"`
+
+	// TrustPolicyHash is the hash of the public keys of the image trust policy that this VirtualMachineImage
+	// was last verified against.
+	// +optional
+	TrustPolicyHash string `json:"trustPolicyHash,omitempty"`
 }
 
 func (vmImage *VirtualMachineImage) GetConditions() Conditions {
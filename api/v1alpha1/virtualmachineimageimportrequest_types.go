@@ -0,0 +1,286 @@
+// Copyright (c) 2023 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// VirtualMachineImageImportRequestConditionTargetValid is the Type for a
+	// VirtualMachineImageImportRequest resource's status condition.
+	//
+	// The condition's status is set to true only when the information
+	// that describes the target side of the import has been validated.
+	VirtualMachineImageImportRequestConditionTargetValid = "TargetValid"
+
+	// VirtualMachineImageImportRequestConditionDownloaded is the Type for a
+	// VirtualMachineImageImportRequest resource's status condition.
+	//
+	// The condition's status is set to true only when all the files of the
+	// imported image have been downloaded into the target location.
+	VirtualMachineImageImportRequestConditionDownloaded = "Downloaded"
+
+	// VirtualMachineImageImportRequestConditionValidated is the Type for a
+	// VirtualMachineImageImportRequest resource's status condition.
+	//
+	// The condition's status is set to true only when the downloaded files
+	// match the expected checksum, if any, and the target location has
+	// accepted them as a valid image.
+	VirtualMachineImageImportRequestConditionValidated = "Validated"
+
+	// VirtualMachineImageImportRequestConditionImageAvailable is the Type for
+	// a VirtualMachineImageImportRequest resource's status condition.
+	//
+	// The condition's status is set to true only when a new
+	// VirtualMachineImage resource has been realized from the imported item.
+	VirtualMachineImageImportRequestConditionImageAvailable = "ImageAvailable"
+
+	// VirtualMachineImageImportRequestConditionComplete is the Type for a
+	// VirtualMachineImageImportRequest resource's status condition.
+	//
+	// The condition's status is set to true only when all other conditions
+	// present on the resource have a truthy status.
+	VirtualMachineImageImportRequestConditionComplete = "Complete"
+)
+
+// Condition.Reason for Conditions related to VirtualMachineImageImportRequest.
+const (
+	// DownloadingReason documents that the files of the imported image are
+	// being downloaded into the target location.
+	DownloadingReason = "Downloading"
+
+	// DownloadFailureReason documents that downloading the files of the
+	// imported image into the target location failed.
+	DownloadFailureReason = "DownloadFailure"
+
+	// ValidatingReason documents that the downloaded files of the imported
+	// image are being validated by the target location.
+	ValidatingReason = "Validating"
+
+	// ChecksumMismatchReason documents that the downloaded file does not
+	// match the checksum in spec.source.checksum.
+	ChecksumMismatchReason = "ChecksumMismatch"
+
+	// ValidationFailureReason documents that the target location did not
+	// accept the downloaded files as a valid image.
+	ValidationFailureReason = "ValidationFailure"
+
+	// HasNotBeenDownloadedReason documents that the
+	// VirtualMachineImageImportRequest hasn't completed because the files of
+	// the imported image haven't been downloaded yet.
+	HasNotBeenDownloadedReason = "HasNotBeenDownloaded"
+)
+
+// VirtualMachineImageImportRequestChecksum is the expected checksum of an
+// imported file.
+type VirtualMachineImageImportRequestChecksum struct {
+	// Algorithm is the algorithm used to calculate the checksum.
+	//
+	// +kubebuilder:validation:Enum=SHA1;SHA256;SHA512;MD5
+	// +kubebuilder:default=SHA256
+	// +optional
+	Algorithm string `json:"algorithm,omitempty"`
+
+	// Value is the hex-encoded checksum of the file.
+	Value string `json:"value"`
+}
+
+// VirtualMachineImageImportRequestSource is the source of an import request.
+type VirtualMachineImageImportRequestSource struct {
+	// URL is the HTTP or HTTPS URL of the OVA or OVF file to import.
+	//
+	// When the URL refers to an OVF descriptor, the files referenced by the
+	// descriptor are downloaded from locations relative to the URL.
+	URL string `json:"url"`
+
+	// Checksum is the expected checksum of the file at spec.source.url. The
+	// import fails if the downloaded file does not match it.
+	//
+	// If omitted then the downloaded file is not verified.
+	//
+	// +optional
+	Checksum *VirtualMachineImageImportRequestChecksum `json:"checksum,omitempty"`
+}
+
+// VirtualMachineImageImportRequestTargetItem is the item part of an import
+// request's target.
+type VirtualMachineImageImportRequestTargetItem struct {
+	// Name is the name of the imported item. This is the name that will show
+	// up in vCenter Content Library, not the custom resource name in the
+	// namespace.
+	//
+	// If omitted then the controller will use the name of the
+	// VirtualMachineImageImportRequest resource.
+	//
+	// +optional
+	Name string `json:"name,omitempty"`
+
+	// Description is the description to assign to the imported item.
+	//
+	// +optional
+	Description string `json:"description,omitempty"`
+}
+
+// VirtualMachineImageImportRequestTargetLocation is the location part of an
+// import request's target.
+type VirtualMachineImageImportRequestTargetLocation struct {
+	// Name is the name of the referenced object.
+	Name string `json:"name"`
+
+	// APIVersion is the API version of the referenced object.
+	//
+	// +kubebuilder:default=imageregistry.vmware.com/v1alpha1
+	// +optional
+	APIVersion string `json:"apiVersion,omitempty"`
+
+	// Kind is the kind of referenced object.
+	//
+	// +kubebuilder:default=ContentLibrary
+	// +optional
+	Kind string `json:"kind,omitempty"`
+}
+
+// VirtualMachineImageImportRequestTarget is the target of an import request,
+// typically a ContentLibrary resource.
+type VirtualMachineImageImportRequestTarget struct {
+	// Item contains information about the item into which the image is
+	// imported.
+	//
+	// +optional
+	Item VirtualMachineImageImportRequestTargetItem `json:"item,omitempty"`
+
+	// Location contains information about the location into which the image
+	// is imported.
+	Location VirtualMachineImageImportRequestTargetLocation `json:"location"`
+}
+
+// VirtualMachineImageImportRequestSpec defines the desired state of a
+// VirtualMachineImageImportRequest.
+type VirtualMachineImageImportRequestSpec struct {
+	// Source is the source of the import request.
+	Source VirtualMachineImageImportRequestSource `json:"source"`
+
+	// Target is the target of the import request, ex. item information and
+	// a ContentLibrary resource.
+	Target VirtualMachineImageImportRequestTarget `json:"target"`
+
+	// TTLSecondsAfterFinished is the time-to-live duration for how long this
+	// resource will be allowed to exist once the import operation
+	// completes. After the TTL expires, the resource will be automatically
+	// deleted without the user having to take any direct action.
+	//
+	// If this field is unset then the request resource will not be
+	// automatically deleted. If this field is set to zero then the request
+	// resource is eligible for deletion immediately after it finishes.
+	//
+	// +optional
+	// +kubebuilder:validation:Minimum=0
+	TTLSecondsAfterFinished *int64 `json:"ttlSecondsAfterFinished,omitempty"`
+}
+
+// VirtualMachineImageImportRequestStatus defines the observed state of a
+// VirtualMachineImageImportRequest.
+type VirtualMachineImageImportRequestStatus struct {
+	// ItemID is the identifier of the content library item into which the
+	// image is imported.
+	//
+	// The item is deleted, and this field cleared, if the import fails.
+	//
+	// +optional
+	ItemID string `json:"itemID,omitempty"`
+
+	// UpdateSessionID is the identifier of the content library update
+	// session that downloads the files of the image.
+	//
+	// +optional
+	UpdateSessionID string `json:"updateSessionID,omitempty"`
+
+	// StartTime represents time when the request was acknowledged by the
+	// controller. It is represented in RFC3339 form and is in UTC.
+	//
+	// +optional
+	StartTime metav1.Time `json:"startTime,omitempty"`
+
+	// CompletionTime represents time when the request was completed. It is
+	// represented in RFC3339 form and is in UTC.
+	//
+	// The value of this field should be equal to the value of the
+	// LastTransitionTime for the status condition Type=Complete.
+	//
+	// +optional
+	CompletionTime metav1.Time `json:"completionTime,omitempty"`
+
+	// ImageName is the name of the VirtualMachineImage resource that is
+	// eventually realized in the same namespace as the import request after
+	// the import operation completes.
+	//
+	// This field will not be set until the VirtualMachineImage resource
+	// is realized.
+	//
+	// +optional
+	ImageName string `json:"imageName,omitempty"`
+
+	// Ready is set to true only when the image has been imported
+	// successfully and the new VirtualMachineImage resource is ready.
+	//
+	// Readiness is determined by waiting until there is status condition
+	// Type=Complete and ensuring it and all other status conditions present
+	// have a Status=True. The conditions present will be:
+	//
+	//   * TargetValid
+	//   * Downloaded
+	//   * Validated
+	//   * ImageAvailable
+	//   * Complete
+	//
+	// +optional
+	Ready bool `json:"ready,omitempty"`
+
+	// Conditions is a list of the latest, available observations of the
+	// request's current state.
+	//
+	// +optional
+	Conditions []Condition `json:"conditions,omitempty"`
+}
+
+func (vmiir *VirtualMachineImageImportRequest) GetConditions() Conditions {
+	return vmiir.Status.Conditions
+}
+
+func (vmiir *VirtualMachineImageImportRequest) SetConditions(conditions Conditions) {
+	vmiir.Status.Conditions = conditions
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:resource:scope=Namespaced,shortName=vmimport
+// +kubebuilder:storageversion
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="URL",type="string",JSONPath=".spec.source.url"
+// +kubebuilder:printcolumn:name="Image",type="string",JSONPath=".status.imageName"
+// +kubebuilder:printcolumn:name="Ready",type="boolean",JSONPath=".status.ready"
+
+// VirtualMachineImageImportRequest imports an OVA or OVF from an HTTP(S) URL
+// into an image registry and surfaces it as a VirtualMachineImage.
+type VirtualMachineImageImportRequest struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   VirtualMachineImageImportRequestSpec   `json:"spec,omitempty"`
+	Status VirtualMachineImageImportRequestStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// VirtualMachineImageImportRequestList contains a list of
+// VirtualMachineImageImportRequest resources.
+type VirtualMachineImageImportRequestList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []VirtualMachineImageImportRequest `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&VirtualMachineImageImportRequest{}, &VirtualMachineImageImportRequestList{})
+}
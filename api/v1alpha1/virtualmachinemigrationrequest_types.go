@@ -0,0 +1,214 @@
+// Copyright (c) 2023 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// VirtualMachineMigrationRequestConditionTargetValid is the Type for a
+	// VirtualMachineMigrationRequest resource's status condition.
+	//
+	// The condition's status is set to true only when the information
+	// that describes the target of the migration has been validated.
+	VirtualMachineMigrationRequestConditionTargetValid = "TargetValid"
+
+	// VirtualMachineMigrationRequestConditionMigrated is the Type for a
+	// VirtualMachineMigrationRequest resource's status condition.
+	//
+	// The condition's status is set to true only when the VM has been
+	// relocated to the target.
+	VirtualMachineMigrationRequestConditionMigrated = "Migrated"
+
+	// VirtualMachineMigrationRequestConditionComplete is the Type for a
+	// VirtualMachineMigrationRequest resource's status condition.
+	//
+	// The condition's status is set to true only when all other conditions
+	// present on the resource have a truthy status.
+	VirtualMachineMigrationRequestConditionComplete = "Complete"
+)
+
+// Condition.Reason for Conditions related to VirtualMachineMigrationRequest.
+const (
+	// TargetZoneNotExistReason documents that the zone in spec.target.zone
+	// does not exist or is not available to the namespace.
+	TargetZoneNotExistReason = "TargetZoneNotExist"
+
+	// TargetStorageClassNotExistReason documents that the StorageClass in
+	// spec.target.storageClass does not exist.
+	TargetStorageClassNotExistReason = "TargetStorageClassNotExist"
+
+	// TargetStorageClassVolumesReason documents that the VM cannot be
+	// migrated to the StorageClass in spec.target.storageClass because it has
+	// PersistentVolumeClaim volumes, which are not migrated with the VM.
+	TargetStorageClassVolumesReason = "TargetStorageClassVolumes"
+
+	// MigrationNotStartedReason documents that the relocation of the VM to
+	// the target could not be started, ex. the host in spec.target.host is
+	// not in the cluster the VM is migrated to. Starting the relocation is
+	// retried.
+	MigrationNotStartedReason = "MigrationNotStarted"
+
+	// MigratingReason documents that the VM is being relocated to the
+	// target.
+	MigratingReason = "Migrating"
+
+	// MigrationFailureReason documents that the task that relocates the VM
+	// to the target failed. The migration is not retried.
+	MigrationFailureReason = "MigrationFailure"
+
+	// HasNotBeenMigratedReason documents that the
+	// VirtualMachineMigrationRequest hasn't completed because the VM hasn't
+	// been relocated to the target yet.
+	HasNotBeenMigratedReason = "HasNotBeenMigrated"
+)
+
+// VirtualMachineMigrationRequestLocation is where a VM runs and stores its
+// files.
+type VirtualMachineMigrationRequestLocation struct {
+	// Zone is the name of the availability zone.
+	//
+	// +optional
+	Zone string `json:"zone,omitempty"`
+
+	// Host is the name of the ESXi host.
+	//
+	// +optional
+	Host string `json:"host,omitempty"`
+
+	// StorageClass is the name of the StorageClass of the VM's files and
+	// disks.
+	//
+	// +optional
+	StorageClass string `json:"storageClass,omitempty"`
+}
+
+// VirtualMachineMigrationRequestSpec defines the desired state of a
+// VirtualMachineMigrationRequest.
+type VirtualMachineMigrationRequestSpec struct {
+	// VMName is the name of the VirtualMachine resource in the same namespace
+	// as the request that is migrated.
+	VMName string `json:"vmName"`
+
+	// Target is where the VM is migrated to. At least one of the zone, host
+	// or storage class must be specified. The VM stays where it is for the
+	// parts of the target that are omitted.
+	//
+	// Changing the zone or host migrates the VM with vMotion, and changing
+	// the storage class migrates the VM's files and disks, except those of
+	// its PersistentVolumeClaims, with Storage vMotion.
+	Target VirtualMachineMigrationRequestLocation `json:"target"`
+
+	// TTLSecondsAfterFinished is the time-to-live duration for how long this
+	// resource will be allowed to exist once the migration completes. After
+	// the TTL expires, the resource will be automatically deleted without
+	// the user having to take any direct action.
+	//
+	// If this field is unset then the request resource will not be
+	// automatically deleted. If this field is set to zero then the request
+	// resource is eligible for deletion immediately after it finishes.
+	//
+	// +optional
+	// +kubebuilder:validation:Minimum=0
+	TTLSecondsAfterFinished *int64 `json:"ttlSecondsAfterFinished,omitempty"`
+}
+
+// VirtualMachineMigrationRequestStatus defines the observed state of a
+// VirtualMachineMigrationRequest.
+type VirtualMachineMigrationRequestStatus struct {
+	// Source is where the VM ran and stored its files when the migration
+	// started.
+	//
+	// +optional
+	Source VirtualMachineMigrationRequestLocation `json:"source,omitempty"`
+
+	// TaskID is the identifier of the vSphere task that relocates the VM.
+	//
+	// +optional
+	TaskID string `json:"taskID,omitempty"`
+
+	// Progress is the percentage of the relocation that has completed.
+	//
+	// +optional
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:validation:Maximum=100
+	Progress int32 `json:"progress,omitempty"`
+
+	// StartTime represents time when the request was acknowledged by the
+	// controller. It is represented in RFC3339 form and is in UTC.
+	//
+	// +optional
+	StartTime metav1.Time `json:"startTime,omitempty"`
+
+	// CompletionTime represents time when the request was completed. It is
+	// represented in RFC3339 form and is in UTC.
+	//
+	// The value of this field should be equal to the value of the
+	// LastTransitionTime for the status condition Type=Complete.
+	//
+	// +optional
+	CompletionTime metav1.Time `json:"completionTime,omitempty"`
+
+	// Ready is set to true only when the VM has been migrated successfully
+	// and the VirtualMachine resource has been updated with its new
+	// location.
+	//
+	// Readiness is determined by waiting until there is status condition
+	// Type=Complete and ensuring it and all other status conditions present
+	// have a Status=True. The conditions present will be:
+	//
+	//   * TargetValid
+	//   * Migrated
+	//   * Complete
+	//
+	// +optional
+	Ready bool `json:"ready,omitempty"`
+
+	// Conditions is a list of the latest, available observations of the
+	// request's current state.
+	//
+	// +optional
+	Conditions []Condition `json:"conditions,omitempty"`
+}
+
+func (vmmr *VirtualMachineMigrationRequest) GetConditions() Conditions {
+	return vmmr.Status.Conditions
+}
+
+func (vmmr *VirtualMachineMigrationRequest) SetConditions(conditions Conditions) {
+	vmmr.Status.Conditions = conditions
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:resource:scope=Namespaced,shortName=vmmigrate
+// +kubebuilder:storageversion
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="VM",type="string",JSONPath=".spec.vmName"
+// +kubebuilder:printcolumn:name="Progress",type="integer",JSONPath=".status.progress"
+// +kubebuilder:printcolumn:name="Ready",type="boolean",JSONPath=".status.ready"
+
+// VirtualMachineMigrationRequest relocates a VirtualMachine to another zone,
+// host or storage class with vMotion and Storage vMotion.
+type VirtualMachineMigrationRequest struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   VirtualMachineMigrationRequestSpec   `json:"spec,omitempty"`
+	Status VirtualMachineMigrationRequestStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// VirtualMachineMigrationRequestList contains a list of
+// VirtualMachineMigrationRequest resources.
+type VirtualMachineMigrationRequestList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []VirtualMachineMigrationRequest `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&VirtualMachineMigrationRequest{}, &VirtualMachineMigrationRequestList{})
+}
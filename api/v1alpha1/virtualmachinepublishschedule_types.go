@@ -0,0 +1,192 @@
+// Copyright (c) 2023 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// VirtualMachinePublishScheduleConditionScheduleValid is the Type for a
+	// VirtualMachinePublishSchedule resource's status condition.
+	//
+	// The condition's status is set to true only when spec.schedule is a
+	// valid cron expression.
+	VirtualMachinePublishScheduleConditionScheduleValid = "ScheduleValid"
+
+	// VirtualMachinePublishScheduleConditionLastPublishSucceeded is the Type
+	// for a VirtualMachinePublishSchedule resource's status condition.
+	//
+	// The condition's status is set to true when the most recently finished
+	// VirtualMachinePublishRequest spawned by the schedule completed.
+	VirtualMachinePublishScheduleConditionLastPublishSucceeded = "LastPublishSucceeded"
+)
+
+// Condition.Reason for Conditions related to VirtualMachinePublishSchedule.
+const (
+	// InvalidScheduleReason documents that spec.schedule of the
+	// VirtualMachinePublishSchedule is not a valid cron expression.
+	InvalidScheduleReason = "InvalidSchedule"
+
+	// PublishRequestFailedReason documents that the VirtualMachinePublishRequest
+	// spawned by the VirtualMachinePublishSchedule could not be completed.
+	PublishRequestFailedReason = "PublishRequestFailed"
+)
+
+const (
+	// VirtualMachinePublishScheduleLabelKey is the label applied to the
+	// VirtualMachinePublishRequest resources spawned by a
+	// VirtualMachinePublishSchedule. Its value is the name of the schedule.
+	VirtualMachinePublishScheduleLabelKey = "vmoperator.vmware.com/publish-schedule"
+
+	// VirtualMachinePublishScheduleDefaultRetentionCount is the number of
+	// published items retained when spec.retentionCount is omitted.
+	VirtualMachinePublishScheduleDefaultRetentionCount = 3
+)
+
+// VirtualMachinePublishScheduleSpec defines the desired state of a
+// VirtualMachinePublishSchedule.
+type VirtualMachinePublishScheduleSpec struct {
+	// Schedule is the schedule in standard cron format, ex. "0 2 * * *",
+	// on which the source VM is published. The schedule is evaluated in UTC.
+	Schedule string `json:"schedule"`
+
+	// Suspend indicates that subsequent publications should not be started.
+	// Publications that are already in progress are not affected.
+	//
+	// +optional
+	Suspend bool `json:"suspend,omitempty"`
+
+	// Source is the source of the publications, ex. a VirtualMachine
+	// resource.
+	//
+	// If this value is omitted then the source is the resource with the same
+	// name as this VirtualMachinePublishSchedule resource.
+	//
+	// +optional
+	Source VirtualMachinePublishRequestSource `json:"source,omitempty"`
+
+	// Target is the target of the publications, ex. item information and a
+	// ContentLibrary resource.
+	//
+	// The value of spec.target.item.name is used as a prefix of the name of
+	// each published item, which is suffixed with the time the publication
+	// was scheduled, ex. my-vm-image-20230102030405. If omitted, the prefix
+	// is spec.source.name + "-image".
+	//
+	// Publishing to an OCI registry is not supported.
+	//
+	// +optional
+	Target VirtualMachinePublishRequestTarget `json:"target,omitempty"`
+
+	// RetentionCount is the number of the most recently published items
+	// that are retained. When a publication completes, the oldest items
+	// published by this schedule are deleted from the target location until
+	// no more than this number of items remain. The
+	// VirtualMachinePublishRequest resources that published the deleted
+	// items are deleted as well.
+	//
+	// +kubebuilder:default=3
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	RetentionCount *int32 `json:"retentionCount,omitempty"`
+}
+
+// VirtualMachinePublishSchedulePublishedItem describes an item published
+// by a VirtualMachinePublishSchedule.
+type VirtualMachinePublishSchedulePublishedItem struct {
+	// Name is the name of the published item.
+	Name string `json:"name"`
+
+	// PublishRequestName is the name of the VirtualMachinePublishRequest
+	// resource that published the item.
+	//
+	// +optional
+	PublishRequestName string `json:"publishRequestName,omitempty"`
+
+	// ImageName is the name of the VirtualMachineImage resource realized
+	// from the published item.
+	//
+	// +optional
+	ImageName string `json:"imageName,omitempty"`
+
+	// PublishTime is the time the publication of the item completed.
+	PublishTime metav1.Time `json:"publishTime"`
+}
+
+// VirtualMachinePublishScheduleStatus defines the observed state of a
+// VirtualMachinePublishSchedule.
+type VirtualMachinePublishScheduleStatus struct {
+	// LastScheduleTime is the last time a publication was scheduled.
+	//
+	// +optional
+	LastScheduleTime *metav1.Time `json:"lastScheduleTime,omitempty"`
+
+	// LastSuccessfulPublishTime is the last time a publication completed.
+	//
+	// +optional
+	LastSuccessfulPublishTime *metav1.Time `json:"lastSuccessfulPublishTime,omitempty"`
+
+	// Active is the name of the VirtualMachinePublishRequest resource that
+	// is currently publishing the source VM, if any.
+	//
+	// A scheduled publication is skipped while another one is in progress.
+	//
+	// +optional
+	Active string `json:"active,omitempty"`
+
+	// PublishedItems is the list of retained items published by this
+	// schedule, from oldest to newest.
+	//
+	// +optional
+	PublishedItems []VirtualMachinePublishSchedulePublishedItem `json:"publishedItems,omitempty"`
+
+	// Conditions is a list of the latest, available observations of the
+	// schedule's current state.
+	//
+	// +optional
+	Conditions []Condition `json:"conditions,omitempty"`
+}
+
+func (vmps *VirtualMachinePublishSchedule) GetConditions() Conditions {
+	return vmps.Status.Conditions
+}
+
+func (vmps *VirtualMachinePublishSchedule) SetConditions(conditions Conditions) {
+	vmps.Status.Conditions = conditions
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:resource:scope=Namespaced,shortName=vmpubsched
+// +kubebuilder:storageversion
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="Schedule",type="string",JSONPath=".spec.schedule"
+// +kubebuilder:printcolumn:name="Suspend",type="boolean",JSONPath=".spec.suspend"
+// +kubebuilder:printcolumn:name="Active",type="string",JSONPath=".status.active"
+// +kubebuilder:printcolumn:name="Last-Schedule",type="date",JSONPath=".status.lastScheduleTime"
+
+// VirtualMachinePublishSchedule periodically publishes a VirtualMachine as a
+// VirtualMachineImage to an image registry and retains a limited number of
+// the published items.
+type VirtualMachinePublishSchedule struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   VirtualMachinePublishScheduleSpec   `json:"spec,omitempty"`
+	Status VirtualMachinePublishScheduleStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// VirtualMachinePublishScheduleList contains a list of
+// VirtualMachinePublishSchedule resources.
+type VirtualMachinePublishScheduleList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []VirtualMachinePublishSchedule `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&VirtualMachinePublishSchedule{}, &VirtualMachinePublishScheduleList{})
+}
@@ -0,0 +1,86 @@
+// Copyright (c) 2023 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package v1alpha1
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// The resources whose consumption by the VirtualMachines of a namespace may
+// be limited by a VirtualMachineQuota. The consumption of a VirtualMachine is
+// derived from its VirtualMachineClass.
+const (
+	// VirtualMachineQuotaResourceVirtualMachines is the number of
+	// VirtualMachines.
+	VirtualMachineQuotaResourceVirtualMachines corev1.ResourceName = "virtualmachines"
+
+	// VirtualMachineQuotaResourceCPU is the number of virtual CPUs.
+	VirtualMachineQuotaResourceCPU corev1.ResourceName = "cpu"
+
+	// VirtualMachineQuotaResourceMemory is the amount of memory.
+	VirtualMachineQuotaResourceMemory corev1.ResourceName = "memory"
+
+	// VirtualMachineQuotaResourceVGPUs is the number of vGPU devices.
+	VirtualMachineQuotaResourceVGPUs corev1.ResourceName = "vgpus"
+
+	// VirtualMachineQuotaResourceInstanceStorage is the size of the instance
+	// storage volumes.
+	VirtualMachineQuotaResourceInstanceStorage corev1.ResourceName = "instancestorage"
+)
+
+// VirtualMachineQuotaSpec defines the desired state of VirtualMachineQuota.
+type VirtualMachineQuotaSpec struct {
+	// Hard is the maximum amount of each resource that the VirtualMachines of
+	// the namespace may consume together. The resources are virtualmachines,
+	// cpu, memory, vgpus and instancestorage. A VirtualMachine that would
+	// make the consumption of a resource exceed its hard limit is not
+	// created.
+	//
+	// +optional
+	Hard corev1.ResourceList `json:"hard,omitempty"`
+}
+
+// VirtualMachineQuotaStatus defines the observed state of VirtualMachineQuota.
+type VirtualMachineQuotaStatus struct {
+	// Hard is the enforced hard limit of each resource.
+	//
+	// +optional
+	Hard corev1.ResourceList `json:"hard,omitempty"`
+
+	// Used is the amount of each resource of Hard that the VirtualMachines of
+	// the namespace consume.
+	//
+	// +optional
+	Used corev1.ResourceList `json:"used,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:resource:scope=Namespaced,shortName=vmquota
+// +kubebuilder:storageversion
+// +kubebuilder:subresource:status
+
+// VirtualMachineQuota limits the resources that the VirtualMachines of a
+// namespace may consume together, as derived from their
+// VirtualMachineClasses.
+type VirtualMachineQuota struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   VirtualMachineQuotaSpec   `json:"spec,omitempty"`
+	Status VirtualMachineQuotaStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// VirtualMachineQuotaList contains a list of VirtualMachineQuota resources.
+type VirtualMachineQuotaList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []VirtualMachineQuota `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&VirtualMachineQuota{}, &VirtualMachineQuotaList{})
+}
@@ -14,6 +14,8 @@ func Convert_v1alpha1_VirtualMachineSetResourcePolicySpec_To_v1alpha2_VirtualMac
 	in *VirtualMachineSetResourcePolicySpec, out *v1alpha2.VirtualMachineSetResourcePolicySpec, s apiconversion.Scope) error {
 
 	out.Folder = in.Folder.Name
+	// WARNING: in.ClusterModules[].Type and in.ClusterModules[].Enforcement require manual
+	// conversion: do not exist in peer-type
 	for _, mod := range in.ClusterModules {
 		out.ClusterModuleGroups = append(out.ClusterModuleGroups, mod.GroupName)
 	}
@@ -1,4 +1,4 @@
-// Copyright (c) 2019 VMware, Inc. All Rights Reserved.
+// Copyright (c) 2019-2023 VMware, Inc. All Rights Reserved.
 // SPDX-License-Identifier: Apache-2.0
 
 package v1alpha1
@@ -29,11 +29,54 @@ type FolderSpec struct {
 	Name string `json:"name,omitempty"`
 }
 
+// ClusterModuleType is the placement relationship between the VMs of a ClusterModule Group.
+type ClusterModuleType string
+
+const (
+	// ClusterModuleTypeAntiAffinity places the VMs of the group on different hosts.
+	ClusterModuleTypeAntiAffinity ClusterModuleType = "AntiAffinity"
+
+	// ClusterModuleTypeAffinity places the VMs of the group on the same host.
+	ClusterModuleTypeAffinity ClusterModuleType = "Affinity"
+)
+
+// ClusterModuleEnforcement is how strictly the placement relationship between the VMs of a ClusterModule
+// Group is enforced.
+type ClusterModuleEnforcement string
+
+const (
+	// ClusterModuleEnforcementPreferred means the VMs should be placed according to the group's type,
+	// but DRS may violate it, for example when there are not enough hosts.
+	ClusterModuleEnforcementPreferred ClusterModuleEnforcement = "Preferred"
+
+	// ClusterModuleEnforcementRequired means the VMs must be placed according to the group's type:
+	// DRS will not power on or migrate a VM when that would violate it.
+	ClusterModuleEnforcementRequired ClusterModuleEnforcement = "Required"
+)
+
 // ClusterModuleSpec defines a grouping of VirtualMachines that are to be grouped together as a logical unit by
-// the infrastructure provider.  Within vSphere, the ClusterModuleSpec maps directly to a vSphere ClusterModule.
+// the infrastructure provider.  Within vSphere, a preferred anti-affinity ClusterModuleSpec maps directly to a
+// vSphere ClusterModule, and the other ClusterModuleSpecs map to vSphere DRS VM-VM rules.
+//
+// A VirtualMachine is a member of the groups whose names are in its vsphere-cluster-module-group annotation,
+// separated by commas.
 type ClusterModuleSpec struct {
 	// GroupName describes the name of the ClusterModule Group.
 	GroupName string `json:"groupname"`
+
+	// Type is whether the VMs of the group are placed on different hosts, AntiAffinity, or on the
+	// same host, Affinity. Defaults to AntiAffinity.
+	//
+	// +optional
+	// +kubebuilder:validation:Enum=AntiAffinity;Affinity
+	Type ClusterModuleType `json:"type,omitempty"`
+
+	// Enforcement is whether the VMs should, Preferred, or must, Required, be placed according to the
+	// group's type. Defaults to Preferred.
+	//
+	// +optional
+	// +kubebuilder:validation:Enum=Preferred;Required
+	Enforcement ClusterModuleEnforcement `json:"enforcement,omitempty"`
 }
 
 // VirtualMachineSetResourcePolicySpec defines the desired state of VirtualMachineSetResourcePolicy.
@@ -46,12 +89,48 @@ type VirtualMachineSetResourcePolicySpec struct {
 // VirtualMachineSetResourcePolicyStatus defines the observed state of VirtualMachineSetResourcePolicy.
 type VirtualMachineSetResourcePolicyStatus struct {
 	ClusterModules []ClusterModuleStatus `json:"clustermodules,omitempty"`
+
+	// Zones describes the observed state of the VirtualMachineSetResourcePolicy in each of the
+	// availability zones of the namespace.
+	//
+	// +optional
+	Zones []ResourcePolicyZoneStatus `json:"zones,omitempty"`
+
+	// Conditions describes the observed conditions of the VirtualMachineSetResourcePolicy. The Ready
+	// condition is true when the VirtualMachineSetResourcePolicy is ready in all of the Zones.
+	//
+	// +optional
+	Conditions []Condition `json:"conditions,omitempty" patchStrategy:"merge" patchMergeKey:"type"`
+}
+
+// ResourcePolicyZoneStatus describes the observed state of a VirtualMachineSetResourcePolicy in an
+// availability zone.
+type ResourcePolicyZoneStatus struct {
+	// Name is the name of the availability zone.
+	Name string `json:"name"`
+
+	// Conditions describes whether the Folder, ResourcePool and ClusterModules of the
+	// VirtualMachineSetResourcePolicy are ready in the availability zone.
+	//
+	// +optional
+	Conditions []Condition `json:"conditions,omitempty" patchStrategy:"merge" patchMergeKey:"type"`
 }
 
 type ClusterModuleStatus struct {
 	GroupName   string `json:"groupname"`
 	ModuleUuid  string `json:"moduleUUID"` //nolint:revive,stylecheck
 	ClusterMoID string `json:"clusterMoID"`
+
+	// RuleName is the name of the DRS VM-VM rule that realizes the group in the cluster. It is empty
+	// when the group is realized by the ClusterModule ModuleUuid.
+	//
+	// +optional
+	RuleName string `json:"ruleName,omitempty"`
+
+	// Members are the names of the VirtualMachines in the cluster that are members of the group.
+	//
+	// +optional
+	Members []string `json:"members,omitempty"`
 }
 
 // +kubebuilder:object:root=true
@@ -71,6 +150,14 @@ func (res VirtualMachineSetResourcePolicy) NamespacedName() string {
 	return res.Namespace + "/" + res.Name
 }
 
+func (res *VirtualMachineSetResourcePolicy) GetConditions() Conditions {
+	return res.Status.Conditions
+}
+
+func (res *VirtualMachineSetResourcePolicy) SetConditions(conditions Conditions) {
+	res.Status.Conditions = conditions
+}
+
 // +kubebuilder:object:root=true
 
 // VirtualMachineSetResourcePolicyList contains a list of VirtualMachineSetResourcePolicy.
@@ -13,6 +13,14 @@ type WebConsoleRequestSpec struct {
 	VirtualMachineName string `json:"virtualMachineName"`
 	// PublicKey is used to encrypt the status.response. This is expected to be a RSA OAEP public key in X.509 PEM format.
 	PublicKey string `json:"publicKey"`
+	// Duration is the requested lifetime of the ticket. When not set, a default lifetime configured by the
+	// administrator is used. The lifetime is at most a maximum configured by the administrator.
+	// +optional
+	Duration *metav1.Duration `json:"duration,omitempty"`
+	// RenewalCount is incremented to request a new ticket, with a new ExpiryTime, before the current ticket
+	// expires.
+	// +optional
+	RenewalCount int32 `json:"renewalCount,omitempty"`
 }
 
 // WebConsoleRequestStatus defines the observed state, which includes the web console request itself.
@@ -45,6 +53,8 @@ type WebConsoleRequestStatus struct {
 	// by Go's https://pkg.go.dev/net#ResolveIPAddr and
 	// https://pkg.go.dev/net#ParseIP functions.
 	ProxyAddr string `json:"proxyAddr,omitempty"`
+	// RenewalCount is the spec.renewalCount when the ticket referenced in Response was issued.
+	RenewalCount int32 `json:"renewalCount,omitempty"`
 }
 
 // +kubebuilder:object:root=true
@@ -127,6 +127,16 @@ func RegisterConversions(s *runtime.Scheme) error {
 	}); err != nil {
 		return err
 	}
+	if err := s.AddGeneratedConversionFunc((*ResourcePolicyZoneStatus)(nil), (*v1alpha2.ResourcePolicyZoneStatus)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1alpha1_ResourcePolicyZoneStatus_To_v1alpha2_ResourcePolicyZoneStatus(a.(*ResourcePolicyZoneStatus), b.(*v1alpha2.ResourcePolicyZoneStatus), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*v1alpha2.ResourcePolicyZoneStatus)(nil), (*ResourcePolicyZoneStatus)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1alpha2_ResourcePolicyZoneStatus_To_v1alpha1_ResourcePolicyZoneStatus(a.(*v1alpha2.ResourcePolicyZoneStatus), b.(*ResourcePolicyZoneStatus), scope)
+	}); err != nil {
+		return err
+	}
 	if err := s.AddGeneratedConversionFunc((*ResourcePoolSpec)(nil), (*v1alpha2.ResourcePoolSpec)(nil), func(a, b interface{}, scope conversion.Scope) error {
 		return Convert_v1alpha1_ResourcePoolSpec_To_v1alpha2_ResourcePoolSpec(a.(*ResourcePoolSpec), b.(*v1alpha2.ResourcePoolSpec), scope)
 	}); err != nil {
@@ -177,6 +187,36 @@ func RegisterConversions(s *runtime.Scheme) error {
 	}); err != nil {
 		return err
 	}
+	if err := s.AddGeneratedConversionFunc((*VirtualMachineAffinityRules)(nil), (*v1alpha2.VirtualMachineAffinityRules)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1alpha1_VirtualMachineAffinityRules_To_v1alpha2_VirtualMachineAffinityRules(a.(*VirtualMachineAffinityRules), b.(*v1alpha2.VirtualMachineAffinityRules), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*v1alpha2.VirtualMachineAffinityRules)(nil), (*VirtualMachineAffinityRules)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1alpha2_VirtualMachineAffinityRules_To_v1alpha1_VirtualMachineAffinityRules(a.(*v1alpha2.VirtualMachineAffinityRules), b.(*VirtualMachineAffinityRules), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*VirtualMachineAffinitySpec)(nil), (*v1alpha2.VirtualMachineAffinitySpec)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1alpha1_VirtualMachineAffinitySpec_To_v1alpha2_VirtualMachineAffinitySpec(a.(*VirtualMachineAffinitySpec), b.(*v1alpha2.VirtualMachineAffinitySpec), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*v1alpha2.VirtualMachineAffinitySpec)(nil), (*VirtualMachineAffinitySpec)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1alpha2_VirtualMachineAffinitySpec_To_v1alpha1_VirtualMachineAffinitySpec(a.(*v1alpha2.VirtualMachineAffinitySpec), b.(*VirtualMachineAffinitySpec), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*VirtualMachineAffinityTerm)(nil), (*v1alpha2.VirtualMachineAffinityTerm)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1alpha1_VirtualMachineAffinityTerm_To_v1alpha2_VirtualMachineAffinityTerm(a.(*VirtualMachineAffinityTerm), b.(*v1alpha2.VirtualMachineAffinityTerm), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*v1alpha2.VirtualMachineAffinityTerm)(nil), (*VirtualMachineAffinityTerm)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1alpha2_VirtualMachineAffinityTerm_To_v1alpha1_VirtualMachineAffinityTerm(a.(*v1alpha2.VirtualMachineAffinityTerm), b.(*VirtualMachineAffinityTerm), scope)
+	}); err != nil {
+		return err
+	}
 	if err := s.AddGeneratedConversionFunc((*VirtualMachineClass)(nil), (*v1alpha2.VirtualMachineClass)(nil), func(a, b interface{}, scope conversion.Scope) error {
 		return Convert_v1alpha1_VirtualMachineClass_To_v1alpha2_VirtualMachineClass(a.(*VirtualMachineClass), b.(*v1alpha2.VirtualMachineClass), scope)
 	}); err != nil {
@@ -227,6 +267,16 @@ func RegisterConversions(s *runtime.Scheme) error {
 	}); err != nil {
 		return err
 	}
+	if err := s.AddGeneratedConversionFunc((*VirtualMachineClassRollingRestart)(nil), (*v1alpha2.VirtualMachineClassRollingRestart)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1alpha1_VirtualMachineClassRollingRestart_To_v1alpha2_VirtualMachineClassRollingRestart(a.(*VirtualMachineClassRollingRestart), b.(*v1alpha2.VirtualMachineClassRollingRestart), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*v1alpha2.VirtualMachineClassRollingRestart)(nil), (*VirtualMachineClassRollingRestart)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1alpha2_VirtualMachineClassRollingRestart_To_v1alpha1_VirtualMachineClassRollingRestart(a.(*v1alpha2.VirtualMachineClassRollingRestart), b.(*VirtualMachineClassRollingRestart), scope)
+	}); err != nil {
+		return err
+	}
 	if err := s.AddGeneratedConversionFunc((*VirtualMachineClassSpec)(nil), (*v1alpha2.VirtualMachineClassSpec)(nil), func(a, b interface{}, scope conversion.Scope) error {
 		return Convert_v1alpha1_VirtualMachineClassSpec_To_v1alpha2_VirtualMachineClassSpec(a.(*VirtualMachineClassSpec), b.(*v1alpha2.VirtualMachineClassSpec), scope)
 	}); err != nil {
@@ -242,6 +292,16 @@ func RegisterConversions(s *runtime.Scheme) error {
 	}); err != nil {
 		return err
 	}
+	if err := s.AddGeneratedConversionFunc((*VirtualMachineClassUpdatePolicy)(nil), (*v1alpha2.VirtualMachineClassUpdatePolicy)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1alpha1_VirtualMachineClassUpdatePolicy_To_v1alpha2_VirtualMachineClassUpdatePolicy(a.(*VirtualMachineClassUpdatePolicy), b.(*v1alpha2.VirtualMachineClassUpdatePolicy), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*v1alpha2.VirtualMachineClassUpdatePolicy)(nil), (*VirtualMachineClassUpdatePolicy)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1alpha2_VirtualMachineClassUpdatePolicy_To_v1alpha1_VirtualMachineClassUpdatePolicy(a.(*v1alpha2.VirtualMachineClassUpdatePolicy), b.(*VirtualMachineClassUpdatePolicy), scope)
+	}); err != nil {
+		return err
+	}
 	if err := s.AddGeneratedConversionFunc((*VirtualMachineImage)(nil), (*v1alpha2.VirtualMachineImage)(nil), func(a, b interface{}, scope conversion.Scope) error {
 		return Convert_v1alpha1_VirtualMachineImage_To_v1alpha2_VirtualMachineImage(a.(*VirtualMachineImage), b.(*v1alpha2.VirtualMachineImage), scope)
 	}); err != nil {
@@ -312,6 +372,16 @@ func RegisterConversions(s *runtime.Scheme) error {
 	}); err != nil {
 		return err
 	}
+	if err := s.AddGeneratedConversionFunc((*VirtualMachinePublishRequestOCIArtifactStatus)(nil), (*v1alpha2.VirtualMachinePublishRequestOCIArtifactStatus)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1alpha1_VirtualMachinePublishRequestOCIArtifactStatus_To_v1alpha2_VirtualMachinePublishRequestOCIArtifactStatus(a.(*VirtualMachinePublishRequestOCIArtifactStatus), b.(*v1alpha2.VirtualMachinePublishRequestOCIArtifactStatus), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*v1alpha2.VirtualMachinePublishRequestOCIArtifactStatus)(nil), (*VirtualMachinePublishRequestOCIArtifactStatus)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1alpha2_VirtualMachinePublishRequestOCIArtifactStatus_To_v1alpha1_VirtualMachinePublishRequestOCIArtifactStatus(a.(*v1alpha2.VirtualMachinePublishRequestOCIArtifactStatus), b.(*VirtualMachinePublishRequestOCIArtifactStatus), scope)
+	}); err != nil {
+		return err
+	}
 	if err := s.AddGeneratedConversionFunc((*VirtualMachinePublishRequestSource)(nil), (*v1alpha2.VirtualMachinePublishRequestSource)(nil), func(a, b interface{}, scope conversion.Scope) error {
 		return Convert_v1alpha1_VirtualMachinePublishRequestSource_To_v1alpha2_VirtualMachinePublishRequestSource(a.(*VirtualMachinePublishRequestSource), b.(*v1alpha2.VirtualMachinePublishRequestSource), scope)
 	}); err != nil {
@@ -372,6 +442,16 @@ func RegisterConversions(s *runtime.Scheme) error {
 	}); err != nil {
 		return err
 	}
+	if err := s.AddGeneratedConversionFunc((*VirtualMachinePublishRequestTargetOCILocation)(nil), (*v1alpha2.VirtualMachinePublishRequestTargetOCILocation)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1alpha1_VirtualMachinePublishRequestTargetOCILocation_To_v1alpha2_VirtualMachinePublishRequestTargetOCILocation(a.(*VirtualMachinePublishRequestTargetOCILocation), b.(*v1alpha2.VirtualMachinePublishRequestTargetOCILocation), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*v1alpha2.VirtualMachinePublishRequestTargetOCILocation)(nil), (*VirtualMachinePublishRequestTargetOCILocation)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1alpha2_VirtualMachinePublishRequestTargetOCILocation_To_v1alpha1_VirtualMachinePublishRequestTargetOCILocation(a.(*v1alpha2.VirtualMachinePublishRequestTargetOCILocation), b.(*VirtualMachinePublishRequestTargetOCILocation), scope)
+	}); err != nil {
+		return err
+	}
 	if err := s.AddGeneratedConversionFunc((*VirtualMachineResourceSpec)(nil), (*v1alpha2.VirtualMachineResourceSpec)(nil), func(a, b interface{}, scope conversion.Scope) error {
 		return Convert_v1alpha1_VirtualMachineResourceSpec_To_v1alpha2_VirtualMachineResourceSpec(a.(*VirtualMachineResourceSpec), b.(*v1alpha2.VirtualMachineResourceSpec), scope)
 	}); err != nil {
@@ -462,6 +542,26 @@ func RegisterConversions(s *runtime.Scheme) error {
 	}); err != nil {
 		return err
 	}
+	if err := s.AddGeneratedConversionFunc((*VirtualMachineTaskFault)(nil), (*v1alpha2.VirtualMachineTaskFault)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1alpha1_VirtualMachineTaskFault_To_v1alpha2_VirtualMachineTaskFault(a.(*VirtualMachineTaskFault), b.(*v1alpha2.VirtualMachineTaskFault), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*v1alpha2.VirtualMachineTaskFault)(nil), (*VirtualMachineTaskFault)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1alpha2_VirtualMachineTaskFault_To_v1alpha1_VirtualMachineTaskFault(a.(*v1alpha2.VirtualMachineTaskFault), b.(*VirtualMachineTaskFault), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*VirtualMachineTaskInfo)(nil), (*v1alpha2.VirtualMachineTaskInfo)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1alpha1_VirtualMachineTaskInfo_To_v1alpha2_VirtualMachineTaskInfo(a.(*VirtualMachineTaskInfo), b.(*v1alpha2.VirtualMachineTaskInfo), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*v1alpha2.VirtualMachineTaskInfo)(nil), (*VirtualMachineTaskInfo)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1alpha2_VirtualMachineTaskInfo_To_v1alpha1_VirtualMachineTaskInfo(a.(*v1alpha2.VirtualMachineTaskInfo), b.(*VirtualMachineTaskInfo), scope)
+	}); err != nil {
+		return err
+	}
 	if err := s.AddGeneratedConversionFunc((*VirtualMachineTemplate)(nil), (*v1alpha2.VirtualMachineTemplate)(nil), func(a, b interface{}, scope conversion.Scope) error {
 		return Convert_v1alpha1_VirtualMachineTemplate_To_v1alpha2_VirtualMachineTemplate(a.(*VirtualMachineTemplate), b.(*v1alpha2.VirtualMachineTemplate), scope)
 	}); err != nil {
@@ -472,6 +572,26 @@ func RegisterConversions(s *runtime.Scheme) error {
 	}); err != nil {
 		return err
 	}
+	if err := s.AddGeneratedConversionFunc((*VirtualMachineTopologySpreadConstraint)(nil), (*v1alpha2.VirtualMachineTopologySpreadConstraint)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1alpha1_VirtualMachineTopologySpreadConstraint_To_v1alpha2_VirtualMachineTopologySpreadConstraint(a.(*VirtualMachineTopologySpreadConstraint), b.(*v1alpha2.VirtualMachineTopologySpreadConstraint), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*v1alpha2.VirtualMachineTopologySpreadConstraint)(nil), (*VirtualMachineTopologySpreadConstraint)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1alpha2_VirtualMachineTopologySpreadConstraint_To_v1alpha1_VirtualMachineTopologySpreadConstraint(a.(*v1alpha2.VirtualMachineTopologySpreadConstraint), b.(*VirtualMachineTopologySpreadConstraint), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*VirtualMachineWeightedAffinityTerm)(nil), (*v1alpha2.VirtualMachineWeightedAffinityTerm)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1alpha1_VirtualMachineWeightedAffinityTerm_To_v1alpha2_VirtualMachineWeightedAffinityTerm(a.(*VirtualMachineWeightedAffinityTerm), b.(*v1alpha2.VirtualMachineWeightedAffinityTerm), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*v1alpha2.VirtualMachineWeightedAffinityTerm)(nil), (*VirtualMachineWeightedAffinityTerm)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1alpha2_VirtualMachineWeightedAffinityTerm_To_v1alpha1_VirtualMachineWeightedAffinityTerm(a.(*v1alpha2.VirtualMachineWeightedAffinityTerm), b.(*VirtualMachineWeightedAffinityTerm), scope)
+	}); err != nil {
+		return err
+	}
 	if err := s.AddConversionFunc((*common.LocalObjectRef)(nil), (*ContentProviderReference)(nil), func(a, b interface{}, scope conversion.Scope) error {
 		return Convert_common_LocalObjectRef_To_v1alpha1_ContentProviderReference(a.(*common.LocalObjectRef), b.(*ContentProviderReference), scope)
 	}); err != nil {
@@ -846,6 +966,48 @@ func Convert_v1alpha2_NetworkStatus_To_v1alpha1_NetworkStatus(in *v1alpha2.Netwo
 	return autoConvert_v1alpha2_NetworkStatus_To_v1alpha1_NetworkStatus(in, out, s)
 }
 
+func autoConvert_v1alpha1_ResourcePolicyZoneStatus_To_v1alpha2_ResourcePolicyZoneStatus(in *ResourcePolicyZoneStatus, out *v1alpha2.ResourcePolicyZoneStatus, s conversion.Scope) error {
+	out.Name = in.Name
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]v1.Condition, len(*in))
+		for i := range *in {
+			if err := Convert_v1alpha1_Condition_To_v1_Condition(&(*in)[i], &(*out)[i], s); err != nil {
+				return err
+			}
+		}
+	} else {
+		out.Conditions = nil
+	}
+	return nil
+}
+
+// Convert_v1alpha1_ResourcePolicyZoneStatus_To_v1alpha2_ResourcePolicyZoneStatus is an autogenerated conversion function.
+func Convert_v1alpha1_ResourcePolicyZoneStatus_To_v1alpha2_ResourcePolicyZoneStatus(in *ResourcePolicyZoneStatus, out *v1alpha2.ResourcePolicyZoneStatus, s conversion.Scope) error {
+	return autoConvert_v1alpha1_ResourcePolicyZoneStatus_To_v1alpha2_ResourcePolicyZoneStatus(in, out, s)
+}
+
+func autoConvert_v1alpha2_ResourcePolicyZoneStatus_To_v1alpha1_ResourcePolicyZoneStatus(in *v1alpha2.ResourcePolicyZoneStatus, out *ResourcePolicyZoneStatus, s conversion.Scope) error {
+	out.Name = in.Name
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]Condition, len(*in))
+		for i := range *in {
+			if err := Convert_v1_Condition_To_v1alpha1_Condition(&(*in)[i], &(*out)[i], s); err != nil {
+				return err
+			}
+		}
+	} else {
+		out.Conditions = nil
+	}
+	return nil
+}
+
+// Convert_v1alpha2_ResourcePolicyZoneStatus_To_v1alpha1_ResourcePolicyZoneStatus is an autogenerated conversion function.
+func Convert_v1alpha2_ResourcePolicyZoneStatus_To_v1alpha1_ResourcePolicyZoneStatus(in *v1alpha2.ResourcePolicyZoneStatus, out *ResourcePolicyZoneStatus, s conversion.Scope) error {
+	return autoConvert_v1alpha2_ResourcePolicyZoneStatus_To_v1alpha1_ResourcePolicyZoneStatus(in, out, s)
+}
+
 func autoConvert_v1alpha1_ResourcePoolSpec_To_v1alpha2_ResourcePoolSpec(in *ResourcePoolSpec, out *v1alpha2.ResourcePoolSpec, s conversion.Scope) error {
 	out.Name = in.Name
 	if err := Convert_v1alpha1_VirtualMachineResourceSpec_To_v1alpha2_VirtualMachineResourceSpec(&in.Reservations, &out.Reservations, s); err != nil {
@@ -994,6 +1156,74 @@ func Convert_v1alpha2_VirtualMachine_To_v1alpha1_VirtualMachine(in *v1alpha2.Vir
 	return autoConvert_v1alpha2_VirtualMachine_To_v1alpha1_VirtualMachine(in, out, s)
 }
 
+func autoConvert_v1alpha1_VirtualMachineAffinityRules_To_v1alpha2_VirtualMachineAffinityRules(in *VirtualMachineAffinityRules, out *v1alpha2.VirtualMachineAffinityRules, s conversion.Scope) error {
+	out.RequiredDuringSchedulingIgnoredDuringExecution = *(*[]v1alpha2.VirtualMachineAffinityTerm)(unsafe.Pointer(&in.RequiredDuringSchedulingIgnoredDuringExecution))
+	out.RequiredDuringSchedulingRequiredDuringExecution = *(*[]v1alpha2.VirtualMachineAffinityTerm)(unsafe.Pointer(&in.RequiredDuringSchedulingRequiredDuringExecution))
+	out.PreferredDuringSchedulingIgnoredDuringExecution = *(*[]v1alpha2.VirtualMachineWeightedAffinityTerm)(unsafe.Pointer(&in.PreferredDuringSchedulingIgnoredDuringExecution))
+	return nil
+}
+
+// Convert_v1alpha1_VirtualMachineAffinityRules_To_v1alpha2_VirtualMachineAffinityRules is an autogenerated conversion function.
+func Convert_v1alpha1_VirtualMachineAffinityRules_To_v1alpha2_VirtualMachineAffinityRules(in *VirtualMachineAffinityRules, out *v1alpha2.VirtualMachineAffinityRules, s conversion.Scope) error {
+	return autoConvert_v1alpha1_VirtualMachineAffinityRules_To_v1alpha2_VirtualMachineAffinityRules(in, out, s)
+}
+
+func autoConvert_v1alpha2_VirtualMachineAffinityRules_To_v1alpha1_VirtualMachineAffinityRules(in *v1alpha2.VirtualMachineAffinityRules, out *VirtualMachineAffinityRules, s conversion.Scope) error {
+	out.RequiredDuringSchedulingIgnoredDuringExecution = *(*[]VirtualMachineAffinityTerm)(unsafe.Pointer(&in.RequiredDuringSchedulingIgnoredDuringExecution))
+	out.RequiredDuringSchedulingRequiredDuringExecution = *(*[]VirtualMachineAffinityTerm)(unsafe.Pointer(&in.RequiredDuringSchedulingRequiredDuringExecution))
+	out.PreferredDuringSchedulingIgnoredDuringExecution = *(*[]VirtualMachineWeightedAffinityTerm)(unsafe.Pointer(&in.PreferredDuringSchedulingIgnoredDuringExecution))
+	return nil
+}
+
+// Convert_v1alpha2_VirtualMachineAffinityRules_To_v1alpha1_VirtualMachineAffinityRules is an autogenerated conversion function.
+func Convert_v1alpha2_VirtualMachineAffinityRules_To_v1alpha1_VirtualMachineAffinityRules(in *v1alpha2.VirtualMachineAffinityRules, out *VirtualMachineAffinityRules, s conversion.Scope) error {
+	return autoConvert_v1alpha2_VirtualMachineAffinityRules_To_v1alpha1_VirtualMachineAffinityRules(in, out, s)
+}
+
+func autoConvert_v1alpha1_VirtualMachineAffinitySpec_To_v1alpha2_VirtualMachineAffinitySpec(in *VirtualMachineAffinitySpec, out *v1alpha2.VirtualMachineAffinitySpec, s conversion.Scope) error {
+	out.VMAffinity = (*v1alpha2.VirtualMachineAffinityRules)(unsafe.Pointer(in.VMAffinity))
+	out.VMAntiAffinity = (*v1alpha2.VirtualMachineAffinityRules)(unsafe.Pointer(in.VMAntiAffinity))
+	return nil
+}
+
+// Convert_v1alpha1_VirtualMachineAffinitySpec_To_v1alpha2_VirtualMachineAffinitySpec is an autogenerated conversion function.
+func Convert_v1alpha1_VirtualMachineAffinitySpec_To_v1alpha2_VirtualMachineAffinitySpec(in *VirtualMachineAffinitySpec, out *v1alpha2.VirtualMachineAffinitySpec, s conversion.Scope) error {
+	return autoConvert_v1alpha1_VirtualMachineAffinitySpec_To_v1alpha2_VirtualMachineAffinitySpec(in, out, s)
+}
+
+func autoConvert_v1alpha2_VirtualMachineAffinitySpec_To_v1alpha1_VirtualMachineAffinitySpec(in *v1alpha2.VirtualMachineAffinitySpec, out *VirtualMachineAffinitySpec, s conversion.Scope) error {
+	out.VMAffinity = (*VirtualMachineAffinityRules)(unsafe.Pointer(in.VMAffinity))
+	out.VMAntiAffinity = (*VirtualMachineAffinityRules)(unsafe.Pointer(in.VMAntiAffinity))
+	return nil
+}
+
+// Convert_v1alpha2_VirtualMachineAffinitySpec_To_v1alpha1_VirtualMachineAffinitySpec is an autogenerated conversion function.
+func Convert_v1alpha2_VirtualMachineAffinitySpec_To_v1alpha1_VirtualMachineAffinitySpec(in *v1alpha2.VirtualMachineAffinitySpec, out *VirtualMachineAffinitySpec, s conversion.Scope) error {
+	return autoConvert_v1alpha2_VirtualMachineAffinitySpec_To_v1alpha1_VirtualMachineAffinitySpec(in, out, s)
+}
+
+func autoConvert_v1alpha1_VirtualMachineAffinityTerm_To_v1alpha2_VirtualMachineAffinityTerm(in *VirtualMachineAffinityTerm, out *v1alpha2.VirtualMachineAffinityTerm, s conversion.Scope) error {
+	out.LabelSelector = (*v1.LabelSelector)(unsafe.Pointer(in.LabelSelector))
+	out.TopologyKey = in.TopologyKey
+	return nil
+}
+
+// Convert_v1alpha1_VirtualMachineAffinityTerm_To_v1alpha2_VirtualMachineAffinityTerm is an autogenerated conversion function.
+func Convert_v1alpha1_VirtualMachineAffinityTerm_To_v1alpha2_VirtualMachineAffinityTerm(in *VirtualMachineAffinityTerm, out *v1alpha2.VirtualMachineAffinityTerm, s conversion.Scope) error {
+	return autoConvert_v1alpha1_VirtualMachineAffinityTerm_To_v1alpha2_VirtualMachineAffinityTerm(in, out, s)
+}
+
+func autoConvert_v1alpha2_VirtualMachineAffinityTerm_To_v1alpha1_VirtualMachineAffinityTerm(in *v1alpha2.VirtualMachineAffinityTerm, out *VirtualMachineAffinityTerm, s conversion.Scope) error {
+	out.LabelSelector = (*v1.LabelSelector)(unsafe.Pointer(in.LabelSelector))
+	out.TopologyKey = in.TopologyKey
+	return nil
+}
+
+// Convert_v1alpha2_VirtualMachineAffinityTerm_To_v1alpha1_VirtualMachineAffinityTerm is an autogenerated conversion function.
+func Convert_v1alpha2_VirtualMachineAffinityTerm_To_v1alpha1_VirtualMachineAffinityTerm(in *v1alpha2.VirtualMachineAffinityTerm, out *VirtualMachineAffinityTerm, s conversion.Scope) error {
+	return autoConvert_v1alpha2_VirtualMachineAffinityTerm_To_v1alpha1_VirtualMachineAffinityTerm(in, out, s)
+}
+
 func autoConvert_v1alpha1_VirtualMachineClass_To_v1alpha2_VirtualMachineClass(in *VirtualMachineClass, out *v1alpha2.VirtualMachineClass, s conversion.Scope) error {
 	out.ObjectMeta = in.ObjectMeta
 	if err := Convert_v1alpha1_VirtualMachineClassSpec_To_v1alpha2_VirtualMachineClassSpec(&in.Spec, &out.Spec, s); err != nil {
@@ -1156,6 +1386,26 @@ func Convert_v1alpha2_VirtualMachineClassResources_To_v1alpha1_VirtualMachineCla
 	return autoConvert_v1alpha2_VirtualMachineClassResources_To_v1alpha1_VirtualMachineClassResources(in, out, s)
 }
 
+func autoConvert_v1alpha1_VirtualMachineClassRollingRestart_To_v1alpha2_VirtualMachineClassRollingRestart(in *VirtualMachineClassRollingRestart, out *v1alpha2.VirtualMachineClassRollingRestart, s conversion.Scope) error {
+	out.MaxUnavailable = (*int32)(unsafe.Pointer(in.MaxUnavailable))
+	return nil
+}
+
+// Convert_v1alpha1_VirtualMachineClassRollingRestart_To_v1alpha2_VirtualMachineClassRollingRestart is an autogenerated conversion function.
+func Convert_v1alpha1_VirtualMachineClassRollingRestart_To_v1alpha2_VirtualMachineClassRollingRestart(in *VirtualMachineClassRollingRestart, out *v1alpha2.VirtualMachineClassRollingRestart, s conversion.Scope) error {
+	return autoConvert_v1alpha1_VirtualMachineClassRollingRestart_To_v1alpha2_VirtualMachineClassRollingRestart(in, out, s)
+}
+
+func autoConvert_v1alpha2_VirtualMachineClassRollingRestart_To_v1alpha1_VirtualMachineClassRollingRestart(in *v1alpha2.VirtualMachineClassRollingRestart, out *VirtualMachineClassRollingRestart, s conversion.Scope) error {
+	out.MaxUnavailable = (*int32)(unsafe.Pointer(in.MaxUnavailable))
+	return nil
+}
+
+// Convert_v1alpha2_VirtualMachineClassRollingRestart_To_v1alpha1_VirtualMachineClassRollingRestart is an autogenerated conversion function.
+func Convert_v1alpha2_VirtualMachineClassRollingRestart_To_v1alpha1_VirtualMachineClassRollingRestart(in *v1alpha2.VirtualMachineClassRollingRestart, out *VirtualMachineClassRollingRestart, s conversion.Scope) error {
+	return autoConvert_v1alpha2_VirtualMachineClassRollingRestart_To_v1alpha1_VirtualMachineClassRollingRestart(in, out, s)
+}
+
 func autoConvert_v1alpha1_VirtualMachineClassSpec_To_v1alpha2_VirtualMachineClassSpec(in *VirtualMachineClassSpec, out *v1alpha2.VirtualMachineClassSpec, s conversion.Scope) error {
 	if err := Convert_v1alpha1_VirtualMachineClassHardware_To_v1alpha2_VirtualMachineClassHardware(&in.Hardware, &out.Hardware, s); err != nil {
 		return err
@@ -1165,6 +1415,9 @@ func autoConvert_v1alpha1_VirtualMachineClassSpec_To_v1alpha2_VirtualMachineClas
 	}
 	out.Description = in.Description
 	out.ConfigSpec = *(*json.RawMessage)(unsafe.Pointer(&in.ConfigSpec))
+	if err := Convert_v1alpha1_VirtualMachineClassUpdatePolicy_To_v1alpha2_VirtualMachineClassUpdatePolicy(&in.UpdatePolicy, &out.UpdatePolicy, s); err != nil {
+		return err
+	}
 	return nil
 }
 
@@ -1182,6 +1435,9 @@ func autoConvert_v1alpha2_VirtualMachineClassSpec_To_v1alpha1_VirtualMachineClas
 	}
 	out.Description = in.Description
 	out.ConfigSpec = *(*json.RawMessage)(unsafe.Pointer(&in.ConfigSpec))
+	if err := Convert_v1alpha2_VirtualMachineClassUpdatePolicy_To_v1alpha1_VirtualMachineClassUpdatePolicy(&in.UpdatePolicy, &out.UpdatePolicy, s); err != nil {
+		return err
+	}
 	return nil
 }
 
@@ -1191,6 +1447,7 @@ func Convert_v1alpha2_VirtualMachineClassSpec_To_v1alpha1_VirtualMachineClassSpe
 }
 
 func autoConvert_v1alpha1_VirtualMachineClassStatus_To_v1alpha2_VirtualMachineClassStatus(in *VirtualMachineClassStatus, out *v1alpha2.VirtualMachineClassStatus, s conversion.Scope) error {
+	out.EffectiveConfigSpec = *(*json.RawMessage)(unsafe.Pointer(&in.EffectiveConfigSpec))
 	return nil
 }
 
@@ -1203,9 +1460,32 @@ func autoConvert_v1alpha2_VirtualMachineClassStatus_To_v1alpha1_VirtualMachineCl
 	// WARNING: in.Capabilities requires manual conversion: does not exist in peer-type
 	// WARNING: in.Conditions requires manual conversion: does not exist in peer-type
 	// WARNING: in.Ready requires manual conversion: does not exist in peer-type
+	out.EffectiveConfigSpec = *(*json.RawMessage)(unsafe.Pointer(&in.EffectiveConfigSpec))
+	return nil
+}
+
+func autoConvert_v1alpha1_VirtualMachineClassUpdatePolicy_To_v1alpha2_VirtualMachineClassUpdatePolicy(in *VirtualMachineClassUpdatePolicy, out *v1alpha2.VirtualMachineClassUpdatePolicy, s conversion.Scope) error {
+	out.Strategy = v1alpha2.VirtualMachineClassUpdateStrategyType(in.Strategy)
+	out.RollingRestart = (*v1alpha2.VirtualMachineClassRollingRestart)(unsafe.Pointer(in.RollingRestart))
+	return nil
+}
+
+// Convert_v1alpha1_VirtualMachineClassUpdatePolicy_To_v1alpha2_VirtualMachineClassUpdatePolicy is an autogenerated conversion function.
+func Convert_v1alpha1_VirtualMachineClassUpdatePolicy_To_v1alpha2_VirtualMachineClassUpdatePolicy(in *VirtualMachineClassUpdatePolicy, out *v1alpha2.VirtualMachineClassUpdatePolicy, s conversion.Scope) error {
+	return autoConvert_v1alpha1_VirtualMachineClassUpdatePolicy_To_v1alpha2_VirtualMachineClassUpdatePolicy(in, out, s)
+}
+
+func autoConvert_v1alpha2_VirtualMachineClassUpdatePolicy_To_v1alpha1_VirtualMachineClassUpdatePolicy(in *v1alpha2.VirtualMachineClassUpdatePolicy, out *VirtualMachineClassUpdatePolicy, s conversion.Scope) error {
+	out.Strategy = VirtualMachineClassUpdateStrategyType(in.Strategy)
+	out.RollingRestart = (*VirtualMachineClassRollingRestart)(unsafe.Pointer(in.RollingRestart))
 	return nil
 }
 
+// Convert_v1alpha2_VirtualMachineClassUpdatePolicy_To_v1alpha1_VirtualMachineClassUpdatePolicy is an autogenerated conversion function.
+func Convert_v1alpha2_VirtualMachineClassUpdatePolicy_To_v1alpha1_VirtualMachineClassUpdatePolicy(in *v1alpha2.VirtualMachineClassUpdatePolicy, out *VirtualMachineClassUpdatePolicy, s conversion.Scope) error {
+	return autoConvert_v1alpha2_VirtualMachineClassUpdatePolicy_To_v1alpha1_VirtualMachineClassUpdatePolicy(in, out, s)
+}
+
 func autoConvert_v1alpha1_VirtualMachineImage_To_v1alpha2_VirtualMachineImage(in *VirtualMachineImage, out *v1alpha2.VirtualMachineImage, s conversion.Scope) error {
 	out.ObjectMeta = in.ObjectMeta
 	if err := Convert_v1alpha1_VirtualMachineImageSpec_To_v1alpha2_VirtualMachineImageSpec(&in.Spec, &out.Spec, s); err != nil {
@@ -1370,6 +1650,7 @@ func autoConvert_v1alpha1_VirtualMachineImageStatus_To_v1alpha2_VirtualMachineIm
 	// WARNING: in.ContentLibraryRef requires manual conversion: does not exist in peer-type
 	// WARNING: in.ContentVersion requires manual conversion: does not exist in peer-type
 	out.Firmware = in.Firmware
+	out.TrustPolicyHash = in.TrustPolicyHash
 	return nil
 }
 
@@ -1382,6 +1663,7 @@ func autoConvert_v1alpha2_VirtualMachineImageStatus_To_v1alpha1_VirtualMachineIm
 	// WARNING: in.OVFProperties requires manual conversion: does not exist in peer-type
 	// WARNING: in.ProductInfo requires manual conversion: does not exist in peer-type
 	// WARNING: in.ProviderContentVersion requires manual conversion: does not exist in peer-type
+	out.TrustPolicyHash = in.TrustPolicyHash
 	if in.Conditions != nil {
 		in, out := &in.Conditions, &out.Conditions
 		*out = make([]Condition, len(*in))
@@ -1512,6 +1794,28 @@ func Convert_v1alpha2_VirtualMachinePublishRequestList_To_v1alpha1_VirtualMachin
 	return autoConvert_v1alpha2_VirtualMachinePublishRequestList_To_v1alpha1_VirtualMachinePublishRequestList(in, out, s)
 }
 
+func autoConvert_v1alpha1_VirtualMachinePublishRequestOCIArtifactStatus_To_v1alpha2_VirtualMachinePublishRequestOCIArtifactStatus(in *VirtualMachinePublishRequestOCIArtifactStatus, out *v1alpha2.VirtualMachinePublishRequestOCIArtifactStatus, s conversion.Scope) error {
+	out.Reference = in.Reference
+	out.Digest = in.Digest
+	return nil
+}
+
+// Convert_v1alpha1_VirtualMachinePublishRequestOCIArtifactStatus_To_v1alpha2_VirtualMachinePublishRequestOCIArtifactStatus is an autogenerated conversion function.
+func Convert_v1alpha1_VirtualMachinePublishRequestOCIArtifactStatus_To_v1alpha2_VirtualMachinePublishRequestOCIArtifactStatus(in *VirtualMachinePublishRequestOCIArtifactStatus, out *v1alpha2.VirtualMachinePublishRequestOCIArtifactStatus, s conversion.Scope) error {
+	return autoConvert_v1alpha1_VirtualMachinePublishRequestOCIArtifactStatus_To_v1alpha2_VirtualMachinePublishRequestOCIArtifactStatus(in, out, s)
+}
+
+func autoConvert_v1alpha2_VirtualMachinePublishRequestOCIArtifactStatus_To_v1alpha1_VirtualMachinePublishRequestOCIArtifactStatus(in *v1alpha2.VirtualMachinePublishRequestOCIArtifactStatus, out *VirtualMachinePublishRequestOCIArtifactStatus, s conversion.Scope) error {
+	out.Reference = in.Reference
+	out.Digest = in.Digest
+	return nil
+}
+
+// Convert_v1alpha2_VirtualMachinePublishRequestOCIArtifactStatus_To_v1alpha1_VirtualMachinePublishRequestOCIArtifactStatus is an autogenerated conversion function.
+func Convert_v1alpha2_VirtualMachinePublishRequestOCIArtifactStatus_To_v1alpha1_VirtualMachinePublishRequestOCIArtifactStatus(in *v1alpha2.VirtualMachinePublishRequestOCIArtifactStatus, out *VirtualMachinePublishRequestOCIArtifactStatus, s conversion.Scope) error {
+	return autoConvert_v1alpha2_VirtualMachinePublishRequestOCIArtifactStatus_To_v1alpha1_VirtualMachinePublishRequestOCIArtifactStatus(in, out, s)
+}
+
 func autoConvert_v1alpha1_VirtualMachinePublishRequestSource_To_v1alpha2_VirtualMachinePublishRequestSource(in *VirtualMachinePublishRequestSource, out *v1alpha2.VirtualMachinePublishRequestSource, s conversion.Scope) error {
 	out.Name = in.Name
 	out.APIVersion = in.APIVersion
@@ -1576,6 +1880,7 @@ func autoConvert_v1alpha1_VirtualMachinePublishRequestStatus_To_v1alpha2_Virtual
 	out.Attempts = in.Attempts
 	out.LastAttemptTime = in.LastAttemptTime
 	out.ImageName = in.ImageName
+	out.OCIArtifact = (*v1alpha2.VirtualMachinePublishRequestOCIArtifactStatus)(unsafe.Pointer(in.OCIArtifact))
 	out.Ready = in.Ready
 	if in.Conditions != nil {
 		in, out := &in.Conditions, &out.Conditions
@@ -1604,6 +1909,7 @@ func autoConvert_v1alpha2_VirtualMachinePublishRequestStatus_To_v1alpha1_Virtual
 	out.Attempts = in.Attempts
 	out.LastAttemptTime = in.LastAttemptTime
 	out.ImageName = in.ImageName
+	out.OCIArtifact = (*VirtualMachinePublishRequestOCIArtifactStatus)(unsafe.Pointer(in.OCIArtifact))
 	out.Ready = in.Ready
 	if in.Conditions != nil {
 		in, out := &in.Conditions, &out.Conditions
@@ -1680,6 +1986,7 @@ func autoConvert_v1alpha1_VirtualMachinePublishRequestTargetLocation_To_v1alpha2
 	out.Name = in.Name
 	out.APIVersion = in.APIVersion
 	out.Kind = in.Kind
+	out.OCI = (*v1alpha2.VirtualMachinePublishRequestTargetOCILocation)(unsafe.Pointer(in.OCI))
 	return nil
 }
 
@@ -1692,6 +1999,7 @@ func autoConvert_v1alpha2_VirtualMachinePublishRequestTargetLocation_To_v1alpha1
 	out.Name = in.Name
 	out.APIVersion = in.APIVersion
 	out.Kind = in.Kind
+	out.OCI = (*VirtualMachinePublishRequestTargetOCILocation)(unsafe.Pointer(in.OCI))
 	return nil
 }
 
@@ -1700,6 +2008,30 @@ func Convert_v1alpha2_VirtualMachinePublishRequestTargetLocation_To_v1alpha1_Vir
 	return autoConvert_v1alpha2_VirtualMachinePublishRequestTargetLocation_To_v1alpha1_VirtualMachinePublishRequestTargetLocation(in, out, s)
 }
 
+func autoConvert_v1alpha1_VirtualMachinePublishRequestTargetOCILocation_To_v1alpha2_VirtualMachinePublishRequestTargetOCILocation(in *VirtualMachinePublishRequestTargetOCILocation, out *v1alpha2.VirtualMachinePublishRequestTargetOCILocation, s conversion.Scope) error {
+	out.Repository = in.Repository
+	out.SecretName = in.SecretName
+	out.InsecureSkipTLSVerify = in.InsecureSkipTLSVerify
+	return nil
+}
+
+// Convert_v1alpha1_VirtualMachinePublishRequestTargetOCILocation_To_v1alpha2_VirtualMachinePublishRequestTargetOCILocation is an autogenerated conversion function.
+func Convert_v1alpha1_VirtualMachinePublishRequestTargetOCILocation_To_v1alpha2_VirtualMachinePublishRequestTargetOCILocation(in *VirtualMachinePublishRequestTargetOCILocation, out *v1alpha2.VirtualMachinePublishRequestTargetOCILocation, s conversion.Scope) error {
+	return autoConvert_v1alpha1_VirtualMachinePublishRequestTargetOCILocation_To_v1alpha2_VirtualMachinePublishRequestTargetOCILocation(in, out, s)
+}
+
+func autoConvert_v1alpha2_VirtualMachinePublishRequestTargetOCILocation_To_v1alpha1_VirtualMachinePublishRequestTargetOCILocation(in *v1alpha2.VirtualMachinePublishRequestTargetOCILocation, out *VirtualMachinePublishRequestTargetOCILocation, s conversion.Scope) error {
+	out.Repository = in.Repository
+	out.SecretName = in.SecretName
+	out.InsecureSkipTLSVerify = in.InsecureSkipTLSVerify
+	return nil
+}
+
+// Convert_v1alpha2_VirtualMachinePublishRequestTargetOCILocation_To_v1alpha1_VirtualMachinePublishRequestTargetOCILocation is an autogenerated conversion function.
+func Convert_v1alpha2_VirtualMachinePublishRequestTargetOCILocation_To_v1alpha1_VirtualMachinePublishRequestTargetOCILocation(in *v1alpha2.VirtualMachinePublishRequestTargetOCILocation, out *VirtualMachinePublishRequestTargetOCILocation, s conversion.Scope) error {
+	return autoConvert_v1alpha2_VirtualMachinePublishRequestTargetOCILocation_To_v1alpha1_VirtualMachinePublishRequestTargetOCILocation(in, out, s)
+}
+
 func autoConvert_v1alpha1_VirtualMachineResourceSpec_To_v1alpha2_VirtualMachineResourceSpec(in *VirtualMachineResourceSpec, out *v1alpha2.VirtualMachineResourceSpec, s conversion.Scope) error {
 	out.Cpu = in.Cpu
 	out.Memory = in.Memory
@@ -1952,6 +2284,28 @@ func autoConvert_v1alpha2_VirtualMachineSetResourcePolicySpec_To_v1alpha1_Virtua
 
 func autoConvert_v1alpha1_VirtualMachineSetResourcePolicyStatus_To_v1alpha2_VirtualMachineSetResourcePolicyStatus(in *VirtualMachineSetResourcePolicyStatus, out *v1alpha2.VirtualMachineSetResourcePolicyStatus, s conversion.Scope) error {
 	out.ClusterModules = *(*[]v1alpha2.VSphereClusterModuleStatus)(unsafe.Pointer(&in.ClusterModules))
+	if in.Zones != nil {
+		in, out := &in.Zones, &out.Zones
+		*out = make([]v1alpha2.ResourcePolicyZoneStatus, len(*in))
+		for i := range *in {
+			if err := Convert_v1alpha1_ResourcePolicyZoneStatus_To_v1alpha2_ResourcePolicyZoneStatus(&(*in)[i], &(*out)[i], s); err != nil {
+				return err
+			}
+		}
+	} else {
+		out.Zones = nil
+	}
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]v1.Condition, len(*in))
+		for i := range *in {
+			if err := Convert_v1alpha1_Condition_To_v1_Condition(&(*in)[i], &(*out)[i], s); err != nil {
+				return err
+			}
+		}
+	} else {
+		out.Conditions = nil
+	}
 	return nil
 }
 
@@ -1962,6 +2316,28 @@ func Convert_v1alpha1_VirtualMachineSetResourcePolicyStatus_To_v1alpha2_VirtualM
 
 func autoConvert_v1alpha2_VirtualMachineSetResourcePolicyStatus_To_v1alpha1_VirtualMachineSetResourcePolicyStatus(in *v1alpha2.VirtualMachineSetResourcePolicyStatus, out *VirtualMachineSetResourcePolicyStatus, s conversion.Scope) error {
 	out.ClusterModules = *(*[]ClusterModuleStatus)(unsafe.Pointer(&in.ClusterModules))
+	if in.Zones != nil {
+		in, out := &in.Zones, &out.Zones
+		*out = make([]ResourcePolicyZoneStatus, len(*in))
+		for i := range *in {
+			if err := Convert_v1alpha2_ResourcePolicyZoneStatus_To_v1alpha1_ResourcePolicyZoneStatus(&(*in)[i], &(*out)[i], s); err != nil {
+				return err
+			}
+		}
+	} else {
+		out.Zones = nil
+	}
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]Condition, len(*in))
+		for i := range *in {
+			if err := Convert_v1_Condition_To_v1alpha1_Condition(&(*in)[i], &(*out)[i], s); err != nil {
+				return err
+			}
+		}
+	} else {
+		out.Conditions = nil
+	}
 	return nil
 }
 
@@ -1992,6 +2368,8 @@ func autoConvert_v1alpha1_VirtualMachineSpec_To_v1alpha2_VirtualMachineSpec(in *
 	}
 	// WARNING: in.ReadinessProbe requires manual conversion: inconvertible types (*github.com/vmware-tanzu/vm-operator/api/v1alpha1.Probe vs github.com/vmware-tanzu/vm-operator/api/v1alpha2.VirtualMachineReadinessProbeSpec)
 	// WARNING: in.AdvancedOptions requires manual conversion: does not exist in peer-type
+	out.Affinity = (*v1alpha2.VirtualMachineAffinitySpec)(unsafe.Pointer(in.Affinity))
+	out.TopologySpreadConstraints = *(*[]v1alpha2.VirtualMachineTopologySpreadConstraint)(unsafe.Pointer(&in.TopologySpreadConstraints))
 	return nil
 }
 
@@ -2016,6 +2394,8 @@ func autoConvert_v1alpha2_VirtualMachineSpec_To_v1alpha1_VirtualMachineSpec(in *
 	// WARNING: in.ReadinessProbe requires manual conversion: inconvertible types (github.com/vmware-tanzu/vm-operator/api/v1alpha2.VirtualMachineReadinessProbeSpec vs *github.com/vmware-tanzu/vm-operator/api/v1alpha1.Probe)
 	// WARNING: in.ReadinessGates requires manual conversion: does not exist in peer-type
 	// WARNING: in.Advanced requires manual conversion: does not exist in peer-type
+	out.Affinity = (*VirtualMachineAffinitySpec)(unsafe.Pointer(in.Affinity))
+	out.TopologySpreadConstraints = *(*[]VirtualMachineTopologySpreadConstraint)(unsafe.Pointer(&in.TopologySpreadConstraints))
 	// WARNING: in.Reserved requires manual conversion: does not exist in peer-type
 	return nil
 }
@@ -2053,6 +2433,8 @@ func autoConvert_v1alpha1_VirtualMachineStatus_To_v1alpha2_VirtualMachineStatus(
 	out.ChangeBlockTracking = (*bool)(unsafe.Pointer(in.ChangeBlockTracking))
 	// WARNING: in.NetworkInterfaces requires manual conversion: does not exist in peer-type
 	out.Zone = in.Zone
+	out.ClassGeneration = in.ClassGeneration
+	out.LastTask = (*v1alpha2.VirtualMachineTaskInfo)(unsafe.Pointer(in.LastTask))
 	return nil
 }
 
@@ -2089,9 +2471,67 @@ func autoConvert_v1alpha2_VirtualMachineStatus_To_v1alpha1_VirtualMachineStatus(
 	}
 	out.ChangeBlockTracking = (*bool)(unsafe.Pointer(in.ChangeBlockTracking))
 	out.Zone = in.Zone
+	out.ClassGeneration = in.ClassGeneration
+	out.LastTask = (*VirtualMachineTaskInfo)(unsafe.Pointer(in.LastTask))
+	return nil
+}
+
+func autoConvert_v1alpha1_VirtualMachineTaskFault_To_v1alpha2_VirtualMachineTaskFault(in *VirtualMachineTaskFault, out *v1alpha2.VirtualMachineTaskFault, s conversion.Scope) error {
+	out.Type = in.Type
+	out.Message = in.Message
+	return nil
+}
+
+// Convert_v1alpha1_VirtualMachineTaskFault_To_v1alpha2_VirtualMachineTaskFault is an autogenerated conversion function.
+func Convert_v1alpha1_VirtualMachineTaskFault_To_v1alpha2_VirtualMachineTaskFault(in *VirtualMachineTaskFault, out *v1alpha2.VirtualMachineTaskFault, s conversion.Scope) error {
+	return autoConvert_v1alpha1_VirtualMachineTaskFault_To_v1alpha2_VirtualMachineTaskFault(in, out, s)
+}
+
+func autoConvert_v1alpha2_VirtualMachineTaskFault_To_v1alpha1_VirtualMachineTaskFault(in *v1alpha2.VirtualMachineTaskFault, out *VirtualMachineTaskFault, s conversion.Scope) error {
+	out.Type = in.Type
+	out.Message = in.Message
+	return nil
+}
+
+// Convert_v1alpha2_VirtualMachineTaskFault_To_v1alpha1_VirtualMachineTaskFault is an autogenerated conversion function.
+func Convert_v1alpha2_VirtualMachineTaskFault_To_v1alpha1_VirtualMachineTaskFault(in *v1alpha2.VirtualMachineTaskFault, out *VirtualMachineTaskFault, s conversion.Scope) error {
+	return autoConvert_v1alpha2_VirtualMachineTaskFault_To_v1alpha1_VirtualMachineTaskFault(in, out, s)
+}
+
+func autoConvert_v1alpha1_VirtualMachineTaskInfo_To_v1alpha2_VirtualMachineTaskInfo(in *VirtualMachineTaskInfo, out *v1alpha2.VirtualMachineTaskInfo, s conversion.Scope) error {
+	out.TaskRef = in.TaskRef
+	out.DescriptionID = in.DescriptionID
+	out.Description = in.Description
+	out.State = v1alpha2.VirtualMachineTaskState(in.State)
+	out.Progress = in.Progress
+	out.StartTime = (*v1.Time)(unsafe.Pointer(in.StartTime))
+	out.CompleteTime = (*v1.Time)(unsafe.Pointer(in.CompleteTime))
+	out.Fault = (*v1alpha2.VirtualMachineTaskFault)(unsafe.Pointer(in.Fault))
 	return nil
 }
 
+// Convert_v1alpha1_VirtualMachineTaskInfo_To_v1alpha2_VirtualMachineTaskInfo is an autogenerated conversion function.
+func Convert_v1alpha1_VirtualMachineTaskInfo_To_v1alpha2_VirtualMachineTaskInfo(in *VirtualMachineTaskInfo, out *v1alpha2.VirtualMachineTaskInfo, s conversion.Scope) error {
+	return autoConvert_v1alpha1_VirtualMachineTaskInfo_To_v1alpha2_VirtualMachineTaskInfo(in, out, s)
+}
+
+func autoConvert_v1alpha2_VirtualMachineTaskInfo_To_v1alpha1_VirtualMachineTaskInfo(in *v1alpha2.VirtualMachineTaskInfo, out *VirtualMachineTaskInfo, s conversion.Scope) error {
+	out.TaskRef = in.TaskRef
+	out.DescriptionID = in.DescriptionID
+	out.Description = in.Description
+	out.State = VirtualMachineTaskState(in.State)
+	out.Progress = in.Progress
+	out.StartTime = (*v1.Time)(unsafe.Pointer(in.StartTime))
+	out.CompleteTime = (*v1.Time)(unsafe.Pointer(in.CompleteTime))
+	out.Fault = (*VirtualMachineTaskFault)(unsafe.Pointer(in.Fault))
+	return nil
+}
+
+// Convert_v1alpha2_VirtualMachineTaskInfo_To_v1alpha1_VirtualMachineTaskInfo is an autogenerated conversion function.
+func Convert_v1alpha2_VirtualMachineTaskInfo_To_v1alpha1_VirtualMachineTaskInfo(in *v1alpha2.VirtualMachineTaskInfo, out *VirtualMachineTaskInfo, s conversion.Scope) error {
+	return autoConvert_v1alpha2_VirtualMachineTaskInfo_To_v1alpha1_VirtualMachineTaskInfo(in, out, s)
+}
+
 func autoConvert_v1alpha1_VirtualMachineTemplate_To_v1alpha2_VirtualMachineTemplate(in *VirtualMachineTemplate, out *v1alpha2.VirtualMachineTemplate, s conversion.Scope) error {
 	if err := Convert_v1alpha1_NetworkStatus_To_v1alpha2_NetworkStatus(&in.Net, &out.Net, s); err != nil {
 		return err
@@ -2134,6 +2574,32 @@ func Convert_v1alpha2_VirtualMachineTemplate_To_v1alpha1_VirtualMachineTemplate(
 	return autoConvert_v1alpha2_VirtualMachineTemplate_To_v1alpha1_VirtualMachineTemplate(in, out, s)
 }
 
+func autoConvert_v1alpha1_VirtualMachineTopologySpreadConstraint_To_v1alpha2_VirtualMachineTopologySpreadConstraint(in *VirtualMachineTopologySpreadConstraint, out *v1alpha2.VirtualMachineTopologySpreadConstraint, s conversion.Scope) error {
+	out.MaxSkew = in.MaxSkew
+	out.TopologyKey = in.TopologyKey
+	out.WhenUnsatisfiable = v1alpha2.VirtualMachineUnsatisfiableConstraintAction(in.WhenUnsatisfiable)
+	out.LabelSelector = (*v1.LabelSelector)(unsafe.Pointer(in.LabelSelector))
+	return nil
+}
+
+// Convert_v1alpha1_VirtualMachineTopologySpreadConstraint_To_v1alpha2_VirtualMachineTopologySpreadConstraint is an autogenerated conversion function.
+func Convert_v1alpha1_VirtualMachineTopologySpreadConstraint_To_v1alpha2_VirtualMachineTopologySpreadConstraint(in *VirtualMachineTopologySpreadConstraint, out *v1alpha2.VirtualMachineTopologySpreadConstraint, s conversion.Scope) error {
+	return autoConvert_v1alpha1_VirtualMachineTopologySpreadConstraint_To_v1alpha2_VirtualMachineTopologySpreadConstraint(in, out, s)
+}
+
+func autoConvert_v1alpha2_VirtualMachineTopologySpreadConstraint_To_v1alpha1_VirtualMachineTopologySpreadConstraint(in *v1alpha2.VirtualMachineTopologySpreadConstraint, out *VirtualMachineTopologySpreadConstraint, s conversion.Scope) error {
+	out.MaxSkew = in.MaxSkew
+	out.TopologyKey = in.TopologyKey
+	out.WhenUnsatisfiable = VirtualMachineUnsatisfiableConstraintAction(in.WhenUnsatisfiable)
+	out.LabelSelector = (*v1.LabelSelector)(unsafe.Pointer(in.LabelSelector))
+	return nil
+}
+
+// Convert_v1alpha2_VirtualMachineTopologySpreadConstraint_To_v1alpha1_VirtualMachineTopologySpreadConstraint is an autogenerated conversion function.
+func Convert_v1alpha2_VirtualMachineTopologySpreadConstraint_To_v1alpha1_VirtualMachineTopologySpreadConstraint(in *v1alpha2.VirtualMachineTopologySpreadConstraint, out *VirtualMachineTopologySpreadConstraint, s conversion.Scope) error {
+	return autoConvert_v1alpha2_VirtualMachineTopologySpreadConstraint_To_v1alpha1_VirtualMachineTopologySpreadConstraint(in, out, s)
+}
+
 func autoConvert_v1alpha1_VirtualMachineVolume_To_v1alpha2_VirtualMachineVolume(in *VirtualMachineVolume, out *v1alpha2.VirtualMachineVolume, s conversion.Scope) error {
 	out.Name = in.Name
 	// WARNING: in.PersistentVolumeClaim requires manual conversion: does not exist in peer-type
@@ -2174,3 +2640,29 @@ func autoConvert_v1alpha2_VirtualMachineVolumeStatus_To_v1alpha1_VirtualMachineV
 	out.Error = in.Error
 	return nil
 }
+
+func autoConvert_v1alpha1_VirtualMachineWeightedAffinityTerm_To_v1alpha2_VirtualMachineWeightedAffinityTerm(in *VirtualMachineWeightedAffinityTerm, out *v1alpha2.VirtualMachineWeightedAffinityTerm, s conversion.Scope) error {
+	out.Weight = in.Weight
+	if err := Convert_v1alpha1_VirtualMachineAffinityTerm_To_v1alpha2_VirtualMachineAffinityTerm(&in.AffinityTerm, &out.AffinityTerm, s); err != nil {
+		return err
+	}
+	return nil
+}
+
+// Convert_v1alpha1_VirtualMachineWeightedAffinityTerm_To_v1alpha2_VirtualMachineWeightedAffinityTerm is an autogenerated conversion function.
+func Convert_v1alpha1_VirtualMachineWeightedAffinityTerm_To_v1alpha2_VirtualMachineWeightedAffinityTerm(in *VirtualMachineWeightedAffinityTerm, out *v1alpha2.VirtualMachineWeightedAffinityTerm, s conversion.Scope) error {
+	return autoConvert_v1alpha1_VirtualMachineWeightedAffinityTerm_To_v1alpha2_VirtualMachineWeightedAffinityTerm(in, out, s)
+}
+
+func autoConvert_v1alpha2_VirtualMachineWeightedAffinityTerm_To_v1alpha1_VirtualMachineWeightedAffinityTerm(in *v1alpha2.VirtualMachineWeightedAffinityTerm, out *VirtualMachineWeightedAffinityTerm, s conversion.Scope) error {
+	out.Weight = in.Weight
+	if err := Convert_v1alpha2_VirtualMachineAffinityTerm_To_v1alpha1_VirtualMachineAffinityTerm(&in.AffinityTerm, &out.AffinityTerm, s); err != nil {
+		return err
+	}
+	return nil
+}
+
+// Convert_v1alpha2_VirtualMachineWeightedAffinityTerm_To_v1alpha1_VirtualMachineWeightedAffinityTerm is an autogenerated conversion function.
+func Convert_v1alpha2_VirtualMachineWeightedAffinityTerm_To_v1alpha1_VirtualMachineWeightedAffinityTerm(in *v1alpha2.VirtualMachineWeightedAffinityTerm, out *VirtualMachineWeightedAffinityTerm, s conversion.Scope) error {
+	return autoConvert_v1alpha2_VirtualMachineWeightedAffinityTerm_To_v1alpha1_VirtualMachineWeightedAffinityTerm(in, out, s)
+}
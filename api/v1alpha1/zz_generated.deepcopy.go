@@ -10,7 +10,8 @@ package v1alpha1
 
 import (
 	"encoding/json"
-	"k8s.io/api/core/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1"
 	runtime "k8s.io/apimachinery/pkg/runtime"
 )
 
@@ -47,6 +48,11 @@ func (in *ClusterModuleSpec) DeepCopy() *ClusterModuleSpec {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ClusterModuleStatus) DeepCopyInto(out *ClusterModuleStatus) {
 	*out = *in
+	if in.Members != nil {
+		in, out := &in.Members, &out.Members
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterModuleStatus.
@@ -704,6 +710,28 @@ func (in *Probe) DeepCopy() *Probe {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ResourcePolicyZoneStatus) DeepCopyInto(out *ResourcePolicyZoneStatus) {
+	*out = *in
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ResourcePolicyZoneStatus.
+func (in *ResourcePolicyZoneStatus) DeepCopy() *ResourcePolicyZoneStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(ResourcePolicyZoneStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ResourcePoolSpec) DeepCopyInto(out *ResourcePoolSpec) {
 	*out = *in
@@ -721,6 +749,96 @@ func (in *ResourcePoolSpec) DeepCopy() *ResourcePoolSpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SerialConsoleRequest) DeepCopyInto(out *SerialConsoleRequest) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	out.Spec = in.Spec
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SerialConsoleRequest.
+func (in *SerialConsoleRequest) DeepCopy() *SerialConsoleRequest {
+	if in == nil {
+		return nil
+	}
+	out := new(SerialConsoleRequest)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *SerialConsoleRequest) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SerialConsoleRequestList) DeepCopyInto(out *SerialConsoleRequestList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]SerialConsoleRequest, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SerialConsoleRequestList.
+func (in *SerialConsoleRequestList) DeepCopy() *SerialConsoleRequestList {
+	if in == nil {
+		return nil
+	}
+	out := new(SerialConsoleRequestList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *SerialConsoleRequestList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SerialConsoleRequestSpec) DeepCopyInto(out *SerialConsoleRequestSpec) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SerialConsoleRequestSpec.
+func (in *SerialConsoleRequestSpec) DeepCopy() *SerialConsoleRequestSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(SerialConsoleRequestSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SerialConsoleRequestStatus) DeepCopyInto(out *SerialConsoleRequestStatus) {
+	*out = *in
+	in.ExpiryTime.DeepCopyInto(&out.ExpiryTime)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SerialConsoleRequestStatus.
+func (in *SerialConsoleRequestStatus) DeepCopy() *SerialConsoleRequestStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(SerialConsoleRequestStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *TCPSocketAction) DeepCopyInto(out *TCPSocketAction) {
 	*out = *in
@@ -829,13 +947,94 @@ func (in *VirtualMachineAdvancedOptions) DeepCopy() *VirtualMachineAdvancedOptio
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VirtualMachineAffinityRules) DeepCopyInto(out *VirtualMachineAffinityRules) {
+	*out = *in
+	if in.RequiredDuringSchedulingIgnoredDuringExecution != nil {
+		in, out := &in.RequiredDuringSchedulingIgnoredDuringExecution, &out.RequiredDuringSchedulingIgnoredDuringExecution
+		*out = make([]VirtualMachineAffinityTerm, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.RequiredDuringSchedulingRequiredDuringExecution != nil {
+		in, out := &in.RequiredDuringSchedulingRequiredDuringExecution, &out.RequiredDuringSchedulingRequiredDuringExecution
+		*out = make([]VirtualMachineAffinityTerm, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.PreferredDuringSchedulingIgnoredDuringExecution != nil {
+		in, out := &in.PreferredDuringSchedulingIgnoredDuringExecution, &out.PreferredDuringSchedulingIgnoredDuringExecution
+		*out = make([]VirtualMachineWeightedAffinityTerm, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VirtualMachineAffinityRules.
+func (in *VirtualMachineAffinityRules) DeepCopy() *VirtualMachineAffinityRules {
+	if in == nil {
+		return nil
+	}
+	out := new(VirtualMachineAffinityRules)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VirtualMachineAffinitySpec) DeepCopyInto(out *VirtualMachineAffinitySpec) {
+	*out = *in
+	if in.VMAffinity != nil {
+		in, out := &in.VMAffinity, &out.VMAffinity
+		*out = new(VirtualMachineAffinityRules)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.VMAntiAffinity != nil {
+		in, out := &in.VMAntiAffinity, &out.VMAntiAffinity
+		*out = new(VirtualMachineAffinityRules)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VirtualMachineAffinitySpec.
+func (in *VirtualMachineAffinitySpec) DeepCopy() *VirtualMachineAffinitySpec {
+	if in == nil {
+		return nil
+	}
+	out := new(VirtualMachineAffinitySpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VirtualMachineAffinityTerm) DeepCopyInto(out *VirtualMachineAffinityTerm) {
+	*out = *in
+	if in.LabelSelector != nil {
+		in, out := &in.LabelSelector, &out.LabelSelector
+		*out = new(v1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VirtualMachineAffinityTerm.
+func (in *VirtualMachineAffinityTerm) DeepCopy() *VirtualMachineAffinityTerm {
+	if in == nil {
+		return nil
+	}
+	out := new(VirtualMachineAffinityTerm)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *VirtualMachineClass) DeepCopyInto(out *VirtualMachineClass) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
 	in.Spec.DeepCopyInto(&out.Spec)
-	out.Status = in.Status
+	in.Status.DeepCopyInto(&out.Status)
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VirtualMachineClass.
@@ -997,6 +1196,26 @@ func (in *VirtualMachineClassResources) DeepCopy() *VirtualMachineClassResources
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VirtualMachineClassRollingRestart) DeepCopyInto(out *VirtualMachineClassRollingRestart) {
+	*out = *in
+	if in.MaxUnavailable != nil {
+		in, out := &in.MaxUnavailable, &out.MaxUnavailable
+		*out = new(int32)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VirtualMachineClassRollingRestart.
+func (in *VirtualMachineClassRollingRestart) DeepCopy() *VirtualMachineClassRollingRestart {
+	if in == nil {
+		return nil
+	}
+	out := new(VirtualMachineClassRollingRestart)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *VirtualMachineClassSpec) DeepCopyInto(out *VirtualMachineClassSpec) {
 	*out = *in
@@ -1007,6 +1226,7 @@ func (in *VirtualMachineClassSpec) DeepCopyInto(out *VirtualMachineClassSpec) {
 		*out = make(json.RawMessage, len(*in))
 		copy(*out, *in)
 	}
+	in.UpdatePolicy.DeepCopyInto(&out.UpdatePolicy)
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VirtualMachineClassSpec.
@@ -1022,6 +1242,11 @@ func (in *VirtualMachineClassSpec) DeepCopy() *VirtualMachineClassSpec {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *VirtualMachineClassStatus) DeepCopyInto(out *VirtualMachineClassStatus) {
 	*out = *in
+	if in.EffectiveConfigSpec != nil {
+		in, out := &in.EffectiveConfigSpec, &out.EffectiveConfigSpec
+		*out = make(json.RawMessage, len(*in))
+		copy(*out, *in)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VirtualMachineClassStatus.
@@ -1034,6 +1259,26 @@ func (in *VirtualMachineClassStatus) DeepCopy() *VirtualMachineClassStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VirtualMachineClassUpdatePolicy) DeepCopyInto(out *VirtualMachineClassUpdatePolicy) {
+	*out = *in
+	if in.RollingRestart != nil {
+		in, out := &in.RollingRestart, &out.RollingRestart
+		*out = new(VirtualMachineClassRollingRestart)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VirtualMachineClassUpdatePolicy.
+func (in *VirtualMachineClassUpdatePolicy) DeepCopy() *VirtualMachineClassUpdatePolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(VirtualMachineClassUpdatePolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *VirtualMachineImage) DeepCopyInto(out *VirtualMachineImage) {
 	*out = *in
@@ -1062,31 +1307,26 @@ func (in *VirtualMachineImage) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *VirtualMachineImageList) DeepCopyInto(out *VirtualMachineImageList) {
+func (in *VirtualMachineImageImportRequest) DeepCopyInto(out *VirtualMachineImageImportRequest) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
-	in.ListMeta.DeepCopyInto(&out.ListMeta)
-	if in.Items != nil {
-		in, out := &in.Items, &out.Items
-		*out = make([]VirtualMachineImage, len(*in))
-		for i := range *in {
-			(*in)[i].DeepCopyInto(&(*out)[i])
-		}
-	}
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VirtualMachineImageList.
-func (in *VirtualMachineImageList) DeepCopy() *VirtualMachineImageList {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VirtualMachineImageImportRequest.
+func (in *VirtualMachineImageImportRequest) DeepCopy() *VirtualMachineImageImportRequest {
 	if in == nil {
 		return nil
 	}
-	out := new(VirtualMachineImageList)
+	out := new(VirtualMachineImageImportRequest)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *VirtualMachineImageList) DeepCopyObject() runtime.Object {
+func (in *VirtualMachineImageImportRequest) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	}
@@ -1094,68 +1334,99 @@ func (in *VirtualMachineImageList) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *VirtualMachineImageOSInfo) DeepCopyInto(out *VirtualMachineImageOSInfo) {
+func (in *VirtualMachineImageImportRequestChecksum) DeepCopyInto(out *VirtualMachineImageImportRequestChecksum) {
 	*out = *in
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VirtualMachineImageOSInfo.
-func (in *VirtualMachineImageOSInfo) DeepCopy() *VirtualMachineImageOSInfo {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VirtualMachineImageImportRequestChecksum.
+func (in *VirtualMachineImageImportRequestChecksum) DeepCopy() *VirtualMachineImageImportRequestChecksum {
 	if in == nil {
 		return nil
 	}
-	out := new(VirtualMachineImageOSInfo)
+	out := new(VirtualMachineImageImportRequestChecksum)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *VirtualMachineImageProductInfo) DeepCopyInto(out *VirtualMachineImageProductInfo) {
+func (in *VirtualMachineImageImportRequestList) DeepCopyInto(out *VirtualMachineImageImportRequestList) {
 	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]VirtualMachineImageImportRequest, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VirtualMachineImageProductInfo.
-func (in *VirtualMachineImageProductInfo) DeepCopy() *VirtualMachineImageProductInfo {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VirtualMachineImageImportRequestList.
+func (in *VirtualMachineImageImportRequestList) DeepCopy() *VirtualMachineImageImportRequestList {
 	if in == nil {
 		return nil
 	}
-	out := new(VirtualMachineImageProductInfo)
+	out := new(VirtualMachineImageImportRequestList)
 	in.DeepCopyInto(out)
 	return out
 }
 
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *VirtualMachineImageImportRequestList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *VirtualMachineImageSpec) DeepCopyInto(out *VirtualMachineImageSpec) {
+func (in *VirtualMachineImageImportRequestSource) DeepCopyInto(out *VirtualMachineImageImportRequestSource) {
 	*out = *in
-	out.ProviderRef = in.ProviderRef
-	out.ProductInfo = in.ProductInfo
-	out.OSInfo = in.OSInfo
-	if in.OVFEnv != nil {
-		in, out := &in.OVFEnv, &out.OVFEnv
-		*out = make(map[string]OvfProperty, len(*in))
-		for key, val := range *in {
-			(*out)[key] = *val.DeepCopy()
-		}
+	if in.Checksum != nil {
+		in, out := &in.Checksum, &out.Checksum
+		*out = new(VirtualMachineImageImportRequestChecksum)
+		**out = **in
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VirtualMachineImageSpec.
-func (in *VirtualMachineImageSpec) DeepCopy() *VirtualMachineImageSpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VirtualMachineImageImportRequestSource.
+func (in *VirtualMachineImageImportRequestSource) DeepCopy() *VirtualMachineImageImportRequestSource {
 	if in == nil {
 		return nil
 	}
-	out := new(VirtualMachineImageSpec)
+	out := new(VirtualMachineImageImportRequestSource)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *VirtualMachineImageStatus) DeepCopyInto(out *VirtualMachineImageStatus) {
+func (in *VirtualMachineImageImportRequestSpec) DeepCopyInto(out *VirtualMachineImageImportRequestSpec) {
 	*out = *in
-	if in.ImageSupported != nil {
-		in, out := &in.ImageSupported, &out.ImageSupported
-		*out = new(bool)
+	in.Source.DeepCopyInto(&out.Source)
+	out.Target = in.Target
+	if in.TTLSecondsAfterFinished != nil {
+		in, out := &in.TTLSecondsAfterFinished, &out.TTLSecondsAfterFinished
+		*out = new(int64)
 		**out = **in
 	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VirtualMachineImageImportRequestSpec.
+func (in *VirtualMachineImageImportRequestSpec) DeepCopy() *VirtualMachineImageImportRequestSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(VirtualMachineImageImportRequestSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VirtualMachineImageImportRequestStatus) DeepCopyInto(out *VirtualMachineImageImportRequestStatus) {
+	*out = *in
+	in.StartTime.DeepCopyInto(&out.StartTime)
+	in.CompletionTime.DeepCopyInto(&out.CompletionTime)
 	if in.Conditions != nil {
 		in, out := &in.Conditions, &out.Conditions
 		*out = make([]Condition, len(*in))
@@ -1163,25 +1434,186 @@ func (in *VirtualMachineImageStatus) DeepCopyInto(out *VirtualMachineImageStatus
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
-	if in.ContentLibraryRef != nil {
-		in, out := &in.ContentLibraryRef, &out.ContentLibraryRef
-		*out = new(v1.TypedLocalObjectReference)
-		(*in).DeepCopyInto(*out)
-	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VirtualMachineImageStatus.
-func (in *VirtualMachineImageStatus) DeepCopy() *VirtualMachineImageStatus {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VirtualMachineImageImportRequestStatus.
+func (in *VirtualMachineImageImportRequestStatus) DeepCopy() *VirtualMachineImageImportRequestStatus {
 	if in == nil {
 		return nil
 	}
-	out := new(VirtualMachineImageStatus)
+	out := new(VirtualMachineImageImportRequestStatus)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *VirtualMachineList) DeepCopyInto(out *VirtualMachineList) {
+func (in *VirtualMachineImageImportRequestTarget) DeepCopyInto(out *VirtualMachineImageImportRequestTarget) {
+	*out = *in
+	out.Item = in.Item
+	out.Location = in.Location
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VirtualMachineImageImportRequestTarget.
+func (in *VirtualMachineImageImportRequestTarget) DeepCopy() *VirtualMachineImageImportRequestTarget {
+	if in == nil {
+		return nil
+	}
+	out := new(VirtualMachineImageImportRequestTarget)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VirtualMachineImageImportRequestTargetItem) DeepCopyInto(out *VirtualMachineImageImportRequestTargetItem) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VirtualMachineImageImportRequestTargetItem.
+func (in *VirtualMachineImageImportRequestTargetItem) DeepCopy() *VirtualMachineImageImportRequestTargetItem {
+	if in == nil {
+		return nil
+	}
+	out := new(VirtualMachineImageImportRequestTargetItem)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VirtualMachineImageImportRequestTargetLocation) DeepCopyInto(out *VirtualMachineImageImportRequestTargetLocation) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VirtualMachineImageImportRequestTargetLocation.
+func (in *VirtualMachineImageImportRequestTargetLocation) DeepCopy() *VirtualMachineImageImportRequestTargetLocation {
+	if in == nil {
+		return nil
+	}
+	out := new(VirtualMachineImageImportRequestTargetLocation)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VirtualMachineImageList) DeepCopyInto(out *VirtualMachineImageList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]VirtualMachineImage, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VirtualMachineImageList.
+func (in *VirtualMachineImageList) DeepCopy() *VirtualMachineImageList {
+	if in == nil {
+		return nil
+	}
+	out := new(VirtualMachineImageList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *VirtualMachineImageList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VirtualMachineImageOSInfo) DeepCopyInto(out *VirtualMachineImageOSInfo) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VirtualMachineImageOSInfo.
+func (in *VirtualMachineImageOSInfo) DeepCopy() *VirtualMachineImageOSInfo {
+	if in == nil {
+		return nil
+	}
+	out := new(VirtualMachineImageOSInfo)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VirtualMachineImageProductInfo) DeepCopyInto(out *VirtualMachineImageProductInfo) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VirtualMachineImageProductInfo.
+func (in *VirtualMachineImageProductInfo) DeepCopy() *VirtualMachineImageProductInfo {
+	if in == nil {
+		return nil
+	}
+	out := new(VirtualMachineImageProductInfo)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VirtualMachineImageSpec) DeepCopyInto(out *VirtualMachineImageSpec) {
+	*out = *in
+	out.ProviderRef = in.ProviderRef
+	out.ProductInfo = in.ProductInfo
+	out.OSInfo = in.OSInfo
+	if in.OVFEnv != nil {
+		in, out := &in.OVFEnv, &out.OVFEnv
+		*out = make(map[string]OvfProperty, len(*in))
+		for key, val := range *in {
+			(*out)[key] = *val.DeepCopy()
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VirtualMachineImageSpec.
+func (in *VirtualMachineImageSpec) DeepCopy() *VirtualMachineImageSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(VirtualMachineImageSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VirtualMachineImageStatus) DeepCopyInto(out *VirtualMachineImageStatus) {
+	*out = *in
+	if in.ImageSupported != nil {
+		in, out := &in.ImageSupported, &out.ImageSupported
+		*out = new(bool)
+		**out = **in
+	}
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.ContentLibraryRef != nil {
+		in, out := &in.ContentLibraryRef, &out.ContentLibraryRef
+		*out = new(corev1.TypedLocalObjectReference)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VirtualMachineImageStatus.
+func (in *VirtualMachineImageStatus) DeepCopy() *VirtualMachineImageStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(VirtualMachineImageStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VirtualMachineList) DeepCopyInto(out *VirtualMachineList) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ListMeta.DeepCopyInto(&out.ListMeta)
@@ -1194,76 +1626,414 @@ func (in *VirtualMachineList) DeepCopyInto(out *VirtualMachineList) {
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VirtualMachineList.
-func (in *VirtualMachineList) DeepCopy() *VirtualMachineList {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VirtualMachineList.
+func (in *VirtualMachineList) DeepCopy() *VirtualMachineList {
+	if in == nil {
+		return nil
+	}
+	out := new(VirtualMachineList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *VirtualMachineList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VirtualMachineMetadata) DeepCopyInto(out *VirtualMachineMetadata) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VirtualMachineMetadata.
+func (in *VirtualMachineMetadata) DeepCopy() *VirtualMachineMetadata {
+	if in == nil {
+		return nil
+	}
+	out := new(VirtualMachineMetadata)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VirtualMachineMigrationRequest) DeepCopyInto(out *VirtualMachineMigrationRequest) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VirtualMachineMigrationRequest.
+func (in *VirtualMachineMigrationRequest) DeepCopy() *VirtualMachineMigrationRequest {
+	if in == nil {
+		return nil
+	}
+	out := new(VirtualMachineMigrationRequest)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *VirtualMachineMigrationRequest) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VirtualMachineMigrationRequestList) DeepCopyInto(out *VirtualMachineMigrationRequestList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]VirtualMachineMigrationRequest, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VirtualMachineMigrationRequestList.
+func (in *VirtualMachineMigrationRequestList) DeepCopy() *VirtualMachineMigrationRequestList {
+	if in == nil {
+		return nil
+	}
+	out := new(VirtualMachineMigrationRequestList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *VirtualMachineMigrationRequestList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VirtualMachineMigrationRequestLocation) DeepCopyInto(out *VirtualMachineMigrationRequestLocation) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VirtualMachineMigrationRequestLocation.
+func (in *VirtualMachineMigrationRequestLocation) DeepCopy() *VirtualMachineMigrationRequestLocation {
+	if in == nil {
+		return nil
+	}
+	out := new(VirtualMachineMigrationRequestLocation)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VirtualMachineMigrationRequestSpec) DeepCopyInto(out *VirtualMachineMigrationRequestSpec) {
+	*out = *in
+	out.Target = in.Target
+	if in.TTLSecondsAfterFinished != nil {
+		in, out := &in.TTLSecondsAfterFinished, &out.TTLSecondsAfterFinished
+		*out = new(int64)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VirtualMachineMigrationRequestSpec.
+func (in *VirtualMachineMigrationRequestSpec) DeepCopy() *VirtualMachineMigrationRequestSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(VirtualMachineMigrationRequestSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VirtualMachineMigrationRequestStatus) DeepCopyInto(out *VirtualMachineMigrationRequestStatus) {
+	*out = *in
+	out.Source = in.Source
+	in.StartTime.DeepCopyInto(&out.StartTime)
+	in.CompletionTime.DeepCopyInto(&out.CompletionTime)
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VirtualMachineMigrationRequestStatus.
+func (in *VirtualMachineMigrationRequestStatus) DeepCopy() *VirtualMachineMigrationRequestStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(VirtualMachineMigrationRequestStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VirtualMachineNetworkInterface) DeepCopyInto(out *VirtualMachineNetworkInterface) {
+	*out = *in
+	if in.ProviderRef != nil {
+		in, out := &in.ProviderRef, &out.ProviderRef
+		*out = new(NetworkInterfaceProviderReference)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VirtualMachineNetworkInterface.
+func (in *VirtualMachineNetworkInterface) DeepCopy() *VirtualMachineNetworkInterface {
+	if in == nil {
+		return nil
+	}
+	out := new(VirtualMachineNetworkInterface)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VirtualMachinePort) DeepCopyInto(out *VirtualMachinePort) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VirtualMachinePort.
+func (in *VirtualMachinePort) DeepCopy() *VirtualMachinePort {
+	if in == nil {
+		return nil
+	}
+	out := new(VirtualMachinePort)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VirtualMachinePublishRequest) DeepCopyInto(out *VirtualMachinePublishRequest) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VirtualMachinePublishRequest.
+func (in *VirtualMachinePublishRequest) DeepCopy() *VirtualMachinePublishRequest {
+	if in == nil {
+		return nil
+	}
+	out := new(VirtualMachinePublishRequest)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *VirtualMachinePublishRequest) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VirtualMachinePublishRequestList) DeepCopyInto(out *VirtualMachinePublishRequestList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]VirtualMachinePublishRequest, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VirtualMachinePublishRequestList.
+func (in *VirtualMachinePublishRequestList) DeepCopy() *VirtualMachinePublishRequestList {
+	if in == nil {
+		return nil
+	}
+	out := new(VirtualMachinePublishRequestList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *VirtualMachinePublishRequestList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VirtualMachinePublishRequestOCIArtifactStatus) DeepCopyInto(out *VirtualMachinePublishRequestOCIArtifactStatus) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VirtualMachinePublishRequestOCIArtifactStatus.
+func (in *VirtualMachinePublishRequestOCIArtifactStatus) DeepCopy() *VirtualMachinePublishRequestOCIArtifactStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(VirtualMachinePublishRequestOCIArtifactStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VirtualMachinePublishRequestSource) DeepCopyInto(out *VirtualMachinePublishRequestSource) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VirtualMachinePublishRequestSource.
+func (in *VirtualMachinePublishRequestSource) DeepCopy() *VirtualMachinePublishRequestSource {
+	if in == nil {
+		return nil
+	}
+	out := new(VirtualMachinePublishRequestSource)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VirtualMachinePublishRequestSpec) DeepCopyInto(out *VirtualMachinePublishRequestSpec) {
+	*out = *in
+	out.Source = in.Source
+	in.Target.DeepCopyInto(&out.Target)
+	if in.TTLSecondsAfterFinished != nil {
+		in, out := &in.TTLSecondsAfterFinished, &out.TTLSecondsAfterFinished
+		*out = new(int64)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VirtualMachinePublishRequestSpec.
+func (in *VirtualMachinePublishRequestSpec) DeepCopy() *VirtualMachinePublishRequestSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(VirtualMachinePublishRequestSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VirtualMachinePublishRequestStatus) DeepCopyInto(out *VirtualMachinePublishRequestStatus) {
+	*out = *in
+	if in.SourceRef != nil {
+		in, out := &in.SourceRef, &out.SourceRef
+		*out = new(VirtualMachinePublishRequestSource)
+		**out = **in
+	}
+	if in.TargetRef != nil {
+		in, out := &in.TargetRef, &out.TargetRef
+		*out = new(VirtualMachinePublishRequestTarget)
+		(*in).DeepCopyInto(*out)
+	}
+	in.CompletionTime.DeepCopyInto(&out.CompletionTime)
+	in.StartTime.DeepCopyInto(&out.StartTime)
+	in.LastAttemptTime.DeepCopyInto(&out.LastAttemptTime)
+	if in.OCIArtifact != nil {
+		in, out := &in.OCIArtifact, &out.OCIArtifact
+		*out = new(VirtualMachinePublishRequestOCIArtifactStatus)
+		**out = **in
+	}
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VirtualMachinePublishRequestStatus.
+func (in *VirtualMachinePublishRequestStatus) DeepCopy() *VirtualMachinePublishRequestStatus {
 	if in == nil {
 		return nil
 	}
-	out := new(VirtualMachineList)
+	out := new(VirtualMachinePublishRequestStatus)
 	in.DeepCopyInto(out)
 	return out
 }
 
-// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *VirtualMachineList) DeepCopyObject() runtime.Object {
-	if c := in.DeepCopy(); c != nil {
-		return c
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VirtualMachinePublishRequestTarget) DeepCopyInto(out *VirtualMachinePublishRequestTarget) {
+	*out = *in
+	out.Item = in.Item
+	in.Location.DeepCopyInto(&out.Location)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VirtualMachinePublishRequestTarget.
+func (in *VirtualMachinePublishRequestTarget) DeepCopy() *VirtualMachinePublishRequestTarget {
+	if in == nil {
+		return nil
 	}
-	return nil
+	out := new(VirtualMachinePublishRequestTarget)
+	in.DeepCopyInto(out)
+	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *VirtualMachineMetadata) DeepCopyInto(out *VirtualMachineMetadata) {
+func (in *VirtualMachinePublishRequestTargetItem) DeepCopyInto(out *VirtualMachinePublishRequestTargetItem) {
 	*out = *in
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VirtualMachineMetadata.
-func (in *VirtualMachineMetadata) DeepCopy() *VirtualMachineMetadata {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VirtualMachinePublishRequestTargetItem.
+func (in *VirtualMachinePublishRequestTargetItem) DeepCopy() *VirtualMachinePublishRequestTargetItem {
 	if in == nil {
 		return nil
 	}
-	out := new(VirtualMachineMetadata)
+	out := new(VirtualMachinePublishRequestTargetItem)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *VirtualMachineNetworkInterface) DeepCopyInto(out *VirtualMachineNetworkInterface) {
+func (in *VirtualMachinePublishRequestTargetLocation) DeepCopyInto(out *VirtualMachinePublishRequestTargetLocation) {
 	*out = *in
-	if in.ProviderRef != nil {
-		in, out := &in.ProviderRef, &out.ProviderRef
-		*out = new(NetworkInterfaceProviderReference)
+	if in.OCI != nil {
+		in, out := &in.OCI, &out.OCI
+		*out = new(VirtualMachinePublishRequestTargetOCILocation)
 		**out = **in
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VirtualMachineNetworkInterface.
-func (in *VirtualMachineNetworkInterface) DeepCopy() *VirtualMachineNetworkInterface {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VirtualMachinePublishRequestTargetLocation.
+func (in *VirtualMachinePublishRequestTargetLocation) DeepCopy() *VirtualMachinePublishRequestTargetLocation {
 	if in == nil {
 		return nil
 	}
-	out := new(VirtualMachineNetworkInterface)
+	out := new(VirtualMachinePublishRequestTargetLocation)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *VirtualMachinePort) DeepCopyInto(out *VirtualMachinePort) {
+func (in *VirtualMachinePublishRequestTargetOCILocation) DeepCopyInto(out *VirtualMachinePublishRequestTargetOCILocation) {
 	*out = *in
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VirtualMachinePort.
-func (in *VirtualMachinePort) DeepCopy() *VirtualMachinePort {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VirtualMachinePublishRequestTargetOCILocation.
+func (in *VirtualMachinePublishRequestTargetOCILocation) DeepCopy() *VirtualMachinePublishRequestTargetOCILocation {
 	if in == nil {
 		return nil
 	}
-	out := new(VirtualMachinePort)
+	out := new(VirtualMachinePublishRequestTargetOCILocation)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *VirtualMachinePublishRequest) DeepCopyInto(out *VirtualMachinePublishRequest) {
+func (in *VirtualMachinePublishSchedule) DeepCopyInto(out *VirtualMachinePublishSchedule) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
@@ -1271,18 +2041,18 @@ func (in *VirtualMachinePublishRequest) DeepCopyInto(out *VirtualMachinePublishR
 	in.Status.DeepCopyInto(&out.Status)
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VirtualMachinePublishRequest.
-func (in *VirtualMachinePublishRequest) DeepCopy() *VirtualMachinePublishRequest {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VirtualMachinePublishSchedule.
+func (in *VirtualMachinePublishSchedule) DeepCopy() *VirtualMachinePublishSchedule {
 	if in == nil {
 		return nil
 	}
-	out := new(VirtualMachinePublishRequest)
+	out := new(VirtualMachinePublishSchedule)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *VirtualMachinePublishRequest) DeepCopyObject() runtime.Object {
+func (in *VirtualMachinePublishSchedule) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	}
@@ -1290,31 +2060,31 @@ func (in *VirtualMachinePublishRequest) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *VirtualMachinePublishRequestList) DeepCopyInto(out *VirtualMachinePublishRequestList) {
+func (in *VirtualMachinePublishScheduleList) DeepCopyInto(out *VirtualMachinePublishScheduleList) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ListMeta.DeepCopyInto(&out.ListMeta)
 	if in.Items != nil {
 		in, out := &in.Items, &out.Items
-		*out = make([]VirtualMachinePublishRequest, len(*in))
+		*out = make([]VirtualMachinePublishSchedule, len(*in))
 		for i := range *in {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VirtualMachinePublishRequestList.
-func (in *VirtualMachinePublishRequestList) DeepCopy() *VirtualMachinePublishRequestList {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VirtualMachinePublishScheduleList.
+func (in *VirtualMachinePublishScheduleList) DeepCopy() *VirtualMachinePublishScheduleList {
 	if in == nil {
 		return nil
 	}
-	out := new(VirtualMachinePublishRequestList)
+	out := new(VirtualMachinePublishScheduleList)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *VirtualMachinePublishRequestList) DeepCopyObject() runtime.Object {
+func (in *VirtualMachinePublishScheduleList) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	}
@@ -1322,58 +2092,61 @@ func (in *VirtualMachinePublishRequestList) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *VirtualMachinePublishRequestSource) DeepCopyInto(out *VirtualMachinePublishRequestSource) {
+func (in *VirtualMachinePublishSchedulePublishedItem) DeepCopyInto(out *VirtualMachinePublishSchedulePublishedItem) {
 	*out = *in
+	in.PublishTime.DeepCopyInto(&out.PublishTime)
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VirtualMachinePublishRequestSource.
-func (in *VirtualMachinePublishRequestSource) DeepCopy() *VirtualMachinePublishRequestSource {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VirtualMachinePublishSchedulePublishedItem.
+func (in *VirtualMachinePublishSchedulePublishedItem) DeepCopy() *VirtualMachinePublishSchedulePublishedItem {
 	if in == nil {
 		return nil
 	}
-	out := new(VirtualMachinePublishRequestSource)
+	out := new(VirtualMachinePublishSchedulePublishedItem)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *VirtualMachinePublishRequestSpec) DeepCopyInto(out *VirtualMachinePublishRequestSpec) {
+func (in *VirtualMachinePublishScheduleSpec) DeepCopyInto(out *VirtualMachinePublishScheduleSpec) {
 	*out = *in
 	out.Source = in.Source
-	out.Target = in.Target
-	if in.TTLSecondsAfterFinished != nil {
-		in, out := &in.TTLSecondsAfterFinished, &out.TTLSecondsAfterFinished
-		*out = new(int64)
+	in.Target.DeepCopyInto(&out.Target)
+	if in.RetentionCount != nil {
+		in, out := &in.RetentionCount, &out.RetentionCount
+		*out = new(int32)
 		**out = **in
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VirtualMachinePublishRequestSpec.
-func (in *VirtualMachinePublishRequestSpec) DeepCopy() *VirtualMachinePublishRequestSpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VirtualMachinePublishScheduleSpec.
+func (in *VirtualMachinePublishScheduleSpec) DeepCopy() *VirtualMachinePublishScheduleSpec {
 	if in == nil {
 		return nil
 	}
-	out := new(VirtualMachinePublishRequestSpec)
+	out := new(VirtualMachinePublishScheduleSpec)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *VirtualMachinePublishRequestStatus) DeepCopyInto(out *VirtualMachinePublishRequestStatus) {
+func (in *VirtualMachinePublishScheduleStatus) DeepCopyInto(out *VirtualMachinePublishScheduleStatus) {
 	*out = *in
-	if in.SourceRef != nil {
-		in, out := &in.SourceRef, &out.SourceRef
-		*out = new(VirtualMachinePublishRequestSource)
-		**out = **in
+	if in.LastScheduleTime != nil {
+		in, out := &in.LastScheduleTime, &out.LastScheduleTime
+		*out = (*in).DeepCopy()
 	}
-	if in.TargetRef != nil {
-		in, out := &in.TargetRef, &out.TargetRef
-		*out = new(VirtualMachinePublishRequestTarget)
-		**out = **in
+	if in.LastSuccessfulPublishTime != nil {
+		in, out := &in.LastSuccessfulPublishTime, &out.LastSuccessfulPublishTime
+		*out = (*in).DeepCopy()
+	}
+	if in.PublishedItems != nil {
+		in, out := &in.PublishedItems, &out.PublishedItems
+		*out = make([]VirtualMachinePublishSchedulePublishedItem, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
 	}
-	in.CompletionTime.DeepCopyInto(&out.CompletionTime)
-	in.StartTime.DeepCopyInto(&out.StartTime)
-	in.LastAttemptTime.DeepCopyInto(&out.LastAttemptTime)
 	if in.Conditions != nil {
 		in, out := &in.Conditions, &out.Conditions
 		*out = make([]Condition, len(*in))
@@ -1383,59 +2156,122 @@ func (in *VirtualMachinePublishRequestStatus) DeepCopyInto(out *VirtualMachinePu
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VirtualMachinePublishRequestStatus.
-func (in *VirtualMachinePublishRequestStatus) DeepCopy() *VirtualMachinePublishRequestStatus {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VirtualMachinePublishScheduleStatus.
+func (in *VirtualMachinePublishScheduleStatus) DeepCopy() *VirtualMachinePublishScheduleStatus {
 	if in == nil {
 		return nil
 	}
-	out := new(VirtualMachinePublishRequestStatus)
+	out := new(VirtualMachinePublishScheduleStatus)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *VirtualMachinePublishRequestTarget) DeepCopyInto(out *VirtualMachinePublishRequestTarget) {
+func (in *VirtualMachineQuota) DeepCopyInto(out *VirtualMachineQuota) {
 	*out = *in
-	out.Item = in.Item
-	out.Location = in.Location
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VirtualMachinePublishRequestTarget.
-func (in *VirtualMachinePublishRequestTarget) DeepCopy() *VirtualMachinePublishRequestTarget {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VirtualMachineQuota.
+func (in *VirtualMachineQuota) DeepCopy() *VirtualMachineQuota {
 	if in == nil {
 		return nil
 	}
-	out := new(VirtualMachinePublishRequestTarget)
+	out := new(VirtualMachineQuota)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *VirtualMachineQuota) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VirtualMachineQuotaList) DeepCopyInto(out *VirtualMachineQuotaList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]VirtualMachineQuota, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VirtualMachineQuotaList.
+func (in *VirtualMachineQuotaList) DeepCopy() *VirtualMachineQuotaList {
+	if in == nil {
+		return nil
+	}
+	out := new(VirtualMachineQuotaList)
 	in.DeepCopyInto(out)
 	return out
 }
 
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *VirtualMachineQuotaList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *VirtualMachinePublishRequestTargetItem) DeepCopyInto(out *VirtualMachinePublishRequestTargetItem) {
+func (in *VirtualMachineQuotaSpec) DeepCopyInto(out *VirtualMachineQuotaSpec) {
 	*out = *in
+	if in.Hard != nil {
+		in, out := &in.Hard, &out.Hard
+		*out = make(corev1.ResourceList, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val.DeepCopy()
+		}
+	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VirtualMachinePublishRequestTargetItem.
-func (in *VirtualMachinePublishRequestTargetItem) DeepCopy() *VirtualMachinePublishRequestTargetItem {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VirtualMachineQuotaSpec.
+func (in *VirtualMachineQuotaSpec) DeepCopy() *VirtualMachineQuotaSpec {
 	if in == nil {
 		return nil
 	}
-	out := new(VirtualMachinePublishRequestTargetItem)
+	out := new(VirtualMachineQuotaSpec)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *VirtualMachinePublishRequestTargetLocation) DeepCopyInto(out *VirtualMachinePublishRequestTargetLocation) {
+func (in *VirtualMachineQuotaStatus) DeepCopyInto(out *VirtualMachineQuotaStatus) {
 	*out = *in
+	if in.Hard != nil {
+		in, out := &in.Hard, &out.Hard
+		*out = make(corev1.ResourceList, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val.DeepCopy()
+		}
+	}
+	if in.Used != nil {
+		in, out := &in.Used, &out.Used
+		*out = make(corev1.ResourceList, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val.DeepCopy()
+		}
+	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VirtualMachinePublishRequestTargetLocation.
-func (in *VirtualMachinePublishRequestTargetLocation) DeepCopy() *VirtualMachinePublishRequestTargetLocation {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VirtualMachineQuotaStatus.
+func (in *VirtualMachineQuotaStatus) DeepCopy() *VirtualMachineQuotaStatus {
 	if in == nil {
 		return nil
 	}
-	out := new(VirtualMachinePublishRequestTargetLocation)
+	out := new(VirtualMachineQuotaStatus)
 	in.DeepCopyInto(out)
 	return out
 }
@@ -1666,7 +2502,23 @@ func (in *VirtualMachineSetResourcePolicyStatus) DeepCopyInto(out *VirtualMachin
 	if in.ClusterModules != nil {
 		in, out := &in.ClusterModules, &out.ClusterModules
 		*out = make([]ClusterModuleStatus, len(*in))
-		copy(*out, *in)
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Zones != nil {
+		in, out := &in.Zones, &out.Zones
+		*out = make([]ResourcePolicyZoneStatus, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
 	}
 }
 
@@ -1717,6 +2569,18 @@ func (in *VirtualMachineSpec) DeepCopyInto(out *VirtualMachineSpec) {
 		*out = new(VirtualMachineAdvancedOptions)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.Affinity != nil {
+		in, out := &in.Affinity, &out.Affinity
+		*out = new(VirtualMachineAffinitySpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.TopologySpreadConstraints != nil {
+		in, out := &in.TopologySpreadConstraints, &out.TopologySpreadConstraints
+		*out = make([]VirtualMachineTopologySpreadConstraint, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VirtualMachineSpec.
@@ -1756,6 +2620,11 @@ func (in *VirtualMachineStatus) DeepCopyInto(out *VirtualMachineStatus) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.LastTask != nil {
+		in, out := &in.LastTask, &out.LastTask
+		*out = new(VirtualMachineTaskInfo)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VirtualMachineStatus.
@@ -1768,6 +2637,49 @@ func (in *VirtualMachineStatus) DeepCopy() *VirtualMachineStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VirtualMachineTaskFault) DeepCopyInto(out *VirtualMachineTaskFault) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VirtualMachineTaskFault.
+func (in *VirtualMachineTaskFault) DeepCopy() *VirtualMachineTaskFault {
+	if in == nil {
+		return nil
+	}
+	out := new(VirtualMachineTaskFault)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VirtualMachineTaskInfo) DeepCopyInto(out *VirtualMachineTaskInfo) {
+	*out = *in
+	if in.StartTime != nil {
+		in, out := &in.StartTime, &out.StartTime
+		*out = (*in).DeepCopy()
+	}
+	if in.CompleteTime != nil {
+		in, out := &in.CompleteTime, &out.CompleteTime
+		*out = (*in).DeepCopy()
+	}
+	if in.Fault != nil {
+		in, out := &in.Fault, &out.Fault
+		*out = new(VirtualMachineTaskFault)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VirtualMachineTaskInfo.
+func (in *VirtualMachineTaskInfo) DeepCopy() *VirtualMachineTaskInfo {
+	if in == nil {
+		return nil
+	}
+	out := new(VirtualMachineTaskInfo)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *VirtualMachineTemplate) DeepCopyInto(out *VirtualMachineTemplate) {
 	*out = *in
@@ -1789,6 +2701,26 @@ func (in *VirtualMachineTemplate) DeepCopy() *VirtualMachineTemplate {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VirtualMachineTopologySpreadConstraint) DeepCopyInto(out *VirtualMachineTopologySpreadConstraint) {
+	*out = *in
+	if in.LabelSelector != nil {
+		in, out := &in.LabelSelector, &out.LabelSelector
+		*out = new(v1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VirtualMachineTopologySpreadConstraint.
+func (in *VirtualMachineTopologySpreadConstraint) DeepCopy() *VirtualMachineTopologySpreadConstraint {
+	if in == nil {
+		return nil
+	}
+	out := new(VirtualMachineTopologySpreadConstraint)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *VirtualMachineVolume) DeepCopyInto(out *VirtualMachineVolume) {
 	*out = *in
@@ -1854,12 +2786,28 @@ func (in *VirtualMachineVolumeStatus) DeepCopy() *VirtualMachineVolumeStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VirtualMachineWeightedAffinityTerm) DeepCopyInto(out *VirtualMachineWeightedAffinityTerm) {
+	*out = *in
+	in.AffinityTerm.DeepCopyInto(&out.AffinityTerm)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VirtualMachineWeightedAffinityTerm.
+func (in *VirtualMachineWeightedAffinityTerm) DeepCopy() *VirtualMachineWeightedAffinityTerm {
+	if in == nil {
+		return nil
+	}
+	out := new(VirtualMachineWeightedAffinityTerm)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *VsphereVolumeSource) DeepCopyInto(out *VsphereVolumeSource) {
 	*out = *in
 	if in.Capacity != nil {
 		in, out := &in.Capacity, &out.Capacity
-		*out = make(v1.ResourceList, len(*in))
+		*out = make(corev1.ResourceList, len(*in))
 		for key, val := range *in {
 			(*out)[key] = val.DeepCopy()
 		}
@@ -1886,7 +2834,7 @@ func (in *WebConsoleRequest) DeepCopyInto(out *WebConsoleRequest) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
-	out.Spec = in.Spec
+	in.Spec.DeepCopyInto(&out.Spec)
 	in.Status.DeepCopyInto(&out.Status)
 }
 
@@ -1943,6 +2891,11 @@ func (in *WebConsoleRequestList) DeepCopyObject() runtime.Object {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *WebConsoleRequestSpec) DeepCopyInto(out *WebConsoleRequestSpec) {
 	*out = *in
+	if in.Duration != nil {
+		in, out := &in.Duration, &out.Duration
+		*out = new(v1.Duration)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new WebConsoleRequestSpec.
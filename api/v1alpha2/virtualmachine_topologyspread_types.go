@@ -0,0 +1,48 @@
+// Copyright (c) 2023 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package v1alpha2
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// VirtualMachineUnsatisfiableConstraintAction is the action to take when a
+// topology spread constraint cannot be satisfied.
+// +kubebuilder:validation:Enum=DoNotSchedule;ScheduleAnyway
+type VirtualMachineUnsatisfiableConstraintAction string
+
+const (
+	// VirtualMachineDoNotSchedule does not place the VM when the constraint
+	// cannot be satisfied.
+	VirtualMachineDoNotSchedule VirtualMachineUnsatisfiableConstraintAction = "DoNotSchedule"
+
+	// VirtualMachineScheduleAnyway places the VM in the zone that minimizes
+	// the skew when the constraint cannot be satisfied.
+	VirtualMachineScheduleAnyway VirtualMachineUnsatisfiableConstraintAction = "ScheduleAnyway"
+)
+
+// VirtualMachineTopologySpreadConstraint describes how the VMs in the same
+// namespace that are selected by the label selector are spread across zones.
+type VirtualMachineTopologySpreadConstraint struct {
+	// MaxSkew is the maximum permitted difference between the number of
+	// selected VMs in a zone, including the VM being placed, and the minimum
+	// number of selected VMs in any zone that the VM may be placed in.
+	// +kubebuilder:validation:Minimum=1
+	MaxSkew int32 `json:"maxSkew"`
+
+	// TopologyKey is the topology domain to spread the VMs over. Only
+	// topology.kubernetes.io/zone is supported.
+	// +kubebuilder:validation:Enum=topology.kubernetes.io/zone
+	TopologyKey string `json:"topologyKey"`
+
+	// WhenUnsatisfiable is the action to take when no zone satisfies the
+	// constraint: DoNotSchedule does not place the VM, and ScheduleAnyway
+	// places the VM in a zone that minimizes the skew.
+	WhenUnsatisfiable VirtualMachineUnsatisfiableConstraintAction `json:"whenUnsatisfiable"`
+
+	// LabelSelector selects the VMs to count in each zone. A nil or empty
+	// selector does not select any VMs.
+	// +optional
+	LabelSelector *metav1.LabelSelector `json:"labelSelector,omitempty"`
+}
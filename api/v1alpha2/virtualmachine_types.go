@@ -70,6 +70,44 @@ const (
 	VirtualMachineToolsRunningReason = "VirtualMachineToolsRunning"
 )
 
+const (
+	// VirtualMachineTopologySpreadCondition documents whether the zone the VM
+	// is placed in satisfies the VM's topology spread constraints.
+	VirtualMachineTopologySpreadCondition = "VirtualMachineTopologySpread"
+
+	// TopologySpreadConstraintsNotSatisfiedReason (Severity=Error) documents
+	// that the VM cannot be placed since no zone satisfies its DoNotSchedule
+	// topology spread constraints.
+	TopologySpreadConstraintsNotSatisfiedReason = "TopologySpreadConstraintsNotSatisfied"
+
+	// TopologySpreadConstraintsSkewedReason (Severity=Warning) documents that
+	// the VM was placed in a zone that exceeds the maximum skew of its
+	// ScheduleAnyway topology spread constraints.
+	TopologySpreadConstraintsSkewedReason = "TopologySpreadConstraintsSkewed"
+)
+
+const (
+	// VirtualMachinePCIDevicePlacementCondition documents whether the VM is
+	// placed on a host that has its vGPU and Dynamic DirectPath I/O devices
+	// available.
+	VirtualMachinePCIDevicePlacementCondition = "VirtualMachinePCIDevicePlacement"
+
+	// NoHostWithVGPUProfileReason (Severity=Error) documents that the VM
+	// cannot be placed since no host supports the profile of one of its vGPU
+	// devices.
+	NoHostWithVGPUProfileReason = "NoHostWithVGPUProfile"
+
+	// NoHostWithPCIDeviceReason (Severity=Error) documents that the VM cannot
+	// be placed since no host has enough free passthrough devices for one of
+	// its Dynamic DirectPath I/O devices.
+	NoHostWithPCIDeviceReason = "NoHostWithPCIDevice"
+
+	// NoHostWithAllPCIDevicesReason (Severity=Error) documents that the VM
+	// cannot be placed since no host has all of its vGPU and Dynamic
+	// DirectPath I/O devices available.
+	NoHostWithAllPCIDevicesReason = "NoHostWithAllPCIDevices"
+)
+
 const (
 	// PauseAnnotation is an annotation that prevents a VM from being
 	// reconciled.
@@ -205,6 +243,18 @@ type VirtualMachineSpec struct {
 	// +optional
 	Advanced VirtualMachineAdvancedSpec `json:"advanced,omitempty"`
 
+	// Affinity describes the affinity and anti-affinity of the VM to other VMs
+	// in the same namespace, by zone or by host, that is considered when the
+	// VM is placed.
+	// +optional
+	Affinity *VirtualMachineAffinitySpec `json:"affinity,omitempty"`
+
+	// TopologySpreadConstraints describes how the VM and the other VMs in the
+	// same namespace that are selected by each constraint are spread across
+	// zones when the VM is placed.
+	// +optional
+	TopologySpreadConstraints []VirtualMachineTopologySpreadConstraint `json:"topologySpreadConstraints,omitempty"`
+
 	// Reserved describes a set of VM configuration options reserved for system
 	// use.
 	//
@@ -254,6 +304,80 @@ type VirtualMachineAdvancedSpec struct {
 	ChangeBlockTracking bool `json:"changeBlockTracking,omitempty"`
 }
 
+// VirtualMachineTaskState describes the state of a vSphere task.
+type VirtualMachineTaskState string
+
+const (
+	// VirtualMachineTaskStateQueued indicates the task is waiting to be run by
+	// vSphere.
+	VirtualMachineTaskStateQueued VirtualMachineTaskState = "Queued"
+
+	// VirtualMachineTaskStateRunning indicates the task is being run by
+	// vSphere.
+	VirtualMachineTaskStateRunning VirtualMachineTaskState = "Running"
+
+	// VirtualMachineTaskStateSuccess indicates the task completed successfully.
+	VirtualMachineTaskStateSuccess VirtualMachineTaskState = "Success"
+
+	// VirtualMachineTaskStateError indicates the task failed.
+	VirtualMachineTaskStateError VirtualMachineTaskState = "Error"
+)
+
+// VirtualMachineTaskFault describes the fault a vSphere task failed with.
+type VirtualMachineTaskFault struct {
+	// Type is the type of the fault, such as InvalidPowerState or
+	// InsufficientResourcesFault.
+	Type string `json:"type"`
+
+	// Message is the localized message of the fault.
+	//
+	// +optional
+	Message string `json:"message,omitempty"`
+}
+
+// VirtualMachineTaskInfo describes a vSphere task that was started on the VM.
+type VirtualMachineTaskInfo struct {
+	// TaskRef is the managed object ID of the task, such as task-123. For the deploy of a content library item,
+	// which is not a vSphere task, it is the ID of the library item.
+	TaskRef string `json:"taskRef"`
+
+	// DescriptionID identifies the operation of the task, such as
+	// VirtualMachine.powerOn.
+	//
+	// +optional
+	DescriptionID string `json:"descriptionID,omitempty"`
+
+	// Description describes the current step of the task, when it is reported
+	// by vSphere.
+	//
+	// +optional
+	Description string `json:"description,omitempty"`
+
+	// State describes the state of the task.
+	State VirtualMachineTaskState `json:"state"`
+
+	// Progress is the percentage of the task that is complete, when it is
+	// reported by vSphere.
+	//
+	// +optional
+	Progress int32 `json:"progress,omitempty"`
+
+	// StartTime is the time the task was started by vSphere.
+	//
+	// +optional
+	StartTime *metav1.Time `json:"startTime,omitempty"`
+
+	// CompleteTime is the time the task completed.
+	//
+	// +optional
+	CompleteTime *metav1.Time `json:"completeTime,omitempty"`
+
+	// Fault describes the fault the task failed with.
+	//
+	// +optional
+	Fault *VirtualMachineTaskFault `json:"fault,omitempty"`
+}
+
 // VirtualMachineStatus defines the observed state of a VirtualMachine instance.
 type VirtualMachineStatus struct {
 	// Image is a reference to the VirtualMachineImage resource used to deploy
@@ -326,6 +450,19 @@ type VirtualMachineStatus struct {
 	//
 	// +optional
 	Zone string `json:"zone,omitempty"`
+
+	// ClassGeneration is the generation of the VirtualMachineClass that the
+	// VM's hardware was last configured from.
+	//
+	// +optional
+	ClassGeneration int64 `json:"classGeneration,omitempty"`
+
+	// LastTask describes the last vSphere task that was started on the VM. A
+	// task that is still running is checked by the following reconciles of the
+	// VM, instead of being waited for.
+	//
+	// +optional
+	LastTask *VirtualMachineTaskInfo `json:"lastTask,omitempty"`
 }
 
 // +kubebuilder:object:root=true
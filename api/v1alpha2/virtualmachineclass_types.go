@@ -1,4 +1,4 @@
-// Copyright (c) 2022 VMware, Inc. All Rights Reserved.
+// Copyright (c) 2022-2023 VMware, Inc. All Rights Reserved.
 // SPDX-License-Identifier: Apache-2.0
 
 package v1alpha2
@@ -152,6 +152,54 @@ type VirtualMachineClassPolicies struct {
 	Resources VirtualMachineClassResources `json:"resources,omitempty"`
 }
 
+// VirtualMachineClassUpdateStrategyType is how changes to a
+// VirtualMachineClass are applied to the existing VirtualMachines of the class.
+type VirtualMachineClassUpdateStrategyType string
+
+const (
+	// VirtualMachineClassUpdateStrategyNever does not apply changes to the
+	// class to existing VirtualMachines.
+	VirtualMachineClassUpdateStrategyNever VirtualMachineClassUpdateStrategyType = "Never"
+
+	// VirtualMachineClassUpdateStrategyOnNextPowerCycle applies changes to the
+	// class to an existing VirtualMachine the next time it is powered on.
+	VirtualMachineClassUpdateStrategyOnNextPowerCycle VirtualMachineClassUpdateStrategyType = "OnNextPowerCycle"
+
+	// VirtualMachineClassUpdateStrategyRollingRestart applies changes to the
+	// class to the existing powered on VirtualMachines by restarting them, at
+	// most MaxUnavailable at a time in each namespace, and to the powered off
+	// VirtualMachines the next time they are powered on.
+	VirtualMachineClassUpdateStrategyRollingRestart VirtualMachineClassUpdateStrategyType = "RollingRestart"
+)
+
+// VirtualMachineClassRollingRestart describes how the VirtualMachines of a
+// class are restarted to apply changes to the class.
+type VirtualMachineClassRollingRestart struct {
+	// MaxUnavailable is the maximum number of VirtualMachines of the class in
+	// a namespace that are restarted at the same time. Defaults to 1.
+	//
+	// +optional
+	// +kubebuilder:validation:Minimum=1
+	MaxUnavailable *int32 `json:"maxUnavailable,omitempty"`
+}
+
+// VirtualMachineClassUpdatePolicy describes how changes to a
+// VirtualMachineClass are applied to the existing VirtualMachines of the class.
+type VirtualMachineClassUpdatePolicy struct {
+	// Strategy is how changes to the class are applied to the existing
+	// VirtualMachines of the class. Defaults to Never.
+	//
+	// +optional
+	// +kubebuilder:validation:Enum=Never;OnNextPowerCycle;RollingRestart
+	Strategy VirtualMachineClassUpdateStrategyType `json:"strategy,omitempty"`
+
+	// RollingRestart describes how the VirtualMachines are restarted when the
+	// strategy is RollingRestart.
+	//
+	// +optional
+	RollingRestart *VirtualMachineClassRollingRestart `json:"rollingRestart,omitempty"`
+}
+
 // VirtualMachineClassSpec defines the desired state of VirtualMachineClass.
 type VirtualMachineClassSpec struct {
 	// Hardware describes the configuration of the VirtualMachineClass
@@ -188,6 +236,12 @@ type VirtualMachineClassSpec struct {
 	// +kubebuilder:validation:Type=object
 	// +kubebuilder:pruning:PreserveUnknownFields
 	ConfigSpec json.RawMessage `json:"configSpec,omitempty"`
+
+	// UpdatePolicy describes how changes to the class are applied to the
+	// existing VirtualMachines of the class.
+	//
+	// +optional
+	UpdatePolicy VirtualMachineClassUpdatePolicy `json:"updatePolicy,omitempty"`
 }
 
 // VirtualMachineClassStatus defines the observed state of VirtualMachineClass.
@@ -226,6 +280,18 @@ type VirtualMachineClassStatus struct {
 	//
 	// +optional
 	Ready bool `json:"ready,omitempty"`
+
+	// EffectiveConfigSpec is the ConfigSpec that VirtualMachines of this class
+	// are created with: the class's ConfigSpec without the fields and devices
+	// that are unique to a VirtualMachine or not supported, such as disks, and
+	// with the CPU and memory of the class's hardware. It is marshaled to JSON
+	// the same way as the class's ConfigSpec.
+	//
+	// +optional
+	// +kubebuilder:validation:Schemaless
+	// +kubebuilder:validation:Type=object
+	// +kubebuilder:pruning:PreserveUnknownFields
+	EffectiveConfigSpec json.RawMessage `json:"effectiveConfigSpec,omitempty"`
 }
 
 // +kubebuilder:object:root=true
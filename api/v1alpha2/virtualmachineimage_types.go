@@ -193,6 +193,11 @@ type VirtualMachineImageStatus struct {
 	// +optional
 	ProviderContentVersion string `json:"providerContentVersion,omitempty"`
 
+	// TrustPolicyHash is the hash of the public keys of the image trust policy
+	// that this image was last verified against.
+	// +optional
+	TrustPolicyHash string `json:"trustPolicyHash,omitempty"`
+
 	// Conditions describes the observed conditions for this image.
 	//
 	// +optional
@@ -35,7 +35,8 @@ const (
 	//
 	// The condition's status is set to true only when a new
 	// VirtualMachineImage resource has been realized from the published
-	// VM.
+	// VM, or, when publishing to an OCI registry, when the pushed artifact
+	// can be resolved from the registry.
 	VirtualMachinePublishRequestConditionImageAvailable = "ImageAvailable"
 
 	// VirtualMachinePublishRequestConditionComplete is the Type for a
@@ -73,11 +74,30 @@ const (
 	// the target content library.
 	TargetItemAlreadyExistsReason = "TargetItemAlreadyExists"
 
+	// TargetRegistrySecretNotExistReason documents that the Secret with the
+	// credentials for the target OCI registry of the
+	// VirtualMachinePublishRequest doesn't exist.
+	TargetRegistrySecretNotExistReason = "TargetRegistrySecretNotExist"
+
+	// TargetRegistrySecretInvalidReason documents that the Secret with the
+	// credentials for the target OCI registry of the
+	// VirtualMachinePublishRequest is not a valid docker config Secret.
+	TargetRegistrySecretInvalidReason = "TargetRegistrySecretInvalid"
+
+	// TargetRegistryNotReachableReason documents that the target OCI
+	// registry of the VirtualMachinePublishRequest could not be accessed.
+	TargetRegistryNotReachableReason = "TargetRegistryNotReachable"
+
 	// TargetVirtualMachineImageNotFoundReason documents that the expected
 	// VirtualMachineImage resource corresponding to the VirtualMachinePublishRequest's
 	// target item is not found in the namespace.
 	TargetVirtualMachineImageNotFoundReason = "VirtualMachineImageNotFound"
 
+	// TargetOCIArtifactNotFoundReason documents that the OCI artifact pushed
+	// for the VirtualMachinePublishRequest could not be resolved from the
+	// target registry.
+	TargetOCIArtifactNotFoundReason = "OCIArtifactNotFound"
+
 	// UploadTaskNotStartedReason documents that the VM publish task hasn't started.
 	UploadTaskNotStartedReason = "NotStarted"
 
@@ -180,6 +200,57 @@ type VirtualMachinePublishRequestTargetLocation struct {
 	// +kubebuilder:default=ContentLibrary
 	// +optional
 	Kind string `json:"kind,omitempty"`
+
+	// OCI describes an OCI registry repository to which the VM is
+	// published as an OCI artifact instead of a content library item.
+	//
+	// When this field is set, spec.target.location.name must be empty and
+	// spec.target.location.apiVersion and spec.target.location.kind are
+	// ignored.
+	//
+	// +optional
+	OCI *VirtualMachinePublishRequestTargetOCILocation `json:"oci,omitempty"`
+}
+
+// VirtualMachinePublishRequestTargetOCILocation describes an OCI registry
+// repository to which a VM is published.
+type VirtualMachinePublishRequestTargetOCILocation struct {
+	// Repository is the OCI repository to which the VM is pushed, ex.
+	// registry.example.com/vm-images/ubuntu.
+	//
+	// The published artifact is tagged with spec.target.item.name.
+	Repository string `json:"repository"`
+
+	// SecretName is the name of a Secret in the same namespace as the
+	// VirtualMachinePublishRequest that contains the credentials used to
+	// pull from and push to the registry.
+	//
+	// The Secret must be of type kubernetes.io/dockerconfigjson. If omitted
+	// then the registry is accessed anonymously.
+	//
+	// +optional
+	SecretName string `json:"secretName,omitempty"`
+
+	// InsecureSkipTLSVerify indicates that the registry's TLS certificate
+	// should not be verified. This should only be used for testing.
+	//
+	// +optional
+	InsecureSkipTLSVerify bool `json:"insecureSkipTLSVerify,omitempty"`
+}
+
+// VirtualMachinePublishRequestOCIArtifactStatus describes the OCI artifact
+// that was pushed to a registry.
+type VirtualMachinePublishRequestOCIArtifactStatus struct {
+	// Reference is the tagged reference of the pushed artifact, ex.
+	// registry.example.com/vm-images/ubuntu:my-vm-image.
+	//
+	// +optional
+	Reference string `json:"reference,omitempty"`
+
+	// Digest is the digest of the pushed artifact's manifest.
+	//
+	// +optional
+	Digest string `json:"digest,omitempty"`
 }
 
 // VirtualMachinePublishRequestTarget is the target of a publication request,
@@ -309,6 +380,14 @@ type VirtualMachinePublishRequestStatus struct {
 	// +optional
 	ImageName string `json:"imageName,omitempty"`
 
+	// OCIArtifact describes the OCI artifact that was pushed to the
+	// registry when spec.target.location.oci is set.
+	//
+	// This field will not be set until the artifact has been uploaded.
+	//
+	// +optional
+	OCIArtifact *VirtualMachinePublishRequestOCIArtifactStatus `json:"ociArtifact,omitempty"`
+
 	// Ready is set to true only when the VM has been published successfully
 	// and the new VirtualMachineImage resource is ready.
 	//
@@ -36,6 +36,32 @@ type VirtualMachineSetResourcePolicySpec struct {
 // VirtualMachineSetResourcePolicy.
 type VirtualMachineSetResourcePolicyStatus struct {
 	ClusterModules []VSphereClusterModuleStatus `json:"clustermodules,omitempty"`
+
+	// Zones describes the observed state of the resource policy in each of
+	// the availability zones of the namespace.
+	//
+	// +optional
+	Zones []ResourcePolicyZoneStatus `json:"zones,omitempty"`
+
+	// Conditions describes the observed conditions of the resource policy.
+	// The Ready condition is true when the resource policy is ready in all
+	// of the Zones.
+	//
+	// +optional
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+}
+
+// ResourcePolicyZoneStatus describes the observed state of a resource policy
+// in an availability zone.
+type ResourcePolicyZoneStatus struct {
+	// Name is the name of the availability zone.
+	Name string `json:"name"`
+
+	// Conditions describes whether the folder, resource pool, and cluster
+	// modules of the resource policy are ready in the availability zone.
+	//
+	// +optional
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
 }
 
 // VSphereClusterModuleStatus describes the observed state of a vSphere
@@ -44,6 +70,19 @@ type VSphereClusterModuleStatus struct {
 	GroupName   string `json:"groupName"`
 	ModuleUuid  string `json:"moduleUUID"` //nolint:revive,stylecheck
 	ClusterMoID string `json:"clusterMoID"`
+
+	// RuleName is the name of the DRS VM-VM rule that realizes the group in
+	// the cluster. It is empty when the group is realized by the cluster
+	// module ModuleUuid.
+	//
+	// +optional
+	RuleName string `json:"ruleName,omitempty"`
+
+	// Members are the names of the VirtualMachines in the cluster that are
+	// members of the group.
+	//
+	// +optional
+	Members []string `json:"members,omitempty"`
 }
 
 // +kubebuilder:object:root=true
@@ -13,6 +13,19 @@ type VirtualMachineWebConsoleRequestSpec struct {
 	// Name is the name of a VM in the same Namespace as this web console
 	// request.
 	Name string `json:"name"`
+
+	// Duration is the requested lifetime of the access via this request. When
+	// not set, a default lifetime configured by the administrator is used. The
+	// lifetime is at most a maximum configured by the administrator.
+	//
+	// +optional
+	Duration *metav1.Duration `json:"duration,omitempty"`
+
+	// RenewalCount is incremented to renew the access via this request before
+	// it expires.
+	//
+	// +optional
+	RenewalCount int32 `json:"renewalCount,omitempty"`
 }
 
 // VirtualMachineWebConsoleRequestStatus describes the observed state of the
@@ -46,6 +59,10 @@ type VirtualMachineWebConsoleRequestStatus struct {
 	// by Go's https://pkg.go.dev/net#ResolveIPAddr and
 	// https://pkg.go.dev/net#ParseIP functions.
 	ProxyAddr string `json:"proxyAddr,omitempty"`
+
+	// RenewalCount is the spec.renewalCount when the access via this request
+	// was last granted.
+	RenewalCount int32 `json:"renewalCount,omitempty"`
 }
 
 // +kubebuilder:object:root=true
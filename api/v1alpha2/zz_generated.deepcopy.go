@@ -11,8 +11,8 @@ package v1alpha2
 import (
 	"encoding/json"
 	"github.com/vmware-tanzu/vm-operator/api/v1alpha2/common"
-	"k8s.io/api/core/v1"
-	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1"
 	runtime "k8s.io/apimachinery/pkg/runtime"
 )
 
@@ -260,6 +260,28 @@ func (in *OVFProperty) DeepCopy() *OVFProperty {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ResourcePolicyZoneStatus) DeepCopyInto(out *ResourcePolicyZoneStatus) {
+	*out = *in
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]v1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ResourcePolicyZoneStatus.
+func (in *ResourcePolicyZoneStatus) DeepCopy() *ResourcePolicyZoneStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(ResourcePolicyZoneStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ResourcePoolSpec) DeepCopyInto(out *ResourcePoolSpec) {
 	*out = *in
@@ -311,6 +333,11 @@ func (in *VGPUDevice) DeepCopy() *VGPUDevice {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *VSphereClusterModuleStatus) DeepCopyInto(out *VSphereClusterModuleStatus) {
 	*out = *in
+	if in.Members != nil {
+		in, out := &in.Members, &out.Members
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VSphereClusterModuleStatus.
@@ -391,6 +418,87 @@ func (in *VirtualMachineAdvancedSpec) DeepCopy() *VirtualMachineAdvancedSpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VirtualMachineAffinityRules) DeepCopyInto(out *VirtualMachineAffinityRules) {
+	*out = *in
+	if in.RequiredDuringSchedulingIgnoredDuringExecution != nil {
+		in, out := &in.RequiredDuringSchedulingIgnoredDuringExecution, &out.RequiredDuringSchedulingIgnoredDuringExecution
+		*out = make([]VirtualMachineAffinityTerm, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.RequiredDuringSchedulingRequiredDuringExecution != nil {
+		in, out := &in.RequiredDuringSchedulingRequiredDuringExecution, &out.RequiredDuringSchedulingRequiredDuringExecution
+		*out = make([]VirtualMachineAffinityTerm, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.PreferredDuringSchedulingIgnoredDuringExecution != nil {
+		in, out := &in.PreferredDuringSchedulingIgnoredDuringExecution, &out.PreferredDuringSchedulingIgnoredDuringExecution
+		*out = make([]VirtualMachineWeightedAffinityTerm, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VirtualMachineAffinityRules.
+func (in *VirtualMachineAffinityRules) DeepCopy() *VirtualMachineAffinityRules {
+	if in == nil {
+		return nil
+	}
+	out := new(VirtualMachineAffinityRules)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VirtualMachineAffinitySpec) DeepCopyInto(out *VirtualMachineAffinitySpec) {
+	*out = *in
+	if in.VMAffinity != nil {
+		in, out := &in.VMAffinity, &out.VMAffinity
+		*out = new(VirtualMachineAffinityRules)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.VMAntiAffinity != nil {
+		in, out := &in.VMAntiAffinity, &out.VMAntiAffinity
+		*out = new(VirtualMachineAffinityRules)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VirtualMachineAffinitySpec.
+func (in *VirtualMachineAffinitySpec) DeepCopy() *VirtualMachineAffinitySpec {
+	if in == nil {
+		return nil
+	}
+	out := new(VirtualMachineAffinitySpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VirtualMachineAffinityTerm) DeepCopyInto(out *VirtualMachineAffinityTerm) {
+	*out = *in
+	if in.LabelSelector != nil {
+		in, out := &in.LabelSelector, &out.LabelSelector
+		*out = new(v1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VirtualMachineAffinityTerm.
+func (in *VirtualMachineAffinityTerm) DeepCopy() *VirtualMachineAffinityTerm {
+	if in == nil {
+		return nil
+	}
+	out := new(VirtualMachineAffinityTerm)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *VirtualMachineBootstrapCloudInitSpec) DeepCopyInto(out *VirtualMachineBootstrapCloudInitSpec) {
 	*out = *in
@@ -612,6 +720,26 @@ func (in *VirtualMachineClassResources) DeepCopy() *VirtualMachineClassResources
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VirtualMachineClassRollingRestart) DeepCopyInto(out *VirtualMachineClassRollingRestart) {
+	*out = *in
+	if in.MaxUnavailable != nil {
+		in, out := &in.MaxUnavailable, &out.MaxUnavailable
+		*out = new(int32)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VirtualMachineClassRollingRestart.
+func (in *VirtualMachineClassRollingRestart) DeepCopy() *VirtualMachineClassRollingRestart {
+	if in == nil {
+		return nil
+	}
+	out := new(VirtualMachineClassRollingRestart)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *VirtualMachineClassSpec) DeepCopyInto(out *VirtualMachineClassSpec) {
 	*out = *in
@@ -622,6 +750,7 @@ func (in *VirtualMachineClassSpec) DeepCopyInto(out *VirtualMachineClassSpec) {
 		*out = make(json.RawMessage, len(*in))
 		copy(*out, *in)
 	}
+	in.UpdatePolicy.DeepCopyInto(&out.UpdatePolicy)
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VirtualMachineClassSpec.
@@ -644,11 +773,16 @@ func (in *VirtualMachineClassStatus) DeepCopyInto(out *VirtualMachineClassStatus
 	}
 	if in.Conditions != nil {
 		in, out := &in.Conditions, &out.Conditions
-		*out = make([]metav1.Condition, len(*in))
+		*out = make([]v1.Condition, len(*in))
 		for i := range *in {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.EffectiveConfigSpec != nil {
+		in, out := &in.EffectiveConfigSpec, &out.EffectiveConfigSpec
+		*out = make(json.RawMessage, len(*in))
+		copy(*out, *in)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VirtualMachineClassStatus.
@@ -661,6 +795,26 @@ func (in *VirtualMachineClassStatus) DeepCopy() *VirtualMachineClassStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VirtualMachineClassUpdatePolicy) DeepCopyInto(out *VirtualMachineClassUpdatePolicy) {
+	*out = *in
+	if in.RollingRestart != nil {
+		in, out := &in.RollingRestart, &out.RollingRestart
+		*out = new(VirtualMachineClassRollingRestart)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VirtualMachineClassUpdatePolicy.
+func (in *VirtualMachineClassUpdatePolicy) DeepCopy() *VirtualMachineClassUpdatePolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(VirtualMachineClassUpdatePolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *VirtualMachineConfigSpec) DeepCopyInto(out *VirtualMachineConfigSpec) {
 	*out = *in
@@ -805,7 +959,7 @@ func (in *VirtualMachineImageStatus) DeepCopyInto(out *VirtualMachineImageStatus
 	out.ProductInfo = in.ProductInfo
 	if in.Conditions != nil {
 		in, out := &in.Conditions, &out.Conditions
-		*out = make([]metav1.Condition, len(*in))
+		*out = make([]v1.Condition, len(*in))
 		for i := range *in {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
@@ -1245,6 +1399,21 @@ func (in *VirtualMachinePublishRequestList) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VirtualMachinePublishRequestOCIArtifactStatus) DeepCopyInto(out *VirtualMachinePublishRequestOCIArtifactStatus) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VirtualMachinePublishRequestOCIArtifactStatus.
+func (in *VirtualMachinePublishRequestOCIArtifactStatus) DeepCopy() *VirtualMachinePublishRequestOCIArtifactStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(VirtualMachinePublishRequestOCIArtifactStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *VirtualMachinePublishRequestSource) DeepCopyInto(out *VirtualMachinePublishRequestSource) {
 	*out = *in
@@ -1264,7 +1433,7 @@ func (in *VirtualMachinePublishRequestSource) DeepCopy() *VirtualMachinePublishR
 func (in *VirtualMachinePublishRequestSpec) DeepCopyInto(out *VirtualMachinePublishRequestSpec) {
 	*out = *in
 	out.Source = in.Source
-	out.Target = in.Target
+	in.Target.DeepCopyInto(&out.Target)
 	if in.TTLSecondsAfterFinished != nil {
 		in, out := &in.TTLSecondsAfterFinished, &out.TTLSecondsAfterFinished
 		*out = new(int64)
@@ -1293,14 +1462,19 @@ func (in *VirtualMachinePublishRequestStatus) DeepCopyInto(out *VirtualMachinePu
 	if in.TargetRef != nil {
 		in, out := &in.TargetRef, &out.TargetRef
 		*out = new(VirtualMachinePublishRequestTarget)
-		**out = **in
+		(*in).DeepCopyInto(*out)
 	}
 	in.CompletionTime.DeepCopyInto(&out.CompletionTime)
 	in.StartTime.DeepCopyInto(&out.StartTime)
 	in.LastAttemptTime.DeepCopyInto(&out.LastAttemptTime)
+	if in.OCIArtifact != nil {
+		in, out := &in.OCIArtifact, &out.OCIArtifact
+		*out = new(VirtualMachinePublishRequestOCIArtifactStatus)
+		**out = **in
+	}
 	if in.Conditions != nil {
 		in, out := &in.Conditions, &out.Conditions
-		*out = make([]metav1.Condition, len(*in))
+		*out = make([]v1.Condition, len(*in))
 		for i := range *in {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
@@ -1321,7 +1495,7 @@ func (in *VirtualMachinePublishRequestStatus) DeepCopy() *VirtualMachinePublishR
 func (in *VirtualMachinePublishRequestTarget) DeepCopyInto(out *VirtualMachinePublishRequestTarget) {
 	*out = *in
 	out.Item = in.Item
-	out.Location = in.Location
+	in.Location.DeepCopyInto(&out.Location)
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VirtualMachinePublishRequestTarget.
@@ -1352,6 +1526,11 @@ func (in *VirtualMachinePublishRequestTargetItem) DeepCopy() *VirtualMachinePubl
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *VirtualMachinePublishRequestTargetLocation) DeepCopyInto(out *VirtualMachinePublishRequestTargetLocation) {
 	*out = *in
+	if in.OCI != nil {
+		in, out := &in.OCI, &out.OCI
+		*out = new(VirtualMachinePublishRequestTargetOCILocation)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VirtualMachinePublishRequestTargetLocation.
@@ -1364,6 +1543,21 @@ func (in *VirtualMachinePublishRequestTargetLocation) DeepCopy() *VirtualMachine
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VirtualMachinePublishRequestTargetOCILocation) DeepCopyInto(out *VirtualMachinePublishRequestTargetOCILocation) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VirtualMachinePublishRequestTargetOCILocation.
+func (in *VirtualMachinePublishRequestTargetOCILocation) DeepCopy() *VirtualMachinePublishRequestTargetOCILocation {
+	if in == nil {
+		return nil
+	}
+	out := new(VirtualMachinePublishRequestTargetOCILocation)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *VirtualMachineReadinessGate) DeepCopyInto(out *VirtualMachineReadinessGate) {
 	*out = *in
@@ -1649,7 +1843,23 @@ func (in *VirtualMachineSetResourcePolicyStatus) DeepCopyInto(out *VirtualMachin
 	if in.ClusterModules != nil {
 		in, out := &in.ClusterModules, &out.ClusterModules
 		*out = make([]VSphereClusterModuleStatus, len(*in))
-		copy(*out, *in)
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Zones != nil {
+		in, out := &in.Zones, &out.Zones
+		*out = make([]ResourcePolicyZoneStatus, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]v1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
 	}
 }
 
@@ -1682,6 +1892,18 @@ func (in *VirtualMachineSpec) DeepCopyInto(out *VirtualMachineSpec) {
 		copy(*out, *in)
 	}
 	in.Advanced.DeepCopyInto(&out.Advanced)
+	if in.Affinity != nil {
+		in, out := &in.Affinity, &out.Affinity
+		*out = new(VirtualMachineAffinitySpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.TopologySpreadConstraints != nil {
+		in, out := &in.TopologySpreadConstraints, &out.TopologySpreadConstraints
+		*out = make([]VirtualMachineTopologySpreadConstraint, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 	out.Reserved = in.Reserved
 }
 
@@ -1710,7 +1932,7 @@ func (in *VirtualMachineStatus) DeepCopyInto(out *VirtualMachineStatus) {
 	}
 	if in.Conditions != nil {
 		in, out := &in.Conditions, &out.Conditions
-		*out = make([]metav1.Condition, len(*in))
+		*out = make([]v1.Condition, len(*in))
 		for i := range *in {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
@@ -1730,6 +1952,11 @@ func (in *VirtualMachineStatus) DeepCopyInto(out *VirtualMachineStatus) {
 		*out = new(bool)
 		**out = **in
 	}
+	if in.LastTask != nil {
+		in, out := &in.LastTask, &out.LastTask
+		*out = new(VirtualMachineTaskInfo)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VirtualMachineStatus.
@@ -1742,6 +1969,49 @@ func (in *VirtualMachineStatus) DeepCopy() *VirtualMachineStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VirtualMachineTaskFault) DeepCopyInto(out *VirtualMachineTaskFault) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VirtualMachineTaskFault.
+func (in *VirtualMachineTaskFault) DeepCopy() *VirtualMachineTaskFault {
+	if in == nil {
+		return nil
+	}
+	out := new(VirtualMachineTaskFault)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VirtualMachineTaskInfo) DeepCopyInto(out *VirtualMachineTaskInfo) {
+	*out = *in
+	if in.StartTime != nil {
+		in, out := &in.StartTime, &out.StartTime
+		*out = (*in).DeepCopy()
+	}
+	if in.CompleteTime != nil {
+		in, out := &in.CompleteTime, &out.CompleteTime
+		*out = (*in).DeepCopy()
+	}
+	if in.Fault != nil {
+		in, out := &in.Fault, &out.Fault
+		*out = new(VirtualMachineTaskFault)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VirtualMachineTaskInfo.
+func (in *VirtualMachineTaskInfo) DeepCopy() *VirtualMachineTaskInfo {
+	if in == nil {
+		return nil
+	}
+	out := new(VirtualMachineTaskInfo)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *VirtualMachineTemplate) DeepCopyInto(out *VirtualMachineTemplate) {
 	*out = *in
@@ -1763,6 +2033,26 @@ func (in *VirtualMachineTemplate) DeepCopy() *VirtualMachineTemplate {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VirtualMachineTopologySpreadConstraint) DeepCopyInto(out *VirtualMachineTopologySpreadConstraint) {
+	*out = *in
+	if in.LabelSelector != nil {
+		in, out := &in.LabelSelector, &out.LabelSelector
+		*out = new(v1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VirtualMachineTopologySpreadConstraint.
+func (in *VirtualMachineTopologySpreadConstraint) DeepCopy() *VirtualMachineTopologySpreadConstraint {
+	if in == nil {
+		return nil
+	}
+	out := new(VirtualMachineTopologySpreadConstraint)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *VirtualMachineVolume) DeepCopyInto(out *VirtualMachineVolume) {
 	*out = *in
@@ -1809,7 +2099,7 @@ func (in *VirtualMachineVolumeSource) DeepCopyInto(out *VirtualMachineVolumeSour
 	*out = *in
 	if in.PersistentVolumeClaim != nil {
 		in, out := &in.PersistentVolumeClaim, &out.PersistentVolumeClaim
-		*out = new(v1.PersistentVolumeClaimVolumeSource)
+		*out = new(corev1.PersistentVolumeClaimVolumeSource)
 		**out = **in
 	}
 }
@@ -1844,7 +2134,7 @@ func (in *VirtualMachineWebConsoleRequest) DeepCopyInto(out *VirtualMachineWebCo
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
-	out.Spec = in.Spec
+	in.Spec.DeepCopyInto(&out.Spec)
 	in.Status.DeepCopyInto(&out.Status)
 }
 
@@ -1901,6 +2191,11 @@ func (in *VirtualMachineWebConsoleRequestList) DeepCopyObject() runtime.Object {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *VirtualMachineWebConsoleRequestSpec) DeepCopyInto(out *VirtualMachineWebConsoleRequestSpec) {
 	*out = *in
+	if in.Duration != nil {
+		in, out := &in.Duration, &out.Duration
+		*out = new(v1.Duration)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VirtualMachineWebConsoleRequestSpec.
@@ -1928,3 +2223,19 @@ func (in *VirtualMachineWebConsoleRequestStatus) DeepCopy() *VirtualMachineWebCo
 	in.DeepCopyInto(out)
 	return out
 }
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VirtualMachineWeightedAffinityTerm) DeepCopyInto(out *VirtualMachineWeightedAffinityTerm) {
+	*out = *in
+	in.AffinityTerm.DeepCopyInto(&out.AffinityTerm)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VirtualMachineWeightedAffinityTerm.
+func (in *VirtualMachineWeightedAffinityTerm) DeepCopy() *VirtualMachineWeightedAffinityTerm {
+	if in == nil {
+		return nil
+	}
+	out := new(VirtualMachineWeightedAffinityTerm)
+	in.DeepCopyInto(out)
+	return out
+}
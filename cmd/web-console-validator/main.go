@@ -1,4 +1,4 @@
-// Copyright (c) 2022 VMware, Inc. All Rights Reserved.
+// Copyright (c) 2022-2023 VMware, Inc. All Rights Reserved.
 // SPDX-License-Identifier: Apache-2.0
 
 package main
@@ -9,6 +9,7 @@ import (
 	"os"
 	"strconv"
 
+	"golang.org/x/time/rate"
 	klog "k8s.io/klog/v2"
 	"k8s.io/klog/v2/klogr"
 	ctrllog "sigs.k8s.io/controller-runtime/pkg/log"
@@ -20,15 +21,31 @@ import (
 var (
 	defaultServerPort = 9868
 	defaultServerPath = "/validate"
+
+	defaultSerialConsolePath = "/serial-console"
+	defaultVSPCPort          = 13370
+
+	defaultSingleUseTickets = false
+	defaultRateLimitQPS     = 20.0
+	defaultRateLimitBurst   = 40
 )
 
 func init() {
 	if v := os.Getenv("SERVER_PATH"); v != "" {
 		defaultServerPath = v
 	}
+	if v := os.Getenv("SERIAL_CONSOLE_PATH"); v != "" {
+		defaultSerialConsolePath = v
+	}
 	if v, err := strconv.Atoi(os.Getenv("SERVER_PORT")); err == nil {
 		defaultServerPort = v
 	}
+	if v, err := strconv.Atoi(os.Getenv("VSPC_PORT")); err == nil {
+		defaultVSPCPort = v
+	}
+	if v, err := strconv.ParseBool(os.Getenv("SINGLE_USE_TICKETS")); err == nil {
+		defaultSingleUseTickets = v
+	}
 }
 
 func main() {
@@ -50,6 +67,31 @@ func main() {
 		defaultServerPath,
 		"The pattern path to handle the web-console validation requests.",
 	)
+	serialConsolePath := flag.String(
+		"serial-console-path",
+		defaultSerialConsolePath,
+		"The pattern path to handle the serial console stream requests.",
+	)
+	vspcPort := flag.Int(
+		"vspc-port",
+		defaultVSPCPort,
+		"The port on which the virtual serial port concentrator listens for the ESXi hosts' serial port connections. Zero disables serial consoles.",
+	)
+	singleUseTickets := flag.Bool(
+		"single-use-tickets",
+		defaultSingleUseTickets,
+		"Whether a web console ticket may only be validated once.",
+	)
+	rateLimitQPS := flag.Float64(
+		"rate-limit-qps",
+		defaultRateLimitQPS,
+		"The maximum average number of requests per second to handle from each client. Zero disables rate limiting.",
+	)
+	rateLimitBurst := flag.Int(
+		"rate-limit-burst",
+		defaultRateLimitBurst,
+		"The maximum number of requests to handle from each client in a burst.",
+	)
 
 	flag.Parse()
 
@@ -58,11 +100,22 @@ func main() {
 		os.Exit(1)
 	}
 
-	logger.Info("Starting the web-console validation server", "port", *serverPort, "path", *serverPath)
+	webconsolevalidation.SingleUseTickets = *singleUseTickets
+	if *rateLimitQPS > 0 {
+		webconsolevalidation.RateLimiter = webconsolevalidation.NewClientRateLimiter(rate.Limit(*rateLimitQPS), *rateLimitBurst)
+	}
+
+	logger.Info("Starting the web-console validation server", "port", *serverPort, "path", *serverPath,
+		"serialConsolePath", *serialConsolePath, "vspcPort", *vspcPort, "singleUseTickets", *singleUseTickets,
+		"rateLimitQPS", *rateLimitQPS, "rateLimitBurst", *rateLimitBurst)
 
 	// Pass serverPath to the RunServer so one can check what path the server is listening on
 	// by looking at the commands specified in the server deployment spec.
-	runErr := webconsolevalidation.RunServer(":"+strconv.Itoa(*serverPort), *serverPath)
+	var vspcAddr string
+	if *vspcPort > 0 {
+		vspcAddr = ":" + strconv.Itoa(*vspcPort)
+	}
+	runErr := webconsolevalidation.RunServer(":"+strconv.Itoa(*serverPort), *serverPath, *serialConsolePath, vspcAddr)
 	if runErr != nil && runErr != http.ErrServerClosed {
 		logger.Error(runErr, "Error occurred while running the web-console validation server!")
 	}
@@ -16,6 +16,7 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/controller"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
 	"github.com/go-logr/logr"
 
@@ -25,8 +26,12 @@ import (
 	"github.com/vmware-tanzu/vm-operator/controllers/contentlibrary/utils"
 	"github.com/vmware-tanzu/vm-operator/pkg/conditions"
 	"github.com/vmware-tanzu/vm-operator/pkg/context"
+	"github.com/vmware-tanzu/vm-operator/pkg/imagetrust"
+	"github.com/vmware-tanzu/vm-operator/pkg/lib"
+	pkgmgr "github.com/vmware-tanzu/vm-operator/pkg/manager"
 	"github.com/vmware-tanzu/vm-operator/pkg/metrics"
 	"github.com/vmware-tanzu/vm-operator/pkg/record"
+	"github.com/vmware-tanzu/vm-operator/pkg/vmimage"
 	"github.com/vmware-tanzu/vm-operator/pkg/vmprovider"
 )
 
@@ -47,12 +52,43 @@ func AddToManager(ctx *context.ControllerManagerContext, mgr manager.Manager) er
 		ctx.VMProvider,
 	)
 
-	return ctrl.NewControllerManagedBy(mgr).
+	builder := ctrl.NewControllerManagedBy(mgr).
 		For(cclItemType).
 		// We do not set Owns(ClusterVirtualMachineImage) here as we call SetControllerReference()
 		// when creating such resources in the reconciling process below.
-		WithOptions(controller.Options{MaxConcurrentReconciles: ctx.MaxConcurrentReconciles}).
-		Complete(r)
+		WithOptions(controller.Options{MaxConcurrentReconciles: ctx.MaxConcurrentReconciles})
+
+	// The images are verified again when the keys of the image trust policy change.
+	if lib.IsImageTrustPolicyEnabled() {
+		nsCache, err := pkgmgr.NewNamespaceCache(mgr, &ctx.SyncPeriod, ctx.Namespace)
+		if err != nil {
+			return err
+		}
+		builder = utils.WatchImageTrustPolicySecret(builder, nsCache, imageTrustPolicyToItemsMapperFn(ctx, r.Client))
+	}
+
+	return builder.Complete(r)
+}
+
+// imageTrustPolicyToItemsMapperFn returns a mapper function that can be used to queue reconcile requests
+// for all the ClusterContentLibraryItems in response to an event on the image trust policy Secret.
+func imageTrustPolicyToItemsMapperFn(ctx *context.ControllerManagerContext, c client.Reader) func(o client.Object) []reconcile.Request {
+	return func(o client.Object) []reconcile.Request {
+		logger := ctx.Logger.WithValues("name", o.GetName(), "namespace", o.GetNamespace())
+		logger.V(4).Info("Reconciling all ClusterContentLibraryItems because of an image trust policy Secret watch")
+
+		itemList := &imgregv1a1.ClusterContentLibraryItemList{}
+		if err := c.List(ctx, itemList); err != nil {
+			logger.Error(err, "Failed to list ClusterContentLibraryItems for reconciliation due to image trust policy Secret watch")
+			return nil
+		}
+
+		requests := make([]reconcile.Request, 0, len(itemList.Items))
+		for i := range itemList.Items {
+			requests = append(requests, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(&itemList.Items[i])})
+		}
+		return requests
+	}
 }
 
 func NewReconciler(
@@ -84,6 +120,7 @@ type Reconciler struct {
 // +kubebuilder:rbac:groups=imageregistry.vmware.com,resources=clustercontentlibraryitems/status,verbs=get
 // +kubebuilder:rbac:groups=vmoperator.vmware.com,resources=clustervirtualmachineimages,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=vmoperator.vmware.com,resources=clustervirtualmachineimages/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups="",resources=secrets,verbs=get;list;watch
 
 func (r *Reconciler) Reconcile(ctx goctx.Context, req ctrl.Request) (_ ctrl.Result, reterr error) {
 	logger := r.Logger.WithValues("cclItemName", req.Name)
@@ -148,7 +185,7 @@ func (r *Reconciler) ReconcileNormal(ctx *context.ClusterContentLibraryItemConte
 	ctx.CVMI = cvmi
 
 	var didSync bool
-	var syncErr error
+	var syncErr, verifyErr error
 	var savedStatus *vmopv1.VirtualMachineImageStatus
 
 	opRes, createOrPatchErr := controllerutil.CreateOrPatch(ctx, r.Client, cvmi, func() error {
@@ -172,10 +209,17 @@ func (r *Reconciler) ReconcileNormal(ctx *context.ClusterContentLibraryItemConte
 			ctx.Logger.Info("ClusterContentLibraryItem is not ready yet, skipping image content sync")
 		} else {
 			conditions.MarkTrue(cvmi, vmopv1.VirtualMachineImageProviderReadyCondition)
+			prevContentVersion := cvmi.Status.ContentVersion
 			syncErr = r.syncImageContent(ctx)
 			didSync = true
+			if syncErr == nil {
+				verifyErr = r.verifyImageTrust(ctx, prevContentVersion)
+			}
 		}
 
+		// Set the labels after the sync as they are derived from the synced product info.
+		vmimage.SetProductLabels(cvmi, cvmi.Spec.ProductInfo)
+
 		// Do not return syncErr here as we still want to patch the updated fields we get above.
 		return nil
 	})
@@ -207,6 +251,11 @@ func (r *Reconciler) ReconcileNormal(ctx *context.ClusterContentLibraryItemConte
 		return syncErr
 	}
 
+	if verifyErr != nil {
+		ctx.Logger.Error(verifyErr, "Failed to verify ClusterVirtualMachineImage against the image trust policy")
+		return verifyErr
+	}
+
 	ctx.Logger.Info("Successfully reconciled ClusterVirtualMachineImage", "contentVersion", savedStatus.ContentVersion)
 	return nil
 }
@@ -231,6 +280,8 @@ func (r *Reconciler) setUpCVMIFromCCLItem(ctx *context.ClusterContentLibraryItem
 		}
 	}
 
+	vmimage.SetDeprecatedLabel(cvmi, cclItem.Labels)
+
 	// Do not initialize the Spec or Status directly as it might overwrite the existing fields.
 	cvmi.Spec.Type = string(cclItem.Status.Type)
 	cvmi.Spec.ImageID = cclItem.Spec.UUID
@@ -288,3 +339,53 @@ func (r *Reconciler) syncImageContent(ctx *context.ClusterContentLibraryItemCont
 	r.Recorder.EmitEvent(cvmi, "Update", err, false)
 	return err
 }
+
+// verifyImageTrust verifies the library item against the image trust policy, if one is configured, and
+// updates the image's trust verified condition. It skips the verification if the image was already verified
+// against the same keys and its content version has not changed. An error is returned if the verification
+// could not be completed.
+func (r *Reconciler) verifyImageTrust(ctx *context.ClusterContentLibraryItemContext, prevContentVersion string) error {
+	cvmi := ctx.CVMI
+	if !lib.IsImageTrustPolicyEnabled() {
+		conditions.Delete(cvmi, vmopv1.VirtualMachineImageTrustVerifiedCondition)
+		cvmi.Status.TrustPolicyHash = ""
+		return nil
+	}
+
+	policy, err := utils.GetImageTrustPolicy(ctx, r.Client)
+	if err == nil {
+		policyHash := policy.Hash()
+		if conditions.IsTrue(cvmi, vmopv1.VirtualMachineImageTrustVerifiedCondition) &&
+			cvmi.Status.ContentVersion == prevContentVersion &&
+			cvmi.Status.TrustPolicyHash == policyHash {
+			return nil
+		}
+
+		err = r.VMProvider.VerifyVirtualMachineImage(ctx, ctx.CCLItem, policy)
+		cvmi.Status.TrustPolicyHash = policyHash
+	}
+
+	switch {
+	case err == nil:
+		conditions.MarkTrue(cvmi, vmopv1.VirtualMachineImageTrustVerifiedCondition)
+	case imagetrust.IsVerificationError(err):
+		conditions.MarkFalse(cvmi,
+			vmopv1.VirtualMachineImageTrustVerifiedCondition,
+			vmopv1.VirtualMachineImageNotTrustedReason,
+			vmopv1.ConditionSeverityError,
+			"%s", err)
+		r.Recorder.Warnf(cvmi, "VerifyFailure", "Image failed trust verification: %s", err)
+		// Do not requeue as the library item will not pass verification until its content or the policy
+		// changes, and the policy Secret is watched.
+		return nil
+	default:
+		cvmi.Status.TrustPolicyHash = ""
+		conditions.MarkFalse(cvmi,
+			vmopv1.VirtualMachineImageTrustVerifiedCondition,
+			vmopv1.VirtualMachineImageTrustNotVerifiedReason,
+			vmopv1.ConditionSeverityWarning,
+			"%s", err)
+	}
+
+	return err
+}
@@ -6,6 +6,7 @@ package clustercontentlibraryitem_test
 import (
 	goctx "context"
 	"fmt"
+	"os"
 
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
@@ -21,6 +22,8 @@ import (
 	"github.com/vmware-tanzu/vm-operator/controllers/contentlibrary/utils"
 	"github.com/vmware-tanzu/vm-operator/pkg/conditions"
 	"github.com/vmware-tanzu/vm-operator/pkg/context"
+	"github.com/vmware-tanzu/vm-operator/pkg/imagetrust"
+	"github.com/vmware-tanzu/vm-operator/pkg/lib"
 	providerfake "github.com/vmware-tanzu/vm-operator/pkg/vmprovider/fake"
 	"github.com/vmware-tanzu/vm-operator/test/builder"
 )
@@ -155,6 +158,18 @@ func unitTestsReconcile() {
 				})
 			})
 
+			When("ClusterContentLibraryItem is deprecated", func() {
+
+				BeforeEach(func() {
+					cclItem.Labels = map[string]string{vmopv1.VirtualMachineImageDeprecatedLabel: "true"}
+				})
+
+				It("should mark the ClusterVirtualMachineImage as deprecated", func() {
+					cvmi := getCVMIFromCCLItem(*ctx, cclItem)
+					Expect(cvmi.Labels).To(HaveKeyWithValue(vmopv1.VirtualMachineImageDeprecatedLabel, "true"))
+				})
+			})
+
 			When("ClusterVirtualMachineImage resource is created but not up-to-date", func() {
 
 				BeforeEach(func() {
@@ -209,6 +224,72 @@ func unitTestsReconcile() {
 		})
 	})
 
+	Context("ReconcileNormal with an image trust policy", func() {
+		var (
+			oldGetImageTrustPolicySecretNameFunc func() string
+			verifyErr                            error
+			policyHash                           string
+		)
+
+		BeforeEach(func() {
+			verifyErr = nil
+
+			oldGetImageTrustPolicySecretNameFunc = lib.GetImageTrustPolicySecretName
+			lib.GetImageTrustPolicySecretName = func() string {
+				return "image-trust-policy"
+			}
+			Expect(lib.SetVMOpNamespaceEnv("vmop-system")).To(Succeed())
+
+			secret := utils.DummyImageTrustPolicySecret("image-trust-policy", "vmop-system")
+			policy, err := imagetrust.PolicyFromSecret(secret)
+			Expect(err).ToNot(HaveOccurred())
+			policyHash = policy.Hash()
+			initObjects = append(initObjects, secret)
+		})
+
+		AfterEach(func() {
+			lib.GetImageTrustPolicySecretName = oldGetImageTrustPolicySecretNameFunc
+			Expect(os.Unsetenv(lib.VmopNamespaceEnv)).To(Succeed())
+		})
+
+		JustBeforeEach(func() {
+			fakeVMProvider.VerifyVirtualMachineImageFn = func(_ goctx.Context, cli client.Object, _ *imagetrust.Policy) error {
+				Expect(cli.GetName()).To(Equal(cclItem.Name))
+				return verifyErr
+			}
+
+			cclItemCtx := &context.ClusterContentLibraryItemContext{
+				Context:      ctx,
+				Logger:       ctx.Logger,
+				CCLItem:      cclItem,
+				ImageObjName: utils.GetTestVMINameFrom(cclItem.Name),
+			}
+			Expect(reconciler.ReconcileNormal(cclItemCtx)).To(Succeed())
+		})
+
+		When("the library item passes verification", func() {
+			It("should mark ClusterVirtualMachineImage condition as trust verified", func() {
+				cvmi := getCVMIFromCCLItem(*ctx, cclItem)
+				Expect(conditions.IsTrue(cvmi, vmopv1.VirtualMachineImageTrustVerifiedCondition)).To(BeTrue())
+				Expect(cvmi.Status.TrustPolicyHash).To(Equal(policyHash))
+			})
+		})
+
+		When("the library item fails verification", func() {
+			BeforeEach(func() {
+				verifyErr = imagetrust.NewVerificationError("library item does not have an OVF manifest")
+			})
+
+			It("should mark ClusterVirtualMachineImage condition as not trusted", func() {
+				cvmi := getCVMIFromCCLItem(*ctx, cclItem)
+				condition := conditions.Get(cvmi, vmopv1.VirtualMachineImageTrustVerifiedCondition)
+				Expect(condition).ToNot(BeNil())
+				Expect(condition.Status).To(Equal(corev1.ConditionFalse))
+				Expect(condition.Reason).To(Equal(vmopv1.VirtualMachineImageNotTrustedReason))
+			})
+		})
+	})
+
 	Context("ReconcileDelete", func() {
 
 		JustBeforeEach(func() {
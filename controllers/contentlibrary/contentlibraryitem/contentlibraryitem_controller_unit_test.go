@@ -6,6 +6,7 @@ package contentlibraryitem_test
 import (
 	goctx "context"
 	"fmt"
+	"os"
 
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
@@ -21,6 +22,8 @@ import (
 	"github.com/vmware-tanzu/vm-operator/controllers/contentlibrary/utils"
 	"github.com/vmware-tanzu/vm-operator/pkg/conditions"
 	"github.com/vmware-tanzu/vm-operator/pkg/context"
+	"github.com/vmware-tanzu/vm-operator/pkg/imagetrust"
+	"github.com/vmware-tanzu/vm-operator/pkg/lib"
 	providerfake "github.com/vmware-tanzu/vm-operator/pkg/vmprovider/fake"
 	"github.com/vmware-tanzu/vm-operator/test/builder"
 )
@@ -148,6 +151,35 @@ func unitTestsReconcile() {
 				})
 			})
 
+			When("ContentLibraryItem is deprecated", func() {
+				BeforeEach(func() {
+					clItem.Labels = map[string]string{vmopv1.VirtualMachineImageDeprecatedLabel: "true"}
+				})
+
+				It("should mark the VirtualMachineImage as deprecated", func() {
+					vmi := getVMIFromCLItem(*ctx, clItem)
+					Expect(vmi.Labels).To(HaveKeyWithValue(vmopv1.VirtualMachineImageDeprecatedLabel, "true"))
+				})
+			})
+
+			When("ContentLibraryItem is no longer deprecated", func() {
+				BeforeEach(func() {
+					existingVMI := &vmopv1.VirtualMachineImage{
+						ObjectMeta: metav1.ObjectMeta{
+							Name:      utils.GetTestVMINameFrom(clItem.Name),
+							Namespace: clItem.Namespace,
+							Labels:    map[string]string{vmopv1.VirtualMachineImageDeprecatedLabel: "true"},
+						},
+					}
+					initObjects = append(initObjects, existingVMI)
+				})
+
+				It("should remove the deprecated label from the VirtualMachineImage", func() {
+					vmi := getVMIFromCLItem(*ctx, clItem)
+					Expect(vmi.Labels).ToNot(HaveKey(vmopv1.VirtualMachineImageDeprecatedLabel))
+				})
+			})
+
 			When("VirtualMachineImage resource is created but not up-to-date", func() {
 				BeforeEach(func() {
 					vmiName := utils.GetTestVMINameFrom(clItem.Name)
@@ -199,6 +231,111 @@ func unitTestsReconcile() {
 		})
 	})
 
+	Context("ReconcileNormal with an image trust policy", func() {
+		var (
+			oldGetImageTrustPolicySecretNameFunc func() string
+			verifyErr                            error
+			verifyCalled                         bool
+			policyHash                           string
+		)
+
+		BeforeEach(func() {
+			verifyErr = nil
+			verifyCalled = false
+
+			oldGetImageTrustPolicySecretNameFunc = lib.GetImageTrustPolicySecretName
+			lib.GetImageTrustPolicySecretName = func() string {
+				return "image-trust-policy"
+			}
+			Expect(lib.SetVMOpNamespaceEnv("vmop-system")).To(Succeed())
+
+			secret := utils.DummyImageTrustPolicySecret("image-trust-policy", "vmop-system")
+			policy, err := imagetrust.PolicyFromSecret(secret)
+			Expect(err).ToNot(HaveOccurred())
+			policyHash = policy.Hash()
+			initObjects = append(initObjects, secret)
+		})
+
+		AfterEach(func() {
+			lib.GetImageTrustPolicySecretName = oldGetImageTrustPolicySecretNameFunc
+			Expect(os.Unsetenv(lib.VmopNamespaceEnv)).To(Succeed())
+		})
+
+		JustBeforeEach(func() {
+			fakeVMProvider.VerifyVirtualMachineImageFn = func(_ goctx.Context, cli client.Object, policy *imagetrust.Policy) error {
+				verifyCalled = true
+				Expect(cli.GetName()).To(Equal(clItem.Name))
+				Expect(policy.PublicKeys).To(HaveLen(1))
+				return verifyErr
+			}
+
+			clItemCtx := &context.ContentLibraryItemContext{
+				Context:      ctx,
+				Logger:       ctx.Logger,
+				CLItem:       clItem,
+				ImageObjName: utils.GetTestVMINameFrom(clItem.Name),
+			}
+			Expect(reconciler.ReconcileNormal(clItemCtx)).To(Succeed())
+		})
+
+		When("the library item passes verification", func() {
+			It("should mark VirtualMachineImage condition as trust verified", func() {
+				Expect(verifyCalled).To(BeTrue())
+				vmi := getVMIFromCLItem(*ctx, clItem)
+				Expect(conditions.IsTrue(vmi, vmopv1.VirtualMachineImageTrustVerifiedCondition)).To(BeTrue())
+				Expect(vmi.Status.TrustPolicyHash).To(Equal(policyHash))
+			})
+		})
+
+		When("the library item fails verification", func() {
+			BeforeEach(func() {
+				verifyErr = imagetrust.NewVerificationError("file photon-disk1.vmdk does not match its checksum")
+			})
+
+			It("should mark VirtualMachineImage condition as not trusted", func() {
+				vmi := getVMIFromCLItem(*ctx, clItem)
+				condition := conditions.Get(vmi, vmopv1.VirtualMachineImageTrustVerifiedCondition)
+				Expect(condition).ToNot(BeNil())
+				Expect(condition.Status).To(Equal(corev1.ConditionFalse))
+				Expect(condition.Reason).To(Equal(vmopv1.VirtualMachineImageNotTrustedReason))
+				Expect(condition.Message).To(ContainSubstring("does not match its checksum"))
+			})
+		})
+
+		When("the VirtualMachineImage is already verified and up-to-date", func() {
+			BeforeEach(func() {
+				upToDateVMI := utils.GetExpectedVMIFrom(*clItem, nil)
+				conditions.MarkTrue(upToDateVMI, vmopv1.VirtualMachineImageTrustVerifiedCondition)
+				upToDateVMI.Status.TrustPolicyHash = policyHash
+				initObjects = append(initObjects, upToDateVMI)
+			})
+
+			It("should skip verifying the library item", func() {
+				Expect(verifyCalled).To(BeFalse())
+				vmi := getVMIFromCLItem(*ctx, clItem)
+				Expect(conditions.IsTrue(vmi, vmopv1.VirtualMachineImageTrustVerifiedCondition)).To(BeTrue())
+			})
+		})
+
+		When("the VirtualMachineImage was verified against other keys", func() {
+			BeforeEach(func() {
+				verifyErr = imagetrust.NewVerificationError("manifest signature is not signed by a trusted key")
+
+				verifiedVMI := utils.GetExpectedVMIFrom(*clItem, nil)
+				conditions.MarkTrue(verifiedVMI, vmopv1.VirtualMachineImageTrustVerifiedCondition)
+				verifiedVMI.Status.TrustPolicyHash = "removed-keys-hash"
+				initObjects = append(initObjects, verifiedVMI)
+			})
+
+			It("should verify the library item again", func() {
+				Expect(verifyCalled).To(BeTrue())
+				vmi := getVMIFromCLItem(*ctx, clItem)
+				Expect(conditions.IsFalse(vmi, vmopv1.VirtualMachineImageTrustVerifiedCondition)).To(BeTrue())
+				Expect(vmi.Status.TrustPolicyHash).To(Equal(policyHash))
+			})
+		})
+	})
+
 	Context("ReconcileDelete", func() {
 
 		JustBeforeEach(func() {
@@ -1,4 +1,4 @@
-// Copyright (c) 2020-2021 VMware, Inc. All Rights Reserved.
+// Copyright (c) 2020-2023 VMware, Inc. All Rights Reserved.
 // SPDX-License-Identifier: Apache-2.0
 
 package contentsource
@@ -27,6 +27,7 @@ import (
 	"github.com/vmware-tanzu/vm-operator/pkg/context"
 	"github.com/vmware-tanzu/vm-operator/pkg/metrics"
 	"github.com/vmware-tanzu/vm-operator/pkg/record"
+	"github.com/vmware-tanzu/vm-operator/pkg/vmimage"
 	"github.com/vmware-tanzu/vm-operator/pkg/vmprovider"
 )
 
@@ -137,6 +138,8 @@ func (r *Reconciler) UpdateImage(ctx goctx.Context, currentImage, expectedImage
 
 	beforeUpdate := currentImage.DeepCopy()
 	currentImage.Annotations = expectedImage.Annotations
+	// Preserve the other labels, such as the deprecated label, that may have been set on the image.
+	vmimage.SetProductLabels(&currentImage, expectedImage.Spec.ProductInfo)
 	currentImage.OwnerReferences = expectedImage.OwnerReferences
 	currentImage.Spec = expectedImage.Spec
 
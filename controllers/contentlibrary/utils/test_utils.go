@@ -5,6 +5,11 @@ package utils
 
 import (
 	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/pem"
 	"strings"
 
 	corev1 "k8s.io/api/core/v1"
@@ -267,3 +272,25 @@ func PopulateRuntimeFieldsTo(vmi, appliedVMI crtlclient.Object) {
 		vmi.SetOwnerReferences(ownerReferences)
 	}
 }
+
+// DummyImageTrustPolicySecret returns an image trust policy Secret with a generated ECDSA public key.
+func DummyImageTrustPolicySecret(name, namespace string) *corev1.Secret {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		panic(err)
+	}
+	der, err := x509.MarshalPKIXPublicKey(&key.PublicKey)
+	if err != nil {
+		panic(err)
+	}
+
+	return &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: namespace,
+		},
+		Data: map[string][]byte{
+			"cosign.pub": pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}),
+		},
+	}
+}
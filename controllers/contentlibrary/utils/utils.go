@@ -1,4 +1,4 @@
-// Copyright (c) 2022 VMware, Inc. All Rights Reserved.
+// Copyright (c) 2022-2023 VMware, Inc. All Rights Reserved.
 // SPDX-License-Identifier: Apache-2.0
 
 package utils
@@ -10,11 +10,18 @@ import (
 
 	corev1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/source"
 
 	imgregv1a1 "github.com/vmware-tanzu/vm-operator/external/image-registry/api/v1alpha1"
 
 	vmopv1 "github.com/vmware-tanzu/vm-operator/api/v1alpha1"
+	"github.com/vmware-tanzu/vm-operator/pkg/imagetrust"
+	"github.com/vmware-tanzu/vm-operator/pkg/lib"
 )
 
 // GetImageFieldNameFromItem returns the Image field name in format of "vmi-<uuid>"
@@ -65,3 +72,39 @@ func GetVMImageSpecStatus(ctx context.Context, ctrlClient client.Client, imageNa
 
 	return spec, status, err
 }
+
+// GetImageTrustPolicy returns the image trust policy from the Secret, in the VM Operator pod's namespace, that
+// is named by lib.GetImageTrustPolicySecretName.
+func GetImageTrustPolicy(ctx context.Context, ctrlClient client.Client) (*imagetrust.Policy, error) {
+	namespace, err := lib.GetVMOpNamespaceFromEnv()
+	if err != nil {
+		return nil, err
+	}
+
+	secret := &corev1.Secret{}
+	key := client.ObjectKey{Name: lib.GetImageTrustPolicySecretName(), Namespace: namespace}
+	if err := ctrlClient.Get(ctx, key, secret); err != nil {
+		return nil, fmt.Errorf("failed to get image trust policy Secret %s: %w", key, err)
+	}
+
+	return imagetrust.PolicyFromSecret(secret)
+}
+
+// WatchImageTrustPolicySecret adds a watch of the image trust policy Secret whose events are mapped to
+// reconcile requests by mapFn. Secrets are not cached by the manager, so the Secret is watched with
+// nsCache, a cache of the VM Operator pod's namespace.
+func WatchImageTrustPolicySecret(
+	b *builder.Builder,
+	nsCache cache.Cache,
+	mapFn handler.MapFunc) *builder.Builder {
+
+	secretName := lib.GetImageTrustPolicySecretName()
+	return b.Watches(source.NewKindWithCache(&corev1.Secret{}, nsCache),
+		handler.EnqueueRequestsFromMapFunc(mapFn),
+		builder.WithPredicates(
+			predicate.NewPredicateFuncs(func(o client.Object) bool {
+				return o.GetName() == secretName
+			}),
+			predicate.ResourceVersionChangedPredicate{},
+		))
+}
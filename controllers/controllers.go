@@ -1,4 +1,4 @@
-// Copyright (c) 2019-2022 VMware, Inc. All Rights Reserved.
+// Copyright (c) 2019-2023 VMware, Inc. All Rights Reserved.
 // SPDX-License-Identifier: Apache-2.0
 
 package controllers
@@ -14,9 +14,14 @@ import (
 	"github.com/vmware-tanzu/vm-operator/controllers/infracluster"
 	"github.com/vmware-tanzu/vm-operator/controllers/infraprovider"
 	"github.com/vmware-tanzu/vm-operator/controllers/providerconfigmap"
+	"github.com/vmware-tanzu/vm-operator/controllers/serialconsolerequest"
 	"github.com/vmware-tanzu/vm-operator/controllers/virtualmachine"
 	"github.com/vmware-tanzu/vm-operator/controllers/virtualmachineclass"
+	"github.com/vmware-tanzu/vm-operator/controllers/virtualmachineimageimportrequest"
+	"github.com/vmware-tanzu/vm-operator/controllers/virtualmachinemigrationrequest"
 	"github.com/vmware-tanzu/vm-operator/controllers/virtualmachinepublishrequest"
+	"github.com/vmware-tanzu/vm-operator/controllers/virtualmachinepublishschedule"
+	"github.com/vmware-tanzu/vm-operator/controllers/virtualmachinequota"
 	"github.com/vmware-tanzu/vm-operator/controllers/virtualmachineservice"
 	"github.com/vmware-tanzu/vm-operator/controllers/virtualmachinesetresourcepolicy"
 	"github.com/vmware-tanzu/vm-operator/controllers/volume"
@@ -39,6 +44,12 @@ func AddToManager(ctx *context.ControllerManagerContext, mgr manager.Manager) er
 	if err := virtualmachineclass.AddToManager(ctx, mgr); err != nil {
 		return errors.Wrap(err, "failed to initialize VirtualMachineClass controller")
 	}
+	if err := virtualmachinemigrationrequest.AddToManager(ctx, mgr); err != nil {
+		return errors.Wrap(err, "failed to initialize VirtualMachineMigrationRequest controller")
+	}
+	if err := virtualmachinequota.AddToManager(ctx, mgr); err != nil {
+		return errors.Wrap(err, "failed to initialize VirtualMachineQuota controller")
+	}
 	if err := virtualmachineservice.AddToManager(ctx, mgr); err != nil {
 		return errors.Wrap(err, "failed to initialize VirtualMachineService controller")
 	}
@@ -51,6 +62,9 @@ func AddToManager(ctx *context.ControllerManagerContext, mgr manager.Manager) er
 	if err := webconsolerequest.AddToManager(ctx, mgr); err != nil {
 		return errors.Wrap(err, "failed to initialize WebConsoleRequest controller")
 	}
+	if err := serialconsolerequest.AddToManager(ctx, mgr); err != nil {
+		return errors.Wrap(err, "failed to initialize SerialConsoleRequest controller")
+	}
 	if lib.IsWCPVMImageRegistryEnabled() {
 		if err := clustercontentlibraryitem.AddToManager(ctx, mgr); err != nil {
 			return errors.Wrap(err, "failed to initialize ClusterContentLibraryItem controller")
@@ -61,6 +75,12 @@ func AddToManager(ctx *context.ControllerManagerContext, mgr manager.Manager) er
 		if err := virtualmachinepublishrequest.AddToManager(ctx, mgr); err != nil {
 			return errors.Wrap(err, "failed to initialize VirtualMachinePublishRequest controller")
 		}
+		if err := virtualmachinepublishschedule.AddToManager(ctx, mgr); err != nil {
+			return errors.Wrap(err, "failed to initialize VirtualMachinePublishSchedule controller")
+		}
+		if err := virtualmachineimageimportrequest.AddToManager(ctx, mgr); err != nil {
+			return errors.Wrap(err, "failed to initialize VirtualMachineImageImportRequest controller")
+		}
 	} else {
 		if err := contentsource.AddToManager(ctx, mgr); err != nil {
 			return errors.Wrap(err, "failed to initialize ContentSource controller")
@@ -0,0 +1,326 @@
+// Copyright (c) 2023 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package serialconsolerequest
+
+import (
+	goctx "context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"net"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/pkg/errors"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+
+	vmopv1 "github.com/vmware-tanzu/vm-operator/api/v1alpha1"
+
+	"github.com/vmware-tanzu/vm-operator/controllers/webconsolerequest"
+	"github.com/vmware-tanzu/vm-operator/pkg/context"
+	"github.com/vmware-tanzu/vm-operator/pkg/patch"
+	"github.com/vmware-tanzu/vm-operator/pkg/record"
+	"github.com/vmware-tanzu/vm-operator/pkg/vmprovider"
+	"github.com/vmware-tanzu/vm-operator/pkg/vmprovider/providers/vsphere/virtualmachine"
+)
+
+const (
+	DefaultExpiryTime = time.Minute * 30
+	UUIDLabelKey      = "vmoperator.vmware.com/serialconsolerequest-uuid"
+
+	// Finalizer is the finalizer of a SerialConsoleRequest that removes the VM's serial port once no other
+	// SerialConsoleRequest of the VM is active.
+	Finalizer = "serialconsolerequest.vmoperator.vmware.com"
+
+	tokenLength = 32
+)
+
+// AddToManager adds this package's controller to the provided manager.
+func AddToManager(ctx *context.ControllerManagerContext, mgr manager.Manager) error {
+	var (
+		controlledType     = &vmopv1.SerialConsoleRequest{}
+		controlledTypeName = reflect.TypeOf(controlledType).Elem().Name()
+
+		controllerNameShort = fmt.Sprintf("%s-controller", strings.ToLower(controlledTypeName))
+		controllerNameLong  = fmt.Sprintf("%s/%s/%s", ctx.Namespace, ctx.Name, controllerNameShort)
+	)
+
+	r := NewReconciler(
+		mgr.GetClient(),
+		ctrl.Log.WithName("controllers").WithName(controlledTypeName),
+		record.New(mgr.GetEventRecorderFor(controllerNameLong)),
+		ctx.VMProvider,
+	)
+
+	return ctrl.NewControllerManagedBy(mgr).
+		For(controlledType).
+		WithOptions(controller.Options{MaxConcurrentReconciles: 1}).
+		Complete(r)
+}
+
+func NewReconciler(
+	client client.Client,
+	logger logr.Logger,
+	recorder record.Recorder,
+	vmProvider vmprovider.VirtualMachineProviderInterface) *Reconciler {
+	return &Reconciler{
+		Client:     client,
+		Logger:     logger,
+		Recorder:   recorder,
+		VMProvider: vmProvider,
+	}
+}
+
+// Reconciler reconciles a SerialConsoleRequest object.
+type Reconciler struct {
+	client.Client
+	Logger     logr.Logger
+	Recorder   record.Recorder
+	VMProvider vmprovider.VirtualMachineProviderInterface
+}
+
+// HashToken returns the hex encoded SHA-256 hash of a serial console token.
+func HashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// +kubebuilder:rbac:groups=vmoperator.vmware.com,resources=serialconsolerequests,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=vmoperator.vmware.com,resources=serialconsolerequests/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups=vmoperator.vmware.com,resources=virtualmachines,verbs=get;list
+// +kubebuilder:rbac:groups="",resources=services,verbs=get;list;watch
+// +kubebuilder:rbac:groups="",resources=services/status,verbs=get
+
+func (r *Reconciler) Reconcile(ctx goctx.Context, req ctrl.Request) (_ ctrl.Result, reterr error) {
+	serialConsoleRequest := &vmopv1.SerialConsoleRequest{}
+	err := r.Get(ctx, req.NamespacedName, serialConsoleRequest)
+	if err != nil {
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+
+	scrCtx := &context.SerialConsoleRequestContext{
+		Context:              ctx,
+		Logger:               ctrl.Log.WithName("SerialConsoleRequest").WithValues("name", req.NamespacedName),
+		SerialConsoleRequest: serialConsoleRequest,
+		VM:                   &vmopv1.VirtualMachine{},
+	}
+
+	if !serialConsoleRequest.DeletionTimestamp.IsZero() {
+		if err := r.ReconcileDelete(scrCtx); err != nil {
+			scrCtx.Logger.Error(err, "failed to reconcile SerialConsoleRequest deletion")
+			return ctrl.Result{}, err
+		}
+		return ctrl.Result{}, nil
+	}
+
+	done, err := r.ReconcileEarlyNormal(scrCtx)
+	if err != nil {
+		scrCtx.Logger.Error(err, "failed to expire SerialConsoleRequest")
+		return ctrl.Result{}, err
+	}
+	if done {
+		return ctrl.Result{}, nil
+	}
+
+	if !controllerutil.ContainsFinalizer(serialConsoleRequest, Finalizer) {
+		// The finalizer must be present before the serial port is added to the VM in order to ensure
+		// ReconcileDelete() removes it. The request is reconciled again once it is updated.
+		controllerutil.AddFinalizer(serialConsoleRequest, Finalizer)
+		return ctrl.Result{}, r.Update(ctx, serialConsoleRequest)
+	}
+
+	vmName := serialConsoleRequest.Spec.VirtualMachineName
+	err = r.Get(ctx, client.ObjectKey{Name: vmName, Namespace: serialConsoleRequest.Namespace}, scrCtx.VM)
+	if err != nil {
+		r.Recorder.Warn(scrCtx.SerialConsoleRequest, "VirtualMachine Not Found", "")
+		scrCtx.Logger.Error(err, "failed to get subject vm", "vmName", vmName)
+		return ctrl.Result{}, errors.Wrapf(err, "failed to get subject vm %s", vmName)
+	}
+
+	patchHelper, err := patch.NewHelper(serialConsoleRequest, r.Client)
+	if err != nil {
+		return ctrl.Result{}, fmt.Errorf("failed to init patch helper for %s: %w", scrCtx, err)
+	}
+	defer func() {
+		if err := patchHelper.Patch(ctx, serialConsoleRequest); err != nil {
+			if reterr == nil {
+				reterr = err
+			}
+			scrCtx.Logger.Error(err, "patch failed")
+		}
+	}()
+
+	if err := r.ReconcileNormal(scrCtx); err != nil {
+		scrCtx.Logger.Error(err, "failed to reconcile SerialConsoleRequest")
+		return ctrl.Result{}, err
+	}
+
+	// The request is deleted once it expires.
+	return ctrl.Result{RequeueAfter: time.Until(serialConsoleRequest.Status.ExpiryTime.Time)}, nil
+}
+
+// ReconcileDelete removes the VM's serial port, unless another SerialConsoleRequest of the VM is active, and
+// then removes the finalizer.
+func (r *Reconciler) ReconcileDelete(ctx *context.SerialConsoleRequestContext) error {
+	if !controllerutil.ContainsFinalizer(ctx.SerialConsoleRequest, Finalizer) {
+		return nil
+	}
+
+	vmKey := client.ObjectKey{Name: ctx.SerialConsoleRequest.Spec.VirtualMachineName, Namespace: ctx.SerialConsoleRequest.Namespace}
+	if err := r.Get(ctx, vmKey, ctx.VM); err != nil {
+		if !apierrors.IsNotFound(err) {
+			return errors.Wrapf(err, "failed to get subject vm %s", vmKey.Name)
+		}
+		// The serial port is removed along with the VM.
+		ctx.VM = nil
+	}
+
+	if ctx.VM != nil {
+		active, err := r.hasActiveSerialConsoleRequest(ctx)
+		if err != nil {
+			return err
+		}
+
+		if !active {
+			if err := r.VMProvider.RemoveVirtualMachineSerialConsole(ctx, ctx.VM); err != nil {
+				return errors.Wrapf(err, "failed to remove serial console")
+			}
+			ctx.Logger.Info("Removed the serial console of the VM", "vmName", ctx.VM.Name)
+		}
+	}
+
+	controllerutil.RemoveFinalizer(ctx.SerialConsoleRequest, Finalizer)
+	return r.Update(ctx, ctx.SerialConsoleRequest)
+}
+
+// hasActiveSerialConsoleRequest returns true if another SerialConsoleRequest of the VM is neither deleted nor
+// expired, so the VM's serial port is still in use.
+func (r *Reconciler) hasActiveSerialConsoleRequest(ctx *context.SerialConsoleRequestContext) (bool, error) {
+	list := &vmopv1.SerialConsoleRequestList{}
+	if err := r.List(ctx, list, client.InNamespace(ctx.SerialConsoleRequest.Namespace)); err != nil {
+		return false, errors.Wrapf(err, "failed to list SerialConsoleRequests")
+	}
+
+	now := metav1.Now()
+	for _, scr := range list.Items {
+		if scr.UID == ctx.SerialConsoleRequest.UID || scr.Spec.VirtualMachineName != ctx.VM.Name {
+			continue
+		}
+		if !scr.DeletionTimestamp.IsZero() {
+			continue
+		}
+		if expiryTime := scr.Status.ExpiryTime; !expiryTime.IsZero() && !now.Before(&expiryTime) {
+			continue
+		}
+		return true, nil
+	}
+
+	return false, nil
+}
+
+func (r *Reconciler) ReconcileEarlyNormal(ctx *context.SerialConsoleRequestContext) (bool, error) {
+	expiryTime := ctx.SerialConsoleRequest.Status.ExpiryTime
+	nowTime := metav1.Now()
+	if !expiryTime.IsZero() && !nowTime.Before(&expiryTime) {
+		err := r.Delete(ctx, ctx.SerialConsoleRequest)
+		if client.IgnoreNotFound(err) != nil {
+			return false, errors.Wrapf(err, "failed to delete serialconsolerequest")
+		}
+		ctx.Logger.Info("Deleted expired SerialConsoleRequest")
+		return true, nil
+	}
+
+	return false, nil
+}
+
+func (r *Reconciler) ReconcileNormal(ctx *context.SerialConsoleRequestContext) error {
+	ctx.Logger.Info("Reconciling SerialConsoleRequest")
+	defer func() {
+		ctx.Logger.Info("Finished reconciling SerialConsoleRequest")
+	}()
+
+	// The serial port is ensured until the request expires since it is disconnected when another
+	// SerialConsoleRequest of the VM is deleted while the VM is powered on.
+	serialPortURI, err := r.VMProvider.EnsureVirtualMachineSerialConsole(ctx, ctx.VM)
+	if err != nil {
+		return errors.Wrapf(err, "failed to ensure serial console")
+	}
+
+	status := &ctx.SerialConsoleRequest.Status
+	status.SerialPortURI = serialPortURI
+
+	if status.Response != "" && status.ProxyAddr != "" {
+		// The token was already issued.
+		return nil
+	}
+
+	token, err := generateToken()
+	if err != nil {
+		return errors.Wrapf(err, "failed to generate serial console token")
+	}
+
+	response, err := virtualmachine.EncryptWebMKS(ctx.SerialConsoleRequest.Spec.PublicKey, token)
+	if err != nil {
+		return errors.Wrapf(err, "failed to encrypt serial console token")
+	}
+	r.Recorder.EmitEvent(ctx.SerialConsoleRequest, "Acquired Token", nil, false)
+
+	ctx.SerialConsoleRequest.Status.Response = response
+	ctx.SerialConsoleRequest.Status.TokenHash = HashToken(token)
+	ctx.SerialConsoleRequest.Status.ExpiryTime = metav1.NewTime(metav1.Now().Add(DefaultExpiryTime))
+
+	// The serial console stream is served by the same proxy as the web console.
+	proxyAddr, err := webconsolerequest.GetProxyAddr(ctx, r.Client)
+	if err != nil {
+		return err
+	}
+	// An IPv6 address is bracketed so the proxy address can be used as the host of the serial console URL.
+	if ip := net.ParseIP(proxyAddr); ip != nil && ip.To4() == nil {
+		proxyAddr = "[" + proxyAddr + "]"
+	}
+	ctx.SerialConsoleRequest.Status.ProxyAddr = proxyAddr
+
+	// Add UUID as a Label to the current SerialConsoleRequest resource after acquiring the token.
+	// This will be used to find the SerialConsoleRequest when a user connects to the serial console stream.
+	if ctx.SerialConsoleRequest.Labels == nil {
+		ctx.SerialConsoleRequest.Labels = make(map[string]string)
+	}
+	ctx.SerialConsoleRequest.Labels[UUIDLabelKey] = string(ctx.SerialConsoleRequest.UID)
+
+	r.ReconcileOwnerReferences(ctx)
+
+	return nil
+}
+
+func (r *Reconciler) ReconcileOwnerReferences(ctx *context.SerialConsoleRequestContext) {
+	isController := true
+	ownerRef := metav1.OwnerReference{
+		APIVersion: ctx.VM.APIVersion,
+		Kind:       ctx.VM.Kind,
+		Name:       ctx.VM.Name,
+		UID:        ctx.VM.UID,
+		Controller: &isController,
+	}
+
+	ctx.SerialConsoleRequest.SetOwnerReferences([]metav1.OwnerReference{ownerRef})
+}
+
+// generateToken returns a random token used to authenticate the connection to the serial console stream.
+func generateToken() (string, error) {
+	b := make([]byte, tokenLength)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
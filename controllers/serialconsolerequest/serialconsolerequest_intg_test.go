@@ -0,0 +1,163 @@
+// Copyright (c) 2023 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package serialconsolerequest_test
+
+import (
+	"context"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	vmopv1 "github.com/vmware-tanzu/vm-operator/api/v1alpha1"
+	"github.com/vmware-tanzu/vm-operator/controllers/serialconsolerequest"
+	"github.com/vmware-tanzu/vm-operator/pkg/lib"
+	"github.com/vmware-tanzu/vm-operator/test/builder"
+)
+
+func intgTests() {
+	Describe("Invoking SerialConsoleRequest controller tests", serialConsoleRequestReconcile)
+}
+
+func serialConsoleRequestReconcile() {
+	var (
+		ctx      *builder.IntegrationTestContext
+		scr      *vmopv1.SerialConsoleRequest
+		vm       *vmopv1.VirtualMachine
+		proxySvc *corev1.Service
+	)
+
+	getSerialConsoleRequest := func(ctx *builder.IntegrationTestContext, objKey types.NamespacedName) *vmopv1.SerialConsoleRequest {
+		scr := &vmopv1.SerialConsoleRequest{}
+		if err := ctx.Client.Get(ctx, objKey, scr); err != nil {
+			return nil
+		}
+		return scr
+	}
+
+	BeforeEach(func() {
+		ctx = suite.NewIntegrationTestContext()
+
+		vm = &vmopv1.VirtualMachine{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "dummy-vm",
+				Namespace: ctx.Namespace,
+			},
+			Spec: vmopv1.VirtualMachineSpec{
+				ImageName:  "dummy-image",
+				PowerState: vmopv1.VirtualMachinePoweredOn,
+			},
+		}
+
+		_, publicKeyPem := builder.WebConsoleRequestKeyPair()
+
+		scr = &vmopv1.SerialConsoleRequest{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "dummy-scr",
+				Namespace: ctx.Namespace,
+			},
+			Spec: vmopv1.SerialConsoleRequestSpec{
+				VirtualMachineName: vm.Name,
+				PublicKey:          publicKeyPem,
+			},
+		}
+
+		proxySvc = &corev1.Service{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      lib.DefaultWebConsoleProxyServiceName,
+				Namespace: lib.DefaultWebConsoleProxyServiceNamespace,
+			},
+			Spec: corev1.ServiceSpec{
+				Ports: []corev1.ServicePort{
+					{
+						Name: "dummy-proxy-port",
+						Port: 443,
+					},
+				},
+			},
+		}
+
+		fakeVMProvider.Lock()
+		defer fakeVMProvider.Unlock()
+		fakeVMProvider.EnsureVirtualMachineSerialConsoleFn = func(ctx context.Context, vm *vmopv1.VirtualMachine) (string, error) {
+			return "dummy-service-uri", nil
+		}
+	})
+
+	AfterEach(func() {
+		ctx.AfterEach()
+		ctx = nil
+		fakeVMProvider.Reset()
+	})
+
+	Context("Reconcile", func() {
+		BeforeEach(func() {
+			Expect(ctx.Client.Create(ctx, vm)).To(Succeed())
+			Expect(ctx.Client.Create(ctx, scr)).To(Succeed())
+			err := ctx.Client.Create(ctx, proxySvc)
+			Expect(err == nil || k8serrors.IsAlreadyExists(err)).To(BeTrue())
+			proxySvc.Status = corev1.ServiceStatus{
+				LoadBalancer: corev1.LoadBalancerStatus{
+					Ingress: []corev1.LoadBalancerIngress{
+						{
+							IP: "192.168.0.1",
+						},
+					},
+				},
+			}
+			Expect(ctx.Client.Status().Update(ctx, proxySvc)).To(Succeed())
+		})
+
+		AfterEach(func() {
+			err := ctx.Client.Delete(ctx, scr)
+			Expect(err == nil || k8serrors.IsNotFound(err)).To(BeTrue())
+			err = ctx.Client.Delete(ctx, vm)
+			Expect(err == nil || k8serrors.IsNotFound(err)).To(BeTrue())
+		})
+
+		It("resource successfully created", func() {
+			Eventually(func() bool {
+				scr = getSerialConsoleRequest(ctx, types.NamespacedName{Name: scr.Name, Namespace: scr.Namespace})
+				if scr != nil && scr.Status.Response != "" {
+					return true
+				}
+				return false
+			}).Should(BeTrue(), "waiting for serialconsolerequest to be")
+			Expect(scr.Status.ProxyAddr).To(Equal("192.168.0.1"))
+			Expect(scr.Status.SerialPortURI).To(Equal("dummy-service-uri"))
+			Expect(scr.Status.TokenHash).ToNot(BeEmpty())
+			Expect(scr.Status.ExpiryTime.Time).To(BeTemporally("~", time.Now().Add(serialconsolerequest.DefaultExpiryTime), time.Minute))
+			Expect(scr.Labels).To(HaveKeyWithValue(serialconsolerequest.UUIDLabelKey, string(scr.UID)))
+			Expect(scr.Finalizers).To(ContainElement(serialconsolerequest.Finalizer))
+		})
+
+		It("removes the serial console when the resource is deleted", func() {
+			removed := make(chan struct{})
+			fakeVMProvider.Lock()
+			fakeVMProvider.RemoveVirtualMachineSerialConsoleFn = func(ctx context.Context, vm *vmopv1.VirtualMachine) error {
+				close(removed)
+				return nil
+			}
+			fakeVMProvider.Unlock()
+
+			Eventually(func() []string {
+				if scr = getSerialConsoleRequest(ctx, types.NamespacedName{Name: scr.Name, Namespace: scr.Namespace}); scr != nil {
+					return scr.Finalizers
+				}
+				return nil
+			}).Should(ContainElement(serialconsolerequest.Finalizer))
+
+			Expect(ctx.Client.Delete(ctx, scr)).To(Succeed())
+			Eventually(removed).Should(BeClosed())
+			Eventually(func() bool {
+				return getSerialConsoleRequest(ctx, types.NamespacedName{Name: scr.Name, Namespace: scr.Namespace}) == nil
+			}).Should(BeTrue())
+		})
+	})
+}
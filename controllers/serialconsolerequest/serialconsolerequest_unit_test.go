@@ -0,0 +1,324 @@
+// Copyright (c) 2023 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package serialconsolerequest_test
+
+import (
+	"context"
+	"crypto/rsa"
+	"errors"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	vmopv1 "github.com/vmware-tanzu/vm-operator/api/v1alpha1"
+	"github.com/vmware-tanzu/vm-operator/controllers/serialconsolerequest"
+	vmopContext "github.com/vmware-tanzu/vm-operator/pkg/context"
+	"github.com/vmware-tanzu/vm-operator/pkg/lib"
+	providerfake "github.com/vmware-tanzu/vm-operator/pkg/vmprovider/fake"
+	"github.com/vmware-tanzu/vm-operator/pkg/vmprovider/providers/vsphere/virtualmachine"
+	"github.com/vmware-tanzu/vm-operator/test/builder"
+)
+
+func unitTests() {
+	Describe("Invoking SerialConsoleRequest Reconcile", unitTestsReconcile)
+}
+
+func unitTestsReconcile() {
+
+	var (
+		initObjects []client.Object
+		ctx         *builder.UnitTestContextForController
+
+		reconciler *serialconsolerequest.Reconciler
+		scrCtx     *vmopContext.SerialConsoleRequestContext
+		scr        *vmopv1.SerialConsoleRequest
+		vm         *vmopv1.VirtualMachine
+		proxySvc   *corev1.Service
+		privateKey *rsa.PrivateKey
+	)
+
+	BeforeEach(func() {
+		vm = &vmopv1.VirtualMachine{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: "dummy-vm",
+			},
+		}
+
+		var publicKeyPem string
+		privateKey, publicKeyPem = builder.WebConsoleRequestKeyPair()
+
+		scr = &vmopv1.SerialConsoleRequest{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: "dummy-scr",
+			},
+			Spec: vmopv1.SerialConsoleRequestSpec{
+				VirtualMachineName: vm.Name,
+				PublicKey:          publicKeyPem,
+			},
+		}
+
+		proxySvc = &corev1.Service{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      lib.DefaultWebConsoleProxyServiceName,
+				Namespace: lib.DefaultWebConsoleProxyServiceNamespace,
+			},
+			Status: corev1.ServiceStatus{
+				LoadBalancer: corev1.LoadBalancerStatus{
+					Ingress: []corev1.LoadBalancerIngress{
+						{
+							IP: "10.0.0.1",
+						},
+					},
+				},
+			},
+		}
+	})
+
+	JustBeforeEach(func() {
+		ctx = suite.NewUnitTestContextForController(initObjects...)
+		reconciler = serialconsolerequest.NewReconciler(
+			ctx.Client,
+			ctx.Logger,
+			ctx.Recorder,
+			ctx.VMProvider,
+		)
+		fakeVMProvider = ctx.VMProvider.(*providerfake.VMProvider)
+
+		scrCtx = &vmopContext.SerialConsoleRequestContext{
+			Context:              ctx,
+			Logger:               ctx.Logger.WithName(scr.Name),
+			SerialConsoleRequest: scr,
+			VM:                   vm,
+		}
+	})
+
+	AfterEach(func() {
+		ctx.AfterEach()
+		ctx = nil
+		initObjects = nil
+		reconciler = nil
+		fakeVMProvider.Reset()
+	})
+
+	Context("ReconcileNormal", func() {
+		BeforeEach(func() {
+			initObjects = append(initObjects, scr, vm, proxySvc)
+		})
+
+		JustBeforeEach(func() {
+			fakeVMProvider.EnsureVirtualMachineSerialConsoleFn = func(ctx context.Context, vm *vmopv1.VirtualMachine) (string, error) {
+				return "dummy-service-uri", nil
+			}
+		})
+
+		When("NoOp", func() {
+			It("returns success", func() {
+				err := reconciler.ReconcileNormal(scrCtx)
+				Expect(err).ToNot(HaveOccurred())
+
+				status := scrCtx.SerialConsoleRequest.Status
+				Expect(status.ProxyAddr).To(Equal("10.0.0.1"))
+				Expect(status.SerialPortURI).To(Equal("dummy-service-uri"))
+				Expect(status.ExpiryTime.Time).To(BeTemporally("~", time.Now().Add(serialconsolerequest.DefaultExpiryTime), time.Minute))
+				// Checking the label key only because UID will not be set to a resource during unit test.
+				Expect(scrCtx.SerialConsoleRequest.Labels).To(HaveKey(serialconsolerequest.UUIDLabelKey))
+
+				token, err := virtualmachine.DecryptWebMKS(privateKey, status.Response)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(token).ToNot(BeEmpty())
+				Expect(status.TokenHash).To(Equal(serialconsolerequest.HashToken(token)))
+			})
+		})
+
+		When("the proxy address is an IPv6 address", func() {
+			BeforeEach(func() {
+				proxySvc.Status.LoadBalancer.Ingress = []corev1.LoadBalancerIngress{{IP: "fd00::1"}}
+			})
+
+			It("encloses the proxy address in brackets", func() {
+				Expect(reconciler.ReconcileNormal(scrCtx)).To(Succeed())
+				Expect(scrCtx.SerialConsoleRequest.Status.ProxyAddr).To(Equal("[fd00::1]"))
+			})
+		})
+
+		When("the token was already issued", func() {
+			BeforeEach(func() {
+				scr.Status.Response = "dummy-response"
+				scr.Status.TokenHash = "dummy-hash"
+				scr.Status.ProxyAddr = "10.0.0.1"
+				scr.Status.SerialPortURI = "dummy-service-uri"
+				scr.Status.ExpiryTime = metav1.NewTime(time.Now().Add(time.Minute))
+			})
+
+			It("ensures the serial console and keeps the token", func() {
+				called := false
+				fakeVMProvider.EnsureVirtualMachineSerialConsoleFn = func(ctx context.Context, vm *vmopv1.VirtualMachine) (string, error) {
+					called = true
+					return "dummy-service-uri", nil
+				}
+
+				err := reconciler.ReconcileNormal(scrCtx)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(called).To(BeTrue())
+
+				status := scrCtx.SerialConsoleRequest.Status
+				Expect(status.SerialPortURI).To(Equal("dummy-service-uri"))
+				Expect(status.Response).To(Equal("dummy-response"))
+				Expect(status.TokenHash).To(Equal("dummy-hash"))
+				Expect(status.ExpiryTime.Time).To(BeTemporally("~", time.Now().Add(time.Minute), 5*time.Second))
+			})
+		})
+
+		When("the provider fails to ensure the serial console", func() {
+			JustBeforeEach(func() {
+				fakeVMProvider.EnsureVirtualMachineSerialConsoleFn = func(ctx context.Context, vm *vmopv1.VirtualMachine) (string, error) {
+					return "", errors.New("no serial port")
+				}
+			})
+
+			It("returns an error", func() {
+				err := reconciler.ReconcileNormal(scrCtx)
+				Expect(err).To(MatchError(ContainSubstring("no serial port")))
+				Expect(scrCtx.SerialConsoleRequest.Status.Response).To(BeEmpty())
+			})
+		})
+
+		When("the public key is invalid", func() {
+			BeforeEach(func() {
+				scr.Spec.PublicKey = "invalid-pub-key"
+			})
+
+			It("returns an error", func() {
+				err := reconciler.ReconcileNormal(scrCtx)
+				Expect(err).To(MatchError(ContainSubstring("failed to encrypt serial console token")))
+				Expect(scrCtx.SerialConsoleRequest.Status.Response).To(BeEmpty())
+			})
+		})
+	})
+
+	Context("ReconcileDelete", func() {
+		var (
+			removed bool
+		)
+
+		BeforeEach(func() {
+			removed = false
+			scr.Finalizers = []string{serialconsolerequest.Finalizer}
+			initObjects = append(initObjects, scr)
+		})
+
+		JustBeforeEach(func() {
+			fakeVMProvider.RemoveVirtualMachineSerialConsoleFn = func(ctx context.Context, vm *vmopv1.VirtualMachine) error {
+				removed = true
+				return nil
+			}
+		})
+
+		expectFinalizerRemoved := func() {
+			obj := &vmopv1.SerialConsoleRequest{}
+			ExpectWithOffset(1, ctx.Client.Get(ctx, client.ObjectKeyFromObject(scr), obj)).To(Succeed())
+			ExpectWithOffset(1, obj.Finalizers).ToNot(ContainElement(serialconsolerequest.Finalizer))
+		}
+
+		When("the VM exists", func() {
+			BeforeEach(func() {
+				initObjects = append(initObjects, vm)
+			})
+
+			It("removes the serial console and the finalizer", func() {
+				Expect(reconciler.ReconcileDelete(scrCtx)).To(Succeed())
+				Expect(removed).To(BeTrue())
+				expectFinalizerRemoved()
+			})
+
+			When("the provider fails to remove the serial console", func() {
+				JustBeforeEach(func() {
+					fakeVMProvider.RemoveVirtualMachineSerialConsoleFn = func(ctx context.Context, vm *vmopv1.VirtualMachine) error {
+						return errors.New("remove failed")
+					}
+				})
+
+				It("returns an error and keeps the finalizer", func() {
+					Expect(reconciler.ReconcileDelete(scrCtx)).To(MatchError(ContainSubstring("remove failed")))
+					obj := &vmopv1.SerialConsoleRequest{}
+					Expect(ctx.Client.Get(ctx, client.ObjectKeyFromObject(scr), obj)).To(Succeed())
+					Expect(obj.Finalizers).To(ContainElement(serialconsolerequest.Finalizer))
+				})
+			})
+
+			When("another request of the VM is active", func() {
+				BeforeEach(func() {
+					other := scr.DeepCopy()
+					other.Name = "other-scr"
+					other.UID = "other-uid"
+					other.Status.ExpiryTime = metav1.NewTime(time.Now().Add(time.Minute))
+					initObjects = append(initObjects, other)
+				})
+
+				It("keeps the serial console and removes the finalizer", func() {
+					Expect(reconciler.ReconcileDelete(scrCtx)).To(Succeed())
+					Expect(removed).To(BeFalse())
+					expectFinalizerRemoved()
+				})
+			})
+
+			When("another request of the VM has expired", func() {
+				BeforeEach(func() {
+					other := scr.DeepCopy()
+					other.Name = "other-scr"
+					other.UID = "other-uid"
+					other.Status.ExpiryTime = metav1.NewTime(time.Now().Add(-time.Minute))
+					initObjects = append(initObjects, other)
+				})
+
+				It("removes the serial console", func() {
+					Expect(reconciler.ReconcileDelete(scrCtx)).To(Succeed())
+					Expect(removed).To(BeTrue())
+				})
+			})
+		})
+
+		When("the VM does not exist", func() {
+			It("removes the finalizer", func() {
+				Expect(reconciler.ReconcileDelete(scrCtx)).To(Succeed())
+				Expect(removed).To(BeFalse())
+				expectFinalizerRemoved()
+			})
+		})
+	})
+
+	Context("ReconcileEarlyNormal", func() {
+		BeforeEach(func() {
+			initObjects = append(initObjects, scr)
+		})
+
+		When("the request has expired", func() {
+			BeforeEach(func() {
+				scr.Status.ExpiryTime = metav1.NewTime(time.Now().Add(-time.Minute))
+			})
+
+			It("deletes the request", func() {
+				done, err := reconciler.ReconcileEarlyNormal(scrCtx)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(done).To(BeTrue())
+
+				err = ctx.Client.Get(ctx, client.ObjectKeyFromObject(scr), &vmopv1.SerialConsoleRequest{})
+				Expect(err).To(HaveOccurred())
+			})
+		})
+
+		When("the request has not been reconciled", func() {
+			It("is not done", func() {
+				done, err := reconciler.ReconcileEarlyNormal(scrCtx)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(done).To(BeFalse())
+			})
+		})
+	})
+}
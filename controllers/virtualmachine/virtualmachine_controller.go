@@ -10,13 +10,17 @@ import (
 	"strings"
 	"time"
 
+	corev1 "k8s.io/api/core/v1"
 	apiequality "k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	ctrl "sigs.k8s.io/controller-runtime"
+	ctrlbuilder "sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 	"sigs.k8s.io/controller-runtime/pkg/source"
 
@@ -25,16 +29,26 @@ import (
 
 	vmopv1 "github.com/vmware-tanzu/vm-operator/api/v1alpha1"
 
+	"github.com/vmware-tanzu/vm-operator/pkg/conditions"
 	"github.com/vmware-tanzu/vm-operator/pkg/context"
 	"github.com/vmware-tanzu/vm-operator/pkg/lib"
 	"github.com/vmware-tanzu/vm-operator/pkg/metrics"
 	"github.com/vmware-tanzu/vm-operator/pkg/patch"
 	"github.com/vmware-tanzu/vm-operator/pkg/prober"
 	"github.com/vmware-tanzu/vm-operator/pkg/record"
+	"github.com/vmware-tanzu/vm-operator/pkg/tracing"
 	"github.com/vmware-tanzu/vm-operator/pkg/vmprovider"
 )
 
-const finalizerName = "virtualmachine.vmoperator.vmware.com"
+const (
+	finalizerName = "virtualmachine.vmoperator.vmware.com"
+
+	// ClassUpdateRestartsConfigMapName is the name of the ConfigMap in a namespace that records the VMs that are
+	// restarted to apply a VirtualMachineClass update under the RollingRestart update strategy. The data is keyed
+	// by the name of the class, and the value is the comma-separated list of the names of the VMs of the class
+	// being restarted.
+	ClassUpdateRestartsConfigMapName = "vmoperator-class-update-restarts"
+)
 
 // AddToManager adds this package's controller to the provided manager.
 func AddToManager(ctx *context.ControllerManagerContext, mgr manager.Manager) error {
@@ -72,11 +86,14 @@ func AddToManager(ctx *context.ControllerManagerContext, mgr manager.Manager) er
 	if !lib.IsNamespacedClassAndWindowsFSSEnabled() {
 		builder = builder.Watches(&source.Kind{Type: &vmopv1.VirtualMachineClassBinding{}},
 			handler.EnqueueRequestsFromMapFunc(classBindingToVMMapperFn(ctx, r.Client)))
-	} else {
-		builder = builder.Watches(&source.Kind{Type: &vmopv1.VirtualMachineClass{}},
-			handler.EnqueueRequestsFromMapFunc(classToVMMapperFn(ctx, r.Client)))
 	}
 
+	// Changes to a VM class are rolled out to the VMs of the class according to the
+	// class's update policy.
+	builder = builder.Watches(&source.Kind{Type: &vmopv1.VirtualMachineClass{}},
+		handler.EnqueueRequestsFromMapFunc(classToVMMapperFn(ctx, r.Client)),
+		ctrlbuilder.WithPredicates(predicate.GenerationChangedPredicate{}))
+
 	return builder.Complete(r)
 }
 
@@ -172,8 +189,9 @@ func classBindingToVMMapperFn(ctx *context.ControllerManagerContext, c client.Cl
 }
 
 // classToVMMapperFn returns a mapper function that can be used to queue reconcile request
-// for the VirtualMachines in response to an event on the VirtualMachineClass resource when
-// WCP_Namespaced_Class_And_Windows_Support is enabled.
+// for the VirtualMachines in response to an event on the VirtualMachineClass resource. When
+// WCP_Namespaced_Class_And_Windows_Support is disabled, the class is cluster scoped and the
+// VMs in all namespaces are considered.
 func classToVMMapperFn(ctx *context.ControllerManagerContext, c client.Client) func(o client.Object) []reconcile.Request {
 	// For a given VirtualMachineClass, return reconcile requests
 	// for those VirtualMachines with corresponding VirtualMachinesClasses referenced
@@ -236,7 +254,7 @@ type Reconciler struct {
 
 // +kubebuilder:rbac:groups=vmoperator.vmware.com,resources=virtualmachines,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=vmoperator.vmware.com,resources=virtualmachines/status,verbs=get;update;patch
-// +kubebuilder:rbac:groups=vmoperator.vmware.com,resources=virtualmachineclasses,verbs=get;list
+// +kubebuilder:rbac:groups=vmoperator.vmware.com,resources=virtualmachineclasses,verbs=get;list;watch
 // +kubebuilder:rbac:groups=vmware.com,resources=virtualnetworkinterfaces;virtualnetworkinterfaces/status,verbs=create;get;list;patch;delete;watch;update
 // +kubebuilder:rbac:groups=storage.k8s.io,resources=storageclasses,verbs=get;list;watch
 // +kubebuilder:rbac:groups="",resources=events;configmaps,verbs=get;list;watch;create;update;patch;delete
@@ -247,6 +265,11 @@ type Reconciler struct {
 // +kubebuilder:rbac:groups=vmoperator.vmware.com,resources=contentsourcebindings,verbs=get;list;watch
 
 func (r *Reconciler) Reconcile(ctx goctx.Context, req ctrl.Request) (_ ctrl.Result, reterr error) {
+	ctx, span := tracing.StartSpan(ctx, "VirtualMachine.Reconcile", tracing.VMKey.String(req.NamespacedName.String()))
+	defer func() {
+		tracing.EndSpanWithRequeue(span, reterr, vmprovider.RequeueAfter)
+	}()
+
 	vm := &vmopv1.VirtualMachine{}
 	if err := r.Get(ctx, req.NamespacedName, vm); err != nil {
 		return ctrl.Result{}, client.IgnoreNotFound(err)
@@ -283,10 +306,19 @@ func (r *Reconciler) Reconcile(ctx goctx.Context, req ctrl.Request) (_ ctrl.Resu
 
 	if !vm.DeletionTimestamp.IsZero() {
 		err = r.ReconcileDelete(vmCtx)
+		if requeueAfter, ok := vmprovider.RequeueAfter(err); ok {
+			return ctrl.Result{RequeueAfter: requeueAfter}, nil
+		}
 		return ctrl.Result{}, err
 	}
 
 	if err := r.ReconcileNormal(vmCtx); err != nil {
+		// The VM is reconciled again once it is likely its operation can be started, or its task has
+		// completed. Do not return the error to avoid the exponential backoff.
+		if requeueAfter, ok := vmprovider.RequeueAfter(err); ok {
+			return ctrl.Result{RequeueAfter: requeueAfter}, nil
+		}
+
 		vmCtx.Logger.Error(err, "Failed to reconcile VirtualMachine")
 		return ctrl.Result{}, err
 	}
@@ -312,6 +344,13 @@ func requeueDelay(ctx *context.VirtualMachineContext) time.Duration {
 		return 10 * time.Second
 	}
 
+	// The VM waits for other VMs of its class to be restarted before it is restarted to apply
+	// the changes to its class, and for its guest OS to shut down while it is restarted.
+	switch conditions.GetReason(ctx.VM, vmopv1.VirtualMachineClassUpToDateCondition) {
+	case vmopv1.VirtualMachineClassUpdatePendingRestartReason, vmopv1.VirtualMachineClassUpdateRestartingReason:
+		return 10 * time.Second
+	}
+
 	return 0
 }
 
@@ -322,10 +361,17 @@ func (r *Reconciler) ReconcileDelete(ctx *context.VirtualMachineContext) (reterr
 		ctx.VM.Status.Phase = vmopv1.Deleting
 
 		defer func() {
-			r.Recorder.EmitEvent(ctx.VM, "Delete", reterr, false)
+			// The VM is still being deleted when its task is in progress.
+			if _, ok := vmprovider.RequeueAfter(reterr); !ok {
+				r.Recorder.EmitEvent(ctx.VM, "Delete", reterr, false)
+			}
 		}()
 
 		if err := r.VMProvider.DeleteVirtualMachine(ctx, ctx.VM); err != nil {
+			if _, ok := vmprovider.RequeueAfter(err); ok {
+				return err
+			}
+
 			ctx.Logger.Error(err, "Failed to delete VirtualMachine")
 			return err
 		}
@@ -368,13 +414,24 @@ func (r *Reconciler) ReconcileNormal(ctx *context.VirtualMachineContext) (reterr
 		if ip := initialVMStatus.VmIp; ip == "" && ip != ctx.VM.Status.VmIp {
 			ctx.Logger.Info("VM successfully got assigned with an IP address", "time", time.Now().Format(time.RFC3339))
 		}
+
+		r.vmMetrics.ObserveVMProvisioning(ctx, initialVMStatus)
 	}(ctx.VM.Status.DeepCopy())
 
 	defer func() {
 		r.vmMetrics.RegisterVMCreateOrUpdateMetrics(ctx)
 	}()
 
+	if err := r.reconcileClassUpdate(ctx); err != nil {
+		ctx.Logger.Error(err, "Failed to reconcile VirtualMachineClass update")
+		return err
+	}
+
 	if err := r.VMProvider.CreateOrUpdateVirtualMachine(ctx, ctx.VM); err != nil {
+		if _, ok := vmprovider.RequeueAfter(err); ok {
+			return err
+		}
+
 		ctx.Logger.Error(err, "Failed to reconcile VirtualMachine")
 		r.Recorder.EmitEvent(ctx.VM, "CreateOrUpdate", err, false)
 		return err
@@ -387,3 +444,223 @@ func (r *Reconciler) ReconcileNormal(ctx *context.VirtualMachineContext) (reterr
 	ctx.Logger.Info("Finished Reconciling VirtualMachine")
 	return nil
 }
+
+// reconcileClassUpdate updates the VirtualMachineClassUpToDate condition of a created VM. When the
+// VM is built from an older generation of its class and the class's update strategy is RollingRestart,
+// the VM is annotated to be restarted by the provider, unless the class's maxUnavailable VMs in the
+// namespace are already being restarted.
+func (r *Reconciler) reconcileClassUpdate(ctx *context.VirtualMachineContext) error {
+	vm := ctx.VM
+	if vm.Status.UniqueID == "" {
+		// The provider records the class generation when the VM is created.
+		return nil
+	}
+
+	key := client.ObjectKey{Name: vm.Spec.ClassName}
+	if lib.IsNamespacedClassAndWindowsFSSEnabled() {
+		key.Namespace = vm.Namespace
+	}
+
+	vmClass := &vmopv1.VirtualMachineClass{}
+	if err := r.Get(ctx, key, vmClass); err != nil {
+		// The provider reports a missing class in the VM's conditions.
+		return client.IgnoreNotFound(err)
+	}
+
+	if vm.Status.ClassGeneration == 0 {
+		// VMs created before the class generation was recorded are assumed to be up to date.
+		vm.Status.ClassGeneration = vmClass.Generation
+	}
+
+	if vm.Status.ClassGeneration == vmClass.Generation {
+		if err := r.releaseClassUpdateRestart(ctx, vmClass); err != nil {
+			return err
+		}
+		delete(vm.Annotations, vmopv1.ClassUpdateRestartAnnotation)
+		conditions.MarkTrue(vm, vmopv1.VirtualMachineClassUpToDateCondition)
+		return nil
+	}
+
+	if _, ok := vm.Annotations[vmopv1.ClassUpdateRestartAnnotation]; ok {
+		conditions.MarkFalse(vm, vmopv1.VirtualMachineClassUpToDateCondition,
+			vmopv1.VirtualMachineClassUpdateRestartingReason, vmopv1.ConditionSeverityInfo,
+			"VM is restarted to apply generation %d of VirtualMachineClass %s", vmClass.Generation, vmClass.Name)
+		return nil
+	}
+
+	msg := fmt.Sprintf("VM is configured from generation %d of VirtualMachineClass %s, which is at generation %d",
+		vm.Status.ClassGeneration, vmClass.Name, vmClass.Generation)
+
+	switch vmClass.Spec.UpdatePolicy.Strategy {
+	case vmopv1.VirtualMachineClassUpdateStrategyOnNextPowerCycle:
+		conditions.MarkFalse(vm, vmopv1.VirtualMachineClassUpToDateCondition,
+			vmopv1.VirtualMachineClassUpdatePendingPowerCycleReason, vmopv1.ConditionSeverityInfo, msg)
+
+	case vmopv1.VirtualMachineClassUpdateStrategyRollingRestart:
+		if vm.Spec.PowerState != vmopv1.VirtualMachinePoweredOn || vm.Status.PowerState != vmopv1.VirtualMachinePoweredOn {
+			// A VM that is not powered on is updated when it is powered on.
+			conditions.MarkFalse(vm, vmopv1.VirtualMachineClassUpToDateCondition,
+				vmopv1.VirtualMachineClassUpdatePendingPowerCycleReason, vmopv1.ConditionSeverityInfo, msg)
+			return nil
+		}
+
+		maxUnavailable := int32(1)
+		if rr := vmClass.Spec.UpdatePolicy.RollingRestart; rr != nil && rr.MaxUnavailable != nil {
+			maxUnavailable = *rr.MaxUnavailable
+		}
+
+		claimed, err := r.claimClassUpdateRestart(ctx, vmClass, maxUnavailable)
+		if err != nil {
+			return err
+		}
+
+		if !claimed {
+			conditions.MarkFalse(vm, vmopv1.VirtualMachineClassUpToDateCondition,
+				vmopv1.VirtualMachineClassUpdatePendingRestartReason, vmopv1.ConditionSeverityInfo, msg)
+			return nil
+		}
+
+		// Persist the annotation before the VM is restarted so that the restart is not requested
+		// again with a new slot if the VM is reconciled before it is restarted.
+		vmCopy := vm.DeepCopy()
+		if vmCopy.Annotations == nil {
+			vmCopy.Annotations = map[string]string{}
+		}
+		vmCopy.Annotations[vmopv1.ClassUpdateRestartAnnotation] = ""
+		if err := r.Patch(ctx, vmCopy, client.MergeFrom(vm)); err != nil {
+			return errors.Wrap(err, "failed to annotate VM for restart")
+		}
+		vm.Annotations = vmCopy.Annotations
+
+		ctx.Logger.Info("Restarting VM to apply VirtualMachineClass update",
+			"class", vmClass.Name, "generation", vmClass.Generation)
+		conditions.MarkFalse(vm, vmopv1.VirtualMachineClassUpToDateCondition,
+			vmopv1.VirtualMachineClassUpdateRestartingReason, vmopv1.ConditionSeverityInfo,
+			"VM is restarted to apply generation %d of VirtualMachineClass %s", vmClass.Generation, vmClass.Name)
+
+	default:
+		conditions.MarkFalse(vm, vmopv1.VirtualMachineClassUpToDateCondition,
+			vmopv1.VirtualMachineClassUpdateNeverReason, vmopv1.ConditionSeverityInfo, msg)
+	}
+
+	return nil
+}
+
+// claimClassUpdateRestart adds the VM to the VMs of its class in the namespace that are being restarted, unless
+// the class's maxUnavailable VMs are already being restarted. The restarts are recorded in the namespace's
+// ClassUpdateRestartsConfigMapName ConfigMap, which is updated with its resourceVersion, so a slot claimed
+// concurrently for another VM results in a conflict and the VM is reconciled again. VMs that were deleted or that
+// no longer use the class are dropped from the list. Returns true if the VM holds a slot.
+func (r *Reconciler) claimClassUpdateRestart(
+	ctx *context.VirtualMachineContext,
+	vmClass *vmopv1.VirtualMachineClass,
+	maxUnavailable int32) (bool, error) {
+
+	cm, err := r.getClassUpdateRestarts(ctx)
+	if err != nil {
+		return false, err
+	}
+
+	var restarts []string
+	for _, name := range classUpdateRestarts(cm, vmClass.Name) {
+		if name == ctx.VM.Name {
+			return true, nil
+		}
+
+		vm := &vmopv1.VirtualMachine{}
+		if err := r.Get(ctx, client.ObjectKey{Namespace: ctx.VM.Namespace, Name: name}, vm); err != nil {
+			if !apierrors.IsNotFound(err) {
+				return false, errors.Wrapf(err, "failed to get VirtualMachine %s/%s", ctx.VM.Namespace, name)
+			}
+			continue
+		}
+		if vm.Spec.ClassName != vmClass.Name || !vm.DeletionTimestamp.IsZero() {
+			continue
+		}
+
+		restarts = append(restarts, name)
+	}
+
+	if int32(len(restarts)) >= maxUnavailable {
+		return false, nil
+	}
+
+	if err := r.updateClassUpdateRestarts(ctx, cm, vmClass.Name, append(restarts, ctx.VM.Name)); err != nil {
+		return false, errors.Wrap(err, "failed to claim VirtualMachineClass restart")
+	}
+	return true, nil
+}
+
+// releaseClassUpdateRestart removes the VM from the VMs of its class in the namespace that are being restarted.
+func (r *Reconciler) releaseClassUpdateRestart(
+	ctx *context.VirtualMachineContext,
+	vmClass *vmopv1.VirtualMachineClass) error {
+
+	cm, err := r.getClassUpdateRestarts(ctx)
+	if err != nil {
+		return err
+	}
+
+	restarts := classUpdateRestarts(cm, vmClass.Name)
+	remaining := make([]string, 0, len(restarts))
+	for _, name := range restarts {
+		if name != ctx.VM.Name {
+			remaining = append(remaining, name)
+		}
+	}
+
+	if len(remaining) == len(restarts) {
+		return nil
+	}
+
+	if err := r.updateClassUpdateRestarts(ctx, cm, vmClass.Name, remaining); err != nil {
+		return errors.Wrap(err, "failed to release VirtualMachineClass restart")
+	}
+	return nil
+}
+
+// getClassUpdateRestarts returns the ConfigMap that records the VMs in the VM's namespace that are being restarted,
+// keyed by the name of their class. A ConfigMap that does not exist yet is returned without a resourceVersion.
+func (r *Reconciler) getClassUpdateRestarts(ctx *context.VirtualMachineContext) (*corev1.ConfigMap, error) {
+	cm := &corev1.ConfigMap{}
+	key := client.ObjectKey{Namespace: ctx.VM.Namespace, Name: ClassUpdateRestartsConfigMapName}
+	if err := r.Get(ctx, key, cm); err != nil {
+		if !apierrors.IsNotFound(err) {
+			return nil, errors.Wrap(err, "failed to get VirtualMachineClass restarts ConfigMap")
+		}
+		cm.Namespace = key.Namespace
+		cm.Name = key.Name
+	}
+	return cm, nil
+}
+
+func classUpdateRestarts(cm *corev1.ConfigMap, className string) []string {
+	if value := cm.Data[className]; value != "" {
+		return strings.Split(value, ",")
+	}
+	return nil
+}
+
+func (r *Reconciler) updateClassUpdateRestarts(
+	ctx *context.VirtualMachineContext,
+	cm *corev1.ConfigMap,
+	className string,
+	restarts []string) error {
+
+	if len(restarts) == 0 {
+		delete(cm.Data, className)
+	} else {
+		if cm.Data == nil {
+			cm.Data = map[string]string{}
+		}
+		cm.Data[className] = strings.Join(restarts, ",")
+	}
+
+	if cm.ResourceVersion == "" {
+		// Create fails if the ConfigMap was created since it was read.
+		return r.Create(ctx, cm)
+	}
+
+	// Update, unlike a merge patch, fails if the ConfigMap was changed since it was read.
+	return r.Update(ctx, cm)
+}
@@ -1,4 +1,4 @@
-// Copyright (c) 2019-2021 VMware, Inc. All Rights Reserved.
+// Copyright (c) 2019-2023 VMware, Inc. All Rights Reserved.
 // SPDX-License-Identifier: Apache-2.0
 
 package virtualmachine_test
@@ -7,18 +7,22 @@ import (
 	"context"
 	"errors"
 	"strings"
+	"time"
 
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
 
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	vmopv1 "github.com/vmware-tanzu/vm-operator/api/v1alpha1"
 
 	"github.com/vmware-tanzu/vm-operator/controllers/virtualmachine"
+	"github.com/vmware-tanzu/vm-operator/pkg/conditions"
 	vmopContext "github.com/vmware-tanzu/vm-operator/pkg/context"
 	proberfake "github.com/vmware-tanzu/vm-operator/pkg/prober/fake"
+	"github.com/vmware-tanzu/vm-operator/pkg/vmprovider"
 	providerfake "github.com/vmware-tanzu/vm-operator/pkg/vmprovider/fake"
 	"github.com/vmware-tanzu/vm-operator/test/builder"
 )
@@ -126,6 +130,19 @@ func unitTestsReconcile() {
 			expectEvent(ctx, "CreateOrUpdateFailure")
 		})
 
+		It("will return the error without an event when the provider queued the operation", func() {
+			fakeVMProvider.CreateOrUpdateVirtualMachineFn = func(ctx context.Context, vm *vmopv1.VirtualMachine) error {
+				vm.Status.Phase = vmopv1.Creating
+				return vmprovider.OperationQueuedError{Operation: "create", RequeueAfter: time.Second}
+			}
+
+			err := reconciler.ReconcileNormal(vmCtx)
+			Expect(err).To(MatchError("create operation is queued"))
+			Expect(vmCtx.VM.Status.Phase).To(Equal(vmopv1.Creating))
+			Expect(ctx.Events).ShouldNot(Receive())
+			Expect(fakeProbeManager.IsAddToProberManagerCalled).Should(BeFalse())
+		})
+
 		It("can be called multiple times", func() {
 			err := reconciler.ReconcileNormal(vmCtx)
 			Expect(err).ToNot(HaveOccurred())
@@ -154,6 +171,283 @@ func unitTestsReconcile() {
 			Expect(reconciler.ReconcileNormal(vmCtx)).Should(Succeed())
 			Expect(fakeProbeManager.IsAddToProberManagerCalled).Should(BeTrue())
 		})
+
+		Context("VM Class update", func() {
+			var (
+				vmClass *vmopv1.VirtualMachineClass
+			)
+
+			BeforeEach(func() {
+				vmClass = &vmopv1.VirtualMachineClass{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:       vm.Spec.ClassName,
+						Generation: 2,
+					},
+				}
+				initObjects = append(initObjects, vmClass)
+
+				vm.Status.UniqueID = "vm-42"
+				vm.Status.ClassGeneration = 1
+				vm.Spec.PowerState = vmopv1.VirtualMachinePoweredOn
+				vm.Status.PowerState = vmopv1.VirtualMachinePoweredOn
+			})
+
+			expectClassUpToDateReason := func(reason string) {
+				c := conditions.Get(vmCtx.VM, vmopv1.VirtualMachineClassUpToDateCondition)
+				Expect(c).ToNot(BeNil())
+				Expect(c.Status).To(Equal(corev1.ConditionFalse))
+				Expect(c.Reason).To(Equal(reason))
+			}
+
+			newClassUpdateRestarts := func(namespace, restarts string) *corev1.ConfigMap {
+				return &corev1.ConfigMap{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      virtualmachine.ClassUpdateRestartsConfigMapName,
+						Namespace: namespace,
+					},
+					Data: map[string]string{
+						vmClass.Name: restarts,
+					},
+				}
+			}
+
+			getClassUpdateRestarts := func() string {
+				obj := &corev1.ConfigMap{}
+				key := client.ObjectKey{Namespace: vm.Namespace, Name: virtualmachine.ClassUpdateRestartsConfigMapName}
+				ExpectWithOffset(1, ctx.Client.Get(ctx, key, obj)).To(Succeed())
+				return obj.Data[vmClass.Name]
+			}
+
+			expectRestartAnnotation := func(expected bool) {
+				Expect(vmCtx.VM.Annotations).To(WithTransform(func(a map[string]string) bool {
+					_, ok := a[vmopv1.ClassUpdateRestartAnnotation]
+					return ok
+				}, Equal(expected)))
+
+				obj := &vmopv1.VirtualMachine{}
+				Expect(ctx.Client.Get(ctx, client.ObjectKeyFromObject(vm), obj)).To(Succeed())
+				Expect(obj.Annotations).To(WithTransform(func(a map[string]string) bool {
+					_, ok := a[vmopv1.ClassUpdateRestartAnnotation]
+					return ok
+				}, Equal(expected)))
+			}
+
+			When("the VM has not been created", func() {
+				BeforeEach(func() {
+					vm.Status.UniqueID = ""
+				})
+
+				It("does not set the condition", func() {
+					Expect(reconciler.ReconcileNormal(vmCtx)).To(Succeed())
+					Expect(conditions.Has(vmCtx.VM, vmopv1.VirtualMachineClassUpToDateCondition)).To(BeFalse())
+				})
+			})
+
+			When("the VM does not have a class generation", func() {
+				BeforeEach(func() {
+					vm.Status.ClassGeneration = 0
+				})
+
+				It("records the class generation and marks the VM up to date", func() {
+					Expect(reconciler.ReconcileNormal(vmCtx)).To(Succeed())
+					Expect(vmCtx.VM.Status.ClassGeneration).To(Equal(vmClass.Generation))
+					Expect(conditions.IsTrue(vmCtx.VM, vmopv1.VirtualMachineClassUpToDateCondition)).To(BeTrue())
+				})
+			})
+
+			When("the VM was restarted and is built from the current class generation", func() {
+				BeforeEach(func() {
+					vm.Status.ClassGeneration = vmClass.Generation
+					vm.Annotations = map[string]string{vmopv1.ClassUpdateRestartAnnotation: ""}
+				})
+
+				It("removes the restart annotation and marks the VM up to date", func() {
+					Expect(reconciler.ReconcileNormal(vmCtx)).To(Succeed())
+					Expect(vmCtx.VM.Annotations).ToNot(HaveKey(vmopv1.ClassUpdateRestartAnnotation))
+					Expect(conditions.IsTrue(vmCtx.VM, vmopv1.VirtualMachineClassUpToDateCondition)).To(BeTrue())
+				})
+
+				When("the VM holds a restart slot of the class", func() {
+					BeforeEach(func() {
+						initObjects = append(initObjects, newClassUpdateRestarts(vm.Namespace, "other-vm,"+vm.Name))
+					})
+
+					It("releases the restart slot", func() {
+						Expect(reconciler.ReconcileNormal(vmCtx)).To(Succeed())
+						Expect(getClassUpdateRestarts()).To(Equal("other-vm"))
+					})
+				})
+			})
+
+			When("the class update strategy is not set", func() {
+				It("leaves the VM out of date", func() {
+					Expect(reconciler.ReconcileNormal(vmCtx)).To(Succeed())
+					expectClassUpToDateReason(vmopv1.VirtualMachineClassUpdateNeverReason)
+					expectRestartAnnotation(false)
+				})
+			})
+
+			When("the class update strategy is OnNextPowerCycle", func() {
+				BeforeEach(func() {
+					vmClass.Spec.UpdatePolicy.Strategy = vmopv1.VirtualMachineClassUpdateStrategyOnNextPowerCycle
+				})
+
+				It("waits for the VM to be power cycled", func() {
+					Expect(reconciler.ReconcileNormal(vmCtx)).To(Succeed())
+					expectClassUpToDateReason(vmopv1.VirtualMachineClassUpdatePendingPowerCycleReason)
+					expectRestartAnnotation(false)
+				})
+			})
+
+			When("the class update strategy is RollingRestart", func() {
+				var (
+					otherVM *vmopv1.VirtualMachine
+				)
+
+				BeforeEach(func() {
+					vmClass.Spec.UpdatePolicy.Strategy = vmopv1.VirtualMachineClassUpdateStrategyRollingRestart
+
+					otherVM = &vmopv1.VirtualMachine{
+						ObjectMeta: metav1.ObjectMeta{
+							Name:      "other-vm",
+							Namespace: vm.Namespace,
+							Annotations: map[string]string{
+								vmopv1.ClassUpdateRestartAnnotation: "",
+							},
+						},
+						Spec: vmopv1.VirtualMachineSpec{
+							ClassName: vm.Spec.ClassName,
+						},
+					}
+				})
+
+				It("claims a restart slot of the class and annotates the VM to be restarted", func() {
+					Expect(reconciler.ReconcileNormal(vmCtx)).To(Succeed())
+					expectClassUpToDateReason(vmopv1.VirtualMachineClassUpdateRestartingReason)
+					expectRestartAnnotation(true)
+					Expect(getClassUpdateRestarts()).To(Equal(vm.Name))
+				})
+
+				It("does not update the class", func() {
+					before := &vmopv1.VirtualMachineClass{}
+					Expect(ctx.Client.Get(ctx, client.ObjectKeyFromObject(vmClass), before)).To(Succeed())
+					Expect(reconciler.ReconcileNormal(vmCtx)).To(Succeed())
+					after := &vmopv1.VirtualMachineClass{}
+					Expect(ctx.Client.Get(ctx, client.ObjectKeyFromObject(vmClass), after)).To(Succeed())
+					Expect(after).To(Equal(before))
+				})
+
+				When("the VM is powered off", func() {
+					BeforeEach(func() {
+						vm.Spec.PowerState = vmopv1.VirtualMachinePoweredOff
+						vm.Status.PowerState = vmopv1.VirtualMachinePoweredOff
+					})
+
+					It("waits for the VM to be powered on", func() {
+						Expect(reconciler.ReconcileNormal(vmCtx)).To(Succeed())
+						expectClassUpToDateReason(vmopv1.VirtualMachineClassUpdatePendingPowerCycleReason)
+						expectRestartAnnotation(false)
+					})
+				})
+
+				When("another VM of the class is being restarted", func() {
+					var (
+						restarts *corev1.ConfigMap
+					)
+
+					BeforeEach(func() {
+						restarts = newClassUpdateRestarts(vm.Namespace, otherVM.Name)
+						initObjects = append(initObjects, otherVM, restarts)
+					})
+
+					It("waits for the other VM to be restarted", func() {
+						Expect(reconciler.ReconcileNormal(vmCtx)).To(Succeed())
+						expectClassUpToDateReason(vmopv1.VirtualMachineClassUpdatePendingRestartReason)
+						expectRestartAnnotation(false)
+						Expect(getClassUpdateRestarts()).To(Equal(otherVM.Name))
+					})
+
+					When("the VM already holds a restart slot of the class", func() {
+						BeforeEach(func() {
+							restarts.Data[vmClass.Name] += "," + vm.Name
+						})
+
+						It("annotates the VM to be restarted", func() {
+							Expect(reconciler.ReconcileNormal(vmCtx)).To(Succeed())
+							expectClassUpToDateReason(vmopv1.VirtualMachineClassUpdateRestartingReason)
+							expectRestartAnnotation(true)
+						})
+					})
+
+					When("maxUnavailable allows more than one VM to be restarted", func() {
+						BeforeEach(func() {
+							maxUnavailable := int32(2)
+							vmClass.Spec.UpdatePolicy.RollingRestart = &vmopv1.VirtualMachineClassRollingRestart{
+								MaxUnavailable: &maxUnavailable,
+							}
+						})
+
+						It("annotates the VM to be restarted", func() {
+							Expect(reconciler.ReconcileNormal(vmCtx)).To(Succeed())
+							expectClassUpToDateReason(vmopv1.VirtualMachineClassUpdateRestartingReason)
+							expectRestartAnnotation(true)
+						})
+					})
+				})
+
+				When("a VM of another class is being restarted", func() {
+					BeforeEach(func() {
+						otherVM.Spec.ClassName = "other-class"
+						initObjects = append(initObjects, otherVM)
+					})
+
+					It("annotates the VM to be restarted", func() {
+						Expect(reconciler.ReconcileNormal(vmCtx)).To(Succeed())
+						expectClassUpToDateReason(vmopv1.VirtualMachineClassUpdateRestartingReason)
+						expectRestartAnnotation(true)
+					})
+				})
+
+				When("the restart slot of the class is held by a VM that no longer uses the class", func() {
+					BeforeEach(func() {
+						otherVM.Spec.ClassName = "other-class"
+						initObjects = append(initObjects, otherVM, newClassUpdateRestarts(vm.Namespace, otherVM.Name))
+					})
+
+					It("drops the other VM and claims the restart slot", func() {
+						Expect(reconciler.ReconcileNormal(vmCtx)).To(Succeed())
+						expectClassUpToDateReason(vmopv1.VirtualMachineClassUpdateRestartingReason)
+						expectRestartAnnotation(true)
+						Expect(getClassUpdateRestarts()).To(Equal(vm.Name))
+					})
+				})
+
+				When("the restart slot of the class is held by a deleted VM", func() {
+					BeforeEach(func() {
+						initObjects = append(initObjects, newClassUpdateRestarts(vm.Namespace, otherVM.Name))
+					})
+
+					It("drops the deleted VM and claims the restart slot", func() {
+						Expect(reconciler.ReconcileNormal(vmCtx)).To(Succeed())
+						expectRestartAnnotation(true)
+						Expect(getClassUpdateRestarts()).To(Equal(vm.Name))
+					})
+				})
+
+				When("the restart slot of the class is held by a VM in another namespace", func() {
+					BeforeEach(func() {
+						otherVM.Namespace = "other-ns"
+						initObjects = append(initObjects, otherVM, newClassUpdateRestarts(otherVM.Namespace, otherVM.Name))
+					})
+
+					It("claims a restart slot in the VM's namespace", func() {
+						Expect(reconciler.ReconcileNormal(vmCtx)).To(Succeed())
+						expectRestartAnnotation(true)
+						Expect(getClassUpdateRestarts()).To(Equal(vm.Name))
+					})
+				})
+			})
+		})
 	})
 
 	Context("ReconcileDelete", func() {
@@ -187,6 +481,18 @@ func unitTestsReconcile() {
 			Expect(vmCtx.VM.Status.Phase).To(Equal(vmopv1.Deleting))
 		})
 
+		It("will not emit an event while the delete task is in progress", func() {
+			fakeVMProvider.DeleteVirtualMachineFn = func(ctx context.Context, vm *vmopv1.VirtualMachine) error {
+				return vmprovider.TaskInProgressError{TaskRef: "task-1", RequeueAfter: time.Second}
+			}
+
+			err := reconciler.ReconcileDelete(vmCtx)
+			Expect(err).To(MatchError("task task-1 is in progress"))
+			Expect(ctx.Events).ShouldNot(Receive())
+			Expect(vmCtx.VM.Status.Phase).To(Equal(vmopv1.Deleting))
+			Expect(vmCtx.VM.GetFinalizers()).To(ContainElement(finalizer))
+		})
+
 		It("Should not remove from Prober Manager if ReconcileDelete fails", func() {
 			// Simulate delete failure
 			fakeVMProvider.DeleteVirtualMachineFn = func(ctx context.Context, vm *vmopv1.VirtualMachine) error {
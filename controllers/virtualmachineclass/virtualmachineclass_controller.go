@@ -1,4 +1,4 @@
-// Copyright (c) 2020-2021 VMware, Inc. All Rights Reserved.
+// Copyright (c) 2020-2023 VMware, Inc. All Rights Reserved.
 // SPDX-License-Identifier: Apache-2.0
 
 package virtualmachineclass
@@ -10,6 +10,7 @@ import (
 	"strings"
 
 	"github.com/go-logr/logr"
+	"github.com/pkg/errors"
 	apiErrors "k8s.io/apimachinery/pkg/api/errors"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -19,7 +20,10 @@ import (
 	vmopv1 "github.com/vmware-tanzu/vm-operator/api/v1alpha1"
 
 	"github.com/vmware-tanzu/vm-operator/pkg/context"
+	"github.com/vmware-tanzu/vm-operator/pkg/patch"
 	"github.com/vmware-tanzu/vm-operator/pkg/record"
+	"github.com/vmware-tanzu/vm-operator/pkg/util"
+	"github.com/vmware-tanzu/vm-operator/pkg/vmprovider/providers/vsphere/virtualmachine"
 )
 
 // AddToManager adds this package's controller to the provided manager.
@@ -65,7 +69,7 @@ type Reconciler struct {
 // +kubebuilder:rbac:groups=vmoperator.vmware.com,resources=virtualmachineclasses,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=vmoperator.vmware.com,resources=virtualmachineclasses/status,verbs=get;update;patch
 
-func (r *Reconciler) Reconcile(ctx goctx.Context, req ctrl.Request) (ctrl.Result, error) {
+func (r *Reconciler) Reconcile(ctx goctx.Context, req ctrl.Request) (_ ctrl.Result, reterr error) {
 	vmClass := &vmopv1.VirtualMachineClass{}
 	err := r.Get(ctx, req.NamespacedName, vmClass)
 	if err != nil {
@@ -81,6 +85,19 @@ func (r *Reconciler) Reconcile(ctx goctx.Context, req ctrl.Request) (ctrl.Result
 		VMClass: vmClass,
 	}
 
+	patchHelper, err := patch.NewHelper(vmClass, r.Client)
+	if err != nil {
+		return ctrl.Result{}, errors.Wrapf(err, "failed to init patch helper for %s", vmClassCtx)
+	}
+	defer func() {
+		if err := patchHelper.Patch(ctx, vmClass); err != nil {
+			if reterr == nil {
+				reterr = err
+			}
+			vmClassCtx.Logger.Error(err, "patch failed")
+		}
+	}()
+
 	if err := r.ReconcileNormal(vmClassCtx); err != nil {
 		vmClassCtx.Logger.Error(err, "Failed to reconcile VirtualMachineClass")
 		return ctrl.Result{}, err
@@ -89,7 +106,29 @@ func (r *Reconciler) Reconcile(ctx goctx.Context, req ctrl.Request) (ctrl.Result
 	return ctrl.Result{}, nil
 }
 
-func (r *Reconciler) ReconcileNormal(_ *context.VirtualMachineClassContext) error {
-	// NoOp.
+// ReconcileNormal updates the class's effective ConfigSpec. A ConfigSpec that cannot be unmarshaled, for
+// example because the class was created before its ConfigSpec was validated, has no effective ConfigSpec.
+func (r *Reconciler) ReconcileNormal(ctx *context.VirtualMachineClassContext) error {
+	vmClass := ctx.VMClass
+
+	configSpec, err := virtualmachine.EffectiveClassConfigSpec(&vmClass.Spec)
+	if err != nil {
+		ctx.Logger.Error(err, "Failed to unmarshal the VirtualMachineClass ConfigSpec")
+		r.Recorder.Warnf(vmClass, "InvalidConfigSpec", "Failed to unmarshal ConfigSpec: %v", err)
+		vmClass.Status.EffectiveConfigSpec = nil
+		return nil
+	}
+
+	if configSpec == nil {
+		vmClass.Status.EffectiveConfigSpec = nil
+		return nil
+	}
+
+	raw, err := util.MarshalConfigSpecToJSON(configSpec)
+	if err != nil {
+		return errors.Wrap(err, "failed to marshal the effective ConfigSpec")
+	}
+	vmClass.Status.EffectiveConfigSpec = raw
+
 	return nil
 }
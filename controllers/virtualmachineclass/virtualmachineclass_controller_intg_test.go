@@ -1,4 +1,4 @@
-// Copyright (c) 2020 VMware, Inc. All Rights Reserved.
+// Copyright (c) 2020-2023 VMware, Inc. All Rights Reserved.
 // SPDX-License-Identifier: Apache-2.0
 
 package virtualmachineclass_test
@@ -10,6 +10,7 @@ import (
 	k8serrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	vmopv1 "github.com/vmware-tanzu/vm-operator/api/v1alpha1"
 
@@ -66,5 +67,21 @@ func intgTests() {
 
 		It("NoOp", func() {
 		})
+
+		When("the class has a ConfigSpec", func() {
+			BeforeEach(func() {
+				vmClass.Spec.ConfigSpec = []byte(`{"_typeName":"VirtualMachineConfigSpec","firmware":"efi"}`)
+			})
+
+			It("sets the effective ConfigSpec", func() {
+				Eventually(func() string {
+					obj := &vmopv1.VirtualMachineClass{}
+					if err := ctx.Client.Get(ctx, client.ObjectKeyFromObject(vmClass), obj); err != nil {
+						return ""
+					}
+					return string(obj.Status.EffectiveConfigSpec)
+				}).Should(And(ContainSubstring(`"firmware":"efi"`), ContainSubstring(`"numCPUs":4`)))
+			})
+		})
 	})
 }
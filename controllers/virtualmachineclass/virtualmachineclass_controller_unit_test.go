@@ -1,4 +1,4 @@
-// Copyright (c) 2020-2021 VMware, Inc. All Rights Reserved.
+// Copyright (c) 2020-2023 VMware, Inc. All Rights Reserved.
 // SPDX-License-Identifier: Apache-2.0
 
 package virtualmachineclass_test
@@ -7,13 +7,17 @@ import (
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
 
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
+	vimtypes "github.com/vmware/govmomi/vim25/types"
+
 	vmopv1 "github.com/vmware-tanzu/vm-operator/api/v1alpha1"
 
 	"github.com/vmware-tanzu/vm-operator/controllers/virtualmachineclass"
 	vmopContext "github.com/vmware-tanzu/vm-operator/pkg/context"
+	"github.com/vmware-tanzu/vm-operator/pkg/util"
 	"github.com/vmware-tanzu/vm-operator/test/builder"
 )
 
@@ -54,15 +58,70 @@ func unitTestsReconcile() {
 		}
 	})
 
+	AfterEach(func() {
+		ctx.AfterEach()
+		ctx = nil
+		initObjects = nil
+		reconciler = nil
+	})
+
 	Context("ReconcileNormal", func() {
 		BeforeEach(func() {
 			initObjects = append(initObjects, vmClass)
 		})
 
-		When("NoOp", func() {
-			It("returns success", func() {
+		When("the class does not have a ConfigSpec", func() {
+			It("does not set the effective ConfigSpec", func() {
+				err := reconciler.ReconcileNormal(vmClassCtx)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(vmClass.Status.EffectiveConfigSpec).To(BeEmpty())
+			})
+		})
+
+		When("the class has a ConfigSpec", func() {
+			BeforeEach(func() {
+				vmClass.Spec.Hardware.Cpus = 4
+				vmClass.Spec.Hardware.Memory = resource.MustParse("8Gi")
+
+				raw, err := util.MarshalConfigSpecToJSON(&vimtypes.VirtualMachineConfigSpec{
+					InstanceUuid: "dont-use-this-uuid",
+					Firmware:     "efi",
+					DeviceChange: []vimtypes.BaseVirtualDeviceConfigSpec{
+						&vimtypes.VirtualDeviceConfigSpec{
+							Operation: vimtypes.VirtualDeviceConfigSpecOperationAdd,
+							Device:    &vimtypes.ParaVirtualSCSIController{},
+						},
+					},
+				})
+				Expect(err).ToNot(HaveOccurred())
+				vmClass.Spec.ConfigSpec = raw
+			})
+
+			It("sets the effective ConfigSpec", func() {
+				err := reconciler.ReconcileNormal(vmClassCtx)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(vmClass.Status.EffectiveConfigSpec).ToNot(BeEmpty())
+
+				configSpec, err := util.UnmarshalConfigSpecFromJSON(vmClass.Status.EffectiveConfigSpec)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(configSpec.InstanceUuid).To(BeEmpty())
+				Expect(configSpec.Firmware).To(Equal("efi"))
+				Expect(configSpec.NumCPUs).To(BeEquivalentTo(4))
+				Expect(configSpec.MemoryMB).To(BeEquivalentTo(8 * 1024))
+				Expect(configSpec.DeviceChange).To(BeEmpty())
+			})
+		})
+
+		When("the class has a ConfigSpec that cannot be unmarshaled", func() {
+			BeforeEach(func() {
+				vmClass.Spec.ConfigSpec = []byte(`{"numCPUs": "two"}`)
+				vmClass.Status.EffectiveConfigSpec = []byte(`{"numCPUs": 2}`)
+			})
+
+			It("clears the effective ConfigSpec", func() {
 				err := reconciler.ReconcileNormal(vmClassCtx)
 				Expect(err).ToNot(HaveOccurred())
+				Expect(vmClass.Status.EffectiveConfigSpec).To(BeEmpty())
 			})
 		})
 	})
@@ -0,0 +1,510 @@
+// Copyright (c) 2023 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package virtualmachineimageimportrequest
+
+import (
+	goctx "context"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/pkg/errors"
+
+	corev1 "k8s.io/api/core/v1"
+	apiErrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+
+	"github.com/vmware/govmomi/vapi/library"
+
+	vmopv1 "github.com/vmware-tanzu/vm-operator/api/v1alpha1"
+
+	imgregv1a1 "github.com/vmware-tanzu/vm-operator/external/image-registry/api/v1alpha1"
+
+	"github.com/vmware-tanzu/vm-operator/pkg/conditions"
+	"github.com/vmware-tanzu/vm-operator/pkg/context"
+	"github.com/vmware-tanzu/vm-operator/pkg/patch"
+	"github.com/vmware-tanzu/vm-operator/pkg/record"
+	"github.com/vmware-tanzu/vm-operator/pkg/vmprovider"
+)
+
+const (
+	// requeueInterval is how often the progress of an in-progress import is checked.
+	requeueInterval = 10 * time.Second
+)
+
+// AddToManager adds this package's controller to the provided manager.
+func AddToManager(ctx *context.ControllerManagerContext, mgr manager.Manager) error {
+	var (
+		controlledType     = &vmopv1.VirtualMachineImageImportRequest{}
+		controlledTypeName = reflect.TypeOf(controlledType).Elem().Name()
+
+		controllerNameShort = fmt.Sprintf("%s-controller", strings.ToLower(controlledTypeName))
+		controllerNameLong  = fmt.Sprintf("%s/%s/%s", ctx.Namespace, ctx.Name, controllerNameShort)
+	)
+
+	r := NewReconciler(
+		mgr.GetClient(),
+		ctrl.Log.WithName("controllers").WithName(controlledTypeName),
+		record.New(mgr.GetEventRecorderFor(controllerNameLong)),
+		ctx.VMProvider,
+	)
+
+	return ctrl.NewControllerManagedBy(mgr).
+		For(controlledType).
+		WithOptions(controller.Options{MaxConcurrentReconciles: ctx.MaxConcurrentReconciles}).
+		Watches(&source.Kind{Type: &vmopv1.VirtualMachineImage{}},
+			handler.EnqueueRequestsFromMapFunc(vmiToVMImportMapperFn(ctx, r.Client))).
+		Complete(r)
+}
+
+// vmiToVMImportMapperFn returns a mapper function that can be used to queue reconcile requests
+// for the VirtualMachineImageImportRequests in response to an event on the VirtualMachineImage resource.
+func vmiToVMImportMapperFn(ctx *context.ControllerManagerContext, c client.Client) func(o client.Object) []reconcile.Request {
+	// For a given VirtualMachineImage, return reconcile requests for those
+	// VirtualMachineImageImportRequests that imported the item of the image.
+	return func(o client.Object) []reconcile.Request {
+		vmi := o.(*vmopv1.VirtualMachineImage)
+		if vmi.Spec.ImageID == "" {
+			return nil
+		}
+
+		logger := ctx.Logger.WithValues("name", vmi.Name, "namespace", vmi.Namespace)
+
+		vmImportList := &vmopv1.VirtualMachineImageImportRequestList{}
+		if err := c.List(ctx, vmImportList, client.InNamespace(vmi.Namespace)); err != nil {
+			logger.Error(err, "Failed to list VirtualMachineImageImportRequests for reconciliation due to VirtualMachineImage watch")
+			return nil
+		}
+
+		var reconcileRequests []reconcile.Request
+		for _, vmImport := range vmImportList.Items {
+			if vmImport.Status.ItemID == vmi.Spec.ImageID {
+				key := client.ObjectKey{Namespace: vmImport.Namespace, Name: vmImport.Name}
+				reconcileRequests = append(reconcileRequests, reconcile.Request{NamespacedName: key})
+			}
+		}
+
+		return reconcileRequests
+	}
+}
+
+func NewReconciler(
+	client client.Client,
+	logger logr.Logger,
+	recorder record.Recorder,
+	vmProvider vmprovider.VirtualMachineProviderInterface) *Reconciler {
+
+	return &Reconciler{
+		Client:     client,
+		Logger:     logger,
+		Recorder:   recorder,
+		VMProvider: vmProvider,
+	}
+}
+
+// Reconciler reconciles a VirtualMachineImageImportRequest object.
+type Reconciler struct {
+	client.Client
+	Logger     logr.Logger
+	Recorder   record.Recorder
+	VMProvider vmprovider.VirtualMachineProviderInterface
+}
+
+// +kubebuilder:rbac:groups=vmoperator.vmware.com,resources=virtualmachineimageimportrequests,verbs=get;list;watch;update;patch;delete
+// +kubebuilder:rbac:groups=vmoperator.vmware.com,resources=virtualmachineimageimportrequests/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups=vmoperator.vmware.com,resources=virtualmachineimages,verbs=get;list;watch
+// +kubebuilder:rbac:groups=imageregistry.vmware.com,resources=contentlibraries,verbs=get;list;watch
+
+func (r *Reconciler) Reconcile(ctx goctx.Context, req ctrl.Request) (_ ctrl.Result, reterr error) {
+	vmImportReq := &vmopv1.VirtualMachineImageImportRequest{}
+	if err := r.Get(ctx, req.NamespacedName, vmImportReq); err != nil {
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+
+	vmImportCtx := &context.VirtualMachineImageImportRequestContext{
+		Context:              ctx,
+		Logger:               ctrl.Log.WithName("VirtualMachineImageImportRequest").WithValues("name", req.NamespacedName),
+		VMImageImportRequest: vmImportReq,
+	}
+
+	patchHelper, err := patch.NewHelper(vmImportReq, r.Client)
+	if err != nil {
+		return ctrl.Result{}, errors.Wrapf(err, "failed to init patch helper for %s", vmImportCtx)
+	}
+	defer func() {
+		if err := patchHelper.Patch(ctx, vmImportReq); err != nil {
+			if reterr == nil {
+				reterr = err
+			}
+			vmImportCtx.Logger.Error(err, "patch failed")
+		}
+	}()
+
+	if !vmImportReq.DeletionTimestamp.IsZero() {
+		return ctrl.Result{}, nil
+	}
+
+	return r.ReconcileNormal(vmImportCtx)
+}
+
+func (r *Reconciler) ReconcileNormal(ctx *context.VirtualMachineImageImportRequestContext) (ctrl.Result, error) {
+	ctx.Logger.Info("Reconciling VirtualMachineImageImportRequest")
+	vmImportReq := ctx.VMImageImportRequest
+
+	if conditions.IsTrue(vmImportReq, vmopv1.VirtualMachineImageImportRequestConditionComplete) {
+		return r.removeVMImportResourceFromCluster(ctx)
+	}
+
+	// A failed import is not retried: the user must create a new request.
+	if isFailed(vmImportReq) {
+		return ctrl.Result{}, r.deleteFailedImportItem(ctx)
+	}
+
+	if vmImportReq.Status.StartTime.IsZero() {
+		vmImportReq.Status.StartTime = metav1.Now()
+	}
+
+	if vmImportReq.Status.UpdateSessionID == "" {
+		if err := r.checkIsTargetValid(ctx); err != nil {
+			return ctrl.Result{}, err
+		}
+
+		if !conditions.IsTrue(vmImportReq, vmopv1.VirtualMachineImageImportRequestConditionTargetValid) {
+			// The item already exists in the library, so there is no need to requeue.
+			return ctrl.Result{}, nil
+		}
+
+		if err := r.startImport(ctx); err != nil {
+			r.Recorder.EmitEvent(vmImportReq, "Import", err, true)
+			return ctrl.Result{}, err
+		}
+
+		return ctrl.Result{RequeueAfter: requeueInterval}, nil
+	}
+
+	if err := r.checkImportStatus(ctx); err != nil {
+		return ctrl.Result{}, err
+	}
+
+	if isFailed(vmImportReq) {
+		return ctrl.Result{}, r.deleteFailedImportItem(ctx)
+	}
+
+	if err := r.checkIsImageAvailable(ctx); err != nil {
+		return ctrl.Result{}, err
+	}
+
+	if r.checkIsComplete(ctx) {
+		return r.removeVMImportResourceFromCluster(ctx)
+	}
+
+	return ctrl.Result{RequeueAfter: requeueInterval}, nil
+}
+
+// isFailed returns true if the import failed in a way that cannot be recovered from.
+func isFailed(vmImportReq *vmopv1.VirtualMachineImageImportRequest) bool {
+	if conditions.GetReason(vmImportReq, vmopv1.VirtualMachineImageImportRequestConditionDownloaded) ==
+		vmopv1.DownloadFailureReason {
+		return true
+	}
+
+	switch conditions.GetReason(vmImportReq, vmopv1.VirtualMachineImageImportRequestConditionValidated) {
+	case vmopv1.ChecksumMismatchReason, vmopv1.ValidationFailureReason:
+		return true
+	}
+
+	return false
+}
+
+// itemName returns the name of the content library item into which the image is imported.
+func itemName(vmImportReq *vmopv1.VirtualMachineImageImportRequest) string {
+	if name := vmImportReq.Spec.Target.Item.Name; name != "" {
+		return name
+	}
+	return vmImportReq.Name
+}
+
+// checkIsTargetValid checks if the target item is valid. It is invalid if the content library
+// doesn't exist, isn't writable or ready, or an item with the same name exists in the library.
+func (r *Reconciler) checkIsTargetValid(ctx *context.VirtualMachineImageImportRequestContext) error {
+	vmImportReq := ctx.VMImageImportRequest
+
+	contentLibrary := &imgregv1a1.ContentLibrary{}
+	objKey := client.ObjectKey{Name: vmImportReq.Spec.Target.Location.Name, Namespace: vmImportReq.Namespace}
+	if err := r.Get(ctx, objKey, contentLibrary); err != nil {
+		ctx.Logger.Error(err, "failed to get ContentLibrary", "cl", objKey)
+		if apiErrors.IsNotFound(err) {
+			conditions.MarkFalse(vmImportReq,
+				vmopv1.VirtualMachineImageImportRequestConditionTargetValid,
+				vmopv1.TargetContentLibraryNotExistReason,
+				vmopv1.ConditionSeverityError, err.Error())
+		}
+		return err
+	}
+
+	if !contentLibrary.Spec.Writable {
+		err := fmt.Errorf("target location %s is not writable", contentLibrary.Status.Name)
+		conditions.MarkFalse(vmImportReq,
+			vmopv1.VirtualMachineImageImportRequestConditionTargetValid,
+			vmopv1.TargetContentLibraryNotWritableReason,
+			vmopv1.ConditionSeverityError, err.Error())
+		return err
+	}
+
+	isReady := false
+	for _, condition := range contentLibrary.Status.Conditions {
+		if condition.Type == imgregv1a1.ReadyCondition {
+			isReady = condition.Status == corev1.ConditionTrue
+			break
+		}
+	}
+
+	if !isReady {
+		err := fmt.Errorf("target location %s is not ready", contentLibrary.Status.Name)
+		conditions.MarkFalse(vmImportReq,
+			vmopv1.VirtualMachineImageImportRequestConditionTargetValid,
+			vmopv1.TargetContentLibraryNotReadyReason,
+			vmopv1.ConditionSeverityError, err.Error())
+		return err
+	}
+
+	ctx.ContentLibrary = contentLibrary
+	targetItemName := itemName(vmImportReq)
+	item, err := r.VMProvider.GetItemFromLibraryByName(ctx, contentLibrary.Spec.UUID, targetItemName)
+	if err != nil {
+		ctx.Logger.Error(err, "failed to find item", "cl", objKey, "item name", targetItemName)
+		return err
+	}
+
+	if item != nil {
+		conditions.MarkFalse(vmImportReq,
+			vmopv1.VirtualMachineImageImportRequestConditionTargetValid,
+			vmopv1.TargetItemAlreadyExistsReason,
+			vmopv1.ConditionSeverityError,
+			fmt.Sprintf("item with name %s already exists in the content library %s", targetItemName,
+				contentLibrary.Status.Name))
+		return nil
+	}
+
+	conditions.MarkTrue(vmImportReq, vmopv1.VirtualMachineImageImportRequestConditionTargetValid)
+	return nil
+}
+
+// startImport creates the content library item and starts pulling its files from the source URL.
+func (r *Reconciler) startImport(ctx *context.VirtualMachineImageImportRequestContext) error {
+	vmImportReq := ctx.VMImageImportRequest
+
+	var checksum *library.Checksum
+	if c := vmImportReq.Spec.Source.Checksum; c != nil {
+		checksum = &library.Checksum{
+			Algorithm: c.Algorithm,
+			Checksum:  c.Value,
+		}
+	}
+
+	itemID, sessionID, err := r.VMProvider.ImportContentLibraryItemFromURL(ctx, ctx.ContentLibrary,
+		itemName(vmImportReq), vmImportReq.Spec.Target.Item.Description, vmImportReq.Spec.Source.URL, checksum)
+	if err != nil {
+		ctx.Logger.Error(err, "failed to start import")
+		return errors.Wrapf(err, "failed to import %s", vmImportReq.Spec.Source.URL)
+	}
+
+	ctx.Logger.Info("Started importing item", "itemID", itemID, "sessionID", sessionID)
+	vmImportReq.Status.ItemID = itemID
+	vmImportReq.Status.UpdateSessionID = sessionID
+	conditions.MarkFalse(vmImportReq,
+		vmopv1.VirtualMachineImageImportRequestConditionDownloaded,
+		vmopv1.DownloadingReason,
+		vmopv1.ConditionSeverityInfo,
+		"downloading %s", vmImportReq.Spec.Source.URL)
+
+	return nil
+}
+
+// checkImportStatus updates the Downloaded and Validated conditions from the status of the import.
+// The import is completed once all the files have been downloaded, after which the library validates
+// the item. The item is deleted if the import fails.
+func (r *Reconciler) checkImportStatus(ctx *context.VirtualMachineImageImportRequestContext) error {
+	vmImportReq := ctx.VMImageImportRequest
+	if conditions.IsTrue(vmImportReq, vmopv1.VirtualMachineImageImportRequestConditionValidated) {
+		return nil
+	}
+
+	status, err := r.VMProvider.GetContentLibraryItemImportStatus(ctx, vmImportReq.Status.UpdateSessionID)
+	if err != nil {
+		ctx.Logger.Error(err, "failed to get import status")
+		return err
+	}
+
+	switch status.Phase {
+	case vmprovider.ImportPhaseDownloading:
+		msg := fmt.Sprintf("downloaded %d bytes", status.BytesTransferred)
+		if status.Size > 0 {
+			msg = fmt.Sprintf("downloaded %d of %d bytes", status.BytesTransferred, status.Size)
+		}
+		conditions.MarkFalse(vmImportReq,
+			vmopv1.VirtualMachineImageImportRequestConditionDownloaded,
+			vmopv1.DownloadingReason,
+			vmopv1.ConditionSeverityInfo, "%s", msg)
+
+	case vmprovider.ImportPhaseDownloaded:
+		if !conditions.IsTrue(vmImportReq, vmopv1.VirtualMachineImageImportRequestConditionDownloaded) {
+			if err := r.VMProvider.CompleteContentLibraryItemImport(ctx, vmImportReq.Status.UpdateSessionID); err != nil {
+				ctx.Logger.Error(err, "failed to complete import")
+				return err
+			}
+			conditions.MarkTrue(vmImportReq, vmopv1.VirtualMachineImageImportRequestConditionDownloaded)
+		}
+		conditions.MarkFalse(vmImportReq,
+			vmopv1.VirtualMachineImageImportRequestConditionValidated,
+			vmopv1.ValidatingReason,
+			vmopv1.ConditionSeverityInfo,
+			"item is being validated")
+
+	case vmprovider.ImportPhaseDone:
+		conditions.MarkTrue(vmImportReq, vmopv1.VirtualMachineImageImportRequestConditionDownloaded)
+		conditions.MarkTrue(vmImportReq, vmopv1.VirtualMachineImageImportRequestConditionValidated)
+
+	case vmprovider.ImportPhaseDownloadFailed:
+		// The library reports a checksum mismatch as a failure to transfer the file.
+		if vmImportReq.Spec.Source.Checksum != nil && strings.Contains(strings.ToLower(status.Message), "checksum") {
+			conditions.MarkTrue(vmImportReq, vmopv1.VirtualMachineImageImportRequestConditionDownloaded)
+			conditions.MarkFalse(vmImportReq,
+				vmopv1.VirtualMachineImageImportRequestConditionValidated,
+				vmopv1.ChecksumMismatchReason,
+				vmopv1.ConditionSeverityError, "%s", status.Message)
+		} else {
+			conditions.MarkFalse(vmImportReq,
+				vmopv1.VirtualMachineImageImportRequestConditionDownloaded,
+				vmopv1.DownloadFailureReason,
+				vmopv1.ConditionSeverityError, "%s", status.Message)
+		}
+		r.Recorder.Warnf(vmImportReq, "ImportFailed", "failed to import %s: %s", vmImportReq.Spec.Source.URL, status.Message)
+
+	case vmprovider.ImportPhaseValidationFailed:
+		conditions.MarkTrue(vmImportReq, vmopv1.VirtualMachineImageImportRequestConditionDownloaded)
+		conditions.MarkFalse(vmImportReq,
+			vmopv1.VirtualMachineImageImportRequestConditionValidated,
+			vmopv1.ValidationFailureReason,
+			vmopv1.ConditionSeverityError, "%s", status.Message)
+		r.Recorder.Warnf(vmImportReq, "ImportFailed", "failed to import %s: %s", vmImportReq.Spec.Source.URL, status.Message)
+	}
+
+	return nil
+}
+
+// deleteFailedImportItem deletes the content library item of a failed import so that the import can
+// be retried with a new request for the same item.
+func (r *Reconciler) deleteFailedImportItem(ctx *context.VirtualMachineImageImportRequestContext) error {
+	vmImportReq := ctx.VMImageImportRequest
+	if vmImportReq.Status.ItemID == "" {
+		return nil
+	}
+
+	if err := r.VMProvider.DeleteContentLibraryItem(ctx, vmImportReq.Status.ItemID); err != nil {
+		ctx.Logger.Error(err, "failed to delete item of failed import", "itemID", vmImportReq.Status.ItemID)
+		return err
+	}
+
+	vmImportReq.Status.ItemID = ""
+	return nil
+}
+
+// checkIsImageAvailable checks if the VirtualMachineImage resource of the imported item is available in the cluster.
+func (r *Reconciler) checkIsImageAvailable(ctx *context.VirtualMachineImageImportRequestContext) error {
+	vmImportReq := ctx.VMImageImportRequest
+	if !conditions.IsTrue(vmImportReq, vmopv1.VirtualMachineImageImportRequestConditionValidated) {
+		return nil
+	}
+
+	if conditions.IsTrue(vmImportReq, vmopv1.VirtualMachineImageImportRequestConditionImageAvailable) {
+		return nil
+	}
+
+	vmiList := &vmopv1.VirtualMachineImageList{}
+	if err := r.Client.List(ctx, vmiList, client.InNamespace(vmImportReq.Namespace)); err != nil {
+		ctx.Logger.Error(err, "failed to list VirtualMachineImage")
+		return err
+	}
+
+	for _, vmi := range vmiList.Items {
+		if vmi.Spec.ImageID == vmImportReq.Status.ItemID {
+			vmImportReq.Status.ImageName = vmi.Name
+			conditions.MarkTrue(vmImportReq, vmopv1.VirtualMachineImageImportRequestConditionImageAvailable)
+			ctx.Logger.Info("VirtualMachineImage is available", "vmiName", vmi.Name)
+			return nil
+		}
+	}
+
+	conditions.MarkFalse(vmImportReq,
+		vmopv1.VirtualMachineImageImportRequestConditionImageAvailable,
+		vmopv1.TargetVirtualMachineImageNotFoundReason,
+		vmopv1.ConditionSeverityWarning, "VirtualMachineImage not found")
+
+	return nil
+}
+
+// checkIsComplete checks if condition Complete can be marked to true.
+// The condition's status is set to true only when all other conditions present on the resource have a truthy status.
+func (r *Reconciler) checkIsComplete(ctx *context.VirtualMachineImageImportRequestContext) bool {
+	vmImportReq := ctx.VMImageImportRequest
+
+	if !conditions.IsTrue(vmImportReq, vmopv1.VirtualMachineImageImportRequestConditionDownloaded) {
+		conditions.MarkFalse(vmImportReq,
+			vmopv1.VirtualMachineImageImportRequestConditionComplete,
+			vmopv1.HasNotBeenDownloadedReason,
+			vmopv1.ConditionSeverityWarning,
+			"item hasn't been downloaded yet")
+		return false
+	}
+
+	if !conditions.IsTrue(vmImportReq, vmopv1.VirtualMachineImageImportRequestConditionImageAvailable) {
+		conditions.MarkFalse(vmImportReq,
+			vmopv1.VirtualMachineImageImportRequestConditionComplete,
+			vmopv1.ImageUnavailableReason,
+			vmopv1.ConditionSeverityWarning,
+			"VirtualMachineImage is not available")
+		return false
+	}
+
+	conditions.MarkTrue(vmImportReq, vmopv1.VirtualMachineImageImportRequestConditionComplete)
+	vmImportReq.Status.Ready = true
+	vmImportReq.Status.CompletionTime = metav1.Now()
+	ctx.Logger.Info("VM image import request completed", "time", vmImportReq.Status.CompletionTime)
+
+	return true
+}
+
+// removeVMImportResourceFromCluster deletes the completed request once its spec.ttlSecondsAfterFinished elapses.
+func (r *Reconciler) removeVMImportResourceFromCluster(ctx *context.VirtualMachineImageImportRequestContext) (ctrl.Result, error) {
+	vmImportReq := ctx.VMImageImportRequest
+	ttlSecondsAfterFinished := vmImportReq.Spec.TTLSecondsAfterFinished
+	if ttlSecondsAfterFinished == nil {
+		return ctrl.Result{}, nil
+	}
+
+	if *ttlSecondsAfterFinished > 0 {
+		targetTime := vmImportReq.Status.CompletionTime.Add(time.Duration(*ttlSecondsAfterFinished) * time.Second)
+		if requeueAfter := time.Until(targetTime); requeueAfter > 0 {
+			return ctrl.Result{RequeueAfter: requeueAfter}, nil
+		}
+	}
+
+	ctx.Logger.Info("deleting VM Image Import Request")
+	if err := r.Delete(ctx, vmImportReq); err != nil {
+		ctx.Logger.Error(err, "failed to delete VM image import request")
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+
+	return ctrl.Result{}, nil
+}
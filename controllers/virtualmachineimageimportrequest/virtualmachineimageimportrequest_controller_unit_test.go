@@ -0,0 +1,377 @@
+// Copyright (c) 2023 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package virtualmachineimageimportrequest_test
+
+import (
+	goctx "context"
+	"errors"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	apiErrors "k8s.io/apimachinery/pkg/api/errors"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/vmware/govmomi/vapi/library"
+
+	vmopv1 "github.com/vmware-tanzu/vm-operator/api/v1alpha1"
+
+	imgregv1a1 "github.com/vmware-tanzu/vm-operator/external/image-registry/api/v1alpha1"
+
+	"github.com/vmware-tanzu/vm-operator/controllers/virtualmachineimageimportrequest"
+	"github.com/vmware-tanzu/vm-operator/pkg/conditions"
+	vmopContext "github.com/vmware-tanzu/vm-operator/pkg/context"
+	"github.com/vmware-tanzu/vm-operator/pkg/vmprovider"
+	providerfake "github.com/vmware-tanzu/vm-operator/pkg/vmprovider/fake"
+	"github.com/vmware-tanzu/vm-operator/test/builder"
+)
+
+func unitTests() {
+	Describe("Invoking VirtualMachineImageImportRequest Reconcile", unitTestsReconcile)
+}
+
+func unitTestsReconcile() {
+	const (
+		sourceURL = "https://example.com/images/photon.ova"
+		itemID    = "dummy-item-id"
+		sessionID = "dummy-session-id"
+	)
+
+	var (
+		initObjects []client.Object
+		ctx         *builder.UnitTestContextForController
+
+		reconciler     *virtualmachineimageimportrequest.Reconciler
+		fakeVMProvider *providerfake.VMProvider
+
+		vmImportReq    *vmopv1.VirtualMachineImageImportRequest
+		cl             *imgregv1a1.ContentLibrary
+		vmImportReqCtx *vmopContext.VirtualMachineImageImportRequestContext
+	)
+
+	BeforeEach(func() {
+		vmImportReq = builder.DummyVirtualMachineImageImportRequest("dummy-import", "dummy-ns", sourceURL,
+			"dummy-item", "dummy-cl")
+		cl = builder.DummyContentLibrary("dummy-cl", vmImportReq.Namespace, "dummy-cl-uuid")
+	})
+
+	JustBeforeEach(func() {
+		ctx = suite.NewUnitTestContextForController(initObjects...)
+		reconciler = virtualmachineimageimportrequest.NewReconciler(
+			ctx.Client,
+			ctx.Logger,
+			ctx.Recorder,
+			ctx.VMProvider,
+		)
+		fakeVMProvider = ctx.VMProvider.(*providerfake.VMProvider)
+		fakeVMProvider.Reset()
+
+		vmImportReqCtx = &vmopContext.VirtualMachineImageImportRequestContext{
+			Context:              ctx,
+			Logger:               ctx.Logger.WithName(vmImportReq.Name),
+			VMImageImportRequest: vmImportReq,
+		}
+	})
+
+	AfterEach(func() {
+		ctx.AfterEach()
+		ctx = nil
+		initObjects = nil
+		reconciler = nil
+	})
+
+	Context("ReconcileNormal", func() {
+		BeforeEach(func() {
+			initObjects = append(initObjects, vmImportReq, cl)
+		})
+
+		When("the import has not started", func() {
+			It("starts the import", func() {
+				var importedURL, importedItemName string
+				var importedChecksum *library.Checksum
+				vmImportReq.Spec.Source.Checksum = &vmopv1.VirtualMachineImageImportRequestChecksum{
+					Algorithm: "SHA256",
+					Value:     "abcdef",
+				}
+
+				fakeVMProvider.ImportContentLibraryItemFromURLFn = func(_ goctx.Context, cl *imgregv1a1.ContentLibrary,
+					itemName, _, url string, checksum *library.Checksum) (string, string, error) {
+					importedURL, importedItemName, importedChecksum = url, itemName, checksum
+					return itemID, sessionID, nil
+				}
+
+				result, err := reconciler.ReconcileNormal(vmImportReqCtx)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(result.RequeueAfter).ToNot(BeZero())
+
+				Expect(importedURL).To(Equal(sourceURL))
+				Expect(importedItemName).To(Equal("dummy-item"))
+				Expect(importedChecksum).To(Equal(&library.Checksum{Algorithm: "SHA256", Checksum: "abcdef"}))
+
+				Expect(vmImportReq.Status.StartTime.IsZero()).To(BeFalse())
+				Expect(vmImportReq.Status.ItemID).To(Equal(itemID))
+				Expect(vmImportReq.Status.UpdateSessionID).To(Equal(sessionID))
+				Expect(conditions.IsTrue(vmImportReq, vmopv1.VirtualMachineImageImportRequestConditionTargetValid)).To(BeTrue())
+				Expect(conditions.GetReason(vmImportReq, vmopv1.VirtualMachineImageImportRequestConditionDownloaded)).
+					To(Equal(vmopv1.DownloadingReason))
+			})
+
+			It("uses the request name when the item name is omitted", func() {
+				vmImportReq.Spec.Target.Item.Name = ""
+
+				var importedItemName string
+				fakeVMProvider.ImportContentLibraryItemFromURLFn = func(_ goctx.Context, _ *imgregv1a1.ContentLibrary,
+					itemName, _, _ string, _ *library.Checksum) (string, string, error) {
+					importedItemName = itemName
+					return itemID, sessionID, nil
+				}
+
+				_, err := reconciler.ReconcileNormal(vmImportReqCtx)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(importedItemName).To(Equal(vmImportReq.Name))
+			})
+
+			It("returns an error if the import cannot be started", func() {
+				fakeVMProvider.ImportContentLibraryItemFromURLFn = func(_ goctx.Context, _ *imgregv1a1.ContentLibrary,
+					_, _, _ string, _ *library.Checksum) (string, string, error) {
+					return "", "", errors.New("import failed")
+				}
+
+				_, err := reconciler.ReconcileNormal(vmImportReqCtx)
+				Expect(err).To(HaveOccurred())
+				Expect(vmImportReq.Status.UpdateSessionID).To(BeEmpty())
+			})
+
+			When("the target content library does not exist", func() {
+				BeforeEach(func() {
+					vmImportReq.Spec.Target.Location.Name = "missing-cl"
+				})
+
+				It("marks TargetValid false", func() {
+					_, err := reconciler.ReconcileNormal(vmImportReqCtx)
+					Expect(err).To(HaveOccurred())
+					Expect(conditions.GetReason(vmImportReq, vmopv1.VirtualMachineImageImportRequestConditionTargetValid)).
+						To(Equal(vmopv1.TargetContentLibraryNotExistReason))
+				})
+			})
+
+			When("the target content library is not writable", func() {
+				BeforeEach(func() {
+					cl.Spec.Writable = false
+				})
+
+				It("marks TargetValid false", func() {
+					_, err := reconciler.ReconcileNormal(vmImportReqCtx)
+					Expect(err).To(HaveOccurred())
+					Expect(conditions.GetReason(vmImportReq, vmopv1.VirtualMachineImageImportRequestConditionTargetValid)).
+						To(Equal(vmopv1.TargetContentLibraryNotWritableReason))
+				})
+			})
+
+			It("marks TargetValid false and does not requeue if the item already exists", func() {
+				fakeVMProvider.GetItemFromLibraryByNameFn = func(_ goctx.Context, _, _ string) (*library.Item, error) {
+					return &library.Item{ID: "existing-item-id"}, nil
+				}
+
+				result, err := reconciler.ReconcileNormal(vmImportReqCtx)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(result.RequeueAfter).To(BeZero())
+				Expect(conditions.GetReason(vmImportReq, vmopv1.VirtualMachineImageImportRequestConditionTargetValid)).
+					To(Equal(vmopv1.TargetItemAlreadyExistsReason))
+				Expect(vmImportReq.Status.UpdateSessionID).To(BeEmpty())
+			})
+		})
+
+		When("the import has started", func() {
+			var (
+				importStatus    *vmprovider.ImportStatus
+				completeCalls   int
+				deletedItemIDs  []string
+				importStatusErr error
+			)
+
+			BeforeEach(func() {
+				vmImportReq.Status.ItemID = itemID
+				vmImportReq.Status.UpdateSessionID = sessionID
+				conditions.MarkTrue(vmImportReq, vmopv1.VirtualMachineImageImportRequestConditionTargetValid)
+
+				importStatus = nil
+				importStatusErr = nil
+				completeCalls = 0
+				deletedItemIDs = nil
+			})
+
+			JustBeforeEach(func() {
+				fakeVMProvider.GetContentLibraryItemImportStatusFn = func(_ goctx.Context, id string) (*vmprovider.ImportStatus, error) {
+					Expect(id).To(Equal(sessionID))
+					return importStatus, importStatusErr
+				}
+				fakeVMProvider.CompleteContentLibraryItemImportFn = func(_ goctx.Context, id string) error {
+					Expect(id).To(Equal(sessionID))
+					completeCalls++
+					return nil
+				}
+				fakeVMProvider.DeleteContentLibraryItemFn = func(_ goctx.Context, id string) error {
+					deletedItemIDs = append(deletedItemIDs, id)
+					return nil
+				}
+			})
+
+			It("reports the download progress", func() {
+				importStatus = &vmprovider.ImportStatus{
+					Phase:            vmprovider.ImportPhaseDownloading,
+					BytesTransferred: 5,
+					Size:             10,
+				}
+
+				result, err := reconciler.ReconcileNormal(vmImportReqCtx)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(result.RequeueAfter).ToNot(BeZero())
+				Expect(conditions.GetReason(vmImportReq, vmopv1.VirtualMachineImageImportRequestConditionDownloaded)).
+					To(Equal(vmopv1.DownloadingReason))
+				Expect(conditions.GetMessage(vmImportReq, vmopv1.VirtualMachineImageImportRequestConditionDownloaded)).
+					To(Equal("downloaded 5 of 10 bytes"))
+				Expect(conditions.GetReason(vmImportReq, vmopv1.VirtualMachineImageImportRequestConditionComplete)).
+					To(Equal(vmopv1.HasNotBeenDownloadedReason))
+			})
+
+			It("completes the import once when all files are downloaded", func() {
+				importStatus = &vmprovider.ImportStatus{Phase: vmprovider.ImportPhaseDownloaded}
+
+				_, err := reconciler.ReconcileNormal(vmImportReqCtx)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(completeCalls).To(Equal(1))
+				Expect(conditions.IsTrue(vmImportReq, vmopv1.VirtualMachineImageImportRequestConditionDownloaded)).To(BeTrue())
+				Expect(conditions.GetReason(vmImportReq, vmopv1.VirtualMachineImageImportRequestConditionValidated)).
+					To(Equal(vmopv1.ValidatingReason))
+
+				By("not completing the import again while it is validated", func() {
+					_, err := reconciler.ReconcileNormal(vmImportReqCtx)
+					Expect(err).ToNot(HaveOccurred())
+					Expect(completeCalls).To(Equal(1))
+				})
+			})
+
+			It("returns an error if the import status cannot be retrieved", func() {
+				importStatusErr = errors.New("get status failed")
+
+				_, err := reconciler.ReconcileNormal(vmImportReqCtx)
+				Expect(err).To(HaveOccurred())
+			})
+
+			When("the import is done", func() {
+				BeforeEach(func() {
+					importStatus = &vmprovider.ImportStatus{Phase: vmprovider.ImportPhaseDone}
+				})
+
+				It("waits for the VirtualMachineImage", func() {
+					result, err := reconciler.ReconcileNormal(vmImportReqCtx)
+					Expect(err).ToNot(HaveOccurred())
+					Expect(result.RequeueAfter).ToNot(BeZero())
+					Expect(conditions.IsTrue(vmImportReq, vmopv1.VirtualMachineImageImportRequestConditionValidated)).To(BeTrue())
+					Expect(conditions.GetReason(vmImportReq, vmopv1.VirtualMachineImageImportRequestConditionImageAvailable)).
+						To(Equal(vmopv1.TargetVirtualMachineImageNotFoundReason))
+					Expect(conditions.GetReason(vmImportReq, vmopv1.VirtualMachineImageImportRequestConditionComplete)).
+						To(Equal(vmopv1.ImageUnavailableReason))
+				})
+
+				When("the VirtualMachineImage is available", func() {
+					BeforeEach(func() {
+						vmi := builder.DummyVirtualMachineImage("vmi-imported")
+						vmi.Namespace = vmImportReq.Namespace
+						vmi.Spec.ImageID = itemID
+						initObjects = append(initObjects, vmi)
+					})
+
+					It("completes the request", func() {
+						result, err := reconciler.ReconcileNormal(vmImportReqCtx)
+						Expect(err).ToNot(HaveOccurred())
+						Expect(result.RequeueAfter).To(BeZero())
+						Expect(vmImportReq.Status.ImageName).To(Equal("vmi-imported"))
+						Expect(vmImportReq.Status.Ready).To(BeTrue())
+						Expect(vmImportReq.Status.CompletionTime.IsZero()).To(BeFalse())
+						Expect(conditions.IsTrue(vmImportReq, vmopv1.VirtualMachineImageImportRequestConditionComplete)).To(BeTrue())
+					})
+
+					It("deletes the request when TTLSecondsAfterFinished is zero", func() {
+						ttl := int64(0)
+						vmImportReq.Spec.TTLSecondsAfterFinished = &ttl
+
+						_, err := reconciler.ReconcileNormal(vmImportReqCtx)
+						Expect(err).ToNot(HaveOccurred())
+
+						err = ctx.Client.Get(ctx, client.ObjectKeyFromObject(vmImportReq), &vmopv1.VirtualMachineImageImportRequest{})
+						Expect(apiErrors.IsNotFound(err)).To(BeTrue())
+					})
+				})
+			})
+
+			It("marks Downloaded false and deletes the item when the download fails", func() {
+				importStatus = &vmprovider.ImportStatus{
+					Phase:   vmprovider.ImportPhaseDownloadFailed,
+					Message: "connection refused",
+				}
+
+				result, err := reconciler.ReconcileNormal(vmImportReqCtx)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(result.RequeueAfter).To(BeZero())
+				Expect(conditions.GetReason(vmImportReq, vmopv1.VirtualMachineImageImportRequestConditionDownloaded)).
+					To(Equal(vmopv1.DownloadFailureReason))
+				Expect(conditions.GetMessage(vmImportReq, vmopv1.VirtualMachineImageImportRequestConditionDownloaded)).
+					To(Equal("connection refused"))
+				Expect(deletedItemIDs).To(ConsistOf(itemID))
+				Expect(vmImportReq.Status.ItemID).To(BeEmpty())
+
+				By("not checking the import status again", func() {
+					importStatusErr = errors.New("should not be called")
+					_, err := reconciler.ReconcileNormal(vmImportReqCtx)
+					Expect(err).ToNot(HaveOccurred())
+				})
+			})
+
+			It("marks Validated false when the checksum does not match", func() {
+				vmImportReq.Spec.Source.Checksum = &vmopv1.VirtualMachineImageImportRequestChecksum{
+					Algorithm: "SHA256",
+					Value:     "abcdef",
+				}
+				importStatus = &vmprovider.ImportStatus{
+					Phase:   vmprovider.ImportPhaseDownloadFailed,
+					Message: "The checksum of file photon.ova does not match",
+				}
+
+				_, err := reconciler.ReconcileNormal(vmImportReqCtx)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(conditions.IsTrue(vmImportReq, vmopv1.VirtualMachineImageImportRequestConditionDownloaded)).To(BeTrue())
+				Expect(conditions.GetReason(vmImportReq, vmopv1.VirtualMachineImageImportRequestConditionValidated)).
+					To(Equal(vmopv1.ChecksumMismatchReason))
+				Expect(deletedItemIDs).To(ConsistOf(itemID))
+			})
+
+			It("marks Validated false when the item is not valid", func() {
+				importStatus = &vmprovider.ImportStatus{
+					Phase:   vmprovider.ImportPhaseValidationFailed,
+					Message: "invalid OVF descriptor",
+				}
+
+				_, err := reconciler.ReconcileNormal(vmImportReqCtx)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(conditions.GetReason(vmImportReq, vmopv1.VirtualMachineImageImportRequestConditionValidated)).
+					To(Equal(vmopv1.ValidationFailureReason))
+				Expect(deletedItemIDs).To(ConsistOf(itemID))
+			})
+
+			It("retries deleting the item of a failed import", func() {
+				conditions.MarkFalse(vmImportReq,
+					vmopv1.VirtualMachineImageImportRequestConditionDownloaded,
+					vmopv1.DownloadFailureReason,
+					vmopv1.ConditionSeverityError, "connection refused")
+
+				_, err := reconciler.ReconcileNormal(vmImportReqCtx)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(deletedItemIDs).To(ConsistOf(itemID))
+				Expect(vmImportReq.Status.ItemID).To(BeEmpty())
+			})
+		})
+	})
+}
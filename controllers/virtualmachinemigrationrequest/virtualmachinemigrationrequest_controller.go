@@ -0,0 +1,418 @@
+// Copyright (c) 2023 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package virtualmachinemigrationrequest
+
+import (
+	goctx "context"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/pkg/errors"
+
+	storagev1 "k8s.io/api/storage/v1"
+	apiErrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+
+	vimtypes "github.com/vmware/govmomi/vim25/types"
+
+	vmopv1 "github.com/vmware-tanzu/vm-operator/api/v1alpha1"
+
+	"github.com/vmware-tanzu/vm-operator/pkg/conditions"
+	"github.com/vmware-tanzu/vm-operator/pkg/context"
+	"github.com/vmware-tanzu/vm-operator/pkg/patch"
+	"github.com/vmware-tanzu/vm-operator/pkg/record"
+	"github.com/vmware-tanzu/vm-operator/pkg/topology"
+	"github.com/vmware-tanzu/vm-operator/pkg/vmprovider"
+)
+
+const (
+	// requeueInterval is how often the progress of an in-progress migration is checked.
+	requeueInterval = 10 * time.Second
+)
+
+// AddToManager adds this package's controller to the provided manager.
+func AddToManager(ctx *context.ControllerManagerContext, mgr manager.Manager) error {
+	var (
+		controlledType     = &vmopv1.VirtualMachineMigrationRequest{}
+		controlledTypeName = reflect.TypeOf(controlledType).Elem().Name()
+
+		controllerNameShort = fmt.Sprintf("%s-controller", strings.ToLower(controlledTypeName))
+		controllerNameLong  = fmt.Sprintf("%s/%s/%s", ctx.Namespace, ctx.Name, controllerNameShort)
+	)
+
+	r := NewReconciler(
+		mgr.GetClient(),
+		ctrl.Log.WithName("controllers").WithName(controlledTypeName),
+		record.New(mgr.GetEventRecorderFor(controllerNameLong)),
+		ctx.VMProvider,
+	)
+
+	return ctrl.NewControllerManagedBy(mgr).
+		For(controlledType).
+		WithOptions(controller.Options{MaxConcurrentReconciles: ctx.MaxConcurrentReconciles}).
+		Complete(r)
+}
+
+func NewReconciler(
+	client client.Client,
+	logger logr.Logger,
+	recorder record.Recorder,
+	vmProvider vmprovider.VirtualMachineProviderInterface) *Reconciler {
+
+	return &Reconciler{
+		Client:     client,
+		Logger:     logger,
+		Recorder:   recorder,
+		VMProvider: vmProvider,
+	}
+}
+
+// Reconciler reconciles a VirtualMachineMigrationRequest object.
+type Reconciler struct {
+	client.Client
+	Logger     logr.Logger
+	Recorder   record.Recorder
+	VMProvider vmprovider.VirtualMachineProviderInterface
+}
+
+// +kubebuilder:rbac:groups=vmoperator.vmware.com,resources=virtualmachinemigrationrequests,verbs=get;list;watch;update;patch;delete
+// +kubebuilder:rbac:groups=vmoperator.vmware.com,resources=virtualmachinemigrationrequests/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups=vmoperator.vmware.com,resources=virtualmachines,verbs=get;list;watch;update;patch
+// +kubebuilder:rbac:groups=vmoperator.vmware.com,resources=virtualmachines/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups=topology.tanzu.vmware.com,resources=availabilityzones,verbs=get;list;watch
+// +kubebuilder:rbac:groups=storage.k8s.io,resources=storageclasses,verbs=get;list;watch
+
+func (r *Reconciler) Reconcile(ctx goctx.Context, req ctrl.Request) (_ ctrl.Result, reterr error) {
+	vmMigrateReq := &vmopv1.VirtualMachineMigrationRequest{}
+	if err := r.Get(ctx, req.NamespacedName, vmMigrateReq); err != nil {
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+
+	vmMigrateCtx := &context.VirtualMachineMigrationRequestContext{
+		Context:            ctx,
+		Logger:             ctrl.Log.WithName("VirtualMachineMigrationRequest").WithValues("name", req.NamespacedName),
+		VMMigrationRequest: vmMigrateReq,
+	}
+
+	patchHelper, err := patch.NewHelper(vmMigrateReq, r.Client)
+	if err != nil {
+		return ctrl.Result{}, errors.Wrapf(err, "failed to init patch helper for %s", vmMigrateCtx)
+	}
+	defer func() {
+		if err := patchHelper.Patch(ctx, vmMigrateReq); err != nil {
+			if reterr == nil {
+				reterr = err
+			}
+			vmMigrateCtx.Logger.Error(err, "patch failed")
+		}
+	}()
+
+	if !vmMigrateReq.DeletionTimestamp.IsZero() {
+		return ctrl.Result{}, nil
+	}
+
+	return r.ReconcileNormal(vmMigrateCtx)
+}
+
+func (r *Reconciler) ReconcileNormal(ctx *context.VirtualMachineMigrationRequestContext) (ctrl.Result, error) {
+	ctx.Logger.Info("Reconciling VirtualMachineMigrationRequest")
+	vmMigrateReq := ctx.VMMigrationRequest
+
+	if conditions.IsTrue(vmMigrateReq, vmopv1.VirtualMachineMigrationRequestConditionComplete) {
+		return r.removeVMMigrateResourceFromCluster(ctx)
+	}
+
+	// A failed migration is not retried: the user must create a new request.
+	if conditions.GetReason(vmMigrateReq, vmopv1.VirtualMachineMigrationRequestConditionMigrated) ==
+		vmopv1.MigrationFailureReason {
+		return ctrl.Result{}, nil
+	}
+
+	if vmMigrateReq.Status.StartTime.IsZero() {
+		vmMigrateReq.Status.StartTime = metav1.Now()
+	}
+
+	if err := r.getSourceVM(ctx); err != nil {
+		return ctrl.Result{}, err
+	}
+
+	if vmMigrateReq.Status.TaskID == "" {
+		if err := r.checkIsTargetValid(ctx); err != nil {
+			return ctrl.Result{}, err
+		}
+
+		if err := r.startMigration(ctx); err != nil {
+			r.Recorder.EmitEvent(vmMigrateReq, "Migrate", err, true)
+			return ctrl.Result{}, err
+		}
+
+		return ctrl.Result{RequeueAfter: requeueInterval}, nil
+	}
+
+	if err := r.checkMigrationStatus(ctx); err != nil {
+		return ctrl.Result{}, err
+	}
+
+	if r.checkIsComplete(ctx) {
+		return r.removeVMMigrateResourceFromCluster(ctx)
+	}
+
+	if conditions.GetReason(vmMigrateReq, vmopv1.VirtualMachineMigrationRequestConditionMigrated) ==
+		vmopv1.MigrationFailureReason {
+		return ctrl.Result{}, nil
+	}
+
+	return ctrl.Result{RequeueAfter: requeueInterval}, nil
+}
+
+// getSourceVM gets the VM that is migrated. The VM must have been created on vSphere.
+func (r *Reconciler) getSourceVM(ctx *context.VirtualMachineMigrationRequestContext) error {
+	vmMigrateReq := ctx.VMMigrationRequest
+
+	vm := &vmopv1.VirtualMachine{}
+	objKey := client.ObjectKey{Name: vmMigrateReq.Spec.VMName, Namespace: vmMigrateReq.Namespace}
+	if err := r.Get(ctx, objKey, vm); err != nil {
+		ctx.Logger.Error(err, "failed to get VirtualMachine", "vm", objKey)
+		if apiErrors.IsNotFound(err) {
+			conditions.MarkFalse(vmMigrateReq,
+				vmopv1.VirtualMachineMigrationRequestConditionMigrated,
+				vmopv1.SourceVirtualMachineNotExistReason,
+				vmopv1.ConditionSeverityError, err.Error())
+		}
+		return err
+	}
+
+	if vm.Status.UniqueID == "" {
+		err := fmt.Errorf("VirtualMachine %s has not been created", objKey)
+		conditions.MarkFalse(vmMigrateReq,
+			vmopv1.VirtualMachineMigrationRequestConditionMigrated,
+			vmopv1.SourceVirtualMachineNotCreatedReason,
+			vmopv1.ConditionSeverityError, err.Error())
+		return err
+	}
+
+	ctx.VM = vm
+	return nil
+}
+
+// checkIsTargetValid checks if the target is valid. It is invalid if the zone is not available to
+// the namespace, or the StorageClass doesn't exist or the VM has PVC volumes. The host is validated
+// when the migration starts.
+func (r *Reconciler) checkIsTargetValid(ctx *context.VirtualMachineMigrationRequestContext) error {
+	vmMigrateReq := ctx.VMMigrationRequest
+	target := vmMigrateReq.Spec.Target
+
+	if target.Zone != "" {
+		if _, _, err := topology.GetNamespaceFolderAndRPMoID(ctx, r.Client, target.Zone, vmMigrateReq.Namespace); err != nil {
+			ctx.Logger.Error(err, "failed to get target zone", "zone", target.Zone)
+			conditions.MarkFalse(vmMigrateReq,
+				vmopv1.VirtualMachineMigrationRequestConditionTargetValid,
+				vmopv1.TargetZoneNotExistReason,
+				vmopv1.ConditionSeverityError, err.Error())
+			return err
+		}
+	}
+
+	if target.StorageClass != "" {
+		sc := &storagev1.StorageClass{}
+		if err := r.Get(ctx, client.ObjectKey{Name: target.StorageClass}, sc); err != nil {
+			ctx.Logger.Error(err, "failed to get target StorageClass", "storageClass", target.StorageClass)
+			conditions.MarkFalse(vmMigrateReq,
+				vmopv1.VirtualMachineMigrationRequestConditionTargetValid,
+				vmopv1.TargetStorageClassNotExistReason,
+				vmopv1.ConditionSeverityError, err.Error())
+			return err
+		}
+
+		// The PVC volumes are not relocated with the VM so they would be left on the datastores of
+		// the StorageClass that the VM is migrated from.
+		if hasPVCVolumes(ctx.VM) {
+			err := fmt.Errorf("VirtualMachine %s has PersistentVolumeClaim volumes that cannot be migrated to StorageClass %s",
+				ctx.VM.Name, target.StorageClass)
+			conditions.MarkFalse(vmMigrateReq,
+				vmopv1.VirtualMachineMigrationRequestConditionTargetValid,
+				vmopv1.TargetStorageClassVolumesReason,
+				vmopv1.ConditionSeverityError, err.Error())
+			return err
+		}
+	}
+
+	conditions.MarkTrue(vmMigrateReq, vmopv1.VirtualMachineMigrationRequestConditionTargetValid)
+	return nil
+}
+
+func hasPVCVolumes(vm *vmopv1.VirtualMachine) bool {
+	for _, vol := range vm.Spec.Volumes {
+		if vol.PersistentVolumeClaim != nil {
+			return true
+		}
+	}
+	return false
+}
+
+// startMigration records where the VM is and starts relocating it to the target.
+func (r *Reconciler) startMigration(ctx *context.VirtualMachineMigrationRequestContext) error {
+	vmMigrateReq := ctx.VMMigrationRequest
+	vm := ctx.VM
+
+	source := vmopv1.VirtualMachineMigrationRequestLocation{
+		Zone:         vm.Labels[topology.KubernetesTopologyZoneLabelKey],
+		Host:         vm.Status.Host,
+		StorageClass: vm.Spec.StorageClass,
+	}
+
+	taskID, err := r.VMProvider.MigrateVirtualMachine(ctx, vm, vmMigrateReq.Spec.Target)
+	if err != nil {
+		ctx.Logger.Error(err, "failed to start migration")
+		conditions.MarkFalse(vmMigrateReq,
+			vmopv1.VirtualMachineMigrationRequestConditionMigrated,
+			vmopv1.MigrationNotStartedReason,
+			vmopv1.ConditionSeverityWarning, err.Error())
+		return errors.Wrapf(err, "failed to migrate VirtualMachine %s", vm.Name)
+	}
+
+	ctx.Logger.Info("Started migrating VirtualMachine", "taskID", taskID)
+	vmMigrateReq.Status.Source = source
+	vmMigrateReq.Status.TaskID = taskID
+	vmMigrateReq.Status.Progress = 0
+	conditions.MarkFalse(vmMigrateReq,
+		vmopv1.VirtualMachineMigrationRequestConditionMigrated,
+		vmopv1.MigratingReason,
+		vmopv1.ConditionSeverityInfo,
+		"migration started")
+
+	return nil
+}
+
+// checkMigrationStatus updates the progress and the Migrated condition from the relocation task.
+// Once the task succeeds, the VirtualMachine is updated with its new zone and StorageClass.
+func (r *Reconciler) checkMigrationStatus(ctx *context.VirtualMachineMigrationRequestContext) error {
+	vmMigrateReq := ctx.VMMigrationRequest
+	if conditions.IsTrue(vmMigrateReq, vmopv1.VirtualMachineMigrationRequestConditionMigrated) {
+		return nil
+	}
+
+	task, err := r.VMProvider.GetTaskInfo(ctx, ctx.VM, vmMigrateReq.Status.TaskID)
+	if err != nil {
+		ctx.Logger.Error(err, "failed to get migration task", "taskID", vmMigrateReq.Status.TaskID)
+		return err
+	}
+
+	switch task.State {
+	case vimtypes.TaskInfoStateQueued, vimtypes.TaskInfoStateRunning:
+		vmMigrateReq.Status.Progress = task.Progress
+		conditions.MarkFalse(vmMigrateReq,
+			vmopv1.VirtualMachineMigrationRequestConditionMigrated,
+			vmopv1.MigratingReason,
+			vmopv1.ConditionSeverityInfo,
+			"migrated %d%%", task.Progress)
+
+	case vimtypes.TaskInfoStateSuccess:
+		if err := r.updateMigratedVM(ctx); err != nil {
+			return err
+		}
+		vmMigrateReq.Status.Progress = 100
+		conditions.MarkTrue(vmMigrateReq, vmopv1.VirtualMachineMigrationRequestConditionMigrated)
+		ctx.Logger.Info("VirtualMachine migrated", "target", vmMigrateReq.Spec.Target)
+
+	case vimtypes.TaskInfoStateError:
+		msg := "migration task failed"
+		if task.Error != nil {
+			msg = task.Error.LocalizedMessage
+		}
+		conditions.MarkFalse(vmMigrateReq,
+			vmopv1.VirtualMachineMigrationRequestConditionMigrated,
+			vmopv1.MigrationFailureReason,
+			vmopv1.ConditionSeverityError, "%s", msg)
+		r.Recorder.Warnf(vmMigrateReq, "MigrationFailed", "failed to migrate VirtualMachine %s: %s",
+			vmMigrateReq.Spec.VMName, msg)
+	}
+
+	return nil
+}
+
+// updateMigratedVM updates the zone label and StorageClass of the migrated VirtualMachine, and its
+// zone status. The updated VirtualMachine is reconciled, which refreshes the rest of its status,
+// including the status of its volumes.
+func (r *Reconciler) updateMigratedVM(ctx *context.VirtualMachineMigrationRequestContext) error {
+	vm := ctx.VM
+	target := ctx.VMMigrationRequest.Spec.Target
+
+	patchHelper, err := patch.NewHelper(vm, r.Client)
+	if err != nil {
+		return errors.Wrapf(err, "failed to init patch helper for VirtualMachine %s", vm.Name)
+	}
+
+	if target.Zone != "" {
+		if vm.Labels == nil {
+			vm.Labels = map[string]string{}
+		}
+		vm.Labels[topology.KubernetesTopologyZoneLabelKey] = target.Zone
+		vm.Status.Zone = target.Zone
+	}
+
+	if target.StorageClass != "" {
+		vm.Spec.StorageClass = target.StorageClass
+	}
+
+	if err := patchHelper.Patch(ctx, vm); err != nil {
+		ctx.Logger.Error(err, "failed to update migrated VirtualMachine")
+		return err
+	}
+
+	return nil
+}
+
+// checkIsComplete checks if condition Complete can be marked to true.
+// The condition's status is set to true only when all other conditions present on the resource have a truthy status.
+func (r *Reconciler) checkIsComplete(ctx *context.VirtualMachineMigrationRequestContext) bool {
+	vmMigrateReq := ctx.VMMigrationRequest
+
+	if !conditions.IsTrue(vmMigrateReq, vmopv1.VirtualMachineMigrationRequestConditionMigrated) {
+		conditions.MarkFalse(vmMigrateReq,
+			vmopv1.VirtualMachineMigrationRequestConditionComplete,
+			vmopv1.HasNotBeenMigratedReason,
+			vmopv1.ConditionSeverityWarning,
+			"VirtualMachine hasn't been migrated yet")
+		return false
+	}
+
+	conditions.MarkTrue(vmMigrateReq, vmopv1.VirtualMachineMigrationRequestConditionComplete)
+	vmMigrateReq.Status.Ready = true
+	vmMigrateReq.Status.CompletionTime = metav1.Now()
+	ctx.Logger.Info("VM migration request completed", "time", vmMigrateReq.Status.CompletionTime)
+
+	return true
+}
+
+// removeVMMigrateResourceFromCluster deletes the completed request once its spec.ttlSecondsAfterFinished elapses.
+func (r *Reconciler) removeVMMigrateResourceFromCluster(ctx *context.VirtualMachineMigrationRequestContext) (ctrl.Result, error) {
+	vmMigrateReq := ctx.VMMigrationRequest
+	ttlSecondsAfterFinished := vmMigrateReq.Spec.TTLSecondsAfterFinished
+	if ttlSecondsAfterFinished == nil {
+		return ctrl.Result{}, nil
+	}
+
+	if *ttlSecondsAfterFinished > 0 {
+		targetTime := vmMigrateReq.Status.CompletionTime.Add(time.Duration(*ttlSecondsAfterFinished) * time.Second)
+		if requeueAfter := time.Until(targetTime); requeueAfter > 0 {
+			return ctrl.Result{RequeueAfter: requeueAfter}, nil
+		}
+	}
+
+	ctx.Logger.Info("deleting VM Migration Request")
+	if err := r.Delete(ctx, vmMigrateReq); err != nil {
+		ctx.Logger.Error(err, "failed to delete VM migration request")
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+
+	return ctrl.Result{}, nil
+}
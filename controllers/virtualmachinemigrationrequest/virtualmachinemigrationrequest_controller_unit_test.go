@@ -0,0 +1,327 @@
+// Copyright (c) 2023 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package virtualmachinemigrationrequest_test
+
+import (
+	goctx "context"
+	"errors"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	apiErrors "k8s.io/apimachinery/pkg/api/errors"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	topologyv1 "github.com/vmware-tanzu/vm-operator/external/tanzu-topology/api/v1alpha1"
+	vimtypes "github.com/vmware/govmomi/vim25/types"
+
+	vmopv1 "github.com/vmware-tanzu/vm-operator/api/v1alpha1"
+
+	"github.com/vmware-tanzu/vm-operator/controllers/virtualmachinemigrationrequest"
+	"github.com/vmware-tanzu/vm-operator/pkg/conditions"
+	vmopContext "github.com/vmware-tanzu/vm-operator/pkg/context"
+	"github.com/vmware-tanzu/vm-operator/pkg/lib"
+	"github.com/vmware-tanzu/vm-operator/pkg/topology"
+	providerfake "github.com/vmware-tanzu/vm-operator/pkg/vmprovider/fake"
+	"github.com/vmware-tanzu/vm-operator/test/builder"
+)
+
+func unitTests() {
+	Describe("Invoking VirtualMachineMigrationRequest Reconcile", unitTestsReconcile)
+}
+
+func unitTestsReconcile() {
+	const (
+		sourceZone = "zone-a"
+		taskID     = "task-42"
+	)
+
+	var (
+		initObjects []client.Object
+		ctx         *builder.UnitTestContextForController
+
+		reconciler     *virtualmachinemigrationrequest.Reconciler
+		fakeVMProvider *providerfake.VMProvider
+
+		vm              *vmopv1.VirtualMachine
+		az              *topologyv1.AvailabilityZone
+		vmMigrateReq    *vmopv1.VirtualMachineMigrationRequest
+		vmMigrateReqCtx *vmopContext.VirtualMachineMigrationRequestContext
+
+		oldFaultDomainsFunc func() bool
+	)
+
+	BeforeEach(func() {
+		oldFaultDomainsFunc = lib.IsWcpFaultDomainsFSSEnabled
+		lib.IsWcpFaultDomainsFSSEnabled = func() bool { return true }
+
+		vm = builder.DummyVirtualMachine()
+		vm.Name = "dummy-vm"
+		vm.Namespace = "dummy-ns"
+		vm.Labels = map[string]string{topology.KubernetesTopologyZoneLabelKey: sourceZone}
+		vm.Status.UniqueID = "vm-42"
+		vm.Status.Host = "esx-1"
+		vm.Status.Zone = sourceZone
+
+		az = builder.DummyAvailabilityZone()
+		az.Spec.Namespaces[vm.Namespace] = topologyv1.NamespaceInfo{PoolMoId: "rp-1", FolderMoId: "folder-1"}
+
+		vmMigrateReq = builder.DummyVirtualMachineMigrationRequest("dummy-migrate", vm.Namespace, vm.Name, az.Name)
+	})
+
+	JustBeforeEach(func() {
+		ctx = suite.NewUnitTestContextForController(initObjects...)
+		reconciler = virtualmachinemigrationrequest.NewReconciler(
+			ctx.Client,
+			ctx.Logger,
+			ctx.Recorder,
+			ctx.VMProvider,
+		)
+		fakeVMProvider = ctx.VMProvider.(*providerfake.VMProvider)
+		fakeVMProvider.Reset()
+
+		vmMigrateReqCtx = &vmopContext.VirtualMachineMigrationRequestContext{
+			Context:            ctx,
+			Logger:             ctx.Logger.WithName(vmMigrateReq.Name),
+			VMMigrationRequest: vmMigrateReq,
+		}
+	})
+
+	AfterEach(func() {
+		ctx.AfterEach()
+		ctx = nil
+		initObjects = nil
+		reconciler = nil
+		lib.IsWcpFaultDomainsFSSEnabled = oldFaultDomainsFunc
+	})
+
+	Context("ReconcileNormal", func() {
+		BeforeEach(func() {
+			initObjects = append(initObjects, vmMigrateReq, vm, az)
+		})
+
+		When("the migration has not started", func() {
+			It("starts the migration", func() {
+				var migratedVMName string
+				var migratedTarget vmopv1.VirtualMachineMigrationRequestLocation
+				fakeVMProvider.MigrateVirtualMachineFn = func(_ goctx.Context, vm *vmopv1.VirtualMachine,
+					target vmopv1.VirtualMachineMigrationRequestLocation) (string, error) {
+					migratedVMName, migratedTarget = vm.Name, target
+					return taskID, nil
+				}
+
+				result, err := reconciler.ReconcileNormal(vmMigrateReqCtx)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(result.RequeueAfter).ToNot(BeZero())
+
+				Expect(migratedVMName).To(Equal(vm.Name))
+				Expect(migratedTarget).To(Equal(vmMigrateReq.Spec.Target))
+
+				Expect(vmMigrateReq.Status.StartTime.IsZero()).To(BeFalse())
+				Expect(vmMigrateReq.Status.TaskID).To(Equal(taskID))
+				Expect(vmMigrateReq.Status.Source).To(Equal(vmopv1.VirtualMachineMigrationRequestLocation{
+					Zone:         sourceZone,
+					Host:         vm.Status.Host,
+					StorageClass: vm.Spec.StorageClass,
+				}))
+				Expect(conditions.IsTrue(vmMigrateReq, vmopv1.VirtualMachineMigrationRequestConditionTargetValid)).To(BeTrue())
+				Expect(conditions.GetReason(vmMigrateReq, vmopv1.VirtualMachineMigrationRequestConditionMigrated)).
+					To(Equal(vmopv1.MigratingReason))
+			})
+
+			It("marks Migrated false if the migration cannot be started", func() {
+				fakeVMProvider.MigrateVirtualMachineFn = func(_ goctx.Context, _ *vmopv1.VirtualMachine,
+					_ vmopv1.VirtualMachineMigrationRequestLocation) (string, error) {
+					return "", errors.New("host esx-9 is not in cluster")
+				}
+
+				_, err := reconciler.ReconcileNormal(vmMigrateReqCtx)
+				Expect(err).To(HaveOccurred())
+				Expect(vmMigrateReq.Status.TaskID).To(BeEmpty())
+				Expect(conditions.GetReason(vmMigrateReq, vmopv1.VirtualMachineMigrationRequestConditionMigrated)).
+					To(Equal(vmopv1.MigrationNotStartedReason))
+			})
+
+			When("the VM does not exist", func() {
+				BeforeEach(func() {
+					vmMigrateReq.Spec.VMName = "missing-vm"
+				})
+
+				It("marks Migrated false", func() {
+					_, err := reconciler.ReconcileNormal(vmMigrateReqCtx)
+					Expect(err).To(HaveOccurred())
+					Expect(conditions.GetReason(vmMigrateReq, vmopv1.VirtualMachineMigrationRequestConditionMigrated)).
+						To(Equal(vmopv1.SourceVirtualMachineNotExistReason))
+				})
+			})
+
+			When("the VM has not been created", func() {
+				BeforeEach(func() {
+					vm.Status.UniqueID = ""
+				})
+
+				It("marks Migrated false", func() {
+					_, err := reconciler.ReconcileNormal(vmMigrateReqCtx)
+					Expect(err).To(HaveOccurred())
+					Expect(conditions.GetReason(vmMigrateReq, vmopv1.VirtualMachineMigrationRequestConditionMigrated)).
+						To(Equal(vmopv1.SourceVirtualMachineNotCreatedReason))
+				})
+			})
+
+			When("the target zone is not available to the namespace", func() {
+				BeforeEach(func() {
+					delete(az.Spec.Namespaces, vm.Namespace)
+				})
+
+				It("marks TargetValid false", func() {
+					_, err := reconciler.ReconcileNormal(vmMigrateReqCtx)
+					Expect(err).To(HaveOccurred())
+					Expect(conditions.GetReason(vmMigrateReq, vmopv1.VirtualMachineMigrationRequestConditionTargetValid)).
+						To(Equal(vmopv1.TargetZoneNotExistReason))
+					Expect(vmMigrateReq.Status.TaskID).To(BeEmpty())
+				})
+			})
+
+			When("the target StorageClass does not exist", func() {
+				BeforeEach(func() {
+					vmMigrateReq.Spec.Target.StorageClass = "missing-sc"
+				})
+
+				It("marks TargetValid false", func() {
+					_, err := reconciler.ReconcileNormal(vmMigrateReqCtx)
+					Expect(err).To(HaveOccurred())
+					Expect(conditions.GetReason(vmMigrateReq, vmopv1.VirtualMachineMigrationRequestConditionTargetValid)).
+						To(Equal(vmopv1.TargetStorageClassNotExistReason))
+				})
+			})
+
+			When("the VM with PVC volumes is migrated to another StorageClass", func() {
+				BeforeEach(func() {
+					sc := builder.DummyStorageClass()
+					vmMigrateReq.Spec.Target.StorageClass = sc.Name
+					initObjects = append(initObjects, sc)
+				})
+
+				It("marks TargetValid false and does not start the migration", func() {
+					Expect(vm.Spec.Volumes).ToNot(BeEmpty())
+
+					var migrated bool
+					fakeVMProvider.MigrateVirtualMachineFn = func(_ goctx.Context, _ *vmopv1.VirtualMachine,
+						_ vmopv1.VirtualMachineMigrationRequestLocation) (string, error) {
+						migrated = true
+						return taskID, nil
+					}
+
+					_, err := reconciler.ReconcileNormal(vmMigrateReqCtx)
+					Expect(err).To(HaveOccurred())
+					Expect(conditions.GetReason(vmMigrateReq, vmopv1.VirtualMachineMigrationRequestConditionTargetValid)).
+						To(Equal(vmopv1.TargetStorageClassVolumesReason))
+					Expect(migrated).To(BeFalse())
+				})
+			})
+		})
+
+		When("the migration has started", func() {
+			var (
+				taskInfo    *vimtypes.TaskInfo
+				taskInfoErr error
+			)
+
+			BeforeEach(func() {
+				vmMigrateReq.Status.TaskID = taskID
+				conditions.MarkTrue(vmMigrateReq, vmopv1.VirtualMachineMigrationRequestConditionTargetValid)
+
+				taskInfo = nil
+				taskInfoErr = nil
+			})
+
+			JustBeforeEach(func() {
+				fakeVMProvider.GetTaskInfoFn = func(_ goctx.Context, _ *vmopv1.VirtualMachine, id string) (*vimtypes.TaskInfo, error) {
+					Expect(id).To(Equal(taskID))
+					return taskInfo, taskInfoErr
+				}
+			})
+
+			It("reports the progress", func() {
+				taskInfo = &vimtypes.TaskInfo{State: vimtypes.TaskInfoStateRunning, Progress: 40}
+
+				result, err := reconciler.ReconcileNormal(vmMigrateReqCtx)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(result.RequeueAfter).ToNot(BeZero())
+				Expect(vmMigrateReq.Status.Progress).To(BeEquivalentTo(40))
+				Expect(conditions.GetMessage(vmMigrateReq, vmopv1.VirtualMachineMigrationRequestConditionMigrated)).
+					To(Equal("migrated 40%"))
+				Expect(conditions.GetReason(vmMigrateReq, vmopv1.VirtualMachineMigrationRequestConditionComplete)).
+					To(Equal(vmopv1.HasNotBeenMigratedReason))
+			})
+
+			It("returns an error if the task cannot be retrieved", func() {
+				taskInfoErr = errors.New("get task failed")
+
+				_, err := reconciler.ReconcileNormal(vmMigrateReqCtx)
+				Expect(err).To(HaveOccurred())
+			})
+
+			When("the task succeeds", func() {
+				BeforeEach(func() {
+					taskInfo = &vimtypes.TaskInfo{State: vimtypes.TaskInfoStateSuccess}
+					vmMigrateReq.Spec.Target.StorageClass = "new-sc"
+				})
+
+				It("updates the VM and completes the request", func() {
+					result, err := reconciler.ReconcileNormal(vmMigrateReqCtx)
+					Expect(err).ToNot(HaveOccurred())
+					Expect(result.RequeueAfter).To(BeZero())
+					Expect(vmMigrateReq.Status.Progress).To(BeEquivalentTo(100))
+					Expect(vmMigrateReq.Status.Ready).To(BeTrue())
+					Expect(vmMigrateReq.Status.CompletionTime.IsZero()).To(BeFalse())
+					Expect(conditions.IsTrue(vmMigrateReq, vmopv1.VirtualMachineMigrationRequestConditionMigrated)).To(BeTrue())
+					Expect(conditions.IsTrue(vmMigrateReq, vmopv1.VirtualMachineMigrationRequestConditionComplete)).To(BeTrue())
+
+					migratedVM := &vmopv1.VirtualMachine{}
+					Expect(ctx.Client.Get(ctx, client.ObjectKeyFromObject(vm), migratedVM)).To(Succeed())
+					Expect(migratedVM.Labels).To(HaveKeyWithValue(topology.KubernetesTopologyZoneLabelKey, az.Name))
+					Expect(migratedVM.Status.Zone).To(Equal(az.Name))
+					Expect(migratedVM.Spec.StorageClass).To(Equal("new-sc"))
+				})
+
+				It("deletes the request when TTLSecondsAfterFinished is zero", func() {
+					ttl := int64(0)
+					vmMigrateReq.Spec.TTLSecondsAfterFinished = &ttl
+
+					_, err := reconciler.ReconcileNormal(vmMigrateReqCtx)
+					Expect(err).ToNot(HaveOccurred())
+
+					err = ctx.Client.Get(ctx, client.ObjectKeyFromObject(vmMigrateReq), &vmopv1.VirtualMachineMigrationRequest{})
+					Expect(apiErrors.IsNotFound(err)).To(BeTrue())
+				})
+			})
+
+			It("marks Migrated false and does not retry when the task fails", func() {
+				taskInfo = &vimtypes.TaskInfo{
+					State: vimtypes.TaskInfoStateError,
+					Error: &vimtypes.LocalizedMethodFault{LocalizedMessage: "insufficient resources"},
+				}
+
+				result, err := reconciler.ReconcileNormal(vmMigrateReqCtx)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(result.RequeueAfter).To(BeZero())
+				Expect(conditions.GetReason(vmMigrateReq, vmopv1.VirtualMachineMigrationRequestConditionMigrated)).
+					To(Equal(vmopv1.MigrationFailureReason))
+				Expect(conditions.GetMessage(vmMigrateReq, vmopv1.VirtualMachineMigrationRequestConditionMigrated)).
+					To(Equal("insufficient resources"))
+
+				By("not checking the task again", func() {
+					taskInfoErr = errors.New("should not be called")
+					_, err := reconciler.ReconcileNormal(vmMigrateReqCtx)
+					Expect(err).ToNot(HaveOccurred())
+				})
+
+				migratedVM := &vmopv1.VirtualMachine{}
+				Expect(ctx.Client.Get(ctx, client.ObjectKeyFromObject(vm), migratedVM)).To(Succeed())
+				Expect(migratedVM.Labels).To(HaveKeyWithValue(topology.KubernetesTopologyZoneLabelKey, sourceZone))
+			})
+		})
+	})
+}
@@ -9,6 +9,7 @@ import (
 	"reflect"
 	"regexp"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/go-logr/logr"
@@ -155,6 +156,9 @@ type Reconciler struct {
 	Recorder   record.Recorder
 	VMProvider vmprovider.VirtualMachineProviderInterface
 	Metrics    *metrics.VMPublishMetrics
+
+	// ociPushes maps the activation ID of a push to an OCI registry to its ociPushResult.
+	ociPushes sync.Map
 }
 
 func requeueResult(ctx *context.VirtualMachinePublishRequestContext) ctrl.Result {
@@ -196,6 +200,7 @@ func requeueResult(ctx *context.VirtualMachinePublishRequestContext) ctrl.Result
 // +kubebuilder:rbac:groups=vmoperator.vmware.com,resources=virtualmachines,verbs=get;list
 // +kubebuilder:rbac:groups=imageregistry.vmware.com,resources=contentlibraries,verbs=get;list;watch
 // +kubebuilder:rbac:groups=imageregistry.vmware.com,resources=contentlibraries/status,verbs=get;
+// +kubebuilder:rbac:groups="",resources=secrets,verbs=get
 
 func (r *Reconciler) Reconcile(ctx goctx.Context, req ctrl.Request) (_ ctrl.Result, reterr error) {
 	vmPublishReq := &vmopv1.VirtualMachinePublishRequest{}
@@ -308,8 +313,13 @@ func (r *Reconciler) publishVirtualMachine(ctx *context.VirtualMachinePublishReq
 			return err
 		}
 
+		actID := getPublishRequestActID(vmPublishReq)
+		if isOCITarget(vmPublishReq) {
+			r.pushToOCIRegistry(ctx, actID)
+			return nil
+		}
+
 		go func() {
-			actID := getPublishRequestActID(vmPublishReq)
 			itemID, pubErr := r.VMProvider.PublishVirtualMachine(ctx, ctx.VM, vmPublishReq, ctx.ContentLibrary, actID)
 			if pubErr != nil {
 				ctx.Logger.Error(pubErr, "failed to publish VM")
@@ -388,6 +398,10 @@ func (r *Reconciler) checkIsSourceValid(ctx *context.VirtualMachinePublishReques
 // It is invalid if the content library doesn't exist, an item with the same name in the CL exists.
 func (r *Reconciler) checkIsTargetValid(ctx *context.VirtualMachinePublishRequestContext) error {
 	vmPubReq := ctx.VMPublishRequest
+	if isOCITarget(vmPubReq) {
+		return r.checkIsOCITargetValid(ctx)
+	}
+
 	contentLibrary := &imgregv1a1.ContentLibrary{}
 	targetLocationName := vmPubReq.Spec.Target.Location.Name
 	targetItemName := vmPubReq.Status.TargetRef.Item.Name
@@ -480,6 +494,10 @@ func (r *Reconciler) checkIsImageAvailable(ctx *context.VirtualMachinePublishReq
 		return nil
 	}
 
+	if isOCITarget(ctx.VMPublishRequest) {
+		return r.checkIsOCIArtifactAvailable(ctx)
+	}
+
 	if ctx.ItemID == "" {
 		id, err := r.getUploadedItemID(ctx)
 		if err != nil {
@@ -597,6 +615,10 @@ func (r *Reconciler) checkPubReqStatusAndShouldRepublish(ctx *context.VirtualMac
 		return false, nil
 	}
 
+	if isOCITarget(ctx.VMPublishRequest) {
+		return r.checkOCIPushStatusAndShouldRepublish(ctx), nil
+	}
+
 	actID := getPublishRequestActID(ctx.VMPublishRequest)
 	logger := ctx.Logger.WithValues("actID", actID, "descriptionID", TaskDescriptionID)
 
@@ -780,6 +802,11 @@ func (r *Reconciler) updatePublishedItemDescription(ctx *context.VirtualMachineP
 		return nil
 	}
 
+	// The pushed OCI artifact does not carry the vmPub UUID in its description.
+	if isOCITarget(ctx.VMPublishRequest) {
+		return nil
+	}
+
 	if ctx.ItemID == "" {
 		id, err := r.getUploadedItemID(ctx)
 		if err != nil {
@@ -912,6 +939,7 @@ func (r *Reconciler) ReconcileNormal(ctx *context.VirtualMachinePublishRequestCo
 
 func (r *Reconciler) ReconcileDelete(ctx *context.VirtualMachinePublishRequestContext) (ctrl.Result, error) {
 	if controllerutil.ContainsFinalizer(ctx.VMPublishRequest, finalizerName) {
+		r.cancelOCIPushes(ctx.VMPublishRequest)
 		r.Metrics.DeleteMetrics(ctx.Logger, ctx.VMPublishRequest.Name, ctx.VMPublishRequest.Namespace)
 		controllerutil.RemoveFinalizer(ctx.VMPublishRequest, finalizerName)
 	}
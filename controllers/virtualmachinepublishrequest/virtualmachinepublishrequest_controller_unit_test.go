@@ -6,6 +6,8 @@ package virtualmachinepublishrequest_test
 import (
 	goctx "context"
 	"fmt"
+	"net/http/httptest"
+	"strings"
 	"time"
 
 	corev1 "k8s.io/api/core/v1"
@@ -29,6 +31,8 @@ import (
 	"github.com/vmware-tanzu/vm-operator/controllers/virtualmachinepublishrequest"
 	"github.com/vmware-tanzu/vm-operator/pkg/conditions"
 	vmopContext "github.com/vmware-tanzu/vm-operator/pkg/context"
+	"github.com/vmware-tanzu/vm-operator/pkg/ociregistry"
+	ociregistryfake "github.com/vmware-tanzu/vm-operator/pkg/ociregistry/fake"
 	providerfake "github.com/vmware-tanzu/vm-operator/pkg/vmprovider/fake"
 	"github.com/vmware-tanzu/vm-operator/test/builder"
 )
@@ -678,5 +682,259 @@ func unitTestsReconcile() {
 				})
 			})
 		})
+
+		Context("Target is an OCI registry", func() {
+			var (
+				registry *ociregistryfake.Registry
+				server   *httptest.Server
+				secret   *corev1.Secret
+			)
+
+			pushArtifact := func(ctx goctx.Context, c *ociregistry.Client, tag, uid string) (string, error) {
+				layer, err := c.PushBlob(ctx, "application/test", strings.NewReader("disk"))
+				if err != nil {
+					return "", err
+				}
+				config, err := c.PushBlob(ctx, "application/test+json", strings.NewReader("{}"))
+				if err != nil {
+					return "", err
+				}
+				desc, err := c.PushManifest(ctx, tag, ociregistry.Manifest{
+					SchemaVersion: 2,
+					Config:        config,
+					Layers:        []ociregistry.Descriptor{layer},
+					Annotations:   map[string]string{ociregistry.AnnotationPublishRequestUID: uid},
+				})
+				return desc.Digest, err
+			}
+
+			getReason := func(condition vmopv1.ConditionType) string {
+				return conditions.GetReason(vmpub, condition)
+			}
+
+			BeforeEach(func() {
+				registry = ociregistryfake.NewRegistry()
+				registry.Username = "user"
+				registry.Password = "pass"
+				server = httptest.NewTLSServer(registry)
+				host := strings.TrimPrefix(server.URL, "https://")
+
+				vmpub.UID = "dummy-uid"
+				vmpub.Spec.Target.Location = vmopv1.VirtualMachinePublishRequestTargetLocation{
+					OCI: &vmopv1.VirtualMachinePublishRequestTargetOCILocation{
+						Repository:            host + "/vms/dummy",
+						SecretName:            "dummy-secret",
+						InsecureSkipTLSVerify: true,
+					},
+				}
+
+				secret = &corev1.Secret{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "dummy-secret",
+						Namespace: vmpub.Namespace,
+					},
+					Type: corev1.SecretTypeDockerConfigJson,
+					Data: map[string][]byte{
+						corev1.DockerConfigJsonKey: []byte(fmt.Sprintf(
+							`{"auths":{%q:{"username":"user","password":"pass"}}}`, host)),
+					},
+				}
+
+				initObjects = []client.Object{vm, vmpub}
+			})
+
+			AfterEach(func() {
+				server.Close()
+			})
+
+			When("Secret doesn't exist", func() {
+				It("returns error and sets TargetValid to false", func() {
+					_, err := reconciler.ReconcileNormal(vmpubCtx)
+					Expect(err).To(HaveOccurred())
+					Expect(getReason(vmopv1.VirtualMachinePublishRequestConditionTargetValid)).
+						To(Equal(vmopv1.TargetRegistrySecretNotExistReason))
+				})
+			})
+
+			When("Secret is invalid", func() {
+				BeforeEach(func() {
+					secret.Type = corev1.SecretTypeOpaque
+					initObjects = append(initObjects, secret)
+				})
+
+				It("returns error and sets TargetValid to false", func() {
+					_, err := reconciler.ReconcileNormal(vmpubCtx)
+					Expect(err).To(HaveOccurred())
+					Expect(getReason(vmopv1.VirtualMachinePublishRequestConditionTargetValid)).
+						To(Equal(vmopv1.TargetRegistrySecretInvalidReason))
+				})
+			})
+
+			When("Secret has the wrong credentials", func() {
+				BeforeEach(func() {
+					registry.Password = "other"
+					initObjects = append(initObjects, secret)
+				})
+
+				It("returns error and sets TargetValid to false", func() {
+					_, err := reconciler.ReconcileNormal(vmpubCtx)
+					Expect(err).To(HaveOccurred())
+					Expect(getReason(vmopv1.VirtualMachinePublishRequestConditionTargetValid)).
+						To(Equal(vmopv1.TargetRegistrySecretInvalidReason))
+				})
+			})
+
+			When("Secret is valid", func() {
+				BeforeEach(func() {
+					initObjects = append(initObjects, secret)
+				})
+
+				JustBeforeEach(func() {
+					fakeVMProvider.PublishVirtualMachineToOCIRegistryFn = func(ctx goctx.Context, vm *vmopv1.VirtualMachine,
+						vmPub *vmopv1.VirtualMachinePublishRequest, c *ociregistry.Client) (string, error) {
+						return pushArtifact(ctx, c, vmPub.Status.TargetRef.Item.Name, string(vmPub.UID))
+					}
+				})
+
+				It("pushes the VM and eventually sets Complete to true", func() {
+					_, err := reconciler.ReconcileNormal(vmpubCtx)
+					Expect(err).NotTo(HaveOccurred())
+					Expect(vmpub.Status.Attempts).To(BeEquivalentTo(1))
+
+					Eventually(func() bool {
+						_, err := reconciler.ReconcileNormal(vmpubCtx)
+						Expect(err).NotTo(HaveOccurred())
+						return conditions.IsTrue(vmpub, vmopv1.VirtualMachinePublishRequestConditionComplete)
+					}).Should(BeTrue())
+
+					Expect(conditions.IsTrue(vmpub,
+						vmopv1.VirtualMachinePublishRequestConditionUploaded)).To(BeTrue())
+					Expect(conditions.IsTrue(vmpub,
+						vmopv1.VirtualMachinePublishRequestConditionImageAvailable)).To(BeTrue())
+					Expect(vmpub.Status.OCIArtifact).ToNot(BeNil())
+					Expect(vmpub.Status.OCIArtifact.Reference).To(Equal(
+						vmpub.Spec.Target.Location.OCI.Repository + ":dummy-item"))
+					_, ok := registry.Manifest("vms/dummy", "dummy-item")
+					Expect(ok).To(BeTrue())
+					Expect(vmpub.Status.Ready).To(BeTrue())
+				})
+
+				It("persists the result of the push in the status", func() {
+					_, err := reconciler.ReconcileNormal(vmpubCtx)
+					Expect(err).NotTo(HaveOccurred())
+
+					Eventually(func() bool {
+						obj := &vmopv1.VirtualMachinePublishRequest{}
+						Expect(ctx.Client.Get(ctx, client.ObjectKeyFromObject(vmpub), obj)).To(Succeed())
+						return conditions.IsTrue(obj, vmopv1.VirtualMachinePublishRequestConditionUploaded) &&
+							obj.Status.OCIArtifact != nil
+					}).Should(BeTrue())
+				})
+
+				When("push fails", func() {
+					JustBeforeEach(func() {
+						fakeVMProvider.PublishVirtualMachineToOCIRegistryFn = func(ctx goctx.Context, vm *vmopv1.VirtualMachine,
+							vmPub *vmopv1.VirtualMachinePublishRequest, c *ociregistry.Client) (string, error) {
+							return "", fmt.Errorf("dummy error")
+						}
+					})
+
+					It("sets Uploaded to false and retries the push", func() {
+						_, err := reconciler.ReconcileNormal(vmpubCtx)
+						Expect(err).NotTo(HaveOccurred())
+
+						Eventually(func() int64 {
+							// The failure is persisted in the status by the push, so get the latest object
+							// like Reconcile does.
+							Expect(ctx.Client.Get(ctx, client.ObjectKeyFromObject(vmpub), vmpub)).To(Succeed())
+							_, err := reconciler.ReconcileNormal(vmpubCtx)
+							Expect(err).NotTo(HaveOccurred())
+							return vmpub.Status.Attempts
+						}).Should(BeNumerically(">", 1))
+						Expect(getReason(vmopv1.VirtualMachinePublishRequestConditionUploaded)).
+							To(Equal(vmopv1.UploadFailureReason))
+					})
+				})
+
+				When("the request is deleted while the push is running", func() {
+					var pushErr chan error
+
+					JustBeforeEach(func() {
+						pushErr = make(chan error, 1)
+						fakeVMProvider.PublishVirtualMachineToOCIRegistryFn = func(ctx goctx.Context, vm *vmopv1.VirtualMachine,
+							vmPub *vmopv1.VirtualMachinePublishRequest, c *ociregistry.Client) (string, error) {
+							<-ctx.Done()
+							pushErr <- ctx.Err()
+							return "", ctx.Err()
+						}
+					})
+
+					It("cancels the push", func() {
+						_, err := reconciler.ReconcileNormal(vmpubCtx)
+						Expect(err).NotTo(HaveOccurred())
+
+						_, err = reconciler.ReconcileDelete(vmpubCtx)
+						Expect(err).NotTo(HaveOccurred())
+						Eventually(pushErr).Should(Receive(MatchError(goctx.Canceled)))
+					})
+				})
+
+				When("artifact with the same tag already exists", func() {
+					var digest string
+
+					JustBeforeEach(func() {
+						c := ociregistry.NewClient(ociregistry.Repository{
+							Registry: strings.TrimPrefix(server.URL, "https://"),
+							Name:     "vms/dummy",
+						}, ociregistry.Options{
+							Credentials:           &ociregistry.Credentials{Username: "user", Password: "pass"},
+							InsecureSkipTLSVerify: true,
+						})
+						var err error
+						digest, err = pushArtifact(ctx, c, "dummy-item", "other-uid")
+						Expect(err).NotTo(HaveOccurred())
+					})
+
+					It("doesn't return error to skip requeue", func() {
+						_, err := reconciler.ReconcileNormal(vmpubCtx)
+						Expect(err).NotTo(HaveOccurred())
+						Expect(getReason(vmopv1.VirtualMachinePublishRequestConditionTargetValid)).
+							To(Equal(vmopv1.TargetItemAlreadyExistsReason))
+						Expect(fakeVMProvider.IsPublishVMCalled()).To(BeFalse())
+					})
+
+					When("it was pushed by this request in a prior attempt", func() {
+						BeforeEach(func() {
+							vmpub.Status.Attempts = 1
+							vmpub.Status.LastAttemptTime = metav1.NewTime(time.Now().Add(-time.Minute))
+						})
+
+						JustBeforeEach(func() {
+							c := ociregistry.NewClient(ociregistry.Repository{
+								Registry: strings.TrimPrefix(server.URL, "https://"),
+								Name:     "vms/dummy",
+							}, ociregistry.Options{
+								Credentials:           &ociregistry.Credentials{Username: "user", Password: "pass"},
+								InsecureSkipTLSVerify: true,
+							})
+							var err error
+							digest, err = pushArtifact(ctx, c, "dummy-item", string(vmpub.UID))
+							Expect(err).NotTo(HaveOccurred())
+						})
+
+						It("marks Uploaded to true without publishing again", func() {
+							_, err := reconciler.ReconcileNormal(vmpubCtx)
+							Expect(err).NotTo(HaveOccurred())
+
+							Expect(fakeVMProvider.IsPublishVMCalled()).To(BeFalse())
+							Expect(conditions.IsTrue(vmpub,
+								vmopv1.VirtualMachinePublishRequestConditionUploaded)).To(BeTrue())
+							Expect(vmpub.Status.OCIArtifact).ToNot(BeNil())
+							Expect(vmpub.Status.OCIArtifact.Digest).To(Equal(digest))
+						})
+					})
+				})
+			})
+		})
 	})
 }
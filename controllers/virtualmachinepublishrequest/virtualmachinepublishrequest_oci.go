@@ -0,0 +1,342 @@
+// Copyright (c) 2023 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package virtualmachinepublishrequest
+
+import (
+	goctx "context"
+	"fmt"
+	"time"
+
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	apiErrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	vmopv1 "github.com/vmware-tanzu/vm-operator/api/v1alpha1"
+
+	"github.com/vmware-tanzu/vm-operator/pkg/conditions"
+	"github.com/vmware-tanzu/vm-operator/pkg/context"
+	"github.com/vmware-tanzu/vm-operator/pkg/ociregistry"
+)
+
+// ociPushTimeout is the maximum duration of a push to an OCI registry. A push
+// that takes longer is canceled and fails, and the VM is published again.
+const ociPushTimeout = 2 * time.Hour
+
+// ociPushResult is the in-memory state of a push to an OCI registry. Unlike
+// publishing to a content library, there is no vCenter task tracking the
+// whole push, so the controller records the result itself.
+type ociPushResult struct {
+	// requestUID is the UID of the VirtualMachinePublishRequest of the push.
+	requestUID types.UID
+	// cancel cancels the push while it is running.
+	cancel goctx.CancelFunc
+
+	done   bool
+	digest string
+	err    error
+}
+
+func isOCITarget(vmPubReq *vmopv1.VirtualMachinePublishRequest) bool {
+	return vmPubReq.Spec.Target.Location.OCI != nil
+}
+
+// getOCIRegistryClient returns a client for the target repository using the
+// credentials from the target's Secret, if any.
+func (r *Reconciler) getOCIRegistryClient(ctx *context.VirtualMachinePublishRequestContext) (*ociregistry.Client, error) {
+	if ctx.OCIRegistry != nil {
+		return ctx.OCIRegistry, nil
+	}
+
+	vmPubReq := ctx.VMPublishRequest
+	oci := vmPubReq.Spec.Target.Location.OCI
+	repo, err := ociregistry.ParseRepository(oci.Repository)
+	if err != nil {
+		return nil, err
+	}
+
+	var creds *ociregistry.Credentials
+	if oci.SecretName != "" {
+		secret := &corev1.Secret{}
+		objKey := client.ObjectKey{Name: oci.SecretName, Namespace: vmPubReq.Namespace}
+		if err := r.Get(ctx, objKey, secret); err != nil {
+			ctx.Logger.Error(err, "failed to get registry Secret", "secret", objKey)
+			if apiErrors.IsNotFound(err) {
+				conditions.MarkFalse(vmPubReq,
+					vmopv1.VirtualMachinePublishRequestConditionTargetValid,
+					vmopv1.TargetRegistrySecretNotExistReason,
+					vmopv1.ConditionSeverityError, err.Error())
+			}
+			return nil, err
+		}
+
+		creds, err = credentialsFromSecret(secret, repo.Registry)
+		if err != nil {
+			conditions.MarkFalse(vmPubReq,
+				vmopv1.VirtualMachinePublishRequestConditionTargetValid,
+				vmopv1.TargetRegistrySecretInvalidReason,
+				vmopv1.ConditionSeverityError, err.Error())
+			return nil, err
+		}
+	}
+
+	ctx.OCIRegistry = ociregistry.NewClient(repo, ociregistry.Options{
+		Credentials:           creds,
+		InsecureSkipTLSVerify: oci.InsecureSkipTLSVerify,
+	})
+	return ctx.OCIRegistry, nil
+}
+
+func credentialsFromSecret(secret *corev1.Secret, registry string) (*ociregistry.Credentials, error) {
+	if secret.Type != corev1.SecretTypeDockerConfigJson {
+		return nil, fmt.Errorf("secret %s has type %q, expected %q", secret.Name, secret.Type, corev1.SecretTypeDockerConfigJson)
+	}
+
+	data, ok := secret.Data[corev1.DockerConfigJsonKey]
+	if !ok {
+		return nil, fmt.Errorf("secret %s is missing the %s key", secret.Name, corev1.DockerConfigJsonKey)
+	}
+
+	creds, err := ociregistry.CredentialsFromDockerConfigJSON(data, registry)
+	if err != nil {
+		return nil, fmt.Errorf("secret %s is invalid: %w", secret.Name, err)
+	}
+	if creds == nil {
+		return nil, fmt.Errorf("secret %s has no credentials for registry %s", secret.Name, registry)
+	}
+
+	return creds, nil
+}
+
+// checkIsOCITargetValid checks if the target repository is valid.
+// It is invalid if the registry Secret is invalid, the registry is not reachable, or an
+// artifact with the same tag already exists in the repository.
+func (r *Reconciler) checkIsOCITargetValid(ctx *context.VirtualMachinePublishRequestContext) error {
+	vmPubReq := ctx.VMPublishRequest
+	targetItemName := vmPubReq.Status.TargetRef.Item.Name
+
+	registry, err := r.getOCIRegistryClient(ctx)
+	if err != nil {
+		return err
+	}
+
+	manifest, digest, err := registry.GetManifest(ctx, targetItemName)
+	if err != nil {
+		ctx.Logger.Error(err, "failed to get manifest", "repository", registry.Repository().String())
+		reason := vmopv1.TargetRegistryNotReachableReason
+		if ociregistry.IsUnauthorized(err) {
+			reason = vmopv1.TargetRegistrySecretInvalidReason
+		}
+		conditions.MarkFalse(vmPubReq,
+			vmopv1.VirtualMachinePublishRequestConditionTargetValid,
+			reason,
+			vmopv1.ConditionSeverityError, err.Error())
+		return err
+	}
+
+	if manifest != nil {
+		reference := registry.Repository().Reference(targetItemName)
+		ctx.Logger.Info("target artifact already exists in the registry", "reference", reference)
+		// Similar to content library items, if a prior attempt pushed this artifact but the
+		// result was lost, e.g. due to a restart, the manifest annotation links it to this request.
+		if vmPubReq.Status.Attempts > 0 &&
+			manifest.Annotations[ociregistry.AnnotationPublishRequestUID] == string(vmPubReq.UID) {
+			ctx.Logger.Info("existing target artifact is published by this VMPubReq")
+			conditions.MarkTrue(vmPubReq, vmopv1.VirtualMachinePublishRequestConditionTargetValid)
+			markOCIArtifactUploaded(vmPubReq, digest)
+			return nil
+		}
+
+		conditions.MarkFalse(vmPubReq,
+			vmopv1.VirtualMachinePublishRequestConditionTargetValid,
+			vmopv1.TargetItemAlreadyExistsReason,
+			vmopv1.ConditionSeverityError,
+			fmt.Sprintf("artifact %s already exists in the registry", reference))
+		return nil
+	}
+
+	conditions.MarkTrue(vmPubReq, vmopv1.VirtualMachinePublishRequestConditionTargetValid)
+	return nil
+}
+
+// pushToOCIRegistry pushes the source VM to the target registry in the background and
+// records the result under the activation ID. The push outlives the reconcile, so it uses
+// copies of the VM and the VirtualMachinePublishRequest, and its result is also persisted
+// in the status of the VirtualMachinePublishRequest. The push is canceled after
+// ociPushTimeout, or when the VirtualMachinePublishRequest is deleted.
+func (r *Reconciler) pushToOCIRegistry(ctx *context.VirtualMachinePublishRequestContext, actID string) {
+	vm := ctx.VM.DeepCopy()
+	vmPublishReq := ctx.VMPublishRequest.DeepCopy()
+	registry := ctx.OCIRegistry
+	logger := ctx.Logger.WithValues("actID", actID)
+
+	pushCtx, cancel := goctx.WithTimeout(goctx.Background(), ociPushTimeout)
+	r.ociPushes.Store(actID, ociPushResult{requestUID: vmPublishReq.UID, cancel: cancel})
+
+	go func() {
+		defer cancel()
+
+		digest, pubErr := r.VMProvider.PublishVirtualMachineToOCIRegistry(pushCtx, vm, vmPublishReq, registry)
+		if errors.Is(pushCtx.Err(), goctx.Canceled) {
+			// The VirtualMachinePublishRequest is deleted, so there is nobody left to
+			// check the result.
+			logger.Info("push to OCI registry is canceled")
+			r.ociPushes.Delete(actID)
+			return
+		}
+
+		if pubErr != nil {
+			logger.Error(pubErr, "failed to publish VM to OCI registry")
+		} else {
+			logger.Info("pushed VM to OCI registry", "digest", digest)
+		}
+
+		current, err := r.recordOCIPushResult(vmPublishReq, actID, digest, pubErr)
+		if err != nil {
+			logger.Error(err, "failed to record the result of the push to OCI registry")
+		}
+		if current {
+			r.ociPushes.Store(actID, ociPushResult{requestUID: vmPublishReq.UID, done: true, digest: digest, err: pubErr})
+		} else {
+			r.ociPushes.Delete(actID)
+		}
+		r.Recorder.EmitEvent(vmPublishReq, "Publish", pubErr, false)
+	}()
+}
+
+// cancelOCIPushes cancels the pushes to OCI registries of the VirtualMachinePublishRequest
+// and forgets their results.
+func (r *Reconciler) cancelOCIPushes(vmPubReq *vmopv1.VirtualMachinePublishRequest) {
+	r.ociPushes.Range(func(key, value interface{}) bool {
+		if result := value.(ociPushResult); result.requestUID == vmPubReq.UID {
+			if result.cancel != nil {
+				result.cancel()
+			}
+			r.ociPushes.Delete(key)
+		}
+		return true
+	})
+}
+
+// recordOCIPushResult persists the result of the push in the status of the latest
+// VirtualMachinePublishRequest, so the result is not lost if the controller restarts
+// before the push is checked. The result is skipped, and false is returned, if the
+// VirtualMachinePublishRequest no longer exists or the push is no longer the current
+// attempt.
+func (r *Reconciler) recordOCIPushResult(
+	vmPubReq *vmopv1.VirtualMachinePublishRequest,
+	actID, digest string,
+	pushErr error) (bool, error) {
+
+	// The push context may have expired, so the result is recorded with its own deadline.
+	ctx, cancel := goctx.WithTimeout(goctx.Background(), time.Minute)
+	defer cancel()
+
+	latest := &vmopv1.VirtualMachinePublishRequest{}
+	if err := r.apiReader.Get(ctx, client.ObjectKeyFromObject(vmPubReq), latest); err != nil {
+		if apiErrors.IsNotFound(err) {
+			return false, nil
+		}
+		return true, err
+	}
+
+	if !latest.DeletionTimestamp.IsZero() || getPublishRequestActID(latest) != actID ||
+		conditions.IsTrue(latest, vmopv1.VirtualMachinePublishRequestConditionUploaded) {
+		return false, nil
+	}
+
+	patch := client.MergeFrom(latest.DeepCopy())
+	if pushErr != nil {
+		conditions.MarkFalse(latest,
+			vmopv1.VirtualMachinePublishRequestConditionUploaded,
+			vmopv1.UploadFailureReason,
+			vmopv1.ConditionSeverityError, pushErr.Error())
+	} else {
+		markOCIArtifactUploaded(latest, digest)
+	}
+
+	return true, r.Client.Status().Patch(ctx, latest, patch)
+}
+
+// checkOCIPushStatusAndShouldRepublish checks the status of the push for the current
+// attempt, marks the Uploaded condition, and returns if the VM should be published again.
+func (r *Reconciler) checkOCIPushStatusAndShouldRepublish(ctx *context.VirtualMachinePublishRequestContext) bool {
+	actID := getPublishRequestActID(ctx.VMPublishRequest)
+	logger := ctx.Logger.WithValues("actID", actID)
+
+	obj, ok := r.ociPushes.Load(actID)
+	if !ok {
+		// The push is always recorded before it is started, so a missing record means the
+		// push was started by a prior instance of this controller and is no longer running.
+		logger.Info("push to OCI registry is not running, retry publishing this VM",
+			"lastAttemptTime", ctx.VMPublishRequest.Status.LastAttemptTime.String())
+		return true
+	}
+
+	result := obj.(ociPushResult)
+	switch {
+	case !result.done:
+		logger.V(5).Info("push to OCI registry is still in progress")
+		conditions.MarkFalse(ctx.VMPublishRequest,
+			vmopv1.VirtualMachinePublishRequestConditionUploaded,
+			vmopv1.UploadingReason,
+			vmopv1.ConditionSeverityInfo, "Uploading artifact to OCI registry.")
+		return false
+	case result.err != nil:
+		logger.Error(result.err, "push to OCI registry failed, will retry this operation")
+		r.ociPushes.Delete(actID)
+		conditions.MarkFalse(ctx.VMPublishRequest,
+			vmopv1.VirtualMachinePublishRequestConditionUploaded,
+			vmopv1.UploadFailureReason,
+			vmopv1.ConditionSeverityError, result.err.Error())
+		return true
+	default:
+		logger.Info("push to OCI registry succeeded", "digest", result.digest)
+		r.ociPushes.Delete(actID)
+		markOCIArtifactUploaded(ctx.VMPublishRequest, result.digest)
+		return false
+	}
+}
+
+// markOCIArtifactUploaded records the pushed artifact in the status and marks Uploaded to true.
+func markOCIArtifactUploaded(vmPubReq *vmopv1.VirtualMachinePublishRequest, digest string) {
+	repo, _ := ociregistry.ParseRepository(vmPubReq.Spec.Target.Location.OCI.Repository)
+	vmPubReq.Status.OCIArtifact = &vmopv1.VirtualMachinePublishRequestOCIArtifactStatus{
+		Reference: repo.Reference(vmPubReq.Status.TargetRef.Item.Name),
+		Digest:    digest,
+	}
+	conditions.MarkTrue(vmPubReq, vmopv1.VirtualMachinePublishRequestConditionUploaded)
+}
+
+// checkIsOCIArtifactAvailable checks if the pushed artifact can be resolved from the registry.
+func (r *Reconciler) checkIsOCIArtifactAvailable(ctx *context.VirtualMachinePublishRequestContext) error {
+	vmPubReq := ctx.VMPublishRequest
+	if vmPubReq.Status.OCIArtifact == nil {
+		return fmt.Errorf("uploaded artifact is missing from the status")
+	}
+
+	registry, err := r.getOCIRegistryClient(ctx)
+	if err != nil {
+		return err
+	}
+
+	digest, err := registry.ResolveManifest(ctx, vmPubReq.Status.OCIArtifact.Digest)
+	if err != nil {
+		ctx.Logger.Error(err, "failed to resolve manifest", "reference", vmPubReq.Status.OCIArtifact.Reference)
+		return err
+	}
+
+	if digest == "" {
+		conditions.MarkFalse(vmPubReq,
+			vmopv1.VirtualMachinePublishRequestConditionImageAvailable,
+			vmopv1.TargetOCIArtifactNotFoundReason,
+			vmopv1.ConditionSeverityWarning, "OCI artifact not found")
+		return nil
+	}
+
+	conditions.MarkTrue(vmPubReq, vmopv1.VirtualMachinePublishRequestConditionImageAvailable)
+	ctx.Logger.Info("OCI artifact is available", "reference", vmPubReq.Status.OCIArtifact.Reference)
+	return nil
+}
@@ -0,0 +1,497 @@
+// Copyright (c) 2023 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package virtualmachinepublishschedule
+
+import (
+	goctx "context"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/pkg/errors"
+	"github.com/robfig/cron/v3"
+
+	apiErrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+
+	vmopv1 "github.com/vmware-tanzu/vm-operator/api/v1alpha1"
+
+	imgregv1a1 "github.com/vmware-tanzu/vm-operator/external/image-registry/api/v1alpha1"
+
+	"github.com/vmware-tanzu/vm-operator/pkg/conditions"
+	"github.com/vmware-tanzu/vm-operator/pkg/context"
+	"github.com/vmware-tanzu/vm-operator/pkg/patch"
+	"github.com/vmware-tanzu/vm-operator/pkg/record"
+	"github.com/vmware-tanzu/vm-operator/pkg/vmprovider"
+)
+
+const (
+	// ItemNameTimeFormat is the format of the time the publication was scheduled
+	// that is appended to the names of the published items and the
+	// VirtualMachinePublishRequests spawned by a schedule.
+	ItemNameTimeFormat = "20060102150405"
+
+	// maxMissedSchedules is the number of missed publications after which,
+	// as in the CronJob controller, the schedule is no longer walked one
+	// publication at a time to find the most recent one.
+	maxMissedSchedules = 100
+)
+
+// AddToManager adds this package's controller to the provided manager.
+func AddToManager(ctx *context.ControllerManagerContext, mgr manager.Manager) error {
+	var (
+		controlledType     = &vmopv1.VirtualMachinePublishSchedule{}
+		controlledTypeName = reflect.TypeOf(controlledType).Elem().Name()
+
+		controllerNameShort = fmt.Sprintf("%s-controller", strings.ToLower(controlledTypeName))
+		controllerNameLong  = fmt.Sprintf("%s/%s/%s", ctx.Namespace, ctx.Name, controllerNameShort)
+	)
+
+	r := NewReconciler(
+		mgr.GetClient(),
+		ctrl.Log.WithName("controllers").WithName(controlledTypeName),
+		record.New(mgr.GetEventRecorderFor(controllerNameLong)),
+		ctx.VMProvider,
+	)
+
+	return ctrl.NewControllerManagedBy(mgr).
+		For(controlledType).
+		Owns(&vmopv1.VirtualMachinePublishRequest{}).
+		WithOptions(controller.Options{MaxConcurrentReconciles: ctx.MaxConcurrentReconciles}).
+		Complete(r)
+}
+
+func NewReconciler(
+	client client.Client,
+	logger logr.Logger,
+	recorder record.Recorder,
+	vmProvider vmprovider.VirtualMachineProviderInterface) *Reconciler {
+
+	return &Reconciler{
+		Client:     client,
+		Logger:     logger,
+		Recorder:   recorder,
+		VMProvider: vmProvider,
+	}
+}
+
+// Reconciler reconciles a VirtualMachinePublishSchedule object.
+type Reconciler struct {
+	client.Client
+	Logger     logr.Logger
+	Recorder   record.Recorder
+	VMProvider vmprovider.VirtualMachineProviderInterface
+}
+
+// +kubebuilder:rbac:groups=vmoperator.vmware.com,resources=virtualmachinepublishschedules,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=vmoperator.vmware.com,resources=virtualmachinepublishschedules/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups=vmoperator.vmware.com,resources=virtualmachinepublishrequests,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=imageregistry.vmware.com,resources=contentlibraries,verbs=get;list;watch
+
+func (r *Reconciler) Reconcile(ctx goctx.Context, req ctrl.Request) (_ ctrl.Result, reterr error) {
+	vmPubSchedule := &vmopv1.VirtualMachinePublishSchedule{}
+	if err := r.Get(ctx, req.NamespacedName, vmPubSchedule); err != nil {
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+
+	// The spawned VirtualMachinePublishRequests are owned by the schedule and garbage
+	// collected with it. The published items are intentionally left in the content library.
+	if !vmPubSchedule.DeletionTimestamp.IsZero() {
+		return ctrl.Result{}, nil
+	}
+
+	vmPubScheduleCtx := &context.VirtualMachinePublishScheduleContext{
+		Context:           ctx,
+		Logger:            ctrl.Log.WithName("VirtualMachinePublishSchedule").WithValues("name", req.NamespacedName),
+		VMPublishSchedule: vmPubSchedule,
+	}
+
+	patchHelper, err := patch.NewHelper(vmPubSchedule, r.Client)
+	if err != nil {
+		return ctrl.Result{}, errors.Wrapf(err, "failed to init patch helper for %s", vmPubScheduleCtx)
+	}
+	defer func() {
+		if err := patchHelper.Patch(ctx, vmPubSchedule); err != nil {
+			if reterr == nil {
+				reterr = err
+			}
+			vmPubScheduleCtx.Logger.Error(err, "patch failed")
+		}
+	}()
+
+	return r.ReconcileNormal(vmPubScheduleCtx, time.Now())
+}
+
+func (r *Reconciler) ReconcileNormal(ctx *context.VirtualMachinePublishScheduleContext, now time.Time) (ctrl.Result, error) {
+	ctx.Logger.V(4).Info("Reconciling VirtualMachinePublishSchedule")
+	vmPubSchedule := ctx.VMPublishSchedule
+
+	schedule, err := cron.ParseStandard(vmPubSchedule.Spec.Schedule)
+	if err != nil {
+		// The schedule is validated by the webhook, so this is not expected. There is no
+		// point in requeueing until the spec is updated.
+		ctx.Logger.Error(err, "invalid schedule", "schedule", vmPubSchedule.Spec.Schedule)
+		conditions.MarkFalse(vmPubSchedule,
+			vmopv1.VirtualMachinePublishScheduleConditionScheduleValid,
+			vmopv1.InvalidScheduleReason,
+			vmopv1.ConditionSeverityError, err.Error())
+		return ctrl.Result{}, nil
+	}
+	conditions.MarkTrue(vmPubSchedule, vmopv1.VirtualMachinePublishScheduleConditionScheduleValid)
+
+	pubReqs, err := r.getPublishRequests(ctx)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+
+	r.syncPublishRequests(ctx, pubReqs)
+
+	if err := r.deleteExpiredItems(ctx, pubReqs); err != nil {
+		return ctrl.Result{}, err
+	}
+
+	scheduledTime, tooManyMissed := getMostRecentScheduleTime(vmPubSchedule, schedule, now)
+	if tooManyMissed {
+		r.Recorder.Warnf(vmPubSchedule, "TooManyMissedPublications",
+			"More than %d publications were missed, only the most recent one is scheduled", maxMissedSchedules)
+	}
+	if scheduledTime != nil {
+		if err := r.schedulePublishRequest(ctx, *scheduledTime); err != nil {
+			return ctrl.Result{}, err
+		}
+	}
+
+	return ctrl.Result{RequeueAfter: schedule.Next(now).Sub(now)}, nil
+}
+
+// getPublishRequests returns the VirtualMachinePublishRequests spawned by the schedule, oldest first.
+func (r *Reconciler) getPublishRequests(ctx *context.VirtualMachinePublishScheduleContext) ([]vmopv1.VirtualMachinePublishRequest, error) {
+	vmPubSchedule := ctx.VMPublishSchedule
+
+	pubReqList := &vmopv1.VirtualMachinePublishRequestList{}
+	if err := r.List(ctx, pubReqList,
+		client.InNamespace(vmPubSchedule.Namespace),
+		client.MatchingLabels{vmopv1.VirtualMachinePublishScheduleLabelKey: vmPubSchedule.Name}); err != nil {
+		return nil, errors.Wrap(err, "failed to list VirtualMachinePublishRequests")
+	}
+
+	pubReqs := make([]vmopv1.VirtualMachinePublishRequest, 0, len(pubReqList.Items))
+	for _, pubReq := range pubReqList.Items {
+		// Skip the resources being deleted so the items they published are not recorded again.
+		if metav1.IsControlledBy(&pubReq, vmPubSchedule) && pubReq.DeletionTimestamp.IsZero() {
+			pubReqs = append(pubReqs, pubReq)
+		}
+	}
+
+	sort.Slice(pubReqs, func(i, j int) bool {
+		return pubReqs[i].Name < pubReqs[j].Name
+	})
+
+	return pubReqs, nil
+}
+
+// syncPublishRequests updates the status of the schedule from the spawned VirtualMachinePublishRequests.
+func (r *Reconciler) syncPublishRequests(ctx *context.VirtualMachinePublishScheduleContext,
+	pubReqs []vmopv1.VirtualMachinePublishRequest) {
+
+	vmPubSchedule := ctx.VMPublishSchedule
+	vmPubSchedule.Status.Active = ""
+
+	for i := range pubReqs {
+		pubReq := &pubReqs[i]
+
+		switch {
+		case conditions.IsTrue(pubReq, vmopv1.VirtualMachinePublishRequestConditionComplete):
+			if pubReq.Status.TargetRef == nil || hasPublishedItem(vmPubSchedule, pubReq.Status.TargetRef.Item.Name) {
+				continue
+			}
+
+			ctx.Logger.Info("VirtualMachinePublishRequest completed",
+				"publishRequest", pubReq.Name, "item", pubReq.Status.TargetRef.Item.Name)
+			publishTime := pubReq.Status.CompletionTime
+			vmPubSchedule.Status.PublishedItems = append(vmPubSchedule.Status.PublishedItems,
+				vmopv1.VirtualMachinePublishSchedulePublishedItem{
+					Name:               pubReq.Status.TargetRef.Item.Name,
+					PublishRequestName: pubReq.Name,
+					ImageName:          pubReq.Status.ImageName,
+					PublishTime:        publishTime,
+				})
+			if vmPubSchedule.Status.LastSuccessfulPublishTime == nil ||
+				vmPubSchedule.Status.LastSuccessfulPublishTime.Before(&publishTime) {
+				vmPubSchedule.Status.LastSuccessfulPublishTime = &publishTime
+			}
+			conditions.MarkTrue(vmPubSchedule, vmopv1.VirtualMachinePublishScheduleConditionLastPublishSucceeded)
+
+		case isPublishRequestFailed(pubReq):
+			// Only the most recent failure is reflected in the condition.
+			if i == len(pubReqs)-1 {
+				conditions.MarkFalse(vmPubSchedule,
+					vmopv1.VirtualMachinePublishScheduleConditionLastPublishSucceeded,
+					vmopv1.PublishRequestFailedReason,
+					vmopv1.ConditionSeverityError,
+					"VirtualMachinePublishRequest %s failed: %s", pubReq.Name, getPublishRequestFailureMessage(pubReq))
+			}
+
+		default:
+			vmPubSchedule.Status.Active = pubReq.Name
+		}
+	}
+
+	sort.SliceStable(vmPubSchedule.Status.PublishedItems, func(i, j int) bool {
+		items := vmPubSchedule.Status.PublishedItems
+		return items[i].PublishTime.Before(&items[j].PublishTime)
+	})
+}
+
+// deleteExpiredItems deletes the oldest published items from the content library until no more than
+// spec.retentionCount items remain, along with the VirtualMachinePublishRequests that published them.
+// Failed VirtualMachinePublishRequests other than the most recent one are deleted as well.
+func (r *Reconciler) deleteExpiredItems(ctx *context.VirtualMachinePublishScheduleContext,
+	pubReqs []vmopv1.VirtualMachinePublishRequest) error {
+
+	vmPubSchedule := ctx.VMPublishSchedule
+
+	retentionCount := vmopv1.VirtualMachinePublishScheduleDefaultRetentionCount
+	if vmPubSchedule.Spec.RetentionCount != nil {
+		retentionCount = int(*vmPubSchedule.Spec.RetentionCount)
+	}
+
+	for len(vmPubSchedule.Status.PublishedItems) > retentionCount {
+		item := vmPubSchedule.Status.PublishedItems[0]
+		if err := r.deletePublishedItem(ctx, item); err != nil {
+			r.Recorder.EmitEvent(vmPubSchedule, "DeletePublishedItem", err, false)
+			return err
+		}
+		r.Recorder.Eventf(vmPubSchedule, "DeletePublishedItemSuccess", "Deleted published item %s", item.Name)
+		vmPubSchedule.Status.PublishedItems = vmPubSchedule.Status.PublishedItems[1:]
+	}
+
+	for i := range pubReqs {
+		pubReq := &pubReqs[i]
+		if i == len(pubReqs)-1 || !isPublishRequestFailed(pubReq) {
+			continue
+		}
+		if err := r.deletePublishRequest(ctx, pubReq.Name); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (r *Reconciler) deletePublishedItem(ctx *context.VirtualMachinePublishScheduleContext,
+	item vmopv1.VirtualMachinePublishSchedulePublishedItem) error {
+
+	vmPubSchedule := ctx.VMPublishSchedule
+	logger := ctx.Logger.WithValues("item", item.Name)
+
+	contentLibrary := &imgregv1a1.ContentLibrary{}
+	objKey := client.ObjectKey{Name: vmPubSchedule.Spec.Target.Location.Name, Namespace: vmPubSchedule.Namespace}
+	if err := r.Get(ctx, objKey, contentLibrary); err != nil {
+		return errors.Wrapf(err, "failed to get ContentLibrary %s", objKey)
+	}
+
+	libItem, err := r.VMProvider.GetItemFromLibraryByName(ctx, contentLibrary.Spec.UUID, item.Name)
+	if err != nil {
+		return errors.Wrapf(err, "failed to find item %s", item.Name)
+	}
+
+	if libItem != nil {
+		logger.Info("Deleting published item from the content library", "itemID", libItem.ID)
+		if err := r.VMProvider.DeleteContentLibraryItem(ctx, libItem.ID); err != nil {
+			return errors.Wrapf(err, "failed to delete item %s", item.Name)
+		}
+	}
+
+	// Delete the VirtualMachinePublishRequest last so the item is not forgotten if
+	// deleting it from the content library fails.
+	return r.deletePublishRequest(ctx, item.PublishRequestName)
+}
+
+func (r *Reconciler) deletePublishRequest(ctx *context.VirtualMachinePublishScheduleContext, name string) error {
+	if name == "" {
+		return nil
+	}
+
+	pubReq := &vmopv1.VirtualMachinePublishRequest{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: ctx.VMPublishSchedule.Namespace,
+		},
+	}
+
+	ctx.Logger.Info("Deleting VirtualMachinePublishRequest", "publishRequest", name)
+	if err := r.Delete(ctx, pubReq); err != nil && !apiErrors.IsNotFound(err) {
+		return errors.Wrapf(err, "failed to delete VirtualMachinePublishRequest %s", name)
+	}
+
+	return nil
+}
+
+// schedulePublishRequest creates the VirtualMachinePublishRequest for the publication scheduled at the
+// provided time, unless another publication is still in progress.
+func (r *Reconciler) schedulePublishRequest(ctx *context.VirtualMachinePublishScheduleContext, scheduledTime time.Time) error {
+	vmPubSchedule := ctx.VMPublishSchedule
+	logger := ctx.Logger.WithValues("scheduledTime", scheduledTime)
+
+	if vmPubSchedule.Status.Active != "" {
+		logger.Info("Skipping scheduled publication because another one is in progress",
+			"active", vmPubSchedule.Status.Active)
+		r.Recorder.Warnf(vmPubSchedule, "PublishSkipped",
+			"Skipped publication scheduled at %s because %s is in progress",
+			scheduledTime.Format(time.RFC3339), vmPubSchedule.Status.Active)
+		vmPubSchedule.Status.LastScheduleTime = &metav1.Time{Time: scheduledTime}
+		return nil
+	}
+
+	pubReq, err := r.newPublishRequest(vmPubSchedule, scheduledTime)
+	if err != nil {
+		return err
+	}
+
+	logger.Info("Creating VirtualMachinePublishRequest",
+		"publishRequest", pubReq.Name, "item", pubReq.Spec.Target.Item.Name)
+	if err := r.Create(ctx, pubReq); err != nil && !apiErrors.IsAlreadyExists(err) {
+		r.Recorder.EmitEvent(vmPubSchedule, "CreatePublishRequest", err, false)
+		return errors.Wrapf(err, "failed to create VirtualMachinePublishRequest %s", pubReq.Name)
+	}
+	r.Recorder.Eventf(vmPubSchedule, "CreatePublishRequestSuccess",
+		"Created VirtualMachinePublishRequest %s", pubReq.Name)
+
+	vmPubSchedule.Status.Active = pubReq.Name
+	vmPubSchedule.Status.LastScheduleTime = &metav1.Time{Time: scheduledTime}
+	return nil
+}
+
+func (r *Reconciler) newPublishRequest(vmPubSchedule *vmopv1.VirtualMachinePublishSchedule,
+	scheduledTime time.Time) (*vmopv1.VirtualMachinePublishRequest, error) {
+
+	suffix := scheduledTime.UTC().Format(ItemNameTimeFormat)
+
+	source := vmPubSchedule.Spec.Source
+	if source.Name == "" {
+		source.Name = vmPubSchedule.Name
+	}
+
+	itemNamePrefix := vmPubSchedule.Spec.Target.Item.Name
+	if itemNamePrefix == "" {
+		itemNamePrefix = fmt.Sprintf("%s-image", source.Name)
+	}
+
+	pubReq := &vmopv1.VirtualMachinePublishRequest{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      fmt.Sprintf("%s-%s", vmPubSchedule.Name, suffix),
+			Namespace: vmPubSchedule.Namespace,
+			Labels: map[string]string{
+				vmopv1.VirtualMachinePublishScheduleLabelKey: vmPubSchedule.Name,
+			},
+		},
+		Spec: vmopv1.VirtualMachinePublishRequestSpec{
+			Source: source,
+			Target: vmopv1.VirtualMachinePublishRequestTarget{
+				Item: vmopv1.VirtualMachinePublishRequestTargetItem{
+					Name:        fmt.Sprintf("%s-%s", itemNamePrefix, suffix),
+					Description: vmPubSchedule.Spec.Target.Item.Description,
+				},
+				Location: vmPubSchedule.Spec.Target.Location,
+			},
+		},
+	}
+
+	if err := controllerutil.SetControllerReference(vmPubSchedule, pubReq, r.Scheme()); err != nil {
+		return nil, errors.Wrap(err, "failed to set controller reference")
+	}
+
+	return pubReq, nil
+}
+
+// getMostRecentScheduleTime returns the most recent time a publication was scheduled that has not
+// been handled yet, or nil if there is none or the schedule is suspended. Publications missed while
+// the controller was not running are not caught up on, only the most recent one is scheduled. It
+// also returns true if more than maxMissedSchedules publications were missed.
+func getMostRecentScheduleTime(vmPubSchedule *vmopv1.VirtualMachinePublishSchedule,
+	schedule cron.Schedule, now time.Time) (*time.Time, bool) {
+
+	if vmPubSchedule.Spec.Suspend {
+		return nil, false
+	}
+
+	earliestTime := vmPubSchedule.CreationTimestamp.Time
+	if vmPubSchedule.Status.LastScheduleTime != nil {
+		earliestTime = vmPubSchedule.Status.LastScheduleTime.Time
+	}
+
+	var mostRecentTime *time.Time
+	missed := 0
+	for t := schedule.Next(earliestTime); !t.After(now); t = schedule.Next(t) {
+		if missed == maxMissedSchedules {
+			// A frequent schedule that was suspended for a long time has many missed publications.
+			lastTime := getLastScheduleTime(schedule, t, now)
+			return &lastTime, true
+		}
+		scheduledTime := t
+		mostRecentTime = &scheduledTime
+		missed++
+	}
+
+	return mostRecentTime, false
+}
+
+// getLastScheduleTime returns the last time of the schedule that is not after now, given the time
+// of the schedule earliestTime that is not after now either. Instead of walking every time of the
+// schedule since earliestTime, the times are searched in windows before now that double in size.
+func getLastScheduleTime(schedule cron.Schedule, earliestTime, now time.Time) time.Time {
+	start := earliestTime
+	for window := time.Minute; now.Add(-window).After(earliestTime); window *= 2 {
+		if t := schedule.Next(now.Add(-window)); !t.After(now) {
+			start = t
+			break
+		}
+	}
+
+	lastTime := start
+	for t := schedule.Next(start); !t.After(now); t = schedule.Next(t) {
+		lastTime = t
+	}
+
+	return lastTime
+}
+
+func hasPublishedItem(vmPubSchedule *vmopv1.VirtualMachinePublishSchedule, itemName string) bool {
+	for _, item := range vmPubSchedule.Status.PublishedItems {
+		if item.Name == itemName {
+			return true
+		}
+	}
+	return false
+}
+
+// isPublishRequestFailed returns true if the VirtualMachinePublishRequest failed and will not be retried
+// by the VirtualMachinePublishRequest controller.
+func isPublishRequestFailed(pubReq *vmopv1.VirtualMachinePublishRequest) bool {
+	return conditions.GetReason(pubReq, vmopv1.VirtualMachinePublishRequestConditionTargetValid) ==
+		vmopv1.TargetItemAlreadyExistsReason ||
+		conditions.GetReason(pubReq, vmopv1.VirtualMachinePublishRequestConditionUploaded) ==
+			vmopv1.UploadItemIDInvalidReason
+}
+
+func getPublishRequestFailureMessage(pubReq *vmopv1.VirtualMachinePublishRequest) string {
+	for _, conditionType := range []vmopv1.ConditionType{
+		vmopv1.VirtualMachinePublishRequestConditionTargetValid,
+		vmopv1.VirtualMachinePublishRequestConditionUploaded,
+	} {
+		if conditions.IsFalse(pubReq, conditionType) {
+			return conditions.GetMessage(pubReq, conditionType)
+		}
+	}
+	return ""
+}
@@ -0,0 +1,368 @@
+// Copyright (c) 2023 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package virtualmachinepublishschedule_test
+
+import (
+	goctx "context"
+	"errors"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	apiErrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+
+	"github.com/vmware/govmomi/vapi/library"
+
+	vmopv1 "github.com/vmware-tanzu/vm-operator/api/v1alpha1"
+
+	imgregv1a1 "github.com/vmware-tanzu/vm-operator/external/image-registry/api/v1alpha1"
+
+	"github.com/vmware-tanzu/vm-operator/controllers/virtualmachinepublishschedule"
+	"github.com/vmware-tanzu/vm-operator/pkg/conditions"
+	vmopContext "github.com/vmware-tanzu/vm-operator/pkg/context"
+	providerfake "github.com/vmware-tanzu/vm-operator/pkg/vmprovider/fake"
+	"github.com/vmware-tanzu/vm-operator/test/builder"
+)
+
+func unitTests() {
+	Describe("Invoking VirtualMachinePublishSchedule Reconcile", unitTestsReconcile)
+}
+
+func unitTestsReconcile() {
+	var (
+		initObjects []client.Object
+		ctx         *builder.UnitTestContextForController
+
+		reconciler     *virtualmachinepublishschedule.Reconciler
+		fakeVMProvider *providerfake.VMProvider
+
+		now           time.Time
+		vmPubSched    *vmopv1.VirtualMachinePublishSchedule
+		cl            *imgregv1a1.ContentLibrary
+		vmPubSchedCtx *vmopContext.VirtualMachinePublishScheduleContext
+	)
+
+	// newPublishRequest returns a VirtualMachinePublishRequest spawned by vmPubSched for the
+	// publication scheduled at the provided time.
+	newPublishRequest := func(scheduledTime time.Time) *vmopv1.VirtualMachinePublishRequest {
+		suffix := scheduledTime.Format(virtualmachinepublishschedule.ItemNameTimeFormat)
+		vmPub := builder.DummyVirtualMachinePublishRequest(vmPubSched.Name+"-"+suffix, vmPubSched.Namespace,
+			vmPubSched.Spec.Source.Name, vmPubSched.Spec.Target.Item.Name+"-"+suffix, cl.Name)
+		vmPub.Finalizers = nil
+		vmPub.Labels = map[string]string{vmopv1.VirtualMachinePublishScheduleLabelKey: vmPubSched.Name}
+		Expect(controllerutil.SetControllerReference(vmPubSched, vmPub, builder.NewScheme())).To(Succeed())
+		vmPub.Status.TargetRef = &vmPub.Spec.Target
+		return vmPub
+	}
+
+	markComplete := func(vmPub *vmopv1.VirtualMachinePublishRequest, completionTime time.Time) {
+		conditions.MarkTrue(vmPub, vmopv1.VirtualMachinePublishRequestConditionComplete)
+		vmPub.Status.CompletionTime = metav1.NewTime(completionTime)
+		vmPub.Status.ImageName = "vmi-" + vmPub.Name
+	}
+
+	BeforeEach(func() {
+		now = time.Date(2023, 1, 2, 3, 4, 5, 0, time.UTC)
+
+		vmPubSched = builder.DummyVirtualMachinePublishSchedule("dummy-sched", "dummy-ns", "dummy-vm", "dummy-item", "dummy-cl")
+		vmPubSched.UID = "dummy-sched-uid"
+		vmPubSched.Status.LastScheduleTime = &metav1.Time{Time: now.Add(-time.Hour).Truncate(time.Hour)}
+		cl = builder.DummyContentLibrary("dummy-cl", vmPubSched.Namespace, "dummy-cl-uuid")
+	})
+
+	JustBeforeEach(func() {
+		ctx = suite.NewUnitTestContextForController(initObjects...)
+		reconciler = virtualmachinepublishschedule.NewReconciler(
+			ctx.Client,
+			ctx.Logger,
+			ctx.Recorder,
+			ctx.VMProvider,
+		)
+		fakeVMProvider = ctx.VMProvider.(*providerfake.VMProvider)
+		fakeVMProvider.Reset()
+
+		vmPubSchedCtx = &vmopContext.VirtualMachinePublishScheduleContext{
+			Context:           ctx,
+			Logger:            ctx.Logger.WithName(vmPubSched.Name),
+			VMPublishSchedule: vmPubSched,
+		}
+	})
+
+	AfterEach(func() {
+		ctx.AfterEach()
+		ctx = nil
+		initObjects = nil
+		reconciler = nil
+	})
+
+	getPublishRequests := func() []vmopv1.VirtualMachinePublishRequest {
+		vmPubList := &vmopv1.VirtualMachinePublishRequestList{}
+		Expect(ctx.Client.List(ctx, vmPubList, client.InNamespace(vmPubSched.Namespace))).To(Succeed())
+		return vmPubList.Items
+	}
+
+	Context("ReconcileNormal", func() {
+		BeforeEach(func() {
+			initObjects = append(initObjects, vmPubSched, cl)
+		})
+
+		When("schedule is invalid", func() {
+			BeforeEach(func() {
+				vmPubSched.Spec.Schedule = "not a schedule"
+			})
+
+			It("marks ScheduleValid false and does not requeue", func() {
+				result, err := reconciler.ReconcileNormal(vmPubSchedCtx, now)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(result.RequeueAfter).To(BeZero())
+				Expect(conditions.IsFalse(vmPubSched, vmopv1.VirtualMachinePublishScheduleConditionScheduleValid)).To(BeTrue())
+				Expect(conditions.GetReason(vmPubSched, vmopv1.VirtualMachinePublishScheduleConditionScheduleValid)).
+					To(Equal(vmopv1.InvalidScheduleReason))
+				Expect(getPublishRequests()).To(BeEmpty())
+			})
+		})
+
+		When("a publication is due", func() {
+			It("creates a VirtualMachinePublishRequest with a versioned item name", func() {
+				result, err := reconciler.ReconcileNormal(vmPubSchedCtx, now)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(result.RequeueAfter).To(Equal(55*time.Minute + 55*time.Second))
+				Expect(conditions.IsTrue(vmPubSched, vmopv1.VirtualMachinePublishScheduleConditionScheduleValid)).To(BeTrue())
+
+				vmPubs := getPublishRequests()
+				Expect(vmPubs).To(HaveLen(1))
+				vmPub := vmPubs[0]
+				Expect(vmPub.Name).To(Equal("dummy-sched-20230102030000"))
+				Expect(vmPub.Labels).To(HaveKeyWithValue(vmopv1.VirtualMachinePublishScheduleLabelKey, vmPubSched.Name))
+				Expect(metav1.IsControlledBy(&vmPub, vmPubSched)).To(BeTrue())
+				Expect(vmPub.Spec.Source.Name).To(Equal("dummy-vm"))
+				Expect(vmPub.Spec.Target.Item.Name).To(Equal("dummy-item-20230102030000"))
+				Expect(vmPub.Spec.Target.Location.Name).To(Equal(cl.Name))
+
+				Expect(vmPubSched.Status.Active).To(Equal(vmPub.Name))
+				Expect(vmPubSched.Status.LastScheduleTime.Time).To(Equal(now.Truncate(time.Hour)))
+			})
+
+			When("item name prefix is omitted", func() {
+				BeforeEach(func() {
+					vmPubSched.Spec.Target.Item.Name = ""
+				})
+
+				It("uses the source name as the prefix", func() {
+					_, err := reconciler.ReconcileNormal(vmPubSchedCtx, now)
+					Expect(err).NotTo(HaveOccurred())
+
+					vmPubs := getPublishRequests()
+					Expect(vmPubs).To(HaveLen(1))
+					Expect(vmPubs[0].Spec.Target.Item.Name).To(Equal("dummy-vm-image-20230102030000"))
+				})
+			})
+
+			When("several publications were missed", func() {
+				BeforeEach(func() {
+					vmPubSched.Status.LastScheduleTime = &metav1.Time{Time: now.Add(-5 * time.Hour).Truncate(time.Hour)}
+				})
+
+				It("only schedules the most recent one", func() {
+					_, err := reconciler.ReconcileNormal(vmPubSchedCtx, now)
+					Expect(err).NotTo(HaveOccurred())
+
+					vmPubs := getPublishRequests()
+					Expect(vmPubs).To(HaveLen(1))
+					Expect(vmPubs[0].Name).To(Equal("dummy-sched-20230102030000"))
+				})
+			})
+
+			When("more publications were missed than are walked", func() {
+				BeforeEach(func() {
+					vmPubSched.Spec.Schedule = "* * * * *"
+					vmPubSched.Status.LastScheduleTime = &metav1.Time{Time: now.AddDate(0, -6, 0).Truncate(time.Minute)}
+				})
+
+				It("schedules the most recent one and emits an event", func() {
+					_, err := reconciler.ReconcileNormal(vmPubSchedCtx, now)
+					Expect(err).NotTo(HaveOccurred())
+
+					vmPubs := getPublishRequests()
+					Expect(vmPubs).To(HaveLen(1))
+					Expect(vmPubs[0].Name).To(Equal("dummy-sched-20230102030400"))
+					Expect(vmPubSched.Status.LastScheduleTime.Time).To(Equal(now.Truncate(time.Minute)))
+					Expect(ctx.Events).To(Receive(ContainSubstring("TooManyMissedPublications")))
+				})
+			})
+
+			When("schedule is suspended", func() {
+				BeforeEach(func() {
+					vmPubSched.Spec.Suspend = true
+				})
+
+				It("does not create a VirtualMachinePublishRequest", func() {
+					_, err := reconciler.ReconcileNormal(vmPubSchedCtx, now)
+					Expect(err).NotTo(HaveOccurred())
+					Expect(getPublishRequests()).To(BeEmpty())
+					Expect(vmPubSched.Status.Active).To(BeEmpty())
+				})
+			})
+
+			When("another publication is in progress", func() {
+				var activeVMPub *vmopv1.VirtualMachinePublishRequest
+
+				BeforeEach(func() {
+					activeVMPub = newPublishRequest(now.Add(-time.Hour).Truncate(time.Hour))
+					initObjects = append(initObjects, activeVMPub)
+				})
+
+				It("skips the publication", func() {
+					_, err := reconciler.ReconcileNormal(vmPubSchedCtx, now)
+					Expect(err).NotTo(HaveOccurred())
+
+					vmPubs := getPublishRequests()
+					Expect(vmPubs).To(HaveLen(1))
+					Expect(vmPubs[0].Name).To(Equal(activeVMPub.Name))
+					Expect(vmPubSched.Status.Active).To(Equal(activeVMPub.Name))
+					Expect(vmPubSched.Status.LastScheduleTime.Time).To(Equal(now.Truncate(time.Hour)))
+				})
+			})
+		})
+
+		When("a publication is not due", func() {
+			BeforeEach(func() {
+				vmPubSched.Status.LastScheduleTime = &metav1.Time{Time: now.Truncate(time.Hour)}
+			})
+
+			It("does not create a VirtualMachinePublishRequest", func() {
+				result, err := reconciler.ReconcileNormal(vmPubSchedCtx, now)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(result.RequeueAfter).To(Equal(55*time.Minute + 55*time.Second))
+				Expect(getPublishRequests()).To(BeEmpty())
+			})
+
+			When("a VirtualMachinePublishRequest completed", func() {
+				var vmPub *vmopv1.VirtualMachinePublishRequest
+
+				BeforeEach(func() {
+					vmPub = newPublishRequest(now.Truncate(time.Hour))
+					markComplete(vmPub, now.Add(-time.Minute))
+					initObjects = append(initObjects, vmPub)
+				})
+
+				It("records the published item", func() {
+					_, err := reconciler.ReconcileNormal(vmPubSchedCtx, now)
+					Expect(err).NotTo(HaveOccurred())
+
+					Expect(vmPubSched.Status.Active).To(BeEmpty())
+					Expect(vmPubSched.Status.PublishedItems).To(HaveLen(1))
+					item := vmPubSched.Status.PublishedItems[0]
+					Expect(item.Name).To(Equal(vmPub.Spec.Target.Item.Name))
+					Expect(item.PublishRequestName).To(Equal(vmPub.Name))
+					Expect(item.ImageName).To(Equal(vmPub.Status.ImageName))
+					Expect(vmPubSched.Status.LastSuccessfulPublishTime.Time).To(BeTemporally("==", vmPub.Status.CompletionTime.Time))
+					Expect(conditions.IsTrue(vmPubSched, vmopv1.VirtualMachinePublishScheduleConditionLastPublishSucceeded)).To(BeTrue())
+				})
+
+				When("more items than the retention count are published", func() {
+					var (
+						oldVMPub       *vmopv1.VirtualMachinePublishRequest
+						deletedItemIDs []string
+					)
+
+					BeforeEach(func() {
+						retentionCount := int32(1)
+						vmPubSched.Spec.RetentionCount = &retentionCount
+
+						oldVMPub = newPublishRequest(now.Add(-time.Hour).Truncate(time.Hour))
+						markComplete(oldVMPub, now.Add(-time.Hour))
+						initObjects = append(initObjects, oldVMPub)
+						deletedItemIDs = nil
+					})
+
+					JustBeforeEach(func() {
+						fakeVMProvider.GetItemFromLibraryByNameFn = func(_ goctx.Context, clUUID, itemName string) (*library.Item, error) {
+							Expect(clUUID).To(Equal(cl.Spec.UUID))
+							return &library.Item{ID: "id-" + itemName, Name: itemName}, nil
+						}
+						fakeVMProvider.DeleteContentLibraryItemFn = func(_ goctx.Context, itemID string) error {
+							deletedItemIDs = append(deletedItemIDs, itemID)
+							return nil
+						}
+					})
+
+					It("deletes the oldest items and their VirtualMachinePublishRequests", func() {
+						_, err := reconciler.ReconcileNormal(vmPubSchedCtx, now)
+						Expect(err).NotTo(HaveOccurred())
+
+						Expect(deletedItemIDs).To(ConsistOf("id-" + oldVMPub.Spec.Target.Item.Name))
+						Expect(vmPubSched.Status.PublishedItems).To(HaveLen(1))
+						Expect(vmPubSched.Status.PublishedItems[0].Name).To(Equal(vmPub.Spec.Target.Item.Name))
+
+						err = ctx.Client.Get(ctx, client.ObjectKeyFromObject(oldVMPub), &vmopv1.VirtualMachinePublishRequest{})
+						Expect(apiErrors.IsNotFound(err)).To(BeTrue())
+						Expect(getPublishRequests()).To(HaveLen(1))
+					})
+
+					When("the item is not in the content library", func() {
+						JustBeforeEach(func() {
+							fakeVMProvider.GetItemFromLibraryByNameFn = func(_ goctx.Context, _, _ string) (*library.Item, error) {
+								return nil, nil
+							}
+						})
+
+						It("still forgets the item", func() {
+							_, err := reconciler.ReconcileNormal(vmPubSchedCtx, now)
+							Expect(err).NotTo(HaveOccurred())
+							Expect(deletedItemIDs).To(BeEmpty())
+							Expect(vmPubSched.Status.PublishedItems).To(HaveLen(1))
+							Expect(getPublishRequests()).To(HaveLen(1))
+						})
+					})
+
+					When("deleting the item fails", func() {
+						JustBeforeEach(func() {
+							fakeVMProvider.DeleteContentLibraryItemFn = func(_ goctx.Context, _ string) error {
+								return errors.New("delete failed")
+							}
+						})
+
+						It("returns an error and keeps the item", func() {
+							_, err := reconciler.ReconcileNormal(vmPubSchedCtx, now)
+							Expect(err).To(HaveOccurred())
+							Expect(vmPubSched.Status.PublishedItems).To(HaveLen(2))
+							Expect(getPublishRequests()).To(HaveLen(2))
+						})
+					})
+				})
+			})
+
+			When("a VirtualMachinePublishRequest failed", func() {
+				var vmPub *vmopv1.VirtualMachinePublishRequest
+
+				BeforeEach(func() {
+					vmPub = newPublishRequest(now.Truncate(time.Hour))
+					conditions.MarkFalse(vmPub,
+						vmopv1.VirtualMachinePublishRequestConditionTargetValid,
+						vmopv1.TargetItemAlreadyExistsReason,
+						vmopv1.ConditionSeverityError, "item already exists")
+					initObjects = append(initObjects, vmPub)
+				})
+
+				It("marks LastPublishSucceeded false", func() {
+					_, err := reconciler.ReconcileNormal(vmPubSchedCtx, now)
+					Expect(err).NotTo(HaveOccurred())
+
+					Expect(vmPubSched.Status.Active).To(BeEmpty())
+					Expect(vmPubSched.Status.PublishedItems).To(BeEmpty())
+					Expect(conditions.IsFalse(vmPubSched, vmopv1.VirtualMachinePublishScheduleConditionLastPublishSucceeded)).To(BeTrue())
+					Expect(conditions.GetReason(vmPubSched, vmopv1.VirtualMachinePublishScheduleConditionLastPublishSucceeded)).
+						To(Equal(vmopv1.PublishRequestFailedReason))
+					Expect(conditions.GetMessage(vmPubSched, vmopv1.VirtualMachinePublishScheduleConditionLastPublishSucceeded)).
+						To(ContainSubstring("item already exists"))
+				})
+			})
+		})
+	})
+}
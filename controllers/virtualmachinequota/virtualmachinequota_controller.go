@@ -0,0 +1,139 @@
+// Copyright (c) 2023 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package virtualmachinequota
+
+import (
+	goctx "context"
+	"reflect"
+
+	"github.com/go-logr/logr"
+	"github.com/pkg/errors"
+
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+
+	vmopv1 "github.com/vmware-tanzu/vm-operator/api/v1alpha1"
+
+	"github.com/vmware-tanzu/vm-operator/pkg/context"
+	"github.com/vmware-tanzu/vm-operator/pkg/patch"
+	"github.com/vmware-tanzu/vm-operator/pkg/vmquota"
+)
+
+// AddToManager adds this package's controller to the provided manager.
+func AddToManager(ctx *context.ControllerManagerContext, mgr manager.Manager) error {
+	var (
+		controlledType     = &vmopv1.VirtualMachineQuota{}
+		controlledTypeName = reflect.TypeOf(controlledType).Elem().Name()
+	)
+
+	r := NewReconciler(
+		mgr.GetClient(),
+		ctrl.Log.WithName("controllers").WithName(controlledTypeName),
+	)
+
+	return ctrl.NewControllerManagedBy(mgr).
+		For(controlledType).
+		Watches(&source.Kind{Type: &vmopv1.VirtualMachine{}},
+			handler.EnqueueRequestsFromMapFunc(vmToVMQuotaMapperFn(ctx, r.Client))).
+		WithOptions(controller.Options{MaxConcurrentReconciles: ctx.MaxConcurrentReconciles}).
+		Complete(r)
+}
+
+// vmToVMQuotaMapperFn returns a mapper function that enqueues all the VirtualMachineQuotas of the namespace
+// of a VM so their used resources are updated when VMs are created, updated or deleted.
+func vmToVMQuotaMapperFn(ctx *context.ControllerManagerContext, c client.Client) func(o client.Object) []reconcile.Request {
+	return func(o client.Object) []reconcile.Request {
+		vm := o.(*vmopv1.VirtualMachine)
+
+		vmQuotaList := &vmopv1.VirtualMachineQuotaList{}
+		if err := c.List(ctx, vmQuotaList, client.InNamespace(vm.Namespace)); err != nil {
+			ctx.Logger.Error(err, "Failed to list VirtualMachineQuotas for reconciliation due to VirtualMachine watch",
+				"namespace", vm.Namespace)
+			return nil
+		}
+
+		reconcileRequests := make([]reconcile.Request, 0, len(vmQuotaList.Items))
+		for _, vmQuota := range vmQuotaList.Items {
+			key := client.ObjectKey{Namespace: vmQuota.Namespace, Name: vmQuota.Name}
+			reconcileRequests = append(reconcileRequests, reconcile.Request{NamespacedName: key})
+		}
+
+		return reconcileRequests
+	}
+}
+
+func NewReconciler(
+	client client.Client,
+	logger logr.Logger) *Reconciler {
+
+	return &Reconciler{
+		Client: client,
+		Logger: logger,
+	}
+}
+
+// Reconciler reconciles a VirtualMachineQuota object.
+type Reconciler struct {
+	client.Client
+	Logger logr.Logger
+}
+
+// +kubebuilder:rbac:groups=vmoperator.vmware.com,resources=virtualmachinequotas,verbs=get;list;watch
+// +kubebuilder:rbac:groups=vmoperator.vmware.com,resources=virtualmachinequotas/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups=vmoperator.vmware.com,resources=virtualmachines,verbs=get;list;watch
+// +kubebuilder:rbac:groups=vmoperator.vmware.com,resources=virtualmachineclasses,verbs=get;list;watch
+
+func (r *Reconciler) Reconcile(ctx goctx.Context, req ctrl.Request) (_ ctrl.Result, reterr error) {
+	vmQuota := &vmopv1.VirtualMachineQuota{}
+	if err := r.Get(ctx, req.NamespacedName, vmQuota); err != nil {
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+
+	vmQuotaCtx := &context.VirtualMachineQuotaContext{
+		Context: ctx,
+		Logger:  ctrl.Log.WithName("VirtualMachineQuota").WithValues("name", req.NamespacedName),
+		VMQuota: vmQuota,
+	}
+
+	patchHelper, err := patch.NewHelper(vmQuota, r.Client)
+	if err != nil {
+		return ctrl.Result{}, errors.Wrapf(err, "failed to init patch helper for %s", vmQuotaCtx)
+	}
+	defer func() {
+		if err := patchHelper.Patch(ctx, vmQuota); err != nil {
+			if reterr == nil {
+				reterr = err
+			}
+			vmQuotaCtx.Logger.Error(err, "patch failed")
+		}
+	}()
+
+	if !vmQuota.DeletionTimestamp.IsZero() {
+		return ctrl.Result{}, nil
+	}
+
+	return ctrl.Result{}, r.ReconcileNormal(vmQuotaCtx)
+}
+
+// ReconcileNormal updates the status of the VirtualMachineQuota with its hard limits and the resources
+// that the VMs of its namespace consume.
+func (r *Reconciler) ReconcileNormal(ctx *context.VirtualMachineQuotaContext) error {
+	ctx.Logger.V(4).Info("Reconciling VirtualMachineQuota")
+	vmQuota := ctx.VMQuota
+
+	used, err := vmquota.NamespaceUsage(ctx, r.Client, vmQuota.Namespace, "")
+	if err != nil {
+		return errors.Wrapf(err, "failed to compute the used resources of namespace %s", vmQuota.Namespace)
+	}
+
+	vmQuota.Status.Hard = vmQuota.Spec.Hard.DeepCopy()
+	vmQuota.Status.Used = vmquota.Mask(used, vmQuota.Spec.Hard)
+
+	return nil
+}
@@ -0,0 +1,118 @@
+// Copyright (c) 2023 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package virtualmachinequota_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	vmopv1 "github.com/vmware-tanzu/vm-operator/api/v1alpha1"
+
+	"github.com/vmware-tanzu/vm-operator/controllers/virtualmachinequota"
+	vmopContext "github.com/vmware-tanzu/vm-operator/pkg/context"
+	"github.com/vmware-tanzu/vm-operator/test/builder"
+)
+
+func unitTests() {
+	Describe("Invoking VirtualMachineQuota Reconcile", unitTestsReconcile)
+}
+
+func unitTestsReconcile() {
+	var (
+		initObjects []client.Object
+		ctx         *builder.UnitTestContextForController
+
+		reconciler *virtualmachinequota.Reconciler
+		vmQuota    *vmopv1.VirtualMachineQuota
+		vmQuotaCtx *vmopContext.VirtualMachineQuotaContext
+
+		vmClass *vmopv1.VirtualMachineClass
+		vm1     *vmopv1.VirtualMachine
+		vm2     *vmopv1.VirtualMachine
+	)
+
+	BeforeEach(func() {
+		vmClass = builder.DummyVirtualMachineClass()
+		vmClass.Name = "dummy-class"
+
+		vm1 = builder.DummyVirtualMachine()
+		vm1.Name = "dummy-vm-1"
+		vm1.Namespace = "dummy-ns"
+		vm1.Spec.ClassName = vmClass.Name
+
+		vm2 = vm1.DeepCopy()
+		vm2.Name = "dummy-vm-2"
+
+		vmQuota = builder.DummyVirtualMachineQuota("dummy-quota", vm1.Namespace, corev1.ResourceList{
+			vmopv1.VirtualMachineQuotaResourceVirtualMachines: resource.MustParse("10"),
+			vmopv1.VirtualMachineQuotaResourceCPU:             resource.MustParse("16"),
+			vmopv1.VirtualMachineQuotaResourceVGPUs:           resource.MustParse("2"),
+		})
+	})
+
+	JustBeforeEach(func() {
+		ctx = suite.NewUnitTestContextForController(initObjects...)
+		reconciler = virtualmachinequota.NewReconciler(
+			ctx.Client,
+			ctx.Logger,
+		)
+
+		vmQuotaCtx = &vmopContext.VirtualMachineQuotaContext{
+			Context: ctx,
+			Logger:  ctx.Logger.WithName(vmQuota.Name),
+			VMQuota: vmQuota,
+		}
+	})
+
+	AfterEach(func() {
+		ctx.AfterEach()
+		ctx = nil
+		initObjects = nil
+		reconciler = nil
+	})
+
+	Context("ReconcileNormal", func() {
+		When("the namespace has no VMs", func() {
+			BeforeEach(func() {
+				initObjects = append(initObjects, vmQuota)
+			})
+
+			It("reports the hard limits and zero usage", func() {
+				Expect(reconciler.ReconcileNormal(vmQuotaCtx)).To(Succeed())
+				Expect(vmQuota.Status.Hard).To(Equal(vmQuota.Spec.Hard))
+				Expect(vmQuota.Status.Used).To(HaveLen(3))
+				for name, quantity := range vmQuota.Status.Used {
+					Expect(quantity.IsZero()).To(BeTrue(), string(name))
+				}
+			})
+		})
+
+		When("the namespace has VMs", func() {
+			BeforeEach(func() {
+				otherNamespaceVM := vm1.DeepCopy()
+				otherNamespaceVM.Namespace = "other-ns"
+				initObjects = append(initObjects, vmQuota, vmClass, vm1, vm2, otherNamespaceVM)
+			})
+
+			It("reports the resources of the hard limits that the VMs of the namespace consume", func() {
+				Expect(reconciler.ReconcileNormal(vmQuotaCtx)).To(Succeed())
+
+				used := vmQuota.Status.Used
+				Expect(used).To(HaveLen(3))
+				Expect(used).ToNot(HaveKey(vmopv1.VirtualMachineQuotaResourceMemory))
+
+				vms := used[vmopv1.VirtualMachineQuotaResourceVirtualMachines]
+				Expect(vms.Value()).To(BeEquivalentTo(2))
+				cpus := used[vmopv1.VirtualMachineQuotaResourceCPU]
+				Expect(cpus.Value()).To(BeEquivalentTo(2 * vmClass.Spec.Hardware.Cpus))
+				vgpus := used[vmopv1.VirtualMachineQuotaResourceVGPUs]
+				Expect(vgpus.IsZero()).To(BeTrue())
+			})
+		})
+	})
+}
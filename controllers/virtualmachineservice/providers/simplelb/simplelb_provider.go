@@ -1,4 +1,4 @@
-// Copyright (c) 2019-2020 VMware, Inc. All Rights Reserved.
+// Copyright (c) 2019-2023 VMware, Inc. All Rights Reserved.
 // SPDX-License-Identifier: Apache-2.0
 
 package simplelb
@@ -21,8 +21,12 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/manager"
 
 	vmopv1 "github.com/vmware-tanzu/vm-operator/api/v1alpha1"
+	"github.com/vmware-tanzu/vm-operator/pkg/vmimage"
 )
 
+// lbImageChannel is the product of the loadbalancer-vm image.
+const lbImageChannel = "loadbalancer-vm"
+
 type Provider struct {
 	client       client.Client
 	controlPlane loadbalancerControlPlane
@@ -48,7 +52,7 @@ func (s *Provider) EnsureLoadBalancer(ctx context.Context, vmService *vmopv1.Vir
 	if err != nil {
 		return err
 	}
-	vmImageName, err := s.GetVirtualMachineImageName(ctx)
+	vmImageName, err := s.GetVirtualMachineImageName(ctx, vmService.Namespace)
 	if err != nil {
 		return err
 	}
@@ -86,8 +90,16 @@ func (s *Provider) GetVirtualMachineClassName(ctx context.Context, namespace str
 }
 
 // GetVirtualMachineImageName returns the image name for loadbalancer-vm image in the cluster.
-// Since we use generateName for VirtualMachineImage resources, we cannot directly use 'loadbalancer-vm'.
-func (s *Provider) GetVirtualMachineImageName(ctx context.Context) (string, error) {
+// The latest non-deprecated image whose product or channel label is 'loadbalancer-vm' is preferred. Otherwise,
+// since we use generateName for VirtualMachineImage resources, we cannot directly use 'loadbalancer-vm' and
+// fall back to an image whose name contains it.
+func (s *Provider) GetVirtualMachineImageName(ctx context.Context, namespace string) (string, error) {
+	imageName, err := vmimage.ResolveLatest(ctx, s.client, namespace, lbImageChannel)
+	if err == nil {
+		return imageName, nil
+	}
+	s.log.V(4).Info("no labeled loadbalancer-vm image, falling back to the image name", "reason", err.Error())
+
 	imageList := &vmopv1.VirtualMachineImageList{}
 	if err := s.client.List(ctx, imageList); err != nil {
 		s.log.Error(err, "failed to list VirtualMachineImages from control plane")
@@ -95,7 +107,7 @@ func (s *Provider) GetVirtualMachineImageName(ctx context.Context) (string, erro
 	}
 
 	for _, img := range imageList.Items {
-		if strings.Contains(img.Name, "loadbalancer-vm") {
+		if strings.Contains(img.Name, lbImageChannel) && !vmimage.IsDeprecatedLabels(img.Labels) {
 			return img.Name, nil
 		}
 	}
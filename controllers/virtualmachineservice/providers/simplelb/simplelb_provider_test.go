@@ -1,4 +1,4 @@
-// Copyright (c) 2019-2020 VMware, Inc. All Rights Reserved.
+// Copyright (c) 2019-2023 VMware, Inc. All Rights Reserved.
 // SPDX-License-Identifier: Apache-2.0
 
 package simplelb
@@ -16,6 +16,7 @@ import (
 	"k8s.io/apimachinery/pkg/util/intstr"
 
 	vmopv1 "github.com/vmware-tanzu/vm-operator/api/v1alpha1"
+	"github.com/vmware-tanzu/vm-operator/pkg/vmimage"
 
 	"github.com/vmware-tanzu/vm-operator/test/builder"
 )
@@ -195,4 +196,53 @@ var _ = Describe("", func() {
 			})
 		})
 	})
+
+	Context("GetVirtualMachineImageName()", func() {
+		It("should prefer the latest non-deprecated image labeled as loadbalancer-vm", func() {
+			newImage := func(name, fullVersion string, labels map[string]string) *vmopv1.VirtualMachineImage {
+				img := &vmopv1.VirtualMachineImage{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:   name,
+						Labels: labels,
+					},
+					Spec: vmopv1.VirtualMachineImageSpec{
+						ProductInfo: vmopv1.VirtualMachineImageProductInfo{
+							Product:     "loadbalancer-vm",
+							Version:     "1.0",
+							FullVersion: fullVersion,
+						},
+					},
+				}
+				vmimage.SetProductLabels(img, img.Spec.ProductInfo)
+				return img
+			}
+
+			provider := Provider{
+				client: builder.NewFakeClient(
+					&vmopv1.VirtualMachineImage{ObjectMeta: metav1.ObjectMeta{Name: "loadbalancer-vm-unlabeled"}},
+					newImage("vmi-lb-1", "1.0.1", nil),
+					newImage("vmi-lb-2", "1.0.2", nil),
+					newImage("vmi-lb-3", "1.0.3", map[string]string{vmopv1.VirtualMachineImageDeprecatedLabel: "true"}),
+				),
+				log: logr.Discard(),
+			}
+
+			imageName, err := provider.GetVirtualMachineImageName(context.TODO(), testNs)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(imageName).To(Equal("vmi-lb-2"))
+		})
+
+		It("should fall back to the image whose name contains loadbalancer-vm", func() {
+			provider := Provider{
+				client: builder.NewFakeClient(
+					&vmopv1.VirtualMachineImage{ObjectMeta: metav1.ObjectMeta{Name: "loadbalancer-vm-unlabeled"}},
+				),
+				log: logr.Discard(),
+			}
+
+			imageName, err := provider.GetVirtualMachineImageName(context.TODO(), testNs)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(imageName).To(Equal("loadbalancer-vm-unlabeled"))
+		})
+	})
 })
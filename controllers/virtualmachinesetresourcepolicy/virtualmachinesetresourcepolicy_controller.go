@@ -1,4 +1,4 @@
-// Copyright (c) 2019-2021 VMware, Inc. All Rights Reserved.
+// Copyright (c) 2019-2023 VMware, Inc. All Rights Reserved.
 // SPDX-License-Identifier: Apache-2.0
 
 package virtualmachinesetresourcepolicy
@@ -11,13 +11,21 @@ import (
 	"github.com/go-logr/logr"
 	"github.com/pkg/errors"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
 
 	vmopv1 "github.com/vmware-tanzu/vm-operator/api/v1alpha1"
 
+	"github.com/vmware-tanzu/vm-operator/pkg"
 	"github.com/vmware-tanzu/vm-operator/pkg/context"
+	"github.com/vmware-tanzu/vm-operator/pkg/lib"
 	"github.com/vmware-tanzu/vm-operator/pkg/patch"
 	"github.com/vmware-tanzu/vm-operator/pkg/vmprovider"
 )
@@ -41,9 +49,47 @@ func AddToManager(ctx *context.ControllerManagerContext, mgr manager.Manager) er
 
 	return ctrl.NewControllerManagedBy(mgr).
 		For(controlledType).
+		Watches(&source.Kind{Type: &vmopv1.VirtualMachine{}},
+			handler.EnqueueRequestsFromMapFunc(vmToResourcePolicyMapperFn()),
+			builder.WithPredicates(vmClusterModuleChangedPredicate())).
 		Complete(r)
 }
 
+// vmClusterModuleChangedPredicate returns a predicate that filters out the updates of a VM that
+// do not change its membership of the ClusterModule Groups, so the DRS rules are not updated on
+// every status update of the VM. The mapper function enqueues the resource policies of both the
+// old and new VM.
+func vmClusterModuleChangedPredicate() predicate.Predicate {
+	return predicate.Funcs{
+		UpdateFunc: func(e event.UpdateEvent) bool {
+			oldVM, okOld := e.ObjectOld.(*vmopv1.VirtualMachine)
+			newVM, okNew := e.ObjectNew.(*vmopv1.VirtualMachine)
+			if !okOld || !okNew {
+				return false
+			}
+
+			return oldVM.Annotations[pkg.ClusterModuleNameKey] != newVM.Annotations[pkg.ClusterModuleNameKey] ||
+				oldVM.Spec.ResourcePolicyName != newVM.Spec.ResourcePolicyName ||
+				oldVM.Status.UniqueID != newVM.Status.UniqueID
+		},
+	}
+}
+
+// vmToResourcePolicyMapperFn returns a mapper function that enqueues the resource policy of a VM
+// that is a member of a ClusterModule Group, so the group's DRS rules and members are updated.
+func vmToResourcePolicyMapperFn() func(o client.Object) []reconcile.Request {
+	return func(o client.Object) []reconcile.Request {
+		vm := o.(*vmopv1.VirtualMachine)
+		if vm.Spec.ResourcePolicyName == "" || vm.Annotations[pkg.ClusterModuleNameKey] == "" {
+			return nil
+		}
+
+		return []reconcile.Request{
+			{NamespacedName: client.ObjectKey{Namespace: vm.Namespace, Name: vm.Spec.ResourcePolicyName}},
+		}
+	}
+}
+
 func NewReconciler(
 	client client.Client,
 	logger logr.Logger,
@@ -131,6 +177,7 @@ func (r *Reconciler) ReconcileDelete(ctx *context.VirtualMachineSetResourcePolic
 
 // +kubebuilder:rbac:groups=vmoperator.vmware.com,resources=virtualmachinesetresourcepolicies,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=vmoperator.vmware.com,resources=virtualmachinesetresourcepolicies/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups=vmoperator.vmware.com,resources=virtualmachines,verbs=get;list;watch
 
 func (r *Reconciler) Reconcile(ctx goctx.Context, req ctrl.Request) (_ ctrl.Result, reterr error) {
 	rp := &vmopv1.VirtualMachineSetResourcePolicy{}
@@ -161,5 +208,11 @@ func (r *Reconciler) Reconcile(ctx goctx.Context, req ctrl.Request) (_ ctrl.Resu
 		return ctrl.Result{}, r.ReconcileDelete(rpCtx)
 	}
 
-	return ctrl.Result{}, r.ReconcileNormal(rpCtx)
+	if err := r.ReconcileNormal(rpCtx); err != nil {
+		return ctrl.Result{}, err
+	}
+
+	// Periodically reconcile the resource policy to detect and correct changes made in vCenter, like
+	// edits to the reservations and limits of its ResourcePools.
+	return ctrl.Result{RequeueAfter: lib.GetResourcePolicyDriftCheckInterval()}, nil
 }
@@ -7,6 +7,7 @@ import (
 	goctx "context"
 
 	"fmt"
+	"net"
 	"reflect"
 	"strings"
 	"time"
@@ -23,17 +24,21 @@ import (
 	vmopv1 "github.com/vmware-tanzu/vm-operator/api/v1alpha1"
 
 	"github.com/vmware-tanzu/vm-operator/pkg/context"
+	"github.com/vmware-tanzu/vm-operator/pkg/lib"
 	"github.com/vmware-tanzu/vm-operator/pkg/patch"
 	"github.com/vmware-tanzu/vm-operator/pkg/record"
 	"github.com/vmware-tanzu/vm-operator/pkg/vmprovider"
 )
 
 const (
-	DefaultExpiryTime = time.Second * 120
-	UUIDLabelKey      = "vmoperator.vmware.com/webconsolerequest-uuid"
-
-	ProxyAddrServiceName      = "kube-apiserver-lb-svc"
-	ProxyAddrServiceNamespace = "kube-system"
+	UUIDLabelKey = "vmoperator.vmware.com/webconsolerequest-uuid"
+
+	// RequesterAnnotationKey is set by the mutation webhook to the name of the user that created the
+	// WebConsoleRequest, and is included in the web console access audit logs.
+	RequesterAnnotationKey = "vmoperator.vmware.com/webconsolerequest-requester"
+	// UsedAnnotationKey is set by the web-console validation server when the ticket of the WebConsoleRequest
+	// is used, and single-use tickets are enabled.
+	UsedAnnotationKey = "vmoperator.vmware.com/webconsolerequest-used"
 )
 
 // AddToManager adds this package's controller to the provided manager.
@@ -106,7 +111,7 @@ func (r *Reconciler) Reconcile(ctx goctx.Context, req ctrl.Request) (_ ctrl.Resu
 		return ctrl.Result{}, err
 	}
 	if done {
-		return ctrl.Result{}, nil
+		return ctrl.Result{RequeueAfter: untilExpiry(webconsolerequest)}, nil
 	}
 
 	err = r.Get(ctx, client.ObjectKey{Name: webconsolerequest.Spec.VirtualMachineName, Namespace: webconsolerequest.Namespace}, webConsoleRequestCtx.VM)
@@ -134,7 +139,19 @@ func (r *Reconciler) Reconcile(ctx goctx.Context, req ctrl.Request) (_ ctrl.Resu
 		return ctrl.Result{}, err
 	}
 
-	return ctrl.Result{Requeue: true, RequeueAfter: DefaultExpiryTime}, nil
+	return ctrl.Result{RequeueAfter: untilExpiry(webconsolerequest)}, nil
+}
+
+// untilExpiry returns the duration until the ticket of the WebConsoleRequest expires, when the WebConsoleRequest
+// is requeued to be deleted. Zero is returned if the ticket was not issued or has expired.
+func untilExpiry(wcr *vmopv1.WebConsoleRequest) time.Duration {
+	if wcr.Status.ExpiryTime.IsZero() {
+		return 0
+	}
+	if d := time.Until(wcr.Status.ExpiryTime.Time); d > 0 {
+		return d
+	}
+	return 0
 }
 
 func (r *Reconciler) ReconcileEarlyNormal(ctx *context.WebConsoleRequestContext) (bool, error) {
@@ -150,8 +167,10 @@ func (r *Reconciler) ReconcileEarlyNormal(ctx *context.WebConsoleRequestContext)
 	}
 
 	if ctx.WebConsoleRequest.Status.Response != "" &&
-		ctx.WebConsoleRequest.Status.ProxyAddr != "" {
-		// If the response and proxy address are already set, no need to reconcile anymore
+		ctx.WebConsoleRequest.Status.ProxyAddr != "" &&
+		ctx.WebConsoleRequest.Status.RenewalCount == ctx.WebConsoleRequest.Spec.RenewalCount {
+		// If the response and proxy address are already set, and a renewal is not requested, no need to
+		// reconcile anymore.
 		ctx.Logger.Info("Response and proxy address already set, skip reconciling")
 		return true, nil
 	}
@@ -165,27 +184,43 @@ func (r *Reconciler) ReconcileNormal(ctx *context.WebConsoleRequestContext) erro
 		ctx.Logger.Info("Finished reconciling WebConsoleRequest")
 	}()
 
+	// Renewals are bounded by the maximum lifetime of the request, so a ticket never expires later than the
+	// maximum lifetime after the request was created.
+	var maxExpiryTime time.Time
+	if creationTime := ctx.WebConsoleRequest.CreationTimestamp; !creationTime.IsZero() {
+		maxExpiryTime = creationTime.Add(lib.GetWebConsoleMaxLifetime())
+	}
+	if ctx.WebConsoleRequest.Status.Response != "" && !maxExpiryTime.IsZero() && !time.Now().Before(maxExpiryTime) {
+		ctx.Logger.Info("Not renewing the ticket since the maximum lifetime of the WebConsoleRequest has passed")
+		r.Recorder.Warn(ctx.WebConsoleRequest, "Renewal Rejected", "the maximum lifetime of the WebConsoleRequest has passed")
+		return nil
+	}
+
 	ticket, err := r.VMProvider.GetVirtualMachineWebMKSTicket(ctx, ctx.VM, ctx.WebConsoleRequest.Spec.PublicKey)
 	if err != nil {
 		return errors.Wrapf(err, "failed to get webmksticket")
 	}
 	r.Recorder.EmitEvent(ctx.WebConsoleRequest, "Acquired Ticket", nil, false)
 
+	var requestedDuration time.Duration
+	if ctx.WebConsoleRequest.Spec.Duration != nil {
+		requestedDuration = ctx.WebConsoleRequest.Spec.Duration.Duration
+	}
+
+	expiryTime := time.Now().Add(lib.GetWebConsoleTicketDuration(requestedDuration))
+	if !maxExpiryTime.IsZero() && expiryTime.After(maxExpiryTime) {
+		expiryTime = maxExpiryTime
+	}
+
 	ctx.WebConsoleRequest.Status.Response = ticket
-	ctx.WebConsoleRequest.Status.ExpiryTime = metav1.NewTime(metav1.Now().Add(DefaultExpiryTime))
+	ctx.WebConsoleRequest.Status.ExpiryTime = metav1.NewTime(expiryTime)
+	ctx.WebConsoleRequest.Status.RenewalCount = ctx.WebConsoleRequest.Spec.RenewalCount
 
-	// Retrieve the proxy address from the load balancer service ingress IP.
-	proxySvc := &corev1.Service{}
-	proxySvcObjectKey := client.ObjectKey{Name: ProxyAddrServiceName, Namespace: ProxyAddrServiceNamespace}
-	err = r.Get(ctx, proxySvcObjectKey, proxySvc)
+	proxyAddr, err := GetProxyAddr(ctx, r.Client)
 	if err != nil {
-		return errors.Wrapf(err, "failed to get proxy address service  %s", proxySvcObjectKey)
-	}
-	if len(proxySvc.Status.LoadBalancer.Ingress) == 0 {
-		return errors.Errorf("no ingress found for proxy address service %s", proxySvcObjectKey)
+		return err
 	}
-
-	ctx.WebConsoleRequest.Status.ProxyAddr = proxySvc.Status.LoadBalancer.Ingress[0].IP
+	ctx.WebConsoleRequest.Status.ProxyAddr = proxyAddr
 
 	// Add UUID as a Label to the current WebConsoleRequest resource after acquiring the ticket.
 	// This will be used when validating the connection request from users to the web console URL.
@@ -215,3 +250,41 @@ func (r *Reconciler) ReconcileOwnerReferences(ctx *context.WebConsoleRequestCont
 	ctx.WebConsoleRequest.SetOwnerReferences([]metav1.OwnerReference{ownerRef})
 	return nil
 }
+
+// GetProxyAddr returns the address of the web console proxy from the ingress of the proxy LoadBalancer Service.
+// A hostname is preferred to an IP address. Otherwise the IP address of the Service's primary IP family is
+// preferred, so a dual-stack proxy is reached over the same IP family as the Service.
+func GetProxyAddr(ctx goctx.Context, c client.Client) (string, error) {
+	namespace, name := lib.GetWebConsoleProxyService()
+
+	proxySvc := &corev1.Service{}
+	proxySvcObjectKey := client.ObjectKey{Name: name, Namespace: namespace}
+	if err := c.Get(ctx, proxySvcObjectKey, proxySvc); err != nil {
+		return "", errors.Wrapf(err, "failed to get proxy address service %s", proxySvcObjectKey)
+	}
+
+	var ips []net.IP
+	for _, ingress := range proxySvc.Status.LoadBalancer.Ingress {
+		if ingress.Hostname != "" {
+			return ingress.Hostname, nil
+		}
+		if ip := net.ParseIP(ingress.IP); ip != nil {
+			ips = append(ips, ip)
+		}
+	}
+
+	if len(ips) == 0 {
+		return "", errors.Errorf("no ingress found for proxy address service %s", proxySvcObjectKey)
+	}
+
+	if len(proxySvc.Spec.IPFamilies) > 0 {
+		isIPv6 := proxySvc.Spec.IPFamilies[0] == corev1.IPv6Protocol
+		for _, ip := range ips {
+			if (ip.To4() == nil) == isIPv6 {
+				return ip.String(), nil
+			}
+		}
+	}
+
+	return ips[0].String(), nil
+}
@@ -1,10 +1,11 @@
-// Copyright (c) 2022 VMware, Inc. All Rights Reserved.
+// Copyright (c) 2022-2023 VMware, Inc. All Rights Reserved.
 // SPDX-License-Identifier: Apache-2.0
 
 package webconsolerequest_test
 
 import (
 	"context"
+	"os"
 	"time"
 
 	. "github.com/onsi/ginkgo"
@@ -17,6 +18,7 @@ import (
 	vmopv1 "github.com/vmware-tanzu/vm-operator/api/v1alpha1"
 	"github.com/vmware-tanzu/vm-operator/controllers/webconsolerequest"
 	vmopContext "github.com/vmware-tanzu/vm-operator/pkg/context"
+	"github.com/vmware-tanzu/vm-operator/pkg/lib"
 	providerfake "github.com/vmware-tanzu/vm-operator/pkg/vmprovider/fake"
 	"github.com/vmware-tanzu/vm-operator/test/builder"
 )
@@ -57,14 +59,14 @@ func unitTestsReconcile() {
 
 		proxySvc = &corev1.Service{
 			ObjectMeta: metav1.ObjectMeta{
-				Name:      webconsolerequest.ProxyAddrServiceName,
-				Namespace: webconsolerequest.ProxyAddrServiceNamespace,
+				Name:      lib.DefaultWebConsoleProxyServiceName,
+				Namespace: lib.DefaultWebConsoleProxyServiceNamespace,
 			},
 			Status: corev1.ServiceStatus{
 				LoadBalancer: corev1.LoadBalancerStatus{
 					Ingress: []corev1.LoadBalancerIngress{
 						{
-							IP: "dummy-proxy-ip",
+							IP: "10.0.0.1",
 						},
 					},
 				},
@@ -114,12 +116,160 @@ func unitTestsReconcile() {
 				err := reconciler.ReconcileNormal(wcrCtx)
 				Expect(err).ToNot(HaveOccurred())
 
-				Expect(wcrCtx.WebConsoleRequest.Status.ProxyAddr).To(Equal("dummy-proxy-ip"))
+				Expect(wcrCtx.WebConsoleRequest.Status.ProxyAddr).To(Equal("10.0.0.1"))
 				Expect(wcrCtx.WebConsoleRequest.Status.Response).ToNot(BeEmpty())
-				Expect(wcrCtx.WebConsoleRequest.Status.ExpiryTime.Time).To(BeTemporally("~", time.Now(), webconsolerequest.DefaultExpiryTime))
+				Expect(wcrCtx.WebConsoleRequest.Status.ExpiryTime.Time).To(BeTemporally("~", time.Now(), lib.DefaultWebConsoleTicketDuration))
 				// Checking the label key only because UID will not be set to a resource during unit test.
 				Expect(wcrCtx.WebConsoleRequest.Labels).To(HaveKey(webconsolerequest.UUIDLabelKey))
 			})
 		})
+
+		When("a duration is requested", func() {
+			BeforeEach(func() {
+				wcr.Spec.Duration = &metav1.Duration{Duration: 5 * time.Minute}
+			})
+
+			It("sets the expiry time from the requested duration", func() {
+				Expect(reconciler.ReconcileNormal(wcrCtx)).To(Succeed())
+				Expect(wcrCtx.WebConsoleRequest.Status.ExpiryTime.Time).To(BeTemporally("~", time.Now().Add(5*time.Minute), time.Minute))
+			})
+		})
+
+		When("a duration longer than the maximum is requested", func() {
+			BeforeEach(func() {
+				wcr.Spec.Duration = &metav1.Duration{Duration: 24 * time.Hour}
+			})
+
+			It("sets the expiry time from the maximum duration", func() {
+				Expect(reconciler.ReconcileNormal(wcrCtx)).To(Succeed())
+				Expect(wcrCtx.WebConsoleRequest.Status.ExpiryTime.Time).To(BeTemporally("~", time.Now().Add(lib.DefaultWebConsoleMaxTicketDuration), time.Minute))
+			})
+		})
+
+		When("a renewal is requested", func() {
+			BeforeEach(func() {
+				wcr.Spec.RenewalCount = 2
+				wcr.Status.RenewalCount = 1
+				wcr.Status.Response = "old-ticket"
+				wcr.Status.ProxyAddr = "10.0.0.1"
+				wcr.Status.ExpiryTime = metav1.NewTime(time.Now().Add(time.Minute))
+			})
+
+			It("reissues the ticket", func() {
+				done, err := reconciler.ReconcileEarlyNormal(wcrCtx)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(done).To(BeFalse())
+
+				Expect(reconciler.ReconcileNormal(wcrCtx)).To(Succeed())
+				Expect(wcrCtx.WebConsoleRequest.Status.Response).To(Equal("some-fake-webmksticket"))
+				Expect(wcrCtx.WebConsoleRequest.Status.RenewalCount).To(BeEquivalentTo(2))
+				Expect(wcrCtx.WebConsoleRequest.Status.ExpiryTime.Time).To(BeTemporally("~", time.Now().Add(lib.DefaultWebConsoleTicketDuration), time.Minute))
+
+				done, err = reconciler.ReconcileEarlyNormal(wcrCtx)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(done).To(BeTrue())
+			})
+
+			When("the maximum lifetime ends before the renewed ticket would expire", func() {
+				BeforeEach(func() {
+					wcr.CreationTimestamp = metav1.NewTime(time.Now().Add(-lib.DefaultWebConsoleMaxLifetime + time.Minute))
+				})
+
+				It("expires the ticket at the end of the maximum lifetime", func() {
+					Expect(reconciler.ReconcileNormal(wcrCtx)).To(Succeed())
+					Expect(wcrCtx.WebConsoleRequest.Status.Response).To(Equal("some-fake-webmksticket"))
+					Expect(wcrCtx.WebConsoleRequest.Status.ExpiryTime.Time).To(BeTemporally("~", wcr.CreationTimestamp.Add(lib.DefaultWebConsoleMaxLifetime), time.Second))
+				})
+			})
+
+			When("the maximum lifetime has passed", func() {
+				BeforeEach(func() {
+					wcr.CreationTimestamp = metav1.NewTime(time.Now().Add(-lib.DefaultWebConsoleMaxLifetime - time.Minute))
+				})
+
+				It("does not renew the ticket", func() {
+					Expect(reconciler.ReconcileNormal(wcrCtx)).To(Succeed())
+					Expect(wcrCtx.WebConsoleRequest.Status.Response).To(Equal("old-ticket"))
+					Expect(wcrCtx.WebConsoleRequest.Status.RenewalCount).To(BeEquivalentTo(1))
+				})
+			})
+		})
+	})
+
+	Context("GetProxyAddr", func() {
+		BeforeEach(func() {
+			initObjects = append(initObjects, proxySvc)
+		})
+
+		When("the ingress has a hostname", func() {
+			BeforeEach(func() {
+				proxySvc.Status.LoadBalancer.Ingress = []corev1.LoadBalancerIngress{
+					{IP: "10.0.0.1"},
+					{Hostname: "proxy.example.com"},
+				}
+			})
+
+			It("returns the hostname", func() {
+				Expect(webconsolerequest.GetProxyAddr(ctx, ctx.Client)).To(Equal("proxy.example.com"))
+			})
+		})
+
+		When("the service is dual-stack with IPv6 as the primary IP family", func() {
+			BeforeEach(func() {
+				proxySvc.Spec.IPFamilies = []corev1.IPFamily{corev1.IPv6Protocol, corev1.IPv4Protocol}
+				proxySvc.Status.LoadBalancer.Ingress = []corev1.LoadBalancerIngress{
+					{IP: "10.0.0.1"},
+					{IP: "fd00::1"},
+				}
+			})
+
+			It("returns the IPv6 address", func() {
+				Expect(webconsolerequest.GetProxyAddr(ctx, ctx.Client)).To(Equal("fd00::1"))
+			})
+		})
+
+		When("the service is dual-stack with IPv4 as the primary IP family", func() {
+			BeforeEach(func() {
+				proxySvc.Spec.IPFamilies = []corev1.IPFamily{corev1.IPv4Protocol, corev1.IPv6Protocol}
+				proxySvc.Status.LoadBalancer.Ingress = []corev1.LoadBalancerIngress{
+					{IP: "fd00::1"},
+					{IP: "10.0.0.1"},
+				}
+			})
+
+			It("returns the IPv4 address", func() {
+				Expect(webconsolerequest.GetProxyAddr(ctx, ctx.Client)).To(Equal("10.0.0.1"))
+			})
+		})
+
+		When("the service does not have an ingress", func() {
+			BeforeEach(func() {
+				proxySvc.Status.LoadBalancer.Ingress = nil
+			})
+
+			It("returns an error", func() {
+				_, err := webconsolerequest.GetProxyAddr(ctx, ctx.Client)
+				Expect(err).To(MatchError(ContainSubstring("no ingress found")))
+			})
+		})
+
+		When("the proxy service is configured", func() {
+			BeforeEach(func() {
+				Expect(os.Setenv(lib.WebConsoleProxyServiceEnv, "my-namespace/my-proxy")).To(Succeed())
+				otherSvc := proxySvc.DeepCopy()
+				otherSvc.Namespace = "my-namespace"
+				otherSvc.Name = "my-proxy"
+				otherSvc.Status.LoadBalancer.Ingress = []corev1.LoadBalancerIngress{{IP: "10.0.0.2"}}
+				initObjects = append(initObjects, otherSvc)
+			})
+
+			AfterEach(func() {
+				Expect(os.Unsetenv(lib.WebConsoleProxyServiceEnv)).To(Succeed())
+			})
+
+			It("returns the address of the configured service", func() {
+				Expect(webconsolerequest.GetProxyAddr(ctx, ctx.Client)).To(Equal("10.0.0.2"))
+			})
+		})
 	})
 }
@@ -41,6 +41,11 @@ var (
 
 	defaultSyncPeriod                   = manager.DefaultSyncPeriod
 	defaultMaxConcurrentReconciles      = manager.DefaultMaxConcurrentReconciles
+	defaultVCenterMaxSessions           = manager.DefaultVCenterMaxSessions
+	defaultVCenterCallTimeout           = manager.DefaultVCenterCallTimeout
+	defaultTracingEndpoint              = ""
+	defaultTracingInsecure              = false
+	defaultTracingSampleRatio           = manager.DefaultTracingSampleRatio
 	defaultLeaderElectionID             = manager.DefaultLeaderElectionID
 	defaultPodNamespace                 = manager.DefaultPodNamespace
 	defaultPodName                      = manager.DefaultPodName
@@ -78,6 +83,19 @@ func init() {
 	if v, err := strconv.Atoi(os.Getenv("MAX_CONCURRENT_RECONCILES")); err == nil {
 		defaultMaxConcurrentReconciles = v
 	}
+	if v, err := strconv.Atoi(os.Getenv("VCENTER_MAX_SESSIONS")); err == nil {
+		defaultVCenterMaxSessions = v
+	}
+	if v, err := time.ParseDuration(os.Getenv("VCENTER_CALL_TIMEOUT")); err == nil {
+		defaultVCenterCallTimeout = v
+	}
+	if v := os.Getenv("TRACING_ENDPOINT"); v != "" {
+		defaultTracingEndpoint = v
+	}
+	defaultTracingInsecure, _ = strconv.ParseBool(os.Getenv("TRACING_INSECURE"))
+	if v, err := strconv.ParseFloat(os.Getenv("TRACING_SAMPLE_RATIO"), 64); err == nil {
+		defaultTracingSampleRatio = v
+	}
 	if v := os.Getenv("LEADER_ELECTION_ID"); v != "" {
 		defaultLeaderElectionID = v
 	}
@@ -174,6 +192,31 @@ func main() {
 		"max-concurrent-reconciles",
 		defaultMaxConcurrentReconciles,
 		"The maximum number of allowed, concurrent reconciles.")
+	flag.IntVar(
+		&managerOpts.VCenterMaxSessions,
+		"vcenter-max-sessions",
+		defaultVCenterMaxSessions,
+		"The maximum number of sessions to each vCenter.")
+	flag.DurationVar(
+		&managerOpts.VCenterCallTimeout,
+		"vcenter-call-timeout",
+		defaultVCenterCallTimeout,
+		"The timeout of each call to vCenter.")
+	flag.StringVar(
+		&managerOpts.TracingEndpoint,
+		"tracing-endpoint",
+		defaultTracingEndpoint,
+		"The host:port of the OTLP gRPC collector the trace spans are exported to. Tracing is disabled if unspecified.")
+	flag.BoolVar(
+		&managerOpts.TracingInsecure,
+		"tracing-insecure",
+		defaultTracingInsecure,
+		"Disable TLS on the connection to the trace collector.")
+	flag.Float64Var(
+		&managerOpts.TracingSampleRatio,
+		"tracing-sample-ratio",
+		defaultTracingSampleRatio,
+		"The ratio of the traces that are sampled, from 0 to 1.")
 	flag.StringVar(
 		&managerOpts.PodNamespace,
 		"pod-namespace",
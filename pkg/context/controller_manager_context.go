@@ -1,4 +1,4 @@
-// Copyright (c) 2019 VMware, Inc. All Rights Reserved.
+// Copyright (c) 2019-2023 VMware, Inc. All Rights Reserved.
 // SPDX-License-Identifier: Apache-2.0
 
 package context
@@ -72,6 +72,12 @@ type ControllerManagerContext struct {
 	// responsiveness to change if there are many watched resources.
 	SyncPeriod time.Duration
 
+	// VCenterMaxSessions is the maximum number of sessions to each vCenter.
+	VCenterMaxSessions int
+
+	// VCenterCallTimeout is the timeout of each call to vCenter.
+	VCenterCallTimeout time.Duration
+
 	// VMProvider is the controller manager's VM Provider
 	VMProvider vmprovider.VirtualMachineProviderInterface
 }
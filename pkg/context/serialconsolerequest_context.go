@@ -0,0 +1,25 @@
+// Copyright (c) 2023 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package context
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/go-logr/logr"
+
+	vmopv1 "github.com/vmware-tanzu/vm-operator/api/v1alpha1"
+)
+
+// SerialConsoleRequestContext is the context used for SerialConsoleRequestControllers.
+type SerialConsoleRequestContext struct {
+	context.Context
+	Logger               logr.Logger
+	SerialConsoleRequest *vmopv1.SerialConsoleRequest
+	VM                   *vmopv1.VirtualMachine
+}
+
+func (v *SerialConsoleRequestContext) String() string {
+	return fmt.Sprintf("%s %s/%s", v.SerialConsoleRequest.GroupVersionKind(), v.SerialConsoleRequest.Namespace, v.SerialConsoleRequest.Name)
+}
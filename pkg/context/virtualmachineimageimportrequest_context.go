@@ -0,0 +1,27 @@
+// Copyright (c) 2023 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package context
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/go-logr/logr"
+
+	vmopv1 "github.com/vmware-tanzu/vm-operator/api/v1alpha1"
+
+	imgregv1a1 "github.com/vmware-tanzu/vm-operator/external/image-registry/api/v1alpha1"
+)
+
+// VirtualMachineImageImportRequestContext is the context used for VirtualMachineImageImportRequestControllers.
+type VirtualMachineImageImportRequestContext struct {
+	context.Context
+	Logger               logr.Logger
+	VMImageImportRequest *vmopv1.VirtualMachineImageImportRequest
+	ContentLibrary       *imgregv1a1.ContentLibrary
+}
+
+func (v *VirtualMachineImageImportRequestContext) String() string {
+	return fmt.Sprintf("%s %s/%s", v.VMImageImportRequest.GroupVersionKind(), v.VMImageImportRequest.Namespace, v.VMImageImportRequest.Name)
+}
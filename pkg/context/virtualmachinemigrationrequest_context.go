@@ -0,0 +1,25 @@
+// Copyright (c) 2023 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package context
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/go-logr/logr"
+
+	vmopv1 "github.com/vmware-tanzu/vm-operator/api/v1alpha1"
+)
+
+// VirtualMachineMigrationRequestContext is the context used for VirtualMachineMigrationRequestControllers.
+type VirtualMachineMigrationRequestContext struct {
+	context.Context
+	Logger             logr.Logger
+	VMMigrationRequest *vmopv1.VirtualMachineMigrationRequest
+	VM                 *vmopv1.VirtualMachine
+}
+
+func (v *VirtualMachineMigrationRequestContext) String() string {
+	return fmt.Sprintf("%s %s/%s", v.VMMigrationRequest.GroupVersionKind(), v.VMMigrationRequest.Namespace, v.VMMigrationRequest.Name)
+}
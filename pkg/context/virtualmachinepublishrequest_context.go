@@ -12,6 +12,8 @@ import (
 	vmopv1 "github.com/vmware-tanzu/vm-operator/api/v1alpha1"
 
 	imgregv1a1 "github.com/vmware-tanzu/vm-operator/external/image-registry/api/v1alpha1"
+
+	"github.com/vmware-tanzu/vm-operator/pkg/ociregistry"
 )
 
 // VirtualMachinePublishRequestContext is the context used for VirtualMachinePublishRequestControllers.
@@ -22,6 +24,8 @@ type VirtualMachinePublishRequestContext struct {
 	VM               *vmopv1.VirtualMachine
 	ContentLibrary   *imgregv1a1.ContentLibrary
 	ItemID           string
+	// OCIRegistry is the client for the target registry when publishing to an OCI registry.
+	OCIRegistry *ociregistry.Client
 	// SkipPatch indicates whether we should skip patching the object after reconcile
 	// because Status is updated separately in the publishing case due to CL API limitations.
 	SkipPatch bool
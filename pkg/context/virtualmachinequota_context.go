@@ -0,0 +1,24 @@
+// Copyright (c) 2023 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package context
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/go-logr/logr"
+
+	vmopv1 "github.com/vmware-tanzu/vm-operator/api/v1alpha1"
+)
+
+// VirtualMachineQuotaContext is the context used for VirtualMachineQuotaControllers.
+type VirtualMachineQuotaContext struct {
+	context.Context
+	Logger  logr.Logger
+	VMQuota *vmopv1.VirtualMachineQuota
+}
+
+func (v *VirtualMachineQuotaContext) String() string {
+	return fmt.Sprintf("%s %s/%s", v.VMQuota.GroupVersionKind(), v.VMQuota.Namespace, v.VMQuota.Name)
+}
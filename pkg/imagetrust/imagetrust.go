@@ -0,0 +1,257 @@
+// Copyright (c) 2023 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+// Package imagetrust verifies the provenance of OVF images: the checksums of
+// an image's files against its OVF manifest, and a detached signature of the
+// manifest against the public keys of the image trust policy.
+package imagetrust
+
+import (
+	"bufio"
+	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/sha1" //nolint:gosec // SHA1 is still used by OVF manifests.
+	"crypto/sha256"
+	"crypto/sha512"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/pem"
+	"fmt"
+	"hash"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+)
+
+const (
+	// PublicKeySuffix is the suffix of the keys of the image trust policy Secret
+	// whose values are PEM encoded public keys, such as a cosign.pub.
+	PublicKeySuffix = ".pub"
+
+	// ManifestExtension is the extension of an OVF manifest file.
+	ManifestExtension = ".mf"
+
+	// SignatureExtension is the extension of the file with the detached
+	// signature of an OVF manifest.
+	SignatureExtension = ".sig"
+)
+
+// VerificationError is returned when an image fails verification, as opposed
+// to an error that prevented the image from being verified.
+type VerificationError struct {
+	Message string
+}
+
+func (e *VerificationError) Error() string {
+	return e.Message
+}
+
+// NewVerificationError returns a VerificationError with the formatted message.
+func NewVerificationError(format string, args ...interface{}) error {
+	return &VerificationError{Message: fmt.Sprintf(format, args...)}
+}
+
+// IsVerificationError returns true if the error, or an error it wraps, is a
+// VerificationError.
+func IsVerificationError(err error) bool {
+	var verErr *VerificationError
+	return errors.As(err, &verErr)
+}
+
+// Policy is the image trust policy.
+type Policy struct {
+	// PublicKeys are the keys that may have signed an OVF manifest. A
+	// signature is valid if it was made by any of them.
+	PublicKeys []crypto.PublicKey
+}
+
+// PolicyFromSecret returns the Policy with the public keys in the Secret. Each
+// Secret key with the PublicKeySuffix holds one or more PEM encoded PKIX
+// public keys. ECDSA, RSA and Ed25519 keys are supported.
+func PolicyFromSecret(secret *corev1.Secret) (*Policy, error) {
+	names := make([]string, 0, len(secret.Data))
+	for name := range secret.Data {
+		if strings.HasSuffix(name, PublicKeySuffix) {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	policy := &Policy{}
+	for _, name := range names {
+		keys, err := parsePublicKeys(secret.Data[name])
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid public key %s in Secret %s/%s", name, secret.Namespace, secret.Name)
+		}
+		policy.PublicKeys = append(policy.PublicKeys, keys...)
+	}
+
+	if len(policy.PublicKeys) == 0 {
+		return nil, errors.Errorf("secret %s/%s does not contain any %s public keys",
+			secret.Namespace, secret.Name, PublicKeySuffix)
+	}
+
+	return policy, nil
+}
+
+// Hash returns the SHA256 hash of the policy's public keys. It does not
+// depend on the order of the keys, so it only changes when a key is added,
+// removed or replaced.
+func (p *Policy) Hash() string {
+	keys := make([]string, 0, len(p.PublicKeys))
+	for _, key := range p.PublicKeys {
+		der, err := x509.MarshalPKIXPublicKey(key)
+		if err != nil {
+			// The keys are parsed from PKIX, so they can always be marshaled back.
+			continue
+		}
+		sum := sha256.Sum256(der)
+		keys = append(keys, hex.EncodeToString(sum[:]))
+	}
+	sort.Strings(keys)
+
+	sum := sha256.Sum256([]byte(strings.Join(keys, "\n")))
+	return hex.EncodeToString(sum[:])
+}
+
+func parsePublicKeys(data []byte) ([]crypto.PublicKey, error) {
+	var keys []crypto.PublicKey
+	for {
+		var block *pem.Block
+		block, data = pem.Decode(data)
+		if block == nil {
+			break
+		}
+		if block.Type != "PUBLIC KEY" {
+			return nil, errors.Errorf("unsupported PEM block type %q", block.Type)
+		}
+
+		key, err := x509.ParsePKIXPublicKey(block.Bytes)
+		if err != nil {
+			return nil, err
+		}
+		switch key.(type) {
+		case *ecdsa.PublicKey, *rsa.PublicKey, ed25519.PublicKey:
+		default:
+			return nil, errors.Errorf("unsupported public key type %T", key)
+		}
+		keys = append(keys, key)
+	}
+
+	if len(keys) == 0 {
+		return nil, errors.New("no PEM encoded public key found")
+	}
+	return keys, nil
+}
+
+// VerifySignature verifies the detached signature of the message was made by
+// one of the policy's public keys. Like cosign, the signature may be base64
+// encoded, and ECDSA and RSA signatures are over the SHA256 digest of the
+// message.
+func (p *Policy) VerifySignature(message, signature []byte) error {
+	sig := signature
+	if decoded, err := base64.StdEncoding.DecodeString(string(bytes.TrimSpace(signature))); err == nil {
+		sig = decoded
+	}
+
+	digest := sha256.Sum256(message)
+	for _, key := range p.PublicKeys {
+		switch k := key.(type) {
+		case *ecdsa.PublicKey:
+			if ecdsa.VerifyASN1(k, digest[:], sig) {
+				return nil
+			}
+		case *rsa.PublicKey:
+			if rsa.VerifyPKCS1v15(k, crypto.SHA256, digest[:], sig) == nil {
+				return nil
+			}
+			if rsa.VerifyPSS(k, crypto.SHA256, digest[:], sig, nil) == nil {
+				return nil
+			}
+		case ed25519.PublicKey:
+			if ed25519.Verify(k, message, sig) {
+				return nil
+			}
+		}
+	}
+
+	return NewVerificationError("signature is not valid for any of the %d trusted public keys", len(p.PublicKeys))
+}
+
+// Digest is the checksum of a file listed in an OVF manifest.
+type Digest struct {
+	// Algorithm is the upper case name of the hash algorithm, e.g. SHA256.
+	Algorithm string
+	// Value is the lower case hex encoded checksum.
+	Value string
+}
+
+// Manifest maps the names of the files of an OVF to their digests.
+type Manifest map[string]Digest
+
+var manifestLineRegex = regexp.MustCompile(`^\s*([A-Za-z0-9]+)\s*\((.+)\)\s*=\s*([0-9A-Fa-f]+)\s*$`)
+
+// ParseManifest parses an OVF manifest, which has a line with the digest of
+// each file in the form "SHA256(file.ovf)= <hex>".
+func ParseManifest(data []byte) (Manifest, error) {
+	manifest := Manifest{}
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line := scanner.Text()
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+
+		m := manifestLineRegex.FindStringSubmatch(line)
+		if m == nil {
+			return nil, NewVerificationError("invalid OVF manifest line %q", line)
+		}
+
+		algorithm := strings.ToUpper(m[1])
+		if _, err := NewHash(algorithm); err != nil {
+			return nil, NewVerificationError("unsupported digest algorithm %s for file %s in OVF manifest", m[1], m[2])
+		}
+		manifest[m[2]] = Digest{Algorithm: algorithm, Value: strings.ToLower(m[3])}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	if len(manifest) == 0 {
+		return nil, NewVerificationError("OVF manifest is empty")
+	}
+	return manifest, nil
+}
+
+// NewHash returns a hash for the manifest digest algorithm.
+func NewHash(algorithm string) (hash.Hash, error) {
+	switch strings.ToUpper(algorithm) {
+	case "SHA1":
+		return sha1.New(), nil //nolint:gosec // SHA1 is still used by OVF manifests.
+	case "SHA256":
+		return sha256.New(), nil
+	case "SHA512":
+		return sha512.New(), nil
+	default:
+		return nil, errors.Errorf("unsupported digest algorithm %s", algorithm)
+	}
+}
+
+// Matches returns true if the hex encoded checksum computed with the same
+// algorithm matches the digest.
+func (d Digest) Matches(algorithm, checksum string) bool {
+	return strings.EqualFold(d.Algorithm, algorithm) && strings.EqualFold(d.Value, checksum)
+}
+
+// Sum returns the hex encoded checksum of the hash.
+func Sum(h hash.Hash) string {
+	return hex.EncodeToString(h.Sum(nil))
+}
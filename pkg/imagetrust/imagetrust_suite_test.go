@@ -0,0 +1,16 @@
+// Copyright (c) 2023 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package imagetrust_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+func TestImageTrust(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Image Trust Test Suite")
+}
@@ -0,0 +1,244 @@
+// Copyright (c) 2023 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package imagetrust_test
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/pem"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/vmware-tanzu/vm-operator/pkg/imagetrust"
+)
+
+func publicKeyPEM(key crypto.PublicKey) []byte {
+	der, err := x509.MarshalPKIXPublicKey(key)
+	Expect(err).ToNot(HaveOccurred())
+	return pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der})
+}
+
+func policySecret(data map[string][]byte) *corev1.Secret {
+	return &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "image-trust-policy",
+			Namespace: "vmop-system",
+		},
+		Data: data,
+	}
+}
+
+var _ = Describe("PolicyFromSecret", func() {
+
+	var (
+		ecdsaKey *ecdsa.PrivateKey
+		rsaKey   *rsa.PrivateKey
+		edKey    ed25519.PublicKey
+	)
+
+	BeforeEach(func() {
+		var err error
+		ecdsaKey, err = ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		Expect(err).ToNot(HaveOccurred())
+		rsaKey, err = rsa.GenerateKey(rand.Reader, 2048)
+		Expect(err).ToNot(HaveOccurred())
+		edKey, _, err = ed25519.GenerateKey(rand.Reader)
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	It("returns the public keys of all the .pub keys", func() {
+		ecdsaAndRSA := append(publicKeyPEM(&ecdsaKey.PublicKey), publicKeyPEM(&rsaKey.PublicKey)...)
+		policy, err := imagetrust.PolicyFromSecret(policySecret(map[string][]byte{
+			"cosign.pub":  ecdsaAndRSA,
+			"ed25519.pub": publicKeyPEM(edKey),
+			"README":      []byte("not a key"),
+		}))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(policy.PublicKeys).To(HaveLen(3))
+	})
+
+	It("returns an error when there are no public keys", func() {
+		_, err := imagetrust.PolicyFromSecret(policySecret(map[string][]byte{"README": []byte("not a key")}))
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("does not contain any .pub public keys"))
+	})
+
+	It("returns an error when a public key is invalid", func() {
+		_, err := imagetrust.PolicyFromSecret(policySecret(map[string][]byte{"cosign.pub": []byte("not a key")}))
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("invalid public key cosign.pub"))
+	})
+
+	It("returns an error when a PEM block is not a public key", func() {
+		der, err := x509.MarshalECPrivateKey(ecdsaKey)
+		Expect(err).ToNot(HaveOccurred())
+		privatePEM := pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: der})
+
+		_, err = imagetrust.PolicyFromSecret(policySecret(map[string][]byte{"cosign.pub": privatePEM}))
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("unsupported PEM block type"))
+	})
+
+	It("hashes the public keys regardless of their order", func() {
+		policy, err := imagetrust.PolicyFromSecret(policySecret(map[string][]byte{
+			"a.pub": publicKeyPEM(&ecdsaKey.PublicKey),
+			"b.pub": publicKeyPEM(&rsaKey.PublicKey),
+		}))
+		Expect(err).ToNot(HaveOccurred())
+		reordered, err := imagetrust.PolicyFromSecret(policySecret(map[string][]byte{
+			"a.pub": publicKeyPEM(&rsaKey.PublicKey),
+			"b.pub": publicKeyPEM(&ecdsaKey.PublicKey),
+		}))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(policy.Hash()).To(Equal(reordered.Hash()))
+
+		rotated, err := imagetrust.PolicyFromSecret(policySecret(map[string][]byte{
+			"a.pub": publicKeyPEM(&ecdsaKey.PublicKey),
+			"b.pub": publicKeyPEM(edKey),
+		}))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(rotated.Hash()).ToNot(Equal(policy.Hash()))
+	})
+})
+
+var _ = Describe("VerifySignature", func() {
+
+	var (
+		message  = []byte("SHA256(photon.ovf)= abcd\n")
+		ecdsaKey *ecdsa.PrivateKey
+		rsaKey   *rsa.PrivateKey
+		edPub    ed25519.PublicKey
+		edKey    ed25519.PrivateKey
+		policy   *imagetrust.Policy
+	)
+
+	BeforeEach(func() {
+		var err error
+		ecdsaKey, err = ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		Expect(err).ToNot(HaveOccurred())
+		rsaKey, err = rsa.GenerateKey(rand.Reader, 2048)
+		Expect(err).ToNot(HaveOccurred())
+		edPub, edKey, err = ed25519.GenerateKey(rand.Reader)
+		Expect(err).ToNot(HaveOccurred())
+
+		policy = &imagetrust.Policy{
+			PublicKeys: []crypto.PublicKey{&ecdsaKey.PublicKey, &rsaKey.PublicKey, edPub},
+		}
+	})
+
+	It("verifies a base64 encoded ECDSA signature", func() {
+		digest := sha256.Sum256(message)
+		sig, err := ecdsa.SignASN1(rand.Reader, ecdsaKey, digest[:])
+		Expect(err).ToNot(HaveOccurred())
+
+		encoded := base64.StdEncoding.EncodeToString(sig) + "\n"
+		Expect(policy.VerifySignature(message, []byte(encoded))).To(Succeed())
+	})
+
+	It("verifies a raw RSA signature", func() {
+		digest := sha256.Sum256(message)
+		sig, err := rsa.SignPKCS1v15(rand.Reader, rsaKey, crypto.SHA256, digest[:])
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(policy.VerifySignature(message, sig)).To(Succeed())
+	})
+
+	It("verifies an RSA-PSS signature", func() {
+		digest := sha256.Sum256(message)
+		sig, err := rsa.SignPSS(rand.Reader, rsaKey, crypto.SHA256, digest[:], nil)
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(policy.VerifySignature(message, sig)).To(Succeed())
+	})
+
+	It("verifies an Ed25519 signature", func() {
+		sig := ed25519.Sign(edKey, message)
+		Expect(policy.VerifySignature(message, sig)).To(Succeed())
+	})
+
+	It("returns a verification error when the message was modified", func() {
+		digest := sha256.Sum256(message)
+		sig, err := ecdsa.SignASN1(rand.Reader, ecdsaKey, digest[:])
+		Expect(err).ToNot(HaveOccurred())
+
+		err = policy.VerifySignature([]byte("SHA256(photon.ovf)= ef01\n"), sig)
+		Expect(err).To(HaveOccurred())
+		Expect(imagetrust.IsVerificationError(err)).To(BeTrue())
+	})
+
+	It("returns a verification error when signed by an untrusted key", func() {
+		otherKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		Expect(err).ToNot(HaveOccurred())
+		digest := sha256.Sum256(message)
+		sig, err := ecdsa.SignASN1(rand.Reader, otherKey, digest[:])
+		Expect(err).ToNot(HaveOccurred())
+
+		err = policy.VerifySignature(message, sig)
+		Expect(err).To(HaveOccurred())
+		Expect(imagetrust.IsVerificationError(err)).To(BeTrue())
+	})
+})
+
+var _ = Describe("ParseManifest", func() {
+
+	It("parses the digests of the files", func() {
+		manifest, err := imagetrust.ParseManifest([]byte(
+			"SHA256(photon.ovf)= ABCD\n" +
+				"\n" +
+				"SHA1 (photon-disk1.vmdk) = 0123\r\n"))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(manifest).To(HaveLen(2))
+		Expect(manifest["photon.ovf"]).To(Equal(imagetrust.Digest{Algorithm: "SHA256", Value: "abcd"}))
+		Expect(manifest["photon-disk1.vmdk"]).To(Equal(imagetrust.Digest{Algorithm: "SHA1", Value: "0123"}))
+	})
+
+	DescribeTable("invalid manifests",
+		func(data, expectedErr string) {
+			_, err := imagetrust.ParseManifest([]byte(data))
+			Expect(err).To(HaveOccurred())
+			Expect(imagetrust.IsVerificationError(err)).To(BeTrue())
+			Expect(err.Error()).To(ContainSubstring(expectedErr))
+		},
+		Entry("empty", "\n", "OVF manifest is empty"),
+		Entry("invalid line", "photon.ovf abcd\n", "invalid OVF manifest line"),
+		Entry("unsupported algorithm", "MD5(photon.ovf)= abcd\n", "unsupported digest algorithm MD5"),
+	)
+})
+
+var _ = Describe("Digest", func() {
+
+	It("matches checksums of the same algorithm", func() {
+		digest := imagetrust.Digest{Algorithm: "SHA256", Value: "abcd"}
+		Expect(digest.Matches("sha256", "ABCD")).To(BeTrue())
+		Expect(digest.Matches("SHA1", "abcd")).To(BeFalse())
+		Expect(digest.Matches("SHA256", "ef01")).To(BeFalse())
+	})
+
+	It("is computed by the hash of the algorithm", func() {
+		h, err := imagetrust.NewHash("SHA256")
+		Expect(err).ToNot(HaveOccurred())
+		_, _ = h.Write([]byte("photon"))
+
+		sum := sha256.Sum256([]byte("photon"))
+		Expect(imagetrust.Sum(h)).To(Equal(hex.EncodeToString(sum[:])))
+	})
+
+	It("returns an error for an unsupported algorithm", func() {
+		_, err := imagetrust.NewHash("MD5")
+		Expect(err).To(HaveOccurred())
+	})
+})
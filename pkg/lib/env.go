@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"os"
 	"strconv"
+	"strings"
 	"time"
 
 	"k8s.io/apimachinery/pkg/util/wait"
@@ -27,6 +28,11 @@ const (
 	MaxCreateVMsOnProviderEnv     = "MAX_CREATE_VMS_ON_PROVIDER"
 	DefaultMaxCreateVMsOnProvider = 80
 
+	// MaxConcurrentPublishesOnProviderEnv is the maximum number of VMs that are published concurrently.
+	MaxConcurrentPublishesOnProviderEnv = "MAX_CONCURRENT_PUBLISHES_ON_PROVIDER"
+	// DefaultMaxConcurrentPublishesOnProvider is the default maximum number of VMs that are published concurrently.
+	DefaultMaxConcurrentPublishesOnProvider = 4
+
 	InstanceStoragePVPlacementFailedTTLEnv = "INSTANCE_STORAGE_PV_PLACEMENT_FAILED_TTL"
 	// DefaultInstanceStoragePVPlacementFailedTTL is the default wait time before declaring PV placement failed
 	// after error annotation is set on PVC.
@@ -54,6 +60,44 @@ const (
 	NetworkProviderTypeNamed = "NAMED"
 	NetworkProviderTypeNSXT  = "NSXT"
 	NetworkProviderTypeVDS   = "VSPHERE_NETWORK"
+
+	// ImageTrustPolicySecretEnv is the name of the Secret, in the VM Operator pod's namespace, that holds the
+	// public keys of the image trust policy. The image trust policy is disabled when this is not set.
+	ImageTrustPolicySecretEnv = "VM_IMAGE_TRUST_POLICY_SECRET"
+
+	// ResourcePolicyDriftCheckIntervalEnv is the interval at which the ResourcePools, Folders and
+	// ClusterModules of the VirtualMachineSetResourcePolicies are checked for changes made in vCenter.
+	ResourcePolicyDriftCheckIntervalEnv = "RESOURCE_POLICY_DRIFT_CHECK_INTERVAL"
+	// DefaultResourcePolicyDriftCheckInterval is the default interval at which the VirtualMachineSetResourcePolicies
+	// are checked for changes made in vCenter.
+	DefaultResourcePolicyDriftCheckInterval = 5 * time.Minute
+
+	// WebConsoleTicketDurationEnv is the lifetime of a WebConsoleRequest ticket when the request does not
+	// specify a duration.
+	WebConsoleTicketDurationEnv = "WEB_CONSOLE_TICKET_DURATION"
+	// DefaultWebConsoleTicketDuration is the default lifetime of a WebConsoleRequest ticket.
+	DefaultWebConsoleTicketDuration = 120 * time.Second
+	// WebConsoleMaxTicketDurationEnv is the maximum lifetime of a WebConsoleRequest ticket. A longer requested
+	// duration is reduced to this value.
+	WebConsoleMaxTicketDurationEnv = "WEB_CONSOLE_MAX_TICKET_DURATION"
+	// DefaultWebConsoleMaxTicketDuration is the default maximum lifetime of a WebConsoleRequest ticket.
+	DefaultWebConsoleMaxTicketDuration = 10 * time.Minute
+	// WebConsoleMaxLifetimeEnv is the maximum lifetime of a WebConsoleRequest, including its renewals. A ticket
+	// does not expire later than this duration after the WebConsoleRequest was created.
+	WebConsoleMaxLifetimeEnv = "WEB_CONSOLE_MAX_LIFETIME"
+	// DefaultWebConsoleMaxLifetime is the default maximum lifetime of a WebConsoleRequest.
+	DefaultWebConsoleMaxLifetime = time.Hour
+	// WebConsoleProxyServiceEnv is the <namespace>/<name> of the LoadBalancer Service whose ingress is the
+	// address of the web console proxy.
+	WebConsoleProxyServiceEnv = "WEB_CONSOLE_PROXY_SERVICE"
+	// DefaultWebConsoleProxyServiceNamespace is the default namespace of the web console proxy Service.
+	DefaultWebConsoleProxyServiceNamespace = "kube-system"
+	// DefaultWebConsoleProxyServiceName is the default name of the web console proxy Service.
+	DefaultWebConsoleProxyServiceName = "kube-apiserver-lb-svc"
+	// SerialConsoleVSPCURIEnv is the URI, such as telnet://<address>:<port>, at which the ESXi hosts reach the
+	// virtual serial port concentrator of the web-console-validator. Serial consoles are not available when
+	// it is not set.
+	SerialConsoleVSPCURIEnv = "SERIAL_CONSOLE_VSPC_URI"
 )
 
 // SetVMOpNamespaceEnv sets the VM Operator pod's namespace in the environment.
@@ -110,6 +154,28 @@ var IsNamespacedClassAndWindowsFSSEnabled = func() bool {
 	return os.Getenv(NamespacedClassAndWindowsFSS) == trueString
 }
 
+// GetImageTrustPolicySecretName returns the name of the Secret that holds the image trust policy, or an
+// empty string if the image trust policy is disabled.
+var GetImageTrustPolicySecretName = func() string {
+	return os.Getenv(ImageTrustPolicySecretEnv)
+}
+
+// IsImageTrustPolicyEnabled returns true if images must be verified against the image trust policy
+// before VMs can be deployed from them.
+var IsImageTrustPolicyEnabled = func() bool {
+	return GetImageTrustPolicySecretName() != ""
+}
+
+// CheckImageTrustPolicy returns an error if the image trust policy is configured but cannot be enforced.
+// Only the images of the image registry are verified, so the policy requires the image registry FSS.
+func CheckImageTrustPolicy() error {
+	if IsImageTrustPolicyEnabled() && !IsWCPVMImageRegistryEnabled() {
+		return fmt.Errorf("%s is set but the image trust policy requires %s to be enabled",
+			ImageTrustPolicySecretEnv, VMImageRegistryFSS)
+	}
+	return nil
+}
+
 // MaxConcurrentCreateVMsOnProvider returns the percentage of reconciler
 // threads that can be used to create VMs on the provider concurrently. The
 // default is 80.
@@ -128,6 +194,15 @@ var MaxConcurrentCreateVMsOnProvider = func() int {
 	return val
 }
 
+// MaxConcurrentPublishesOnProvider returns the maximum number of VMs that are
+// published on the provider concurrently. The default is 4.
+func MaxConcurrentPublishesOnProvider() int {
+	if v, err := strconv.Atoi(os.Getenv(MaxConcurrentPublishesOnProviderEnv)); err == nil && v > 0 {
+		return v
+	}
+	return DefaultMaxConcurrentPublishesOnProvider
+}
+
 // GetInstanceStoragePVPlacementFailedTTL returns the configured wait time before declaring PV placement
 // failed after error annotation is set on PVC.
 func GetInstanceStoragePVPlacementFailedTTL() time.Duration {
@@ -139,6 +214,67 @@ func GetInstanceStoragePVPlacementFailedTTL() time.Duration {
 	return DefaultInstanceStoragePVPlacementFailedTTL
 }
 
+// GetResourcePolicyDriftCheckInterval returns the configured interval at which the VirtualMachineSetResourcePolicies
+// are checked for changes made in vCenter.
+func GetResourcePolicyDriftCheckInterval() time.Duration {
+	if interval := os.Getenv(ResourcePolicyDriftCheckIntervalEnv); len(interval) > 0 {
+		if duration, err := time.ParseDuration(interval); err == nil && duration > 0 {
+			return duration
+		}
+	}
+	return DefaultResourcePolicyDriftCheckInterval
+}
+
+// GetWebConsoleMaxTicketDuration returns the configured maximum lifetime of a WebConsoleRequest ticket.
+func GetWebConsoleMaxTicketDuration() time.Duration {
+	if v := os.Getenv(WebConsoleMaxTicketDurationEnv); len(v) > 0 {
+		if duration, err := time.ParseDuration(v); err == nil && duration > 0 {
+			return duration
+		}
+	}
+	return DefaultWebConsoleMaxTicketDuration
+}
+
+// GetWebConsoleMaxLifetime returns the maximum lifetime of a WebConsoleRequest, including its renewals.
+func GetWebConsoleMaxLifetime() time.Duration {
+	if v := os.Getenv(WebConsoleMaxLifetimeEnv); len(v) > 0 {
+		if duration, err := time.ParseDuration(v); err == nil && duration > 0 {
+			return duration
+		}
+	}
+	return DefaultWebConsoleMaxLifetime
+}
+
+// GetWebConsoleTicketDuration returns the lifetime of a WebConsoleRequest ticket. The requested duration is used
+// when it is positive, otherwise the configured default is used. The lifetime is at most the configured maximum.
+func GetWebConsoleTicketDuration(requested time.Duration) time.Duration {
+	duration := DefaultWebConsoleTicketDuration
+	if v := os.Getenv(WebConsoleTicketDurationEnv); len(v) > 0 {
+		if d, err := time.ParseDuration(v); err == nil && d > 0 {
+			duration = d
+		}
+	}
+	if requested > 0 {
+		duration = requested
+	}
+
+	if maxDuration := GetWebConsoleMaxTicketDuration(); duration > maxDuration {
+		return maxDuration
+	}
+	return duration
+}
+
+// GetWebConsoleProxyService returns the namespace and name of the LoadBalancer Service whose ingress is the
+// address of the web console proxy.
+func GetWebConsoleProxyService() (string, string) {
+	if v := os.Getenv(WebConsoleProxyServiceEnv); len(v) > 0 {
+		if namespace, name, ok := strings.Cut(v, "/"); ok && namespace != "" && name != "" {
+			return namespace, name
+		}
+	}
+	return DefaultWebConsoleProxyServiceNamespace, DefaultWebConsoleProxyServiceName
+}
+
 // GetInstanceStorageRequeueDelay returns requeue delay for instance storage.
 func GetInstanceStorageRequeueDelay() time.Duration {
 	maxFactor := DefaultInstanceStorageJitterMaxFactor
@@ -157,3 +293,9 @@ func GetInstanceStorageRequeueDelay() time.Duration {
 
 	return wait.Jitter(seedDuration, maxFactor)
 }
+
+// GetSerialConsoleVSPCURI returns the URI at which the ESXi hosts reach the virtual serial port concentrator of
+// the web-console-validator, or an empty string if it is not configured.
+func GetSerialConsoleVSPCURI() string {
+	return os.Getenv(SerialConsoleVSPCURIEnv)
+}
@@ -1,4 +1,4 @@
-// Copyright (c) 2020 VMware, Inc. All Rights Reserved.
+// Copyright (c) 2020-2023 VMware, Inc. All Rights Reserved.
 // SPDX-License-Identifier: Apache-2.0
 
 package lib
@@ -6,6 +6,7 @@ package lib
 import (
 	"os"
 	"strconv"
+	"time"
 
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
@@ -42,3 +43,159 @@ var _ = Describe("MaxAllowedConcurrentVMsOnProvider", func() {
 		})
 	})
 })
+
+var _ = Describe("MaxConcurrentPublishesOnProvider", func() {
+	AfterEach(func() {
+		Expect(os.Unsetenv(MaxConcurrentPublishesOnProviderEnv)).To(Succeed())
+	})
+
+	It("returns the value from the env", func() {
+		Expect(os.Setenv(MaxConcurrentPublishesOnProviderEnv, "10")).To(Succeed())
+		Expect(MaxConcurrentPublishesOnProvider()).To(Equal(10))
+	})
+
+	It("returns the default value when the env is invalid", func() {
+		Expect(os.Setenv(MaxConcurrentPublishesOnProviderEnv, "0")).To(Succeed())
+		Expect(MaxConcurrentPublishesOnProvider()).To(Equal(DefaultMaxConcurrentPublishesOnProvider))
+	})
+
+	It("returns the default value when the env is not set", func() {
+		Expect(MaxConcurrentPublishesOnProvider()).To(Equal(DefaultMaxConcurrentPublishesOnProvider))
+	})
+})
+
+var _ = Describe("GetResourcePolicyDriftCheckInterval", func() {
+	Context("when the RESOURCE_POLICY_DRIFT_CHECK_INTERVAL env is set", func() {
+		AfterEach(func() {
+			Expect(os.Unsetenv(ResourcePolicyDriftCheckIntervalEnv)).To(Succeed())
+		})
+
+		Context("with a valid env value", func() {
+			It("returns the value from the env", func() {
+				Expect(os.Setenv(ResourcePolicyDriftCheckIntervalEnv, "90s")).To(Succeed())
+
+				Expect(GetResourcePolicyDriftCheckInterval()).To(Equal(90 * time.Second))
+			})
+		})
+
+		Context("with an invalid env value", func() {
+			It("returns the default value", func() {
+				Expect(os.Setenv(ResourcePolicyDriftCheckIntervalEnv, "-1m")).To(Succeed())
+
+				Expect(GetResourcePolicyDriftCheckInterval()).To(Equal(DefaultResourcePolicyDriftCheckInterval))
+			})
+		})
+	})
+
+	Context("when the RESOURCE_POLICY_DRIFT_CHECK_INTERVAL env is not set", func() {
+		It("returns the default value", func() {
+			Expect(GetResourcePolicyDriftCheckInterval()).To(Equal(DefaultResourcePolicyDriftCheckInterval))
+		})
+	})
+})
+
+var _ = Describe("GetWebConsoleTicketDuration", func() {
+	AfterEach(func() {
+		Expect(os.Unsetenv(WebConsoleTicketDurationEnv)).To(Succeed())
+		Expect(os.Unsetenv(WebConsoleMaxTicketDurationEnv)).To(Succeed())
+	})
+
+	Context("when no duration is requested", func() {
+		It("returns the default value", func() {
+			Expect(GetWebConsoleTicketDuration(0)).To(Equal(DefaultWebConsoleTicketDuration))
+		})
+
+		It("returns the value from the WEB_CONSOLE_TICKET_DURATION env", func() {
+			Expect(os.Setenv(WebConsoleTicketDurationEnv, "5m")).To(Succeed())
+			Expect(GetWebConsoleTicketDuration(0)).To(Equal(5 * time.Minute))
+		})
+
+		It("returns the default value with an invalid env value", func() {
+			Expect(os.Setenv(WebConsoleTicketDurationEnv, "-5m")).To(Succeed())
+			Expect(GetWebConsoleTicketDuration(0)).To(Equal(DefaultWebConsoleTicketDuration))
+		})
+	})
+
+	Context("when a duration is requested", func() {
+		It("returns the requested duration", func() {
+			Expect(GetWebConsoleTicketDuration(5 * time.Minute)).To(Equal(5 * time.Minute))
+		})
+
+		It("returns the maximum when the requested duration is longer", func() {
+			Expect(GetWebConsoleTicketDuration(time.Hour)).To(Equal(DefaultWebConsoleMaxTicketDuration))
+		})
+
+		It("returns the maximum from the WEB_CONSOLE_MAX_TICKET_DURATION env when the requested duration is longer", func() {
+			Expect(os.Setenv(WebConsoleMaxTicketDurationEnv, "1m")).To(Succeed())
+			Expect(GetWebConsoleTicketDuration(5 * time.Minute)).To(Equal(time.Minute))
+		})
+	})
+})
+
+var _ = Describe("GetWebConsoleMaxLifetime", func() {
+	AfterEach(func() {
+		Expect(os.Unsetenv(WebConsoleMaxLifetimeEnv)).To(Succeed())
+	})
+
+	It("returns the default value when the env is not set", func() {
+		Expect(GetWebConsoleMaxLifetime()).To(Equal(DefaultWebConsoleMaxLifetime))
+	})
+
+	It("returns the value from the WEB_CONSOLE_MAX_LIFETIME env", func() {
+		Expect(os.Setenv(WebConsoleMaxLifetimeEnv, "30m")).To(Succeed())
+		Expect(GetWebConsoleMaxLifetime()).To(Equal(30 * time.Minute))
+	})
+
+	It("returns the default value with an invalid env value", func() {
+		Expect(os.Setenv(WebConsoleMaxLifetimeEnv, "invalid")).To(Succeed())
+		Expect(GetWebConsoleMaxLifetime()).To(Equal(DefaultWebConsoleMaxLifetime))
+	})
+})
+
+var _ = Describe("GetWebConsoleProxyService", func() {
+	AfterEach(func() {
+		Expect(os.Unsetenv(WebConsoleProxyServiceEnv)).To(Succeed())
+	})
+
+	It("returns the default value when the env is not set", func() {
+		namespace, name := GetWebConsoleProxyService()
+		Expect(namespace).To(Equal(DefaultWebConsoleProxyServiceNamespace))
+		Expect(name).To(Equal(DefaultWebConsoleProxyServiceName))
+	})
+
+	It("returns the value from the env", func() {
+		Expect(os.Setenv(WebConsoleProxyServiceEnv, "my-ns/my-svc")).To(Succeed())
+		namespace, name := GetWebConsoleProxyService()
+		Expect(namespace).To(Equal("my-ns"))
+		Expect(name).To(Equal("my-svc"))
+	})
+
+	It("returns the default value with an invalid env value", func() {
+		Expect(os.Setenv(WebConsoleProxyServiceEnv, "my-svc")).To(Succeed())
+		namespace, name := GetWebConsoleProxyService()
+		Expect(namespace).To(Equal(DefaultWebConsoleProxyServiceNamespace))
+		Expect(name).To(Equal(DefaultWebConsoleProxyServiceName))
+	})
+})
+
+var _ = Describe("CheckImageTrustPolicy", func() {
+	AfterEach(func() {
+		Expect(os.Unsetenv(ImageTrustPolicySecretEnv)).To(Succeed())
+		Expect(os.Unsetenv(VMImageRegistryFSS)).To(Succeed())
+	})
+
+	It("succeeds when the image trust policy is not configured", func() {
+		Expect(CheckImageTrustPolicy()).To(Succeed())
+	})
+
+	It("succeeds when the image trust policy is configured with the image registry", func() {
+		Expect(os.Setenv(ImageTrustPolicySecretEnv, "trust-policy")).To(Succeed())
+		Expect(os.Setenv(VMImageRegistryFSS, "true")).To(Succeed())
+		Expect(CheckImageTrustPolicy()).To(Succeed())
+	})
+
+	It("fails when the image trust policy is configured without the image registry", func() {
+		Expect(os.Setenv(ImageTrustPolicySecretEnv, "trust-policy")).To(Succeed())
+		Expect(CheckImageTrustPolicy()).To(MatchError(ContainSubstring(VMImageRegistryFSS)))
+	})
+})
@@ -1,9 +1,13 @@
-// Copyright (c) 2019 VMware, Inc. All Rights Reserved.
+// Copyright (c) 2019-2023 VMware, Inc. All Rights Reserved.
 // SPDX-License-Identifier: Apache-2.0
 
 package manager
 
-import "time"
+import (
+	"time"
+
+	vcclient "github.com/vmware-tanzu/vm-operator/pkg/vmprovider/providers/vsphere/client"
+)
 
 const (
 	defaultPrefix = "vmoperator-"
@@ -20,6 +24,18 @@ const (
 	// manager option.
 	DefaultMaxConcurrentReconciles = 1
 
+	// DefaultVCenterMaxSessions is the default value for the eponymous manager
+	// option.
+	DefaultVCenterMaxSessions = vcclient.DefaultMaxSessions
+
+	// DefaultVCenterCallTimeout is the default value for the eponymous manager
+	// option.
+	DefaultVCenterCallTimeout = vcclient.DefaultCallTimeout
+
+	// DefaultTracingSampleRatio is the default value for the eponymous manager
+	// option.
+	DefaultTracingSampleRatio = 1.0
+
 	// DefaultPodNamespace is the default value for the eponymous manager
 	// option.
 	DefaultPodNamespace = defaultPrefix + "system"
@@ -1,4 +1,4 @@
-// Copyright (c) 2019-2021 VMware, Inc. All Rights Reserved.
+// Copyright (c) 2019-2023 VMware, Inc. All Rights Reserved.
 // SPDX-License-Identifier: Apache-2.0
 
 package manager
@@ -6,11 +6,14 @@ package manager
 import (
 	goctx "context"
 	"fmt"
+	"net/http"
+	"time"
 
 	"github.com/pkg/errors"
 	corev1 "k8s.io/api/core/v1"
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/healthz"
 	ctrlmgr "sigs.k8s.io/controller-runtime/pkg/manager"
 
 	// Load the GCP authentication plug-in.
@@ -26,8 +29,12 @@ import (
 	netopv1alpha1 "github.com/vmware-tanzu/vm-operator/external/net-operator/api/v1alpha1"
 	cnsv1alpha1 "github.com/vmware-tanzu/vm-operator/external/vsphere-csi-driver/pkg/syncer/cnsoperator/apis/cnsnodevmattachment/v1alpha1"
 	"github.com/vmware-tanzu/vm-operator/pkg/context"
+	"github.com/vmware-tanzu/vm-operator/pkg/lib"
 	"github.com/vmware-tanzu/vm-operator/pkg/record"
+	"github.com/vmware-tanzu/vm-operator/pkg/tracing"
+	"github.com/vmware-tanzu/vm-operator/pkg/vmprovider"
 	"github.com/vmware-tanzu/vm-operator/pkg/vmprovider/providers/vsphere"
+	vcclient "github.com/vmware-tanzu/vm-operator/pkg/vmprovider/providers/vsphere/client"
 )
 
 // Manager is a VM Operator controller manager.
@@ -43,6 +50,10 @@ func New(opts Options) (Manager, error) {
 	// Ensure the default options are set.
 	opts.defaults()
 
+	if err := lib.CheckImageTrustPolicy(); err != nil {
+		return nil, err
+	}
+
 	_ = clientgoscheme.AddToScheme(opts.Scheme)
 	_ = vmopv1.AddToScheme(opts.Scheme)
 	_ = ncpv1alpha1.AddToScheme(opts.Scheme)
@@ -89,12 +100,32 @@ func New(opts Options) (Manager, error) {
 		Recorder:                record.New(mgr.GetEventRecorderFor(fmt.Sprintf("%s/%s", opts.PodNamespace, opts.PodName))),
 		ContainerNode:           opts.ContainerNode,
 		SyncPeriod:              opts.SyncPeriod,
+		VCenterMaxSessions:      opts.VCenterMaxSessions,
+		VCenterCallTimeout:      opts.VCenterCallTimeout,
+	}
+
+	shutdownTracing, err := tracing.Init(controllerManagerContext, "vm-operator", tracing.Options{
+		Endpoint:    opts.TracingEndpoint,
+		Insecure:    opts.TracingInsecure,
+		SampleRatio: opts.TracingSampleRatio,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if err := mgr.Add(tracingShutdown(shutdownTracing)); err != nil {
+		return nil, errors.Wrap(err, "failed to add tracing shutdown")
 	}
 
 	if err := opts.InitializeProviders(controllerManagerContext, mgr); err != nil {
 		return nil, err
 	}
 
+	if controllerManagerContext.VMProvider != nil {
+		if err := mgr.AddHealthzCheck("vcenter", vCenterHealthzCheck(controllerManagerContext.VMProvider)); err != nil {
+			return nil, errors.Wrap(err, "failed to add vCenter health check")
+		}
+	}
+
 	// Add the requested items to the manager.
 	if err := opts.AddToManager(controllerManagerContext, mgr); err != nil {
 		return nil, errors.Wrap(err, "failed to add resources to the manager")
@@ -111,10 +142,42 @@ func New(opts Options) (Manager, error) {
 func InitializeProviders(ctx *context.ControllerManagerContext, mgr ctrlmgr.Manager) error {
 	vmProviderName := fmt.Sprintf("%s/%s/vmProvider", ctx.Namespace, ctx.Name)
 	recorder := record.New(mgr.GetEventRecorderFor(vmProviderName))
-	ctx.VMProvider = vsphere.NewVSphereVMProviderFromClient(mgr.GetClient(), recorder)
+	ctx.VMProvider = vsphere.NewVSphereVMProvider(mgr.GetClient(), recorder, vcclient.PoolOptions{
+		MaxSessions: ctx.VCenterMaxSessions,
+		CallTimeout: ctx.VCenterCallTimeout,
+	})
 	return nil
 }
 
+// vCenterHealthzCheck returns a health check that fails while the VM provider's VCenterReachable condition
+// is false.
+func vCenterHealthzCheck(vmProvider vmprovider.VirtualMachineProviderInterface) healthz.Checker {
+	return func(_ *http.Request) error {
+		for _, c := range vmProvider.GetConditions() {
+			if c.Type == vmopv1.VCenterReachableCondition && c.Status == corev1.ConditionFalse {
+				return errors.New(c.Message)
+			}
+		}
+		return nil
+	}
+}
+
+// tracingShutdown is a runnable that flushes the trace spans when the manager is stopped. It runs on all
+// the replicas, not only the leader.
+type tracingShutdown func(goctx.Context) error
+
+func (shutdown tracingShutdown) Start(ctx goctx.Context) error {
+	<-ctx.Done()
+
+	shutdownCtx, cancel := goctx.WithTimeout(goctx.Background(), 5*time.Second)
+	defer cancel()
+	return shutdown(shutdownCtx)
+}
+
+func (shutdown tracingShutdown) NeedLeaderElection() bool {
+	return false
+}
+
 type manager struct {
 	ctrlmgr.Manager
 	ctx *context.ControllerManagerContext
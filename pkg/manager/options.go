@@ -1,4 +1,4 @@
-// Copyright (c) 2019 VMware, Inc. All Rights Reserved.
+// Copyright (c) 2019-2023 VMware, Inc. All Rights Reserved.
 // SPDX-License-Identifier: Apache-2.0
 
 package manager
@@ -60,9 +60,31 @@ type Options struct {
 	// Defaults to the eponymous constant in this package.
 	MaxConcurrentReconciles int
 
+	// VCenterMaxSessions is the maximum number of sessions to each vCenter.
+	//
+	// Defaults to the eponymous constant in this package.
+	VCenterMaxSessions int
+
+	// VCenterCallTimeout is the timeout of each call to vCenter.
+	//
+	// Defaults to the eponymous constant in this package.
+	VCenterCallTimeout time.Duration
+
 	// MetricsAddr is the net.Addr string for the metrics server.
 	MetricsAddr string
 
+	// TracingEndpoint is the host:port of the OTLP gRPC collector the trace
+	// spans are exported to. Tracing is disabled when it is empty.
+	TracingEndpoint string
+
+	// TracingInsecure disables TLS on the connection to the collector.
+	TracingInsecure bool
+
+	// TracingSampleRatio is the ratio of the traces that are sampled.
+	//
+	// Defaults to the eponymous constant in this package.
+	TracingSampleRatio float64
+
 	// PodNamespace is the namespace in which the pod running the controller
 	// manager is located.
 	//
@@ -175,6 +197,18 @@ func (o *Options) defaults() {
 		o.MaxConcurrentReconciles = DefaultMaxConcurrentReconciles
 	}
 
+	if o.VCenterMaxSessions == 0 {
+		o.VCenterMaxSessions = DefaultVCenterMaxSessions
+	}
+
+	if o.VCenterCallTimeout == 0 {
+		o.VCenterCallTimeout = DefaultVCenterCallTimeout
+	}
+
+	if o.TracingSampleRatio == 0 {
+		o.TracingSampleRatio = DefaultTracingSampleRatio
+	}
+
 	if o.WebhookServiceContainerPort == 0 {
 		o.WebhookServiceContainerPort = DefaultWebhookServiceContainerPort
 	}
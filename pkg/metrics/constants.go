@@ -1,4 +1,4 @@
-// Copyright (c) 2022 VMware, Inc. All Rights Reserved.
+// Copyright (c) 2022-2023 VMware, Inc. All Rights Reserved.
 // SPDX-License-Identifier: Apache-2.0
 
 package metrics
@@ -25,4 +25,17 @@ const (
 	// VMImage related metrics labels (from image registry service).
 	vmiNameLabel      = "vmi_name"
 	vmiNamespaceLabel = "vmi_namespace"
+
+	// vSphere operation scheduler related metrics labels.
+	operationLabel     = "operation"
+	namespaceLabel     = "namespace"
+	priorityClassLabel = "priority_class"
+
+	// vCenter API related metrics labels.
+	vcenterLabel = "vcenter"
+	apiLabel     = "api"
+
+	// Placement related metrics labels.
+	zoneLabel   = "zone"
+	resultLabel = "result"
 )
@@ -0,0 +1,63 @@
+// Copyright (c) 2023 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package metrics
+
+import (
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+const (
+	placementResultSuccess = "success"
+	placementResultFailure = "failure"
+)
+
+var (
+	placementMetricsOnce sync.Once
+	placementMetrics     *PlacementMetrics
+)
+
+// PlacementMetrics are the metrics of the placement of VMs.
+type PlacementMetrics struct {
+	decisions *prometheus.CounterVec
+}
+
+// NewPlacementMetrics initializes a singleton and registers all the defined metrics.
+func NewPlacementMetrics() *PlacementMetrics {
+	placementMetricsOnce.Do(func() {
+		placementMetrics = &PlacementMetrics{
+			decisions: prometheus.NewCounterVec(prometheus.CounterOpts{
+				Namespace: metricsNamespace,
+				Subsystem: "placement",
+				Name:      "decisions_total",
+				Help:      "Number of the placement decisions of VMs by zone and result",
+			}, []string{
+				zoneLabel,
+				resultLabel,
+			}),
+		}
+
+		metrics.Registry.MustRegister(
+			placementMetrics.decisions,
+		)
+	})
+
+	return placementMetrics
+}
+
+// CountDecision counts a placement decision of a VM. The zone is the zone the VM was placed in, or the zone
+// the VM was to be placed in when the placement failed, and may be empty when the cluster is not zone-aware.
+func (m *PlacementMetrics) CountDecision(zone string, err error) {
+	result := placementResultSuccess
+	if err != nil {
+		result = placementResultFailure
+	}
+
+	m.decisions.With(prometheus.Labels{
+		zoneLabel:   zone,
+		resultLabel: result,
+	}).Inc()
+}
@@ -0,0 +1,80 @@
+// Copyright (c) 2023 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package metrics
+
+import (
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+var (
+	schedulerMetricsOnce sync.Once
+	schedulerMetrics     *SchedulerMetrics
+)
+
+// SchedulerMetrics are the metrics of the queues of the vSphere operations.
+type SchedulerMetrics struct {
+	queueDepth *prometheus.GaugeVec
+	waitTime   *prometheus.HistogramVec
+}
+
+// NewSchedulerMetrics initializes a singleton and registers all the defined metrics.
+func NewSchedulerMetrics() *SchedulerMetrics {
+	schedulerMetricsOnce.Do(func() {
+		schedulerMetrics = &SchedulerMetrics{
+			queueDepth: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+				Namespace: metricsNamespace,
+				Subsystem: "scheduler",
+				Name:      "queue_depth",
+				Help:      "Number of vSphere operations of a namespace that are queued",
+			}, []string{
+				operationLabel,
+				namespaceLabel,
+			}),
+			waitTime: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+				Namespace: metricsNamespace,
+				Subsystem: "scheduler",
+				Name:      "wait_time_seconds",
+				Help:      "Time vSphere operations were queued before they were started",
+				Buckets:   []float64{0, 1, 5, 10, 30, 60, 120, 300, 600, 1800},
+			}, []string{
+				operationLabel,
+				priorityClassLabel,
+			}),
+		}
+
+		metrics.Registry.MustRegister(
+			schedulerMetrics.queueDepth,
+			schedulerMetrics.waitTime,
+		)
+	})
+
+	return schedulerMetrics
+}
+
+// SetQueueDepth sets the number of queued operations of the namespace. The metric is deleted when no
+// operations are queued.
+func (m *SchedulerMetrics) SetQueueDepth(operation, namespace string, depth int) {
+	labels := prometheus.Labels{
+		operationLabel: operation,
+		namespaceLabel: namespace,
+	}
+
+	if depth == 0 {
+		m.queueDepth.Delete(labels)
+		return
+	}
+	m.queueDepth.With(labels).Set(float64(depth))
+}
+
+// ObserveWaitTime records the time an operation was queued before it was started.
+func (m *SchedulerMetrics) ObserveWaitTime(operation, priorityClass string, waitTime time.Duration) {
+	m.waitTime.With(prometheus.Labels{
+		operationLabel:     operation,
+		priorityClassLabel: priorityClass,
+	}).Observe(waitTime.Seconds())
+}
@@ -0,0 +1,89 @@
+// Copyright (c) 2023 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package metrics
+
+import (
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+const (
+	// VCenterAPISOAP is the api label of the calls to the vCenter SOAP API.
+	VCenterAPISOAP = "soap"
+	// VCenterAPIREST is the api label of the calls to the vCenter REST API.
+	VCenterAPIREST = "rest"
+)
+
+var (
+	vcenterMetricsOnce sync.Once
+	vcenterMetrics     *VCenterMetrics
+)
+
+// VCenterMetrics are the metrics of the calls to the vCenter APIs.
+type VCenterMetrics struct {
+	callDuration *prometheus.HistogramVec
+	callErrors   *prometheus.CounterVec
+}
+
+// NewVCenterMetrics initializes a singleton and registers all the defined metrics.
+func NewVCenterMetrics() *VCenterMetrics {
+	vcenterMetricsOnce.Do(func() {
+		vcenterMetrics = &VCenterMetrics{
+			callDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+				Namespace: metricsNamespace,
+				Subsystem: "vcenter",
+				Name:      "call_duration_seconds",
+				Help:      "Latency of the calls to the vCenter APIs",
+				Buckets:   []float64{0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30, 60, 120},
+			}, []string{
+				vcenterLabel,
+				apiLabel,
+				operationLabel,
+			}),
+			callErrors: prometheus.NewCounterVec(prometheus.CounterOpts{
+				Namespace: metricsNamespace,
+				Subsystem: "vcenter",
+				Name:      "call_errors_total",
+				Help:      "Number of the calls to the vCenter APIs that failed",
+			}, []string{
+				vcenterLabel,
+				apiLabel,
+				operationLabel,
+			}),
+		}
+
+		metrics.Registry.MustRegister(
+			vcenterMetrics.callDuration,
+			vcenterMetrics.callErrors,
+		)
+	})
+
+	return vcenterMetrics
+}
+
+// ObserveCall records the latency of a call to the vCenter API, and counts the call when it failed.
+func (m *VCenterMetrics) ObserveCall(vcenter, api, operation string, duration time.Duration, failed bool) {
+	labels := prometheus.Labels{
+		vcenterLabel:   vcenter,
+		apiLabel:       api,
+		operationLabel: operation,
+	}
+
+	m.callDuration.With(labels).Observe(duration.Seconds())
+	if failed {
+		m.callErrors.With(labels).Inc()
+	}
+}
+
+// CountCallError counts a failed call to the vCenter API whose latency is not recorded, such as a long poll.
+func (m *VCenterMetrics) CountCallError(vcenter, api, operation string) {
+	m.callErrors.With(prometheus.Labels{
+		vcenterLabel:   vcenter,
+		apiLabel:       api,
+		operationLabel: operation,
+	}).Inc()
+}
@@ -1,16 +1,18 @@
-// Copyright (c) 2022 VMware, Inc. All Rights Reserved.
+// Copyright (c) 2022-2023 VMware, Inc. All Rights Reserved.
 // SPDX-License-Identifier: Apache-2.0
 
 package metrics
 
 import (
 	"sync"
+	"time"
 
 	corev1 "k8s.io/api/core/v1"
 	"sigs.k8s.io/controller-runtime/pkg/metrics"
 
 	"github.com/prometheus/client_golang/prometheus"
 
+	vmopv1 "github.com/vmware-tanzu/vm-operator/api/v1alpha1"
 	"github.com/vmware-tanzu/vm-operator/pkg/context"
 )
 
@@ -24,8 +26,19 @@ type VMMetrics struct {
 	statusPhase           *prometheus.GaugeVec
 	powerState            *prometheus.GaugeVec
 	statusIP              *prometheus.GaugeVec
+	createToPoweredOn     *prometheus.HistogramVec
+	createToIP            *prometheus.HistogramVec
+
+	// provisionedLock protects poweredOn and assignedIP, the VMs that were observed powered on and with an IP
+	// address, so only the first time a VM reaches these states is recorded.
+	provisionedLock sync.Mutex
+	poweredOn       map[string]struct{}
+	assignedIP      map[string]struct{}
 }
 
+// provisioningBuckets are the buckets of the time to provision a VM, in seconds.
+var provisioningBuckets = []float64{5, 10, 20, 30, 45, 60, 90, 120, 180, 240, 300, 450, 600, 900, 1200, 1800, 3600}
+
 func NewVMMetrics() *VMMetrics {
 	vmMetricsOnce.Do(func() {
 		vmMetrics = &VMMetrics{
@@ -58,6 +71,27 @@ func NewVMMetrics() *VMMetrics {
 					Help:      "IP address assignment status of a VM resource"},
 				[]string{vmNameLabel, vmNamespaceLabel},
 			),
+
+			createToPoweredOn: prometheus.NewHistogramVec(
+				prometheus.HistogramOpts{
+					Namespace: metricsNamespace,
+					Name:      "vm_create_to_powered_on_seconds",
+					Help:      "Time from the creation of a VM resource to the first time it is powered on",
+					Buckets:   provisioningBuckets},
+				[]string{vmNamespaceLabel},
+			),
+
+			createToIP: prometheus.NewHistogramVec(
+				prometheus.HistogramOpts{
+					Namespace: metricsNamespace,
+					Name:      "vm_create_to_ip_seconds",
+					Help:      "Time from the creation of a VM resource to the first time it is assigned an IP address",
+					Buckets:   provisioningBuckets},
+				[]string{vmNamespaceLabel},
+			),
+
+			poweredOn:  map[string]struct{}{},
+			assignedIP: map[string]struct{}{},
 		}
 
 		metrics.Registry.MustRegister(
@@ -65,6 +99,8 @@ func NewVMMetrics() *VMMetrics {
 			vmMetrics.statusPhase,
 			vmMetrics.powerState,
 			vmMetrics.statusIP,
+			vmMetrics.createToPoweredOn,
+			vmMetrics.createToIP,
 		)
 	})
 
@@ -100,6 +136,45 @@ func (vmm *VMMetrics) DeleteMetrics(vmCtx *context.VirtualMachineContext) {
 
 	// Delete the 'vm.status.ip' metrics.
 	vmm.statusIP.DeletePartialMatch(labels)
+
+	// Forget the VM was provisioned, since a new VM with the same name may be created.
+	vmm.provisionedLock.Lock()
+	delete(vmm.poweredOn, vm.NamespacedName())
+	delete(vmm.assignedIP, vm.NamespacedName())
+	vmm.provisionedLock.Unlock()
+}
+
+// ObserveVMProvisioning records the time from the creation of the VM to the first time it is powered on and
+// assigned an IP address, when the reconcile with the initial status observed these changes. A VM that already
+// was powered on or had an IP address when it was first reconciled, such as after a restart, is not recorded.
+func (vmm *VMMetrics) ObserveVMProvisioning(vmCtx *context.VirtualMachineContext, initialStatus *vmopv1.VirtualMachineStatus) {
+	vm := vmCtx.VM
+	key := vm.NamespacedName()
+	sinceCreation := time.Since(vm.CreationTimestamp.Time)
+	labels := prometheus.Labels{
+		vmNamespaceLabel: vm.Namespace,
+	}
+
+	vmm.provisionedLock.Lock()
+	defer vmm.provisionedLock.Unlock()
+
+	if vm.Status.PowerState == vmopv1.VirtualMachinePoweredOn {
+		if _, ok := vmm.poweredOn[key]; !ok {
+			vmm.poweredOn[key] = struct{}{}
+			if initialStatus.PowerState != vmopv1.VirtualMachinePoweredOn {
+				vmm.createToPoweredOn.With(labels).Observe(sinceCreation.Seconds())
+			}
+		}
+	}
+
+	if vm.Status.VmIp != "" {
+		if _, ok := vmm.assignedIP[key]; !ok {
+			vmm.assignedIP[key] = struct{}{}
+			if initialStatus.VmIp == "" {
+				vmm.createToIP.With(labels).Observe(sinceCreation.Seconds())
+			}
+		}
+	}
 }
 
 func (vmm *VMMetrics) registerVMStatusConditions(vmCtx *context.VirtualMachineContext) {
@@ -0,0 +1,459 @@
+// Copyright (c) 2023 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package ociregistry
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"crypto/tls"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+)
+
+const (
+	headerAuthenticate        = "WWW-Authenticate"
+	headerContentDigest       = "Docker-Content-Digest"
+	mediaTypeOctetStream      = "application/octet-stream"
+	maxErrorBodySize          = 4096
+	repositoryScopePullPush   = "pull,push"
+	authorizationSchemeBasic  = "basic"
+	authorizationSchemeBearer = "bearer"
+)
+
+// Error is returned when a registry responds with an unexpected status.
+type Error struct {
+	StatusCode int
+	Method     string
+	URL        string
+	Message    string
+}
+
+func (e *Error) Error() string {
+	if e.Message == "" {
+		return fmt.Sprintf("%s %s: unexpected status %d", e.Method, e.URL, e.StatusCode)
+	}
+	return fmt.Sprintf("%s %s: unexpected status %d: %s", e.Method, e.URL, e.StatusCode, e.Message)
+}
+
+// IsUnauthorized returns true if the error is a registry error indicating
+// the request was not authorized.
+func IsUnauthorized(err error) bool {
+	var regErr *Error
+	if errors.As(err, &regErr) {
+		return regErr.StatusCode == http.StatusUnauthorized || regErr.StatusCode == http.StatusForbidden
+	}
+	return false
+}
+
+// Options are the options used to create a Client.
+type Options struct {
+	// Credentials are used to authenticate with the registry. When nil, the
+	// registry is accessed anonymously.
+	Credentials *Credentials
+
+	// InsecureSkipTLSVerify disables verification of the registry's TLS
+	// certificate.
+	InsecureSkipTLSVerify bool
+}
+
+// Client is a minimal client for the OCI distribution API scoped to a single
+// repository.
+type Client struct {
+	repo        Repository
+	credentials *Credentials
+	httpClient  *http.Client
+
+	authLock      sync.Mutex
+	authorization string
+}
+
+// NewClient returns a new Client for the provided repository.
+func NewClient(repo Repository, opts Options) *Client {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	if opts.InsecureSkipTLSVerify {
+		transport.TLSClientConfig = &tls.Config{InsecureSkipVerify: true} //nolint:gosec
+	}
+
+	return &Client{
+		repo:        repo,
+		credentials: opts.Credentials,
+		httpClient:  &http.Client{Transport: transport},
+	}
+}
+
+// Repository returns the repository of the client.
+func (c *Client) Repository() Repository {
+	return c.repo
+}
+
+// Ping verifies the registry is reachable and that the client is authorized
+// to access the repository.
+func (c *Client) Ping(ctx context.Context) error {
+	resp, err := c.do(ctx, http.MethodGet, c.url("/v2/"), nil, nil)
+	if err != nil {
+		return err
+	}
+	return expectStatus(resp, http.StatusOK)
+}
+
+// PushBlob streams the contents of the reader to the repository and returns
+// the descriptor of the pushed blob.
+func (c *Client) PushBlob(ctx context.Context, mediaType string, r io.Reader) (Descriptor, error) {
+	resp, err := c.do(ctx, http.MethodPost, c.url("/v2/%s/blobs/uploads/", c.repo.Name), nil, nil)
+	if err != nil {
+		return Descriptor{}, err
+	}
+	if err := expectStatus(resp, http.StatusAccepted); err != nil {
+		return Descriptor{}, err
+	}
+
+	location, err := resolveLocation(resp)
+	if err != nil {
+		return Descriptor{}, err
+	}
+
+	hasher := sha256.New()
+	counter := &countingWriter{}
+	body := io.TeeReader(r, io.MultiWriter(hasher, counter))
+
+	header := http.Header{"Content-Type": []string{mediaTypeOctetStream}}
+	resp, err = c.do(ctx, http.MethodPatch, location, body, header)
+	if err != nil {
+		return Descriptor{}, err
+	}
+	if err := expectStatus(resp, http.StatusAccepted, http.StatusNoContent); err != nil {
+		return Descriptor{}, err
+	}
+
+	location, err = resolveLocation(resp)
+	if err != nil {
+		return Descriptor{}, err
+	}
+
+	digest := "sha256:" + hex.EncodeToString(hasher.Sum(nil))
+	putURL, err := url.Parse(location)
+	if err != nil {
+		return Descriptor{}, err
+	}
+	query := putURL.Query()
+	query.Set("digest", digest)
+	putURL.RawQuery = query.Encode()
+
+	resp, err = c.do(ctx, http.MethodPut, putURL.String(), nil, nil)
+	if err != nil {
+		return Descriptor{}, err
+	}
+	if err := expectStatus(resp, http.StatusCreated); err != nil {
+		return Descriptor{}, err
+	}
+
+	return Descriptor{
+		MediaType: mediaType,
+		Digest:    digest,
+		Size:      counter.n,
+	}, nil
+}
+
+// PushManifest pushes the manifest to the repository with the provided tag
+// and returns the descriptor of the pushed manifest.
+func (c *Client) PushManifest(ctx context.Context, tag string, manifest Manifest) (Descriptor, error) {
+	if manifest.MediaType == "" {
+		manifest.MediaType = MediaTypeImageManifest
+	}
+	data, err := json.Marshal(manifest)
+	if err != nil {
+		return Descriptor{}, err
+	}
+
+	header := http.Header{"Content-Type": []string{manifest.MediaType}}
+	resp, err := c.do(ctx, http.MethodPut, c.url("/v2/%s/manifests/%s", c.repo.Name, tag), bytes.NewReader(data), header)
+	if err != nil {
+		return Descriptor{}, err
+	}
+	if err := expectStatus(resp, http.StatusCreated); err != nil {
+		return Descriptor{}, err
+	}
+
+	sum := sha256.Sum256(data)
+	return Descriptor{
+		MediaType: manifest.MediaType,
+		Digest:    "sha256:" + hex.EncodeToString(sum[:]),
+		Size:      int64(len(data)),
+	}, nil
+}
+
+// GetManifest returns the manifest and its digest for the provided tag or
+// digest. Nil is returned if the manifest does not exist.
+func (c *Client) GetManifest(ctx context.Context, reference string) (*Manifest, string, error) {
+	header := http.Header{"Accept": []string{MediaTypeImageManifest}}
+	resp, err := c.do(ctx, http.MethodGet, c.url("/v2/%s/manifests/%s", c.repo.Name, reference), nil, header)
+	if err != nil {
+		return nil, "", err
+	}
+	defer drainAndClose(resp)
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusNotFound:
+		return nil, "", nil
+	default:
+		return nil, "", newError(resp)
+	}
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, "", err
+	}
+	manifest := &Manifest{}
+	if err := json.Unmarshal(data, manifest); err != nil {
+		return nil, "", fmt.Errorf("failed to parse manifest: %w", err)
+	}
+
+	digest := resp.Header.Get(headerContentDigest)
+	if digest == "" {
+		sum := sha256.Sum256(data)
+		digest = "sha256:" + hex.EncodeToString(sum[:])
+	}
+
+	return manifest, digest, nil
+}
+
+// ResolveManifest returns the digest of the manifest for the provided tag or
+// digest. An empty string is returned if the manifest does not exist.
+func (c *Client) ResolveManifest(ctx context.Context, reference string) (string, error) {
+	header := http.Header{"Accept": []string{MediaTypeImageManifest}}
+	resp, err := c.do(ctx, http.MethodHead, c.url("/v2/%s/manifests/%s", c.repo.Name, reference), nil, header)
+	if err != nil {
+		return "", err
+	}
+	defer drainAndClose(resp)
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return resp.Header.Get(headerContentDigest), nil
+	case http.StatusNotFound:
+		return "", nil
+	default:
+		return "", newError(resp)
+	}
+}
+
+func (c *Client) url(format string, args ...interface{}) string {
+	return "https://" + c.repo.Registry + fmt.Sprintf(format, args...)
+}
+
+// do sends the request, and if the registry challenges the request, obtains
+// an authorization and retries it once. A request with a body is never
+// retried, so the challenge must already have been answered by an earlier
+// request.
+func (c *Client) do(ctx context.Context, method, rawURL string, body io.Reader, header http.Header) (*http.Response, error) {
+	newRequest := func() (*http.Request, error) {
+		req, err := http.NewRequestWithContext(ctx, method, rawURL, body)
+		if err != nil {
+			return nil, err
+		}
+		for k, v := range header {
+			req.Header[k] = v
+		}
+		c.authLock.Lock()
+		if c.authorization != "" {
+			req.Header.Set("Authorization", c.authorization)
+		}
+		c.authLock.Unlock()
+		return req, nil
+	}
+
+	req, err := newRequest()
+	if err != nil {
+		return nil, err
+	}
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusUnauthorized || body != nil {
+		return resp, nil
+	}
+
+	challenge := resp.Header.Get(headerAuthenticate)
+	drainAndClose(resp)
+
+	if err := c.authorize(ctx, challenge); err != nil {
+		return nil, err
+	}
+
+	if req, err = newRequest(); err != nil {
+		return nil, err
+	}
+	return c.httpClient.Do(req)
+}
+
+func (c *Client) authorize(ctx context.Context, challenge string) error {
+	scheme, params := parseChallenge(challenge)
+
+	switch scheme {
+	case authorizationSchemeBasic:
+		if c.credentials == nil {
+			return &Error{StatusCode: http.StatusUnauthorized, Method: http.MethodGet, URL: c.url("/v2/"),
+				Message: "registry requires credentials"}
+		}
+		auth := c.credentials.Username + ":" + c.credentials.Password
+		c.setAuthorization("Basic " + base64.StdEncoding.EncodeToString([]byte(auth)))
+		return nil
+
+	case authorizationSchemeBearer:
+		token, err := c.fetchToken(ctx, params)
+		if err != nil {
+			return err
+		}
+		c.setAuthorization("Bearer " + token)
+		return nil
+
+	default:
+		return fmt.Errorf("unsupported registry authentication challenge %q", challenge)
+	}
+}
+
+func (c *Client) fetchToken(ctx context.Context, params map[string]string) (string, error) {
+	realm := params["realm"]
+	if realm == "" {
+		return "", fmt.Errorf("registry bearer challenge is missing the realm")
+	}
+	tokenURL, err := url.Parse(realm)
+	if err != nil {
+		return "", fmt.Errorf("registry bearer challenge has an invalid realm %q: %w", realm, err)
+	}
+
+	query := tokenURL.Query()
+	if service := params["service"]; service != "" {
+		query.Set("service", service)
+	}
+	query.Set("scope", fmt.Sprintf("repository:%s:%s", c.repo.Name, repositoryScopePullPush))
+	tokenURL.RawQuery = query.Encode()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, tokenURL.String(), nil)
+	if err != nil {
+		return "", err
+	}
+	if c.credentials != nil {
+		req.SetBasicAuth(c.credentials.Username, c.credentials.Password)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer drainAndClose(resp)
+	if resp.StatusCode != http.StatusOK {
+		return "", newError(resp)
+	}
+
+	var tokenResp struct {
+		Token       string `json:"token"`
+		AccessToken string `json:"access_token"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&tokenResp); err != nil {
+		return "", fmt.Errorf("failed to parse registry token response: %w", err)
+	}
+	if tokenResp.Token != "" {
+		return tokenResp.Token, nil
+	}
+	if tokenResp.AccessToken != "" {
+		return tokenResp.AccessToken, nil
+	}
+	return "", fmt.Errorf("registry token response did not contain a token")
+}
+
+func (c *Client) setAuthorization(authorization string) {
+	c.authLock.Lock()
+	defer c.authLock.Unlock()
+	c.authorization = authorization
+}
+
+// parseChallenge parses a WWW-Authenticate header, ex.
+// Bearer realm="https://auth.example.com/token",service="registry.example.com".
+func parseChallenge(challenge string) (string, map[string]string) {
+	scheme, rest, _ := strings.Cut(strings.TrimSpace(challenge), " ")
+	params := map[string]string{}
+
+	for rest != "" {
+		var v string
+		rest = strings.TrimLeft(rest, " ,")
+		key, value, ok := strings.Cut(rest, "=")
+		if !ok {
+			break
+		}
+		if strings.HasPrefix(value, `"`) {
+			end := strings.Index(value[1:], `"`)
+			if end < 0 {
+				break
+			}
+			v, rest = value[1:end+1], value[end+2:]
+		} else {
+			v, rest, _ = strings.Cut(value, ",")
+		}
+		params[strings.ToLower(strings.TrimSpace(key))] = v
+	}
+
+	return strings.ToLower(scheme), params
+}
+
+// resolveLocation returns the absolute URL of the response's Location header.
+func resolveLocation(resp *http.Response) (string, error) {
+	location := resp.Header.Get("Location")
+	if location == "" {
+		return "", fmt.Errorf("%s %s: registry response is missing the Location header",
+			resp.Request.Method, resp.Request.URL)
+	}
+	u, err := resp.Request.URL.Parse(location)
+	if err != nil {
+		return "", fmt.Errorf("registry returned an invalid Location %q: %w", location, err)
+	}
+	return u.String(), nil
+}
+
+// expectStatus returns an error if the response's status is not one of the
+// provided codes. The response body is always closed.
+func expectStatus(resp *http.Response, codes ...int) error {
+	defer drainAndClose(resp)
+	for _, code := range codes {
+		if resp.StatusCode == code {
+			return nil
+		}
+	}
+	return newError(resp)
+}
+
+func newError(resp *http.Response) error {
+	data, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBodySize))
+	return &Error{
+		StatusCode: resp.StatusCode,
+		Method:     resp.Request.Method,
+		URL:        resp.Request.URL.Redacted(),
+		Message:    strings.TrimSpace(string(data)),
+	}
+}
+
+func drainAndClose(resp *http.Response) {
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, maxErrorBodySize))
+	_ = resp.Body.Close()
+}
+
+type countingWriter struct {
+	n int64
+}
+
+func (w *countingWriter) Write(p []byte) (int, error) {
+	w.n += int64(len(p))
+	return len(p), nil
+}
@@ -0,0 +1,134 @@
+// Copyright (c) 2023 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package ociregistry_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http/httptest"
+	"strings"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/vmware-tanzu/vm-operator/pkg/ociregistry"
+	"github.com/vmware-tanzu/vm-operator/pkg/ociregistry/fake"
+)
+
+var _ = Describe("Client", func() {
+
+	var (
+		ctx      context.Context
+		registry *fake.Registry
+		server   *httptest.Server
+		repo     ociregistry.Repository
+		opts     ociregistry.Options
+		client   *ociregistry.Client
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		registry = fake.NewRegistry()
+		opts = ociregistry.Options{InsecureSkipTLSVerify: true}
+	})
+
+	JustBeforeEach(func() {
+		server = httptest.NewTLSServer(registry)
+
+		var err error
+		repo, err = ociregistry.ParseRepository(strings.TrimPrefix(server.URL, "https://") + "/vms/ubuntu")
+		Expect(err).ToNot(HaveOccurred())
+		client = ociregistry.NewClient(repo, opts)
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	It("pushes and gets an artifact", func() {
+		Expect(client.Ping(ctx)).To(Succeed())
+
+		layer, err := client.PushBlob(ctx, "application/test", strings.NewReader("hello"))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(layer.Size).To(BeEquivalentTo(5))
+		data, ok := registry.Blob(layer.Digest)
+		Expect(ok).To(BeTrue())
+		Expect(string(data)).To(Equal("hello"))
+
+		config, err := client.PushBlob(ctx, "application/test+json", strings.NewReader("{}"))
+		Expect(err).ToNot(HaveOccurred())
+
+		manifest := ociregistry.Manifest{
+			SchemaVersion: 2,
+			Config:        config,
+			Layers:        []ociregistry.Descriptor{layer},
+			Annotations:   map[string]string{"key": "value"},
+		}
+		desc, err := client.PushManifest(ctx, "v1", manifest)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(desc.MediaType).To(Equal(ociregistry.MediaTypeImageManifest))
+
+		data, ok = registry.Manifest("vms/ubuntu", "v1")
+		Expect(ok).To(BeTrue())
+		pushed := ociregistry.Manifest{}
+		Expect(json.Unmarshal(data, &pushed)).To(Succeed())
+		Expect(pushed.Layers).To(HaveLen(1))
+
+		got, digest, err := client.GetManifest(ctx, "v1")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(digest).To(Equal(desc.Digest))
+		Expect(got.Annotations).To(HaveKeyWithValue("key", "value"))
+
+		digest, err = client.ResolveManifest(ctx, "v1")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(digest).To(Equal(desc.Digest))
+	})
+
+	It("returns nil when the manifest does not exist", func() {
+		manifest, _, err := client.GetManifest(ctx, "v1")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(manifest).To(BeNil())
+
+		digest, err := client.ResolveManifest(ctx, "v1")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(digest).To(BeEmpty())
+	})
+
+	Context("registry requires credentials", func() {
+		BeforeEach(func() {
+			registry.Username = "user"
+			registry.Password = "pass"
+		})
+
+		It("returns unauthorized without credentials", func() {
+			err := client.Ping(ctx)
+			Expect(err).To(HaveOccurred())
+			Expect(ociregistry.IsUnauthorized(err)).To(BeTrue())
+		})
+
+		When("credentials are provided", func() {
+			BeforeEach(func() {
+				opts.Credentials = &ociregistry.Credentials{Username: "user", Password: "pass"}
+			})
+
+			It("pushes a blob", func() {
+				Expect(client.Ping(ctx)).To(Succeed())
+				_, err := client.PushBlob(ctx, "application/test", strings.NewReader("hello"))
+				Expect(err).ToNot(HaveOccurred())
+			})
+		})
+
+		When("invalid credentials are provided", func() {
+			BeforeEach(func() {
+				opts.Credentials = &ociregistry.Credentials{Username: "user", Password: "wrong"}
+			})
+
+			It("returns unauthorized", func() {
+				err := client.Ping(ctx)
+				Expect(err).To(HaveOccurred())
+				Expect(ociregistry.IsUnauthorized(err)).To(BeTrue())
+			})
+		})
+	})
+})
@@ -0,0 +1,76 @@
+// Copyright (c) 2023 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package ociregistry
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// Credentials are the username and password used to authenticate with a
+// registry.
+type Credentials struct {
+	Username string
+	Password string
+}
+
+type dockerConfigJSON struct {
+	Auths map[string]dockerConfigEntry `json:"auths"`
+}
+
+type dockerConfigEntry struct {
+	Username string `json:"username,omitempty"`
+	Password string `json:"password,omitempty"`
+	Auth     string `json:"auth,omitempty"`
+}
+
+// CredentialsFromDockerConfigJSON returns the credentials for the provided
+// registry from the contents of a .dockerconfigjson file. Nil is returned when
+// the file does not contain an entry for the registry.
+func CredentialsFromDockerConfigJSON(data []byte, registry string) (*Credentials, error) {
+	var config dockerConfigJSON
+	if err := json.Unmarshal(data, &config); err != nil {
+		return nil, fmt.Errorf("failed to parse docker config: %w", err)
+	}
+
+	for key, entry := range config.Auths {
+		if normalizeRegistry(key) != normalizeRegistry(registry) {
+			continue
+		}
+
+		if entry.Username != "" || entry.Password != "" {
+			return &Credentials{Username: entry.Username, Password: entry.Password}, nil
+		}
+
+		decoded, err := base64.StdEncoding.DecodeString(entry.Auth)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode auth for registry %q: %w", key, err)
+		}
+		username, password, ok := strings.Cut(string(decoded), ":")
+		if !ok {
+			return nil, fmt.Errorf("auth for registry %q is not in username:password form", key)
+		}
+		return &Credentials{Username: username, Password: password}, nil
+	}
+
+	return nil, nil
+}
+
+// normalizeRegistry strips the scheme and path from a docker config key so
+// that both "https://registry.example.com/v1/" and "registry.example.com"
+// match the registry "registry.example.com".
+func normalizeRegistry(s string) string {
+	if i := strings.Index(s, "://"); i >= 0 {
+		s = s[i+3:]
+	}
+	if i := strings.IndexRune(s, '/'); i >= 0 {
+		s = s[:i]
+	}
+	if s == "index.docker.io" || s == "registry-1.docker.io" {
+		return DefaultRegistry
+	}
+	return s
+}
@@ -0,0 +1,45 @@
+// Copyright (c) 2023 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package ociregistry_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/vmware-tanzu/vm-operator/pkg/ociregistry"
+)
+
+var _ = Describe("CredentialsFromDockerConfigJSON", func() {
+
+	It("returns the username and password", func() {
+		data := []byte(`{"auths":{"registry.example.com":{"username":"user","password":"pass"}}}`)
+		creds, err := ociregistry.CredentialsFromDockerConfigJSON(data, "registry.example.com")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(creds).To(Equal(&ociregistry.Credentials{Username: "user", Password: "pass"}))
+	})
+
+	It("decodes the auth field", func() {
+		// dXNlcjpwYXNz is user:pass.
+		data := []byte(`{"auths":{"https://registry.example.com/v1/":{"auth":"dXNlcjpwYXNz"}}}`)
+		creds, err := ociregistry.CredentialsFromDockerConfigJSON(data, "registry.example.com")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(creds).To(Equal(&ociregistry.Credentials{Username: "user", Password: "pass"}))
+	})
+
+	It("returns nil when there is no entry for the registry", func() {
+		data := []byte(`{"auths":{"other.example.com":{"username":"user","password":"pass"}}}`)
+		creds, err := ociregistry.CredentialsFromDockerConfigJSON(data, "registry.example.com")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(creds).To(BeNil())
+	})
+
+	It("returns an error for invalid data", func() {
+		_, err := ociregistry.CredentialsFromDockerConfigJSON([]byte("not json"), "registry.example.com")
+		Expect(err).To(HaveOccurred())
+
+		data := []byte(`{"auths":{"registry.example.com":{"auth":"not base64"}}}`)
+		_, err = ociregistry.CredentialsFromDockerConfigJSON(data, "registry.example.com")
+		Expect(err).To(HaveOccurred())
+	})
+})
@@ -0,0 +1,201 @@
+// Copyright (c) 2023 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package fake
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+const (
+	blobUploadsPath = "/blobs/uploads/"
+	manifestsPath   = "/manifests/"
+)
+
+// Registry is an in-memory OCI registry for use in tests.
+type Registry struct {
+	sync.Mutex
+
+	// Username and Password, when set, are required using basic auth.
+	Username string
+	Password string
+
+	nextUploadID int
+	uploads      map[string][]byte
+	blobs        map[string][]byte
+	manifests    map[string][]byte
+	tags         map[string]string
+}
+
+// NewRegistry returns a new, empty Registry.
+func NewRegistry() *Registry {
+	return &Registry{
+		uploads:   map[string][]byte{},
+		blobs:     map[string][]byte{},
+		manifests: map[string][]byte{},
+		tags:      map[string]string{},
+	}
+}
+
+// Blob returns the contents of the blob with the provided digest.
+func (r *Registry) Blob(digest string) ([]byte, bool) {
+	r.Lock()
+	defer r.Unlock()
+	data, ok := r.blobs[digest]
+	return data, ok
+}
+
+// Manifest returns the manifest tagged in the provided repository.
+func (r *Registry) Manifest(repo, tag string) ([]byte, bool) {
+	r.Lock()
+	defer r.Unlock()
+	digest, ok := r.tags[repo+":"+tag]
+	if !ok {
+		return nil, false
+	}
+	return r.manifests[digest], true
+}
+
+// PutManifest stores the manifest in the provided repository with the tag.
+func (r *Registry) PutManifest(repo, tag string, data []byte) string {
+	r.Lock()
+	defer r.Unlock()
+	return r.putManifest(repo, tag, data)
+}
+
+func (r *Registry) putManifest(repo, reference string, data []byte) string {
+	digest := digestOf(data)
+	r.manifests[digest] = data
+	if !strings.HasPrefix(reference, "sha256:") {
+		r.tags[repo+":"+reference] = digest
+	}
+	return digest
+}
+
+// ServeHTTP implements http.Handler.
+func (r *Registry) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	if r.Username != "" || r.Password != "" {
+		username, password, ok := req.BasicAuth()
+		if !ok || username != r.Username || password != r.Password {
+			w.Header().Set("WWW-Authenticate", `Basic realm="fake"`)
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+	}
+
+	r.Lock()
+	defer r.Unlock()
+
+	path := req.URL.Path
+	switch {
+	case path == "/v2/":
+		w.WriteHeader(http.StatusOK)
+
+	case strings.Contains(path, blobUploadsPath):
+		r.serveUpload(w, req)
+
+	case strings.Contains(path, manifestsPath):
+		r.serveManifest(w, req)
+
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+func (r *Registry) serveUpload(w http.ResponseWriter, req *http.Request) {
+	idx := strings.Index(req.URL.Path, blobUploadsPath)
+	repo := strings.TrimPrefix(req.URL.Path[:idx], "/v2/")
+	uploadID := req.URL.Path[idx+len(blobUploadsPath):]
+
+	switch {
+	case req.Method == http.MethodPost && uploadID == "":
+		r.nextUploadID++
+		uploadID = fmt.Sprintf("%d", r.nextUploadID)
+		r.uploads[uploadID] = nil
+		w.Header().Set("Location", fmt.Sprintf("/v2/%s%s%s", repo, blobUploadsPath, uploadID))
+		w.WriteHeader(http.StatusAccepted)
+
+	case req.Method == http.MethodPatch:
+		data, ok := r.uploads[uploadID]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		chunk, err := io.ReadAll(req.Body)
+		if err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		r.uploads[uploadID] = append(data, chunk...)
+		w.Header().Set("Location", req.URL.Path)
+		w.WriteHeader(http.StatusAccepted)
+
+	case req.Method == http.MethodPut:
+		data, ok := r.uploads[uploadID]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		digest := req.URL.Query().Get("digest")
+		if digest != digestOf(data) {
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte("digest mismatch"))
+			return
+		}
+		delete(r.uploads, uploadID)
+		r.blobs[digest] = data
+		w.Header().Set("Docker-Content-Digest", digest)
+		w.WriteHeader(http.StatusCreated)
+
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+func (r *Registry) serveManifest(w http.ResponseWriter, req *http.Request) {
+	idx := strings.Index(req.URL.Path, manifestsPath)
+	repo := strings.TrimPrefix(req.URL.Path[:idx], "/v2/")
+	reference := req.URL.Path[idx+len(manifestsPath):]
+
+	switch req.Method {
+	case http.MethodPut:
+		data, err := io.ReadAll(req.Body)
+		if err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Docker-Content-Digest", r.putManifest(repo, reference, data))
+		w.WriteHeader(http.StatusCreated)
+
+	case http.MethodGet, http.MethodHead:
+		digest := reference
+		if !strings.HasPrefix(reference, "sha256:") {
+			digest = r.tags[repo+":"+reference]
+		}
+		data, ok := r.manifests[digest]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Docker-Content-Digest", digest)
+		w.Header().Set("Content-Type", "application/vnd.oci.image.manifest.v1+json")
+		w.WriteHeader(http.StatusOK)
+		if req.Method == http.MethodGet {
+			_, _ = w.Write(data)
+		}
+
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+func digestOf(data []byte) string {
+	sum := sha256.Sum256(data)
+	return "sha256:" + hex.EncodeToString(sum[:])
+}
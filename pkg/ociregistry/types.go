@@ -0,0 +1,117 @@
+// Copyright (c) 2023 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package ociregistry
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+const (
+	// MediaTypeImageManifest is the media type of an OCI image manifest.
+	MediaTypeImageManifest = "application/vnd.oci.image.manifest.v1+json"
+
+	// AnnotationTitle is the annotation key for the human-readable title of
+	// a manifest or layer.
+	AnnotationTitle = "org.opencontainers.image.title"
+
+	// AnnotationDescription is the annotation key for the human-readable
+	// description of a manifest.
+	AnnotationDescription = "org.opencontainers.image.description"
+
+	// AnnotationCreated is the annotation key for the date and time on which
+	// a manifest was created.
+	AnnotationCreated = "org.opencontainers.image.created"
+
+	// AnnotationPublishRequestUID is the annotation key for the UID of the
+	// VirtualMachinePublishRequest that pushed a manifest.
+	AnnotationPublishRequestUID = "vmoperator.vmware.com/publish-request-uid"
+
+	// AnnotationSourceVirtualMachine is the annotation key for the
+	// namespaced name of the VirtualMachine a manifest was published from.
+	AnnotationSourceVirtualMachine = "vmoperator.vmware.com/source-virtualmachine"
+
+	// DefaultRegistry is the registry used when a reference does not include
+	// a registry host.
+	DefaultRegistry = "docker.io"
+)
+
+var (
+	repositoryRegex = regexp.MustCompile(`^[a-z0-9]+(?:(?:[._]|__|-+)[a-z0-9]+)*(?:/[a-z0-9]+(?:(?:[._]|__|-+)[a-z0-9]+)*)*$`)
+	tagRegex        = regexp.MustCompile(`^[A-Za-z0-9_][A-Za-z0-9_.-]{0,127}$`)
+)
+
+// Descriptor describes the disposition of targeted content.
+type Descriptor struct {
+	MediaType   string            `json:"mediaType"`
+	Digest      string            `json:"digest"`
+	Size        int64             `json:"size"`
+	Annotations map[string]string `json:"annotations,omitempty"`
+}
+
+// Manifest is an OCI image manifest.
+type Manifest struct {
+	SchemaVersion int               `json:"schemaVersion"`
+	MediaType     string            `json:"mediaType,omitempty"`
+	Config        Descriptor        `json:"config"`
+	Layers        []Descriptor      `json:"layers"`
+	Annotations   map[string]string `json:"annotations,omitempty"`
+}
+
+// Repository identifies a repository in an OCI registry.
+type Repository struct {
+	// Registry is the host and optional port of the registry.
+	Registry string
+
+	// Name is the path of the repository in the registry.
+	Name string
+}
+
+// String returns the repository as registry/name.
+func (r Repository) String() string {
+	return r.Registry + "/" + r.Name
+}
+
+// Reference returns the tagged reference for the provided tag.
+func (r Repository) Reference(tag string) string {
+	return r.String() + ":" + tag
+}
+
+// ParseRepository parses a repository string, ex. registry.example.com/foo/bar.
+// The repository must not include a tag or a digest.
+func ParseRepository(s string) (Repository, error) {
+	if s == "" {
+		return Repository{}, fmt.Errorf("repository is empty")
+	}
+	if strings.Contains(s, "://") {
+		return Repository{}, fmt.Errorf("repository %q must not include a scheme", s)
+	}
+	if strings.Contains(s, "@") {
+		return Repository{}, fmt.Errorf("repository %q must not include a digest", s)
+	}
+
+	registry, name := DefaultRegistry, s
+	if i := strings.IndexRune(s, '/'); i > 0 {
+		// Similar to the docker client, the first path component is a
+		// registry only if it looks like a host.
+		if host := s[:i]; strings.ContainsAny(host, ".:") || host == "localhost" {
+			registry, name = host, s[i+1:]
+		}
+	}
+
+	if i := strings.LastIndex(name, ":"); i >= 0 {
+		return Repository{}, fmt.Errorf("repository %q must not include a tag", s)
+	}
+	if !repositoryRegex.MatchString(name) {
+		return Repository{}, fmt.Errorf("repository name %q is invalid", name)
+	}
+
+	return Repository{Registry: registry, Name: name}, nil
+}
+
+// IsValidTag returns true if the provided string is a valid OCI tag.
+func IsValidTag(tag string) bool {
+	return tagRegex.MatchString(tag)
+}
@@ -0,0 +1,55 @@
+// Copyright (c) 2023 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package ociregistry_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+
+	"github.com/vmware-tanzu/vm-operator/pkg/ociregistry"
+)
+
+var _ = Describe("ParseRepository", func() {
+
+	DescribeTable("valid repositories",
+		func(s, registry, name string) {
+			repo, err := ociregistry.ParseRepository(s)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(repo.Registry).To(Equal(registry))
+			Expect(repo.Name).To(Equal(name))
+		},
+		Entry("registry with path", "registry.example.com/vms/ubuntu", "registry.example.com", "vms/ubuntu"),
+		Entry("registry with port", "registry.example.com:5000/ubuntu", "registry.example.com:5000", "ubuntu"),
+		Entry("localhost", "localhost/ubuntu", "localhost", "ubuntu"),
+		Entry("no registry", "library/ubuntu", ociregistry.DefaultRegistry, "library/ubuntu"),
+	)
+
+	DescribeTable("invalid repositories",
+		func(s string) {
+			_, err := ociregistry.ParseRepository(s)
+			Expect(err).To(HaveOccurred())
+		},
+		Entry("empty", ""),
+		Entry("scheme", "https://registry.example.com/ubuntu"),
+		Entry("tag", "registry.example.com/ubuntu:v1"),
+		Entry("digest", "registry.example.com/ubuntu@sha256:abcd"),
+		Entry("uppercase", "registry.example.com/Ubuntu"),
+	)
+
+	It("returns a tagged reference", func() {
+		repo, err := ociregistry.ParseRepository("registry.example.com/vms/ubuntu")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(repo.Reference("v1")).To(Equal("registry.example.com/vms/ubuntu:v1"))
+	})
+})
+
+var _ = Describe("IsValidTag", func() {
+	It("validates tags", func() {
+		Expect(ociregistry.IsValidTag("v1.0_rc-1")).To(BeTrue())
+		Expect(ociregistry.IsValidTag("")).To(BeFalse())
+		Expect(ociregistry.IsValidTag(".v1")).To(BeFalse())
+		Expect(ociregistry.IsValidTag("v1/v2")).To(BeFalse())
+	})
+})
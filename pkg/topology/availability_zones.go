@@ -1,4 +1,4 @@
-// Copyright (c) 2021 VMware, Inc. All Rights Reserved.
+// Copyright (c) 2021-2023 VMware, Inc. All Rights Reserved.
 // SPDX-License-Identifier: Apache-2.0
 
 package topology
@@ -45,6 +45,18 @@ var (
 	ErrWcpFaultDomainsFSSIsEnabled = errors.New("wcp fault domains fss is enabled")
 )
 
+// VCenterRef identifies the vCenter and Datacenter that manage the vSphere objects of an
+// availability zone. An empty VCenter is the default vCenter, and an empty Datacenter is the
+// Datacenter configured for the vCenter.
+type VCenterRef struct {
+	VCenter    string
+	Datacenter string
+}
+
+// ZoneVCenterRefs maps the names of availability zones to the VCenterRef of the vCenter and Datacenter
+// that manage them. A zone that is not in the map is managed by the default vCenter.
+type ZoneVCenterRefs map[string]VCenterRef
+
 // +kubebuilder:rbac:groups=topology.tanzu.vmware.com,resources=availabilityzones,verbs=get;list;watch
 // +kubebuilder:rbac:groups=topology.tanzu.vmware.com,resources=availabilityzones/status,verbs=get;list;watch
 
@@ -78,6 +90,21 @@ func LookupZoneForClusterMoID(
 	return "", fmt.Errorf("failed to find zone for cluster MoID %s", clusterMoID)
 }
 
+// LookupVCenterRefForZone returns the VCenterRef of the named zone.
+func LookupVCenterRefForZone(
+	ctx context.Context,
+	client ctrlclient.Client,
+	zoneVCenterRefs ZoneVCenterRefs,
+	availabilityZoneName string) (VCenterRef, error) {
+
+	availabilityZone, err := GetAvailabilityZone(ctx, client, availabilityZoneName)
+	if err != nil {
+		return VCenterRef{}, err
+	}
+
+	return zoneVCenterRefs[availabilityZone.Name], nil
+}
+
 // GetNamespaceFolderAndRPMoID returns the Folder and ResourcePool MoID for the zone and namespace.
 func GetNamespaceFolderAndRPMoID(
 	ctx context.Context,
@@ -154,6 +181,33 @@ func GetNamespaceFolderMoID(
 	return "", fmt.Errorf("unable to get FolderMoID for namespace %s", namespace)
 }
 
+// GetNamespaceFolderMoIDForVCenter returns the FolderMoID for the namespace on the vCenter and Datacenter
+// of vcRef.
+func GetNamespaceFolderMoIDForVCenter(
+	ctx context.Context,
+	client ctrlclient.Client,
+	namespace string,
+	zoneVCenterRefs ZoneVCenterRefs,
+	vcRef VCenterRef) (string, error) {
+
+	availabilityZones, err := GetAvailabilityZones(ctx, client)
+	if err != nil {
+		return "", err
+	}
+
+	// The Folder is VC-scoped so return the first match of the zones of the vCenter.
+	for _, zone := range availabilityZones {
+		if zoneVCenterRefs[zone.Name] != vcRef {
+			continue
+		}
+		if nsInfo, ok := zone.Spec.Namespaces[namespace]; ok {
+			return nsInfo.FolderMoId, nil
+		}
+	}
+
+	return "", fmt.Errorf("unable to get FolderMoID for namespace %s on vCenter %q", namespace, vcRef.VCenter)
+}
+
 // GetAvailabilityZones returns a list of the AvailabilityZone resources.
 func GetAvailabilityZones(
 	ctx context.Context,
@@ -1,4 +1,4 @@
-// Copyright (c) 2021 VMware, Inc. All Rights Reserved.
+// Copyright (c) 2021-2023 VMware, Inc. All Rights Reserved.
 // SPDX-License-Identifier: Apache-2.0
 
 package topology_test
@@ -34,6 +34,7 @@ var _ = Describe("Availability Zones", func() {
 		numberOfAvailabilityZones int
 		numberOfNamespaces        int
 		oldFaultDomainsFunc       func() bool
+		zoneVCenterRefs           topology.ZoneVCenterRefs
 	)
 
 	BeforeEach(func() {
@@ -48,6 +49,7 @@ var _ = Describe("Availability Zones", func() {
 		wcpFaultDomainsFssEnabled = false
 		numberOfAvailabilityZones = 0
 		numberOfNamespaces = 0
+		zoneVCenterRefs = nil
 		lib.IsWcpFaultDomainsFSSEnabled = oldFaultDomainsFunc
 	})
 
@@ -57,6 +59,14 @@ var _ = Describe("Availability Zones", func() {
 		}
 
 		for i := 0; i < numberOfAvailabilityZones; i++ {
+			if zoneVCenterRefs == nil {
+				zoneVCenterRefs = topology.ZoneVCenterRefs{}
+			}
+			zoneVCenterRefs[fmt.Sprintf("az-%d", i)] = topology.VCenterRef{
+				VCenter:    fmt.Sprintf("vc-%d", i),
+				Datacenter: fmt.Sprintf("datacenter-%d", i),
+			}
+
 			obj := &topologyv1.AvailabilityZone{
 				ObjectMeta: metav1.ObjectMeta{
 					Name: fmt.Sprintf("az-%d", i),
@@ -151,6 +161,41 @@ var _ = Describe("Availability Zones", func() {
 		}
 	}
 
+	assertLookupVCenterRefForZoneSuccess := func() {
+		for i := 0; i < numberOfAvailabilityZones; i++ {
+			vcRef, err := topology.LookupVCenterRefForZone(ctx, client, zoneVCenterRefs, fmt.Sprintf("az-%d", i))
+			ExpectWithOffset(1, err).ToNot(HaveOccurred())
+			ExpectWithOffset(1, vcRef.VCenter).To(Equal(fmt.Sprintf("vc-%d", i)))
+			ExpectWithOffset(1, vcRef.Datacenter).To(Equal(fmt.Sprintf("datacenter-%d", i)))
+		}
+	}
+	assertLookupVCenterRefForZoneDefaultZone := func() {
+		vcRef, err := topology.LookupVCenterRefForZone(ctx, client, zoneVCenterRefs, "")
+		ExpectWithOffset(1, err).ToNot(HaveOccurred())
+		ExpectWithOffset(1, vcRef).To(Equal(topology.VCenterRef{}))
+	}
+	assertGetNamespaceFolderMoIDForVCenterSuccess := func() {
+		for i := 0; i < numberOfAvailabilityZones; i++ {
+			vcRef := topology.VCenterRef{VCenter: fmt.Sprintf("vc-%d", i), Datacenter: fmt.Sprintf("datacenter-%d", i)}
+			folder, err := topology.GetNamespaceFolderMoIDForVCenter(ctx, client, "ns-0", zoneVCenterRefs, vcRef)
+			ExpectWithOffset(1, err).ToNot(HaveOccurred())
+			ExpectWithOffset(1, folder).To(Equal(folderMoID))
+		}
+	}
+	assertGetNamespaceFolderMoIDForVCenterInvalidVCenter := func() {
+		_, err := topology.GetNamespaceFolderMoIDForVCenter(ctx, client, "ns-0", zoneVCenterRefs, topology.VCenterRef{VCenter: "invalid"})
+		ExpectWithOffset(1, err).To(MatchError(`unable to get FolderMoID for namespace ns-0 on vCenter "invalid"`))
+	}
+	assertGetNamespaceFolderMoIDForVCenterDefaultZone := func() {
+		folder, err := topology.GetNamespaceFolderMoIDForVCenter(ctx, client, "ns-0", zoneVCenterRefs, topology.VCenterRef{})
+		ExpectWithOffset(1, err).ToNot(HaveOccurred())
+		ExpectWithOffset(1, folder).To(Equal(folderMoID))
+	}
+	assertLookupVCenterRefForZoneInvalidNameErrNotFound := func() {
+		_, err := topology.LookupVCenterRefForZone(ctx, client, zoneVCenterRefs, "invalid")
+		ExpectWithOffset(1, apierrors.IsNotFound(err)).To(BeTrue())
+	}
+
 	assertGetAvailabilityZonesErrNoAvailabilityZones := func() {
 		_, err := topology.GetAvailabilityZones(ctx, client)
 		ExpectWithOffset(1, err).To(MatchError(topology.ErrNoAvailabilityZones))
@@ -247,6 +292,18 @@ var _ = Describe("Availability Zones", func() {
 						It("Should return an apierrors.NotFound error", assertGetAvailabilityZoneEmptyNameErrNotFound)
 					})
 				})
+				Context("LookupVCenterRefForZone", func() {
+					Context("With a valid AvailabilityZone name", func() {
+						It("Should return the vCenter and Datacenter of the zone", assertLookupVCenterRefForZoneSuccess)
+					})
+					Context("With an invalid AvailabilityZone name", func() {
+						It("Should return an apierrors.NotFound error", assertLookupVCenterRefForZoneInvalidNameErrNotFound)
+					})
+				})
+				Context("GetNamespaceFolderMoIDForVCenter", func() {
+					It("Should return the Folder of the vCenter", assertGetNamespaceFolderMoIDForVCenterSuccess)
+					It("Should return an error for an invalid vCenter", assertGetNamespaceFolderMoIDForVCenterInvalidVCenter)
+				})
 				Context("GetNamespaceFolderAndRPMoID", func() {
 					Context("With an invalid AvailabilityZone name", assertGetNamespaceFolderAndRPMoIDInvalidAZErrNotFound)
 					Context("With a valid AvailabilityZone name", func() {
@@ -272,6 +329,14 @@ var _ = Describe("Availability Zones", func() {
 						It("Should return the Default AvailabilityZone resource", assertGetAvailabilityEmptyZoneDefaultZone)
 					})
 				})
+				Context("LookupVCenterRefForZone", func() {
+					Context("With an empty AvailabilityZone name", func() {
+						It("Should return the default vCenter", assertLookupVCenterRefForZoneDefaultZone)
+					})
+				})
+				Context("GetNamespaceFolderMoIDForVCenter", func() {
+					It("Should return the Folder of the default vCenter", assertGetNamespaceFolderMoIDForVCenterDefaultZone)
+				})
 				Context("GetNamespaceFolderAndRPMoID", func() {
 					Context("With an invalid AvailabilityZone name", assertGetNamespaceFolderAndRPMoIDInvalidAZErrNotFound)
 					Context("With the default AvailabilityZone name", func() {
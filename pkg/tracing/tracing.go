@@ -0,0 +1,147 @@
+// Copyright (c) 2023 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package tracing
+
+import (
+	"context"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/vmware/govmomi/vim25/types"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.12.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	// TracerName is the name of the tracer of the spans of VM Operator.
+	TracerName = "github.com/vmware-tanzu/vm-operator"
+
+	// OpIDKey is the attribute of the vCenter operation ID of a span.
+	OpIDKey = attribute.Key("vcenter.opid")
+	// VMKey is the attribute of the namespace and name of the VM of a span.
+	VMKey = attribute.Key("vm")
+	// VCenterKey is the attribute of the vCenter of a span.
+	VCenterKey = attribute.Key("vcenter")
+	// RequeueAfterKey is the attribute of the delay after which the object of a span is reconciled again.
+	RequeueAfterKey = attribute.Key("requeue_after")
+)
+
+// Options are the options of the exporter of the spans.
+type Options struct {
+	// Endpoint is the host:port of the OTLP gRPC collector the spans are exported to. Tracing is disabled
+	// when it is empty.
+	Endpoint string
+
+	// Insecure disables the TLS of the connection to the collector.
+	Insecure bool
+
+	// SampleRatio is the ratio of the traces that are sampled, from 0 to 1. The traces whose parent span
+	// is sampled are always sampled.
+	SampleRatio float64
+}
+
+// Init sets the global tracer provider to one that exports the spans of the service with the options.
+// The returned func flushes the spans and stops the exporter. When tracing is disabled, Init does nothing
+// and the spans are not recorded.
+func Init(ctx context.Context, serviceName string, opts Options) (func(context.Context) error, error) {
+	if opts.Endpoint == "" {
+		return func(context.Context) error { return nil }, nil
+	}
+
+	clientOpts := []otlptracegrpc.Option{otlptracegrpc.WithEndpoint(opts.Endpoint)}
+	if opts.Insecure {
+		clientOpts = append(clientOpts, otlptracegrpc.WithInsecure())
+	}
+
+	exporter, err := otlptracegrpc.New(ctx, clientOpts...)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to create the trace exporter to %s", opts.Endpoint)
+	}
+
+	res, err := resource.Merge(resource.Default(), resource.NewWithAttributes(
+		semconv.SchemaURL,
+		semconv.ServiceNameKey.String(serviceName),
+	))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create the trace resource")
+	}
+
+	tp := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(opts.SampleRatio))),
+	)
+
+	otel.SetTracerProvider(tp)
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+
+	return tp.Shutdown, nil
+}
+
+// StartSpan starts a span that is a child of the span in the ctx, if any. The vCenter operation ID of
+// the ctx is recorded as the OpIDKey attribute of the span.
+func StartSpan(
+	ctx context.Context,
+	name string,
+	attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+
+	return startSpan(ctx, name, trace.SpanKindInternal, attrs)
+}
+
+// StartClientSpan starts the span of a call to vCenter like StartSpan.
+func StartClientSpan(
+	ctx context.Context,
+	name string,
+	attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+
+	return startSpan(ctx, name, trace.SpanKindClient, attrs)
+}
+
+func startSpan(
+	ctx context.Context,
+	name string,
+	kind trace.SpanKind,
+	attrs []attribute.KeyValue) (context.Context, trace.Span) {
+
+	if opID, ok := ctx.Value(types.ID{}).(string); ok {
+		attrs = append(attrs, OpIDKey.String(opID))
+	}
+
+	return otel.Tracer(TracerName).Start(ctx, name, trace.WithAttributes(attrs...), trace.WithSpanKind(kind))
+}
+
+// RequeueFunc returns the delay after which the object is reconciled again when the err only means the
+// operation has not completed yet, and false for the other errors.
+type RequeueFunc func(err error) (time.Duration, bool)
+
+// EndSpan ends the span, and records the err on the span when it is not nil.
+func EndSpan(span trace.Span, err error) {
+	EndSpanWithRequeue(span, err, nil)
+}
+
+// EndSpanWithRequeue ends the span like EndSpan, except that the errors for which requeueAfter returns
+// true, such as the operations that are queued or whose task is still in progress, are not recorded as
+// errors. Their delay is recorded as the RequeueAfterKey attribute of the span instead.
+func EndSpanWithRequeue(span trace.Span, err error, requeueAfter RequeueFunc) {
+	if err != nil && requeueAfter != nil {
+		if after, ok := requeueAfter(err); ok {
+			span.SetAttributes(RequeueAfterKey.String(after.String()))
+			span.AddEvent(err.Error())
+			span.End()
+			return
+		}
+	}
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
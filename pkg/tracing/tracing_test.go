@@ -0,0 +1,103 @@
+// Copyright (c) 2023 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package tracing_test
+
+import (
+	"context"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/pkg/errors"
+	"github.com/vmware/govmomi/vim25/types"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/vmware-tanzu/vm-operator/pkg/tracing"
+)
+
+var _ = Describe("Tracing", func() {
+
+	var (
+		recorder *tracetest.SpanRecorder
+		provider trace.TracerProvider
+	)
+
+	BeforeEach(func() {
+		provider = otel.GetTracerProvider()
+		recorder = tracetest.NewSpanRecorder()
+		otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	})
+
+	AfterEach(func() {
+		otel.SetTracerProvider(provider)
+	})
+
+	It("does nothing when the endpoint is not set", func() {
+		shutdown, err := tracing.Init(context.Background(), "vm-operator", tracing.Options{})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(shutdown(context.Background())).To(Succeed())
+	})
+
+	It("records the vCenter opID of the context", func() {
+		ctx := context.WithValue(context.Background(), types.ID{}, "vm-opid")
+
+		ctx, parent := tracing.StartSpan(ctx, "parent")
+		_, child := tracing.StartClientSpan(ctx, "child", tracing.VCenterKey.String("vc"))
+		tracing.EndSpan(child, nil)
+		tracing.EndSpan(parent, nil)
+
+		spans := recorder.Ended()
+		Expect(spans).To(HaveLen(2))
+		Expect(spans[0].Name()).To(Equal("child"))
+		Expect(spans[0].SpanKind()).To(Equal(trace.SpanKindClient))
+		Expect(spans[0].Parent().SpanID()).To(Equal(spans[1].SpanContext().SpanID()))
+		Expect(spans[0].Attributes()).To(ContainElements(
+			tracing.VCenterKey.String("vc"),
+			tracing.OpIDKey.String("vm-opid")))
+		Expect(spans[1].Attributes()).To(ContainElement(tracing.OpIDKey.String("vm-opid")))
+	})
+
+	It("records the error of the span", func() {
+		_, span := tracing.StartSpan(context.Background(), "span")
+		tracing.EndSpan(span, errors.New("boom"))
+
+		spans := recorder.Ended()
+		Expect(spans).To(HaveLen(1))
+		Expect(spans[0].Status().Code).To(Equal(codes.Error))
+		Expect(spans[0].Status().Description).To(Equal("boom"))
+	})
+
+	It("does not record a requeue as an error", func() {
+		errQueued := errors.New("queued")
+		requeueAfter := func(err error) (time.Duration, bool) {
+			return 5 * time.Second, errors.Is(err, errQueued)
+		}
+
+		_, span := tracing.StartSpan(context.Background(), "span")
+		tracing.EndSpanWithRequeue(span, errors.Wrap(errQueued, "create"), requeueAfter)
+
+		spans := recorder.Ended()
+		Expect(spans).To(HaveLen(1))
+		Expect(spans[0].Status().Code).To(Equal(codes.Unset))
+		Expect(spans[0].Attributes()).To(ContainElement(tracing.RequeueAfterKey.String("5s")))
+	})
+
+	It("records the error of the span when it is not a requeue", func() {
+		requeueAfter := func(err error) (time.Duration, bool) {
+			return 0, false
+		}
+
+		_, span := tracing.StartSpan(context.Background(), "span")
+		tracing.EndSpanWithRequeue(span, errors.New("boom"), requeueAfter)
+
+		spans := recorder.Ended()
+		Expect(spans).To(HaveLen(1))
+		Expect(spans[0].Status().Code).To(Equal(codes.Error))
+		Expect(spans[0].Attributes()).ToNot(ContainElement(HaveField("Key", tracing.RequeueAfterKey)))
+	})
+})
@@ -1,4 +1,4 @@
-// Copyright (c) 2022 VMware, Inc. All Rights Reserved.
+// Copyright (c) 2022-2023 VMware, Inc. All Rights Reserved.
 // SPDX-License-Identifier: Apache-2.0
 
 package util_test
@@ -267,6 +267,18 @@ var _ = Describe("SanitizeVMClassConfigSpec", func() {
 						VirtualController: vimTypes.VirtualController{},
 					},
 				},
+				&vimTypes.VirtualDeviceConfigSpec{
+					Operation: vimTypes.VirtualDeviceConfigSpecOperationAdd,
+					Device: &vimTypes.ParaVirtualSCSIController{
+						VirtualSCSIController: vimTypes.VirtualSCSIController{},
+					},
+				},
+				&vimTypes.VirtualDeviceConfigSpec{
+					Operation: vimTypes.VirtualDeviceConfigSpecOperationAdd,
+					Device: &vimTypes.VirtualAHCIController{
+						VirtualSATAController: vimTypes.VirtualSATAController{},
+					},
+				},
 				&vimTypes.VirtualDeviceConfigSpec{
 					Operation: vimTypes.VirtualDeviceConfigSpecOperationAdd,
 					Device: &vimTypes.VirtualE1000{
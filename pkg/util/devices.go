@@ -1,4 +1,4 @@
-// Copyright (c) 2022 VMware, Inc. All Rights Reserved.
+// Copyright (c) 2022-2023 VMware, Inc. All Rights Reserved.
 // SPDX-License-Identifier: Apache-2.0
 
 package util
@@ -157,7 +157,8 @@ func IsEthernetCard(dev vimTypes.BaseVirtualDevice) bool {
 
 func isDiskOrDiskController(dev vimTypes.BaseVirtualDevice) bool {
 	switch dev.(type) {
-	case *vimTypes.VirtualDisk, *vimTypes.VirtualIDEController, *vimTypes.VirtualNVMEController, *vimTypes.VirtualSATAController, *vimTypes.VirtualSCSIController:
+	case *vimTypes.VirtualDisk, *vimTypes.VirtualIDEController, *vimTypes.VirtualNVMEController,
+		vimTypes.BaseVirtualSATAController, vimTypes.BaseVirtualSCSIController:
 		return true
 	default:
 		return false
@@ -0,0 +1,269 @@
+// Copyright (c) 2023 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+// Package vmimage derives the product, version and channel labels of
+// VirtualMachineImages, and resolves the "<channel>@latest" image selectors
+// of VirtualMachines to concrete images.
+package vmimage
+
+import (
+	"context"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	vmopv1 "github.com/vmware-tanzu/vm-operator/api/v1alpha1"
+	"github.com/vmware-tanzu/vm-operator/pkg/lib"
+)
+
+var (
+	invalidLabelValueChars = regexp.MustCompile(`[^a-z0-9._-]+`)
+	versionParts           = regexp.MustCompile(`[0-9]+|[^0-9]+`)
+)
+
+// LabelValue returns the value sanitized to a valid label value: it is lower
+// cased, each run of invalid characters is replaced by a dash, and it is
+// truncated to the maximum label value length.
+func LabelValue(value string) string {
+	value = invalidLabelValueChars.ReplaceAllString(strings.ToLower(strings.TrimSpace(value)), "-")
+	value = strings.Trim(value, "-_.")
+	if len(value) > validation.LabelValueMaxLength {
+		value = strings.TrimRight(value[:validation.LabelValueMaxLength], "-_.")
+	}
+	return value
+}
+
+// ProductLabels returns the product, version and channel labels for the
+// image's product info. A label is omitted when its value would be empty.
+func ProductLabels(productInfo vmopv1.VirtualMachineImageProductInfo) map[string]string {
+	labels := map[string]string{}
+
+	product := LabelValue(productInfo.Product)
+	version := LabelValue(productInfo.Version)
+	if product != "" {
+		labels[vmopv1.VirtualMachineImageProductLabel] = product
+	}
+	if version != "" {
+		labels[vmopv1.VirtualMachineImageVersionLabel] = version
+	}
+	if product != "" && version != "" {
+		if channel := LabelValue(product + "-" + version); channel != "" {
+			labels[vmopv1.VirtualMachineImageChannelLabel] = channel
+		}
+	}
+
+	return labels
+}
+
+// SetProductLabels sets the product, version and channel labels of the image
+// from its product info, removing any of these labels that no longer apply.
+func SetProductLabels(obj metav1.Object, productInfo vmopv1.VirtualMachineImageProductInfo) {
+	labels := obj.GetLabels()
+	if labels == nil {
+		labels = map[string]string{}
+	}
+
+	productLabels := ProductLabels(productInfo)
+	for _, key := range []string{
+		vmopv1.VirtualMachineImageProductLabel,
+		vmopv1.VirtualMachineImageVersionLabel,
+		vmopv1.VirtualMachineImageChannelLabel,
+	} {
+		if v, ok := productLabels[key]; ok {
+			labels[key] = v
+		} else {
+			delete(labels, key)
+		}
+	}
+
+	obj.SetLabels(labels)
+}
+
+// SetDeprecatedLabel sets the deprecated label of the image to that of the
+// provider item it is created from, removing it if the item is not deprecated.
+func SetDeprecatedLabel(obj metav1.Object, itemLabels map[string]string) {
+	labels := obj.GetLabels()
+	if IsDeprecatedLabels(itemLabels) {
+		if labels == nil {
+			labels = map[string]string{}
+		}
+		labels[vmopv1.VirtualMachineImageDeprecatedLabel] = "true"
+	} else {
+		delete(labels, vmopv1.VirtualMachineImageDeprecatedLabel)
+	}
+	obj.SetLabels(labels)
+}
+
+// IsDeprecatedLabels returns true if the labels mark an image as deprecated.
+func IsDeprecatedLabels(labels map[string]string) bool {
+	return strings.EqualFold(labels[vmopv1.VirtualMachineImageDeprecatedLabel], "true")
+}
+
+// ParseSelector returns the channel of a "<channel>@latest" image selector.
+// False is returned if the image name is not a selector.
+func ParseSelector(imageName string) (string, bool) {
+	if !strings.HasSuffix(imageName, vmopv1.VirtualMachineImageLatestSelectorSuffix) {
+		return "", false
+	}
+	return strings.TrimSuffix(imageName, vmopv1.VirtualMachineImageLatestSelectorSuffix), true
+}
+
+// candidate is a VirtualMachineImage or ClusterVirtualMachineImage that may be
+// selected by an image selector.
+type candidate struct {
+	metav1.ObjectMeta
+	productInfo vmopv1.VirtualMachineImageProductInfo
+}
+
+func (c candidate) version() string {
+	if c.productInfo.FullVersion != "" {
+		return c.productInfo.FullVersion
+	}
+	return c.productInfo.Version
+}
+
+// ResolveLatest returns the name of the latest non-deprecated image of the
+// channel, which matches either the images' channel or product label, that is
+// available to VirtualMachines in the namespace. The latest image has the
+// highest product version, and then the newest creation timestamp.
+func ResolveLatest(ctx context.Context, c client.Client, namespace, channel string) (string, error) {
+	candidates, err := listCandidates(ctx, c, namespace)
+	if err != nil {
+		return "", err
+	}
+
+	value := LabelValue(channel)
+	var latest *candidate
+	for i := range candidates {
+		img := &candidates[i]
+		if IsDeprecatedLabels(img.Labels) {
+			continue
+		}
+		if img.Labels[vmopv1.VirtualMachineImageChannelLabel] != value &&
+			img.Labels[vmopv1.VirtualMachineImageProductLabel] != value {
+			continue
+		}
+		if latest == nil || isNewer(img, latest) {
+			latest = img
+		}
+	}
+
+	if latest == nil {
+		return "", errors.Errorf("no non-deprecated VirtualMachineImage matches %q",
+			channel+vmopv1.VirtualMachineImageLatestSelectorSuffix)
+	}
+	return latest.Name, nil
+}
+
+func listCandidates(ctx context.Context, c client.Client, namespace string) ([]candidate, error) {
+	var candidates []candidate
+
+	if !lib.IsWCPVMImageRegistryEnabled() {
+		// The images are effectively cluster scoped without the image registry.
+		vmiList := &vmopv1.VirtualMachineImageList{}
+		if err := c.List(ctx, vmiList); err != nil {
+			return nil, errors.Wrap(err, "failed to list VirtualMachineImages")
+		}
+		for _, vmi := range vmiList.Items {
+			candidates = append(candidates, candidate{ObjectMeta: vmi.ObjectMeta, productInfo: vmi.Spec.ProductInfo})
+		}
+		return candidates, nil
+	}
+
+	vmiList := &vmopv1.VirtualMachineImageList{}
+	if err := c.List(ctx, vmiList, client.InNamespace(namespace)); err != nil {
+		return nil, errors.Wrapf(err, "failed to list VirtualMachineImages in namespace %s", namespace)
+	}
+	for _, vmi := range vmiList.Items {
+		candidates = append(candidates, candidate{ObjectMeta: vmi.ObjectMeta, productInfo: vmi.Spec.ProductInfo})
+	}
+
+	cvmiList := &vmopv1.ClusterVirtualMachineImageList{}
+	if err := c.List(ctx, cvmiList); err != nil {
+		return nil, errors.Wrap(err, "failed to list ClusterVirtualMachineImages")
+	}
+	for _, cvmi := range cvmiList.Items {
+		candidates = append(candidates, candidate{ObjectMeta: cvmi.ObjectMeta, productInfo: cvmi.Spec.ProductInfo})
+	}
+
+	return candidates, nil
+}
+
+func isNewer(a, b *candidate) bool {
+	if cmp := CompareVersions(a.version(), b.version()); cmp != 0 {
+		return cmp > 0
+	}
+	if !a.CreationTimestamp.Equal(&b.CreationTimestamp) {
+		return b.CreationTimestamp.Before(&a.CreationTimestamp)
+	}
+	return a.Name > b.Name
+}
+
+// CompareVersions compares two product versions, returning -1, 0 or 1. The
+// numeric parts of the versions are compared numerically and the other parts
+// lexically, so "22.04.10" is greater than "22.04.9".
+func CompareVersions(a, b string) int {
+	aParts := versionParts.FindAllString(a, -1)
+	bParts := versionParts.FindAllString(b, -1)
+
+	for i := 0; i < len(aParts) && i < len(bParts); i++ {
+		aNum, aErr := strconv.ParseUint(aParts[i], 10, 64)
+		bNum, bErr := strconv.ParseUint(bParts[i], 10, 64)
+
+		switch {
+		case aErr == nil && bErr == nil:
+			if aNum != bNum {
+				if aNum > bNum {
+					return 1
+				}
+				return -1
+			}
+		case aParts[i] != bParts[i]:
+			if aParts[i] > bParts[i] {
+				return 1
+			}
+			return -1
+		}
+	}
+
+	switch {
+	case len(aParts) > len(bParts):
+		return 1
+	case len(aParts) < len(bParts):
+		return -1
+	}
+	return 0
+}
+
+// IsDeprecated returns true if the image, that is available to VirtualMachines
+// in the namespace, is deprecated. False is returned if the image does not exist.
+func IsDeprecated(ctx context.Context, c client.Client, namespace, imageName string) (bool, error) {
+	if !lib.IsWCPVMImageRegistryEnabled() {
+		vmi := &vmopv1.VirtualMachineImage{}
+		if err := c.Get(ctx, client.ObjectKey{Name: imageName}, vmi); err != nil {
+			return false, client.IgnoreNotFound(err)
+		}
+		return IsDeprecatedLabels(vmi.Labels), nil
+	}
+
+	vmi := &vmopv1.VirtualMachineImage{}
+	err := c.Get(ctx, client.ObjectKey{Name: imageName, Namespace: namespace}, vmi)
+	if err == nil {
+		return IsDeprecatedLabels(vmi.Labels), nil
+	}
+	if !apierrors.IsNotFound(err) {
+		return false, err
+	}
+
+	cvmi := &vmopv1.ClusterVirtualMachineImage{}
+	if err := c.Get(ctx, client.ObjectKey{Name: imageName}, cvmi); err != nil {
+		return false, client.IgnoreNotFound(err)
+	}
+	return IsDeprecatedLabels(cvmi.Labels), nil
+}
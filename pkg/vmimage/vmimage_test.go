@@ -0,0 +1,228 @@
+// Copyright (c) 2023 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package vmimage_test
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	vmopv1 "github.com/vmware-tanzu/vm-operator/api/v1alpha1"
+	"github.com/vmware-tanzu/vm-operator/pkg/lib"
+	"github.com/vmware-tanzu/vm-operator/pkg/vmimage"
+	"github.com/vmware-tanzu/vm-operator/test/builder"
+)
+
+var _ = Describe("Labels", func() {
+
+	DescribeTable("LabelValue",
+		func(value, expected string) {
+			Expect(vmimage.LabelValue(value)).To(Equal(expected))
+		},
+		Entry("lower cases", "Ubuntu", "ubuntu"),
+		Entry("replaces invalid characters", "Photon OS (x86_64)", "photon-os-x86_64"),
+		Entry("trims non alphanumeric ends", " -22.04- ", "22.04"),
+		Entry("truncates", strings.Repeat("a", 70), strings.Repeat("a", 63)),
+		Entry("empty", "()", ""),
+	)
+
+	It("derives the product labels from the product info", func() {
+		labels := vmimage.ProductLabels(vmopv1.VirtualMachineImageProductInfo{
+			Product:     "Ubuntu",
+			Version:     "22.04",
+			FullVersion: "22.04.20230601",
+		})
+		Expect(labels).To(Equal(map[string]string{
+			vmopv1.VirtualMachineImageProductLabel: "ubuntu",
+			vmopv1.VirtualMachineImageVersionLabel: "22.04",
+			vmopv1.VirtualMachineImageChannelLabel: "ubuntu-22.04",
+		}))
+	})
+
+	It("omits the channel label without a version", func() {
+		labels := vmimage.ProductLabels(vmopv1.VirtualMachineImageProductInfo{Product: "Ubuntu"})
+		Expect(labels).To(Equal(map[string]string{vmopv1.VirtualMachineImageProductLabel: "ubuntu"}))
+	})
+
+	It("sets and removes the product labels, preserving other labels", func() {
+		vmi := &vmopv1.VirtualMachineImage{}
+		vmi.Labels = map[string]string{
+			"foo":                                  "bar",
+			vmopv1.VirtualMachineImageVersionLabel: "20.04",
+		}
+
+		vmimage.SetProductLabels(vmi, vmopv1.VirtualMachineImageProductInfo{Product: "Ubuntu"})
+		Expect(vmi.Labels).To(Equal(map[string]string{
+			"foo":                                  "bar",
+			vmopv1.VirtualMachineImageProductLabel: "ubuntu",
+		}))
+	})
+
+	It("sets and removes the deprecated label from the item's labels", func() {
+		vmi := &vmopv1.VirtualMachineImage{}
+
+		vmimage.SetDeprecatedLabel(vmi, map[string]string{vmopv1.VirtualMachineImageDeprecatedLabel: "True"})
+		Expect(vmi.Labels).To(HaveKeyWithValue(vmopv1.VirtualMachineImageDeprecatedLabel, "true"))
+		Expect(vmimage.IsDeprecatedLabels(vmi.Labels)).To(BeTrue())
+
+		vmimage.SetDeprecatedLabel(vmi, nil)
+		Expect(vmi.Labels).ToNot(HaveKey(vmopv1.VirtualMachineImageDeprecatedLabel))
+		Expect(vmimage.IsDeprecatedLabels(vmi.Labels)).To(BeFalse())
+	})
+})
+
+var _ = Describe("ParseSelector", func() {
+
+	It("returns the channel of a selector", func() {
+		channel, ok := vmimage.ParseSelector("ubuntu-22.04@latest")
+		Expect(ok).To(BeTrue())
+		Expect(channel).To(Equal("ubuntu-22.04"))
+	})
+
+	It("returns false for an image name", func() {
+		_, ok := vmimage.ParseSelector("vmi-0123456789")
+		Expect(ok).To(BeFalse())
+	})
+})
+
+var _ = DescribeTable("CompareVersions",
+	func(a, b string, expected int) {
+		Expect(vmimage.CompareVersions(a, b)).To(Equal(expected))
+		Expect(vmimage.CompareVersions(b, a)).To(Equal(-expected))
+	},
+	Entry("equal", "22.04.1", "22.04.1", 0),
+	Entry("numeric parts", "22.04.10", "22.04.9", 1),
+	Entry("more parts", "22.04.1", "22.04", 1),
+	Entry("non numeric parts", "1.0-rc2", "1.0-rc1", 1),
+	Entry("numeric and non numeric parts", "1.0.1", "1.0-rc1", 1),
+)
+
+var _ = Describe("ResolveLatest and IsDeprecated", func() {
+
+	const ns = "my-namespace"
+
+	var (
+		ctx              context.Context
+		initObjects      []client.Object
+		k8sClient        client.Client
+		oldRegistryFSSFn func() bool
+		registryEnabled  bool
+	)
+
+	newVMI := func(name, namespace, channel, fullVersion string, age time.Duration) *vmopv1.VirtualMachineImage {
+		vmi := &vmopv1.VirtualMachineImage{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:              name,
+				Namespace:         namespace,
+				CreationTimestamp: metav1.NewTime(time.Now().Add(-age).Truncate(time.Second)),
+			},
+			Spec: vmopv1.VirtualMachineImageSpec{
+				ProductInfo: vmopv1.VirtualMachineImageProductInfo{
+					Product:     strings.Split(channel, "-")[0],
+					Version:     strings.Split(channel, "-")[1],
+					FullVersion: fullVersion,
+				},
+			},
+		}
+		vmimage.SetProductLabels(vmi, vmi.Spec.ProductInfo)
+		return vmi
+	}
+
+	newCVMI := func(name, channel, fullVersion string) *vmopv1.ClusterVirtualMachineImage {
+		vmi := newVMI(name, "", channel, fullVersion, time.Hour)
+		return &vmopv1.ClusterVirtualMachineImage{ObjectMeta: vmi.ObjectMeta, Spec: vmi.Spec}
+	}
+
+	deprecate := func(obj client.Object) client.Object {
+		vmimage.SetDeprecatedLabel(obj, map[string]string{vmopv1.VirtualMachineImageDeprecatedLabel: "true"})
+		return obj
+	}
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		registryEnabled = true
+		oldRegistryFSSFn = lib.IsWCPVMImageRegistryEnabled
+		lib.IsWCPVMImageRegistryEnabled = func() bool {
+			return registryEnabled
+		}
+	})
+
+	JustBeforeEach(func() {
+		k8sClient = builder.NewFakeClient(initObjects...)
+	})
+
+	AfterEach(func() {
+		lib.IsWCPVMImageRegistryEnabled = oldRegistryFSSFn
+		initObjects = nil
+	})
+
+	When("the image registry is enabled", func() {
+
+		BeforeEach(func() {
+			initObjects = []client.Object{
+				newVMI("vmi-1", ns, "ubuntu-22.04", "22.04.9", time.Hour),
+				newVMI("vmi-2", ns, "ubuntu-22.04", "22.04.10", time.Hour),
+				newVMI("vmi-other-ns", "other-namespace", "ubuntu-22.04", "22.04.99", time.Hour),
+				deprecate(newVMI("vmi-deprecated", ns, "ubuntu-22.04", "22.04.11", time.Hour)),
+				newVMI("vmi-focal", ns, "ubuntu-20.04", "20.04.50", time.Hour),
+				newCVMI("vmi-cluster", "ubuntu-24.04", "24.04.1"),
+			}
+		})
+
+		It("resolves the channel to the latest non-deprecated image in the namespace", func() {
+			name, err := vmimage.ResolveLatest(ctx, k8sClient, ns, "ubuntu-22.04")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(name).To(Equal("vmi-2"))
+		})
+
+		It("resolves the product to the latest image of any channel, including cluster images", func() {
+			name, err := vmimage.ResolveLatest(ctx, k8sClient, ns, "Ubuntu")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(name).To(Equal("vmi-cluster"))
+		})
+
+		It("returns an error when no image matches", func() {
+			_, err := vmimage.ResolveLatest(ctx, k8sClient, ns, "photon-5.0")
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring(`no non-deprecated VirtualMachineImage matches "photon-5.0@latest"`))
+		})
+
+		It("returns if the namespace or cluster image is deprecated", func() {
+			Expect(vmimage.IsDeprecated(ctx, k8sClient, ns, "vmi-deprecated")).To(BeTrue())
+			Expect(vmimage.IsDeprecated(ctx, k8sClient, ns, "vmi-1")).To(BeFalse())
+			Expect(vmimage.IsDeprecated(ctx, k8sClient, ns, "vmi-cluster")).To(BeFalse())
+			Expect(vmimage.IsDeprecated(ctx, k8sClient, ns, "vmi-does-not-exist")).To(BeFalse())
+		})
+	})
+
+	When("the image registry is disabled", func() {
+
+		BeforeEach(func() {
+			registryEnabled = false
+			initObjects = []client.Object{
+				newVMI("ubuntu-old", "", "ubuntu-22.04", "22.04.1", 2*time.Hour),
+				newVMI("ubuntu-new", "", "ubuntu-22.04", "22.04.1", time.Hour),
+				deprecate(newVMI("ubuntu-deprecated", "", "ubuntu-22.04", "22.04.2", time.Hour)),
+			}
+		})
+
+		It("resolves to the newest image when the versions are equal", func() {
+			name, err := vmimage.ResolveLatest(ctx, k8sClient, ns, "ubuntu-22.04")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(name).To(Equal("ubuntu-new"))
+		})
+
+		It("returns if the image is deprecated", func() {
+			Expect(vmimage.IsDeprecated(ctx, k8sClient, ns, "ubuntu-deprecated")).To(BeTrue())
+			Expect(vmimage.IsDeprecated(ctx, k8sClient, ns, "ubuntu-new")).To(BeFalse())
+		})
+	})
+})
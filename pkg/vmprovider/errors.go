@@ -0,0 +1,65 @@
+// Copyright (c) 2023 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package vmprovider
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// OperationQueuedError is returned by a provider when an operation is queued behind the operations of other
+// objects. The operation keeps its place in the queue when it is retried after RequeueAfter.
+type OperationQueuedError struct {
+	Operation    string
+	RequeueAfter time.Duration
+}
+
+func (e OperationQueuedError) Error() string {
+	return fmt.Sprintf("%s operation is queued", e.Operation)
+}
+
+// TaskInProgressError is returned by a provider when a task it started on a VM is still running. The task
+// is checked again when the VM is reconciled after RequeueAfter.
+type TaskInProgressError struct {
+	TaskRef      string
+	RequeueAfter time.Duration
+}
+
+func (e TaskInProgressError) Error() string {
+	return fmt.Sprintf("task %s is in progress", e.TaskRef)
+}
+
+// GuestShutdownInProgressError is returned by a provider while it waits for the guest OS of a VM to shut
+// down. The VM is checked again when it is reconciled after RequeueAfter.
+type GuestShutdownInProgressError struct {
+	RequeueAfter time.Duration
+}
+
+func (e GuestShutdownInProgressError) Error() string {
+	return "guest OS shutdown is in progress"
+}
+
+// RequeueAfter returns the RequeueAfter of the OperationQueuedError, TaskInProgressError or
+// GuestShutdownInProgressError in the chain of err, and false when err is another error. The object of these errors is expected to be reconciled again
+// after the returned delay, instead of being reported as failed.
+func RequeueAfter(err error) (time.Duration, bool) {
+	queuedErr := OperationQueuedError{}
+	if errors.As(err, &queuedErr) {
+		return queuedErr.RequeueAfter, true
+	}
+
+	taskErr := TaskInProgressError{}
+	if errors.As(err, &taskErr) {
+		return taskErr.RequeueAfter, true
+	}
+
+	shutdownErr := GuestShutdownInProgressError{}
+	if errors.As(err, &shutdownErr) {
+		return shutdownErr.RequeueAfter, true
+	}
+
+	return 0, false
+}
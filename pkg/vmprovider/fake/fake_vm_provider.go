@@ -16,6 +16,8 @@ import (
 	imgregv1a1 "github.com/vmware-tanzu/vm-operator/external/image-registry/api/v1alpha1"
 
 	vmopv1 "github.com/vmware-tanzu/vm-operator/api/v1alpha1"
+	"github.com/vmware-tanzu/vm-operator/pkg/imagetrust"
+	"github.com/vmware-tanzu/vm-operator/pkg/ociregistry"
 	"github.com/vmware-tanzu/vm-operator/pkg/vmprovider"
 )
 
@@ -31,19 +33,32 @@ type funcs struct {
 	DeleteVirtualMachineFn         func(ctx context.Context, vm *vmopv1.VirtualMachine) error
 	PublishVirtualMachineFn        func(ctx context.Context, vm *vmopv1.VirtualMachine,
 		vmPub *vmopv1.VirtualMachinePublishRequest, cl *imgregv1a1.ContentLibrary, actID string) (string, error)
-	GetVirtualMachineGuestHeartbeatFn  func(ctx context.Context, vm *vmopv1.VirtualMachine) (vmopv1.GuestHeartbeatStatus, error)
-	GetVirtualMachineWebMKSTicketFn    func(ctx context.Context, vm *vmopv1.VirtualMachine, pubKey string) (string, error)
-	GetVirtualMachineHardwareVersionFn func(ctx context.Context, vm *vmopv1.VirtualMachine) (int32, error)
+	PublishVirtualMachineToOCIRegistryFn func(ctx context.Context, vm *vmopv1.VirtualMachine,
+		vmPub *vmopv1.VirtualMachinePublishRequest, registry *ociregistry.Client) (string, error)
+	GetVirtualMachineGuestHeartbeatFn   func(ctx context.Context, vm *vmopv1.VirtualMachine) (vmopv1.GuestHeartbeatStatus, error)
+	GetVirtualMachineWebMKSTicketFn     func(ctx context.Context, vm *vmopv1.VirtualMachine, pubKey string) (string, error)
+	EnsureVirtualMachineSerialConsoleFn func(ctx context.Context, vm *vmopv1.VirtualMachine) (string, error)
+	RemoveVirtualMachineSerialConsoleFn func(ctx context.Context, vm *vmopv1.VirtualMachine) error
+	GetVirtualMachineHardwareVersionFn  func(ctx context.Context, vm *vmopv1.VirtualMachine) (int32, error)
+	MigrateVirtualMachineFn             func(ctx context.Context, vm *vmopv1.VirtualMachine,
+		target vmopv1.VirtualMachineMigrationRequestLocation) (string, error)
 
 	ListItemsFromContentLibraryFn              func(ctx context.Context, contentLibrary *vmopv1.ContentLibraryProvider) ([]string, error)
 	GetVirtualMachineImageFromContentLibraryFn func(ctx context.Context, contentLibrary *vmopv1.ContentLibraryProvider, itemID string,
 		currentCLImages map[string]vmopv1.VirtualMachineImage) (*vmopv1.VirtualMachineImage, error)
-	GetItemFromLibraryByNameFn func(ctx context.Context, contentLibrary, itemName string) (*library.Item, error)
-	UpdateContentLibraryItemFn func(ctx context.Context, itemID, newName string, newDescription *string) error
-	SyncVirtualMachineImageFn  func(ctx context.Context, cli, vmi client.Object) error
+	GetItemFromLibraryByNameFn        func(ctx context.Context, contentLibrary, itemName string) (*library.Item, error)
+	UpdateContentLibraryItemFn        func(ctx context.Context, itemID, newName string, newDescription *string) error
+	DeleteContentLibraryItemFn        func(ctx context.Context, itemID string) error
+	ImportContentLibraryItemFromURLFn func(ctx context.Context, cl *imgregv1a1.ContentLibrary, itemName, itemDescription,
+		sourceURL string, checksum *library.Checksum) (string, string, error)
+	GetContentLibraryItemImportStatusFn func(ctx context.Context, sessionID string) (*vmprovider.ImportStatus, error)
+	CompleteContentLibraryItemImportFn  func(ctx context.Context, sessionID string) error
+	SyncVirtualMachineImageFn           func(ctx context.Context, cli, vmi client.Object) error
+	VerifyVirtualMachineImageFn         func(ctx context.Context, cli client.Object, policy *imagetrust.Policy) error
 
 	UpdateVcPNIDFn  func(ctx context.Context, vcPNID, vcPort string) error
 	ResetVcClientFn func(ctx context.Context)
+	GetConditionsFn func() vmopv1.Conditions
 
 	CreateOrUpdateVirtualMachineSetResourcePolicyFn func(ctx context.Context, rp *vmopv1.VirtualMachineSetResourcePolicy) error
 	IsVirtualMachineSetResourcePolicyReadyFn        func(ctx context.Context, azName string, rp *vmopv1.VirtualMachineSetResourcePolicy) (bool, error)
@@ -51,6 +66,7 @@ type funcs struct {
 	ComputeCPUMinFrequencyFn                        func(ctx context.Context) error
 
 	GetTasksByActIDFn func(ctx context.Context, actID string) (tasksInfo []vimTypes.TaskInfo, retErr error)
+	GetTaskInfoFn     func(ctx context.Context, vm *vmopv1.VirtualMachine, taskID string) (*vimTypes.TaskInfo, error)
 }
 
 type VMProvider struct {
@@ -112,6 +128,22 @@ func (s *VMProvider) PublishVirtualMachine(ctx context.Context, vm *vmopv1.Virtu
 	return "dummy-id", nil
 }
 
+func (s *VMProvider) PublishVirtualMachineToOCIRegistry(ctx context.Context, vm *vmopv1.VirtualMachine,
+	vmPub *vmopv1.VirtualMachinePublishRequest, registry *ociregistry.Client) (string, error) {
+	s.Lock()
+	s.isPublishVMCalled = true
+	fn := s.PublishVirtualMachineToOCIRegistryFn
+	s.Unlock()
+
+	// The lock is not held while calling the override since pushing to the
+	// registry is done asynchronously by the caller.
+	if fn != nil {
+		return fn(ctx, vm, vmPub, registry)
+	}
+
+	return "sha256:dummy-digest", nil
+}
+
 func (s *VMProvider) GetVirtualMachineGuestHeartbeat(ctx context.Context, vm *vmopv1.VirtualMachine) (vmopv1.GuestHeartbeatStatus, error) {
 	s.Lock()
 	defer s.Unlock()
@@ -130,6 +162,24 @@ func (s *VMProvider) GetVirtualMachineWebMKSTicket(ctx context.Context, vm *vmop
 	return "", nil
 }
 
+func (s *VMProvider) EnsureVirtualMachineSerialConsole(ctx context.Context, vm *vmopv1.VirtualMachine) (string, error) {
+	s.Lock()
+	defer s.Unlock()
+	if s.EnsureVirtualMachineSerialConsoleFn != nil {
+		return s.EnsureVirtualMachineSerialConsoleFn(ctx, vm)
+	}
+	return "", nil
+}
+
+func (s *VMProvider) RemoveVirtualMachineSerialConsole(ctx context.Context, vm *vmopv1.VirtualMachine) error {
+	s.Lock()
+	defer s.Unlock()
+	if s.RemoveVirtualMachineSerialConsoleFn != nil {
+		return s.RemoveVirtualMachineSerialConsoleFn(ctx, vm)
+	}
+	return nil
+}
+
 func (s *VMProvider) GetVirtualMachineHardwareVersion(ctx context.Context, vm *vmopv1.VirtualMachine) (int32, error) {
 	s.Lock()
 	defer s.Unlock()
@@ -139,6 +189,16 @@ func (s *VMProvider) GetVirtualMachineHardwareVersion(ctx context.Context, vm *v
 	return 13, nil
 }
 
+func (s *VMProvider) MigrateVirtualMachine(ctx context.Context, vm *vmopv1.VirtualMachine,
+	target vmopv1.VirtualMachineMigrationRequestLocation) (string, error) {
+	s.Lock()
+	defer s.Unlock()
+	if s.MigrateVirtualMachineFn != nil {
+		return s.MigrateVirtualMachineFn(ctx, vm, target)
+	}
+	return "task-1", nil
+}
+
 func (s *VMProvider) CreateOrUpdateVirtualMachineSetResourcePolicy(ctx context.Context, resourcePolicy *vmopv1.VirtualMachineSetResourcePolicy) error {
 	s.Lock()
 	defer s.Unlock()
@@ -207,6 +267,17 @@ func (s *VMProvider) ResetVcClient(ctx context.Context) {
 	}
 }
 
+func (s *VMProvider) GetConditions() vmopv1.Conditions {
+	s.Lock()
+	defer s.Unlock()
+
+	if s.GetConditionsFn != nil {
+		return s.GetConditionsFn()
+	}
+
+	return nil
+}
+
 func (s *VMProvider) ListItemsFromContentLibrary(ctx context.Context, contentLibrary *vmopv1.ContentLibraryProvider) ([]string, error) {
 	s.Lock()
 	defer s.Unlock()
@@ -243,6 +314,18 @@ func (s *VMProvider) SyncVirtualMachineImage(ctx context.Context, cli, vmi clien
 	return nil
 }
 
+func (s *VMProvider) VerifyVirtualMachineImage(ctx context.Context, cli client.Object,
+	policy *imagetrust.Policy) error {
+	s.Lock()
+	defer s.Unlock()
+
+	if s.VerifyVirtualMachineImageFn != nil {
+		return s.VerifyVirtualMachineImageFn(ctx, cli, policy)
+	}
+
+	return nil
+}
+
 func (s *VMProvider) GetItemFromLibraryByName(ctx context.Context,
 	contentLibrary, itemName string) (*library.Item, error) {
 	s.Lock()
@@ -265,6 +348,48 @@ func (s *VMProvider) UpdateContentLibraryItem(ctx context.Context, itemID, newNa
 	return nil
 }
 
+func (s *VMProvider) DeleteContentLibraryItem(ctx context.Context, itemID string) error {
+	s.Lock()
+	defer s.Unlock()
+
+	if s.DeleteContentLibraryItemFn != nil {
+		return s.DeleteContentLibraryItemFn(ctx, itemID)
+	}
+	return nil
+}
+
+func (s *VMProvider) ImportContentLibraryItemFromURL(ctx context.Context, cl *imgregv1a1.ContentLibrary,
+	itemName, itemDescription, sourceURL string, checksum *library.Checksum) (string, string, error) {
+	s.Lock()
+	defer s.Unlock()
+
+	if s.ImportContentLibraryItemFromURLFn != nil {
+		return s.ImportContentLibraryItemFromURLFn(ctx, cl, itemName, itemDescription, sourceURL, checksum)
+	}
+	return "dummy-item-id", "dummy-session-id", nil
+}
+
+func (s *VMProvider) GetContentLibraryItemImportStatus(ctx context.Context,
+	sessionID string) (*vmprovider.ImportStatus, error) {
+	s.Lock()
+	defer s.Unlock()
+
+	if s.GetContentLibraryItemImportStatusFn != nil {
+		return s.GetContentLibraryItemImportStatusFn(ctx, sessionID)
+	}
+	return &vmprovider.ImportStatus{Phase: vmprovider.ImportPhaseDone}, nil
+}
+
+func (s *VMProvider) CompleteContentLibraryItemImport(ctx context.Context, sessionID string) error {
+	s.Lock()
+	defer s.Unlock()
+
+	if s.CompleteContentLibraryItemImportFn != nil {
+		return s.CompleteContentLibraryItemImportFn(ctx, sessionID)
+	}
+	return nil
+}
+
 func (s *VMProvider) GetTasksByActID(ctx context.Context, actID string) (tasksInfo []vimTypes.TaskInfo, retErr error) {
 	s.Lock()
 	defer s.Unlock()
@@ -288,6 +413,20 @@ func (s *VMProvider) GetTasksByActID(ctx context.Context, actID string) (tasksIn
 	return []vimTypes.TaskInfo{task1}, nil
 }
 
+func (s *VMProvider) GetTaskInfo(ctx context.Context, vm *vmopv1.VirtualMachine, taskID string) (*vimTypes.TaskInfo, error) {
+	s.Lock()
+	defer s.Unlock()
+
+	if s.GetTaskInfoFn != nil {
+		return s.GetTaskInfoFn(ctx, vm, taskID)
+	}
+
+	return &vimTypes.TaskInfo{
+		Task:  vimTypes.ManagedObjectReference{Type: "Task", Value: taskID},
+		State: vimTypes.TaskInfoStateSuccess,
+	}, nil
+}
+
 func (s *VMProvider) addToVMMap(vm *vmopv1.VirtualMachine) {
 	objectKey := client.ObjectKey{
 		Namespace: vm.Namespace,
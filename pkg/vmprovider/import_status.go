@@ -0,0 +1,31 @@
+// Copyright (c) 2023 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package vmprovider
+
+// ImportPhase is the phase of an import of a content library item from a URL.
+type ImportPhase string
+
+const (
+	// ImportPhaseDownloading indicates the files are being pulled into the library item.
+	ImportPhaseDownloading ImportPhase = "Downloading"
+	// ImportPhaseDownloaded indicates all the files have been pulled and the import can be completed.
+	ImportPhaseDownloaded ImportPhase = "Downloaded"
+	// ImportPhaseDone indicates the import was completed and the library item was validated.
+	ImportPhaseDone ImportPhase = "Done"
+	// ImportPhaseDownloadFailed indicates a file could not be pulled or did not match its checksum.
+	ImportPhaseDownloadFailed ImportPhase = "DownloadFailed"
+	// ImportPhaseValidationFailed indicates the library item was not valid once the import was completed.
+	ImportPhaseValidationFailed ImportPhase = "ValidationFailed"
+)
+
+// ImportStatus is the status of an import of a content library item from a URL.
+type ImportStatus struct {
+	Phase ImportPhase
+	// BytesTransferred is the number of bytes pulled so far across all the files.
+	BytesTransferred int64
+	// Size is the total size of all the files, if known.
+	Size int64
+	// Message describes the failure of a failed import.
+	Message string
+}
@@ -14,6 +14,9 @@ import (
 	vmopv1 "github.com/vmware-tanzu/vm-operator/api/v1alpha1"
 
 	imgregv1a1 "github.com/vmware-tanzu/vm-operator/external/image-registry/api/v1alpha1"
+
+	"github.com/vmware-tanzu/vm-operator/pkg/imagetrust"
+	"github.com/vmware-tanzu/vm-operator/pkg/ociregistry"
 )
 
 // VirtualMachineProviderInterface is a plugable interface for VM Providers.
@@ -22,9 +25,15 @@ type VirtualMachineProviderInterface interface {
 	DeleteVirtualMachine(ctx context.Context, vm *vmopv1.VirtualMachine) error
 	PublishVirtualMachine(ctx context.Context, vm *vmopv1.VirtualMachine,
 		vmPub *vmopv1.VirtualMachinePublishRequest, cl *imgregv1a1.ContentLibrary, actID string) (string, error)
+	PublishVirtualMachineToOCIRegistry(ctx context.Context, vm *vmopv1.VirtualMachine,
+		vmPub *vmopv1.VirtualMachinePublishRequest, registry *ociregistry.Client) (string, error)
 	GetVirtualMachineGuestHeartbeat(ctx context.Context, vm *vmopv1.VirtualMachine) (vmopv1.GuestHeartbeatStatus, error)
 	GetVirtualMachineWebMKSTicket(ctx context.Context, vm *vmopv1.VirtualMachine, pubKey string) (string, error)
+	EnsureVirtualMachineSerialConsole(ctx context.Context, vm *vmopv1.VirtualMachine) (string, error)
+	RemoveVirtualMachineSerialConsole(ctx context.Context, vm *vmopv1.VirtualMachine) error
 	GetVirtualMachineHardwareVersion(ctx context.Context, vm *vmopv1.VirtualMachine) (int32, error)
+	MigrateVirtualMachine(ctx context.Context, vm *vmopv1.VirtualMachine,
+		target vmopv1.VirtualMachineMigrationRequestLocation) (string, error)
 
 	CreateOrUpdateVirtualMachineSetResourcePolicy(ctx context.Context, resourcePolicy *vmopv1.VirtualMachineSetResourcePolicy) error
 	IsVirtualMachineSetResourcePolicyReady(ctx context.Context, availabilityZoneName string, resourcePolicy *vmopv1.VirtualMachineSetResourcePolicy) (bool, error)
@@ -34,13 +43,22 @@ type VirtualMachineProviderInterface interface {
 	UpdateVcPNID(ctx context.Context, vcPNID, vcPort string) error
 	ResetVcClient(ctx context.Context)
 	ComputeCPUMinFrequency(ctx context.Context) error
+	// GetConditions returns the provider-wide conditions, such as whether the vCenters are reachable.
+	GetConditions() vmopv1.Conditions
 
 	ListItemsFromContentLibrary(ctx context.Context, contentLibrary *vmopv1.ContentLibraryProvider) ([]string, error)
 	GetVirtualMachineImageFromContentLibrary(ctx context.Context, contentLibrary *vmopv1.ContentLibraryProvider, itemID string,
 		currentCLImages map[string]vmopv1.VirtualMachineImage) (*vmopv1.VirtualMachineImage, error)
 	GetItemFromLibraryByName(ctx context.Context, contentLibrary, itemName string) (*library.Item, error)
 	UpdateContentLibraryItem(ctx context.Context, itemID, newName string, newDescription *string) error
+	DeleteContentLibraryItem(ctx context.Context, itemID string) error
+	ImportContentLibraryItemFromURL(ctx context.Context, cl *imgregv1a1.ContentLibrary, itemName, itemDescription, sourceURL string,
+		checksum *library.Checksum) (string, string, error)
+	GetContentLibraryItemImportStatus(ctx context.Context, sessionID string) (*ImportStatus, error)
+	CompleteContentLibraryItemImport(ctx context.Context, sessionID string) error
 	SyncVirtualMachineImage(ctx context.Context, cli, vmi client.Object) error
+	VerifyVirtualMachineImage(ctx context.Context, cli client.Object, policy *imagetrust.Policy) error
 
 	GetTasksByActID(ctx context.Context, actID string) (tasksInfo []vimTypes.TaskInfo, retErr error)
+	GetTaskInfo(ctx context.Context, vm *vmopv1.VirtualMachine, taskID string) (*vimTypes.TaskInfo, error)
 }
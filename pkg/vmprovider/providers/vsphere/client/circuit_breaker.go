@@ -0,0 +1,195 @@
+// Copyright (c) 2023 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package client
+
+import (
+	"context"
+	"io"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// ErrCircuitOpen is wrapped by the errors of the calls that are failed fast because vCenter is unreachable.
+var ErrCircuitOpen = errors.New("circuit breaker is open")
+
+type circuitState int
+
+const (
+	circuitClosed circuitState = iota
+	circuitOpen
+	circuitHalfOpen
+)
+
+// CircuitBreaker fails the calls to a vCenter fast once it is unreachable. The circuit opens after
+// FailureThreshold consecutive calls fail because vCenter could not be reached or did not respond in time.
+// While it is open calls fail with ErrCircuitOpen, until OpenDuration has passed and a single call is let
+// through to probe vCenter: the circuit closes when the probe succeeds, and opens again when it fails.
+//
+// Calls that vCenter responded to, even with a fault, count as successes. Calls that were canceled by the
+// caller are not counted.
+type CircuitBreaker struct {
+	name             string
+	failureThreshold int
+	openDuration     time.Duration
+	onChange         func(err error)
+
+	mu       sync.Mutex
+	state    circuitState
+	failures int
+	openedAt time.Time
+	lastErr  error
+	probing  bool
+	reported bool
+}
+
+// NewCircuitBreaker returns a closed CircuitBreaker for the vCenter name. onChange, when not nil, is called
+// with nil when vCenter becomes reachable and with the last error when it becomes unreachable. It is called
+// with the CircuitBreaker locked, so the changes are reported in order, and must not call the CircuitBreaker.
+func NewCircuitBreaker(
+	name string,
+	failureThreshold int,
+	openDuration time.Duration,
+	onChange func(err error)) *CircuitBreaker {
+
+	if failureThreshold < 1 {
+		failureThreshold = 1
+	}
+
+	return &CircuitBreaker{
+		name:             name,
+		failureThreshold: failureThreshold,
+		openDuration:     openDuration,
+		onChange:         onChange,
+	}
+}
+
+// Allow returns an error wrapping ErrCircuitOpen when the call must fail fast. Otherwise, the outcome of the
+// call must be passed to Done.
+func (b *CircuitBreaker) Allow() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch b.state {
+	case circuitOpen:
+		if time.Since(b.openedAt) < b.openDuration {
+			return b.openError()
+		}
+		b.state = circuitHalfOpen
+		b.probing = true
+	case circuitHalfOpen:
+		if b.probing {
+			return b.openError()
+		}
+		b.probing = true
+	}
+
+	return nil
+}
+
+// Done records the outcome of a call that was allowed. ctx is the context of the caller, so calls that the
+// caller canceled are not counted.
+func (b *CircuitBreaker) Done(ctx context.Context, err error) {
+	if ctx.Err() != nil {
+		b.mu.Lock()
+		b.probing = false
+		b.mu.Unlock()
+		return
+	}
+
+	if !IsUnreachableError(err) {
+		b.succeeded()
+	} else {
+		b.failed(err)
+	}
+}
+
+// Name returns the name of the vCenter.
+func (b *CircuitBreaker) Name() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.name
+}
+
+func (b *CircuitBreaker) setName(name string) {
+	b.mu.Lock()
+	b.name = name
+	b.mu.Unlock()
+}
+
+// IsOpen returns true when vCenter is unreachable.
+func (b *CircuitBreaker) IsOpen() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.state != circuitClosed
+}
+
+func (b *CircuitBreaker) succeeded() {
+	b.mu.Lock()
+	changed := b.state != circuitClosed || !b.reported
+	if b.state != circuitClosed {
+		log.Info("vCenter is reachable", "vCenter", b.name)
+	}
+	b.state = circuitClosed
+	b.failures = 0
+	b.lastErr = nil
+	b.probing = false
+	b.reported = true
+
+	if changed && b.onChange != nil {
+		b.onChange(nil)
+	}
+	b.mu.Unlock()
+}
+
+func (b *CircuitBreaker) failed(err error) {
+	b.mu.Lock()
+	b.failures++
+	b.lastErr = err
+	b.probing = false
+
+	changed := false
+	switch {
+	case b.state == circuitHalfOpen:
+		b.state = circuitOpen
+		b.openedAt = time.Now()
+	case b.state == circuitClosed && b.failures >= b.failureThreshold:
+		log.Error(err, "vCenter is unreachable", "vCenter", b.name, "failures", b.failures)
+		b.state = circuitOpen
+		b.openedAt = time.Now()
+		changed = true
+	}
+	b.reported = b.reported || changed
+
+	if changed && b.onChange != nil {
+		b.onChange(err)
+	}
+	b.mu.Unlock()
+}
+
+func (b *CircuitBreaker) openError() error {
+	return errors.Wrapf(ErrCircuitOpen, "vCenter %s is unreachable: %v", b.name, b.lastErr)
+}
+
+// IsUnreachableError returns true when err is from a call that did not reach vCenter, or that vCenter did not
+// respond to in time.
+func IsUnreachableError(err error) bool {
+	if err == nil {
+		return false
+	}
+
+	if errors.Is(err, ErrCircuitOpen) ||
+		errors.Is(err, context.DeadlineExceeded) ||
+		errors.Is(err, io.EOF) ||
+		errors.Is(err, io.ErrUnexpectedEOF) {
+		return true
+	}
+
+	var netErr net.Error
+	return errors.As(err, &netErr)
+}
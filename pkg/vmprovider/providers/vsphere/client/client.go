@@ -1,4 +1,4 @@
-// Copyright (c) 2018-2020 VMware, Inc. All Rights Reserved.
+// Copyright (c) 2018-2023 VMware, Inc. All Rights Reserved.
 // SPDX-License-Identifier: Apache-2.0
 
 package client
@@ -7,6 +7,7 @@ import (
 	"context"
 	"net"
 	"net/url"
+	"sync"
 	"time"
 
 	"github.com/pkg/errors"
@@ -14,6 +15,7 @@ import (
 	"github.com/vmware/govmomi/object"
 	"github.com/vmware/govmomi/session"
 	"github.com/vmware/govmomi/session/keepalive"
+	"github.com/vmware/govmomi/sts"
 	"github.com/vmware/govmomi/vapi/rest"
 	"github.com/vmware/govmomi/vim25"
 	"github.com/vmware/govmomi/vim25/methods"
@@ -24,6 +26,7 @@ import (
 	"github.com/vmware-tanzu/vm-operator/pkg/vmprovider/providers/vsphere/clustermodules"
 	"github.com/vmware-tanzu/vm-operator/pkg/vmprovider/providers/vsphere/config"
 	"github.com/vmware-tanzu/vm-operator/pkg/vmprovider/providers/vsphere/contentlibrary"
+	"github.com/vmware-tanzu/vm-operator/pkg/vmprovider/providers/vsphere/credentials"
 )
 
 var log = logf.Log.WithName("vsphere").WithName("client")
@@ -37,20 +40,39 @@ type Client struct {
 	clusterModClient clustermodules.Provider
 	sessionManager   *session.Manager
 	config           *config.VSphereVMProviderConfig
+
+	credsSource  credentials.Source
+	credsLock    sync.Mutex
+	credsRenewAt time.Time
+	credsChanged bool
+	stopNotify   func()
+
+	// inFlight is the number of calls of the client that are in-flight.
+	inFlight int32
+	// lastCall is the time, in Unix nanoseconds, the last call of the client ended.
+	lastCall int64
 }
 
 // Idle time before a keepalive will be invoked.
 const keepAliveIdleTime = 5 * time.Minute
 
+type getCredentialsFn func(ctx context.Context) (*credentials.VSphereVMProviderCredentials, error)
+
 // SoapKeepAliveHandlerFn returns a keepalive handler function suitable for use with the SOAP handler.
 // In case the connectivity to VC is down long enough, the session expires. Further attempts to use the
 // client yield NotAuthenticated fault. This handler ensures that we re-login the client in those scenarios.
 func SoapKeepAliveHandlerFn(sc *soap.Client, sm *session.Manager, userInfo *url.Userinfo) func() error {
+	return soapKeepAliveHandlerFn(sc, func(ctx context.Context) error {
+		return sm.Login(ctx, userInfo)
+	})
+}
+
+func soapKeepAliveHandlerFn(sc *soap.Client, login func(context.Context) error) func() error {
 	return func() error {
 		ctx := context.Background()
 		if _, err := methods.GetCurrentTime(ctx, sc); err != nil && isNotAuthenticatedError(err) {
 			log.Info("Re-authenticating vim client")
-			if err = sm.Login(ctx, userInfo); err != nil {
+			if err = login(ctx); err != nil {
 				if isInvalidLogin(err) {
 					log.Error(err, "Invalid login in keepalive handler", "url", sc.URL())
 					return err
@@ -68,12 +90,18 @@ func SoapKeepAliveHandlerFn(sc *soap.Client, sm *session.Manager, userInfo *url.
 // Similar to the SOAP handler, we customize the handler here so we can re-login the client in case the
 // REST session expires due to connectivity issues.
 func RestKeepAliveHandlerFn(c *rest.Client, userInfo *url.Userinfo) func() error {
+	return restKeepAliveHandlerFn(c, func(ctx context.Context) error {
+		return c.Login(ctx, userInfo)
+	})
+}
+
+func restKeepAliveHandlerFn(c *rest.Client, login func(context.Context) error) func() error {
 	return func() error {
 		ctx := context.Background()
 		if sess, err := c.Session(ctx); err == nil && sess == nil {
 			// session is Unauthorized.
 			log.Info("Re-authenticating REST client")
-			if err = c.Login(ctx, userInfo); err != nil {
+			if err = login(ctx); err != nil {
 				log.Error(err, "Invalid login in keepalive handler", "url", c.URL())
 				return err
 			}
@@ -85,18 +113,71 @@ func RestKeepAliveHandlerFn(c *rest.Client, userInfo *url.Userinfo) func() error
 	}
 }
 
+// vimLogin logs the vim25 session in with the current credentials.
+func vimLogin(
+	ctx context.Context,
+	vimClient *vim25.Client,
+	sm *session.Manager,
+	getCredentials getCredentialsFn) error {
+
+	creds, err := getCredentials(ctx)
+	if err != nil {
+		return err
+	}
+
+	if creds.Token != "" {
+		header := soap.Header{Security: &sts.Signer{Token: creds.Token}}
+		return sm.LoginByToken(vimClient.WithHeader(ctx, header))
+	}
+
+	return sm.Login(ctx, url.UserPassword(creds.Username, creds.Password))
+}
+
+// restLogin logs the REST session in with the current credentials.
+func restLogin(
+	ctx context.Context,
+	restClient *rest.Client,
+	getCredentials getCredentialsFn) error {
+
+	creds, err := getCredentials(ctx)
+	if err != nil {
+		return err
+	}
+
+	if creds.Token != "" {
+		return restClient.LoginByToken(restClient.WithSigner(ctx, &sts.Signer{Token: creds.Token}))
+	}
+
+	return restClient.Login(ctx, url.UserPassword(creds.Username, creds.Password))
+}
+
+// credentialsSource returns the source of the credentials of the config.
+func credentialsSource(config *config.VSphereVMProviderConfig) credentials.Source {
+	if config.VcCredsSource != nil {
+		return config.VcCredsSource
+	}
+	return credentials.NewStaticSource(config.VcCreds)
+}
+
 // newRestClient creates a rest client which is configured to use a custom keepalive handler function.
-func newRestClient(ctx context.Context, vimClient *vim25.Client, config *config.VSphereVMProviderConfig) (*rest.Client, error) {
+func newRestClient(
+	ctx context.Context,
+	vimClient *vim25.Client,
+	config *config.VSphereVMProviderConfig,
+	getCredentials getCredentialsFn) (*rest.Client, error) {
+
 	log.Info("Creating new REST Client", "VcPNID", config.VcPNID, "VcPort", config.VcPort)
 	restClient := rest.NewClient(vimClient)
 
-	userInfo := url.UserPassword(config.VcCreds.Username, config.VcCreds.Password)
+	login := func(ctx context.Context) error {
+		return restLogin(ctx, restClient, getCredentials)
+	}
 
 	// Set a custom keepalive handler function
-	restClient.Transport = keepalive.NewHandlerREST(restClient, keepAliveIdleTime, RestKeepAliveHandlerFn(restClient, userInfo))
+	restClient.Transport = keepalive.NewHandlerREST(restClient, keepAliveIdleTime, restKeepAliveHandlerFn(restClient, login))
 
 	// Initial login. This will also start the keepalive.
-	if err := restClient.Login(ctx, userInfo); err != nil {
+	if err := login(ctx); err != nil {
 		// Log message used by VMC LINT. Refer to before making changes
 		return nil, errors.Wrapf(err, "login failed for url: %v", vimClient.URL())
 	}
@@ -107,6 +188,14 @@ func newRestClient(ctx context.Context, vimClient *vim25.Client, config *config.
 // NewVimClient creates a new vim25 client which is configured to use a custom keepalive handler function.
 // Making this public to allow access from other packages when only VimClient is needed.
 func NewVimClient(ctx context.Context, config *config.VSphereVMProviderConfig) (*vim25.Client, *session.Manager, error) {
+	return newVimClient(ctx, config, credentialsSource(config).GetCredentials)
+}
+
+func newVimClient(
+	ctx context.Context,
+	config *config.VSphereVMProviderConfig,
+	getCredentials getCredentialsFn) (*vim25.Client, *session.Manager, error) {
+
 	log.Info("Creating new vim Client", "VcPNID", config.VcPNID, "VcPort", config.VcPort)
 	soapURL, err := soap.ParseURL(net.JoinHostPort(config.VcPNID, config.VcPort))
 	if err != nil {
@@ -130,14 +219,16 @@ func NewVimClient(ctx context.Context, config *config.VSphereVMProviderConfig) (
 		return nil, nil, errors.Wrapf(err, "error setting vim client version for url: %v", soapURL)
 	}
 
-	userInfo := url.UserPassword(config.VcCreds.Username, config.VcCreds.Password)
 	sm := session.NewManager(vimClient)
+	login := func(ctx context.Context) error {
+		return vimLogin(ctx, vimClient, sm, getCredentials)
+	}
 
 	// Set a custom keepalive handler function
-	vimClient.RoundTripper = keepalive.NewHandlerSOAP(soapClient, keepAliveIdleTime, SoapKeepAliveHandlerFn(soapClient, sm, userInfo))
+	vimClient.RoundTripper = keepalive.NewHandlerSOAP(soapClient, keepAliveIdleTime, soapKeepAliveHandlerFn(soapClient, login))
 
 	// Initial login. This will also start the keepalive.
-	if err = sm.Login(ctx, userInfo); err != nil {
+	if err = login(ctx); err != nil {
 		// Log message used by VMC LINT. Refer to before making changes
 		return nil, nil, errors.Wrapf(err, "login failed for url: %v", soapURL)
 	}
@@ -165,7 +256,12 @@ func newFinder(
 
 // NewClient creates a new Client. As a side effect, it creates a vim25 client and a REST client.
 func NewClient(ctx context.Context, config *config.VSphereVMProviderConfig) (*Client, error) {
-	vimClient, sm, err := NewVimClient(ctx, config)
+	c := &Client{
+		config:      config,
+		credsSource: credentialsSource(config),
+	}
+
+	vimClient, sm, err := newVimClient(ctx, config, c.getCredentials)
 	if err != nil {
 		return nil, err
 	}
@@ -175,21 +271,65 @@ func NewClient(ctx context.Context, config *config.VSphereVMProviderConfig) (*Cl
 		return nil, err
 	}
 
-	restClient, err := newRestClient(ctx, vimClient, config)
+	restClient, err := newRestClient(ctx, vimClient, config, c.getCredentials)
+	if err != nil {
+		return nil, err
+	}
+
+	c.vimClient = vimClient
+	c.finder = finder
+	c.datacenter = datacenter
+	c.restClient = restClient
+	c.contentLibClient = contentlibrary.NewProvider(restClient)
+	c.clusterModClient = clustermodules.NewProvider(restClient)
+	c.sessionManager = sm
+
+	if notifier, ok := c.credsSource.(credentials.Notifier); ok {
+		stop, err := notifier.Notify(c.CredentialsChanged)
+		if err != nil {
+			log.Error(err, "Failed to watch the provider credentials for changes", "VcPNID", config.VcPNID)
+		} else {
+			c.stopNotify = stop
+		}
+	}
+
+	return c, nil
+}
+
+// getCredentials returns the current credentials from the client's source, and records when they should be
+// renewed.
+func (c *Client) getCredentials(ctx context.Context) (*credentials.VSphereVMProviderCredentials, error) {
+	creds, err := c.credsSource.GetCredentials(ctx)
 	if err != nil {
 		return nil, err
 	}
 
-	return &Client{
-		vimClient:        vimClient,
-		finder:           finder,
-		datacenter:       datacenter,
-		restClient:       restClient,
-		contentLibClient: contentlibrary.NewProvider(restClient),
-		clusterModClient: clustermodules.NewProvider(restClient),
-		sessionManager:   sm,
-		config:           config,
-	}, nil
+	c.credsLock.Lock()
+	c.credsRenewAt = credentials.RenewAt(time.Now(), creds.Expiry)
+	c.credsLock.Unlock()
+
+	return creds, nil
+}
+
+// CredentialsChanged records that the credentials of the client have changed.
+func (c *Client) CredentialsChanged() {
+	c.credsLock.Lock()
+	c.credsChanged = true
+	c.credsLock.Unlock()
+}
+
+// NeedsReauthentication returns true when the credentials of the client have changed, or are about to
+// expire, so the client should be replaced by a new client that is logged in with the current credentials.
+// The client keeps working until then, so requests that are in-flight are not failed.
+func (c *Client) NeedsReauthentication() bool {
+	c.credsLock.Lock()
+	defer c.credsLock.Unlock()
+
+	if c.credsChanged {
+		return true
+	}
+
+	return !c.credsRenewAt.IsZero() && !time.Now().Before(c.credsRenewAt)
 }
 
 func isNotAuthenticatedError(err error) bool {
@@ -243,6 +383,10 @@ func (c *Client) Config() *config.VSphereVMProviderConfig {
 }
 
 func (c *Client) Logout(ctx context.Context) {
+	if c.stopNotify != nil {
+		c.stopNotify()
+	}
+
 	clientURL := c.vimClient.URL()
 	log.Info("vsphere client logging out from", "VC", clientURL.Host)
 	if err := c.sessionManager.Logout(ctx); err != nil {
@@ -1,6 +1,6 @@
 //go:build !race
 
-// Copyright (c) 2019-2021 VMware, Inc. All Rights Reserved.
+// Copyright (c) 2019-2023 VMware, Inc. All Rights Reserved.
 // SPDX-License-Identifier: Apache-2.0
 
 package client_test
@@ -8,6 +8,8 @@ package client_test
 import (
 	"context"
 	"net/url"
+	"os"
+	"path/filepath"
 	"time"
 
 	. "github.com/onsi/ginkgo"
@@ -16,10 +18,14 @@ import (
 	"github.com/vmware/govmomi/session"
 	"github.com/vmware/govmomi/session/keepalive"
 	"github.com/vmware/govmomi/simulator"
+	"github.com/vmware/govmomi/sts"
 	"github.com/vmware/govmomi/vapi/rest"
 	"github.com/vmware/govmomi/vim25"
 	"github.com/vmware/govmomi/vim25/soap"
 
+	// Registers the STS endpoint of the simulator.
+	_ "github.com/vmware/govmomi/sts/simulator"
+
 	. "github.com/vmware-tanzu/vm-operator/pkg/vmprovider/providers/vsphere/client"
 	"github.com/vmware-tanzu/vm-operator/pkg/vmprovider/providers/vsphere/config"
 	"github.com/vmware-tanzu/vm-operator/pkg/vmprovider/providers/vsphere/credentials"
@@ -305,6 +311,80 @@ var _ = Describe("NewClient", func() {
 		})
 	})
 
+	Context("When the credentials are a token", func() {
+		Specify("returns a client that is logged in with the token", func() {
+			config := testConfig(server.URL.Hostname(), server.URL.Port(), "some-username", "some-password")
+			stsURL := &url.URL{Scheme: server.URL.Scheme, Host: server.URL.Host, Path: sts.Path + "/vsphere.local"}
+			config.VcCredsSource = credentials.NewTokenExchangeSource(
+				credentials.NewStaticSource(config.VcCreds), stsURL, true, "")
+
+			client, err := NewClient(ctx, config)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(client).ToNot(BeNil())
+			defer client.Logout(ctx)
+
+			Expect(client.NeedsReauthentication()).To(BeFalse())
+
+			restSession, err := client.RestClient().Session(ctx)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(restSession).ToNot(BeNil())
+		})
+	})
+
+	Context("When the credentials are about to expire", func() {
+		Specify("the client needs to re-authenticate", func() {
+			config := testConfig(server.URL.Hostname(), server.URL.Port(), "some-username", "some-password")
+			config.VcCreds.Expiry = time.Now().Add(time.Second)
+
+			client, err := NewClient(ctx, config)
+			Expect(err).ToNot(HaveOccurred())
+			defer client.Logout(ctx)
+
+			Eventually(client.NeedsReauthentication, "2s").Should(BeTrue())
+		})
+	})
+
+	Context("When the credentials have changed", func() {
+		Specify("the client needs to re-authenticate", func() {
+			client, err := NewClient(ctx, testConfig(server.URL.Hostname(), server.URL.Port(), "some-username", "some-password"))
+			Expect(err).ToNot(HaveOccurred())
+			defer client.Logout(ctx)
+
+			Expect(client.NeedsReauthentication()).To(BeFalse())
+			client.CredentialsChanged()
+			Expect(client.NeedsReauthentication()).To(BeTrue())
+		})
+	})
+
+	Context("When the credentials are read from files", func() {
+		var dir string
+
+		BeforeEach(func() {
+			var err error
+			dir, err = os.MkdirTemp("", "creds-")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(os.WriteFile(filepath.Join(dir, "username"), []byte("some-username"), 0600)).To(Succeed())
+			Expect(os.WriteFile(filepath.Join(dir, "password"), []byte("some-password"), 0600)).To(Succeed())
+		})
+
+		AfterEach(func() {
+			Expect(os.RemoveAll(dir)).To(Succeed())
+		})
+
+		Specify("the client needs to re-authenticate when the files are rotated", func() {
+			config := testConfig(server.URL.Hostname(), server.URL.Port(), "", "")
+			config.VcCredsSource = credentials.NewFileSource(dir)
+
+			client, err := NewClient(ctx, config)
+			Expect(err).ToNot(HaveOccurred())
+			defer client.Logout(ctx)
+
+			Expect(client.NeedsReauthentication()).To(BeFalse())
+			Expect(os.WriteFile(filepath.Join(dir, "password"), []byte("new-password"), 0600)).To(Succeed())
+			Eventually(client.NeedsReauthentication).Should(BeTrue())
+		})
+	})
+
 	DescribeTable("Should fail if given wrong username and/or wrong password",
 		func(expectedUsername, expectedPassword, username, password string) {
 			server.URL.User = url.UserPassword(expectedUsername, expectedPassword)
@@ -0,0 +1,285 @@
+// Copyright (c) 2023 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package client
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/vmware-tanzu/vm-operator/pkg/metrics"
+	"github.com/vmware-tanzu/vm-operator/pkg/vmprovider/providers/vsphere/config"
+)
+
+const (
+	// DefaultMaxSessions is the default maximum number of sessions of a Pool.
+	DefaultMaxSessions = 4
+
+	// DefaultCallTimeout is the default timeout of the calls to vCenter.
+	DefaultCallTimeout = 2 * time.Minute
+
+	// DefaultFailureThreshold is the default number of consecutive failed calls after which vCenter is
+	// considered unreachable.
+	DefaultFailureThreshold = 3
+
+	// DefaultOpenDuration is the default time calls fail fast once vCenter is unreachable, before a call is
+	// let through to probe vCenter again.
+	DefaultOpenDuration = 30 * time.Second
+
+	// DefaultRetiredClientIdleTime is the default time a client that was replaced by a re-authenticated
+	// client must have no in-flight calls before it is logged out.
+	DefaultRetiredClientIdleTime = time.Minute
+)
+
+// PoolOptions are the options of a Pool. The zero values are replaced by the defaults.
+type PoolOptions struct {
+	// MaxSessions is the maximum number of Clients, each with its own session, of the Pool.
+	MaxSessions int
+
+	// CallTimeout is the timeout of each SOAP call to vCenter, other than the long polls for updates.
+	CallTimeout time.Duration
+
+	// FailureThreshold is the number of consecutive calls that failed to reach vCenter after which
+	// vCenter is considered unreachable.
+	FailureThreshold int
+
+	// OpenDuration is the time calls fail fast once vCenter is unreachable.
+	OpenDuration time.Duration
+
+	// RetiredClientIdleTime is the time a Client that was replaced by a re-authenticated Client must have
+	// no in-flight calls before it is logged out, so that the operations that are still using it, such as
+	// one that waits for a task, are not cut off.
+	RetiredClientIdleTime time.Duration
+}
+
+func (o *PoolOptions) defaults() {
+	if o.MaxSessions <= 0 {
+		o.MaxSessions = DefaultMaxSessions
+	}
+	if o.CallTimeout <= 0 {
+		o.CallTimeout = DefaultCallTimeout
+	}
+	if o.FailureThreshold <= 0 {
+		o.FailureThreshold = DefaultFailureThreshold
+	}
+	if o.OpenDuration <= 0 {
+		o.OpenDuration = DefaultOpenDuration
+	}
+	if o.RetiredClientIdleTime <= 0 {
+		o.RetiredClientIdleTime = DefaultRetiredClientIdleTime
+	}
+}
+
+// Pool is a pool of the Clients of a vCenter. Get returns the Client with the fewest in-flight calls, and
+// creates new Clients while they are all busy, up to MaxSessions. The calls of all the Clients share the
+// CircuitBreaker of the vCenter.
+type Pool struct {
+	getConfig func(context.Context) (*config.VSphereVMProviderConfig, error)
+	opts      PoolOptions
+	breaker   *CircuitBreaker
+
+	mu       sync.Mutex
+	cond     *sync.Cond
+	clients  []*Client
+	creating int
+}
+
+// NewPool returns an empty Pool of the Clients of the vCenter name, that are created with the config
+// returned by getConfig. onChange is called when the vCenter becomes reachable or unreachable.
+func NewPool(
+	name string,
+	getConfig func(context.Context) (*config.VSphereVMProviderConfig, error),
+	opts PoolOptions,
+	onChange func(err error)) *Pool {
+
+	opts.defaults()
+
+	p := &Pool{
+		getConfig: getConfig,
+		opts:      opts,
+		breaker:   NewCircuitBreaker(name, opts.FailureThreshold, opts.OpenDuration, onChange),
+	}
+	p.cond = sync.NewCond(&p.mu)
+
+	return p
+}
+
+// Get returns a Client of the Pool. A Client that needs to re-authenticate is only returned when a new
+// Client could not be created, and is logged out once it has been replaced and its calls have ended.
+func (p *Pool) Get(ctx context.Context) (*Client, error) {
+	p.mu.Lock()
+
+	var best, bestStale *Client
+	for {
+		best, bestStale = nil, nil
+		fresh := 0
+		for _, c := range p.clients {
+			if c.NeedsReauthentication() {
+				if bestStale == nil || c.inFlightCalls() < bestStale.inFlightCalls() {
+					bestStale = c
+				}
+				continue
+			}
+			fresh++
+			if best == nil || c.inFlightCalls() < best.inFlightCalls() {
+				best = c
+			}
+		}
+
+		canCreate := fresh+p.creating < p.opts.MaxSessions
+		if best != nil && (best.inFlightCalls() == 0 || !canCreate) {
+			p.mu.Unlock()
+			return best, nil
+		}
+		if canCreate {
+			break
+		}
+		if bestStale != nil {
+			p.mu.Unlock()
+			return bestStale, nil
+		}
+
+		// Wait for the Clients that are being created.
+		p.cond.Wait()
+	}
+
+	p.creating++
+	p.mu.Unlock()
+
+	c, err := p.newClient(ctx)
+
+	p.mu.Lock()
+	p.creating--
+	var retired []*Client
+	if err == nil {
+		clients := []*Client{c}
+		for _, pc := range p.clients {
+			if pc.NeedsReauthentication() {
+				retired = append(retired, pc)
+			} else {
+				clients = append(clients, pc)
+			}
+		}
+		p.clients = clients
+	}
+	p.cond.Broadcast()
+	p.mu.Unlock()
+
+	for _, rc := range retired {
+		log.Info("Re-authenticated the vCenter client", "vCenter", p.breaker.Name())
+		p.retireClient(rc)
+	}
+
+	if err != nil {
+		if best != nil {
+			log.Error(err, "Failed to create a vCenter client, reusing a busy client", "vCenter", p.breaker.Name())
+			return best, nil
+		}
+		if bestStale != nil {
+			// The existing client remains usable until its credentials are no longer accepted.
+			log.Error(err, "Failed to re-authenticate the vCenter client", "vCenter", p.breaker.Name())
+			return bestStale, nil
+		}
+		return nil, err
+	}
+
+	return c, nil
+}
+
+// newClient creates a Client whose calls are subject to the per-call timeout and the CircuitBreaker.
+func (p *Pool) newClient(ctx context.Context) (*Client, error) {
+	config, err := p.getConfig(ctx)
+	if err != nil {
+		return nil, err
+	}
+	p.breaker.setName(config.VcPNID)
+
+	if err := p.breaker.Allow(); err != nil {
+		return nil, err
+	}
+
+	createCtx, cancel := context.WithTimeout(ctx, p.opts.CallTimeout)
+	defer cancel()
+
+	c, err := NewClient(createCtx, config)
+	p.breaker.Done(ctx, err)
+	if err != nil {
+		return nil, err
+	}
+
+	atomic.StoreInt64(&c.lastCall, time.Now().UnixNano())
+	c.vimClient.RoundTripper = &soapRoundTripper{
+		next:     c.vimClient.RoundTripper,
+		breaker:  p.breaker,
+		timeout:  p.opts.CallTimeout,
+		inFlight: &c.inFlight,
+		lastCall: &c.lastCall,
+		metrics:  metrics.NewVCenterMetrics(),
+	}
+	c.restClient.Transport = &restRoundTripper{
+		next:     c.restClient.Transport,
+		breaker:  p.breaker,
+		inFlight: &c.inFlight,
+		lastCall: &c.lastCall,
+		metrics:  metrics.NewVCenterMetrics(),
+	}
+
+	return c, nil
+}
+
+// CredentialsChanged records that the credentials of the Clients of the Pool have changed, so they are
+// replaced by Clients that are logged in with the new credentials.
+func (p *Pool) CredentialsChanged() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	for _, c := range p.clients {
+		c.CredentialsChanged()
+	}
+}
+
+// IsReachable returns false when the calls to the vCenter fail fast because it is unreachable.
+func (p *Pool) IsReachable() bool {
+	return !p.breaker.IsOpen()
+}
+
+// Logout logs out and removes the Clients of the Pool.
+func (p *Pool) Logout(ctx context.Context) {
+	p.mu.Lock()
+	clients := p.clients
+	p.clients = nil
+	p.mu.Unlock()
+
+	for _, c := range clients {
+		c.Logout(ctx)
+	}
+}
+
+// retireClient logs out the client once it has had no in-flight calls for RetiredClientIdleTime, so that
+// the calls that are in-flight on it, however long they take, can complete.
+func (p *Pool) retireClient(c *Client) {
+	go func() {
+		for {
+			idle := c.idleTime()
+			if idle >= p.opts.RetiredClientIdleTime {
+				break
+			}
+			time.Sleep(p.opts.RetiredClientIdleTime - idle)
+		}
+		c.Logout(context.Background())
+	}()
+}
+
+func (c *Client) inFlightCalls() int32 {
+	return atomic.LoadInt32(&c.inFlight)
+}
+
+// idleTime returns how long the client has had no in-flight calls.
+func (c *Client) idleTime() time.Duration {
+	if c.inFlightCalls() > 0 {
+		return 0
+	}
+	return time.Since(time.Unix(0, atomic.LoadInt64(&c.lastCall)))
+}
@@ -0,0 +1,278 @@
+//go:build !race
+
+// Copyright (c) 2023 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package client_test
+
+import (
+	"context"
+	"net"
+	"strconv"
+	"sync"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/pkg/errors"
+	"github.com/vmware/govmomi/vapi/rest"
+	"github.com/vmware/govmomi/vim25/methods"
+	ctrlmetrics "sigs.k8s.io/controller-runtime/pkg/metrics"
+
+	. "github.com/vmware-tanzu/vm-operator/pkg/vmprovider/providers/vsphere/client"
+	"github.com/vmware-tanzu/vm-operator/pkg/vmprovider/providers/vsphere/config"
+)
+
+var _ = Describe("Pool", func() {
+
+	var (
+		opts    PoolOptions
+		cfg     *config.VSphereVMProviderConfig
+		cfgLock sync.Mutex
+		pool    *Pool
+
+		changesLock sync.Mutex
+		changes     []error
+	)
+
+	setConfig := func(c *config.VSphereVMProviderConfig) {
+		cfgLock.Lock()
+		cfg = c
+		cfgLock.Unlock()
+	}
+
+	getChanges := func() []error {
+		changesLock.Lock()
+		defer changesLock.Unlock()
+		return append([]error(nil), changes...)
+	}
+
+	BeforeEach(func() {
+		opts = PoolOptions{}
+		changes = nil
+
+		password, _ := server.URL.User.Password()
+		setConfig(testConfig(server.URL.Hostname(), server.URL.Port(), server.URL.User.Username(), password))
+	})
+
+	JustBeforeEach(func() {
+		getConfig := func(_ context.Context) (*config.VSphereVMProviderConfig, error) {
+			cfgLock.Lock()
+			defer cfgLock.Unlock()
+			c := *cfg
+			return &c, nil
+		}
+
+		pool = NewPool("vc", getConfig, opts, func(err error) {
+			changesLock.Lock()
+			changes = append(changes, err)
+			changesLock.Unlock()
+		})
+	})
+
+	AfterEach(func() {
+		pool.Logout(ctx)
+	})
+
+	It("reuses an idle client", func() {
+		c1, err := pool.Get(ctx)
+		Expect(err).ToNot(HaveOccurred())
+		c2, err := pool.Get(ctx)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(c2).To(BeIdenticalTo(c1))
+
+		Expect(getChanges()).To(Equal([]error{nil}))
+		Expect(pool.IsReachable()).To(BeTrue())
+	})
+
+	Context("with busy clients", func() {
+
+		BeforeEach(func() {
+			opts.MaxSessions = 2
+		})
+
+		AfterEach(func() {
+			model.DelayConfig.MethodDelay = nil
+		})
+
+		It("creates clients up to MaxSessions", func() {
+			model.DelayConfig.MethodDelay = map[string]int{"CurrentTime": 2000}
+
+			var wg sync.WaitGroup
+			defer wg.Wait()
+
+			busy := func(c *Client) {
+				wg.Add(1)
+				go func() {
+					defer GinkgoRecover()
+					defer wg.Done()
+					_, _ = methods.GetCurrentTime(ctx, c.VimClient())
+				}()
+				// Let the call start.
+				time.Sleep(200 * time.Millisecond)
+			}
+
+			c1, err := pool.Get(ctx)
+			Expect(err).ToNot(HaveOccurred())
+			busy(c1)
+
+			c2, err := pool.Get(ctx)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(c2).ToNot(BeIdenticalTo(c1))
+			busy(c2)
+
+			c3, err := pool.Get(ctx)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(c3).To(Or(BeIdenticalTo(c1), BeIdenticalTo(c2)))
+		})
+	})
+
+	It("replaces a client whose credentials changed", func() {
+		c1, err := pool.Get(ctx)
+		Expect(err).ToNot(HaveOccurred())
+
+		pool.CredentialsChanged()
+
+		c2, err := pool.Get(ctx)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(c2).ToNot(BeIdenticalTo(c1))
+		Expect(c2.NeedsReauthentication()).To(BeFalse())
+	})
+
+	Context("when a replaced client has an in-flight call", func() {
+
+		BeforeEach(func() {
+			opts.RetiredClientIdleTime = 200 * time.Millisecond
+		})
+
+		AfterEach(func() {
+			model.DelayConfig.MethodDelay = nil
+		})
+
+		It("logs out the replaced client once the call has ended", func() {
+			c1, err := pool.Get(ctx)
+			Expect(err).ToNot(HaveOccurred())
+
+			model.DelayConfig.MethodDelay = map[string]int{"CurrentTime": 1000}
+			callErr := make(chan error, 1)
+			go func() {
+				_, err := methods.GetCurrentTime(ctx, c1.VimClient())
+				callErr <- err
+			}()
+			// Let the call start.
+			time.Sleep(200 * time.Millisecond)
+
+			pool.CredentialsChanged()
+			c2, err := pool.Get(ctx)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(c2).ToNot(BeIdenticalTo(c1))
+
+			Eventually(callErr, 5*time.Second).Should(Receive(BeNil()))
+			model.DelayConfig.MethodDelay = nil
+
+			// Poll less often than the idle time since polling is a call of the replaced client.
+			Eventually(func() (*rest.Session, error) {
+				return c1.RestClient().Session(ctx)
+			}, 5*time.Second, time.Second).Should(BeNil())
+		})
+	})
+
+	It("records the latency of the SOAP calls", func() {
+		callCount := func() uint64 {
+			families, err := ctrlmetrics.Registry.Gather()
+			Expect(err).ToNot(HaveOccurred())
+			for _, family := range families {
+				if family.GetName() != "vmservice_vcenter_call_duration_seconds" {
+					continue
+				}
+				for _, m := range family.GetMetric() {
+					labels := map[string]string{}
+					for _, l := range m.GetLabel() {
+						labels[l.GetName()] = l.GetValue()
+					}
+					if labels["vcenter"] == server.URL.Hostname() && labels["api"] == "soap" &&
+						labels["operation"] == "CurrentTime" {
+						return m.GetHistogram().GetSampleCount()
+					}
+				}
+			}
+			return 0
+		}
+
+		c, err := pool.Get(ctx)
+		Expect(err).ToNot(HaveOccurred())
+
+		count := callCount()
+		_, err = methods.GetCurrentTime(ctx, c.VimClient())
+		Expect(err).ToNot(HaveOccurred())
+		Expect(callCount()).To(Equal(count + 1))
+	})
+
+	Context("with a per-call timeout", func() {
+
+		BeforeEach(func() {
+			opts.CallTimeout = 500 * time.Millisecond
+		})
+
+		AfterEach(func() {
+			model.DelayConfig.MethodDelay = nil
+		})
+
+		It("fails the calls that vCenter does not respond to in time", func() {
+			c, err := pool.Get(ctx)
+			Expect(err).ToNot(HaveOccurred())
+
+			model.DelayConfig.MethodDelay = map[string]int{"CurrentTime": 2000}
+
+			_, err = methods.GetCurrentTime(ctx, c.VimClient())
+			Expect(err).To(HaveOccurred())
+			Expect(errors.Is(err, context.DeadlineExceeded)).To(BeTrue())
+			Expect(err.Error()).To(ContainSubstring("did not respond within 500ms"))
+		})
+	})
+
+	Context("when vCenter is unreachable", func() {
+
+		BeforeEach(func() {
+			opts.FailureThreshold = 2
+			opts.OpenDuration = time.Second
+
+			l, err := net.Listen("tcp", "127.0.0.1:0")
+			Expect(err).ToNot(HaveOccurred())
+			port := l.Addr().(*net.TCPAddr).Port
+			Expect(l.Close()).To(Succeed())
+
+			c := *cfg
+			c.VcPNID, c.VcPort = "127.0.0.1", strconv.Itoa(port)
+			setConfig(&c)
+		})
+
+		It("fails fast until vCenter is reachable again", func() {
+			for i := 0; i < opts.FailureThreshold; i++ {
+				_, err := pool.Get(ctx)
+				Expect(err).To(HaveOccurred())
+				Expect(errors.Is(err, ErrCircuitOpen)).To(BeFalse())
+			}
+
+			Expect(pool.IsReachable()).To(BeFalse())
+			Expect(getChanges()).To(HaveLen(1))
+			Expect(getChanges()[0]).To(MatchError(ContainSubstring("connection refused")))
+
+			_, err := pool.Get(ctx)
+			Expect(errors.Is(err, ErrCircuitOpen)).To(BeTrue())
+			Expect(err.Error()).To(ContainSubstring("vCenter 127.0.0.1 is unreachable"))
+
+			password, _ := server.URL.User.Password()
+			setConfig(testConfig(server.URL.Hostname(), server.URL.Port(), server.URL.User.Username(), password))
+
+			Eventually(func() error {
+				_, err := pool.Get(ctx)
+				return err
+			}, 5*time.Second, 100*time.Millisecond).Should(Succeed())
+
+			Expect(pool.IsReachable()).To(BeTrue())
+			Expect(getChanges()).To(HaveLen(2))
+			Expect(getChanges()[1]).ToNot(HaveOccurred())
+		})
+	})
+})
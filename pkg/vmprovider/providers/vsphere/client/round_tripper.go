@@ -0,0 +1,163 @@
+// Copyright (c) 2023 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package client
+
+import (
+	"context"
+	"net/http"
+	"reflect"
+	"strings"
+	"sync/atomic"
+	"time"
+	"unicode"
+
+	"github.com/pkg/errors"
+	"github.com/vmware/govmomi/vim25/methods"
+	"github.com/vmware/govmomi/vim25/soap"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.12.0"
+
+	"github.com/vmware-tanzu/vm-operator/pkg/metrics"
+	"github.com/vmware-tanzu/vm-operator/pkg/tracing"
+)
+
+// soapRoundTripper counts the in-flight SOAP calls of a Client, applies the per-call timeout, reports the
+// outcome of the calls to the circuit breaker of the vCenter, and records the calls in metrics and spans.
+type soapRoundTripper struct {
+	next     soap.RoundTripper
+	breaker  *CircuitBreaker
+	timeout  time.Duration
+	inFlight *int32
+	lastCall *int64
+	metrics  *metrics.VCenterMetrics
+}
+
+func (rt *soapRoundTripper) RoundTrip(ctx context.Context, req, res soap.HasFault) error {
+	if err := rt.breaker.Allow(); err != nil {
+		return err
+	}
+
+	atomic.AddInt32(rt.inFlight, 1)
+	defer endCall(rt.inFlight, rt.lastCall)
+
+	longPoll := isLongPoll(req)
+
+	callCtx := ctx
+	if rt.timeout > 0 && !longPoll {
+		var cancel context.CancelFunc
+		callCtx, cancel = context.WithTimeout(ctx, rt.timeout)
+		defer cancel()
+	}
+
+	callCtx, span := tracing.StartClientSpan(callCtx, "vcenter.soap/"+soapOperation(req),
+		tracing.VCenterKey.String(rt.breaker.Name()))
+
+	start := time.Now()
+	err := rt.next.RoundTrip(callCtx, req, res)
+	if err != nil && callCtx.Err() != nil && ctx.Err() == nil {
+		err = errors.Wrapf(context.DeadlineExceeded, "vCenter %s did not respond within %s", rt.breaker.Name(), rt.timeout)
+	}
+	tracing.EndSpan(span, err)
+	rt.breaker.Done(ctx, err)
+
+	// The latency of the long polls depends on when there are updates, not on vCenter.
+	if longPoll {
+		if err != nil && ctx.Err() == nil {
+			rt.metrics.CountCallError(rt.breaker.Name(), metrics.VCenterAPISOAP, soapOperation(req))
+		}
+	} else {
+		rt.metrics.ObserveCall(rt.breaker.Name(), metrics.VCenterAPISOAP, soapOperation(req), time.Since(start), err != nil)
+	}
+
+	return err
+}
+
+// soapOperation returns the name of the method of the SOAP call, such as RetrievePropertiesEx.
+func soapOperation(req soap.HasFault) string {
+	return strings.TrimSuffix(reflect.Indirect(reflect.ValueOf(req)).Type().Name(), "Body")
+}
+
+// isLongPoll returns true for the calls that vCenter only responds to once there are updates, so they must
+// not be subject to the per-call timeout.
+func isLongPoll(req soap.HasFault) bool {
+	switch req.(type) {
+	case *methods.WaitForUpdatesBody, *methods.WaitForUpdatesExBody:
+		return true
+	}
+	return false
+}
+
+// restRoundTripper counts the in-flight REST calls of a Client, reports the outcome of the calls to the
+// circuit breaker of the vCenter, and records the calls in metrics and spans. REST calls are not subject
+// to the per-call timeout since they include the content library transfers.
+type restRoundTripper struct {
+	next     http.RoundTripper
+	breaker  *CircuitBreaker
+	inFlight *int32
+	lastCall *int64
+	metrics  *metrics.VCenterMetrics
+}
+
+func (rt *restRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	if err := rt.breaker.Allow(); err != nil {
+		return nil, err
+	}
+
+	atomic.AddInt32(rt.inFlight, 1)
+	defer endCall(rt.inFlight, rt.lastCall)
+
+	ctx, span := tracing.StartClientSpan(req.Context(), "vcenter.rest/"+restOperation(req),
+		tracing.VCenterKey.String(rt.breaker.Name()),
+		semconv.HTTPMethodKey.String(req.Method),
+		semconv.HTTPURLKey.String(req.URL.Redacted()))
+	req = req.WithContext(ctx)
+
+	start := time.Now()
+	res, err := rt.next.RoundTrip(req)
+	rt.metrics.ObserveCall(rt.breaker.Name(), metrics.VCenterAPIREST, restOperation(req), time.Since(start),
+		err != nil || res.StatusCode >= http.StatusInternalServerError)
+
+	if err == nil {
+		span.SetAttributes(semconv.HTTPStatusCodeKey.Int(res.StatusCode))
+		if res.StatusCode >= http.StatusInternalServerError {
+			span.SetStatus(codes.Error, res.Status)
+		}
+	}
+	tracing.EndSpan(span, err)
+
+	if err == nil {
+		switch res.StatusCode {
+		case http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+			rt.breaker.failed(errors.Errorf("vCenter %s responded with %s", rt.breaker.Name(), res.Status))
+			return res, nil
+		}
+	}
+	rt.breaker.Done(req.Context(), err)
+
+	return res, err
+}
+
+// restOperation returns the method and path of the REST call, such as GET /api/vcenter/vm/{id}. The path
+// segments that contain digits, such as the IDs of objects, are replaced by {id} to bound the number of
+// operations, and the action of the call is kept.
+func restOperation(req *http.Request) string {
+	segments := strings.Split(req.URL.Path, "/")
+	for i, segment := range segments {
+		if strings.IndexFunc(segment, unicode.IsDigit) >= 0 {
+			segments[i] = "{id}"
+		}
+	}
+
+	operation := req.Method + " " + strings.Join(segments, "/")
+	if action := req.URL.Query().Get("~action"); action != "" {
+		operation += "?~action=" + action
+	}
+	return operation
+}
+
+// endCall records that a call of a Client ended.
+func endCall(inFlight *int32, lastCall *int64) {
+	atomic.StoreInt64(lastCall, time.Now().UnixNano())
+	atomic.AddInt32(inFlight, -1)
+}
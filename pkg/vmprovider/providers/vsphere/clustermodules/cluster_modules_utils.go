@@ -1,18 +1,72 @@
-// Copyright (c) 2021 VMware, Inc. All Rights Reserved.
+// Copyright (c) 2021-2023 VMware, Inc. All Rights Reserved.
 // SPDX-License-Identifier: Apache-2.0
 
 package clustermodules
 
 import (
 	"context"
+	"fmt"
+	"strings"
 
 	"github.com/vmware/govmomi/vim25/types"
 	k8serrors "k8s.io/apimachinery/pkg/util/errors"
 
 	vmopv1 "github.com/vmware-tanzu/vm-operator/api/v1alpha1"
+	"github.com/vmware-tanzu/vm-operator/pkg"
 	"github.com/vmware-tanzu/vm-operator/pkg/lib"
 )
 
+// GroupNames returns the names of the ClusterModule Groups the VM is a member of. The names are
+// in the VM's ClusterModuleNameKey annotation, separated by commas.
+func GroupNames(vm *vmopv1.VirtualMachine) []string {
+	var names []string
+	for _, name := range strings.Split(vm.Annotations[pkg.ClusterModuleNameKey], ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// IsMember returns true if the VM is a member of the ClusterModule Group.
+func IsMember(vm *vmopv1.VirtualMachine, groupName string) bool {
+	for _, name := range GroupNames(vm) {
+		if name == groupName {
+			return true
+		}
+	}
+	return false
+}
+
+// FindClusterModuleSpec returns the spec of the ClusterModule Group with the given groupName, or
+// nil if the resource policy does not have the group.
+func FindClusterModuleSpec(
+	groupName string,
+	resourcePolicy *vmopv1.VirtualMachineSetResourcePolicy) *vmopv1.ClusterModuleSpec {
+
+	for i := range resourcePolicy.Spec.ClusterModules {
+		if resourcePolicy.Spec.ClusterModules[i].GroupName == groupName {
+			return &resourcePolicy.Spec.ClusterModules[i]
+		}
+	}
+	return nil
+}
+
+// IsVCClusterModule returns true if the ClusterModule Group is realized by a VC cluster module,
+// which only supports preferred anti-affinity. The other groups are realized by DRS VM-VM rules.
+func IsVCClusterModule(moduleSpec vmopv1.ClusterModuleSpec) bool {
+	return (moduleSpec.Type == "" || moduleSpec.Type == vmopv1.ClusterModuleTypeAntiAffinity) &&
+		(moduleSpec.Enforcement == "" || moduleSpec.Enforcement == vmopv1.ClusterModuleEnforcementPreferred)
+}
+
+// RuleNamePrefix returns the prefix of the names of the DRS VM-VM rules that realize the
+// ClusterModule Groups of the resource policy.
+func RuleNamePrefix(resourcePolicy *vmopv1.VirtualMachineSetResourcePolicy) string {
+	// Namespace and resource policy names cannot contain a '/' or ':' so this is never a prefix
+	// of the rule names of another resource policy or of a VM's affinity rules.
+	return fmt.Sprintf("vmoperator:%s/resourcepolicy/%s:", resourcePolicy.Namespace, resourcePolicy.Name)
+}
+
 // FindClusterModuleUUID returns the index in the Status.ClusterModules and UUID of the
 // VC cluster module for the given groupName and cluster reference.
 func FindClusterModuleUUID(
@@ -1,16 +1,19 @@
-// Copyright (c) 2019-2021 VMware, Inc. All Rights Reserved.
+// Copyright (c) 2019-2023 VMware, Inc. All Rights Reserved.
 // SPDX-License-Identifier: Apache-2.0
 
 package clustermodules_test
 
 import (
 	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
 	. "github.com/onsi/gomega"
 
 	"github.com/vmware/govmomi/vim25/types"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	vmopv1 "github.com/vmware-tanzu/vm-operator/api/v1alpha1"
 
+	"github.com/vmware-tanzu/vm-operator/pkg"
 	"github.com/vmware-tanzu/vm-operator/pkg/lib"
 	"github.com/vmware-tanzu/vm-operator/pkg/vmprovider/providers/vsphere/clustermodules"
 )
@@ -159,3 +162,47 @@ var _ = Describe("FindClusterModuleUUID", func() {
 		})
 	})
 })
+
+var _ = Describe("GroupNames", func() {
+	var vm *vmopv1.VirtualMachine
+
+	BeforeEach(func() {
+		vm = &vmopv1.VirtualMachine{
+			ObjectMeta: metav1.ObjectMeta{
+				Annotations: map[string]string{},
+			},
+		}
+	})
+
+	It("Returns no groups without the annotation", func() {
+		Expect(clustermodules.GroupNames(vm)).To(BeEmpty())
+		Expect(clustermodules.IsMember(vm, "group-a")).To(BeFalse())
+	})
+
+	It("Returns a single group", func() {
+		vm.Annotations[pkg.ClusterModuleNameKey] = "group-a"
+		Expect(clustermodules.GroupNames(vm)).To(Equal([]string{"group-a"}))
+		Expect(clustermodules.IsMember(vm, "group-a")).To(BeTrue())
+	})
+
+	It("Returns the comma separated groups", func() {
+		vm.Annotations[pkg.ClusterModuleNameKey] = "group-a, group-b,,"
+		Expect(clustermodules.GroupNames(vm)).To(Equal([]string{"group-a", "group-b"}))
+		Expect(clustermodules.IsMember(vm, "group-b")).To(BeTrue())
+		Expect(clustermodules.IsMember(vm, "group-c")).To(BeFalse())
+	})
+})
+
+var _ = Describe("IsVCClusterModule", func() {
+	DescribeTable("Returns if the group is realized by a VC cluster module",
+		func(moduleType vmopv1.ClusterModuleType, enforcement vmopv1.ClusterModuleEnforcement, expected bool) {
+			moduleSpec := vmopv1.ClusterModuleSpec{GroupName: "group", Type: moduleType, Enforcement: enforcement}
+			Expect(clustermodules.IsVCClusterModule(moduleSpec)).To(Equal(expected))
+		},
+		Entry("defaults", vmopv1.ClusterModuleType(""), vmopv1.ClusterModuleEnforcement(""), true),
+		Entry("preferred anti-affinity", vmopv1.ClusterModuleTypeAntiAffinity, vmopv1.ClusterModuleEnforcementPreferred, true),
+		Entry("required anti-affinity", vmopv1.ClusterModuleTypeAntiAffinity, vmopv1.ClusterModuleEnforcementRequired, false),
+		Entry("preferred affinity", vmopv1.ClusterModuleTypeAffinity, vmopv1.ClusterModuleEnforcementPreferred, false),
+		Entry("required affinity", vmopv1.ClusterModuleTypeAffinity, vmopv1.ClusterModuleEnforcementRequired, false),
+	)
+})
@@ -1,10 +1,14 @@
-// Copyright (c) 2018-2020 VMware, Inc. All Rights Reserved.
+// Copyright (c) 2018-2023 VMware, Inc. All Rights Reserved.
 // SPDX-License-Identifier: Apache-2.0
 
 package config
 
 import (
 	"context"
+	"fmt"
+	"net"
+	"net/url"
+	"sort"
 	"strconv"
 	"strings"
 
@@ -14,8 +18,10 @@ import (
 	logf "sigs.k8s.io/controller-runtime/pkg/log"
 
 	"github.com/pkg/errors"
+	"github.com/vmware/govmomi/sts"
 
 	"github.com/vmware-tanzu/vm-operator/pkg/lib"
+	"github.com/vmware-tanzu/vm-operator/pkg/topology"
 	"github.com/vmware-tanzu/vm-operator/pkg/vmprovider/providers/vsphere/credentials"
 )
 
@@ -24,6 +30,10 @@ var log = logf.Log.WithName("vsphere").WithName("config")
 // VSphereVMProviderConfig represents the configuration for a Vsphere VM Provider instance.
 // Contains information enabling integration with a backend vSphere instance for VM management.
 type VSphereVMProviderConfig struct {
+	// VCenterName is the name of the vCenter that this config is for. It is empty for the
+	// default vCenter.
+	VCenterName string
+
 	VcPNID                      string
 	VcPort                      string
 	VcCreds                     *credentials.VSphereVMProviderCredentials
@@ -33,10 +43,18 @@ type VSphereVMProviderConfig struct {
 	CAFilePath                  string
 	InsecureSkipTLSVerify       bool // Always false in WCP env.
 
+	// VcCredsSource is the source of the credentials that are used to login to vCenter. When nil,
+	// VcCreds is used.
+	VcCredsSource credentials.Source
+
 	// These are Zone and/or Namespace specific.
 	ResourcePool string
 	Folder       string
 
+	// PlacementScorerWeights are the weights of the placement scorers by name. When nil, the
+	// default weights are used.
+	PlacementScorerWeights map[string]int32
+
 	// Only set in simulated testing env.
 	Datastore string
 	Network   string
@@ -60,6 +78,31 @@ const (
 	insecureSkipTLSVerifyKey = "InsecureSkipTLSVerify"
 	caFilePathKey            = "CAFilePath"
 	ContentSourceKey         = "ContentSource"
+	// placementScorerWeightsKey value is a comma separated list of scorer=weight pairs.
+	placementScorerWeightsKey = "PlacementScorerWeights"
+	// ZoneVCentersKey value is a comma separated list of zone=vCenter[/datacenter] pairs that map the
+	// availability zones to the vCenter and Datacenter that manage them. It is only read from the
+	// ConfigMap of the default vCenter.
+	ZoneVCentersKey = "AvailabilityZoneVCenters"
+	// vcCredsSourceKey value is one of the VcCredsSource values below. Defaults to VcCredsSourceSecret.
+	vcCredsSourceKey = "VcCredsSource"
+	// vcCredsFilePathKey value is the directory with the username and password files of the
+	// VcCredsSourceFile source, and of the VcCredsSourceTokenExchange source when set.
+	vcCredsFilePathKey = "VcCredsFilePath"
+	// vcSTSURLKey value is the URL of the STS endpoint of the VcCredsSourceTokenExchange source.
+	// Defaults to the default SSO domain STS endpoint of the vCenter.
+	vcSTSURLKey = "VcSTSURL"
+
+	// VcCredsSourceSecret reads the username and password from the VcCredsSecretName Secret.
+	VcCredsSourceSecret = "Secret"
+	// VcCredsSourceFile reads the username and password from the files in VcCredsFilePath, and
+	// watches them for rotation.
+	VcCredsSourceFile = "File"
+	// VcCredsSourceTokenExchange exchanges the username and password, from VcCredsFilePath when
+	// set and otherwise from the VcCredsSecretName Secret, for a SAML bearer token from the STS.
+	VcCredsSourceTokenExchange = "TokenExchange"
+
+	defaultSTSDomain = "vsphere.local"
 
 	NetworkConfigMapName = "vmoperator-network-config"
 	NameserversKey       = "nameservers"    // Key in the NetworkConfigMapName.
@@ -116,6 +159,15 @@ func ConfigMapToProviderConfig( //nolint: revive // Ignore linter error about st
 		caFilePath = ca
 	}
 
+	var scorerWeights map[string]int32
+	if w, ok := configMap.Data[placementScorerWeightsKey]; ok {
+		var err error
+		scorerWeights, err = parsePlacementScorerWeights(w)
+		if err != nil {
+			return nil, errors.Wrap(err, "unable to parse value of PlacementScorerWeights")
+		}
+	}
+
 	ret := &VSphereVMProviderConfig{
 		VcPNID:                      vcPNID,
 		VcPort:                      vcPort,
@@ -129,21 +181,164 @@ func ConfigMapToProviderConfig( //nolint: revive // Ignore linter error about st
 		UseInventoryAsContentSource: useInventory,
 		InsecureSkipTLSVerify:       insecureSkipTLSVerify,
 		CAFilePath:                  caFilePath,
+		PlacementScorerWeights:      scorerWeights,
 	}
 
 	return ret, nil
 }
 
-func configMapToProviderCredentials(
+// parsePlacementScorerWeights parses a comma separated list of scorer=weight pairs, such as
+// "DRSRating=2,LeastVMsInNamespace=1". A scorer with a weight of zero is disabled.
+func parsePlacementScorerWeights(s string) (map[string]int32, error) {
+	weights := map[string]int32{}
+
+	for _, pair := range strings.Split(s, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+
+		name, value, ok := strings.Cut(pair, "=")
+		name = strings.TrimSpace(name)
+		if !ok || name == "" {
+			return nil, errors.Errorf("invalid scorer weight %q", pair)
+		}
+
+		weight, err := strconv.ParseInt(strings.TrimSpace(value), 10, 32)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid weight of scorer %s", name)
+		}
+		if weight < 0 {
+			return nil, errors.Errorf("weight of scorer %s must not be negative", name)
+		}
+
+		weights[name] = int32(weight)
+	}
+
+	return weights, nil
+}
+
+// parseZoneVCenterRefs parses a comma separated list of zone=vCenter[/datacenter] pairs, such as
+// "zone-a=vc-2/datacenter-2,zone-b=vc-2". An empty vCenter is the default vCenter, and an empty
+// datacenter is the Datacenter of the vCenter's ConfigMap.
+func parseZoneVCenterRefs(s string) (topology.ZoneVCenterRefs, error) {
+	refs := topology.ZoneVCenterRefs{}
+
+	for _, pair := range strings.Split(s, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+
+		zoneName, value, ok := strings.Cut(pair, "=")
+		zoneName = strings.TrimSpace(zoneName)
+		if !ok || zoneName == "" {
+			return nil, errors.Errorf("invalid zone vCenter %q", pair)
+		}
+
+		vCenter, datacenter, _ := strings.Cut(strings.TrimSpace(value), "/")
+		refs[zoneName] = topology.VCenterRef{VCenter: vCenter, Datacenter: datacenter}
+	}
+
+	return refs, nil
+}
+
+// GetZoneVCenterRefs returns the vCenter and Datacenter that manage each availability zone, from the
+// provider ConfigMap of the default vCenter.
+func GetZoneVCenterRefs(
+	ctx context.Context,
+	client ctrlruntime.Client) (topology.ZoneVCenterRefs, error) {
+
+	configMap, err := getProviderConfigMap(ctx, client, "")
+	if err != nil {
+		return nil, err
+	}
+
+	refs, err := parseZoneVCenterRefs(configMap.Data[ZoneVCentersKey])
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to parse value of AvailabilityZoneVCenters")
+	}
+
+	return refs, nil
+}
+
+func formatPlacementScorerWeights(weights map[string]int32) string {
+	pairs := make([]string, 0, len(weights))
+	for name, weight := range weights {
+		pairs = append(pairs, fmt.Sprintf("%s=%d", name, weight))
+	}
+	sort.Strings(pairs)
+	return strings.Join(pairs, ",")
+}
+
+// configMapToCredentialsSource returns the source of the credentials configured by the provider ConfigMap,
+// and the source of the username and password that it is based on.
+func configMapToCredentialsSource(
 	client ctrlruntime.Client,
-	configMap *corev1.ConfigMap) (*credentials.VSphereVMProviderCredentials, error) {
+	configMap *corev1.ConfigMap,
+	providerConfig *VSphereVMProviderConfig) (credentials.Source, credentials.Source, error) {
+
+	newSecretSource := func() (credentials.Source, error) {
+		secretName := configMap.Data[vcCredsSecretNameKey]
+		if secretName == "" {
+			return nil, errors.Errorf("%s creds secret not set in vmop system namespace", vcCredsSecretNameKey)
+		}
+		return credentials.NewSecretSource(client, configMap.Namespace, secretName), nil
+	}
+
+	filePath := configMap.Data[vcCredsFilePathKey]
+
+	switch sourceType := configMap.Data[vcCredsSourceKey]; sourceType {
+	case "", VcCredsSourceSecret:
+		source, err := newSecretSource()
+		return source, source, err
+
+	case VcCredsSourceFile:
+		if filePath == "" {
+			return nil, nil, errors.Errorf("%s must be set for the %s credentials source", vcCredsFilePathKey, sourceType)
+		}
+		source := credentials.NewFileSource(filePath)
+		return source, source, nil
+
+	case VcCredsSourceTokenExchange:
+		var base credentials.Source
+		if filePath != "" {
+			base = credentials.NewFileSource(filePath)
+		} else {
+			var err error
+			if base, err = newSecretSource(); err != nil {
+				return nil, nil, err
+			}
+		}
+
+		stsURL, err := getSTSURL(configMap, providerConfig)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		source := credentials.NewTokenExchangeSource(base, stsURL,
+			providerConfig.InsecureSkipTLSVerify, providerConfig.CAFilePath)
+		return source, base, nil
 
-	secretName := configMap.Data[vcCredsSecretNameKey]
-	if secretName == "" {
-		return nil, errors.Errorf("%s creds secret not set in vmop system namespace", vcCredsSecretNameKey)
+	default:
+		return nil, nil, errors.Errorf("unknown %s %q", vcCredsSourceKey, sourceType)
+	}
+}
+
+func getSTSURL(configMap *corev1.ConfigMap, providerConfig *VSphereVMProviderConfig) (*url.URL, error) {
+	if s, ok := configMap.Data[vcSTSURLKey]; ok {
+		u, err := url.Parse(s)
+		if err != nil {
+			return nil, errors.Wrapf(err, "unable to parse value of %s", vcSTSURLKey)
+		}
+		return u, nil
 	}
 
-	return credentials.GetProviderCredentials(client, configMap.Namespace, secretName)
+	return &url.URL{
+		Scheme: "https",
+		Host:   net.JoinHostPort(providerConfig.VcPNID, providerConfig.VcPort),
+		Path:   sts.Path + "/" + defaultSTSDomain,
+	}, nil
 }
 
 func GetDNSInformationFromConfigMap(client ctrlruntime.Client) ([]string, []string, error) {
@@ -185,10 +380,20 @@ func GetDNSInformationFromConfigMap(client ctrlruntime.Client) ([]string, []stri
 	return nameservers, searchSuffixes, nil
 }
 
-// getProviderConfigMap returns the provider ConfigMap.
+// ProviderConfigMapNameForVCenter returns the name of the provider ConfigMap of the named vCenter.
+// The default vCenter, that has an empty name, is configured by the ProviderConfigMapName ConfigMap.
+func ProviderConfigMapNameForVCenter(vCenterName string) string {
+	if vCenterName == "" {
+		return ProviderConfigMapName
+	}
+	return vCenterName + "." + ProviderConfigMapName
+}
+
+// getProviderConfigMap returns the provider ConfigMap of the named vCenter.
 func getProviderConfigMap(
 	ctx context.Context,
-	client ctrlruntime.Client) (*corev1.ConfigMap, error) {
+	client ctrlruntime.Client,
+	vCenterName string) (*corev1.ConfigMap, error) {
 
 	vmopNamespace, err := lib.GetVMOpNamespaceFromEnv()
 	if err != nil {
@@ -196,7 +401,7 @@ func getProviderConfigMap(
 	}
 
 	configMap := &corev1.ConfigMap{}
-	configMapKey := ctrlruntime.ObjectKey{Name: ProviderConfigMapName, Namespace: vmopNamespace}
+	configMapKey := ctrlruntime.ObjectKey{Name: ProviderConfigMapNameForVCenter(vCenterName), Namespace: vmopNamespace}
 	if err := client.Get(ctx, configMapKey, configMap); err != nil {
 		// Log message used by VMC LINT. Refer to before making changes
 		return nil, errors.Wrapf(err, "error retrieving the provider ConfigMap %s", configMapKey)
@@ -210,21 +415,40 @@ func GetProviderConfig(
 	ctx context.Context,
 	client ctrlruntime.Client) (*VSphereVMProviderConfig, error) {
 
-	configMap, err := getProviderConfigMap(ctx, client)
+	return GetProviderConfigForVCenter(ctx, client, "")
+}
+
+// GetProviderConfigForVCenter returns a provider config constructed from the provider ConfigMap of the named
+// vCenter in the VM Operator namespace. An empty name is the default vCenter.
+func GetProviderConfigForVCenter(
+	ctx context.Context,
+	client ctrlruntime.Client,
+	vCenterName string) (*VSphereVMProviderConfig, error) {
+
+	configMap, err := getProviderConfigMap(ctx, client, vCenterName)
 	if err != nil {
 		return nil, err
 	}
 
-	vcCreds, err := configMapToProviderCredentials(client, configMap)
+	providerConfig, err := ConfigMapToProviderConfig(configMap, nil)
 	if err != nil {
 		return nil, err
 	}
+	providerConfig.VCenterName = vCenterName
 
-	providerConfig, err := ConfigMapToProviderConfig(configMap, vcCreds)
+	source, baseSource, err := configMapToCredentialsSource(client, configMap, providerConfig)
 	if err != nil {
 		return nil, err
 	}
 
+	// The username and password are always read so the config is only returned when they are present.
+	vcCreds, err := baseSource.GetCredentials(ctx)
+	if err != nil {
+		return nil, err
+	}
+	providerConfig.VcCreds = vcCreds
+	providerConfig.VcCredsSource = source
+
 	return providerConfig, nil
 }
 
@@ -244,6 +468,9 @@ func setConfigMapData(configMap *corev1.ConfigMap, config *VSphereVMProviderConf
 	configMap.Data[useInventoryKey] = strconv.FormatBool(config.UseInventoryAsContentSource)
 	configMap.Data[caFilePathKey] = config.CAFilePath
 	configMap.Data[insecureSkipTLSVerifyKey] = strconv.FormatBool(config.InsecureSkipTLSVerify)
+	if config.PlacementScorerWeights != nil {
+		configMap.Data[placementScorerWeightsKey] = formatPlacementScorerWeights(config.PlacementScorerWeights)
+	}
 }
 
 // ProviderConfigToConfigMap returns the ConfigMap for the config.
@@ -255,7 +482,7 @@ func ProviderConfigToConfigMap(
 
 	configMap := &corev1.ConfigMap{
 		ObjectMeta: metav1.ObjectMeta{
-			Name:      ProviderConfigMapName,
+			Name:      ProviderConfigMapNameForVCenter(config.VCenterName),
 			Namespace: namespace,
 		},
 	}
@@ -264,9 +491,10 @@ func ProviderConfigToConfigMap(
 	return configMap
 }
 
-// UpdateVcInConfigMap updates the ConfigMap with the new vCenter PNID and Port. Returns false if no updated needed.
+// UpdateVcInConfigMap updates the ConfigMap of the default vCenter with the new vCenter PNID and Port. Returns
+// false if no updated needed.
 func UpdateVcInConfigMap(ctx context.Context, client ctrlruntime.Client, vcPNID, vcPort string) (bool, error) {
-	configMap, err := getProviderConfigMap(ctx, client)
+	configMap, err := getProviderConfigMap(ctx, client, "")
 	if err != nil {
 		return false, err
 	}
@@ -1,4 +1,4 @@
-// Copyright (c) 2019-2021 VMware, Inc. All Rights Reserved.
+// Copyright (c) 2019-2023 VMware, Inc. All Rights Reserved.
 // SPDX-License-Identifier: Apache-2.0
 
 package config_test
@@ -10,7 +10,9 @@ import (
 
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 
+	"github.com/vmware-tanzu/vm-operator/pkg/topology"
 	"github.com/vmware-tanzu/vm-operator/pkg/vmprovider/providers/vsphere/config"
 	"github.com/vmware-tanzu/vm-operator/pkg/vmprovider/providers/vsphere/credentials"
 	"github.com/vmware-tanzu/vm-operator/test/builder"
@@ -65,6 +67,200 @@ func configTests() {
 		})
 	})
 
+	Describe("GetProviderConfigForVCenter", func() {
+
+		const vCenterName = "vc-2"
+
+		Context("when the vCenter's ConfigMap doesn't exist", func() {
+			It("returns no provider config and an error", func() {
+				providerConfig, err := config.GetProviderConfigForVCenter(ctx, ctx.Client, vCenterName)
+				Expect(err).To(HaveOccurred())
+				Expect(providerConfig).To(BeNil())
+			})
+		})
+
+		Context("when the vCenter's ConfigMap exists", func() {
+			JustBeforeEach(func() {
+				configMap := &corev1.ConfigMap{}
+				key := client.ObjectKey{Name: config.ProviderConfigMapName, Namespace: ctx.PodNamespace}
+				Expect(ctx.Client.Get(ctx, key, configMap)).To(Succeed())
+
+				vcConfigMap := &corev1.ConfigMap{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      config.ProviderConfigMapNameForVCenter(vCenterName),
+						Namespace: ctx.PodNamespace,
+					},
+					Data: map[string]string{},
+				}
+				for k, v := range configMap.Data {
+					vcConfigMap.Data[k] = v
+				}
+				vcConfigMap.Data["Datacenter"] = "datacenter-99"
+				Expect(ctx.Client.Create(ctx, vcConfigMap)).To(Succeed())
+			})
+
+			It("returns the provider config of the vCenter", func() {
+				providerConfig, err := config.GetProviderConfigForVCenter(ctx, ctx.Client, vCenterName)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(providerConfig.VCenterName).To(Equal(vCenterName))
+				Expect(providerConfig.Datacenter).To(Equal("datacenter-99"))
+
+				defaultConfig, err := config.GetProviderConfig(ctx, ctx.Client)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(defaultConfig.VCenterName).To(BeEmpty())
+				Expect(defaultConfig.Datacenter).ToNot(Equal("datacenter-99"))
+			})
+		})
+	})
+
+	Describe("GetZoneVCenterRefs", func() {
+
+		var zoneVCenters string
+
+		BeforeEach(func() {
+			zoneVCenters = ""
+		})
+
+		JustBeforeEach(func() {
+			if zoneVCenters == "" {
+				return
+			}
+			configMap := &corev1.ConfigMap{}
+			key := client.ObjectKey{Name: config.ProviderConfigMapName, Namespace: ctx.PodNamespace}
+			Expect(ctx.Client.Get(ctx, key, configMap)).To(Succeed())
+			configMap.Data[config.ZoneVCentersKey] = zoneVCenters
+			Expect(ctx.Client.Update(ctx, configMap)).To(Succeed())
+		})
+
+		Context("when the zones are not mapped", func() {
+			It("returns no vCenters", func() {
+				refs, err := config.GetZoneVCenterRefs(ctx, ctx.Client)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(refs).To(BeEmpty())
+			})
+		})
+
+		Context("when the zones are mapped", func() {
+			BeforeEach(func() {
+				zoneVCenters = "zone-a=vc-2/datacenter-2, zone-b=vc-2,zone-c=/datacenter-3"
+			})
+
+			It("returns the vCenter and Datacenter of the zones", func() {
+				refs, err := config.GetZoneVCenterRefs(ctx, ctx.Client)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(refs).To(Equal(topology.ZoneVCenterRefs{
+					"zone-a": {VCenter: "vc-2", Datacenter: "datacenter-2"},
+					"zone-b": {VCenter: "vc-2"},
+					"zone-c": {Datacenter: "datacenter-3"},
+				}))
+			})
+		})
+
+		Context("when a zone is invalid", func() {
+			BeforeEach(func() {
+				zoneVCenters = "zone-a=vc-2,vc-3"
+			})
+
+			It("returns an error", func() {
+				_, err := config.GetZoneVCenterRefs(ctx, ctx.Client)
+				Expect(err).To(MatchError(ContainSubstring(`invalid zone vCenter "vc-3"`)))
+			})
+		})
+	})
+
+	Describe("GetProviderConfig credentials source", func() {
+
+		var data map[string]string
+
+		BeforeEach(func() {
+			data = map[string]string{}
+		})
+
+		JustBeforeEach(func() {
+			configMap := &corev1.ConfigMap{}
+			key := client.ObjectKey{Name: config.ProviderConfigMapName, Namespace: ctx.PodNamespace}
+			Expect(ctx.Client.Get(ctx, key, configMap)).To(Succeed())
+			for k, v := range data {
+				configMap.Data[k] = v
+			}
+			Expect(ctx.Client.Update(ctx, configMap)).To(Succeed())
+		})
+
+		Context("when the source is not set", func() {
+			It("reads the credentials from the Secret", func() {
+				providerConfig, err := config.GetProviderConfig(ctx, ctx.Client)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(providerConfig.VcCreds).ToNot(BeNil())
+				Expect(providerConfig.VcCredsSource).ToNot(BeNil())
+
+				creds, err := providerConfig.VcCredsSource.GetCredentials(ctx)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(creds).To(Equal(providerConfig.VcCreds))
+			})
+		})
+
+		Context("when the source is File", func() {
+			BeforeEach(func() {
+				data["VcCredsSource"] = config.VcCredsSourceFile
+			})
+
+			It("returns an error when the file path is not set", func() {
+				_, err := config.GetProviderConfig(ctx, ctx.Client)
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(Equal("VcCredsFilePath must be set for the File credentials source"))
+			})
+
+			Context("when the files do not exist", func() {
+				BeforeEach(func() {
+					data["VcCredsFilePath"] = "/does-not-exist"
+				})
+
+				It("returns an error", func() {
+					_, err := config.GetProviderConfig(ctx, ctx.Client)
+					Expect(err).To(HaveOccurred())
+					Expect(err.Error()).To(ContainSubstring("cannot read provider credentials file /does-not-exist/username"))
+				})
+			})
+		})
+
+		Context("when the source is TokenExchange", func() {
+			BeforeEach(func() {
+				data["VcCredsSource"] = config.VcCredsSourceTokenExchange
+			})
+
+			It("returns a source that exchanges the credentials of the Secret for a token", func() {
+				providerConfig, err := config.GetProviderConfig(ctx, ctx.Client)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(providerConfig.VcCreds.Username).ToNot(BeEmpty())
+				Expect(providerConfig.VcCredsSource).To(BeAssignableToTypeOf(&credentials.TokenExchangeSource{}))
+			})
+
+			Context("when the STS URL is invalid", func() {
+				BeforeEach(func() {
+					data["VcSTSURL"] = "http://[::1"
+				})
+
+				It("returns an error", func() {
+					_, err := config.GetProviderConfig(ctx, ctx.Client)
+					Expect(err).To(HaveOccurred())
+					Expect(err.Error()).To(ContainSubstring("unable to parse value of VcSTSURL"))
+				})
+			})
+		})
+
+		Context("when the source is unknown", func() {
+			BeforeEach(func() {
+				data["VcCredsSource"] = "Kerberos"
+			})
+
+			It("returns an error", func() {
+				_, err := config.GetProviderConfig(ctx, ctx.Client)
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(Equal(`unknown VcCredsSource "Kerberos"`))
+			})
+		})
+	})
+
 	Describe("UpdateVcInConfigMap", func() {
 
 		Context("UpdateVcInConfigMap", func() {
@@ -99,6 +295,16 @@ func configTests() {
 	})
 }
 
+var _ = Describe("ProviderConfigMapNameForVCenter", func() {
+	It("returns the name of the provider ConfigMap for the default vCenter", func() {
+		Expect(config.ProviderConfigMapNameForVCenter("")).To(Equal(config.ProviderConfigMapName))
+	})
+
+	It("returns the name of the provider ConfigMap for a named vCenter", func() {
+		Expect(config.ProviderConfigMapNameForVCenter("vc-2")).To(Equal("vc-2." + config.ProviderConfigMapName))
+	})
+})
+
 var _ = Describe("ConfigMapToProviderConfig", func() {
 
 	var (
@@ -164,6 +370,40 @@ var _ = Describe("ConfigMapToProviderConfig", func() {
 		})
 	})
 
+	Context("PlacementScorerWeights", func() {
+		It("PlacementScorerWeights is unset in configMap", func() {
+			providerConfig, err := config.ConfigMapToProviderConfig(configMap, providerCreds)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(providerConfig.PlacementScorerWeights).To(BeNil())
+		})
+
+		Context("PlacementScorerWeights is set in configMap", func() {
+			BeforeEach(func() {
+				providerConfigIn.PlacementScorerWeights = map[string]int32{"DRSRating": 2, "LeastVMsInNamespace": 0}
+			})
+
+			It("PlacementScorerWeights are in config", func() {
+				Expect(configMap.Data["PlacementScorerWeights"]).To(Equal("DRSRating=2,LeastVMsInNamespace=0"))
+
+				providerConfig, err := config.ConfigMapToProviderConfig(configMap, providerCreds)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(providerConfig.PlacementScorerWeights).To(Equal(providerConfigIn.PlacementScorerWeights))
+			})
+		})
+
+		DescribeTable("returns an error for invalid weights",
+			func(weights string) {
+				configMap.Data["PlacementScorerWeights"] = weights
+				_, err := config.ConfigMapToProviderConfig(configMap, providerCreds)
+				Expect(err).To(HaveOccurred())
+			},
+			Entry("missing weight", "DRSRating"),
+			Entry("missing name", "=1"),
+			Entry("non-numeric weight", "DRSRating=high"),
+			Entry("negative weight", "DRSRating=-1"),
+		)
+	})
+
 	Describe("Tests for TLS configuration", func() {
 
 		Context("when no TLS configuration is specified", func() {
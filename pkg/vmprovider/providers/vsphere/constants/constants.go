@@ -48,7 +48,7 @@ const (
 	// VMImageCLVersionAnnotation VirtualMachineImage annotation to cache the last fetched version.
 	VMImageCLVersionAnnotation = pkg.VMOperatorKey + "/content-library-version"
 	// VMImageCLVersionAnnotationVersion is the version of the VMImageCLVersionAnnotation for the VirtualMachineImage.
-	VMImageCLVersionAnnotationVersion = 1
+	VMImageCLVersionAnnotationVersion = 2
 
 	PCIPassthruMMIOOverrideAnnotation = pkg.VMOperatorKey + "/pci-passthru-64bit-mmio-size"
 	PCIPassthruMMIOExtraConfigKey     = "pciPassthru.use64bitMMIO"    //nolint:gosec
@@ -86,6 +86,11 @@ const (
 	InstanceStorageSelectedNodeMOIDAnnotationKey = "vmoperator.vmware.com/instance-storage-selected-node-moid"
 	// InstanceStorageSelectedNodeAnnotationKey value corresponds to FQDN of ESXi node that is elected to place instance storage volumes.
 	InstanceStorageSelectedNodeAnnotationKey = "vmoperator.vmware.com/instance-storage-selected-node"
+	// PlacementDecisionAnnotationKey value describes why the VM was placed in its zone, resource pool, and host.
+	PlacementDecisionAnnotationKey = "vmoperator.vmware.com/placement-decision"
+	// PlacementVCenterAnnotationKey value is the vCenter and Datacenter, separated by a slash, of the zone selected by
+	// the placement of a VM, until the VM's zone label is saved.
+	PlacementVCenterAnnotationKey = "vmoperator.vmware.com/placement-vcenter"
 	// KubernetesSelectedNodeAnnotationKey annotation key to set selected node on PVC.
 	KubernetesSelectedNodeAnnotationKey = "volume.kubernetes.io/selected-node"
 	// InstanceStoragePVPlacementErrorPrefix indicates prefix of error value.
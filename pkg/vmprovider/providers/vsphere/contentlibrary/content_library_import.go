@@ -0,0 +1,312 @@
+// Copyright (c) 2023 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package contentlibrary
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/vmware/govmomi/ovf"
+	"github.com/vmware/govmomi/vapi/library"
+	"github.com/vmware/govmomi/vapi/rest"
+)
+
+// ImportPhase is the phase of an import of a library item from a URL.
+type ImportPhase string
+
+const (
+	// ImportPhaseDownloading indicates the files are being pulled into the library item.
+	ImportPhaseDownloading ImportPhase = "Downloading"
+	// ImportPhaseDownloaded indicates all the files have been pulled and the import can be completed.
+	ImportPhaseDownloaded ImportPhase = "Downloaded"
+	// ImportPhaseDone indicates the import was completed and the library item was validated.
+	ImportPhaseDone ImportPhase = "Done"
+	// ImportPhaseDownloadFailed indicates a file could not be pulled or did not match its checksum.
+	ImportPhaseDownloadFailed ImportPhase = "DownloadFailed"
+	// ImportPhaseValidationFailed indicates the library item was not valid once the import was completed.
+	ImportPhaseValidationFailed ImportPhase = "ValidationFailed"
+)
+
+// ImportStatus is the status of an import of a library item from a URL.
+type ImportStatus struct {
+	Phase ImportPhase
+	// BytesTransferred is the number of bytes pulled so far across all the files.
+	BytesTransferred int64
+	// Size is the total size of all the files, if known.
+	Size int64
+	// Message describes the failure of a failed import.
+	Message string
+}
+
+const (
+	updateSessionFileResource = "/com/vmware/content/library/item/updatesession/file"
+
+	updateSessionStateActive   = "ACTIVE"
+	updateSessionStateDone     = "DONE"
+	updateSessionStateError    = "ERROR"
+	updateSessionStateCanceled = "CANCELED"
+
+	updateFileStatusReady = "READY"
+	updateFileStatusError = "ERROR"
+)
+
+// ImportLibraryItemFromURL creates a library item and an update session that pulls the OVA or OVF at
+// sourceURL, and the files referenced by an OVF, into it. The checksum, if any, is verified by the
+// library against the file at sourceURL. The import proceeds asynchronously: use the returned update
+// session ID with GetLibraryItemImportStatus and CompleteLibraryItemImport.
+func (cs *provider) ImportLibraryItemFromURL(
+	ctx context.Context,
+	libraryUUID, itemName, itemDescription, sourceURL string,
+	checksum *library.Checksum) (string, string, error) {
+
+	logger := log.WithValues("libraryUUID", libraryUUID, "itemName", itemName, "url", sourceURL)
+	logger.Info("Importing Library Item from URL")
+
+	files, err := cs.importFiles(ctx, sourceURL, checksum)
+	if err != nil {
+		return "", "", err
+	}
+
+	itemID, err := cs.libMgr.CreateLibraryItem(ctx, library.Item{
+		Name:        itemName,
+		Description: &itemDescription,
+		Type:        library.ItemTypeOVF,
+		LibraryID:   libraryUUID,
+	})
+	if err != nil {
+		return "", "", errors.Wrapf(err, "failed to create library item %s", itemName)
+	}
+
+	sessionID, err := cs.startImport(ctx, itemID, files)
+	if err != nil {
+		if delErr := cs.DeleteLibraryItem(ctx, itemID); delErr != nil {
+			logger.Error(delErr, "failed to delete library item after failed import", "itemID", itemID)
+		}
+		return "", "", err
+	}
+
+	logger.V(4).Info("Import update session started", "itemID", itemID, "sessionID", sessionID)
+	return itemID, sessionID, nil
+}
+
+// GetLibraryItemImportStatus returns the status of the import update session. The session is kept
+// alive while its files are being pulled.
+func (cs *provider) GetLibraryItemImportStatus(ctx context.Context, sessionID string) (*ImportStatus, error) {
+	session, err := cs.libMgr.GetLibraryItemUpdateSession(ctx, sessionID)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to get update session %s", sessionID)
+	}
+
+	var files []library.UpdateFile
+	r := cs.libMgr.Resource(updateSessionFileResource).WithParam("update_session_id", sessionID)
+	if err := cs.libMgr.Do(ctx, r.Request(http.MethodGet), &files); err != nil {
+		return nil, errors.Wrapf(err, "failed to list files of update session %s", sessionID)
+	}
+
+	status := &ImportStatus{}
+	ready := len(files) > 0
+	for _, f := range files {
+		status.BytesTransferred += f.BytesTransferred
+		status.Size += f.Size
+
+		switch f.Status {
+		case updateFileStatusReady:
+		case updateFileStatusError:
+			status.Phase = ImportPhaseDownloadFailed
+			status.Message = localizableMessage(f.ErrorMessage, "failed to download file "+f.Name)
+			return status, nil
+		default:
+			ready = false
+		}
+	}
+
+	switch session.State {
+	case updateSessionStateActive:
+		if ready {
+			status.Phase = ImportPhaseDownloaded
+		} else {
+			status.Phase = ImportPhaseDownloading
+			if err := cs.libMgr.KeepAliveLibraryItemUpdateSession(ctx, sessionID); err != nil {
+				return nil, errors.Wrapf(err, "failed to keep update session %s alive", sessionID)
+			}
+		}
+	case updateSessionStateDone:
+		status.Phase = ImportPhaseDone
+	case updateSessionStateError:
+		status.Phase = ImportPhaseValidationFailed
+		status.Message = localizableMessage(session.ErrorMessage, "update session failed")
+	case updateSessionStateCanceled:
+		status.Phase = ImportPhaseDownloadFailed
+		status.Message = "update session was canceled"
+	default:
+		return nil, errors.Errorf("unexpected state %q of update session %s", session.State, sessionID)
+	}
+
+	return status, nil
+}
+
+// CompleteLibraryItemImport completes the import update session once all its files have been
+// downloaded. The library then validates the item.
+func (cs *provider) CompleteLibraryItemImport(ctx context.Context, sessionID string) error {
+	log.Info("Completing Library Item import", "sessionID", sessionID)
+	return cs.libMgr.CompleteLibraryItemUpdateSession(ctx, sessionID)
+}
+
+// importFiles returns the files to pull into the library item. An OVA is pulled as a single file, while
+// an OVF descriptor is downloaded to find the disks and other files it references.
+func (cs *provider) importFiles(
+	ctx context.Context,
+	sourceURL string,
+	checksum *library.Checksum) ([]library.UpdateFile, error) {
+
+	u, err := url.Parse(sourceURL)
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid URL %s", sourceURL)
+	}
+
+	files := []library.UpdateFile{
+		{
+			Name:           path.Base(u.Path),
+			SourceType:     "PULL",
+			SourceEndpoint: &library.TransferEndpoint{URI: sourceURL},
+			Checksum:       checksum,
+		},
+	}
+
+	if !strings.EqualFold(path.Ext(u.Path), ".ovf") {
+		return files, nil
+	}
+
+	envelope, err := fetchOvfEnvelope(ctx, sourceURL)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, ref := range envelope.References {
+		refURL, err := resolveOvfFileRef(u, ref.Href)
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, library.UpdateFile{
+			Name:           path.Base(refURL.Path),
+			SourceType:     "PULL",
+			SourceEndpoint: &library.TransferEndpoint{URI: refURL.String()},
+		})
+	}
+
+	return files, nil
+}
+
+// resolveOvfFileRef returns the URL of a file referenced by an OVF descriptor downloaded from ovfURL.
+// The descriptor is untrusted and its files are pulled by vCenter, so a reference must be a path
+// relative to the descriptor that does not escape its directory, and it must resolve to the same
+// scheme and host as the descriptor.
+func resolveOvfFileRef(ovfURL *url.URL, href string) (*url.URL, error) {
+	ref, err := url.Parse(href)
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid file reference %s in OVF", href)
+	}
+
+	if ref.IsAbs() || ref.Host != "" || ref.User != nil || strings.HasPrefix(ref.Path, "/") {
+		return nil, errors.Errorf("file reference %s in OVF is not a relative path", href)
+	}
+
+	for _, segment := range strings.Split(ref.Path, "/") {
+		if segment == ".." {
+			return nil, errors.Errorf("file reference %s in OVF is outside of the OVF directory", href)
+		}
+	}
+
+	refURL := ovfURL.ResolveReference(ref)
+	if refURL.Scheme != ovfURL.Scheme || refURL.Host != ovfURL.Host ||
+		!strings.HasPrefix(refURL.Path, path.Dir(ovfURL.Path)) {
+		return nil, errors.Errorf("file reference %s in OVF is outside of the OVF directory", href)
+	}
+
+	return refURL, nil
+}
+
+func (cs *provider) startImport(ctx context.Context, itemID string, files []library.UpdateFile) (string, error) {
+	sessionID, err := cs.libMgr.CreateLibraryItemUpdateSession(ctx, library.Session{LibraryItemID: itemID})
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to create update session for library item %s", itemID)
+	}
+
+	for _, f := range files {
+		if _, err := cs.libMgr.AddLibraryItemFile(ctx, sessionID, f); err != nil {
+			if failErr := cs.libMgr.FailLibraryItemUpdateSession(ctx, sessionID); failErr != nil {
+				log.Error(failErr, "failed to fail update session", "sessionID", sessionID)
+			}
+			return "", errors.Wrapf(err, "failed to add file %s to update session %s", f.Name, sessionID)
+		}
+	}
+
+	return sessionID, nil
+}
+
+const (
+	// ovfDownloadTimeout is the timeout for downloading an OVF descriptor, from the request until its
+	// body is read.
+	ovfDownloadTimeout = 30 * time.Second
+	// maxOvfSize is the maximum size of an OVF descriptor. Descriptors only describe the VM and
+	// reference its files, so they are small.
+	maxOvfSize = 10 * 1024 * 1024
+)
+
+// ovfHTTPClient downloads the OVF descriptors of imports. Unlike http.DefaultClient, it does not
+// wait on a slow or unresponsive server forever.
+var ovfHTTPClient = &http.Client{Timeout: ovfDownloadTimeout}
+
+func fetchOvfEnvelope(ctx context.Context, ovfURL string) (*ovf.Envelope, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, ovfURL, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := ovfHTTPClient.Do(req)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to download OVF %s", ovfURL)
+	}
+	defer func() {
+		_ = res.Body.Close()
+	}()
+
+	if res.StatusCode != http.StatusOK {
+		return nil, errors.Errorf("failed to download OVF %s: %s", ovfURL, res.Status)
+	}
+
+	if res.ContentLength > maxOvfSize {
+		return nil, errors.Errorf("OVF %s is larger than %d bytes", ovfURL, maxOvfSize)
+	}
+
+	// Read one byte more than the limit to tell an OVF of exactly maxOvfSize bytes from a larger one.
+	data, err := io.ReadAll(io.LimitReader(res.Body, maxOvfSize+1))
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to download OVF %s", ovfURL)
+	}
+	if len(data) > maxOvfSize {
+		return nil, errors.Errorf("OVF %s is larger than %d bytes", ovfURL, maxOvfSize)
+	}
+
+	envelope, err := ovf.Unmarshal(bytes.NewReader(data))
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to parse OVF %s", ovfURL)
+	}
+
+	return envelope, nil
+}
+
+func localizableMessage(msg *rest.LocalizableMessage, defaultMsg string) string {
+	if msg == nil {
+		return defaultMsg
+	}
+	return msg.Error()
+}
@@ -22,6 +22,7 @@ import (
 	"github.com/vmware/govmomi/vim25/soap"
 
 	vmopv1 "github.com/vmware-tanzu/vm-operator/api/v1alpha1"
+	"github.com/vmware-tanzu/vm-operator/pkg/imagetrust"
 	"github.com/vmware-tanzu/vm-operator/pkg/lib"
 	"github.com/vmware-tanzu/vm-operator/pkg/vmprovider/providers/vsphere/constants"
 )
@@ -32,8 +33,16 @@ type Provider interface {
 		notFoundReturnErr bool) (*library.Item, error)
 	ListLibraryItems(ctx context.Context, libraryUUID string) ([]string, error)
 	UpdateLibraryItem(ctx context.Context, itemID, newName string, newDescription *string) error
+	DeleteLibraryItem(ctx context.Context, itemID string) error
+	ImportLibraryItemFromURL(ctx context.Context, libraryUUID, itemName, itemDescription, sourceURL string,
+		checksum *library.Checksum) (string, string, error)
+	GetLibraryItemImportStatus(ctx context.Context, sessionID string) (*ImportStatus, error)
+	CompleteLibraryItemImport(ctx context.Context, sessionID string) error
 	RetrieveOvfEnvelopeFromLibraryItem(ctx context.Context, item *library.Item) (*ovf.Envelope, error)
 	RetrieveOvfEnvelopeByLibraryItemID(ctx context.Context, itemID string) (*ovf.Envelope, error)
+	VerifyLibraryItem(ctx context.Context, itemID string, policy *imagetrust.Policy) error
+	GetLibraryPublishURL(ctx context.Context, libraryUUID string) (string, error)
+	GetOrCreateSubscribedLibrary(ctx context.Context, libraryUUID, publishURL, datastoreMoID string) (string, error)
 
 	// TODO: Testing only. Remove these from this file.
 	CreateLibraryItem(ctx context.Context, libraryItem library.Item, path string) error
@@ -226,6 +235,22 @@ func (cs *provider) UpdateLibraryItem(ctx context.Context, itemID, newName strin
 	return cs.libMgr.UpdateLibraryItem(ctx, item)
 }
 
+// DeleteLibraryItem deletes the content library item. It is not an error if the item does not exist.
+func (cs *provider) DeleteLibraryItem(ctx context.Context, itemID string) error {
+	log.Info("Deleting Library Item", "itemID", itemID)
+
+	item, err := cs.libMgr.GetLibraryItem(ctx, itemID)
+	if err != nil {
+		if lib.IsNotFoundError(err) {
+			return nil
+		}
+		log.Error(err, "error getting library item")
+		return err
+	}
+
+	return cs.libMgr.DeleteLibraryItem(ctx, item)
+}
+
 // Only used in testing.
 func (cs *provider) CreateLibraryItem(ctx context.Context, libraryItem library.Item, path string) error {
 	log.Info("Creating Library Item", "item", libraryItem, "path", path)
@@ -362,7 +387,16 @@ func (cs *provider) generateDownloadURLForLibraryItem(
 		return nil, errors.Errorf("No files with supported deploy type are available for download for %s", item.ID)
 	}
 
-	_, err = cs.libMgr.PrepareLibraryItemDownloadSessionFile(ctx, sessionID, fileToDownload)
+	return cs.prepareDownloadSessionFile(ctx, logger, sessionID, fileToDownload)
+}
+
+// prepareDownloadSessionFile prepares the file of the download session and returns the URL to download it from.
+func (cs *provider) prepareDownloadSessionFile(
+	ctx context.Context,
+	logger logr.Logger,
+	sessionID, fileToDownload string) (*url.URL, error) {
+
+	_, err := cs.libMgr.PrepareLibraryItemDownloadSessionFile(ctx, sessionID, fileToDownload)
 	if err != nil {
 		return nil, err
 	}
@@ -0,0 +1,79 @@
+// Copyright (c) 2023 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package contentlibrary
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+	"github.com/vmware/govmomi/vapi/library"
+)
+
+const (
+	// SubscribedLibraryNamePrefix is the prefix of the name of the libraries that VM Operator creates on a
+	// vCenter to subscribe to a library of the default vCenter. It is followed by the ID of that library.
+	SubscribedLibraryNamePrefix = "vmoperator-"
+
+	libraryTypeSubscribed = "SUBSCRIBED"
+	storageTypeDatastore  = "DATASTORE"
+)
+
+// GetLibraryPublishURL returns the URL that the libraries on the other vCenters subscribe to the library with.
+// An error is returned when the library is not published.
+func (cs *provider) GetLibraryPublishURL(ctx context.Context, libraryUUID string) (string, error) {
+	lib, err := cs.libMgr.GetLibraryByID(ctx, libraryUUID)
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to get content library %s", libraryUUID)
+	}
+
+	pub := lib.Publication
+	if pub == nil || pub.Published == nil || !*pub.Published || pub.PublishURL == "" {
+		return "", errors.Errorf("content library %s is not published so its images cannot be deployed on "+
+			"the other vCenters", libraryUUID)
+	}
+
+	return pub.PublishURL, nil
+}
+
+// GetOrCreateSubscribedLibrary returns the ID of the library on this vCenter that subscribes to the
+// published library of the default vCenter with the ID libraryUUID. When the subscribed library does not
+// exist, it is created on the datastore. Its items are synced on demand, when a VM is deployed from them.
+func (cs *provider) GetOrCreateSubscribedLibrary(
+	ctx context.Context,
+	libraryUUID, publishURL, datastoreMoID string) (string, error) {
+
+	name := SubscribedLibraryNamePrefix + libraryUUID
+
+	ids, err := cs.libMgr.FindLibrary(ctx, library.Find{Name: name, Type: libraryTypeSubscribed})
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to find subscribed content library %s", name)
+	}
+	if len(ids) > 0 {
+		return ids[0], nil
+	}
+
+	onDemand, automaticSync := true, true
+	id, err := cs.libMgr.CreateLibrary(ctx, library.Library{
+		Name: name,
+		Type: libraryTypeSubscribed,
+		Storage: []library.StorageBackings{
+			{
+				DatastoreID: datastoreMoID,
+				Type:        storageTypeDatastore,
+			},
+		},
+		Subscription: &library.Subscription{
+			AuthenticationMethod: "NONE",
+			AutomaticSyncEnabled: &automaticSync,
+			OnDemand:             &onDemand,
+			SubscriptionURL:      publishURL,
+		},
+	})
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to create subscribed content library %s", name)
+	}
+
+	log.Info("Created subscribed content library", "name", name, "id", id, "subscriptionURL", publishURL)
+	return id, nil
+}
@@ -1,22 +1,39 @@
-// Copyright (c) 2022 VMware, Inc. All Rights Reserved.
+// Copyright (c) 2022-2023 VMware, Inc. All Rights Reserved.
 // SPDX-License-Identifier: Apache-2.0
 
 package contentlibrary_test
 
 import (
+	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
 	"os"
+	"path"
 	"strings"
 
 	vmopv1 "github.com/vmware-tanzu/vm-operator/api/v1alpha1"
 
 	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
 	. "github.com/onsi/gomega"
 
 	"github.com/vmware/govmomi/vapi/library"
+	"github.com/vmware/govmomi/vim25/soap"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
+	"github.com/vmware-tanzu/vm-operator/pkg/imagetrust"
 	"github.com/vmware-tanzu/vm-operator/pkg/vmprovider/providers/vsphere/contentlibrary"
 	"github.com/vmware-tanzu/vm-operator/test/builder"
+	"github.com/vmware-tanzu/vm-operator/test/testutil"
 )
 
 func clTests() {
@@ -80,6 +97,22 @@ func clTests() {
 				Expect(ovfEnvelope).ToNot(BeNil())
 			})
 
+			It("Deletes item", func() {
+				item, err := clProvider.GetLibraryItem(ctx, ctx.ContentLibraryID, ctx.ContentLibraryImageName, true)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(item).ToNot(BeNil())
+
+				Expect(clProvider.DeleteLibraryItem(ctx, item.ID)).To(Succeed())
+
+				item, err = clProvider.GetLibraryItem(ctx, ctx.ContentLibraryID, ctx.ContentLibraryImageName, false)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(item).To(BeNil())
+
+				By("Does not return error when item does not exist", func() {
+					Expect(clProvider.DeleteLibraryItem(ctx, "dummy-id")).To(Succeed())
+				})
+			})
+
 			Context("VirtualMachineImageResourceForLibrary", func() {
 				var itemID string
 				JustBeforeEach(func() {
@@ -121,6 +154,238 @@ func clTests() {
 
 		})
 
+		Context("ImportLibraryItemFromURL", func() {
+			var (
+				server   *httptest.Server
+				itemName string
+			)
+
+			BeforeEach(func() {
+				itemName = "imported-item"
+
+				ovfPath := path.Join(testutil.GetRootDirOrDie(), "images", "ttylinux-pc_i486-16.1.ovf")
+				mux := http.NewServeMux()
+				mux.HandleFunc("/images/ttylinux.ovf", func(w http.ResponseWriter, r *http.Request) {
+					http.ServeFile(w, r, ovfPath)
+				})
+				mux.HandleFunc("/images/ttylinux-pc_i486-16.1-disk1.vmdk", func(w http.ResponseWriter, r *http.Request) {
+					_, _ = w.Write([]byte("dummy-disk"))
+				})
+				mux.HandleFunc("/images/malicious.ovf", func(w http.ResponseWriter, r *http.Request) {
+					data, err := os.ReadFile(ovfPath)
+					Expect(err).ToNot(HaveOccurred())
+					_, _ = w.Write(bytes.Replace(data, []byte("ttylinux-pc_i486-16.1-disk1.vmdk"),
+						[]byte(r.URL.Query().Get("href")), 1))
+				})
+				mux.HandleFunc("/images/large.ovf", func(w http.ResponseWriter, r *http.Request) {
+					// Without a Content-Length, so the size is only known once the body is read.
+					w.Header().Set("Content-Type", "application/xml")
+					for i := 0; i < 11; i++ {
+						_, _ = w.Write(bytes.Repeat([]byte(" "), 1024*1024))
+						w.(http.Flusher).Flush()
+					}
+				})
+				server = httptest.NewServer(mux)
+			})
+
+			AfterEach(func() {
+				server.Close()
+			})
+
+			waitForPhase := func(sessionID string, phase contentlibrary.ImportPhase) *contentlibrary.ImportStatus {
+				var status *contentlibrary.ImportStatus
+				Eventually(func() contentlibrary.ImportPhase {
+					var err error
+					status, err = clProvider.GetLibraryItemImportStatus(ctx, sessionID)
+					Expect(err).ToNot(HaveOccurred())
+					return status.Phase
+				}).Should(Equal(phase))
+				return status
+			}
+
+			It("Imports the OVF and the files it references", func() {
+				checksum := &library.Checksum{Algorithm: "SHA256", Checksum: "dummy-checksum"}
+				itemID, sessionID, err := clProvider.ImportLibraryItemFromURL(ctx, ctx.ContentLibraryID, itemName,
+					"imported from URL", server.URL+"/images/ttylinux.ovf", checksum)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(itemID).ToNot(BeEmpty())
+				Expect(sessionID).ToNot(BeEmpty())
+
+				waitForPhase(sessionID, contentlibrary.ImportPhaseDownloaded)
+				Expect(clProvider.CompleteLibraryItemImport(ctx, sessionID)).To(Succeed())
+				waitForPhase(sessionID, contentlibrary.ImportPhaseDone)
+
+				item, err := clProvider.GetLibraryItem(ctx, ctx.ContentLibraryID, itemName, true)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(item.ID).To(Equal(itemID))
+				Expect(item.Type).To(Equal(library.ItemTypeOVF))
+
+				ovfEnvelope, err := clProvider.RetrieveOvfEnvelopeByLibraryItemID(ctx, itemID)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(ovfEnvelope).ToNot(BeNil())
+			})
+
+			It("Returns an error when the OVF cannot be downloaded", func() {
+				_, _, err := clProvider.ImportLibraryItemFromURL(ctx, ctx.ContentLibraryID, itemName, "",
+					server.URL+"/images/missing.ovf", nil)
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring("404 Not Found"))
+
+				item, err := clProvider.GetLibraryItem(ctx, ctx.ContentLibraryID, itemName, false)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(item).To(BeNil())
+			})
+
+			It("Returns an error when the OVF is too large", func() {
+				_, _, err := clProvider.ImportLibraryItemFromURL(ctx, ctx.ContentLibraryID, itemName, "",
+					server.URL+"/images/large.ovf", nil)
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring("is larger than"))
+			})
+
+			DescribeTable("Returns an error when the OVF references a file outside of its directory",
+				func(href string) {
+					_, _, err := clProvider.ImportLibraryItemFromURL(ctx, ctx.ContentLibraryID, itemName, "",
+						server.URL+"/images/malicious.ovf?href="+url.QueryEscape(href), nil)
+					Expect(err).To(HaveOccurred())
+					Expect(err.Error()).To(ContainSubstring("file reference " + href))
+
+					item, err := clProvider.GetLibraryItem(ctx, ctx.ContentLibraryID, itemName, false)
+					Expect(err).ToNot(HaveOccurred())
+					Expect(item).To(BeNil())
+				},
+				Entry("absolute URL", "http://169.254.169.254/latest/meta-data"),
+				Entry("URL of another host", "//internal.example.com/disk.vmdk"),
+				Entry("absolute path", "/etc/passwd"),
+				Entry("parent directory", "../../secret/disk.vmdk"),
+				Entry("encoded parent directory", "%2e%2e/disk.vmdk"),
+			)
+
+			It("Reports a download failure when a file cannot be pulled", func() {
+				url := server.URL + "/images/ttylinux.ova"
+				server.Close()
+
+				_, sessionID, err := clProvider.ImportLibraryItemFromURL(ctx, ctx.ContentLibraryID, itemName, "", url, nil)
+				Expect(err).ToNot(HaveOccurred())
+
+				status := waitForPhase(sessionID, contentlibrary.ImportPhaseDownloadFailed)
+				Expect(status.Message).ToNot(BeEmpty())
+			})
+		})
+
+		Context("VerifyLibraryItem", func() {
+			var (
+				signingKey *ecdsa.PrivateKey
+				policy     *imagetrust.Policy
+				files      map[string][]byte
+			)
+
+			manifestFor := func(names ...string) []byte {
+				var manifest string
+				for _, name := range names {
+					sum := sha256.Sum256(files[name])
+					manifest += fmt.Sprintf("SHA256(%s)= %s\n", name, hex.EncodeToString(sum[:]))
+				}
+				return []byte(manifest)
+			}
+
+			sign := func(data []byte) []byte {
+				digest := sha256.Sum256(data)
+				sig, err := ecdsa.SignASN1(rand.Reader, signingKey, digest[:])
+				Expect(err).ToNot(HaveOccurred())
+				return []byte(base64.StdEncoding.EncodeToString(sig))
+			}
+
+			createItem := func() string {
+				libMgr := library.NewManager(ctx.RestClient)
+				itemID, err := libMgr.CreateLibraryItem(ctx, library.Item{
+					Name:      "signed-item",
+					Type:      library.ItemTypeOVF,
+					LibraryID: ctx.ContentLibraryID,
+				})
+				Expect(err).ToNot(HaveOccurred())
+
+				sessionID, err := libMgr.CreateLibraryItemUpdateSession(ctx, library.Session{LibraryItemID: itemID})
+				Expect(err).ToNot(HaveOccurred())
+
+				for name, data := range files {
+					update, err := libMgr.AddLibraryItemFile(ctx, sessionID, library.UpdateFile{
+						Name:       name,
+						SourceType: "PUSH",
+						Size:       int64(len(data)),
+					})
+					Expect(err).ToNot(HaveOccurred())
+
+					u, err := url.Parse(update.UploadEndpoint.URI)
+					Expect(err).ToNot(HaveOccurred())
+					p := soap.DefaultUpload
+					p.ContentLength = int64(len(data))
+					Expect(libMgr.Client.Upload(ctx, bytes.NewReader(data), u, &p)).To(Succeed())
+				}
+
+				Expect(libMgr.CompleteLibraryItemUpdateSession(ctx, sessionID)).To(Succeed())
+				return itemID
+			}
+
+			BeforeEach(func() {
+				var err error
+				signingKey, err = ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+				Expect(err).ToNot(HaveOccurred())
+				policy = &imagetrust.Policy{PublicKeys: []crypto.PublicKey{&signingKey.PublicKey}}
+
+				files = map[string][]byte{
+					"photon.ovf":        []byte("dummy-ovf"),
+					"photon-disk1.vmdk": []byte("dummy-disk"),
+				}
+				files["photon.mf"] = manifestFor("photon.ovf", "photon-disk1.vmdk")
+				files["photon.mf.sig"] = sign(files["photon.mf"])
+			})
+
+			It("Verifies a signed library item", func() {
+				itemID := createItem()
+				Expect(clProvider.VerifyLibraryItem(ctx, itemID, policy)).To(Succeed())
+			})
+
+			It("Returns a verification error when the item does not have a manifest", func() {
+				delete(files, "photon.mf")
+				err := clProvider.VerifyLibraryItem(ctx, createItem(), policy)
+				Expect(imagetrust.IsVerificationError(err)).To(BeTrue())
+				Expect(err.Error()).To(ContainSubstring("does not have an OVF manifest"))
+			})
+
+			It("Returns a verification error when the item does not have a signature", func() {
+				delete(files, "photon.mf.sig")
+				err := clProvider.VerifyLibraryItem(ctx, createItem(), policy)
+				Expect(imagetrust.IsVerificationError(err)).To(BeTrue())
+				Expect(err.Error()).To(ContainSubstring("does not have a signature"))
+			})
+
+			It("Returns a verification error when the manifest is signed by an untrusted key", func() {
+				otherKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+				Expect(err).ToNot(HaveOccurred())
+				policy.PublicKeys = []crypto.PublicKey{&otherKey.PublicKey}
+
+				err = clProvider.VerifyLibraryItem(ctx, createItem(), policy)
+				Expect(imagetrust.IsVerificationError(err)).To(BeTrue())
+				Expect(err.Error()).To(ContainSubstring("signature is not valid"))
+			})
+
+			It("Returns a verification error when a file does not match its checksum", func() {
+				files["photon-disk1.vmdk"] = []byte("tampered-disk")
+				err := clProvider.VerifyLibraryItem(ctx, createItem(), policy)
+				Expect(imagetrust.IsVerificationError(err)).To(BeTrue())
+				Expect(err.Error()).To(ContainSubstring("file photon-disk1.vmdk does not match its SHA256 checksum"))
+			})
+
+			It("Returns a verification error when a file is not in the manifest", func() {
+				files["photon.mf"] = manifestFor("photon.ovf")
+				files["photon.mf.sig"] = sign(files["photon.mf"])
+				err := clProvider.VerifyLibraryItem(ctx, createItem(), policy)
+				Expect(imagetrust.IsVerificationError(err)).To(BeTrue())
+				Expect(err.Error()).To(ContainSubstring("file photon-disk1.vmdk is not listed in the OVF manifest"))
+			})
+		})
+
 		Context("when invalid item id is passed", func() {
 			It("returns an error creating a download session", func() {
 				libItem := &library.Item{
@@ -1,4 +1,4 @@
-// Copyright (c) 2019-2022 VMware, Inc. All Rights Reserved.
+// Copyright (c) 2019-2023 VMware, Inc. All Rights Reserved.
 // SPDX-License-Identifier: Apache-2.0
 
 package contentlibrary
@@ -24,6 +24,7 @@ import (
 	vmopv1 "github.com/vmware-tanzu/vm-operator/api/v1alpha1"
 	"github.com/vmware-tanzu/vm-operator/pkg/conditions"
 	"github.com/vmware-tanzu/vm-operator/pkg/lib"
+	"github.com/vmware-tanzu/vm-operator/pkg/vmimage"
 	"github.com/vmware-tanzu/vm-operator/pkg/vmprovider/providers/vsphere/constants"
 )
 
@@ -84,6 +85,7 @@ func LibItemToVirtualMachineImage(
 
 	if item.Type == library.ItemTypeOVF && ovfEnvelope.VirtualSystem != nil {
 		updateImageSpecWithOvfVirtualSystem(&image.Spec, ovfEnvelope.VirtualSystem)
+		vmimage.SetProductLabels(image, image.Spec.ProductInfo)
 
 		ovfSystemProps := getVmwareSystemPropertiesFromOvf(ovfEnvelope.VirtualSystem)
 		for k, v := range ovfSystemProps {
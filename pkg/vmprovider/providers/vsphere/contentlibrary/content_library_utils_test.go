@@ -1,4 +1,4 @@
-// Copyright (c) 2019-2021 VMware, Inc. All Rights Reserved.
+// Copyright (c) 2019-2023 VMware, Inc. All Rights Reserved.
 // SPDX-License-Identifier: Apache-2.0
 
 package contentlibrary_test
@@ -140,6 +140,12 @@ var _ = Describe("LibItemToVirtualMachineImage", func() {
 			Expect(image.Spec.ProductInfo.Version).Should(Equal("version"))
 			Expect(image.Spec.ProductInfo.FullVersion).Should(Equal("fullVersion"))
 
+			Expect(image.Labels).To(Equal(map[string]string{
+				vmopv1.VirtualMachineImageProductLabel: "product",
+				vmopv1.VirtualMachineImageVersionLabel: "version",
+				vmopv1.VirtualMachineImageChannelLabel: "product-version",
+			}))
+
 			Expect(image.Spec.OVFEnv).Should(HaveLen(1))
 			Expect(image.Spec.OVFEnv).Should(HaveKey(userConfigurableKey))
 			Expect(image.Spec.OVFEnv[userConfigurableKey].Key).Should(Equal(userConfigurableKey))
@@ -0,0 +1,198 @@
+// Copyright (c) 2023 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package contentlibrary
+
+import (
+	"context"
+	"io"
+	"path/filepath"
+	"strings"
+
+	"github.com/go-logr/logr"
+	"github.com/pkg/errors"
+	"github.com/vmware/govmomi/vapi/library"
+
+	"github.com/vmware-tanzu/vm-operator/pkg/imagetrust"
+)
+
+const (
+	// maxManifestFileSize is the maximum size of the OVF manifest and signature files that are read into memory.
+	maxManifestFileSize = 1 << 20
+
+	// certificateExtension is the extension of the OVF certificate file, which like the signature file, is not
+	// listed in the OVF manifest.
+	certificateExtension = ".cert"
+)
+
+// VerifyLibraryItem verifies the library item against the image trust policy. The item must have an OVF
+// manifest and a detached signature of the manifest made by one of the policy's public keys, and each file
+// of the item must match its checksum in the manifest. The library's checksum of a file is used when it was
+// computed with the manifest's algorithm, otherwise the file is downloaded to compute its checksum.
+// An imagetrust.VerificationError is returned if the item failed the verification.
+func (cs *provider) VerifyLibraryItem(ctx context.Context, itemID string, policy *imagetrust.Policy) error {
+	logger := log.WithValues("itemID", itemID)
+	logger.V(4).Info("Verifying Library Item")
+
+	files, err := cs.libMgr.ListLibraryItemFiles(ctx, itemID)
+	if err != nil {
+		return errors.Wrapf(err, "failed to list files of library item %s", itemID)
+	}
+
+	var manifestName, signatureName string
+	for _, f := range files {
+		switch strings.ToLower(filepath.Ext(f.Name)) {
+		case imagetrust.ManifestExtension:
+			if manifestName != "" {
+				return imagetrust.NewVerificationError("library item has more than one OVF manifest")
+			}
+			manifestName = f.Name
+		case imagetrust.SignatureExtension:
+			if signatureName != "" {
+				return imagetrust.NewVerificationError("library item has more than one signature")
+			}
+			signatureName = f.Name
+		}
+	}
+	if manifestName == "" {
+		return imagetrust.NewVerificationError("library item does not have an OVF manifest")
+	}
+	if signatureName == "" {
+		return imagetrust.NewVerificationError("library item does not have a signature of its OVF manifest")
+	}
+
+	sessionID, err := cs.libMgr.CreateLibraryItemDownloadSession(ctx, library.Session{LibraryItemID: itemID})
+	if err != nil {
+		return errors.Wrapf(err, "failed to create download session for library item %s", itemID)
+	}
+	logger = logger.WithValues("sessionID", sessionID)
+
+	defer func() {
+		if err := cs.libMgr.DeleteLibraryItemDownloadSession(ctx, sessionID); err != nil {
+			logger.Error(err, "Error deleting download session")
+		}
+	}()
+
+	manifestData, err := cs.readDownloadSessionFile(ctx, logger, sessionID, manifestName)
+	if err != nil {
+		return err
+	}
+	signatureData, err := cs.readDownloadSessionFile(ctx, logger, sessionID, signatureName)
+	if err != nil {
+		return err
+	}
+
+	if err := policy.VerifySignature(manifestData, signatureData); err != nil {
+		return errors.Wrapf(err, "OVF manifest %s", manifestName)
+	}
+
+	manifest, err := imagetrust.ParseManifest(manifestData)
+	if err != nil {
+		return err
+	}
+
+	itemFiles := map[string]struct{}{}
+	for _, f := range files {
+		itemFiles[f.Name] = struct{}{}
+
+		switch strings.ToLower(filepath.Ext(f.Name)) {
+		case imagetrust.ManifestExtension, imagetrust.SignatureExtension, certificateExtension:
+			continue
+		}
+
+		digest, ok := manifest[f.Name]
+		if !ok {
+			return imagetrust.NewVerificationError("file %s is not listed in the OVF manifest", f.Name)
+		}
+
+		if f.Checksum != nil && strings.EqualFold(f.Checksum.Algorithm, digest.Algorithm) {
+			if !digest.Matches(f.Checksum.Algorithm, f.Checksum.Checksum) {
+				return imagetrust.NewVerificationError("file %s does not match its %s checksum in the OVF manifest",
+					f.Name, digest.Algorithm)
+			}
+			continue
+		}
+
+		checksum, err := cs.checksumDownloadSessionFile(ctx, logger, sessionID, f.Name, digest.Algorithm)
+		if err != nil {
+			return err
+		}
+		if !digest.Matches(digest.Algorithm, checksum) {
+			return imagetrust.NewVerificationError("file %s does not match its %s checksum in the OVF manifest",
+				f.Name, digest.Algorithm)
+		}
+	}
+
+	for name := range manifest {
+		if _, ok := itemFiles[name]; !ok {
+			return imagetrust.NewVerificationError("file %s listed in the OVF manifest is not in the library item", name)
+		}
+	}
+
+	logger.V(4).Info("Verified Library Item", "manifest", manifestName, "signature", signatureName)
+	return nil
+}
+
+// readDownloadSessionFile downloads the small file of the download session into memory.
+func (cs *provider) readDownloadSessionFile(
+	ctx context.Context,
+	logger logr.Logger,
+	sessionID, fileName string) ([]byte, error) {
+
+	reader, err := cs.downloadSessionFile(ctx, logger, sessionID, fileName)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = reader.Close()
+	}()
+
+	data, err := io.ReadAll(io.LimitReader(reader, maxManifestFileSize+1))
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to download file %s", fileName)
+	}
+	if len(data) > maxManifestFileSize {
+		return nil, imagetrust.NewVerificationError("file %s is larger than %d bytes", fileName, maxManifestFileSize)
+	}
+
+	return data, nil
+}
+
+// checksumDownloadSessionFile downloads the file of the download session and returns its hex encoded checksum.
+func (cs *provider) checksumDownloadSessionFile(
+	ctx context.Context,
+	logger logr.Logger,
+	sessionID, fileName, algorithm string) (string, error) {
+
+	h, err := imagetrust.NewHash(algorithm)
+	if err != nil {
+		return "", err
+	}
+
+	reader, err := cs.downloadSessionFile(ctx, logger, sessionID, fileName)
+	if err != nil {
+		return "", err
+	}
+	defer func() {
+		_ = reader.Close()
+	}()
+
+	if _, err := io.Copy(h, reader); err != nil {
+		return "", errors.Wrapf(err, "failed to download file %s", fileName)
+	}
+
+	return imagetrust.Sum(h), nil
+}
+
+func (cs *provider) downloadSessionFile(
+	ctx context.Context,
+	logger logr.Logger,
+	sessionID, fileName string) (io.ReadCloser, error) {
+
+	fileURL, err := cs.prepareDownloadSessionFile(ctx, logger, sessionID, fileName)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to prepare file %s for download", fileName)
+	}
+
+	return readerFromURL(ctx, cs.libMgr.Client, fileURL)
+}
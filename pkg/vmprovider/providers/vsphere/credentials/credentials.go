@@ -1,10 +1,11 @@
-// Copyright (c) 2019-2021 VMware, Inc. All Rights Reserved.
+// Copyright (c) 2019-2023 VMware, Inc. All Rights Reserved.
 // SPDX-License-Identifier: Apache-2.0
 
 package credentials
 
 import (
 	"context"
+	"time"
 
 	"github.com/pkg/errors"
 	corev1 "k8s.io/api/core/v1"
@@ -17,6 +18,11 @@ import (
 type VSphereVMProviderCredentials struct {
 	Username string
 	Password string
+
+	// Token is a SAML bearer token. When set, it is used to login instead of the Username and Password.
+	Token string
+	// Expiry is when the credentials expire. It is zero when the credentials do not expire.
+	Expiry time.Time
 }
 
 func GetProviderCredentials(client ctrlruntime.Client, namespace, secretName string) (*VSphereVMProviderCredentials, error) {
@@ -38,6 +44,15 @@ func GetProviderCredentials(client ctrlruntime.Client, namespace, secretName str
 	return &credentials, nil
 }
 
+// RenewAt returns when credentials obtained at now that expire at expiry should be renewed, which is after four
+// fifths of their remaining validity. It is the zero time when expiry is zero.
+func RenewAt(now, expiry time.Time) time.Time {
+	if expiry.IsZero() {
+		return time.Time{}
+	}
+	return now.Add(expiry.Sub(now) * 4 / 5)
+}
+
 func setSecretData(secret *corev1.Secret, credentials *VSphereVMProviderCredentials) {
 	if secret.Data == nil {
 		secret.Data = map[string][]byte{}
@@ -0,0 +1,112 @@
+// Copyright (c) 2023 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package credentials
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/pkg/errors"
+)
+
+const (
+	usernameFileName = "username"
+	passwordFileName = "password"
+)
+
+// FileSource is a Source that reads the username and password from the "username" and "password" files in
+// a directory, such as a mounted Secret volume. The directory is watched so that rotated credentials are
+// noticed without waiting for the next login.
+type FileSource struct {
+	dir string
+}
+
+var _ Notifier = &FileSource{}
+
+// NewFileSource returns a FileSource for the directory.
+func NewFileSource(dir string) *FileSource {
+	return &FileSource{dir: dir}
+}
+
+func (s *FileSource) GetCredentials(_ context.Context) (*VSphereVMProviderCredentials, error) {
+	username, err := s.readFile(usernameFileName)
+	if err != nil {
+		return nil, err
+	}
+
+	password, err := s.readFile(passwordFileName)
+	if err != nil {
+		return nil, err
+	}
+
+	if username == "" || password == "" {
+		return nil, errors.New("vCenter username and password are missing")
+	}
+
+	return &VSphereVMProviderCredentials{
+		Username: username,
+		Password: password,
+	}, nil
+}
+
+func (s *FileSource) readFile(name string) (string, error) {
+	path := filepath.Join(s.dir, name)
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", errors.Wrapf(err, "cannot read provider credentials file %s", path)
+	}
+
+	return strings.TrimSpace(string(data)), nil
+}
+
+// Notify calls fn whenever a file in the directory is created, written, removed or renamed. A mounted
+// Secret volume is updated by atomically swapping a symlink, which is a create in the directory.
+func (s *FileSource) Notify(fn func()) (func(), error) {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create provider credentials watcher")
+	}
+
+	if err := watcher.Add(s.dir); err != nil {
+		_ = watcher.Close()
+		return nil, errors.Wrapf(err, "failed to watch provider credentials directory %s", s.dir)
+	}
+
+	done := make(chan struct{})
+	go func() {
+		for {
+			select {
+			case event, ok := <-watcher.Events:
+				if !ok {
+					return
+				}
+				if event.Op&(fsnotify.Create|fsnotify.Write|fsnotify.Remove|fsnotify.Rename) != 0 {
+					log.V(4).Info("Provider credentials file changed", "file", event.Name, "op", event.Op.String())
+					fn()
+				}
+			case err, ok := <-watcher.Errors:
+				if !ok {
+					return
+				}
+				log.Error(err, "Error watching provider credentials directory", "dir", s.dir)
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	var once sync.Once
+	stop := func() {
+		once.Do(func() {
+			close(done)
+			_ = watcher.Close()
+		})
+	}
+
+	return stop, nil
+}
@@ -0,0 +1,59 @@
+// Copyright (c) 2023 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package credentials
+
+import (
+	"context"
+
+	ctrlruntime "sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+var log = logf.Log.WithName("vsphere").WithName("credentials")
+
+// Source is a source of the credentials that are used to login to vCenter.
+type Source interface {
+	// GetCredentials returns the current credentials.
+	GetCredentials(ctx context.Context) (*VSphereVMProviderCredentials, error)
+}
+
+// Notifier is implemented by the Sources that detect when their credentials change.
+type Notifier interface {
+	// Notify calls fn whenever the credentials change until the returned stop function is called.
+	Notify(fn func()) (stop func(), err error)
+}
+
+type staticSource struct {
+	credentials *VSphereVMProviderCredentials
+}
+
+// NewStaticSource returns a Source that always returns the same credentials.
+func NewStaticSource(credentials *VSphereVMProviderCredentials) Source {
+	return staticSource{credentials: credentials}
+}
+
+func (s staticSource) GetCredentials(_ context.Context) (*VSphereVMProviderCredentials, error) {
+	c := *s.credentials
+	return &c, nil
+}
+
+type secretSource struct {
+	client     ctrlruntime.Client
+	namespace  string
+	secretName string
+}
+
+// NewSecretSource returns a Source that reads the username and password from a Secret. The Secret is read
+// every time the credentials are requested, so changes to it are picked up by the next login.
+func NewSecretSource(client ctrlruntime.Client, namespace, secretName string) Source {
+	return secretSource{
+		client:     client,
+		namespace:  namespace,
+		secretName: secretName,
+	}
+}
+
+func (s secretSource) GetCredentials(_ context.Context) (*VSphereVMProviderCredentials, error) {
+	return GetProviderCredentials(s.client, s.namespace, s.secretName)
+}
@@ -0,0 +1,137 @@
+// Copyright (c) 2023 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package credentials_test
+
+import (
+	"os"
+	"path/filepath"
+	"sync/atomic"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	. "github.com/vmware-tanzu/vm-operator/pkg/vmprovider/providers/vsphere/credentials"
+	"github.com/vmware-tanzu/vm-operator/test/builder"
+)
+
+var _ = Describe("NewStaticSource", func() {
+
+	It("returns a copy of the credentials", func() {
+		credsIn := &VSphereVMProviderCredentials{Username: "some-user", Password: "some-pass"}
+		credsOut, err := NewStaticSource(credsIn).GetCredentials(ctx)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(credsOut).To(Equal(credsIn))
+		Expect(credsOut).ToNot(BeIdenticalTo(credsIn))
+	})
+})
+
+var _ = Describe("NewSecretSource", func() {
+
+	It("returns the credentials of the Secret", func() {
+		secretIn, credsIn := newSecret("some-name", "some-namespace", "some-user", "some-pass")
+		client := builder.NewFakeClient(secretIn)
+
+		credsOut, err := NewSecretSource(client, secretIn.Namespace, secretIn.Name).GetCredentials(ctx)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(credsOut).To(Equal(credsIn))
+	})
+
+	It("returns the updated credentials when the Secret changes", func() {
+		secretIn, _ := newSecret("some-name", "some-namespace", "some-user", "some-pass")
+		client := builder.NewFakeClient(secretIn)
+		source := NewSecretSource(client, secretIn.Namespace, secretIn.Name)
+
+		secretIn.Data["password"] = []byte("new-pass")
+		Expect(client.Update(ctx, secretIn)).To(Succeed())
+
+		credsOut, err := source.GetCredentials(ctx)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(credsOut.Password).To(Equal("new-pass"))
+	})
+
+	It("returns an error when the Secret does not exist", func() {
+		client := builder.NewFakeClient()
+		_, err := NewSecretSource(client, "none-namespace", "none-name").GetCredentials(ctx)
+		Expect(err).To(HaveOccurred())
+	})
+})
+
+var _ = Describe("FileSource", func() {
+
+	var (
+		dir    string
+		source *FileSource
+	)
+
+	writeCreds := func(username, password string) {
+		ExpectWithOffset(1, os.WriteFile(filepath.Join(dir, "username"), []byte(username+"\n"), 0600)).To(Succeed())
+		ExpectWithOffset(1, os.WriteFile(filepath.Join(dir, "password"), []byte(password+"\n"), 0600)).To(Succeed())
+	}
+
+	BeforeEach(func() {
+		var err error
+		dir, err = os.MkdirTemp("", "creds-")
+		Expect(err).ToNot(HaveOccurred())
+		source = NewFileSource(dir)
+	})
+
+	AfterEach(func() {
+		Expect(os.RemoveAll(dir)).To(Succeed())
+	})
+
+	It("returns the credentials of the files", func() {
+		writeCreds("some-user", "some-pass")
+
+		credsOut, err := source.GetCredentials(ctx)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(credsOut).To(Equal(&VSphereVMProviderCredentials{Username: "some-user", Password: "some-pass"}))
+	})
+
+	It("returns an error when the files do not exist", func() {
+		_, err := source.GetCredentials(ctx)
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("cannot read provider credentials file"))
+	})
+
+	It("returns an error when the password is empty", func() {
+		writeCreds("some-user", "")
+
+		_, err := source.GetCredentials(ctx)
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(Equal("vCenter username and password are missing"))
+	})
+
+	It("notifies when the credentials are rotated", func() {
+		writeCreds("some-user", "some-pass")
+
+		var notified int32
+		stop, err := source.Notify(func() { atomic.AddInt32(&notified, 1) })
+		Expect(err).ToNot(HaveOccurred())
+		defer stop()
+
+		writeCreds("some-user", "new-pass")
+		Eventually(func() int32 { return atomic.LoadInt32(&notified) }).Should(BeNumerically(">", 0))
+
+		credsOut, err := source.GetCredentials(ctx)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(credsOut.Password).To(Equal("new-pass"))
+	})
+
+	It("stops notifying when stopped", func() {
+		var notified int32
+		stop, err := source.Notify(func() { atomic.AddInt32(&notified, 1) })
+		Expect(err).ToNot(HaveOccurred())
+		stop()
+		stop()
+
+		writeCreds("some-user", "some-pass")
+		Consistently(func() int32 { return atomic.LoadInt32(&notified) }, "200ms").Should(BeZero())
+	})
+
+	It("returns an error when the directory does not exist", func() {
+		_, err := NewFileSource(filepath.Join(dir, "does-not-exist")).Notify(func() {})
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("failed to watch provider credentials directory"))
+	})
+})
@@ -0,0 +1,132 @@
+// Copyright (c) 2023 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package credentials
+
+import (
+	"context"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/vmware/govmomi/sts"
+	"github.com/vmware/govmomi/vim25/soap"
+)
+
+// DefaultTokenLifetime is the lifetime of the tokens requested from the STS.
+const DefaultTokenLifetime = time.Hour
+
+// TokenExchangeSource is a Source that exchanges the username and password of another Source for a SAML
+// bearer token issued by the vCenter Security Token Service (STS). The token is cached until four fifths of
+// its lifetime have passed.
+type TokenExchangeSource struct {
+	// TokenLifetime is the lifetime of the tokens requested from the STS.
+	TokenLifetime time.Duration
+
+	base                  Source
+	stsURL                *url.URL
+	insecureSkipTLSVerify bool
+	caFilePath            string
+
+	mu          sync.Mutex
+	credentials *VSphereVMProviderCredentials
+	renewAt     time.Time
+}
+
+var _ Notifier = &TokenExchangeSource{}
+
+// NewTokenExchangeSource returns a TokenExchangeSource that requests tokens from the STS endpoint at stsURL
+// with the credentials of base.
+func NewTokenExchangeSource(
+	base Source,
+	stsURL *url.URL,
+	insecureSkipTLSVerify bool,
+	caFilePath string) *TokenExchangeSource {
+
+	return &TokenExchangeSource{
+		TokenLifetime:         DefaultTokenLifetime,
+		base:                  base,
+		stsURL:                stsURL,
+		insecureSkipTLSVerify: insecureSkipTLSVerify,
+		caFilePath:            caFilePath,
+	}
+}
+
+func (s *TokenExchangeSource) GetCredentials(ctx context.Context) (*VSphereVMProviderCredentials, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.credentials == nil || !time.Now().Before(s.renewAt) {
+		credentials, err := s.issueToken(ctx)
+		if err != nil {
+			return nil, err
+		}
+		s.credentials = credentials
+		s.renewAt = RenewAt(time.Now(), credentials.Expiry)
+	}
+
+	c := *s.credentials
+	return &c, nil
+}
+
+func (s *TokenExchangeSource) issueToken(ctx context.Context) (*VSphereVMProviderCredentials, error) {
+	baseCreds, err := s.base.GetCredentials(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if baseCreds.Username == "" || baseCreds.Password == "" {
+		return nil, errors.New("vCenter username and password are required to request a token")
+	}
+
+	stsClient, err := s.newSTSClient()
+	if err != nil {
+		return nil, err
+	}
+
+	signer, err := stsClient.Issue(ctx, sts.TokenRequest{
+		Userinfo: url.UserPassword(baseCreds.Username, baseCreds.Password),
+		Lifetime: s.TokenLifetime,
+	})
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to issue token from STS %s", s.stsURL)
+	}
+
+	log.V(4).Info("Issued token from STS", "url", s.stsURL.String(), "username", baseCreds.Username,
+		"expires", signer.Lifetime.Expires)
+
+	return &VSphereVMProviderCredentials{
+		Username: baseCreds.Username,
+		Token:    signer.Token,
+		Expiry:   signer.Lifetime.Expires,
+	}, nil
+}
+
+func (s *TokenExchangeSource) newSTSClient() (*sts.Client, error) {
+	sc := soap.NewClient(s.stsURL, s.insecureSkipTLSVerify)
+	if s.caFilePath != "" {
+		if err := sc.SetRootCAs(s.caFilePath); err != nil {
+			return nil, errors.Wrapf(err, "failed to set root CA %s", s.caFilePath)
+		}
+	}
+
+	sc = sc.NewServiceClient(s.stsURL.Path, sts.Namespace)
+	return &sts.Client{Client: sc, RoundTripper: sc}, nil
+}
+
+// Notify forwards to the base Source when it is a Notifier. The cached token is discarded when the base
+// credentials change.
+func (s *TokenExchangeSource) Notify(fn func()) (func(), error) {
+	notifier, ok := s.base.(Notifier)
+	if !ok {
+		return func() {}, nil
+	}
+
+	return notifier.Notify(func() {
+		s.mu.Lock()
+		s.credentials = nil
+		s.mu.Unlock()
+		fn()
+	})
+}
@@ -0,0 +1,91 @@
+// Copyright (c) 2023 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package credentials_test
+
+import (
+	"net/url"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/vmware/govmomi/sts"
+
+	// Registers the STS endpoint of the simulator.
+	_ "github.com/vmware/govmomi/sts/simulator"
+
+	. "github.com/vmware-tanzu/vm-operator/pkg/vmprovider/providers/vsphere/credentials"
+)
+
+var _ = Describe("TokenExchangeSource", func() {
+
+	var (
+		stsURL *url.URL
+		base   Source
+	)
+
+	BeforeEach(func() {
+		stsURL = &url.URL{
+			Scheme: server.URL.Scheme,
+			Host:   server.URL.Host,
+			Path:   sts.Path + "/vsphere.local",
+		}
+		base = NewStaticSource(&VSphereVMProviderCredentials{Username: "some-user", Password: "some-pass"})
+	})
+
+	It("exchanges the username and password for a token", func() {
+		source := NewTokenExchangeSource(base, stsURL, true, "")
+
+		credsOut, err := source.GetCredentials(ctx)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(credsOut.Username).To(Equal("some-user"))
+		Expect(credsOut.Password).To(BeEmpty())
+		Expect(credsOut.Token).To(ContainSubstring("saml2:Assertion"))
+		Expect(credsOut.Expiry).To(BeTemporally(">", time.Now()))
+	})
+
+	It("returns the cached token until it should be renewed", func() {
+		source := NewTokenExchangeSource(base, stsURL, true, "")
+
+		credsOut1, err := source.GetCredentials(ctx)
+		Expect(err).ToNot(HaveOccurred())
+
+		time.Sleep(10 * time.Millisecond)
+
+		credsOut2, err := source.GetCredentials(ctx)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(credsOut2.Expiry).To(Equal(credsOut1.Expiry))
+	})
+
+	It("returns an error when the base credentials have no password", func() {
+		base = NewStaticSource(&VSphereVMProviderCredentials{Username: "some-user"})
+		_, err := NewTokenExchangeSource(base, stsURL, true, "").GetCredentials(ctx)
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("vCenter username and password are required"))
+	})
+
+	It("returns an error when the STS is not available", func() {
+		stsURL.Path = "/does-not-exist"
+		_, err := NewTokenExchangeSource(base, stsURL, true, "").GetCredentials(ctx)
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("failed to issue token from STS"))
+	})
+
+	It("does not notify when the base source is not a Notifier", func() {
+		stop, err := NewTokenExchangeSource(base, stsURL, true, "").Notify(func() {})
+		Expect(err).ToNot(HaveOccurred())
+		stop()
+	})
+})
+
+var _ = Describe("RenewAt", func() {
+
+	It("returns the zero time when the credentials do not expire", func() {
+		Expect(RenewAt(time.Now(), time.Time{})).To(BeZero())
+	})
+
+	It("returns when four fifths of the validity have passed", func() {
+		now := time.Now()
+		Expect(RenewAt(now, now.Add(5*time.Minute))).To(Equal(now.Add(4 * time.Minute)))
+	})
+})
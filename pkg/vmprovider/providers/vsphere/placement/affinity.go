@@ -0,0 +1,438 @@
+// Copyright (c) 2023 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package placement
+
+import (
+	"fmt"
+	"math/rand"
+
+	"github.com/pkg/errors"
+	"github.com/vmware/govmomi/object"
+	"github.com/vmware/govmomi/vim25/types"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	ctrlclient "sigs.k8s.io/controller-runtime/pkg/client"
+
+	vmopv1 "github.com/vmware-tanzu/vm-operator/api/v1alpha1"
+	"github.com/vmware-tanzu/vm-operator/pkg/context"
+	"github.com/vmware-tanzu/vm-operator/pkg/topology"
+)
+
+// AffinityPeer is another VM in the namespace of the VM being placed that the
+// VM's affinity terms are evaluated over.
+type AffinityPeer struct {
+	Labels map[string]string
+	// Zone is the zone the VM is placed in, if any.
+	Zone string
+	// Host is the name of the host the VM is running on, if any.
+	Host string
+}
+
+// AffinityCandidate is a placement recommendation that is evaluated against
+// the affinity terms of the VM being placed.
+type AffinityCandidate struct {
+	ZoneName       string
+	HostName       string
+	Recommendation Recommendation
+}
+
+// HasAffinity returns true if the VM has any affinity or anti-affinity terms.
+func HasAffinity(vm *vmopv1.VirtualMachine) bool {
+	return len(affinityTerms(vm.Spec.Affinity)) > 0
+}
+
+// HasHostAffinity returns true if the VM has any affinity or anti-affinity
+// terms with the host topology key.
+func HasHostAffinity(vm *vmopv1.VirtualMachine) bool {
+	for _, term := range affinityTerms(vm.Spec.Affinity) {
+		if term.TopologyKey == vmopv1.VirtualMachineAffinityHostTopologyKey {
+			return true
+		}
+	}
+	return false
+}
+
+func affinityTerms(spec *vmopv1.VirtualMachineAffinitySpec) []vmopv1.VirtualMachineAffinityTerm {
+	if spec == nil {
+		return nil
+	}
+
+	var terms []vmopv1.VirtualMachineAffinityTerm
+	for _, rules := range []*vmopv1.VirtualMachineAffinityRules{spec.VMAffinity, spec.VMAntiAffinity} {
+		if rules == nil {
+			continue
+		}
+		terms = append(terms, rules.RequiredDuringSchedulingIgnoredDuringExecution...)
+		terms = append(terms, rules.RequiredDuringSchedulingRequiredDuringExecution...)
+		for _, wt := range rules.PreferredDuringSchedulingIgnoredDuringExecution {
+			terms = append(terms, wt.AffinityTerm)
+		}
+	}
+	return terms
+}
+
+func requiredTerms(rules *vmopv1.VirtualMachineAffinityRules) []vmopv1.VirtualMachineAffinityTerm {
+	if rules == nil {
+		return nil
+	}
+	terms := make([]vmopv1.VirtualMachineAffinityTerm, 0,
+		len(rules.RequiredDuringSchedulingIgnoredDuringExecution)+len(rules.RequiredDuringSchedulingRequiredDuringExecution))
+	terms = append(terms, rules.RequiredDuringSchedulingIgnoredDuringExecution...)
+	return append(terms, rules.RequiredDuringSchedulingRequiredDuringExecution...)
+}
+
+func preferredTerms(rules *vmopv1.VirtualMachineAffinityRules) []vmopv1.VirtualMachineWeightedAffinityTerm {
+	if rules == nil {
+		return nil
+	}
+	return rules.PreferredDuringSchedulingIgnoredDuringExecution
+}
+
+// AffinityTermSelector returns the selector of the term. A nil or empty label
+// selector does not select any VMs.
+func AffinityTermSelector(term vmopv1.VirtualMachineAffinityTerm) (labels.Selector, error) {
+	return labelSelectorAsSelector(term.LabelSelector)
+}
+
+// labelSelectorAsSelector returns the selector of the label selector. Unlike
+// metav1.LabelSelectorAsSelector, a nil or empty label selector does not
+// select anything.
+func labelSelectorAsSelector(ls *metav1.LabelSelector) (labels.Selector, error) {
+	if ls == nil || (len(ls.MatchLabels) == 0 && len(ls.MatchExpressions) == 0) {
+		return labels.Nothing(), nil
+	}
+	return metav1.LabelSelectorAsSelector(ls)
+}
+
+// affinityDomain returns the topology domain of the term that the peer or
+// candidate is in.
+func affinityDomain(topologyKey, zone, host string) string {
+	if topologyKey == vmopv1.VirtualMachineAffinityHostTopologyKey {
+		return host
+	}
+	return zone
+}
+
+// termMatches returns the number of peers selected by the term in total, and
+// in the topology domain of the candidate.
+func termMatches(
+	term vmopv1.VirtualMachineAffinityTerm,
+	peers []AffinityPeer,
+	candidate AffinityCandidate) (int, int, error) {
+
+	selector, err := AffinityTermSelector(term)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	domain := affinityDomain(term.TopologyKey, candidate.ZoneName, candidate.HostName)
+
+	var total, inDomain int
+	for _, peer := range peers {
+		if !selector.Matches(labels.Set(peer.Labels)) {
+			continue
+		}
+		peerDomain := affinityDomain(term.TopologyKey, peer.Zone, peer.Host)
+		if peerDomain == "" {
+			// The peer is not placed yet.
+			continue
+		}
+		total++
+		if peerDomain == domain {
+			inDomain++
+		}
+	}
+
+	return total, inDomain, nil
+}
+
+// scoreAffinityCandidate returns if the candidate satisfies the required terms
+// of the affinity spec, and the sum of the weights of the satisfied preferred
+// terms minus those of the unsatisfied preferred anti-affinity terms.
+func scoreAffinityCandidate(
+	spec *vmopv1.VirtualMachineAffinitySpec,
+	peers []AffinityPeer,
+	candidate AffinityCandidate) (bool, int64, error) {
+
+	for _, term := range requiredTerms(spec.VMAffinity) {
+		total, inDomain, err := termMatches(term, peers, candidate)
+		if err != nil {
+			return false, 0, err
+		}
+		// Like Pod affinity, a term that does not select any VMs is satisfied
+		// so that the first VM of a group can be placed.
+		if total > 0 && inDomain == 0 {
+			return false, 0, nil
+		}
+	}
+
+	for _, term := range requiredTerms(spec.VMAntiAffinity) {
+		_, inDomain, err := termMatches(term, peers, candidate)
+		if err != nil {
+			return false, 0, err
+		}
+		if inDomain > 0 {
+			return false, 0, nil
+		}
+	}
+
+	var score int64
+	for _, wt := range preferredTerms(spec.VMAffinity) {
+		_, inDomain, err := termMatches(wt.AffinityTerm, peers, candidate)
+		if err != nil {
+			return false, 0, err
+		}
+		if inDomain > 0 {
+			score += int64(wt.Weight)
+		}
+	}
+	for _, wt := range preferredTerms(spec.VMAntiAffinity) {
+		_, inDomain, err := termMatches(wt.AffinityTerm, peers, candidate)
+		if err != nil {
+			return false, 0, err
+		}
+		if inDomain > 0 {
+			score -= int64(wt.Weight)
+		}
+	}
+
+	return true, score, nil
+}
+
+// MakeAffinityPlacementDecision selects the candidate that satisfies the
+// required terms of the affinity spec with the highest score of its preferred
+// terms. Ties are broken randomly. An error is returned if no candidate
+// satisfies the required terms.
+func MakeAffinityPlacementDecision(
+	spec *vmopv1.VirtualMachineAffinitySpec,
+	peers []AffinityPeer,
+	candidates []AffinityCandidate) (AffinityCandidate, error) {
+
+	best, err := bestAffinityCandidates(spec, peers, candidates)
+	if err != nil {
+		return AffinityCandidate{}, err
+	}
+
+	return best[rand.Intn(len(best))], nil //nolint:gosec
+}
+
+// bestAffinityCandidates returns the candidates that satisfy the required
+// terms of the affinity spec with the highest score of its preferred terms.
+func bestAffinityCandidates(
+	spec *vmopv1.VirtualMachineAffinitySpec,
+	peers []AffinityPeer,
+	candidates []AffinityCandidate) ([]AffinityCandidate, error) {
+
+	var best []AffinityCandidate
+	var bestScore int64
+
+	for _, candidate := range candidates {
+		ok, score, err := scoreAffinityCandidate(spec, peers, candidate)
+		if err != nil {
+			return nil, errors.Wrap(err, "invalid affinity term")
+		}
+		if !ok {
+			continue
+		}
+
+		switch {
+		case len(best) == 0 || score > bestScore:
+			best = []AffinityCandidate{candidate}
+			bestScore = score
+		case score == bestScore:
+			best = append(best, candidate)
+		}
+	}
+
+	if len(best) == 0 {
+		return nil, fmt.Errorf("no placement recommendations satisfy the VM affinity rules")
+	}
+
+	return best, nil
+}
+
+// filterAffinityZoneCandidates returns the candidates in the zones that satisfy
+// the required affinity and anti-affinity terms of the VM with the zone
+// topology key.
+func filterAffinityZoneCandidates(
+	vmCtx context.VirtualMachineContext,
+	peers []AffinityPeer,
+	candidates map[string][]string) (map[string][]string, error) {
+
+	zoneTerms := func(rules *vmopv1.VirtualMachineAffinityRules) *vmopv1.VirtualMachineAffinityRules {
+		var terms []vmopv1.VirtualMachineAffinityTerm
+		for _, term := range requiredTerms(rules) {
+			if term.TopologyKey == vmopv1.VirtualMachineAffinityZoneTopologyKey {
+				terms = append(terms, term)
+			}
+		}
+		return &vmopv1.VirtualMachineAffinityRules{RequiredDuringSchedulingIgnoredDuringExecution: terms}
+	}
+
+	spec := &vmopv1.VirtualMachineAffinitySpec{
+		VMAffinity:     zoneTerms(vmCtx.VM.Spec.Affinity.VMAffinity),
+		VMAntiAffinity: zoneTerms(vmCtx.VM.Spec.Affinity.VMAntiAffinity),
+	}
+
+	filtered := map[string][]string{}
+	for zoneName, rpMoIDs := range candidates {
+		ok, _, err := scoreAffinityCandidate(spec, peers, AffinityCandidate{ZoneName: zoneName})
+		if err != nil {
+			return nil, errors.Wrap(err, "invalid affinity term")
+		}
+		if ok {
+			filtered[zoneName] = rpMoIDs
+		}
+	}
+
+	if len(filtered) == 0 {
+		return nil, fmt.Errorf("no placement recommendations satisfy the VM affinity rules")
+	}
+
+	return filtered, nil
+}
+
+// getAffinityPeers returns the other VMs in the namespace of the VM that the
+// VM's affinity terms and topology spread constraints are evaluated over.
+func getAffinityPeers(
+	vmCtx context.VirtualMachineContext,
+	client ctrlclient.Client) ([]AffinityPeer, error) {
+
+	vmList := &vmopv1.VirtualMachineList{}
+	if err := client.List(vmCtx, vmList, ctrlclient.InNamespace(vmCtx.VM.Namespace)); err != nil {
+		return nil, errors.Wrapf(err, "failed to list VirtualMachines in namespace %s", vmCtx.VM.Namespace)
+	}
+
+	peers := make([]AffinityPeer, 0, len(vmList.Items))
+	for _, vm := range vmList.Items {
+		if vm.Name == vmCtx.VM.Name || !vm.DeletionTimestamp.IsZero() {
+			continue
+		}
+		peers = append(peers, AffinityPeer{
+			Labels: vm.Labels,
+			Zone:   vm.Labels[topology.KubernetesTopologyZoneLabelKey],
+			Host:   vm.Status.Host,
+		})
+	}
+
+	return peers, nil
+}
+
+// getAffinityCandidates returns the recommendations as candidates, with the
+// name of the host of each recommendation when the VM has host affinity terms.
+func getAffinityCandidates(
+	vmCtx context.VirtualMachineContext,
+	zoneClients ZoneClients,
+	recommendations map[string][]Recommendation) ([]AffinityCandidate, error) {
+
+	needsHostName := HasHostAffinity(vmCtx.VM)
+	hostNames := map[string]string{}
+
+	var candidates []AffinityCandidate
+	for zoneName, recs := range recommendations {
+		for _, rec := range recs {
+			candidate := AffinityCandidate{ZoneName: zoneName, Recommendation: rec}
+
+			if needsHostName && rec.HostMoRef != nil {
+				// The host MoIDs are only unique within the vCenter of the zone.
+				key := zoneName + "/" + rec.HostMoRef.Value
+				hostName, ok := hostNames[key]
+				if !ok {
+					name, err := object.NewHostSystem(zoneClients[zoneName], *rec.HostMoRef).ObjectName(vmCtx)
+					if err != nil {
+						return nil, errors.Wrapf(err, "failed to get name of host %s", rec.HostMoRef.Value)
+					}
+					hostName = name
+					hostNames[key] = hostName
+				}
+				candidate.HostName = hostName
+			}
+
+			candidates = append(candidates, candidate)
+		}
+	}
+
+	return candidates, nil
+}
+
+// filterAffinityRecommendations returns the recommendations that satisfy the
+// required affinity terms of the VM with the highest score of its preferred
+// terms, so the placement scorers only select among them.
+func filterAffinityRecommendations(
+	vmCtx context.VirtualMachineContext,
+	zoneClients ZoneClients,
+	peers []AffinityPeer,
+	recommendations map[string][]Recommendation) (map[string][]Recommendation, error) {
+
+	candidates, err := getAffinityCandidates(vmCtx, zoneClients, recommendations)
+	if err != nil {
+		return nil, err
+	}
+
+	best, err := bestAffinityCandidates(vmCtx.VM.Spec.Affinity, peers, candidates)
+	if err != nil {
+		return nil, err
+	}
+
+	filtered := map[string][]Recommendation{}
+	for _, candidate := range best {
+		filtered[candidate.ZoneName] = append(filtered[candidate.ZoneName], candidate.Recommendation)
+	}
+
+	return filtered, nil
+}
+
+// affinityHostFilter limits the hosts that DRS may place a VM on to those
+// that satisfy the VM's required affinity terms.
+type affinityHostFilter struct {
+	zoneClients ZoneClients
+	spec        *vmopv1.VirtualMachineAffinitySpec
+	peers       []AffinityPeer
+}
+
+// filterHosts returns the hosts in the zone that satisfy the required affinity
+// terms.
+func (f *affinityHostFilter) filterHosts(
+	vmCtx context.VirtualMachineContext,
+	zoneName string,
+	hosts []types.ManagedObjectReference) ([]types.ManagedObjectReference, error) {
+
+	var allowed []types.ManagedObjectReference
+	for _, host := range hosts {
+		hostName, err := object.NewHostSystem(f.zoneClients[zoneName], host).ObjectName(vmCtx)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to get name of host %s", host.Value)
+		}
+
+		ok, _, err := scoreAffinityCandidate(f.spec, f.peers, AffinityCandidate{ZoneName: zoneName, HostName: hostName})
+		if err != nil {
+			return nil, errors.Wrap(err, "invalid affinity term")
+		}
+		if ok {
+			allowed = append(allowed, host)
+		}
+	}
+
+	return allowed, nil
+}
+
+// AffinityRuleNamePrefix returns the prefix of the names of the DRS VM/host
+// rules and groups that realize the VM's affinity terms that are required
+// during execution.
+func AffinityRuleNamePrefix(vm *vmopv1.VirtualMachine) string {
+	// Namespace and VM names cannot contain a '/' or ':' so the prefix of one
+	// VM is never a prefix of another VM.
+	return fmt.Sprintf("vmoperator:%s/%s:", vm.Namespace, vm.Name)
+}
+
+// HasRequiredDuringExecutionAffinity returns true if the VM has any affinity
+// or anti-affinity terms that are required during execution.
+func HasRequiredDuringExecutionAffinity(vm *vmopv1.VirtualMachine) bool {
+	spec := vm.Spec.Affinity
+	if spec == nil {
+		return false
+	}
+	return (spec.VMAffinity != nil && len(spec.VMAffinity.RequiredDuringSchedulingRequiredDuringExecution) > 0) ||
+		(spec.VMAntiAffinity != nil && len(spec.VMAntiAffinity.RequiredDuringSchedulingRequiredDuringExecution) > 0)
+}
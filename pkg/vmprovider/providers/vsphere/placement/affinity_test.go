@@ -0,0 +1,173 @@
+// Copyright (c) 2023 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package placement_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/vmware/govmomi/vim25/types"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	vmopv1 "github.com/vmware-tanzu/vm-operator/api/v1alpha1"
+	"github.com/vmware-tanzu/vm-operator/pkg/vmprovider/providers/vsphere/placement"
+)
+
+var _ = Describe("MakeAffinityPlacementDecision", func() {
+
+	var (
+		spec       *vmopv1.VirtualMachineAffinitySpec
+		peers      []placement.AffinityPeer
+		candidates []placement.AffinityCandidate
+	)
+
+	dbTerm := func(topologyKey string) vmopv1.VirtualMachineAffinityTerm {
+		return vmopv1.VirtualMachineAffinityTerm{
+			LabelSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "db"}},
+			TopologyKey:   topologyKey,
+		}
+	}
+
+	candidate := func(zoneName, hostName string) placement.AffinityCandidate {
+		return placement.AffinityCandidate{
+			ZoneName: zoneName,
+			HostName: hostName,
+			Recommendation: placement.Recommendation{
+				PoolMoRef: types.ManagedObjectReference{Type: "ResourcePool", Value: zoneName + "-rp"},
+				HostMoRef: &types.ManagedObjectReference{Type: "HostSystem", Value: hostName + "-moid"},
+			},
+		}
+	}
+
+	BeforeEach(func() {
+		spec = &vmopv1.VirtualMachineAffinitySpec{}
+		peers = []placement.AffinityPeer{
+			{Labels: map[string]string{"app": "db"}, Zone: "zone1", Host: "host1"},
+			{Labels: map[string]string{"app": "web"}, Zone: "zone2", Host: "host3"},
+		}
+		candidates = []placement.AffinityCandidate{
+			candidate("zone1", "host1"),
+			candidate("zone1", "host2"),
+			candidate("zone2", "host3"),
+		}
+	})
+
+	Context("required zone anti-affinity", func() {
+		BeforeEach(func() {
+			spec.VMAntiAffinity = &vmopv1.VirtualMachineAffinityRules{
+				RequiredDuringSchedulingIgnoredDuringExecution: []vmopv1.VirtualMachineAffinityTerm{
+					dbTerm(vmopv1.VirtualMachineAffinityZoneTopologyKey),
+				},
+			}
+		})
+
+		It("selects a zone without the selected VMs", func() {
+			c, err := placement.MakeAffinityPlacementDecision(spec, peers, candidates)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(c.ZoneName).To(Equal("zone2"))
+		})
+
+		It("returns an error when every zone has a selected VM", func() {
+			peers = append(peers, placement.AffinityPeer{Labels: map[string]string{"app": "db"}, Zone: "zone2"})
+			_, err := placement.MakeAffinityPlacementDecision(spec, peers, candidates)
+			Expect(err).To(MatchError("no placement recommendations satisfy the VM affinity rules"))
+		})
+	})
+
+	Context("required host affinity", func() {
+		BeforeEach(func() {
+			spec.VMAffinity = &vmopv1.VirtualMachineAffinityRules{
+				RequiredDuringSchedulingRequiredDuringExecution: []vmopv1.VirtualMachineAffinityTerm{
+					dbTerm(vmopv1.VirtualMachineAffinityHostTopologyKey),
+				},
+			}
+		})
+
+		It("selects the host of the selected VMs", func() {
+			c, err := placement.MakeAffinityPlacementDecision(spec, peers, candidates)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(c.HostName).To(Equal("host1"))
+			Expect(c.Recommendation.HostMoRef.Value).To(Equal("host1-moid"))
+		})
+
+		It("is satisfied by any candidate when no VMs are selected", func() {
+			peers = peers[1:]
+			c, err := placement.MakeAffinityPlacementDecision(spec, peers, candidates)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(candidates).To(ContainElement(c))
+		})
+
+		It("ignores selected VMs that are not placed yet", func() {
+			peers[0].Host = ""
+			c, err := placement.MakeAffinityPlacementDecision(spec, peers, candidates)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(candidates).To(ContainElement(c))
+		})
+	})
+
+	Context("preferred terms", func() {
+		BeforeEach(func() {
+			spec.VMAffinity = &vmopv1.VirtualMachineAffinityRules{
+				PreferredDuringSchedulingIgnoredDuringExecution: []vmopv1.VirtualMachineWeightedAffinityTerm{
+					{Weight: 10, AffinityTerm: dbTerm(vmopv1.VirtualMachineAffinityZoneTopologyKey)},
+				},
+			}
+			spec.VMAntiAffinity = &vmopv1.VirtualMachineAffinityRules{
+				PreferredDuringSchedulingIgnoredDuringExecution: []vmopv1.VirtualMachineWeightedAffinityTerm{
+					{Weight: 5, AffinityTerm: dbTerm(vmopv1.VirtualMachineAffinityHostTopologyKey)},
+				},
+			}
+		})
+
+		It("selects the candidate with the highest score", func() {
+			// zone1/host1 scores 10-5, zone1/host2 scores 10, and zone2/host3 scores 0.
+			c, err := placement.MakeAffinityPlacementDecision(spec, peers, candidates)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(c.ZoneName).To(Equal("zone1"))
+			Expect(c.HostName).To(Equal("host2"))
+		})
+	})
+
+	It("returns an error for an invalid label selector", func() {
+		spec.VMAffinity = &vmopv1.VirtualMachineAffinityRules{
+			RequiredDuringSchedulingIgnoredDuringExecution: []vmopv1.VirtualMachineAffinityTerm{
+				{
+					LabelSelector: &metav1.LabelSelector{
+						MatchExpressions: []metav1.LabelSelectorRequirement{{Key: "app", Operator: "Foo"}},
+					},
+					TopologyKey: vmopv1.VirtualMachineAffinityZoneTopologyKey,
+				},
+			},
+		}
+		_, err := placement.MakeAffinityPlacementDecision(spec, peers, candidates)
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("invalid affinity term"))
+	})
+})
+
+var _ = Describe("HasAffinity", func() {
+
+	It("returns if the VM has affinity and host affinity terms", func() {
+		vm := &vmopv1.VirtualMachine{}
+		Expect(placement.HasAffinity(vm)).To(BeFalse())
+		Expect(placement.HasHostAffinity(vm)).To(BeFalse())
+		Expect(placement.HasRequiredDuringExecutionAffinity(vm)).To(BeFalse())
+
+		vm.Spec.Affinity = &vmopv1.VirtualMachineAffinitySpec{
+			VMAntiAffinity: &vmopv1.VirtualMachineAffinityRules{
+				PreferredDuringSchedulingIgnoredDuringExecution: []vmopv1.VirtualMachineWeightedAffinityTerm{
+					{Weight: 1, AffinityTerm: vmopv1.VirtualMachineAffinityTerm{TopologyKey: vmopv1.VirtualMachineAffinityZoneTopologyKey}},
+				},
+			},
+		}
+		Expect(placement.HasAffinity(vm)).To(BeTrue())
+		Expect(placement.HasHostAffinity(vm)).To(BeFalse())
+
+		vm.Spec.Affinity.VMAntiAffinity.RequiredDuringSchedulingRequiredDuringExecution = []vmopv1.VirtualMachineAffinityTerm{
+			{TopologyKey: vmopv1.VirtualMachineAffinityHostTopologyKey},
+		}
+		Expect(placement.HasHostAffinity(vm)).To(BeTrue())
+		Expect(placement.HasRequiredDuringExecutionAffinity(vm)).To(BeTrue())
+	})
+})
@@ -1,4 +1,4 @@
-// Copyright (c) 2019-2021 VMware, Inc. All Rights Reserved.
+// Copyright (c) 2019-2023 VMware, Inc. All Rights Reserved.
 // SPDX-License-Identifier: Apache-2.0
 
 package placement
@@ -22,6 +22,8 @@ var log = logf.Log.WithName("vsphere").WithName("placement")
 type Recommendation struct {
 	PoolMoRef types.ManagedObjectReference
 	HostMoRef *types.ManagedObjectReference
+	// Rating is the DRS rating of the recommendation, if any.
+	Rating int32
 	// TODO: Datastore, whatever else as we need it.
 }
 
@@ -106,15 +108,18 @@ func CloneVMRelocateSpec(
 	return rSpec, nil
 }
 
-// PlaceVMForCreate determines the suitable placement candidates in the cluster.
+// PlaceVMForCreate determines the suitable placement candidates in the cluster. When hosts
+// are specified, the candidates are limited to those hosts.
 func PlaceVMForCreate(
 	ctx goctx.Context,
 	cluster *object.ClusterComputeResource,
-	configSpec *types.VirtualMachineConfigSpec) ([]Recommendation, error) {
+	configSpec *types.VirtualMachineConfigSpec,
+	hosts ...types.ManagedObjectReference) ([]Recommendation, error) {
 
 	placementSpec := types.PlacementSpec{
 		PlacementType: string(types.PlacementSpecPlacementTypeCreate),
 		ConfigSpec:    configSpec,
+		Hosts:         hosts,
 	}
 
 	resp, err := cluster.PlaceVm(ctx, placementSpec)
@@ -131,8 +136,9 @@ func PlaceVMForCreate(
 
 		for _, a := range r.Action {
 			if pa, ok := a.(*types.PlacementAction); ok {
-				if r := relocateSpecToRecommendation(pa.RelocateSpec); r != nil {
-					recommendations = append(recommendations, *r)
+				if rec := relocateSpecToRecommendation(pa.RelocateSpec); rec != nil {
+					rec.Rating = r.Rating
+					recommendations = append(recommendations, *rec)
 				}
 			}
 		}
@@ -195,6 +201,7 @@ func ClusterPlaceVMForCreate(
 		for _, a := range info.Recommendation.Action {
 			if ca, ok := a.(*types.ClusterClusterInitialPlacementAction); ok {
 				if r := clusterPlacementActionToRecommendation(*ca); r != nil {
+					r.Rating = info.Recommendation.Rating
 					recommendations = append(recommendations, *r)
 				}
 			}
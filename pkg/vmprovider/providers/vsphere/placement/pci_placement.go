@@ -0,0 +1,355 @@
+// Copyright (c) 2023 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package placement
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/vmware/govmomi/property"
+	"github.com/vmware/govmomi/vim25"
+	"github.com/vmware/govmomi/vim25/mo"
+	"github.com/vmware/govmomi/vim25/types"
+
+	vmopv1 "github.com/vmware-tanzu/vm-operator/api/v1alpha1"
+	"github.com/vmware-tanzu/vm-operator/pkg/conditions"
+	"github.com/vmware-tanzu/vm-operator/pkg/context"
+)
+
+// PCIDeviceID is the vendor and device ID of a PCI device.
+type PCIDeviceID struct {
+	VendorID int32
+	DeviceID int32
+}
+
+// newPCIDeviceID returns the ID with the vendor and device IDs as the unsigned 16-bit values
+// since vSphere returns them as signed values.
+func newPCIDeviceID(vendorID, deviceID int32) PCIDeviceID {
+	return PCIDeviceID{VendorID: int32(uint16(vendorID)), DeviceID: int32(uint16(deviceID))}
+}
+
+// PCIDeviceRequirement is a vGPU or Dynamic DirectPath I/O device of a VM that the host the VM
+// is placed on must have available.
+type PCIDeviceRequirement struct {
+	// VGPUProfile is the profile of the vGPU devices. When empty, the requirement is for
+	// Dynamic DirectPath I/O devices with the Device ID.
+	VGPUProfile string
+	Device      PCIDeviceID
+	// Count is the number of the devices of the VM.
+	Count int
+}
+
+func (r PCIDeviceRequirement) String() string {
+	if r.VGPUProfile != "" {
+		return fmt.Sprintf("vGPU profile %s", r.VGPUProfile)
+	}
+	return fmt.Sprintf("PCI device %04x:%04x", r.Device.VendorID, r.Device.DeviceID)
+}
+
+// GetPCIDeviceRequirements returns the requirements of the vGPU and Dynamic DirectPath I/O
+// devices that are added by the ConfigSpec.
+func GetPCIDeviceRequirements(configSpec *types.VirtualMachineConfigSpec) []PCIDeviceRequirement {
+	if configSpec == nil {
+		return nil
+	}
+
+	var requirements []PCIDeviceRequirement
+	add := func(r PCIDeviceRequirement) {
+		for i := range requirements {
+			if requirements[i].VGPUProfile == r.VGPUProfile && requirements[i].Device == r.Device {
+				requirements[i].Count++
+				return
+			}
+		}
+		r.Count = 1
+		requirements = append(requirements, r)
+	}
+
+	for _, change := range configSpec.DeviceChange {
+		spec := change.GetVirtualDeviceConfigSpec()
+		if spec.Operation != types.VirtualDeviceConfigSpecOperationAdd {
+			continue
+		}
+
+		dev, ok := spec.Device.(*types.VirtualPCIPassthrough)
+		if !ok {
+			continue
+		}
+
+		switch backing := dev.Backing.(type) {
+		case *types.VirtualPCIPassthroughVmiopBackingInfo:
+			if backing.Vgpu != "" {
+				add(PCIDeviceRequirement{VGPUProfile: backing.Vgpu})
+			}
+		case *types.VirtualPCIPassthroughDynamicBackingInfo:
+			if len(backing.AllowedDevice) > 0 {
+				allowed := backing.AllowedDevice[0]
+				add(PCIDeviceRequirement{Device: newPCIDeviceID(allowed.VendorId, allowed.DeviceId)})
+			}
+		}
+	}
+
+	return requirements
+}
+
+// HostPCIDevices is the availability of the vGPU and Dynamic DirectPath I/O devices of a host.
+type HostPCIDevices struct {
+	// VGPUProfiles are the vGPU profiles the host supports.
+	VGPUProfiles []string
+	// FreeDevices are the number of the passthrough enabled devices that are not used by a
+	// powered on VM.
+	FreeDevices map[PCIDeviceID]int
+}
+
+// UnsatisfiedPCIDeviceRequirements returns the requirements the host does not satisfy. The
+// capacity of the vGPU profiles is left to DRS.
+func UnsatisfiedPCIDeviceRequirements(
+	requirements []PCIDeviceRequirement,
+	host HostPCIDevices) []PCIDeviceRequirement {
+
+	var unsatisfied []PCIDeviceRequirement
+	for _, r := range requirements {
+		if r.VGPUProfile != "" {
+			supported := false
+			for _, profile := range host.VGPUProfiles {
+				if profile == r.VGPUProfile {
+					supported = true
+					break
+				}
+			}
+			if !supported {
+				unsatisfied = append(unsatisfied, r)
+			}
+			continue
+		}
+
+		if host.FreeDevices[r.Device] < r.Count {
+			unsatisfied = append(unsatisfied, r)
+		}
+	}
+	return unsatisfied
+}
+
+// hostFilter limits the hosts in a zone that DRS may place a VM on.
+type hostFilter interface {
+	filterHosts(
+		vmCtx context.VirtualMachineContext,
+		zoneName string,
+		hosts []types.ManagedObjectReference) ([]types.ManagedObjectReference, error)
+}
+
+// pciDeviceHostFilter limits the hosts that DRS may place a VM on to those that have the VM's
+// vGPU and Dynamic DirectPath I/O devices available.
+type pciDeviceHostFilter struct {
+	zoneClients  ZoneClients
+	requirements []PCIDeviceRequirement
+
+	// satisfiedBy records, by requirement, if any filtered host satisfied the requirement.
+	satisfiedBy map[string]bool
+	// allowedAny is true if any filtered host satisfied all the requirements.
+	allowedAny bool
+}
+
+func newPCIDeviceHostFilter(zoneClients ZoneClients, requirements []PCIDeviceRequirement) *pciDeviceHostFilter {
+	f := &pciDeviceHostFilter{
+		zoneClients:  zoneClients,
+		requirements: requirements,
+		satisfiedBy:  map[string]bool{},
+	}
+	for _, r := range requirements {
+		f.satisfiedBy[r.String()] = false
+	}
+	return f
+}
+
+func (f *pciDeviceHostFilter) filterHosts(
+	vmCtx context.VirtualMachineContext,
+	zoneName string,
+	hosts []types.ManagedObjectReference) ([]types.ManagedObjectReference, error) {
+
+	hostDevices, err := getHostPCIDevices(vmCtx, f.zoneClients[zoneName], hosts)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to get PCI devices of hosts in zone %s", zoneName)
+	}
+
+	var allowed []types.ManagedObjectReference
+	for _, host := range hosts {
+		unsatisfied := UnsatisfiedPCIDeviceRequirements(f.requirements, hostDevices[host])
+
+		for _, r := range f.requirements {
+			satisfied := true
+			for _, u := range unsatisfied {
+				if u == r {
+					satisfied = false
+					break
+				}
+			}
+			if satisfied {
+				f.satisfiedBy[r.String()] = true
+			}
+		}
+
+		if len(unsatisfied) == 0 {
+			allowed = append(allowed, host)
+		} else {
+			vmCtx.Logger.V(4).Info("Host does not have the VM's PCI devices available",
+				"zone", zoneName, "hostMoID", host.Value, "unsatisfied", unsatisfied)
+		}
+	}
+
+	if len(allowed) > 0 {
+		f.allowedAny = true
+	}
+
+	return allowed, nil
+}
+
+// setCondition sets the PCI device placement condition of the VM from the result of the
+// placement.
+func (f *pciDeviceHostFilter) setCondition(vmCtx context.VirtualMachineContext, placementErr error) {
+	if placementErr == nil {
+		conditions.MarkTrue(vmCtx.VM, vmopv1.VirtualMachinePCIDevicePlacementCondition)
+		return
+	}
+
+	if f.allowedAny {
+		// The placement did not fail because of the PCI devices.
+		conditions.Delete(vmCtx.VM, vmopv1.VirtualMachinePCIDevicePlacementCondition)
+		return
+	}
+
+	var vgpuProfiles, devices []string
+	for _, r := range f.requirements {
+		if f.satisfiedBy[r.String()] {
+			continue
+		}
+		if r.VGPUProfile != "" {
+			vgpuProfiles = append(vgpuProfiles, r.VGPUProfile)
+		} else {
+			devices = append(devices, r.String())
+		}
+	}
+	sort.Strings(vgpuProfiles)
+	sort.Strings(devices)
+
+	switch {
+	case len(vgpuProfiles) > 0:
+		conditions.MarkFalse(vmCtx.VM, vmopv1.VirtualMachinePCIDevicePlacementCondition,
+			vmopv1.NoHostWithVGPUProfileReason, vmopv1.ConditionSeverityError,
+			"No host with vGPU profile %s", strings.Join(vgpuProfiles, ", "))
+	case len(devices) > 0:
+		conditions.MarkFalse(vmCtx.VM, vmopv1.VirtualMachinePCIDevicePlacementCondition,
+			vmopv1.NoHostWithPCIDeviceReason, vmopv1.ConditionSeverityError,
+			"No host with free %s", strings.Join(devices, ", "))
+	default:
+		conditions.MarkFalse(vmCtx.VM, vmopv1.VirtualMachinePCIDevicePlacementCondition,
+			vmopv1.NoHostWithAllPCIDevicesReason, vmopv1.ConditionSeverityError,
+			"No host with all of %v", f.requirements)
+	}
+}
+
+// getHostPCIDevices returns the vGPU and Dynamic DirectPath I/O device availability of the hosts.
+func getHostPCIDevices(
+	vmCtx context.VirtualMachineContext,
+	vcClient *vim25.Client,
+	hostMoRefs []types.ManagedObjectReference) (map[types.ManagedObjectReference]HostPCIDevices, error) {
+
+	if len(hostMoRefs) == 0 {
+		return nil, nil
+	}
+
+	pc := property.DefaultCollector(vcClient)
+
+	var hosts []mo.HostSystem
+	props := []string{"config.sharedPassthruGpuTypes", "config.pciPassthruInfo", "hardware.pciDevice", "vm"}
+	if err := pc.Retrieve(vmCtx, hostMoRefs, props, &hosts); err != nil {
+		return nil, err
+	}
+
+	// Get the passthrough devices in use by the powered on VMs on the hosts.
+	var vmMoRefs []types.ManagedObjectReference
+	for _, host := range hosts {
+		vmMoRefs = append(vmMoRefs, host.Vm...)
+	}
+
+	var vms []mo.VirtualMachine
+	if len(vmMoRefs) > 0 {
+		if err := pc.Retrieve(vmCtx, vmMoRefs, []string{"config.hardware.device", "runtime.powerState"}, &vms); err != nil {
+			return nil, err
+		}
+	}
+
+	return NewHostPCIDevices(hosts, vms), nil
+}
+
+// hostPCIDeviceKey is a PCI device of a host. The same PCI address is common across identical
+// hosts so the devices in use are tracked per host.
+type hostPCIDeviceKey struct {
+	host types.ManagedObjectReference
+	id   string
+}
+
+// NewHostPCIDevices returns the vGPU and Dynamic DirectPath I/O device availability of the hosts.
+// The devices of a host are in use when a powered on VM of the host's vm property uses them.
+func NewHostPCIDevices(
+	hosts []mo.HostSystem,
+	vms []mo.VirtualMachine) map[types.ManagedObjectReference]HostPCIDevices {
+
+	vmHosts := map[types.ManagedObjectReference]types.ManagedObjectReference{}
+	for _, host := range hosts {
+		for _, vm := range host.Vm {
+			vmHosts[vm] = host.Reference()
+		}
+	}
+
+	inUse := map[hostPCIDeviceKey]bool{}
+	for _, vm := range vms {
+		host, ok := vmHosts[vm.Reference()]
+		if !ok || vm.Runtime.PowerState != types.VirtualMachinePowerStatePoweredOn || vm.Config == nil {
+			continue
+		}
+		for _, dev := range vm.Config.Hardware.Device {
+			if p, ok := dev.(*types.VirtualPCIPassthrough); ok {
+				switch backing := p.Backing.(type) {
+				case *types.VirtualPCIPassthroughDynamicBackingInfo:
+					inUse[hostPCIDeviceKey{host: host, id: backing.AssignedId}] = true
+				case *types.VirtualPCIPassthroughDeviceBackingInfo:
+					inUse[hostPCIDeviceKey{host: host, id: backing.Id}] = true
+				}
+			}
+		}
+	}
+
+	devices := make(map[types.ManagedObjectReference]HostPCIDevices, len(hosts))
+	for _, host := range hosts {
+		hd := HostPCIDevices{FreeDevices: map[PCIDeviceID]int{}}
+
+		if host.Config != nil {
+			hd.VGPUProfiles = host.Config.SharedPassthruGpuTypes
+
+			if host.Hardware != nil {
+				pciDevices := make(map[string]types.HostPciDevice, len(host.Hardware.PciDevice))
+				for _, d := range host.Hardware.PciDevice {
+					pciDevices[d.Id] = d
+				}
+
+				for _, info := range host.Config.PciPassthruInfo {
+					i := info.GetHostPciPassthruInfo()
+					if !i.PassthruEnabled || inUse[hostPCIDeviceKey{host: host.Reference(), id: i.Id}] {
+						continue
+					}
+					if d, ok := pciDevices[i.Id]; ok {
+						hd.FreeDevices[newPCIDeviceID(int32(d.VendorId), int32(d.DeviceId))]++
+					}
+				}
+			}
+		}
+
+		devices[host.Reference()] = hd
+	}
+
+	return devices
+}
@@ -0,0 +1,162 @@
+// Copyright (c) 2023 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package placement_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/vmware/govmomi/vim25/mo"
+	"github.com/vmware/govmomi/vim25/types"
+
+	"github.com/vmware-tanzu/vm-operator/pkg/vmprovider/providers/vsphere/placement"
+)
+
+var _ = Describe("PCI device requirements", func() {
+
+	vgpu := func(profile string) types.BaseVirtualDeviceConfigSpec {
+		return &types.VirtualDeviceConfigSpec{
+			Operation: types.VirtualDeviceConfigSpecOperationAdd,
+			Device: &types.VirtualPCIPassthrough{
+				VirtualDevice: types.VirtualDevice{
+					Backing: &types.VirtualPCIPassthroughVmiopBackingInfo{Vgpu: profile},
+				},
+			},
+		}
+	}
+
+	dynamic := func(vendorID, deviceID int32) types.BaseVirtualDeviceConfigSpec {
+		return &types.VirtualDeviceConfigSpec{
+			Operation: types.VirtualDeviceConfigSpecOperationAdd,
+			Device: &types.VirtualPCIPassthrough{
+				VirtualDevice: types.VirtualDevice{
+					Backing: &types.VirtualPCIPassthroughDynamicBackingInfo{
+						AllowedDevice: []types.VirtualPCIPassthroughAllowedDevice{
+							{VendorId: vendorID, DeviceId: deviceID},
+						},
+					},
+				},
+			},
+		}
+	}
+
+	var (
+		vgpuReq    = placement.PCIDeviceRequirement{VGPUProfile: "grid_p40-8q", Count: 1}
+		dynamicReq = placement.PCIDeviceRequirement{
+			Device: placement.PCIDeviceID{VendorID: 0x10de, DeviceID: 0x8eb8},
+			Count:  2,
+		}
+	)
+
+	Describe("GetPCIDeviceRequirements", func() {
+		It("returns the requirements of the added devices", func() {
+			configSpec := &types.VirtualMachineConfigSpec{
+				DeviceChange: []types.BaseVirtualDeviceConfigSpec{
+					vgpu("grid_p40-8q"),
+					// The signed device ID returned by vSphere is the same as the unsigned ID.
+					dynamic(0x10de, 0x8eb8),
+					dynamic(0x10de, int32(int16(-0x7148))),
+					&types.VirtualDeviceConfigSpec{
+						Operation: types.VirtualDeviceConfigSpecOperationAdd,
+						Device:    &types.VirtualDisk{},
+					},
+					&types.VirtualDeviceConfigSpec{
+						Operation: types.VirtualDeviceConfigSpecOperationRemove,
+						Device: &types.VirtualPCIPassthrough{
+							VirtualDevice: types.VirtualDevice{
+								Backing: &types.VirtualPCIPassthroughVmiopBackingInfo{Vgpu: "grid_p40-1q"},
+							},
+						},
+					},
+				},
+			}
+
+			Expect(placement.GetPCIDeviceRequirements(configSpec)).To(ConsistOf(vgpuReq, dynamicReq))
+		})
+
+		It("returns no requirements without PCI devices", func() {
+			Expect(placement.GetPCIDeviceRequirements(nil)).To(BeEmpty())
+			Expect(placement.GetPCIDeviceRequirements(&types.VirtualMachineConfigSpec{})).To(BeEmpty())
+		})
+	})
+
+	Describe("UnsatisfiedPCIDeviceRequirements", func() {
+		requirements := []placement.PCIDeviceRequirement{vgpuReq, dynamicReq}
+
+		It("returns no requirements when the host has the devices available", func() {
+			host := placement.HostPCIDevices{
+				VGPUProfiles: []string{"grid_p40-1q", "grid_p40-8q"},
+				FreeDevices:  map[placement.PCIDeviceID]int{dynamicReq.Device: 2},
+			}
+			Expect(placement.UnsatisfiedPCIDeviceRequirements(requirements, host)).To(BeEmpty())
+		})
+
+		It("returns the requirements the host does not have available", func() {
+			host := placement.HostPCIDevices{
+				VGPUProfiles: []string{"grid_p40-1q"},
+				FreeDevices:  map[placement.PCIDeviceID]int{dynamicReq.Device: 1},
+			}
+			Expect(placement.UnsatisfiedPCIDeviceRequirements(requirements, host)).To(
+				ConsistOf(vgpuReq, dynamicReq))
+		})
+	})
+
+	Describe("NewHostPCIDevices", func() {
+		const pciAddress = "0000:3b:00.0"
+
+		host := func(moID string, vms ...types.ManagedObjectReference) mo.HostSystem {
+			h := mo.HostSystem{
+				Config: &types.HostConfigInfo{
+					PciPassthruInfo: []types.BaseHostPciPassthruInfo{
+						&types.HostPciPassthruInfo{Id: pciAddress, PassthruEnabled: true},
+					},
+				},
+				Hardware: &types.HostHardwareInfo{
+					PciDevice: []types.HostPciDevice{
+						{Id: pciAddress, VendorId: int16(dynamicReq.Device.VendorID), DeviceId: int16(dynamicReq.Device.DeviceID)},
+					},
+				},
+				Vm: vms,
+			}
+			h.Self = types.ManagedObjectReference{Type: "HostSystem", Value: moID}
+			return h
+		}
+
+		vm := func(moID string, powerState types.VirtualMachinePowerState) mo.VirtualMachine {
+			v := mo.VirtualMachine{
+				Config: &types.VirtualMachineConfigInfo{
+					Hardware: types.VirtualHardware{
+						Device: []types.BaseVirtualDevice{
+							&types.VirtualPCIPassthrough{
+								VirtualDevice: types.VirtualDevice{
+									Backing: &types.VirtualPCIPassthroughDynamicBackingInfo{AssignedId: pciAddress},
+								},
+							},
+						},
+					},
+				},
+				Runtime: types.VirtualMachineRuntimeInfo{PowerState: powerState},
+			}
+			v.Self = types.ManagedObjectReference{Type: "VirtualMachine", Value: moID}
+			return v
+		}
+
+		It("only counts the devices in use by the VMs of the same host", func() {
+			vm1 := vm("vm-1", types.VirtualMachinePowerStatePoweredOn)
+			vm2 := vm("vm-2", types.VirtualMachinePowerStatePoweredOff)
+			host1 := host("host-1", vm1.Reference())
+			host2 := host("host-2", vm2.Reference())
+
+			devices := placement.NewHostPCIDevices([]mo.HostSystem{host1, host2}, []mo.VirtualMachine{vm1, vm2})
+			Expect(devices).To(HaveLen(2))
+			Expect(devices[host1.Reference()].FreeDevices).To(BeEmpty())
+			Expect(devices[host2.Reference()].FreeDevices).To(Equal(map[placement.PCIDeviceID]int{dynamicReq.Device: 1}))
+		})
+	})
+
+	It("describes the requirements", func() {
+		Expect(vgpuReq.String()).To(Equal("vGPU profile grid_p40-8q"))
+		Expect(dynamicReq.String()).To(Equal("PCI device 10de:8eb8"))
+	})
+})
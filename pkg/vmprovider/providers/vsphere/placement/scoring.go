@@ -0,0 +1,493 @@
+// Copyright (c) 2023 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package placement
+
+import (
+	"fmt"
+	"math/rand"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/vmware/govmomi/pbm"
+	pbmTypes "github.com/vmware/govmomi/pbm/types"
+	"github.com/vmware/govmomi/property"
+	"github.com/vmware/govmomi/vim25"
+	"github.com/vmware/govmomi/vim25/mo"
+	"github.com/vmware/govmomi/vim25/types"
+
+	"github.com/vmware-tanzu/vm-operator/pkg/context"
+)
+
+const (
+	// DRSRatingScorerName is the name of the scorer that prefers the recommendations with
+	// the highest DRS rating.
+	DRSRatingScorerName = "DRSRating"
+	// ZoneFreeCapacityScorerName is the name of the scorer that prefers the recommendations
+	// in the clusters with the most free CPU and memory capacity.
+	ZoneFreeCapacityScorerName = "ZoneFreeCapacity"
+	// DatastoreFreeSpaceScorerName is the name of the scorer that prefers the recommendations
+	// with the datastore compatible with the VM's storage profile with the most free space.
+	DatastoreFreeSpaceScorerName = "DatastoreFreeSpace"
+	// LeastVMsInNamespaceScorerName is the name of the scorer that prefers the recommendations
+	// in the zones with the fewest VMs of the namespace.
+	LeastVMsInNamespaceScorerName = "LeastVMsInNamespace"
+)
+
+// ScoredRecommendation is a placement recommendation in a zone.
+type ScoredRecommendation struct {
+	ZoneName       string
+	Recommendation Recommendation
+}
+
+// ScoringContext is the context the recommendations for the placement of a VM are scored in.
+type ScoringContext struct {
+	context.VirtualMachineContext
+	ZoneClients ZoneClients
+	// Peers are the other VMs in the namespace of the VM.
+	Peers []AffinityPeer
+	// StorageProfileID is the ID of the storage profile of the VM, if any.
+	StorageProfileID string
+}
+
+// Scorer scores placement recommendations.
+type Scorer interface {
+	// Name returns the name of the scorer that its weight is configured with.
+	Name() string
+	// Score returns the raw score of each of the recommendations where a higher score is
+	// better. The raw scores are normalized across the recommendations before they are
+	// weighted.
+	Score(ctx ScoringContext, recommendations []ScoredRecommendation) ([]float64, error)
+}
+
+// ScorerWeights are the weights of the scorers by name. A scorer without a positive weight
+// is not run.
+type ScorerWeights map[string]int32
+
+// DefaultScorerWeights returns the weights used when none are configured.
+func DefaultScorerWeights() ScorerWeights {
+	return ScorerWeights{
+		DRSRatingScorerName:           2,
+		ZoneFreeCapacityScorerName:    1,
+		DatastoreFreeSpaceScorerName:  1,
+		LeastVMsInNamespaceScorerName: 1,
+	}
+}
+
+var (
+	scorersLock sync.RWMutex
+	scorers     = map[string]Scorer{}
+)
+
+func init() {
+	RegisterScorer(drsRatingScorer{})
+	RegisterScorer(zoneFreeCapacityScorer{})
+	RegisterScorer(datastoreFreeSpaceScorer{})
+	RegisterScorer(leastVMsInNamespaceScorer{})
+}
+
+// RegisterScorer registers the scorer, replacing any registered scorer with the same name.
+func RegisterScorer(s Scorer) {
+	scorersLock.Lock()
+	defer scorersLock.Unlock()
+	scorers[s.Name()] = s
+}
+
+func getScorer(name string) Scorer {
+	scorersLock.RLock()
+	defer scorersLock.RUnlock()
+	return scorers[name]
+}
+
+// PlacementDecision is the recommendation selected by MakeScoredPlacementDecision.
+type PlacementDecision struct {
+	ScoredRecommendation
+	// Score is the sum of the weighted normalized scores of the recommendation.
+	Score float64
+	// Scores are the normalized scores of the recommendation by scorer name.
+	Scores map[string]float64
+}
+
+// Explanation returns a description of why the recommendation was selected.
+func (d PlacementDecision) Explanation(weights ScorerWeights) string {
+	names := make([]string, 0, len(d.Scores))
+	for name := range d.Scores {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	scores := make([]string, 0, len(names))
+	for _, name := range names {
+		scores = append(scores, fmt.Sprintf("%s=%.2fx%d", name, d.Scores[name], weights[name]))
+	}
+
+	explanation := fmt.Sprintf("zone=%q pool=%s", d.ZoneName, d.Recommendation.PoolMoRef.Value)
+	if d.Recommendation.HostMoRef != nil {
+		explanation += fmt.Sprintf(" host=%s", d.Recommendation.HostMoRef.Value)
+	}
+	explanation += fmt.Sprintf(" score=%.2f", d.Score)
+	if len(scores) > 0 {
+		explanation += fmt.Sprintf(" (%s)", strings.Join(scores, " "))
+	}
+
+	return explanation
+}
+
+// MakeScoredPlacementDecision selects the recommendation with the highest sum of the weighted
+// normalized scores of the scorers. Ties are broken randomly. Unknown scorers are ignored.
+func MakeScoredPlacementDecision(
+	ctx ScoringContext,
+	recommendations map[string][]Recommendation,
+	weights ScorerWeights) (PlacementDecision, error) {
+
+	// Sort the zones so the ties are broken only by rand.
+	zoneNames := make([]string, 0, len(recommendations))
+	for zoneName := range recommendations {
+		zoneNames = append(zoneNames, zoneName)
+	}
+	sort.Strings(zoneNames)
+
+	var recs []ScoredRecommendation
+	for _, zoneName := range zoneNames {
+		for _, rec := range recommendations[zoneName] {
+			recs = append(recs, ScoredRecommendation{ZoneName: zoneName, Recommendation: rec})
+		}
+	}
+
+	if len(recs) == 0 {
+		return PlacementDecision{}, fmt.Errorf("no placement recommendations available")
+	}
+
+	decisions := make([]PlacementDecision, len(recs))
+	for i := range recs {
+		decisions[i] = PlacementDecision{ScoredRecommendation: recs[i], Scores: map[string]float64{}}
+	}
+
+	names := make([]string, 0, len(weights))
+	for name := range weights {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		weight := weights[name]
+		if weight <= 0 {
+			continue
+		}
+
+		scorer := getScorer(name)
+		if scorer == nil {
+			ctx.Logger.V(4).Info("Ignoring unknown placement scorer", "scorer", name)
+			continue
+		}
+
+		raw, err := scorer.Score(ctx, recs)
+		if err != nil {
+			return PlacementDecision{}, fmt.Errorf("placement scorer %s failed: %w", name, err)
+		}
+		if len(raw) != len(recs) {
+			return PlacementDecision{}, fmt.Errorf("placement scorer %s returned %d scores for %d recommendations",
+				name, len(raw), len(recs))
+		}
+
+		for i, score := range normalizeScores(raw) {
+			decisions[i].Scores[name] = score
+			decisions[i].Score += score * float64(weight)
+		}
+	}
+
+	var best []PlacementDecision
+	for _, d := range decisions {
+		switch {
+		case len(best) == 0 || d.Score > best[0].Score:
+			best = []PlacementDecision{d}
+		case d.Score == best[0].Score:
+			best = append(best, d)
+		}
+	}
+
+	return best[rand.Intn(len(best))], nil //nolint:gosec
+}
+
+// normalizeScores scales the raw scores to [0, 1]. When all the scores are equal, they are
+// all normalized to 1 so the scorer does not affect the decision.
+func normalizeScores(raw []float64) []float64 {
+	minScore, maxScore := raw[0], raw[0]
+	for _, s := range raw[1:] {
+		if s < minScore {
+			minScore = s
+		}
+		if s > maxScore {
+			maxScore = s
+		}
+	}
+
+	normalized := make([]float64, len(raw))
+	for i, s := range raw {
+		if maxScore == minScore {
+			normalized[i] = 1
+		} else {
+			normalized[i] = (s - minScore) / (maxScore - minScore)
+		}
+	}
+	return normalized
+}
+
+type drsRatingScorer struct{}
+
+func (drsRatingScorer) Name() string {
+	return DRSRatingScorerName
+}
+
+func (drsRatingScorer) Score(_ ScoringContext, recs []ScoredRecommendation) ([]float64, error) {
+	scores := make([]float64, len(recs))
+	for i, rec := range recs {
+		scores[i] = float64(rec.Recommendation.Rating)
+	}
+	return scores, nil
+}
+
+type zoneFreeCapacityScorer struct{}
+
+func (zoneFreeCapacityScorer) Name() string {
+	return ZoneFreeCapacityScorerName
+}
+
+// scoreByVCenter scores the recommendations of each vCenter separately, since the managed object
+// references of the recommendations are only unique within their vCenter.
+func scoreByVCenter(
+	ctx ScoringContext,
+	recs []ScoredRecommendation,
+	score func(vcClient *vim25.Client, recs []ScoredRecommendation) ([]float64, error)) ([]float64, error) {
+
+	var vcClients []*vim25.Client
+	indexes := map[*vim25.Client][]int{}
+	for i, rec := range recs {
+		vcClient := ctx.ZoneClients[rec.ZoneName]
+		if _, ok := indexes[vcClient]; !ok {
+			vcClients = append(vcClients, vcClient)
+		}
+		indexes[vcClient] = append(indexes[vcClient], i)
+	}
+
+	scores := make([]float64, len(recs))
+	for _, vcClient := range vcClients {
+		vcRecs := make([]ScoredRecommendation, 0, len(indexes[vcClient]))
+		for _, i := range indexes[vcClient] {
+			vcRecs = append(vcRecs, recs[i])
+		}
+
+		vcScores, err := score(vcClient, vcRecs)
+		if err != nil {
+			return nil, err
+		}
+		for j, i := range indexes[vcClient] {
+			scores[i] = vcScores[j]
+		}
+	}
+
+	return scores, nil
+}
+
+// Score returns the average of the fractions of the free CPU and memory capacity of the
+// cluster of each recommendation's resource pool.
+func (zoneFreeCapacityScorer) Score(ctx ScoringContext, recs []ScoredRecommendation) ([]float64, error) {
+	return scoreByVCenter(ctx, recs, func(vcClient *vim25.Client, recs []ScoredRecommendation) ([]float64, error) {
+		return zoneFreeCapacityScores(ctx, vcClient, recs)
+	})
+}
+
+func zoneFreeCapacityScores(
+	ctx ScoringContext,
+	vcClient *vim25.Client,
+	recs []ScoredRecommendation) ([]float64, error) {
+
+	clusterScores := map[types.ManagedObjectReference]float64{}
+	scores := make([]float64, len(recs))
+
+	for i, rec := range recs {
+		cluster, err := rpMoIDToCluster(ctx, vcClient, rec.Recommendation.PoolMoRef)
+		if err != nil {
+			return nil, err
+		}
+
+		score, ok := clusterScores[cluster.Reference()]
+		if !ok {
+			var moCluster mo.ClusterComputeResource
+			if err := cluster.Properties(ctx, cluster.Reference(), []string{"summary"}, &moCluster); err != nil {
+				return nil, err
+			}
+
+			if summary, ok := moCluster.Summary.(*types.ClusterComputeResourceSummary); ok && summary.UsageSummary != nil {
+				usage := summary.UsageSummary
+				score = (freeFraction(int64(usage.TotalCpuCapacityMhz), int64(usage.CpuDemandMhz)) +
+					freeFraction(int64(usage.TotalMemCapacityMB), int64(usage.MemDemandMB))) / 2
+			}
+			clusterScores[cluster.Reference()] = score
+		}
+
+		scores[i] = score
+	}
+
+	return scores, nil
+}
+
+func freeFraction(capacity, demand int64) float64 {
+	if capacity <= 0 || demand >= capacity {
+		return 0
+	}
+	return float64(capacity-demand) / float64(capacity)
+}
+
+type datastoreFreeSpaceScorer struct{}
+
+func (datastoreFreeSpaceScorer) Name() string {
+	return DatastoreFreeSpaceScorerName
+}
+
+// Score returns the most free space of the accessible datastores of each recommendation's
+// host, or of the cluster of the recommendation's resource pool when there is no host. When
+// the VM has a storage profile, only the datastores compatible with it are considered.
+func (datastoreFreeSpaceScorer) Score(ctx ScoringContext, recs []ScoredRecommendation) ([]float64, error) {
+	return scoreByVCenter(ctx, recs, func(vcClient *vim25.Client, recs []ScoredRecommendation) ([]float64, error) {
+		return datastoreFreeSpaceScores(ctx, vcClient, recs)
+	})
+}
+
+func datastoreFreeSpaceScores(
+	ctx ScoringContext,
+	vcClient *vim25.Client,
+	recs []ScoredRecommendation) ([]float64, error) {
+
+	pc := property.DefaultCollector(vcClient)
+	recDatastores := make([][]types.ManagedObjectReference, len(recs))
+	freeSpace := map[types.ManagedObjectReference]float64{}
+	var datastores []types.ManagedObjectReference
+
+	for i, rec := range recs {
+		if rec.Recommendation.HostMoRef != nil {
+			var host mo.HostSystem
+			if err := pc.RetrieveOne(ctx, *rec.Recommendation.HostMoRef, []string{"datastore"}, &host); err != nil {
+				return nil, err
+			}
+			recDatastores[i] = host.Datastore
+		} else {
+			cluster, err := rpMoIDToCluster(ctx, vcClient, rec.Recommendation.PoolMoRef)
+			if err != nil {
+				return nil, err
+			}
+
+			var moCluster mo.ClusterComputeResource
+			if err := cluster.Properties(ctx, cluster.Reference(), []string{"datastore"}, &moCluster); err != nil {
+				return nil, err
+			}
+			recDatastores[i] = moCluster.Datastore
+		}
+
+		for _, ds := range recDatastores[i] {
+			if _, ok := freeSpace[ds]; !ok {
+				freeSpace[ds] = 0
+				datastores = append(datastores, ds)
+			}
+		}
+	}
+
+	if ctx.StorageProfileID != "" && len(datastores) > 0 {
+		compatible, err := getCompatibleDatastores(ctx, vcClient, ctx.StorageProfileID, datastores)
+		if err != nil {
+			return nil, err
+		}
+		datastores = compatible
+	}
+
+	if len(datastores) > 0 {
+		var moDatastores []mo.Datastore
+		if err := pc.Retrieve(ctx, datastores, []string{"summary"}, &moDatastores); err != nil {
+			return nil, err
+		}
+		for _, ds := range moDatastores {
+			if ds.Summary.Accessible {
+				freeSpace[ds.Reference()] = float64(ds.Summary.FreeSpace)
+			}
+		}
+	}
+
+	scores := make([]float64, len(recs))
+	for i := range recs {
+		for _, ds := range recDatastores[i] {
+			if free := freeSpace[ds]; free > scores[i] {
+				scores[i] = free
+			}
+		}
+	}
+
+	return scores, nil
+}
+
+// getCompatibleDatastores returns the datastores that are compatible with the storage profile.
+func getCompatibleDatastores(
+	ctx ScoringContext,
+	vcClient *vim25.Client,
+	storageProfileID string,
+	datastores []types.ManagedObjectReference) ([]types.ManagedObjectReference, error) {
+
+	c, err := pbm.NewClient(ctx, vcClient)
+	if err != nil {
+		return nil, err
+	}
+
+	hubs := make([]pbmTypes.PbmPlacementHub, 0, len(datastores))
+	for _, ds := range datastores {
+		hubs = append(hubs, pbmTypes.PbmPlacementHub{HubType: ds.Type, HubId: ds.Value})
+	}
+
+	req := []pbmTypes.BasePbmPlacementRequirement{
+		&pbmTypes.PbmPlacementCapabilityProfileRequirement{
+			ProfileId: pbmTypes.PbmProfileId{UniqueId: storageProfileID},
+		},
+	}
+
+	result, err := c.CheckRequirements(ctx, hubs, nil, req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check datastores compatibility with storage profile %s: %w",
+			storageProfileID, err)
+	}
+
+	isCandidate := make(map[string]bool, len(datastores))
+	for _, ds := range datastores {
+		isCandidate[ds.Value] = true
+	}
+
+	var compatible []types.ManagedObjectReference
+	for _, hub := range result.CompatibleDatastores() {
+		if isCandidate[hub.HubId] {
+			compatible = append(compatible, types.ManagedObjectReference{Type: hub.HubType, Value: hub.HubId})
+		}
+	}
+
+	return compatible, nil
+}
+
+type leastVMsInNamespaceScorer struct{}
+
+func (leastVMsInNamespaceScorer) Name() string {
+	return LeastVMsInNamespaceScorerName
+}
+
+// Score returns the negated number of the other VMs in the namespace in the zone of each
+// recommendation.
+func (leastVMsInNamespaceScorer) Score(ctx ScoringContext, recs []ScoredRecommendation) ([]float64, error) {
+	counts := map[string]int{}
+	for _, peer := range ctx.Peers {
+		if peer.Zone != "" {
+			counts[peer.Zone]++
+		}
+	}
+
+	scores := make([]float64, len(recs))
+	for i, rec := range recs {
+		scores[i] = -float64(counts[rec.ZoneName])
+	}
+	return scores, nil
+}
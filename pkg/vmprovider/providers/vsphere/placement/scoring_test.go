@@ -0,0 +1,162 @@
+// Copyright (c) 2023 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package placement_test
+
+import (
+	goctx "context"
+	"fmt"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/vmware/govmomi/vim25/types"
+
+	"github.com/vmware-tanzu/vm-operator/pkg/context"
+	"github.com/vmware-tanzu/vm-operator/pkg/vmprovider/providers/vsphere/placement"
+)
+
+type poolScorer struct {
+	scores map[string]float64
+	err    error
+}
+
+func (s poolScorer) Name() string {
+	return "PoolTest"
+}
+
+func (s poolScorer) Score(_ placement.ScoringContext, recs []placement.ScoredRecommendation) ([]float64, error) {
+	if s.err != nil {
+		return nil, s.err
+	}
+	scores := make([]float64, len(recs))
+	for i, rec := range recs {
+		scores[i] = s.scores[rec.Recommendation.PoolMoRef.Value]
+	}
+	return scores, nil
+}
+
+var _ = Describe("MakeScoredPlacementDecision", func() {
+
+	var (
+		ctx             placement.ScoringContext
+		recommendations map[string][]placement.Recommendation
+		weights         placement.ScorerWeights
+	)
+
+	rec := func(pool string, rating int32) placement.Recommendation {
+		return placement.Recommendation{
+			PoolMoRef: types.ManagedObjectReference{Type: "ResourcePool", Value: pool},
+			Rating:    rating,
+		}
+	}
+
+	BeforeEach(func() {
+		ctx = placement.ScoringContext{
+			VirtualMachineContext: context.VirtualMachineContext{
+				Context: goctx.Background(),
+				Logger:  suite.GetLogger(),
+			},
+			Peers: []placement.AffinityPeer{
+				{Zone: "zone1"},
+				{Zone: "zone1"},
+				{Zone: "zone2"},
+			},
+		}
+		recommendations = map[string][]placement.Recommendation{
+			"zone1": {rec("rp-1", 5)},
+			"zone2": {rec("rp-2", 3)},
+			"zone3": {rec("rp-3", 1)},
+		}
+		weights = placement.ScorerWeights{}
+	})
+
+	It("selects the recommendation with the highest DRS rating", func() {
+		weights[placement.DRSRatingScorerName] = 1
+
+		d, err := placement.MakeScoredPlacementDecision(ctx, recommendations, weights)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(d.ZoneName).To(Equal("zone1"))
+		Expect(d.Score).To(Equal(1.0))
+		Expect(d.Scores).To(HaveKeyWithValue(placement.DRSRatingScorerName, 1.0))
+	})
+
+	It("selects the recommendation in the zone with the fewest VMs in the namespace", func() {
+		weights[placement.LeastVMsInNamespaceScorerName] = 1
+
+		d, err := placement.MakeScoredPlacementDecision(ctx, recommendations, weights)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(d.ZoneName).To(Equal("zone3"))
+	})
+
+	It("sums the weighted normalized scores", func() {
+		// The normalized DRS ratings are 1, 0.5 and 0, and the normalized VM counts are 0, 0.5 and 1.
+		weights[placement.DRSRatingScorerName] = 1
+		weights[placement.LeastVMsInNamespaceScorerName] = 3
+
+		d, err := placement.MakeScoredPlacementDecision(ctx, recommendations, weights)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(d.ZoneName).To(Equal("zone3"))
+		Expect(d.Score).To(Equal(3.0))
+		Expect(d.Explanation(weights)).To(Equal(
+			`zone="zone3" pool=rp-3 score=3.00 (DRSRating=0.00x1 LeastVMsInNamespace=1.00x3)`))
+	})
+
+	It("does not run the scorers without a positive weight or that are unknown", func() {
+		weights[placement.DRSRatingScorerName] = 0
+		weights["Unknown"] = 1
+
+		d, err := placement.MakeScoredPlacementDecision(ctx, recommendations, weights)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(d.Scores).To(BeEmpty())
+		Expect(d.Score).To(BeZero())
+	})
+
+	It("normalizes equal scores so they do not affect the decision", func() {
+		weights[placement.DRSRatingScorerName] = 1
+		recommendations = map[string][]placement.Recommendation{
+			"zone1": {rec("rp-1", 1)},
+			"zone2": {rec("rp-2", 1)},
+		}
+
+		d, err := placement.MakeScoredPlacementDecision(ctx, recommendations, weights)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(d.ZoneName).To(BeElementOf("zone1", "zone2"))
+		Expect(d.Score).To(Equal(1.0))
+	})
+
+	Context("with a registered scorer", func() {
+		var scorer poolScorer
+
+		BeforeEach(func() {
+			scorer = poolScorer{scores: map[string]float64{"rp-1": 1, "rp-2": 10, "rp-3": 5}}
+			weights[scorer.Name()] = 1
+		})
+
+		JustBeforeEach(func() {
+			placement.RegisterScorer(scorer)
+		})
+
+		It("uses the scorer", func() {
+			d, err := placement.MakeScoredPlacementDecision(ctx, recommendations, weights)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(d.ZoneName).To(Equal("zone2"))
+		})
+
+		When("the scorer fails", func() {
+			BeforeEach(func() {
+				scorer.err = fmt.Errorf("fake error")
+			})
+
+			It("returns an error", func() {
+				_, err := placement.MakeScoredPlacementDecision(ctx, recommendations, weights)
+				Expect(err).To(MatchError("placement scorer PoolTest failed: fake error"))
+			})
+		})
+	})
+
+	It("returns an error without recommendations", func() {
+		_, err := placement.MakeScoredPlacementDecision(ctx, nil, weights)
+		Expect(err).To(MatchError("no placement recommendations available"))
+	})
+})
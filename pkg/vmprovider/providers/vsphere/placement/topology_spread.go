@@ -0,0 +1,178 @@
+// Copyright (c) 2023 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package placement
+
+import (
+	"fmt"
+	"sort"
+
+	"k8s.io/apimachinery/pkg/labels"
+
+	vmopv1 "github.com/vmware-tanzu/vm-operator/api/v1alpha1"
+	"github.com/vmware-tanzu/vm-operator/pkg/conditions"
+	"github.com/vmware-tanzu/vm-operator/pkg/context"
+)
+
+// TopologySpreadZone is the evaluation of the topology spread constraints of
+// a VM when the VM is placed in a zone.
+type TopologySpreadZone struct {
+	// Satisfied is true if the zone satisfies all the DoNotSchedule constraints.
+	Satisfied bool
+	// Skewed is true if the zone exceeds the maximum skew of any ScheduleAnyway
+	// constraint.
+	Skewed bool
+	// Skew is the sum of the skews of the ScheduleAnyway constraints.
+	Skew int64
+}
+
+// EvaluateTopologySpread evaluates the topology spread constraints of a VM with
+// the labels for each of the zones the VM may be placed in. The skew of a
+// constraint in a zone is the number of VMs selected by the constraint in the
+// zone, including the VM itself, minus the minimum number of selected VMs in
+// any of the zones.
+func EvaluateTopologySpread(
+	constraints []vmopv1.VirtualMachineTopologySpreadConstraint,
+	vmLabels map[string]string,
+	peers []AffinityPeer,
+	zoneNames []string) (map[string]TopologySpreadZone, error) {
+
+	zones := make(map[string]TopologySpreadZone, len(zoneNames))
+	for _, zoneName := range zoneNames {
+		zones[zoneName] = TopologySpreadZone{Satisfied: true}
+	}
+
+	for _, constraint := range constraints {
+		selector, err := labelSelectorAsSelector(constraint.LabelSelector)
+		if err != nil {
+			return nil, err
+		}
+
+		counts := make(map[string]int64, len(zoneNames))
+		for _, zoneName := range zoneNames {
+			counts[zoneName] = 0
+		}
+		for _, peer := range peers {
+			if _, ok := counts[peer.Zone]; ok && selector.Matches(labels.Set(peer.Labels)) {
+				counts[peer.Zone]++
+			}
+		}
+
+		var self int64
+		if selector.Matches(labels.Set(vmLabels)) {
+			self = 1
+		}
+
+		minCount := int64(-1)
+		for _, count := range counts {
+			if minCount < 0 || count < minCount {
+				minCount = count
+			}
+		}
+
+		for zoneName, count := range counts {
+			zone := zones[zoneName]
+			skew := count + self - minCount
+
+			switch constraint.WhenUnsatisfiable {
+			case vmopv1.VirtualMachineScheduleAnyway:
+				zone.Skew += skew
+				if skew > int64(constraint.MaxSkew) {
+					zone.Skewed = true
+				}
+			default:
+				if skew > int64(constraint.MaxSkew) {
+					zone.Satisfied = false
+				}
+			}
+
+			zones[zoneName] = zone
+		}
+	}
+
+	return zones, nil
+}
+
+// getTopologySpreadCandidateSets evaluates the VM's topology spread constraints for the
+// candidate zones, and returns the candidates in the zones that satisfy the DoNotSchedule
+// constraints, first limited to the zones with the lowest skew of the ScheduleAnyway
+// constraints.
+func getTopologySpreadCandidateSets(
+	vmCtx context.VirtualMachineContext,
+	peers []AffinityPeer,
+	candidates map[string][]string) (map[string]TopologySpreadZone, []map[string][]string, error) {
+
+	zoneNames := make([]string, 0, len(candidates))
+	for zoneName := range candidates {
+		zoneNames = append(zoneNames, zoneName)
+	}
+	sort.Strings(zoneNames)
+
+	zones, err := EvaluateTopologySpread(vmCtx.VM.Spec.TopologySpreadConstraints, vmCtx.VM.Labels, peers, zoneNames)
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid topology spread constraint: %w", err)
+	}
+
+	satisfied := map[string][]string{}
+	var minSkew int64 = -1
+	for zoneName, rpMoIDs := range candidates {
+		if zone := zones[zoneName]; zone.Satisfied {
+			satisfied[zoneName] = rpMoIDs
+			if minSkew < 0 || zone.Skew < minSkew {
+				minSkew = zone.Skew
+			}
+		}
+	}
+
+	leastSkewed := map[string][]string{}
+	for zoneName, rpMoIDs := range satisfied {
+		if zones[zoneName].Skew == minSkew {
+			leastSkewed[zoneName] = rpMoIDs
+		}
+	}
+
+	vmCtx.Logger.V(4).Info("Topology spread candidates", "zones", zones)
+
+	if len(leastSkewed) == len(satisfied) {
+		return zones, []map[string][]string{satisfied}, nil
+	}
+	return zones, []map[string][]string{leastSkewed, satisfied}, nil
+}
+
+// setTopologySpreadCondition sets the topology spread condition of the VM from the result
+// of placing the VM in the zone.
+func setTopologySpreadCondition(
+	vmCtx context.VirtualMachineContext,
+	zones map[string]TopologySpreadZone,
+	zoneName string,
+	placementErr error) {
+
+	if placementErr != nil {
+		var unsatisfied []string
+		for name, zone := range zones {
+			if !zone.Satisfied {
+				unsatisfied = append(unsatisfied, name)
+			}
+		}
+		if len(unsatisfied) == 0 {
+			// The placement did not fail because of the constraints.
+			conditions.Delete(vmCtx.VM, vmopv1.VirtualMachineTopologySpreadCondition)
+			return
+		}
+
+		sort.Strings(unsatisfied)
+		conditions.MarkFalse(vmCtx.VM, vmopv1.VirtualMachineTopologySpreadCondition,
+			vmopv1.TopologySpreadConstraintsNotSatisfiedReason, vmopv1.ConditionSeverityError,
+			"Zones %v do not satisfy the DoNotSchedule topology spread constraints: %v", unsatisfied, placementErr)
+		return
+	}
+
+	if zones[zoneName].Skewed {
+		conditions.MarkFalse(vmCtx.VM, vmopv1.VirtualMachineTopologySpreadCondition,
+			vmopv1.TopologySpreadConstraintsSkewedReason, vmopv1.ConditionSeverityWarning,
+			"Zone %s exceeds the maximum skew of the ScheduleAnyway topology spread constraints", zoneName)
+		return
+	}
+
+	conditions.MarkTrue(vmCtx.VM, vmopv1.VirtualMachineTopologySpreadCondition)
+}
@@ -0,0 +1,91 @@
+// Copyright (c) 2023 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package placement_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	vmopv1 "github.com/vmware-tanzu/vm-operator/api/v1alpha1"
+	"github.com/vmware-tanzu/vm-operator/pkg/vmprovider/providers/vsphere/placement"
+)
+
+var _ = Describe("EvaluateTopologySpread", func() {
+
+	var (
+		constraint vmopv1.VirtualMachineTopologySpreadConstraint
+		vmLabels   map[string]string
+		peers      []placement.AffinityPeer
+		zoneNames  []string
+	)
+
+	dbPeer := func(zone string) placement.AffinityPeer {
+		return placement.AffinityPeer{Labels: map[string]string{"app": "db"}, Zone: zone}
+	}
+
+	BeforeEach(func() {
+		constraint = vmopv1.VirtualMachineTopologySpreadConstraint{
+			MaxSkew:           1,
+			TopologyKey:       vmopv1.VirtualMachineAffinityZoneTopologyKey,
+			WhenUnsatisfiable: vmopv1.VirtualMachineDoNotSchedule,
+			LabelSelector:     &metav1.LabelSelector{MatchLabels: map[string]string{"app": "db"}},
+		}
+		vmLabels = map[string]string{"app": "db"}
+		peers = []placement.AffinityPeer{
+			dbPeer("zone1"),
+			dbPeer("zone1"),
+			dbPeer("zone2"),
+			{Labels: map[string]string{"app": "web"}, Zone: "zone3"},
+			dbPeer("not-a-candidate"),
+		}
+		zoneNames = []string{"zone1", "zone2", "zone3"}
+	})
+
+	It("does not satisfy DoNotSchedule constraints in zones that exceed the max skew", func() {
+		zones, err := placement.EvaluateTopologySpread(
+			[]vmopv1.VirtualMachineTopologySpreadConstraint{constraint}, vmLabels, peers, zoneNames)
+		Expect(err).ToNot(HaveOccurred())
+
+		// The counts are zone1=2, zone2=1, and zone3=0 so the skews are 3, 2 and 1.
+		Expect(zones).To(HaveLen(3))
+		Expect(zones["zone1"].Satisfied).To(BeFalse())
+		Expect(zones["zone2"].Satisfied).To(BeFalse())
+		Expect(zones["zone3"].Satisfied).To(BeTrue())
+	})
+
+	It("does not count the VM when it is not selected", func() {
+		vmLabels = nil
+		constraint.MaxSkew = 2
+
+		zones, err := placement.EvaluateTopologySpread(
+			[]vmopv1.VirtualMachineTopologySpreadConstraint{constraint}, vmLabels, peers, zoneNames)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(zones["zone1"].Satisfied).To(BeTrue())
+		Expect(zones["zone2"].Satisfied).To(BeTrue())
+		Expect(zones["zone3"].Satisfied).To(BeTrue())
+	})
+
+	It("sums the skew of ScheduleAnyway constraints", func() {
+		constraint.WhenUnsatisfiable = vmopv1.VirtualMachineScheduleAnyway
+
+		zones, err := placement.EvaluateTopologySpread(
+			[]vmopv1.VirtualMachineTopologySpreadConstraint{constraint, constraint}, vmLabels, peers, zoneNames)
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(zones["zone1"]).To(Equal(placement.TopologySpreadZone{Satisfied: true, Skewed: true, Skew: 6}))
+		Expect(zones["zone2"]).To(Equal(placement.TopologySpreadZone{Satisfied: true, Skewed: true, Skew: 4}))
+		Expect(zones["zone3"]).To(Equal(placement.TopologySpreadZone{Satisfied: true, Skewed: false, Skew: 2}))
+	})
+
+	It("returns an error for an invalid label selector", func() {
+		constraint.LabelSelector = &metav1.LabelSelector{
+			MatchExpressions: []metav1.LabelSelectorRequirement{{Key: "app", Operator: "Foo"}},
+		}
+		_, err := placement.EvaluateTopologySpread(
+			[]vmopv1.VirtualMachineTopologySpreadConstraint{constraint}, vmLabels, peers, zoneNames)
+		Expect(err).To(HaveOccurred())
+	})
+})
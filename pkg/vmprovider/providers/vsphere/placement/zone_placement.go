@@ -1,4 +1,4 @@
-// Copyright (c) 2022 VMware, Inc. All Rights Reserved.
+// Copyright (c) 2022-2023 VMware, Inc. All Rights Reserved.
 // SPDX-License-Identifier: Apache-2.0
 
 package placement
@@ -25,12 +25,21 @@ import (
 type Result struct {
 	ZonePlacement            bool
 	InstanceStoragePlacement bool
+	HostAffinityPlacement    bool
+	PCIDevicePlacement       bool
 	ZoneName                 string
 	HostMoRef                *types.ManagedObjectReference
 	PoolMoRef                types.ManagedObjectReference
+	// Explanation describes why the placement was selected, if it was selected here.
+	Explanation string
 	// TODO: Datastore, whatever else as we need it.
 }
 
+// ZoneClients are the clients of the vCenters that manage the zones a VM may be placed in, by zone name.
+// The zones of different vCenters have the clients of their vCenter, and a zone without a client is not a
+// placement candidate.
+type ZoneClients map[string]*vim25.Client
+
 func doesVMNeedPlacement(vmCtx context.VirtualMachineContext) (res Result, needZonePlacement, needInstanceStoragePlacement bool) {
 	if lib.IsWcpFaultDomainsFSSEnabled() {
 		res.ZonePlacement = true
@@ -87,11 +96,12 @@ func lookupChildRPs(
 	return childRPMoIDs
 }
 
-// getPlacementCandidates determines the candidate resource pools for VM placement.
+// getPlacementCandidates determines the candidate resource pools for VM placement. When the zone is
+// selected here, the zones of every vCenter are candidates.
 func getPlacementCandidates(
 	vmCtx context.VirtualMachineContext,
 	client ctrlclient.Client,
-	vcClient *vim25.Client,
+	zoneClients ZoneClients,
 	zonePlacement bool,
 	childRPName string) (map[string][]string, error) {
 
@@ -123,6 +133,12 @@ func getPlacementCandidates(
 			continue
 		}
 
+		vcClient, ok := zoneClients[zone.Name]
+		if !ok {
+			vmCtx.Logger.Info("Skipping zone since the client of its vCenter is not available", "zone", zone.Name)
+			continue
+		}
+
 		var rpMoIDs []string
 		if len(nsInfo.PoolMoIDs) != 0 {
 			rpMoIDs = nsInfo.PoolMoIDs
@@ -160,15 +176,18 @@ func rpMoIDToCluster(
 }
 
 // getPlacementRecommendations calls DRS PlaceVM to determine clusters suitable for placement.
+// When there are host filters, DRS may only place the VM on the hosts allowed by every filter.
 func getPlacementRecommendations(
 	vmCtx context.VirtualMachineContext,
-	vcClient *vim25.Client,
+	zoneClients ZoneClients,
 	candidates map[string][]string,
-	configSpec *types.VirtualMachineConfigSpec) map[string][]Recommendation {
+	configSpec *types.VirtualMachineConfigSpec,
+	hostFilters ...hostFilter) map[string][]Recommendation {
 
 	recommendations := map[string][]Recommendation{}
 
 	for zoneName, rpMoIDs := range candidates {
+		vcClient := zoneClients[zoneName]
 		for _, rpMoID := range rpMoIDs {
 			rpMoRef := types.ManagedObjectReference{Type: "ResourcePool", Value: rpMoID}
 
@@ -178,7 +197,22 @@ func getPlacementRecommendations(
 				continue
 			}
 
-			recs, err := PlaceVMForCreate(vmCtx, cluster, configSpec)
+			var hosts []types.ManagedObjectReference
+			if len(hostFilters) > 0 {
+				hosts, err = filterClusterHosts(vmCtx, cluster, zoneName, hostFilters)
+				if err != nil {
+					vmCtx.Logger.Error(err, "failed to get hosts allowed by the host filters", "zone", zoneName,
+						"clusterMoID", cluster.Reference().Value, "rpMoID", rpMoID)
+					continue
+				}
+				if len(hosts) == 0 {
+					vmCtx.Logger.Info("No hosts are allowed by the host filters", "zone", zoneName,
+						"clusterMoID", cluster.Reference().Value, "rpMoID", rpMoID)
+					continue
+				}
+			}
+
+			recs, err := PlaceVMForCreate(vmCtx, cluster, configSpec, hosts...)
 			if err != nil {
 				vmCtx.Logger.Error(err, "PlaceVM failed", "zone", zoneName,
 					"clusterMoID", cluster.Reference().Value, "rpMoID", rpMoID)
@@ -208,59 +242,113 @@ func getPlacementRecommendations(
 	return recommendations
 }
 
+// filterClusterHosts returns the hosts of the cluster that are allowed by every filter.
+func filterClusterHosts(
+	vmCtx context.VirtualMachineContext,
+	cluster *object.ClusterComputeResource,
+	zoneName string,
+	hostFilters []hostFilter) ([]types.ManagedObjectReference, error) {
+
+	clusterHosts, err := cluster.Hosts(vmCtx)
+	if err != nil {
+		return nil, err
+	}
+
+	hosts := make([]types.ManagedObjectReference, 0, len(clusterHosts))
+	for _, host := range clusterHosts {
+		hosts = append(hosts, host.Reference())
+	}
+
+	for _, f := range hostFilters {
+		if len(hosts) == 0 {
+			break
+		}
+		if hosts, err = f.filterHosts(vmCtx, zoneName, hosts); err != nil {
+			return nil, err
+		}
+	}
+
+	return hosts, nil
+}
+
+// vcPlacementCandidates are the candidate resource pools of the zones of one vCenter.
+type vcPlacementCandidates struct {
+	vcClient   *vim25.Client
+	rpMoRefs   []types.ManagedObjectReference
+	rpMOToZone map[types.ManagedObjectReference]string
+}
+
 // getZonalPlacementRecommendations calls DRS PlaceVmsXCluster to determine clusters suitable for placement.
+// PlaceVmsXCluster only places a VM among the clusters of its vCenter, so it is called for the candidates
+// of each vCenter.
 func getZonalPlacementRecommendations(
 	vmCtx context.VirtualMachineContext,
-	vcClient *vim25.Client,
+	zoneClients ZoneClients,
 	candidates map[string][]string,
 	configSpec *types.VirtualMachineConfigSpec,
 	needsHost bool) map[string][]Recommendation {
 
-	rpMOToZone := map[types.ManagedObjectReference]string{}
-	var candidateRPMoRefs []types.ManagedObjectReference
+	var vcCandidates []*vcPlacementCandidates
+	byVCClient := map[*vim25.Client]*vcPlacementCandidates{}
+	numCandidates := 0
 
 	for zoneName, rpMoIDs := range candidates {
+		vcClient := zoneClients[zoneName]
+		c, ok := byVCClient[vcClient]
+		if !ok {
+			c = &vcPlacementCandidates{
+				vcClient:   vcClient,
+				rpMOToZone: map[types.ManagedObjectReference]string{},
+			}
+			byVCClient[vcClient] = c
+			vcCandidates = append(vcCandidates, c)
+		}
+
 		for _, rpMoID := range rpMoIDs {
 			rpMoRef := types.ManagedObjectReference{Type: "ResourcePool", Value: rpMoID}
-			candidateRPMoRefs = append(candidateRPMoRefs, rpMoRef)
-			rpMOToZone[rpMoRef] = zoneName
+			c.rpMoRefs = append(c.rpMoRefs, rpMoRef)
+			c.rpMOToZone[rpMoRef] = zoneName
+			numCandidates++
 		}
 	}
 
-	var recs []Recommendation
+	recommendations := map[string][]Recommendation{}
 
-	if len(candidateRPMoRefs) == 1 {
-		// If there is only one candidate, we might be able to skip some work.
+	for _, c := range vcCandidates {
+		var recs []Recommendation
 
-		if needsHost {
-			// This is a hack until PlaceVmsXCluster() supports instance storage disks.
-			vmCtx.Logger.Info("Falling back into non-zonal placement since the only candidate needs host selected",
-				"rpMoID", candidateRPMoRefs[0].Value)
-			return getPlacementRecommendations(vmCtx, vcClient, candidates, configSpec)
-		}
+		if numCandidates == 1 {
+			// If there is only one candidate, we might be able to skip some work.
 
-		recs = append(recs, Recommendation{
-			PoolMoRef: candidateRPMoRefs[0],
-		})
-		vmCtx.Logger.V(5).Info("Implied placement since there was only one candidate", "rec", recs[0])
+			if needsHost {
+				// This is a hack until PlaceVmsXCluster() supports instance storage disks.
+				vmCtx.Logger.Info("Falling back into non-zonal placement since the only candidate needs host selected",
+					"rpMoID", c.rpMoRefs[0].Value)
+				return getPlacementRecommendations(vmCtx, zoneClients, candidates, configSpec)
+			}
 
-	} else {
-		var err error
+			recs = append(recs, Recommendation{
+				PoolMoRef: c.rpMoRefs[0],
+			})
+			vmCtx.Logger.V(5).Info("Implied placement since there was only one candidate", "rec", recs[0])
 
-		recs, err = ClusterPlaceVMForCreate(vmCtx, vcClient, candidateRPMoRefs, configSpec, needsHost)
-		if err != nil {
-			vmCtx.Logger.Error(err, "PlaceVmsXCluster failed")
-			return nil
+		} else {
+			var err error
+
+			recs, err = ClusterPlaceVMForCreate(vmCtx, c.vcClient, c.rpMoRefs, configSpec, needsHost)
+			if err != nil {
+				vmCtx.Logger.Error(err, "PlaceVmsXCluster failed")
+				continue
+			}
 		}
-	}
 
-	recommendations := map[string][]Recommendation{}
-	for _, rec := range recs {
-		if rpZoneName, ok := rpMOToZone[rec.PoolMoRef]; ok {
-			recommendations[rpZoneName] = append(recommendations[rpZoneName], rec)
-		} else {
-			vmCtx.Logger.V(4).Info("Received unexpected ResourcePool recommendation",
-				"poolMoRef", rec.PoolMoRef)
+		for _, rec := range recs {
+			if rpZoneName, ok := c.rpMOToZone[rec.PoolMoRef]; ok {
+				recommendations[rpZoneName] = append(recommendations[rpZoneName], rec)
+			} else {
+				vmCtx.Logger.V(4).Info("Received unexpected ResourcePool recommendation",
+					"poolMoRef", rec.PoolMoRef)
+			}
 		}
 	}
 
@@ -269,6 +357,23 @@ func getZonalPlacementRecommendations(
 	return recommendations
 }
 
+// getStorageProfileID returns the ID of the storage profile of the first disk added by the
+// ConfigSpec, which is the storage profile of the VM.
+func getStorageProfileID(configSpec *types.VirtualMachineConfigSpec) string {
+	for _, dc := range configSpec.DeviceChange {
+		spec := dc.GetVirtualDeviceConfigSpec()
+		if _, ok := spec.Device.(*types.VirtualDisk); !ok || spec.Operation != types.VirtualDeviceConfigSpecOperationAdd {
+			continue
+		}
+		for _, p := range spec.Profile {
+			if profile, ok := p.(*types.VirtualMachineDefinedProfileSpec); ok {
+				return profile.ProfileId
+			}
+		}
+	}
+	return ""
+}
+
 // MakePlacementDecision selects one of the recommendations for placement.
 func MakePlacementDecision(recommendations map[string][]Recommendation) (string, Recommendation) {
 	// Use an explicit rand.Intn() instead of first entry returned by map iterator.
@@ -283,20 +388,38 @@ func MakePlacementDecision(recommendations map[string][]Recommendation) (string,
 }
 
 // Placement determines if the VM needs placement, and if so, determines where to place the VM
-// and updates the Labels and Annotations with the placement decision.
+// and updates the Labels and Annotations with the placement decision. The recommendation with the
+// highest score of the scorers with the weights is selected, among the recommendations that best
+// satisfy the affinity terms of the VM when it has any.
+// When the weights are nil, the DefaultScorerWeights are used. The zone is selected from the zones of
+// every vCenter that has a client in zoneClients.
 func Placement(
 	vmCtx context.VirtualMachineContext,
 	client ctrlclient.Client,
-	vcClient *vim25.Client,
+	zoneClients ZoneClients,
 	configSpec *types.VirtualMachineConfigSpec,
-	childRPName string) (*Result, error) {
+	childRPName string,
+	weights ScorerWeights) (*Result, error) {
 
 	existingRes, zonePlacement, instanceStoragePlacement := doesVMNeedPlacement(vmCtx)
-	if !zonePlacement && !instanceStoragePlacement {
+
+	// The host must be selected here for a VM with host affinity terms since
+	// they are evaluated against the hosts of the other VMs.
+	hostAffinityPlacement := existingRes.HostMoRef == nil && HasHostAffinity(vmCtx.VM)
+
+	// The host must also be selected here for a VM with vGPU or Dynamic DirectPath I/O
+	// devices so the VM is not placed on a host that does not have the devices available.
+	var pciDeviceRequirements []PCIDeviceRequirement
+	if existingRes.HostMoRef == nil {
+		pciDeviceRequirements = GetPCIDeviceRequirements(configSpec)
+	}
+	pciDevicePlacement := len(pciDeviceRequirements) > 0
+
+	if !zonePlacement && !instanceStoragePlacement && !hostAffinityPlacement && !pciDevicePlacement {
 		return &existingRes, nil
 	}
 
-	candidates, err := getPlacementCandidates(vmCtx, client, vcClient, zonePlacement, childRPName)
+	candidates, err := getPlacementCandidates(vmCtx, client, zoneClients, zonePlacement, childRPName)
 	if err != nil {
 		return nil, err
 	}
@@ -305,28 +428,123 @@ func Placement(
 		return nil, fmt.Errorf("no placement candidates available")
 	}
 
-	// TBD: May want to get the host for vGPU and other passthru devices too.
-	needsHost := instanceStoragePlacement
+	needsHost := instanceStoragePlacement || hostAffinityPlacement || pciDevicePlacement
 
-	var recommendations map[string][]Recommendation
-	if zonePlacement {
-		recommendations = getZonalPlacementRecommendations(vmCtx, vcClient, candidates, configSpec, needsHost)
-	} else /* instanceStoragePlacement */ {
-		recommendations = getPlacementRecommendations(vmCtx, vcClient, candidates, configSpec)
+	// The topology spread constraints are only evaluated when the zone is selected here.
+	topologySpread := zonePlacement && len(vmCtx.VM.Spec.TopologySpreadConstraints) > 0
+
+	if weights == nil {
+		weights = DefaultScorerWeights()
+	}
+
+	var peers []AffinityPeer
+	if HasAffinity(vmCtx.VM) || topologySpread || weights[LeastVMsInNamespaceScorerName] > 0 {
+		peers, err = getAffinityPeers(vmCtx, client)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if zonePlacement && HasAffinity(vmCtx.VM) {
+		// PlaceVmsXCluster may only recommend one zone so exclude the zones that do not
+		// satisfy the required zone affinity terms beforehand.
+		candidates, err = filterAffinityZoneCandidates(vmCtx, peers, candidates)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// The candidates are tried in order until a placement decision is made.
+	candidateSets := []map[string][]string{candidates}
+
+	var spreadZones map[string]TopologySpreadZone
+	if topologySpread {
+		spreadZones, candidateSets, err = getTopologySpreadCandidateSets(vmCtx, peers, candidates)
+		if err != nil {
+			return nil, err
+		}
 	}
-	if len(recommendations) == 0 {
-		return nil, fmt.Errorf("no placement recommendations available")
+
+	// PlaceVmsXCluster cannot be limited to the hosts allowed by the host filters.
+	var hostFilters []hostFilter
+	if hostAffinityPlacement {
+		hostFilters = append(hostFilters,
+			&affinityHostFilter{zoneClients: zoneClients, spec: vmCtx.VM.Spec.Affinity, peers: peers})
+	}
+	var pciFilter *pciDeviceHostFilter
+	if pciDevicePlacement {
+		pciFilter = newPCIDeviceHostFilter(zoneClients, pciDeviceRequirements)
+		hostFilters = append(hostFilters, pciFilter)
+	}
+
+	getRecommendations := func(candidates map[string][]string) map[string][]Recommendation {
+		switch {
+		case len(hostFilters) > 0:
+			return getPlacementRecommendations(vmCtx, zoneClients, candidates, configSpec, hostFilters...)
+		case zonePlacement:
+			return getZonalPlacementRecommendations(vmCtx, zoneClients, candidates, configSpec, needsHost)
+		default: /* instanceStoragePlacement */
+			return getPlacementRecommendations(vmCtx, zoneClients, candidates, configSpec)
+		}
+	}
+
+	scoringCtx := ScoringContext{
+		VirtualMachineContext: vmCtx,
+		ZoneClients:           zoneClients,
+		Peers:                 peers,
+		StorageProfileID:      getStorageProfileID(configSpec),
+	}
+
+	var zoneName, explanation string
+	var rec Recommendation
+	for _, candidates := range candidateSets {
+		recommendations := getRecommendations(candidates)
+		if len(recommendations) == 0 {
+			err = fmt.Errorf("no placement recommendations available")
+			continue
+		}
+
+		if HasAffinity(vmCtx.VM) {
+			recommendations, err = filterAffinityRecommendations(vmCtx, zoneClients, peers, recommendations)
+			if err != nil {
+				continue
+			}
+		}
+
+		decision, scoreErr := MakeScoredPlacementDecision(scoringCtx, recommendations, weights)
+		if scoreErr != nil {
+			vmCtx.Logger.Error(scoreErr, "Failed to score placement recommendations")
+			zoneName, rec = MakePlacementDecision(recommendations)
+		} else {
+			zoneName, rec = decision.ZoneName, decision.Recommendation
+			explanation = decision.Explanation(weights)
+		}
+		err = nil
+		break
+	}
+
+	if topologySpread {
+		setTopologySpreadCondition(vmCtx, spreadZones, zoneName, err)
+	}
+	if pciFilter != nil {
+		pciFilter.setCondition(vmCtx, err)
+	}
+	if err != nil {
+		return nil, err
 	}
 
-	zoneName, rec := MakePlacementDecision(recommendations)
-	vmCtx.Logger.V(5).Info("Placement decision result", "zone", zoneName, "recommendation", rec)
+	vmCtx.Logger.V(5).Info("Placement decision result", "zone", zoneName, "recommendation", rec,
+		"explanation", explanation)
 
 	result := &Result{
 		ZonePlacement:            zonePlacement,
 		InstanceStoragePlacement: instanceStoragePlacement,
+		HostAffinityPlacement:    hostAffinityPlacement,
+		PCIDevicePlacement:       pciDevicePlacement,
 		ZoneName:                 zoneName,
 		PoolMoRef:                rec.PoolMoRef,
 		HostMoRef:                rec.HostMoRef,
+		Explanation:              explanation,
 	}
 
 	return result, nil
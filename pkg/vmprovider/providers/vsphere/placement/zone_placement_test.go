@@ -1,17 +1,25 @@
-// Copyright (c) 2022 VMware, Inc. All Rights Reserved.
+// Copyright (c) 2022-2023 VMware, Inc. All Rights Reserved.
 // SPDX-License-Identifier: Apache-2.0
 
 package placement_test
 
 import (
+	"fmt"
+
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
 
+	"github.com/vmware/govmomi/object"
+	"github.com/vmware/govmomi/simulator"
+	"github.com/vmware/govmomi/vim25"
 	"github.com/vmware/govmomi/vim25/types"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	vmopv1 "github.com/vmware-tanzu/vm-operator/api/v1alpha1"
 
+	"github.com/vmware-tanzu/vm-operator/pkg/conditions"
 	"github.com/vmware-tanzu/vm-operator/pkg/context"
 	"github.com/vmware-tanzu/vm-operator/pkg/topology"
 	"github.com/vmware-tanzu/vm-operator/pkg/vmprovider/providers/vsphere/constants"
@@ -71,9 +79,10 @@ func vcSimPlacement() {
 		nsInfo      builder.WorkloadNamespaceInfo
 		testConfig  builder.VCSimTestConfig
 
-		vm         *vmopv1.VirtualMachine
-		vmCtx      context.VirtualMachineContext
-		configSpec *types.VirtualMachineConfigSpec
+		vm          *vmopv1.VirtualMachine
+		vmCtx       context.VirtualMachineContext
+		configSpec  *types.VirtualMachineConfigSpec
+		zoneClients placement.ZoneClients
 	)
 
 	BeforeEach(func() {
@@ -94,6 +103,11 @@ func vcSimPlacement() {
 
 		vm.Namespace = nsInfo.Namespace
 
+		zoneClients = placement.ZoneClients{topology.DefaultAvailabilityZoneName: ctx.VCClient.Client}
+		for _, zoneName := range ctx.ZoneNames {
+			zoneClients[zoneName] = ctx.VCClient.Client
+		}
+
 		vmCtx = context.VirtualMachineContext{
 			Context: ctx,
 			Logger:  suite.GetLogger().WithValues("vmName", vm.Name),
@@ -120,7 +134,7 @@ func vcSimPlacement() {
 			})
 
 			It("returns success with same zone", func() {
-				result, err := placement.Placement(vmCtx, ctx.Client, ctx.VCClient.Client, configSpec, "")
+				result, err := placement.Placement(vmCtx, ctx.Client, zoneClients, configSpec, "", nil)
 				Expect(err).ToNot(HaveOccurred())
 				Expect(result).ToNot(BeNil())
 				Expect(result.ZonePlacement).To(BeTrue())
@@ -140,33 +154,96 @@ func vcSimPlacement() {
 			})
 
 			It("returns an error", func() {
-				result, err := placement.Placement(vmCtx, ctx.Client, ctx.VCClient.Client, configSpec, "")
+				result, err := placement.Placement(vmCtx, ctx.Client, zoneClients, configSpec, "", nil)
 				Expect(err).To(MatchError("no placement candidates available"))
 				Expect(result).To(BeNil())
 			})
 		})
 
 		It("returns success", func() {
-			result, err := placement.Placement(vmCtx, ctx.Client, ctx.VCClient.Client, configSpec, "")
+			result, err := placement.Placement(vmCtx, ctx.Client, zoneClients, configSpec, "", nil)
 			Expect(err).ToNot(HaveOccurred())
 
 			Expect(result.ZonePlacement).To(BeTrue())
 			Expect(result.ZoneName).To(BeElementOf(ctx.ZoneNames))
 			Expect(result.PoolMoRef.Value).ToNot(BeEmpty())
 			Expect(result.HostMoRef).To(BeNil())
+			Expect(result.Explanation).To(HavePrefix(fmt.Sprintf("zone=%q pool=%s", result.ZoneName, result.PoolMoRef.Value)))
+			for _, name := range []string{
+				placement.DRSRatingScorerName,
+				placement.ZoneFreeCapacityScorerName,
+				placement.DatastoreFreeSpaceScorerName,
+				placement.LeastVMsInNamespaceScorerName,
+			} {
+				Expect(result.Explanation).To(ContainSubstring(name))
+			}
 
 			nsRP := ctx.GetResourcePoolForNamespace(vm.Namespace, result.ZoneName, "")
 			Expect(nsRP).ToNot(BeNil())
 			Expect(result.PoolMoRef.Value).To(Equal(nsRP.Reference().Value))
 		})
 
+		It("scores the datastores compatible with the storage profile", func() {
+			configSpec.DeviceChange = append(configSpec.DeviceChange, &types.VirtualDeviceConfigSpec{
+				Operation: types.VirtualDeviceConfigSpecOperationAdd,
+				Device:    &types.VirtualDisk{},
+				Profile: []types.BaseVirtualMachineProfileSpec{
+					&types.VirtualMachineDefinedProfileSpec{ProfileId: ctx.StorageProfileID},
+				},
+			})
+
+			weights := placement.ScorerWeights{placement.DatastoreFreeSpaceScorerName: 1}
+			result, err := placement.Placement(vmCtx, ctx.Client, zoneClients, configSpec, "", weights)
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(result.ZoneName).To(BeElementOf(ctx.ZoneNames))
+			Expect(result.Explanation).To(ContainSubstring(placement.DatastoreFreeSpaceScorerName + "=1.00x1"))
+		})
+
+		Context("zones are managed by different vCenters", func() {
+			JustBeforeEach(func() {
+				// All the zones but the first are managed by another vCenter, which is the same vcsim
+				// instance with another client.
+				vc2Client, err := vim25.NewClient(ctx, ctx.VCClient.Client.RoundTripper)
+				Expect(err).ToNot(HaveOccurred())
+				for _, zoneName := range ctx.ZoneNames[1:] {
+					zoneClients[zoneName] = vc2Client
+				}
+			})
+
+			It("selects a zone of any vCenter", func() {
+				result, err := placement.Placement(vmCtx, ctx.Client, zoneClients, configSpec, "", nil)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(result.ZonePlacement).To(BeTrue())
+				Expect(result.ZoneName).To(BeElementOf(ctx.ZoneNames))
+
+				nsRP := ctx.GetResourcePoolForNamespace(vm.Namespace, result.ZoneName, "")
+				Expect(nsRP).ToNot(BeNil())
+				Expect(result.PoolMoRef.Value).To(Equal(nsRP.Reference().Value))
+			})
+
+			It("only selects a zone that has a vCenter client", func() {
+				delete(zoneClients, ctx.ZoneNames[0])
+				result, err := placement.Placement(vmCtx, ctx.Client, zoneClients, configSpec, "", nil)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(result.ZonePlacement).To(BeTrue())
+				Expect(result.ZoneName).To(BeElementOf(ctx.ZoneNames[1:]))
+			})
+
+			It("returns an error when no zone has a vCenter client", func() {
+				result, err := placement.Placement(vmCtx, ctx.Client, placement.ZoneClients{}, configSpec, "", nil)
+				Expect(err).To(MatchError("no placement candidates available"))
+				Expect(result).To(BeNil())
+			})
+		})
+
 		Context("Only one zone exists", func() {
 			BeforeEach(func() {
 				testConfig.NumFaultDomains = 1
 			})
 
 			It("returns success", func() {
-				result, err := placement.Placement(vmCtx, ctx.Client, ctx.VCClient.Client, configSpec, "")
+				result, err := placement.Placement(vmCtx, ctx.Client, zoneClients, configSpec, "", nil)
 				Expect(err).ToNot(HaveOccurred())
 
 				Expect(result.ZonePlacement).To(BeTrue())
@@ -188,7 +265,7 @@ func vcSimPlacement() {
 				Expect(childRPName).ToNot(BeEmpty())
 				vmCtx.VM.Spec.ResourcePolicyName = resourcePolicy.Name
 
-				result, err := placement.Placement(vmCtx, ctx.Client, ctx.VCClient.Client, configSpec, childRPName)
+				result, err := placement.Placement(vmCtx, ctx.Client, zoneClients, configSpec, childRPName, nil)
 				Expect(err).ToNot(HaveOccurred())
 
 				Expect(result.ZonePlacement).To(BeTrue())
@@ -199,6 +276,273 @@ func vcSimPlacement() {
 				Expect(result.PoolMoRef.Value).To(Equal(childRP.Reference().Value))
 			})
 		})
+
+		Context("VM has zone anti-affinity", func() {
+			BeforeEach(func() {
+				vm.Labels["app"] = "db"
+				vm.Spec.Affinity = &vmopv1.VirtualMachineAffinitySpec{
+					VMAntiAffinity: &vmopv1.VirtualMachineAffinityRules{
+						RequiredDuringSchedulingIgnoredDuringExecution: []vmopv1.VirtualMachineAffinityTerm{
+							{
+								LabelSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "db"}},
+								TopologyKey:   vmopv1.VirtualMachineAffinityZoneTopologyKey,
+							},
+						},
+					},
+				}
+			})
+
+			It("returns a zone without the selected VMs", func() {
+				Expect(len(ctx.ZoneNames)).To(BeNumerically(">", 1))
+
+				peer := builder.DummyVirtualMachine()
+				peer.Name = "placement-test-peer"
+				peer.Namespace = vm.Namespace
+				peer.Labels = map[string]string{"app": "db", topology.KubernetesTopologyZoneLabelKey: ctx.ZoneNames[0]}
+				Expect(ctx.Client.Create(ctx, peer)).To(Succeed())
+
+				result, err := placement.Placement(vmCtx, ctx.Client, zoneClients, configSpec, "", nil)
+				Expect(err).ToNot(HaveOccurred())
+
+				Expect(result.ZonePlacement).To(BeTrue())
+				Expect(result.ZoneName).To(BeElementOf(ctx.ZoneNames))
+				Expect(result.ZoneName).ToNot(Equal(ctx.ZoneNames[0]))
+			})
+
+			It("scores the zones without the selected VMs", func() {
+				Expect(len(ctx.ZoneNames)).To(BeNumerically(">", 2))
+
+				peer := builder.DummyVirtualMachine()
+				peer.Name = "placement-test-peer"
+				peer.Namespace = vm.Namespace
+				peer.Labels = map[string]string{"app": "db", topology.KubernetesTopologyZoneLabelKey: ctx.ZoneNames[0]}
+				Expect(ctx.Client.Create(ctx, peer)).To(Succeed())
+
+				other := builder.DummyVirtualMachine()
+				other.Name = "placement-test-other"
+				other.Namespace = vm.Namespace
+				other.Labels = map[string]string{topology.KubernetesTopologyZoneLabelKey: ctx.ZoneNames[1]}
+				Expect(ctx.Client.Create(ctx, other)).To(Succeed())
+
+				// PlaceVmsXCluster only recommends one zone, so add a host anti-affinity term for the VM to
+				// be placed with PlaceVM, which recommends the hosts of every remaining zone.
+				antiAffinity := vm.Spec.Affinity.VMAntiAffinity
+				antiAffinity.RequiredDuringSchedulingIgnoredDuringExecution = append(
+					antiAffinity.RequiredDuringSchedulingIgnoredDuringExecution,
+					vmopv1.VirtualMachineAffinityTerm{
+						LabelSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "db"}},
+						TopologyKey:   vmopv1.VirtualMachineAffinityHostTopologyKey,
+					})
+
+				weights := placement.ScorerWeights{placement.LeastVMsInNamespaceScorerName: 1}
+				result, err := placement.Placement(vmCtx, ctx.Client, zoneClients, configSpec, "", weights)
+				Expect(err).ToNot(HaveOccurred())
+
+				Expect(result.ZonePlacement).To(BeTrue())
+				Expect(result.ZoneName).To(Equal(ctx.ZoneNames[2]))
+				Expect(result.Explanation).To(ContainSubstring(placement.LeastVMsInNamespaceScorerName))
+			})
+		})
+
+		Context("VM has topology spread constraints", func() {
+			var whenUnsatisfiable vmopv1.VirtualMachineUnsatisfiableConstraintAction
+
+			BeforeEach(func() {
+				whenUnsatisfiable = vmopv1.VirtualMachineDoNotSchedule
+				vm.Labels["app"] = "db"
+			})
+
+			JustBeforeEach(func() {
+				vm.Spec.TopologySpreadConstraints = []vmopv1.VirtualMachineTopologySpreadConstraint{
+					{
+						MaxSkew:           1,
+						TopologyKey:       vmopv1.VirtualMachineAffinityZoneTopologyKey,
+						WhenUnsatisfiable: whenUnsatisfiable,
+						LabelSelector:     &metav1.LabelSelector{MatchLabels: map[string]string{"app": "db"}},
+					},
+				}
+
+				Expect(len(ctx.ZoneNames)).To(BeNumerically(">", 1))
+
+				// Place a selected VM in every zone but the last.
+				for i, zoneName := range ctx.ZoneNames[:len(ctx.ZoneNames)-1] {
+					peer := builder.DummyVirtualMachine()
+					peer.Name = fmt.Sprintf("placement-test-peer-%d", i)
+					peer.Namespace = vm.Namespace
+					peer.Labels = map[string]string{"app": "db", topology.KubernetesTopologyZoneLabelKey: zoneName}
+					Expect(ctx.Client.Create(ctx, peer)).To(Succeed())
+				}
+			})
+
+			It("returns the zone that satisfies the constraints", func() {
+				result, err := placement.Placement(vmCtx, ctx.Client, zoneClients, configSpec, "", nil)
+				Expect(err).ToNot(HaveOccurred())
+
+				Expect(result.ZonePlacement).To(BeTrue())
+				Expect(result.ZoneName).To(Equal(ctx.ZoneNames[len(ctx.ZoneNames)-1]))
+				Expect(conditions.IsTrue(vm, vmopv1.VirtualMachineTopologySpreadCondition)).To(BeTrue())
+			})
+
+			When("the constraints are ScheduleAnyway", func() {
+				BeforeEach(func() {
+					whenUnsatisfiable = vmopv1.VirtualMachineScheduleAnyway
+				})
+
+				It("returns the least skewed zone", func() {
+					result, err := placement.Placement(vmCtx, ctx.Client, zoneClients, configSpec, "", nil)
+					Expect(err).ToNot(HaveOccurred())
+
+					Expect(result.ZonePlacement).To(BeTrue())
+					Expect(result.ZoneName).To(Equal(ctx.ZoneNames[len(ctx.ZoneNames)-1]))
+					Expect(conditions.IsTrue(vm, vmopv1.VirtualMachineTopologySpreadCondition)).To(BeTrue())
+				})
+			})
+		})
+	})
+
+	Context("Host affinity placement", func() {
+		var hostNames []string
+
+		BeforeEach(func() {
+			vm.Spec.Affinity = &vmopv1.VirtualMachineAffinitySpec{
+				VMAntiAffinity: &vmopv1.VirtualMachineAffinityRules{
+					RequiredDuringSchedulingRequiredDuringExecution: []vmopv1.VirtualMachineAffinityTerm{
+						{
+							LabelSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "db"}},
+							TopologyKey:   vmopv1.VirtualMachineAffinityHostTopologyKey,
+						},
+					},
+				},
+			}
+		})
+
+		JustBeforeEach(func() {
+			hosts, err := ctx.GetSingleClusterCompute().Hosts(ctx)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(hosts).ToNot(BeEmpty())
+
+			hostNames = nil
+			for _, host := range hosts {
+				name, err := host.ObjectName(ctx)
+				Expect(err).ToNot(HaveOccurred())
+				hostNames = append(hostNames, name)
+			}
+		})
+
+		createPeers := func(labels map[string]string) {
+			for i, hostName := range hostNames {
+				peer := builder.DummyVirtualMachine()
+				peer.Name = fmt.Sprintf("placement-test-peer-%d", i)
+				peer.Namespace = vm.Namespace
+				peer.Labels = labels
+				peer.Status.Host = hostName
+				Expect(ctx.Client.Create(ctx, peer)).To(Succeed())
+			}
+		}
+
+		It("returns a host when the selected VMs do not exclude any hosts", func() {
+			createPeers(map[string]string{"app": "web"})
+
+			result, err := placement.Placement(vmCtx, ctx.Client, zoneClients, configSpec, "", nil)
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(result.HostAffinityPlacement).To(BeTrue())
+			Expect(result.HostMoRef).ToNot(BeNil())
+			Expect(result.PoolMoRef.Value).ToNot(BeEmpty())
+
+			name, err := object.NewHostSystem(ctx.VCClient.Client, *result.HostMoRef).ObjectName(ctx)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(name).To(BeElementOf(hostNames))
+		})
+
+		It("returns an error when the selected VMs exclude every host", func() {
+			createPeers(map[string]string{"app": "db"})
+
+			result, err := placement.Placement(vmCtx, ctx.Client, zoneClients, configSpec, "", nil)
+			Expect(err).To(MatchError("no placement recommendations available"))
+			Expect(result).To(BeNil())
+		})
+	})
+
+	Context("PCI device placement", func() {
+		const profile = "grid_p40-8q"
+
+		var hosts []*object.HostSystem
+
+		BeforeEach(func() {
+			configSpec.DeviceChange = append(configSpec.DeviceChange, &types.VirtualDeviceConfigSpec{
+				Operation: types.VirtualDeviceConfigSpecOperationAdd,
+				Device: &types.VirtualPCIPassthrough{
+					VirtualDevice: types.VirtualDevice{
+						Backing: &types.VirtualPCIPassthroughVmiopBackingInfo{Vgpu: profile},
+					},
+				},
+			})
+		})
+
+		JustBeforeEach(func() {
+			var err error
+			hosts, err = ctx.GetSingleClusterCompute().Hosts(ctx)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(hosts).ToNot(BeEmpty())
+		})
+
+		It("returns an error when no host has the vGPU profile", func() {
+			result, err := placement.Placement(vmCtx, ctx.Client, zoneClients, configSpec, "", nil)
+			Expect(err).To(MatchError("no placement recommendations available"))
+			Expect(result).To(BeNil())
+
+			c := conditions.Get(vm, vmopv1.VirtualMachinePCIDevicePlacementCondition)
+			Expect(c).ToNot(BeNil())
+			Expect(c.Status).To(Equal(corev1.ConditionFalse))
+			Expect(c.Reason).To(Equal(vmopv1.NoHostWithVGPUProfileReason))
+			Expect(c.Message).To(Equal("No host with vGPU profile " + profile))
+		})
+
+		It("returns a host with the vGPU profile", func() {
+			for _, host := range hosts {
+				simHost := simulator.Map.Get(host.Reference()).(*simulator.HostSystem)
+				simHost.Config.SharedPassthruGpuTypes = []string{profile}
+			}
+
+			result, err := placement.Placement(vmCtx, ctx.Client, zoneClients, configSpec, "", nil)
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(result.PCIDevicePlacement).To(BeTrue())
+			Expect(result.HostMoRef).ToNot(BeNil())
+			Expect(result.PoolMoRef.Value).ToNot(BeEmpty())
+			Expect(conditions.IsTrue(vm, vmopv1.VirtualMachinePCIDevicePlacementCondition)).To(BeTrue())
+		})
+
+		Context("Dynamic DirectPath I/O device", func() {
+			BeforeEach(func() {
+				configSpec.DeviceChange = []types.BaseVirtualDeviceConfigSpec{
+					&types.VirtualDeviceConfigSpec{
+						Operation: types.VirtualDeviceConfigSpecOperationAdd,
+						Device: &types.VirtualPCIPassthrough{
+							VirtualDevice: types.VirtualDevice{
+								Backing: &types.VirtualPCIPassthroughDynamicBackingInfo{
+									AllowedDevice: []types.VirtualPCIPassthroughAllowedDevice{
+										{VendorId: 0x10de, DeviceId: 0x1eb8},
+									},
+								},
+							},
+						},
+					},
+				}
+			})
+
+			It("returns an error when no host has the device free", func() {
+				result, err := placement.Placement(vmCtx, ctx.Client, zoneClients, configSpec, "", nil)
+				Expect(err).To(MatchError("no placement recommendations available"))
+				Expect(result).To(BeNil())
+
+				Expect(conditions.GetReason(vm, vmopv1.VirtualMachinePCIDevicePlacementCondition)).To(
+					Equal(vmopv1.NoHostWithPCIDeviceReason))
+				Expect(conditions.GetMessage(vm, vmopv1.VirtualMachinePCIDevicePlacementCondition)).To(
+					Equal("No host with free PCI device 10de:1eb8"))
+			})
+		})
 	})
 
 	Context("Instance Storage Placement", func() {
@@ -216,7 +560,7 @@ func vcSimPlacement() {
 			})
 
 			It("returns success with same host", func() {
-				result, err := placement.Placement(vmCtx, ctx.Client, ctx.VCClient.Client, configSpec, "")
+				result, err := placement.Placement(vmCtx, ctx.Client, zoneClients, configSpec, "", nil)
 				Expect(err).ToNot(HaveOccurred())
 
 				Expect(result.InstanceStoragePlacement).To(BeTrue())
@@ -226,7 +570,7 @@ func vcSimPlacement() {
 		})
 
 		It("returns success", func() {
-			result, err := placement.Placement(vmCtx, ctx.Client, ctx.VCClient.Client, configSpec, "")
+			result, err := placement.Placement(vmCtx, ctx.Client, zoneClients, configSpec, "", nil)
 			Expect(err).ToNot(HaveOccurred())
 
 			Expect(result.InstanceStoragePlacement).To(BeTrue())
@@ -241,7 +585,7 @@ func vcSimPlacement() {
 			})
 
 			It("returns success", func() {
-				result, err := placement.Placement(vmCtx, ctx.Client, ctx.VCClient.Client, configSpec, "")
+				result, err := placement.Placement(vmCtx, ctx.Client, zoneClients, configSpec, "", nil)
 				Expect(err).ToNot(HaveOccurred())
 
 				Expect(result.ZonePlacement).To(BeTrue())
@@ -264,7 +608,7 @@ func vcSimPlacement() {
 					Expect(childRPName).ToNot(BeEmpty())
 					vmCtx.VM.Spec.ResourcePolicyName = resourcePolicy.Name
 
-					result, err := placement.Placement(vmCtx, ctx.Client, ctx.VCClient.Client, configSpec, childRPName)
+					result, err := placement.Placement(vmCtx, ctx.Client, zoneClients, configSpec, childRPName, nil)
 					Expect(err).ToNot(HaveOccurred())
 
 					Expect(result.ZonePlacement).To(BeTrue())
@@ -1,4 +1,4 @@
-// Copyright (c) 2018-2022 VMware, Inc. All Rights Reserved.
+// Copyright (c) 2018-2023 VMware, Inc. All Rights Reserved.
 // SPDX-License-Identifier: Apache-2.0
 
 package resources
@@ -16,6 +16,9 @@ import (
 	logf "sigs.k8s.io/controller-runtime/pkg/log"
 
 	vmopv1 "github.com/vmware-tanzu/vm-operator/api/v1alpha1"
+	vmopContext "github.com/vmware-tanzu/vm-operator/pkg/context"
+	"github.com/vmware-tanzu/vm-operator/pkg/vmprovider"
+	"github.com/vmware-tanzu/vm-operator/pkg/vmprovider/providers/vsphere/virtualmachine"
 )
 
 type VirtualMachine struct {
@@ -38,20 +41,30 @@ func (vm *VirtualMachine) VcVM() *object.VirtualMachine {
 	return vm.vcVirtualMachine
 }
 
-func (vm *VirtualMachine) Create(ctx context.Context, folder *object.Folder, pool *object.ResourcePool, vmSpec *types.VirtualMachineConfigSpec) error {
+// Create creates the VM. A TaskInProgressError is returned when the create task is still running, and the
+// created VM is found once the task completes.
+func (vm *VirtualMachine) Create(
+	vmCtx vmopContext.VirtualMachineContext,
+	folder *object.Folder,
+	pool *object.ResourcePool,
+	vmSpec *types.VirtualMachineConfigSpec) error {
+
 	vm.logger.V(5).Info("Create VM")
 
 	if vm.vcVirtualMachine != nil {
 		return fmt.Errorf("failed to create VM %q because the VM object is already set", vm.Name)
 	}
 
-	createTask, err := folder.CreateVM(ctx, *vmSpec, pool, nil)
+	createTask, err := folder.CreateVM(vmCtx, *vmSpec, pool, nil)
 	if err != nil {
 		return err
 	}
 
-	result, err := createTask.WaitForResult(ctx, nil)
+	result, err := virtualmachine.WaitForTask(vmCtx, createTask)
 	if err != nil {
+		if isTaskInProgress(err) {
+			return err
+		}
 		return errors.Wrapf(err, "create VM %q task failed", vm.Name)
 	}
 
@@ -59,16 +72,25 @@ func (vm *VirtualMachine) Create(ctx context.Context, folder *object.Folder, poo
 	return nil
 }
 
-func (vm *VirtualMachine) Clone(ctx context.Context, folder *object.Folder, cloneSpec *types.VirtualMachineCloneSpec) (*types.ManagedObjectReference, error) {
+// Clone clones the VM into the VM of vmCtx. A TaskInProgressError is returned when the clone task is still
+// running, and the cloned VM is found once the task completes.
+func (vm *VirtualMachine) Clone(
+	vmCtx vmopContext.VirtualMachineContext,
+	folder *object.Folder,
+	cloneSpec *types.VirtualMachineCloneSpec) (*types.ManagedObjectReference, error) {
+
 	vm.logger.V(5).Info("Clone VM")
 
-	cloneTask, err := vm.vcVirtualMachine.Clone(ctx, folder, cloneSpec.Config.Name, *cloneSpec)
+	cloneTask, err := vm.vcVirtualMachine.Clone(vmCtx, folder, cloneSpec.Config.Name, *cloneSpec)
 	if err != nil {
 		return nil, err
 	}
 
-	result, err := cloneTask.WaitForResult(ctx, nil)
+	result, err := virtualmachine.WaitForTask(vmCtx, cloneTask)
 	if err != nil {
+		if isTaskInProgress(err) {
+			return nil, err
+		}
 		return nil, errors.Wrapf(err, "clone VM task failed")
 	}
 
@@ -76,16 +98,19 @@ func (vm *VirtualMachine) Clone(ctx context.Context, folder *object.Folder, clon
 	return &ref, nil
 }
 
-func (vm *VirtualMachine) Reconfigure(ctx context.Context, configSpec *types.VirtualMachineConfigSpec) error {
+func (vm *VirtualMachine) Reconfigure(vmCtx vmopContext.VirtualMachineContext, configSpec *types.VirtualMachineConfigSpec) error {
 	vm.logger.V(5).Info("Reconfiguring VM", "configSpec", configSpec)
 
-	reconfigureTask, err := vm.vcVirtualMachine.Reconfigure(ctx, *configSpec)
+	reconfigureTask, err := vm.vcVirtualMachine.Reconfigure(vmCtx, *configSpec)
 	if err != nil {
 		return err
 	}
 
-	_, err = reconfigureTask.WaitForResult(ctx, nil)
+	_, err = virtualmachine.WaitForTask(vmCtx, reconfigureTask)
 	if err != nil {
+		if isTaskInProgress(err) {
+			return err
+		}
 		return errors.Wrapf(err, "reconfigure VM task failed")
 	}
 
@@ -138,7 +163,7 @@ func (vm *VirtualMachine) UniqueID(ctx context.Context) (string, error) {
 	return vm.ReferenceValue(), nil
 }
 
-func (vm *VirtualMachine) SetPowerState(ctx context.Context, desiredPowerState vmopv1.VirtualMachinePowerState) error {
+func (vm *VirtualMachine) SetPowerState(vmCtx vmopContext.VirtualMachineContext, desiredPowerState vmopv1.VirtualMachinePowerState) error {
 	vm.logger.V(5).Info("SetPowerState", "desiredState", desiredPowerState)
 
 	var powerTask *object.Task
@@ -146,9 +171,9 @@ func (vm *VirtualMachine) SetPowerState(ctx context.Context, desiredPowerState v
 
 	switch desiredPowerState {
 	case vmopv1.VirtualMachinePoweredOn:
-		powerTask, err = vm.vcVirtualMachine.PowerOn(ctx)
+		powerTask, err = vm.vcVirtualMachine.PowerOn(vmCtx)
 	case vmopv1.VirtualMachinePoweredOff:
-		powerTask, err = vm.vcVirtualMachine.PowerOff(ctx)
+		powerTask, err = vm.vcVirtualMachine.PowerOff(vmCtx)
 	default:
 		err = fmt.Errorf("invalid desired power state %s", desiredPowerState)
 	}
@@ -158,13 +183,16 @@ func (vm *VirtualMachine) SetPowerState(ctx context.Context, desiredPowerState v
 		return err
 	}
 
-	if taskInfo, err := powerTask.WaitForResult(ctx, nil); err != nil {
+	if taskInfo, err := virtualmachine.WaitForTask(vmCtx, powerTask); err != nil {
 		if te, ok := err.(task.Error); ok {
 			// Ignore error if VM was already in desired state.
 			if ips, ok := te.Fault().(*types.InvalidPowerStateFault); ok && ips.ExistingState == ips.RequestedState {
 				return nil
 			}
 		}
+		if isTaskInProgress(err) {
+			return err
+		}
 		if taskInfo != nil {
 			err = errors.Wrapf(err, "%s failed", taskInfo.Name)
 		}
@@ -176,6 +204,13 @@ func (vm *VirtualMachine) SetPowerState(ctx context.Context, desiredPowerState v
 	return nil
 }
 
+// ShutdownGuest requests the guest OS of the VM to shut down. It does not wait for
+// the VM to be powered off.
+func (vm *VirtualMachine) ShutdownGuest(ctx context.Context) error {
+	vm.logger.V(5).Info("ShutdownGuest")
+	return vm.vcVirtualMachine.ShutdownGuest(ctx)
+}
+
 // GetVirtualDevices returns the VMs VirtualDeviceList.
 func (vm *VirtualMachine) GetVirtualDevices(ctx context.Context) (object.VirtualDeviceList, error) {
 	vm.logger.V(5).Info("GetVirtualDevices")
@@ -211,17 +246,21 @@ func (vm *VirtualMachine) GetNetworkDevices(ctx context.Context) (object.Virtual
 	return devices.SelectByType((*types.VirtualEthernetCard)(nil)), nil
 }
 
-func (vm *VirtualMachine) Customize(ctx context.Context, spec types.CustomizationSpec) error {
+// Customize customizes the VM. A TaskInProgressError is returned when the customize task is still running.
+func (vm *VirtualMachine) Customize(vmCtx vmopContext.VirtualMachineContext, spec types.CustomizationSpec) error {
 	vm.logger.V(5).Info("Customize", "spec", spec)
 
-	customizeTask, err := vm.vcVirtualMachine.Customize(ctx, spec)
+	customizeTask, err := vm.vcVirtualMachine.Customize(vmCtx, spec)
 	if err != nil {
 		vm.logger.Error(err, "Failed to customize VM")
 		return err
 	}
 
-	taskInfo, err := customizeTask.WaitForResult(ctx, nil)
+	taskInfo, err := virtualmachine.WaitForTask(vmCtx, customizeTask)
 	if err != nil {
+		if isTaskInProgress(err) {
+			return err
+		}
 		vm.logger.Error(err, "Failed to wait for the result of Customize VM")
 		return err
 	}
@@ -238,3 +277,7 @@ func (vm *VirtualMachine) Customize(ctx context.Context, spec types.Customizatio
 
 	return nil
 }
+
+func isTaskInProgress(err error) bool {
+	return errors.As(err, &vmprovider.TaskInProgressError{})
+}
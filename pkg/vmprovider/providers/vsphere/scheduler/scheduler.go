@@ -0,0 +1,249 @@
+// Copyright (c) 2023 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package scheduler
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	vmopv1 "github.com/vmware-tanzu/vm-operator/api/v1alpha1"
+	"github.com/vmware-tanzu/vm-operator/pkg/metrics"
+)
+
+// Operation is a kind of vSphere operation whose concurrency is limited by a Scheduler.
+type Operation string
+
+const (
+	OperationCreate      Operation = "create"
+	OperationReconfigure Operation = "reconfigure"
+	OperationPower       Operation = "power"
+	OperationPublish     Operation = "publish"
+)
+
+const (
+	// DefaultWaiterTTL is how long a queued operation keeps its place in the queue without being retried.
+	DefaultWaiterTTL = 30 * time.Second
+
+	// acquirePollInterval is how often Acquire retries when no operation completes.
+	acquirePollInterval = time.Second
+)
+
+// Request is a request to start an operation.
+type Request struct {
+	// Key identifies the object of the operation, so the retries of a queued operation keep its place.
+	Key string
+
+	// Namespace is the namespace of the object.
+	Namespace string
+
+	// Shares are the shares of the namespace. Defaults to 1.
+	Shares int
+
+	// PriorityClass is one of the vmopv1.PriorityClass values. Defaults to Normal.
+	PriorityClass string
+}
+
+type waiter struct {
+	Request
+	priority int
+	enqueued time.Time
+	lastSeen time.Time
+}
+
+// Scheduler limits the number of concurrent operations of a kind, and starts the queued operations in a fair
+// order: the operations of a higher priority class first, then the operations of the namespace that runs the
+// fewest operations relative to its shares, and then the operations that were queued first.
+//
+// Operations are not blocked while they are queued. TryAcquire returns false and the operation is expected to
+// be retried, keeping its place in the queue as long as it is retried within the WaiterTTL.
+type Scheduler struct {
+	operation Operation
+	waiterTTL time.Duration
+	metrics   *metrics.SchedulerMetrics
+
+	mu        sync.Mutex
+	running   int
+	nsRunning map[string]int
+	waiters   map[string]*waiter
+	released  chan struct{}
+}
+
+// New returns a Scheduler for the operation.
+func New(operation Operation, waiterTTL time.Duration) *Scheduler {
+	if waiterTTL <= 0 {
+		waiterTTL = DefaultWaiterTTL
+	}
+
+	return &Scheduler{
+		operation: operation,
+		waiterTTL: waiterTTL,
+		metrics:   metrics.NewSchedulerMetrics(),
+		nsRunning: map[string]int{},
+		waiters:   map[string]*waiter{},
+		released:  make(chan struct{}),
+	}
+}
+
+// TryAcquire starts the operation when fewer than capacity operations are running, and no queued operation
+// is ahead of it. Otherwise, the operation is queued and false is returned. The returned release func must be
+// called once a started operation completes.
+func (s *Scheduler) TryAcquire(req Request, capacity int) (func(), bool) {
+	if req.Shares <= 0 {
+		req.Shares = 1
+	}
+	if req.PriorityClass == "" {
+		req.PriorityClass = vmopv1.PriorityClassNormal
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	s.expireWaiters(now)
+
+	w, ok := s.waiters[req.Key]
+	if !ok {
+		w = &waiter{enqueued: now}
+		s.waiters[req.Key] = w
+	}
+	oldNamespace := w.Namespace
+	w.Request = req
+	w.priority = priority(req.PriorityClass)
+	w.lastSeen = now
+
+	if free := capacity - s.running; free > 0 && s.waitersAhead(w, free) < free {
+		delete(s.waiters, req.Key)
+		s.running++
+		s.nsRunning[req.Namespace]++
+		s.updateQueueDepth(req.Namespace)
+		s.metrics.ObserveWaitTime(string(s.operation), req.PriorityClass, now.Sub(w.enqueued))
+
+		var once sync.Once
+		return func() { once.Do(func() { s.release(req.Namespace) }) }, true
+	}
+
+	if oldNamespace != "" && oldNamespace != req.Namespace {
+		s.updateQueueDepth(oldNamespace)
+	}
+	s.updateQueueDepth(req.Namespace)
+	return nil, false
+}
+
+// Acquire blocks until the operation is started by TryAcquire, or ctx is done.
+func (s *Scheduler) Acquire(ctx context.Context, req Request, capacity int) (func(), error) {
+	for {
+		s.mu.Lock()
+		released := s.released
+		s.mu.Unlock()
+
+		if release, ok := s.TryAcquire(req, capacity); ok {
+			return release, nil
+		}
+
+		select {
+		case <-ctx.Done():
+			s.Dequeue(req.Key)
+			return nil, ctx.Err()
+		case <-released:
+		case <-time.After(acquirePollInterval):
+		}
+	}
+}
+
+// Dequeue removes the queued operation of the key, such as when its object is deleted.
+func (s *Scheduler) Dequeue(key string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if w, ok := s.waiters[key]; ok {
+		delete(s.waiters, key)
+		s.updateQueueDepth(w.Namespace)
+	}
+}
+
+// QueueDepth returns the number of queued operations.
+func (s *Scheduler) QueueDepth() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.expireWaiters(time.Now())
+	return len(s.waiters)
+}
+
+func (s *Scheduler) release(namespace string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.running--
+	if s.nsRunning[namespace]--; s.nsRunning[namespace] <= 0 {
+		delete(s.nsRunning, namespace)
+	}
+
+	close(s.released)
+	s.released = make(chan struct{})
+}
+
+// waitersAhead returns the number of queued operations that are ahead of w, up to limit.
+func (s *Scheduler) waitersAhead(w *waiter, limit int) int {
+	n := 0
+	for _, o := range s.waiters {
+		if o != w && s.ahead(o, w) {
+			if n++; n >= limit {
+				break
+			}
+		}
+	}
+	return n
+}
+
+// ahead returns true when the operation of a starts before the operation of b.
+func (s *Scheduler) ahead(a, b *waiter) bool {
+	if a.priority != b.priority {
+		return a.priority > b.priority
+	}
+
+	if a.Namespace != b.Namespace {
+		// Compare the running operations per share of the namespaces: a.running/a.shares < b.running/b.shares.
+		aLoad, bLoad := s.nsRunning[a.Namespace]*b.Shares, s.nsRunning[b.Namespace]*a.Shares
+		if aLoad != bLoad {
+			return aLoad < bLoad
+		}
+	}
+
+	if !a.enqueued.Equal(b.enqueued) {
+		return a.enqueued.Before(b.enqueued)
+	}
+	return a.Key < b.Key
+}
+
+func (s *Scheduler) expireWaiters(now time.Time) {
+	for key, w := range s.waiters {
+		if now.Sub(w.lastSeen) > s.waiterTTL {
+			delete(s.waiters, key)
+			s.updateQueueDepth(w.Namespace)
+		}
+	}
+}
+
+func (s *Scheduler) updateQueueDepth(namespace string) {
+	depth := 0
+	for _, w := range s.waiters {
+		if w.Namespace == namespace {
+			depth++
+		}
+	}
+	s.metrics.SetQueueDepth(string(s.operation), namespace, depth)
+}
+
+func priority(priorityClass string) int {
+	switch priorityClass {
+	case vmopv1.PriorityClassHigh:
+		return 1
+	case vmopv1.PriorityClassLow:
+		return -1
+	default:
+		return 0
+	}
+}
@@ -0,0 +1,188 @@
+// Copyright (c) 2023 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package scheduler_test
+
+import (
+	"context"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	vmopv1 "github.com/vmware-tanzu/vm-operator/api/v1alpha1"
+	"github.com/vmware-tanzu/vm-operator/pkg/vmprovider/providers/vsphere/scheduler"
+)
+
+var _ = Describe("Scheduler", func() {
+
+	var (
+		s   *scheduler.Scheduler
+		ttl time.Duration
+	)
+
+	request := func(namespace, name string) scheduler.Request {
+		return scheduler.Request{Key: namespace + "/" + name, Namespace: namespace}
+	}
+
+	mustAcquire := func(req scheduler.Request, capacity int) func() {
+		release, ok := s.TryAcquire(req, capacity)
+		ExpectWithOffset(1, ok).To(BeTrue())
+		return release
+	}
+
+	mustQueue := func(req scheduler.Request, capacity int) {
+		_, ok := s.TryAcquire(req, capacity)
+		ExpectWithOffset(1, ok).To(BeFalse())
+	}
+
+	BeforeEach(func() {
+		ttl = scheduler.DefaultWaiterTTL
+	})
+
+	JustBeforeEach(func() {
+		s = scheduler.New(scheduler.OperationCreate, ttl)
+	})
+
+	It("limits the number of running operations", func() {
+		release1 := mustAcquire(request("ns", "vm-1"), 2)
+		release2 := mustAcquire(request("ns", "vm-2"), 2)
+		mustQueue(request("ns", "vm-3"), 2)
+		Expect(s.QueueDepth()).To(Equal(1))
+
+		release1()
+		// Calling the release func again has no effect.
+		release1()
+
+		release3 := mustAcquire(request("ns", "vm-3"), 2)
+		Expect(s.QueueDepth()).To(BeZero())
+		mustQueue(request("ns", "vm-4"), 2)
+
+		release2()
+		release3()
+	})
+
+	It("starts the queued operations before new operations", func() {
+		release1 := mustAcquire(request("ns", "vm-1"), 1)
+		mustQueue(request("ns", "vm-2"), 1)
+		release1()
+
+		mustQueue(request("ns", "vm-3"), 1)
+		release2 := mustAcquire(request("ns", "vm-2"), 1)
+		release2()
+		mustAcquire(request("ns", "vm-3"), 1)()
+	})
+
+	It("starts the operations of a higher priority class first", func() {
+		release1 := mustAcquire(request("ns", "vm-1"), 1)
+
+		low := request("ns", "vm-2")
+		low.PriorityClass = vmopv1.PriorityClassLow
+		mustQueue(low, 1)
+		normal := request("ns", "vm-3")
+		mustQueue(normal, 1)
+		high := request("ns", "vm-4")
+		high.PriorityClass = vmopv1.PriorityClassHigh
+		mustQueue(high, 1)
+
+		release1()
+
+		mustQueue(low, 1)
+		mustQueue(normal, 1)
+		mustAcquire(high, 1)()
+		mustQueue(low, 1)
+		mustAcquire(normal, 1)()
+		mustAcquire(low, 1)()
+	})
+
+	It("starts the operations of the namespace with the fewest running operations first", func() {
+		release1 := mustAcquire(request("ns-1", "vm-1"), 2)
+		release2 := mustAcquire(request("ns-3", "vm-1"), 2)
+		defer release1()
+
+		mustQueue(request("ns-1", "vm-2"), 2)
+		mustQueue(request("ns-2", "vm-1"), 2)
+
+		release2()
+
+		mustQueue(request("ns-1", "vm-2"), 2)
+		mustAcquire(request("ns-2", "vm-1"), 2)()
+	})
+
+	It("starts the operations of the namespace with the most shares first", func() {
+		release1 := mustAcquire(request("ns-1", "vm-1"), 3)
+		release2 := mustAcquire(request("ns-2", "vm-1"), 3)
+		release3 := mustAcquire(request("ns-3", "vm-1"), 3)
+		defer release1()
+		defer release2()
+
+		ns2 := request("ns-2", "vm-2")
+		mustQueue(ns2, 3)
+		ns1 := request("ns-1", "vm-2")
+		ns1.Shares = 4
+		mustQueue(ns1, 3)
+
+		release3()
+
+		mustQueue(ns2, 3)
+		mustAcquire(ns1, 3)()
+	})
+
+	It("removes a dequeued operation", func() {
+		release1 := mustAcquire(request("ns", "vm-1"), 1)
+		mustQueue(request("ns", "vm-2"), 1)
+		s.Dequeue("ns/vm-2")
+		Expect(s.QueueDepth()).To(BeZero())
+
+		release1()
+		mustAcquire(request("ns", "vm-3"), 1)()
+	})
+
+	Context("with a short waiter TTL", func() {
+
+		BeforeEach(func() {
+			ttl = 100 * time.Millisecond
+		})
+
+		It("removes the queued operations that are not retried", func() {
+			release1 := mustAcquire(request("ns", "vm-1"), 1)
+			mustQueue(request("ns", "vm-2"), 1)
+			release1()
+
+			Eventually(s.QueueDepth).Should(BeZero())
+			mustAcquire(request("ns", "vm-3"), 1)()
+		})
+	})
+
+	Context("Acquire", func() {
+
+		It("waits for a running operation to complete", func() {
+			release1 := mustAcquire(request("ns", "vm-1"), 1)
+
+			acquired := make(chan struct{})
+			go func() {
+				defer GinkgoRecover()
+				release, err := s.Acquire(context.Background(), request("ns", "vm-2"), 1)
+				Expect(err).ToNot(HaveOccurred())
+				release()
+				close(acquired)
+			}()
+
+			Consistently(acquired, 200*time.Millisecond).ShouldNot(BeClosed())
+			release1()
+			Eventually(acquired).Should(BeClosed())
+		})
+
+		It("returns an error and dequeues the operation when the context is done", func() {
+			release1 := mustAcquire(request("ns", "vm-1"), 1)
+			defer release1()
+
+			ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+			defer cancel()
+
+			_, err := s.Acquire(ctx, request("ns", "vm-2"), 1)
+			Expect(err).To(MatchError(context.DeadlineExceeded))
+			Expect(s.QueueDepth()).To(BeZero())
+		})
+	})
+})
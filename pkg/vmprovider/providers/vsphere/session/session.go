@@ -1,18 +1,22 @@
-// Copyright (c) 2018-2021 VMware, Inc. All Rights Reserved.
+// Copyright (c) 2018-2023 VMware, Inc. All Rights Reserved.
 // SPDX-License-Identifier: Apache-2.0
 
 package session
 
 import (
+	"github.com/pkg/errors"
 	"github.com/vmware/govmomi/find"
 	"github.com/vmware/govmomi/object"
 	ctrlruntime "sigs.k8s.io/controller-runtime/pkg/client"
 
 	"github.com/vmware-tanzu/vm-operator/pkg/context"
+	"github.com/vmware-tanzu/vm-operator/pkg/vmprovider"
 	"github.com/vmware-tanzu/vm-operator/pkg/vmprovider/providers/vsphere/client"
 	"github.com/vmware-tanzu/vm-operator/pkg/vmprovider/providers/vsphere/internal"
 	"github.com/vmware-tanzu/vm-operator/pkg/vmprovider/providers/vsphere/network"
 	res "github.com/vmware-tanzu/vm-operator/pkg/vmprovider/providers/vsphere/resources"
+	"github.com/vmware-tanzu/vm-operator/pkg/vmprovider/providers/vsphere/scheduler"
+	"github.com/vmware-tanzu/vm-operator/pkg/vmprovider/providers/vsphere/virtualmachine"
 )
 
 type Session struct {
@@ -23,6 +27,17 @@ type Session struct {
 	// Fields only used during Update
 	Cluster         *object.ClusterComputeResource
 	NetworkProvider network.Provider
+
+	// AcquireOperation starts a reconfigure or power operation, and returns the func to call once it completes.
+	// The operations are not limited when nil.
+	AcquireOperation func(scheduler.Operation) (func(), error)
+}
+
+func (s *Session) acquireOperation(op scheduler.Operation) (func(), error) {
+	if s.AcquireOperation == nil {
+		return func() {}, nil
+	}
+	return s.AcquireOperation(op)
 }
 
 func (s *Session) invokeFsrVirtualMachine(vmCtx context.VirtualMachineContext, resVM *res.VirtualMachine) error {
@@ -34,7 +49,10 @@ func (s *Session) invokeFsrVirtualMachine(vmCtx context.VirtualMachineContext, r
 		return err
 	}
 
-	if err = task.Wait(vmCtx); err != nil {
+	if _, err = virtualmachine.WaitForTask(vmCtx, task); err != nil {
+		if errors.As(err, &vmprovider.TaskInProgressError{}) {
+			return err
+		}
 		vmCtx.Logger.Error(err, "InvokeFSR task failed")
 		return err
 	}
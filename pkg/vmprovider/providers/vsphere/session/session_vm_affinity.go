@@ -0,0 +1,106 @@
+// Copyright (c) 2023 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package session
+
+import (
+	"fmt"
+
+	"github.com/pkg/errors"
+	"github.com/vmware/govmomi/vim25/types"
+	"k8s.io/apimachinery/pkg/labels"
+	ctrl "sigs.k8s.io/controller-runtime/pkg/client"
+
+	vmopv1 "github.com/vmware-tanzu/vm-operator/api/v1alpha1"
+	"github.com/vmware-tanzu/vm-operator/pkg/context"
+	"github.com/vmware-tanzu/vm-operator/pkg/vmprovider/providers/vsphere/placement"
+	"github.com/vmware-tanzu/vm-operator/pkg/vmprovider/providers/vsphere/vcenter"
+)
+
+// updateAffinityRules updates the mandatory DRS VM/host rules that realize the
+// VM's affinity and anti-affinity terms that are required during execution.
+// DRS rules are realized only for terms with the host topology key: the VM is
+// kept on, or off, the hosts of the other VMs that are selected by the term and
+// that are in the same cluster. The rules are updated as the selected VMs move,
+// and the rules of the VM without such terms are removed. The VMs that never had
+// rules, as recorded by the AffinityRulesAnnotation, are skipped.
+func (s *Session) updateAffinityRules(
+	vmCtx context.VirtualMachineContext,
+	vmMoRef types.ManagedObjectReference) error {
+
+	if s.Cluster == nil {
+		return nil
+	}
+
+	prefix := placement.AffinityRuleNamePrefix(vmCtx.VM)
+	if !placement.HasRequiredDuringExecutionAffinity(vmCtx.VM) {
+		if _, ok := vmCtx.VM.Annotations[AffinityRulesAnnotation]; !ok {
+			return nil
+		}
+		if err := vcenter.UpdateClusterVMHostRules(vmCtx, s.Cluster, prefix, nil); err != nil {
+			return err
+		}
+		delete(vmCtx.VM.Annotations, AffinityRulesAnnotation)
+		return nil
+	}
+
+	vmList := &vmopv1.VirtualMachineList{}
+	if err := s.K8sClient.List(vmCtx, vmList, ctrl.InNamespace(vmCtx.VM.Namespace)); err != nil {
+		return errors.Wrapf(err, "failed to list VirtualMachines in namespace %s", vmCtx.VM.Namespace)
+	}
+
+	spec := vmCtx.VM.Spec.Affinity
+	var rules []vcenter.ClusterVMHostRule
+
+	for _, r := range []struct {
+		rules    *vmopv1.VirtualMachineAffinityRules
+		affinity bool
+		name     string
+	}{
+		{spec.VMAffinity, true, "affinity"},
+		{spec.VMAntiAffinity, false, "anti-affinity"},
+	} {
+		if r.rules == nil {
+			continue
+		}
+
+		for i, term := range r.rules.RequiredDuringSchedulingRequiredDuringExecution {
+			if term.TopologyKey != vmopv1.VirtualMachineAffinityHostTopologyKey {
+				continue
+			}
+
+			selector, err := placement.AffinityTermSelector(term)
+			if err != nil {
+				return errors.Wrap(err, "invalid affinity term")
+			}
+
+			rule := vcenter.ClusterVMHostRule{
+				Name:     fmt.Sprintf("%s%s-%d", prefix, r.name, i),
+				Affinity: r.affinity,
+				VMs:      []types.ManagedObjectReference{vmMoRef},
+			}
+
+			for _, vm := range vmList.Items {
+				if vm.Name == vmCtx.VM.Name || vm.Status.UniqueID == "" || !vm.DeletionTimestamp.IsZero() {
+					continue
+				}
+				if selector.Matches(labels.Set(vm.Labels)) {
+					rule.HostVMs = append(rule.HostVMs,
+						types.ManagedObjectReference{Type: "VirtualMachine", Value: vm.Status.UniqueID})
+				}
+			}
+
+			rules = append(rules, rule)
+		}
+	}
+
+	if err := vcenter.UpdateClusterVMHostRules(vmCtx, s.Cluster, prefix, rules); err != nil {
+		return err
+	}
+
+	if vmCtx.VM.Annotations == nil {
+		vmCtx.VM.Annotations = map[string]string{}
+	}
+	vmCtx.VM.Annotations[AffinityRulesAnnotation] = "true"
+	return nil
+}
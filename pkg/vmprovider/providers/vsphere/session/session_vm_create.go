@@ -4,6 +4,7 @@
 package session
 
 import (
+	goctx "context"
 	"encoding/base64"
 	"fmt"
 
@@ -23,6 +24,7 @@ import (
 	"github.com/vmware-tanzu/vm-operator/pkg/vmprovider/providers/vsphere/constants"
 	"github.com/vmware-tanzu/vm-operator/pkg/vmprovider/providers/vsphere/placement"
 	res "github.com/vmware-tanzu/vm-operator/pkg/vmprovider/providers/vsphere/resources"
+	"github.com/vmware-tanzu/vm-operator/pkg/vmprovider/providers/vsphere/virtualmachine"
 )
 
 // VMCreateArgs contains the arguments needed to create a VM on VC.
@@ -92,7 +94,11 @@ func (s *Session) deployVMFromCL(
 
 	vmCtx.Logger.Info("Deploying Library Item", "itemID", item.ID, "deploy", deploy)
 
-	vmMoRef, err := vcenter.NewManager(s.Client.RestClient()).DeployLibraryItem(vmCtx, item.ID, deploy)
+	restClient := s.Client.RestClient()
+	vmMoRef, err := virtualmachine.DeployLibraryItem(vmCtx, item.ID,
+		func(ctx goctx.Context) (*vimTypes.ManagedObjectReference, error) {
+			return vcenter.NewManager(restClient).DeployLibraryItem(ctx, item.ID, deploy)
+		})
 	if err != nil {
 		return nil, err
 	}
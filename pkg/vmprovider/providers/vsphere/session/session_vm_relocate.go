@@ -0,0 +1,104 @@
+// Copyright (c) 2023 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package session
+
+import (
+	"github.com/pkg/errors"
+	"github.com/vmware/govmomi/object"
+	"github.com/vmware/govmomi/vim25/types"
+
+	"github.com/vmware-tanzu/vm-operator/pkg/context"
+)
+
+// VMRelocateArgs is where a VM is relocated to. The parts of the VM's location whose MoIDs
+// are empty are left unchanged.
+type VMRelocateArgs struct {
+	ResourcePoolMoID string
+	HostMoID         string
+	DatastoreMoID    string
+	StorageProfileID string
+}
+
+// RelocateVirtualMachine starts relocating the VM with vMotion and Storage vMotion, and returns
+// the relocation task.
+func (s *Session) RelocateVirtualMachine(
+	vmCtx context.VirtualMachineContext,
+	vcVM *object.VirtualMachine,
+	args *VMRelocateArgs) (*object.Task, error) {
+
+	spec, err := s.relocateSpec(vmCtx, vcVM, args)
+	if err != nil {
+		return nil, err
+	}
+
+	vmCtx.Logger.Info("Relocating VirtualMachine", "args", args)
+
+	task, err := vcVM.Relocate(vmCtx, spec, types.VirtualMachineMovePriorityDefaultPriority)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to relocate VM %s", vcVM.Reference().Value)
+	}
+
+	return task, nil
+}
+
+func (s *Session) relocateSpec(
+	vmCtx context.VirtualMachineContext,
+	vcVM *object.VirtualMachine,
+	args *VMRelocateArgs) (types.VirtualMachineRelocateSpec, error) {
+
+	var spec types.VirtualMachineRelocateSpec
+
+	if args.ResourcePoolMoID != "" {
+		spec.Pool = &types.ManagedObjectReference{Type: "ResourcePool", Value: args.ResourcePoolMoID}
+	}
+
+	if args.HostMoID != "" {
+		spec.Host = &types.ManagedObjectReference{Type: "HostSystem", Value: args.HostMoID}
+	}
+
+	if args.DatastoreMoID == "" {
+		return spec, nil
+	}
+
+	datastore := types.ManagedObjectReference{Type: "Datastore", Value: args.DatastoreMoID}
+	spec.Datastore = &datastore
+
+	var profile []types.BaseVirtualMachineProfileSpec
+	if args.StorageProfileID != "" {
+		profile = []types.BaseVirtualMachineProfileSpec{
+			&types.VirtualMachineDefinedProfileSpec{ProfileId: args.StorageProfileID},
+		}
+	}
+	spec.Profile = profile
+
+	devices, err := vcVM.Device(vmCtx)
+	if err != nil {
+		return spec, errors.Wrapf(err, "failed to get devices of VM %s", vcVM.Reference().Value)
+	}
+
+	// The disks of the VM are relocated with the VM's files, except the first class disks of
+	// the PVCs that are managed by CNS: those stay on their current datastore.
+	for _, dev := range devices.SelectByType((*types.VirtualDisk)(nil)) {
+		disk := dev.(*types.VirtualDisk)
+
+		locator := types.VirtualMachineRelocateSpecDiskLocator{
+			DiskId:    disk.Key,
+			Datastore: datastore,
+			Profile:   profile,
+		}
+
+		if disk.VDiskId != nil {
+			backing, ok := disk.Backing.(types.BaseVirtualDeviceFileBackingInfo)
+			if !ok || backing.GetVirtualDeviceFileBackingInfo().Datastore == nil {
+				continue
+			}
+			locator.Datastore = *backing.GetVirtualDeviceFileBackingInfo().Datastore
+			locator.Profile = nil
+		}
+
+		spec.Disk = append(spec.Disk, locator)
+	}
+
+	return spec, nil
+}
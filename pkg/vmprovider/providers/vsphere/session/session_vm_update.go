@@ -6,6 +6,7 @@ package session
 import (
 	"fmt"
 	"reflect"
+	"time"
 
 	apiEquality "k8s.io/apimachinery/pkg/api/equality"
 	apierrs "k8s.io/apimachinery/pkg/api/errors"
@@ -15,21 +16,28 @@ import (
 	vimTypes "github.com/vmware/govmomi/vim25/types"
 
 	vmopv1 "github.com/vmware-tanzu/vm-operator/api/v1alpha1"
-	"github.com/vmware-tanzu/vm-operator/pkg"
+	"github.com/vmware-tanzu/vm-operator/pkg/conditions"
 	"github.com/vmware-tanzu/vm-operator/pkg/context"
 	"github.com/vmware-tanzu/vm-operator/pkg/lib"
 	"github.com/vmware-tanzu/vm-operator/pkg/util"
+	"github.com/vmware-tanzu/vm-operator/pkg/vmprovider"
 	"github.com/vmware-tanzu/vm-operator/pkg/vmprovider/providers/vsphere/clustermodules"
 	"github.com/vmware-tanzu/vm-operator/pkg/vmprovider/providers/vsphere/config"
 	"github.com/vmware-tanzu/vm-operator/pkg/vmprovider/providers/vsphere/constants"
 	"github.com/vmware-tanzu/vm-operator/pkg/vmprovider/providers/vsphere/instancestorage"
 	"github.com/vmware-tanzu/vm-operator/pkg/vmprovider/providers/vsphere/network"
 	res "github.com/vmware-tanzu/vm-operator/pkg/vmprovider/providers/vsphere/resources"
+	"github.com/vmware-tanzu/vm-operator/pkg/vmprovider/providers/vsphere/scheduler"
+	"github.com/vmware-tanzu/vm-operator/pkg/vmprovider/providers/vsphere/vcenter"
 	"github.com/vmware-tanzu/vm-operator/pkg/vmprovider/providers/vsphere/virtualmachine"
 )
 
 const (
 	FirstBootDoneAnnotation = "virtualmachine.vmoperator.vmware.com/first-boot-done"
+
+	// AffinityRulesAnnotation is set on a VM that has DRS rules for its affinity terms, so the
+	// rules are only updated or removed for the VMs that have, or had, such terms.
+	AffinityRulesAnnotation = "virtualmachine.vmoperator.vmware.com/affinity-rules"
 )
 
 type VMMetadata struct {
@@ -48,6 +56,10 @@ type VMUpdateArgs struct {
 	ConfigSpec      *vimTypes.VirtualMachineConfigSpec
 	ClassConfigSpec *vimTypes.VirtualMachineConfigSpec
 
+	// ApplyClassHardware is true when the VM Class hardware and CPU and memory
+	// allocation should be applied to the VM when it is reconfigured before power on.
+	ApplyClassHardware bool
+
 	NetIfList      network.InterfaceInfoList
 	DNSServers     []string
 	SearchSuffixes []string
@@ -466,9 +478,9 @@ func updateConfigSpec(
 	// Before VM Class as Config, VMs were deployed from the OVA, and are then
 	// reconfigured to match the desired CPU and memory reservation.  Maintain that
 	// behavior.  With the FSS enabled, VMs will be _created_ with desired HW spec, and we
-	// will not modify the hardware of the VM post creation.  So, don't populate the
-	// Hardware config and CPU/Memory reservation.
-	if !lib.IsVMClassAsConfigFSSDaynDateEnabled() {
+	// will not modify the hardware of the VM post creation unless the VM Class update
+	// policy allows a change to the class to be rolled out to existing VMs.
+	if updateArgs.ApplyClassHardware {
 		UpdateHardwareConfigSpec(config, configSpec, &vmClassSpec)
 		UpdateConfigSpecCPUAllocation(config, configSpec, &vmClassSpec, updateArgs.MinCPUFreq)
 		UpdateConfigSpecMemoryAllocation(config, configSpec, &vmClassSpec)
@@ -739,6 +751,12 @@ func (s *Session) poweredOnVMReconfigure(
 
 	defaultConfigSpec := &vimTypes.VirtualMachineConfigSpec{}
 	if !apiEquality.Semantic.DeepEqual(configSpec, defaultConfigSpec) {
+		release, err := s.acquireOperation(scheduler.OperationReconfigure)
+		if err != nil {
+			return err
+		}
+		defer release()
+
 		vmCtx.Logger.Info("PoweredOn Reconfigure", "configSpec", configSpec)
 		if err := resVM.Reconfigure(vmCtx, configSpec); err != nil {
 			vmCtx.Logger.Error(err, "powered on reconfigure failed")
@@ -764,19 +782,121 @@ func (s *Session) attachClusterModule(
 	resVM *res.VirtualMachine,
 	resourcePolicy *vmopv1.VirtualMachineSetResourcePolicy) error {
 
-	// The clusterModule is required be able to enforce the vm-vm anti-affinity policy.
-	clusterModuleName := vmCtx.VM.Annotations[pkg.ClusterModuleNameKey]
-	if clusterModuleName == "" {
-		return nil
+	// The clusterModule is required be able to enforce the vm-vm anti-affinity policy. The groups
+	// that are realized by DRS VM-VM rules are updated by the ResourcePolicy, but the VM is added
+	// to their rules here too so DRS enforces them when the VM is powered on.
+	for _, clusterModuleName := range clustermodules.GroupNames(vmCtx.VM) {
+		moduleSpec := clustermodules.FindClusterModuleSpec(clusterModuleName, resourcePolicy)
+		if moduleSpec != nil && !clustermodules.IsVCClusterModule(*moduleSpec) {
+			if err := s.updateClusterModuleRule(vmCtx, resVM, resourcePolicy, *moduleSpec); err != nil {
+				return err
+			}
+			continue
+		}
+
+		// Find ClusterModule UUID from the ResourcePolicy.
+		_, moduleUUID := clustermodules.FindClusterModuleUUID(clusterModuleName, s.Cluster.Reference(), resourcePolicy)
+		if moduleUUID == "" {
+			return fmt.Errorf("ClusterModule %s not found", clusterModuleName)
+		}
+
+		if err := s.Client.ClusterModuleClient().AddMoRefToModule(vmCtx, moduleUUID, resVM.MoRef()); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// updateClusterModuleRule updates the DRS VM-VM rule of the ClusterModule Group in the cluster of
+// the VM to the VM and the other members of the group that have been created.
+func (s *Session) updateClusterModuleRule(
+	vmCtx context.VirtualMachineContext,
+	resVM *res.VirtualMachine,
+	resourcePolicy *vmopv1.VirtualMachineSetResourcePolicy,
+	moduleSpec vmopv1.ClusterModuleSpec) error {
+
+	vmList := &vmopv1.VirtualMachineList{}
+	if err := s.K8sClient.List(vmCtx, vmList, ctrl.InNamespace(vmCtx.VM.Namespace)); err != nil {
+		return err
 	}
 
-	// Find ClusterModule UUID from the ResourcePolicy.
-	_, moduleUUID := clustermodules.FindClusterModuleUUID(clusterModuleName, s.Cluster.Reference(), resourcePolicy)
-	if moduleUUID == "" {
-		return fmt.Errorf("ClusterModule %s not found", clusterModuleName)
+	vms := []vimTypes.ManagedObjectReference{resVM.MoRef()}
+	for i := range vmList.Items {
+		vm := &vmList.Items[i]
+		if vm.Name == vmCtx.VM.Name || vm.Spec.ResourcePolicyName != resourcePolicy.Name ||
+			vm.Status.UniqueID == "" || !clustermodules.IsMember(vm, moduleSpec.GroupName) {
+			continue
+		}
+		vms = append(vms, vimTypes.ManagedObjectReference{Type: "VirtualMachine", Value: vm.Status.UniqueID})
 	}
 
-	return s.Client.ClusterModuleClient().AddMoRefToModule(vmCtx, moduleUUID, resVM.MoRef())
+	rule := vcenter.ClusterVMRule{
+		Name:      clustermodules.RuleNamePrefix(resourcePolicy) + moduleSpec.GroupName,
+		Affinity:  moduleSpec.Type == vmopv1.ClusterModuleTypeAffinity,
+		Preferred: moduleSpec.Enforcement != vmopv1.ClusterModuleEnforcementRequired,
+		VMs:       vms,
+	}
+	return vcenter.UpdateClusterVMRule(vmCtx, s.Cluster, rule)
+}
+
+// ClassUpdateGuestShutdownTimeout is how long the guest OS of a VM that is restarted to
+// apply a VM Class update is given to shut down before the VM is powered off.
+var ClassUpdateGuestShutdownTimeout = 5 * time.Minute
+
+// ClassUpdateGuestShutdownRequeueDelay is how long to wait before checking again whether the
+// guest OS of a VM that is restarted to apply a VM Class update has shut down.
+const ClassUpdateGuestShutdownRequeueDelay = 10 * time.Second
+
+// shutdownForClassUpdate shuts down a VM that is restarted to apply a VM Class update, and
+// returns true once the VM is powered off. The guest OS is requested to shut down first, and
+// the time of the request is recorded in the restart annotation so the following reconciles
+// wait for the guest OS: a GuestShutdownInProgressError is returned while the guest OS shuts
+// down. The VM is powered off when the guest OS cannot be shut down, or does not shut down
+// within the ClassUpdateGuestShutdownTimeout.
+func shutdownForClassUpdate(
+	vmCtx context.VirtualMachineContext,
+	resVM *res.VirtualMachine) (bool, error) {
+
+	requested, err := time.Parse(time.RFC3339, vmCtx.VM.Annotations[vmopv1.ClassUpdateRestartAnnotation])
+	if err != nil {
+		vmCtx.Logger.Info("Shutting down guest OS to apply VirtualMachineClass update")
+		if err := resVM.ShutdownGuest(vmCtx); err != nil {
+			vmCtx.Logger.Error(err, "Failed to shut down guest OS, powering off VM to apply VirtualMachineClass update")
+			return powerOffForClassUpdate(vmCtx, resVM)
+		}
+
+		requested = time.Now().UTC()
+		vmCtx.VM.Annotations[vmopv1.ClassUpdateRestartAnnotation] = requested.Format(time.RFC3339)
+	}
+
+	powerState, err := resVM.VcVM().PowerState(vmCtx)
+	if err != nil {
+		return false, err
+	}
+	if powerState == vimTypes.VirtualMachinePowerStatePoweredOff {
+		return true, nil
+	}
+
+	if time.Since(requested) < ClassUpdateGuestShutdownTimeout {
+		vmCtx.Logger.Info("Waiting for guest OS to shut down to apply VirtualMachineClass update",
+			"requested", requested)
+		return false, vmprovider.GuestShutdownInProgressError{RequeueAfter: ClassUpdateGuestShutdownRequeueDelay}
+	}
+
+	vmCtx.Logger.Info("Guest OS did not shut down in time, powering off VM to apply VirtualMachineClass update",
+		"requested", requested)
+	return powerOffForClassUpdate(vmCtx, resVM)
+}
+
+func powerOffForClassUpdate(
+	vmCtx context.VirtualMachineContext,
+	resVM *res.VirtualMachine) (bool, error) {
+
+	if err := resVM.SetPowerState(vmCtx, vmopv1.VirtualMachinePoweredOff); err != nil {
+		return false, err
+	}
+	return true, nil
 }
 
 func (s *Session) UpdateVirtualMachine(
@@ -801,12 +921,22 @@ func (s *Session) UpdateVirtualMachine(
 		}
 	}()
 
+	if err := s.updateAffinityRules(vmCtx, resVM.MoRef()); err != nil {
+		return err
+	}
+
 	isOff := moVM.Runtime.PowerState == vimTypes.VirtualMachinePowerStatePoweredOff
 
 	switch vmCtx.VM.Spec.PowerState {
 	case vmopv1.VirtualMachinePoweredOff:
 		if !isOff {
-			err := resVM.SetPowerState(vmCtx, vmopv1.VirtualMachinePoweredOff)
+			release, err := s.acquireOperation(scheduler.OperationPower)
+			if err != nil {
+				return err
+			}
+			defer release()
+
+			err = resVM.SetPowerState(vmCtx, vmopv1.VirtualMachinePoweredOff)
 			if err != nil {
 				return err
 			}
@@ -824,6 +954,24 @@ func (s *Session) UpdateVirtualMachine(
 			return fmt.Errorf("VM config is not available, connectionState=%s", moVM.Runtime.ConnectionState)
 		}
 
+		_, restart := vmCtx.VM.Annotations[vmopv1.ClassUpdateRestartAnnotation]
+		if isOff || restart {
+			release, err := s.acquireOperation(scheduler.OperationPower)
+			if err != nil {
+				return err
+			}
+			defer release()
+		}
+
+		// The VM controller requested a restart so the changes to the VM Class are applied
+		// during the pre power on reconfigure.
+		if restart && !isOff {
+			isOff, err = shutdownForClassUpdate(vmCtx, resVM)
+			if err != nil {
+				return err
+			}
+		}
+
 		if isOff {
 			updateArgs, err := getUpdateArgsFn()
 			if err != nil {
@@ -848,6 +996,12 @@ func (s *Session) UpdateVirtualMachine(
 				vmCtx.VM.Annotations = map[string]string{}
 			}
 			vmCtx.VM.Annotations[FirstBootDoneAnnotation] = "true"
+
+			if updateArgs.ApplyClassHardware {
+				vmCtx.VM.Status.ClassGeneration = updateArgs.VMClass.Generation
+				delete(vmCtx.VM.Annotations, vmopv1.ClassUpdateRestartAnnotation)
+				conditions.MarkTrue(vmCtx.VM, vmopv1.VirtualMachineClassUpToDateCondition)
+			}
 		} else {
 			// don't pass classConfigSpec to poweredOnVMReconfigure when VM is already powered on
 			// since we don't have to get VM class at this point.
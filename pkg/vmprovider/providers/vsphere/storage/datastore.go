@@ -0,0 +1,94 @@
+// Copyright (c) 2023 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package storage
+
+import (
+	"fmt"
+
+	"github.com/pkg/errors"
+	"github.com/vmware/govmomi/pbm"
+	pbmTypes "github.com/vmware/govmomi/pbm/types"
+	"github.com/vmware/govmomi/property"
+	"github.com/vmware/govmomi/vim25/mo"
+	vimTypes "github.com/vmware/govmomi/vim25/types"
+
+	"github.com/vmware-tanzu/vm-operator/pkg/context"
+	vcclient "github.com/vmware-tanzu/vm-operator/pkg/vmprovider/providers/vsphere/client"
+)
+
+// SelectDatastoreForProfile returns the datastore, among the candidate datastores, that is
+// compatible with the storage profile and has the most free space.
+func SelectDatastoreForProfile(
+	vmCtx context.VirtualMachineContext,
+	vcClient *vcclient.Client,
+	storageProfileID string,
+	candidates []vimTypes.ManagedObjectReference) (vimTypes.ManagedObjectReference, error) {
+
+	if len(candidates) == 0 {
+		return vimTypes.ManagedObjectReference{}, fmt.Errorf("no datastores available")
+	}
+
+	c, err := pbm.NewClient(vmCtx, vcClient.VimClient())
+	if err != nil {
+		return vimTypes.ManagedObjectReference{}, err
+	}
+
+	hubs := make([]pbmTypes.PbmPlacementHub, 0, len(candidates))
+	for _, ds := range candidates {
+		hubs = append(hubs, pbmTypes.PbmPlacementHub{HubType: ds.Type, HubId: ds.Value})
+	}
+
+	req := []pbmTypes.BasePbmPlacementRequirement{
+		&pbmTypes.PbmPlacementCapabilityProfileRequirement{
+			ProfileId: pbmTypes.PbmProfileId{UniqueId: storageProfileID},
+		},
+	}
+
+	result, err := c.CheckRequirements(vmCtx, hubs, nil, req)
+	if err != nil {
+		return vimTypes.ManagedObjectReference{}, errors.Wrapf(err,
+			"failed to check datastores compatibility with storage profile %s", storageProfileID)
+	}
+
+	isCandidate := make(map[string]bool, len(candidates))
+	for _, ds := range candidates {
+		isCandidate[ds.Value] = true
+	}
+
+	var compatible []vimTypes.ManagedObjectReference
+	for _, hub := range result.CompatibleDatastores() {
+		if isCandidate[hub.HubId] {
+			compatible = append(compatible, vimTypes.ManagedObjectReference{Type: hub.HubType, Value: hub.HubId})
+		}
+	}
+
+	if len(compatible) == 0 {
+		return vimTypes.ManagedObjectReference{}, fmt.Errorf(
+			"no datastores compatible with storage profile %s", storageProfileID)
+	}
+
+	var datastores []mo.Datastore
+	pc := property.DefaultCollector(vcClient.VimClient())
+	if err := pc.Retrieve(vmCtx, compatible, []string{"summary"}, &datastores); err != nil {
+		return vimTypes.ManagedObjectReference{}, err
+	}
+
+	var selected *mo.Datastore
+	for i := range datastores {
+		ds := &datastores[i]
+		if !ds.Summary.Accessible {
+			continue
+		}
+		if selected == nil || ds.Summary.FreeSpace > selected.Summary.FreeSpace {
+			selected = ds
+		}
+	}
+
+	if selected == nil {
+		return vimTypes.ManagedObjectReference{}, fmt.Errorf(
+			"no accessible datastores compatible with storage profile %s", storageProfileID)
+	}
+
+	return selected.Reference(), nil
+}
@@ -0,0 +1,269 @@
+// Copyright (c) 2023 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package vcenter
+
+import (
+	goctx "context"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/vmware/govmomi/object"
+	"github.com/vmware/govmomi/property"
+	"github.com/vmware/govmomi/vim25/mo"
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+// ClusterVMRule is a DRS VM-VM affinity or anti-affinity rule. The rule is
+// mandatory unless it is Preferred.
+type ClusterVMRule struct {
+	Name      string
+	Affinity  bool
+	Preferred bool
+	VMs       []types.ManagedObjectReference
+}
+
+// UpdateClusterVMRules updates the DRS VM-VM rules of the cluster whose name
+// has the prefix to the rules: missing rules are added, changed rules are
+// edited, and the other rules with the prefix are removed. VMs that are not in
+// the cluster are omitted from the rules, and a rule with fewer than two VMs
+// is removed since DRS does not allow it.
+func UpdateClusterVMRules(
+	ctx goctx.Context,
+	cluster *object.ClusterComputeResource,
+	namePrefix string,
+	rules []ClusterVMRule) error {
+
+	config, err := cluster.Configuration(ctx)
+	if err != nil {
+		return errors.Wrapf(err, "failed to get cluster %s configuration", cluster.Reference().Value)
+	}
+
+	existing := map[string]types.BaseClusterRuleInfo{}
+	for _, rule := range config.Rule {
+		if info := rule.GetClusterRuleInfo(); strings.HasPrefix(info.Name, namePrefix) {
+			existing[info.Name] = rule
+		}
+	}
+
+	var clusterVMs map[types.ManagedObjectReference]struct{}
+	if len(rules) > 0 {
+		if clusterVMs, err = GetClusterVMs(ctx, cluster); err != nil {
+			return err
+		}
+	}
+
+	var specs []types.ClusterRuleSpec
+	for _, rule := range rules {
+		current := existing[rule.Name]
+		delete(existing, rule.Name)
+		specs = append(specs, clusterVMRuleSpecs(rule, clusterVMs, current)...)
+	}
+
+	for _, rule := range existing {
+		specs = append(specs, removeClusterRuleSpec(rule))
+	}
+
+	if len(specs) == 0 {
+		return nil
+	}
+
+	return reconfigureCluster(ctx, cluster, &types.ClusterConfigSpecEx{RulesSpec: specs})
+}
+
+// UpdateClusterVMRule updates the DRS VM-VM rule of the cluster with the
+// rule's name to the rule, like UpdateClusterVMRules does, without changing the
+// other rules of the cluster.
+func UpdateClusterVMRule(
+	ctx goctx.Context,
+	cluster *object.ClusterComputeResource,
+	rule ClusterVMRule) error {
+
+	config, err := cluster.Configuration(ctx)
+	if err != nil {
+		return errors.Wrapf(err, "failed to get cluster %s configuration", cluster.Reference().Value)
+	}
+
+	var current types.BaseClusterRuleInfo
+	for _, r := range config.Rule {
+		if r.GetClusterRuleInfo().Name == rule.Name {
+			current = r
+			break
+		}
+	}
+
+	clusterVMs, err := GetClusterVMs(ctx, cluster)
+	if err != nil {
+		return err
+	}
+
+	specs := clusterVMRuleSpecs(rule, clusterVMs, current)
+	if len(specs) == 0 {
+		return nil
+	}
+
+	return reconfigureCluster(ctx, cluster, &types.ClusterConfigSpecEx{RulesSpec: specs})
+}
+
+// clusterVMRuleSpecs returns the specs that add the rule, or change the current
+// rule to it, or remove the current rule when fewer than two of its VMs are in
+// the cluster.
+func clusterVMRuleSpecs(
+	rule ClusterVMRule,
+	clusterVMs map[types.ManagedObjectReference]struct{},
+	current types.BaseClusterRuleInfo) []types.ClusterRuleSpec {
+
+	var vms []types.ManagedObjectReference
+	for _, vm := range rule.VMs {
+		if _, ok := clusterVMs[vm]; ok {
+			vms = append(vms, vm)
+		}
+	}
+
+	if len(vms) < 2 {
+		if current != nil {
+			return []types.ClusterRuleSpec{removeClusterRuleSpec(current)}
+		}
+		return nil
+	}
+
+	info := newClusterVMRuleInfo(rule, vms)
+	addSpec := types.ClusterRuleSpec{
+		ArrayUpdateSpec: types.ArrayUpdateSpec{Operation: types.ArrayUpdateOperationAdd},
+		Info:            info,
+	}
+
+	switch {
+	case current == nil:
+		return []types.ClusterRuleSpec{addSpec}
+	case isClusterVMRuleInfoEqual(current, info):
+		return nil
+	case isClusterVMAffinityRule(current) != rule.Affinity:
+		// Remove and add the rule when its type changed since edit cannot
+		// change the type of the rule.
+		return []types.ClusterRuleSpec{removeClusterRuleSpec(current), addSpec}
+	}
+
+	info.GetClusterRuleInfo().Key = current.GetClusterRuleInfo().Key
+	info.GetClusterRuleInfo().RuleUuid = current.GetClusterRuleInfo().RuleUuid
+	return []types.ClusterRuleSpec{
+		{
+			ArrayUpdateSpec: types.ArrayUpdateSpec{Operation: types.ArrayUpdateOperationEdit},
+			Info:            info,
+		},
+	}
+}
+
+// GetClusterVMs returns the VMs on the hosts of the cluster.
+func GetClusterVMs(
+	ctx goctx.Context,
+	cluster *object.ClusterComputeResource) (map[types.ManagedObjectReference]struct{}, error) {
+
+	vmHosts, err := getClusterVMHosts(ctx, cluster)
+	if err != nil {
+		return nil, err
+	}
+
+	vms := make(map[types.ManagedObjectReference]struct{}, len(vmHosts))
+	for vm := range vmHosts {
+		vms[vm] = struct{}{}
+	}
+
+	return vms, nil
+}
+
+// getClusterVMHosts returns the host of each of the VMs on the hosts of the cluster.
+func getClusterVMHosts(
+	ctx goctx.Context,
+	cluster *object.ClusterComputeResource) (map[types.ManagedObjectReference]types.ManagedObjectReference, error) {
+
+	var cr mo.ComputeResource
+	if err := cluster.Properties(ctx, cluster.Reference(), []string{"host"}, &cr); err != nil {
+		return nil, errors.Wrapf(err, "failed to get cluster %s hosts", cluster.Reference().Value)
+	}
+
+	vmHosts := map[types.ManagedObjectReference]types.ManagedObjectReference{}
+	if len(cr.Host) == 0 {
+		return vmHosts, nil
+	}
+
+	var hosts []mo.HostSystem
+	pc := property.DefaultCollector(cluster.Client())
+	if err := pc.Retrieve(ctx, cr.Host, []string{"vm"}, &hosts); err != nil {
+		return nil, errors.Wrapf(err, "failed to get cluster %s host VMs", cluster.Reference().Value)
+	}
+
+	for _, host := range hosts {
+		for _, vm := range host.Vm {
+			vmHosts[vm] = host.Reference()
+		}
+	}
+
+	return vmHosts, nil
+}
+
+func newClusterVMRuleInfo(rule ClusterVMRule, vms []types.ManagedObjectReference) types.BaseClusterRuleInfo {
+	info := types.ClusterRuleInfo{
+		Name:        rule.Name,
+		Enabled:     types.NewBool(true),
+		Mandatory:   types.NewBool(!rule.Preferred),
+		UserCreated: types.NewBool(true),
+	}
+
+	if rule.Affinity {
+		return &types.ClusterAffinityRuleSpec{ClusterRuleInfo: info, Vm: vms}
+	}
+	return &types.ClusterAntiAffinityRuleSpec{ClusterRuleInfo: info, Vm: vms}
+}
+
+func isClusterVMAffinityRule(rule types.BaseClusterRuleInfo) bool {
+	_, ok := rule.(*types.ClusterAffinityRuleSpec)
+	return ok
+}
+
+func clusterVMRuleVMs(rule types.BaseClusterRuleInfo) []types.ManagedObjectReference {
+	switch r := rule.(type) {
+	case *types.ClusterAffinityRuleSpec:
+		return r.Vm
+	case *types.ClusterAntiAffinityRuleSpec:
+		return r.Vm
+	}
+	return nil
+}
+
+func isClusterVMRuleInfoEqual(a, b types.BaseClusterRuleInfo) bool {
+	if isClusterVMAffinityRule(a) != isClusterVMAffinityRule(b) {
+		return false
+	}
+
+	aVMs, bVMs := clusterVMRuleVMs(a), clusterVMRuleVMs(b)
+	if len(aVMs) != len(bVMs) {
+		return false
+	}
+
+	vms := map[types.ManagedObjectReference]struct{}{}
+	for _, vm := range aVMs {
+		vms[vm] = struct{}{}
+	}
+	for _, vm := range bVMs {
+		if _, ok := vms[vm]; !ok {
+			return false
+		}
+	}
+
+	aInfo, bInfo := a.GetClusterRuleInfo(), b.GetClusterRuleInfo()
+	return isTrue(aInfo.Enabled) == isTrue(bInfo.Enabled) && isTrue(aInfo.Mandatory) == isTrue(bInfo.Mandatory)
+}
+
+func isTrue(b *bool) bool {
+	return b != nil && *b
+}
+
+func removeClusterRuleSpec(rule types.BaseClusterRuleInfo) types.ClusterRuleSpec {
+	return types.ClusterRuleSpec{
+		ArrayUpdateSpec: types.ArrayUpdateSpec{
+			Operation: types.ArrayUpdateOperationRemove,
+			RemoveKey: rule.GetClusterRuleInfo().Key,
+		},
+	}
+}
@@ -0,0 +1,303 @@
+// Copyright (c) 2023 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package vcenter_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/vmware/govmomi/object"
+	"github.com/vmware/govmomi/property"
+	"github.com/vmware/govmomi/vim25/mo"
+	"github.com/vmware/govmomi/vim25/types"
+
+	"github.com/vmware-tanzu/vm-operator/pkg/vmprovider/providers/vsphere/vcenter"
+	"github.com/vmware-tanzu/vm-operator/test/builder"
+)
+
+func drsRulesTests() {
+	Describe("UpdateClusterVMRules", updateClusterVMRules)
+	Describe("UpdateClusterVMHostRules", updateClusterVMHostRules)
+}
+
+func updateClusterVMRules() {
+	const prefix = "vmoperator:my-ns/my-vm:"
+
+	var (
+		ctx        *builder.TestContextForVCSim
+		testConfig builder.VCSimTestConfig
+
+		cluster *object.ClusterComputeResource
+		vms     []types.ManagedObjectReference
+	)
+
+	getRules := func() map[string]types.BaseClusterRuleInfo {
+		config, err := cluster.Configuration(ctx)
+		Expect(err).ToNot(HaveOccurred())
+
+		rules := map[string]types.BaseClusterRuleInfo{}
+		for _, rule := range config.Rule {
+			rules[rule.GetClusterRuleInfo().Name] = rule
+		}
+		return rules
+	}
+
+	BeforeEach(func() {
+		testConfig = builder.VCSimTestConfig{}
+	})
+
+	JustBeforeEach(func() {
+		ctx = suite.NewTestContextForVCSim(testConfig)
+		cluster = ctx.GetSingleClusterCompute()
+
+		var cr mo.ComputeResource
+		Expect(cluster.Properties(ctx, cluster.Reference(), []string{"host"}, &cr)).To(Succeed())
+
+		var hosts []mo.HostSystem
+		Expect(property.DefaultCollector(ctx.VCClient.Client).Retrieve(ctx, cr.Host, []string{"vm"}, &hosts)).To(Succeed())
+
+		vms = nil
+		for _, host := range hosts {
+			vms = append(vms, host.Vm...)
+		}
+		Expect(vms).ToNot(BeEmpty())
+
+		// Clone a VM so the cluster has at least three VMs.
+		var moVM mo.VirtualMachine
+		vm := object.NewVirtualMachine(ctx.VCClient.Client, vms[0])
+		Expect(vm.Properties(ctx, vm.Reference(), []string{"parent"}, &moVM)).To(Succeed())
+
+		task, err := vm.Clone(ctx, object.NewFolder(ctx.VCClient.Client, *moVM.Parent), "drs-rules-test-vm", types.VirtualMachineCloneSpec{})
+		Expect(err).ToNot(HaveOccurred())
+		info, err := task.WaitForResult(ctx)
+		Expect(err).ToNot(HaveOccurred())
+		vms = append(vms, info.Result.(types.ManagedObjectReference))
+		Expect(len(vms)).To(BeNumerically(">=", 3))
+	})
+
+	AfterEach(func() {
+		ctx.AfterEach()
+		ctx = nil
+	})
+
+	It("adds, edits and removes the rules with the prefix", func() {
+		Expect(vcenter.UpdateClusterVMRules(ctx, cluster, prefix, []vcenter.ClusterVMRule{
+			{Name: prefix + "affinity-0", Affinity: true, VMs: vms[:2]},
+			{Name: prefix + "anti-affinity-0", VMs: vms[:2]},
+		})).To(Succeed())
+
+		rules := getRules()
+		Expect(rules).To(HaveKey(prefix + "affinity-0"))
+		Expect(rules[prefix+"affinity-0"]).To(BeAssignableToTypeOf(&types.ClusterAffinityRuleSpec{}))
+		Expect(*rules[prefix+"affinity-0"].GetClusterRuleInfo().Mandatory).To(BeTrue())
+		Expect(rules).To(HaveKey(prefix + "anti-affinity-0"))
+		Expect(rules[prefix+"anti-affinity-0"]).To(BeAssignableToTypeOf(&types.ClusterAntiAffinityRuleSpec{}))
+
+		By("editing the VMs of a rule and removing the other rule", func() {
+			Expect(vcenter.UpdateClusterVMRules(ctx, cluster, prefix, []vcenter.ClusterVMRule{
+				{Name: prefix + "affinity-0", Affinity: true, VMs: vms[:3]},
+			})).To(Succeed())
+
+			rules := getRules()
+			Expect(rules).To(HaveKey(prefix + "affinity-0"))
+			Expect(rules[prefix+"affinity-0"].(*types.ClusterAffinityRuleSpec).Vm).To(ConsistOf(vms[:3]))
+			Expect(rules).ToNot(HaveKey(prefix + "anti-affinity-0"))
+		})
+
+		By("changing the type of a rule", func() {
+			Expect(vcenter.UpdateClusterVMRules(ctx, cluster, prefix, []vcenter.ClusterVMRule{
+				{Name: prefix + "affinity-0", VMs: vms[:3]},
+			})).To(Succeed())
+
+			rules := getRules()
+			Expect(rules[prefix+"affinity-0"]).To(BeAssignableToTypeOf(&types.ClusterAntiAffinityRuleSpec{}))
+		})
+
+		By("removing the rules", func() {
+			Expect(vcenter.UpdateClusterVMRules(ctx, cluster, prefix, nil)).To(Succeed())
+			Expect(getRules()).To(BeEmpty())
+		})
+	})
+
+	It("adds and edits preferred rules", func() {
+		Expect(vcenter.UpdateClusterVMRules(ctx, cluster, prefix, []vcenter.ClusterVMRule{
+			{Name: prefix + "affinity-0", Affinity: true, Preferred: true, VMs: vms[:2]},
+		})).To(Succeed())
+
+		rules := getRules()
+		Expect(rules).To(HaveKey(prefix + "affinity-0"))
+		Expect(*rules[prefix+"affinity-0"].GetClusterRuleInfo().Mandatory).To(BeFalse())
+
+		By("making the rule mandatory", func() {
+			Expect(vcenter.UpdateClusterVMRules(ctx, cluster, prefix, []vcenter.ClusterVMRule{
+				{Name: prefix + "affinity-0", Affinity: true, VMs: vms[:2]},
+			})).To(Succeed())
+
+			rules := getRules()
+			Expect(*rules[prefix+"affinity-0"].GetClusterRuleInfo().Mandatory).To(BeTrue())
+		})
+	})
+
+	It("does not add a rule with fewer than two VMs in the cluster", func() {
+		notInCluster := types.ManagedObjectReference{Type: "VirtualMachine", Value: "vm-does-not-exist"}
+
+		Expect(vcenter.UpdateClusterVMRules(ctx, cluster, prefix, []vcenter.ClusterVMRule{
+			{Name: prefix + "affinity-0", Affinity: true, VMs: []types.ManagedObjectReference{vms[0], notInCluster}},
+		})).To(Succeed())
+		Expect(getRules()).To(BeEmpty())
+	})
+
+	It("does not modify the rules without the prefix", func() {
+		Expect(vcenter.UpdateClusterVMRules(ctx, cluster, "other:", []vcenter.ClusterVMRule{
+			{Name: "other:affinity-0", Affinity: true, VMs: vms[:2]},
+		})).To(Succeed())
+
+		Expect(vcenter.UpdateClusterVMRules(ctx, cluster, prefix, nil)).To(Succeed())
+		Expect(getRules()).To(HaveKey("other:affinity-0"))
+	})
+
+	It("updates a single rule without modifying the other rules", func() {
+		Expect(vcenter.UpdateClusterVMRules(ctx, cluster, prefix, []vcenter.ClusterVMRule{
+			{Name: prefix + "db", VMs: vms[:2]},
+			{Name: prefix + "db2", VMs: vms[:2]},
+		})).To(Succeed())
+
+		Expect(vcenter.UpdateClusterVMRule(ctx, cluster,
+			vcenter.ClusterVMRule{Name: prefix + "db", VMs: vms[:3]})).To(Succeed())
+
+		rules := getRules()
+		Expect(rules[prefix+"db"].(*types.ClusterAntiAffinityRuleSpec).Vm).To(ConsistOf(vms[:3]))
+		Expect(rules[prefix+"db2"].(*types.ClusterAntiAffinityRuleSpec).Vm).To(ConsistOf(vms[:2]))
+
+		By("adding a rule that does not exist", func() {
+			Expect(vcenter.UpdateClusterVMRule(ctx, cluster,
+				vcenter.ClusterVMRule{Name: prefix + "web", Affinity: true, VMs: vms[1:3]})).To(Succeed())
+
+			rules := getRules()
+			Expect(rules).To(HaveLen(3))
+			Expect(rules[prefix+"web"].(*types.ClusterAffinityRuleSpec).Vm).To(ConsistOf(vms[1:3]))
+		})
+	})
+}
+
+func updateClusterVMHostRules() {
+	const prefix = "vmoperator:my-ns/my-vm:"
+
+	var (
+		ctx     *builder.TestContextForVCSim
+		cluster *object.ClusterComputeResource
+		vms     []types.ManagedObjectReference
+	)
+
+	getConfig := func() (map[string]types.BaseClusterRuleInfo, map[string]types.BaseClusterGroupInfo) {
+		config, err := cluster.Configuration(ctx)
+		Expect(err).ToNot(HaveOccurred())
+
+		rules := map[string]types.BaseClusterRuleInfo{}
+		for _, rule := range config.Rule {
+			rules[rule.GetClusterRuleInfo().Name] = rule
+		}
+		groups := map[string]types.BaseClusterGroupInfo{}
+		for _, group := range config.Group {
+			groups[group.GetClusterGroupInfo().Name] = group
+		}
+		return rules, groups
+	}
+
+	getHost := func(vm types.ManagedObjectReference) types.ManagedObjectReference {
+		var moVM mo.VirtualMachine
+		Expect(object.NewVirtualMachine(ctx.VCClient.Client, vm).Properties(
+			ctx, vm, []string{"runtime.host"}, &moVM)).To(Succeed())
+		return *moVM.Runtime.Host
+	}
+
+	BeforeEach(func() {
+		ctx = suite.NewTestContextForVCSim(builder.VCSimTestConfig{})
+		cluster = ctx.GetSingleClusterCompute()
+
+		var cr mo.ComputeResource
+		Expect(cluster.Properties(ctx, cluster.Reference(), []string{"host"}, &cr)).To(Succeed())
+
+		var hosts []mo.HostSystem
+		Expect(property.DefaultCollector(ctx.VCClient.Client).Retrieve(ctx, cr.Host, []string{"vm"}, &hosts)).To(Succeed())
+
+		vms = nil
+		for _, host := range hosts {
+			vms = append(vms, host.Vm...)
+		}
+		Expect(len(vms)).To(BeNumerically(">=", 2))
+	})
+
+	AfterEach(func() {
+		ctx.AfterEach()
+		ctx = nil
+	})
+
+	It("adds, edits and removes the rules and groups with the prefix", func() {
+		Expect(vcenter.UpdateClusterVMHostRules(ctx, cluster, prefix, []vcenter.ClusterVMHostRule{
+			{Name: prefix + "affinity-0", Affinity: true, VMs: vms[:1], HostVMs: vms[1:2]},
+		})).To(Succeed())
+
+		rules, groups := getConfig()
+		Expect(rules).To(HaveKey(prefix + "affinity-0"))
+		rule, ok := rules[prefix+"affinity-0"].(*types.ClusterVmHostRuleInfo)
+		Expect(ok).To(BeTrue())
+		Expect(*rule.Mandatory).To(BeTrue())
+		Expect(rule.VmGroupName).To(Equal(prefix + "affinity-0-vms"))
+		Expect(rule.AffineHostGroupName).To(Equal(prefix + "affinity-0-hosts"))
+		Expect(rule.AntiAffineHostGroupName).To(BeEmpty())
+		Expect(groups[prefix+"affinity-0-vms"].(*types.ClusterVmGroup).Vm).To(ConsistOf(vms[0]))
+		Expect(groups[prefix+"affinity-0-hosts"].(*types.ClusterHostGroup).Host).To(ConsistOf(getHost(vms[1])))
+
+		By("changing the rule to anti-affinity and adding hosts", func() {
+			Expect(vcenter.UpdateClusterVMHostRules(ctx, cluster, prefix, []vcenter.ClusterVMHostRule{
+				{Name: prefix + "affinity-0", VMs: vms[:1], HostVMs: vms},
+			})).To(Succeed())
+
+			rules, groups := getConfig()
+			rule := rules[prefix+"affinity-0"].(*types.ClusterVmHostRuleInfo)
+			Expect(rule.AffineHostGroupName).To(BeEmpty())
+			Expect(rule.AntiAffineHostGroupName).To(Equal(prefix + "affinity-0-hosts"))
+
+			var hosts []types.ManagedObjectReference
+			for _, vm := range vms {
+				hosts = append(hosts, getHost(vm))
+			}
+			Expect(hosts).To(ContainElements(groups[prefix+"affinity-0-hosts"].(*types.ClusterHostGroup).Host))
+		})
+
+		By("removing the rules and groups", func() {
+			Expect(vcenter.UpdateClusterVMHostRules(ctx, cluster, prefix, nil)).To(Succeed())
+			rules, groups := getConfig()
+			Expect(rules).To(BeEmpty())
+			Expect(groups).To(BeEmpty())
+		})
+	})
+
+	It("replaces a VM-VM rule with the same name", func() {
+		Expect(vcenter.UpdateClusterVMRules(ctx, cluster, prefix, []vcenter.ClusterVMRule{
+			{Name: prefix + "affinity-0", Affinity: true, VMs: vms[:2]},
+		})).To(Succeed())
+
+		Expect(vcenter.UpdateClusterVMHostRules(ctx, cluster, prefix, []vcenter.ClusterVMHostRule{
+			{Name: prefix + "affinity-0", Affinity: true, VMs: vms[:1], HostVMs: vms[1:2]},
+		})).To(Succeed())
+
+		rules, _ := getConfig()
+		Expect(rules).To(HaveLen(1))
+		Expect(rules[prefix+"affinity-0"]).To(BeAssignableToTypeOf(&types.ClusterVmHostRuleInfo{}))
+	})
+
+	It("does not add a rule without hosts", func() {
+		notInCluster := types.ManagedObjectReference{Type: "VirtualMachine", Value: "vm-does-not-exist"}
+
+		Expect(vcenter.UpdateClusterVMHostRules(ctx, cluster, prefix, []vcenter.ClusterVMHostRule{
+			{Name: prefix + "anti-affinity-0", VMs: vms[:1], HostVMs: []types.ManagedObjectReference{notInCluster}},
+		})).To(Succeed())
+
+		rules, groups := getConfig()
+		Expect(rules).To(BeEmpty())
+		Expect(groups).To(BeEmpty())
+	})
+}
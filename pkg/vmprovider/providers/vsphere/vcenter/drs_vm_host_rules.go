@@ -0,0 +1,255 @@
+// Copyright (c) 2023 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package vcenter
+
+import (
+	goctx "context"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/vmware/govmomi/object"
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+const (
+	vmGroupNameSuffix   = "-vms"
+	hostGroupNameSuffix = "-hosts"
+)
+
+// ClusterVMHostRule is a mandatory DRS VM/host rule that keeps the VMs of its
+// VM group on, or off, the hosts of its host group. The VM group and the host
+// group are named after the rule, with the "-vms" and "-hosts" suffixes.
+type ClusterVMHostRule struct {
+	Name     string
+	Affinity bool
+
+	// VMs are the VMs of the VM group.
+	VMs []types.ManagedObjectReference
+
+	// HostVMs are the VMs whose hosts are the hosts of the host group.
+	HostVMs []types.ManagedObjectReference
+}
+
+// UpdateClusterVMHostRules updates the DRS VM/host rules and groups of the
+// cluster whose name has the prefix to the rules: missing rules and groups are
+// added, changed ones are edited, and the other rules and groups with the
+// prefix are removed. VMs that are not in the cluster are omitted, and a rule
+// whose VM group or host group would be empty is removed.
+func UpdateClusterVMHostRules(
+	ctx goctx.Context,
+	cluster *object.ClusterComputeResource,
+	namePrefix string,
+	rules []ClusterVMHostRule) error {
+
+	config, err := cluster.Configuration(ctx)
+	if err != nil {
+		return errors.Wrapf(err, "failed to get cluster %s configuration", cluster.Reference().Value)
+	}
+
+	existingRules := map[string]types.BaseClusterRuleInfo{}
+	for _, rule := range config.Rule {
+		if info := rule.GetClusterRuleInfo(); strings.HasPrefix(info.Name, namePrefix) {
+			existingRules[info.Name] = rule
+		}
+	}
+
+	existingGroups := map[string]types.BaseClusterGroupInfo{}
+	for _, group := range config.Group {
+		if info := group.GetClusterGroupInfo(); strings.HasPrefix(info.Name, namePrefix) {
+			existingGroups[info.Name] = group
+		}
+	}
+
+	var vmHosts map[types.ManagedObjectReference]types.ManagedObjectReference
+	if len(rules) > 0 {
+		if vmHosts, err = getClusterVMHosts(ctx, cluster); err != nil {
+			return err
+		}
+	}
+
+	// The groups must exist before the rules that use them are added, and the
+	// rules must be removed before the groups that they use are removed.
+	var groupSpecs, removeGroupSpecs []types.ClusterGroupSpec
+	var ruleSpecs []types.ClusterRuleSpec
+
+	updateGroup := func(group types.BaseClusterGroupInfo) {
+		name := group.GetClusterGroupInfo().Name
+		current, ok := existingGroups[name]
+		delete(existingGroups, name)
+
+		switch {
+		case !ok:
+			groupSpecs = append(groupSpecs, types.ClusterGroupSpec{
+				ArrayUpdateSpec: types.ArrayUpdateSpec{Operation: types.ArrayUpdateOperationAdd},
+				Info:            group,
+			})
+		case !isClusterGroupEqual(current, group):
+			groupSpecs = append(groupSpecs, types.ClusterGroupSpec{
+				ArrayUpdateSpec: types.ArrayUpdateSpec{Operation: types.ArrayUpdateOperationEdit},
+				Info:            group,
+			})
+		}
+	}
+
+	for _, rule := range rules {
+		var vms []types.ManagedObjectReference
+		for _, vm := range rule.VMs {
+			if _, ok := vmHosts[vm]; ok {
+				vms = append(vms, vm)
+			}
+		}
+
+		var hosts []types.ManagedObjectReference
+		seenHosts := map[types.ManagedObjectReference]struct{}{}
+		for _, vm := range rule.HostVMs {
+			if host, ok := vmHosts[vm]; ok {
+				if _, seen := seenHosts[host]; !seen {
+					seenHosts[host] = struct{}{}
+					hosts = append(hosts, host)
+				}
+			}
+		}
+
+		if len(vms) == 0 || len(hosts) == 0 {
+			continue
+		}
+
+		vmGroupName, hostGroupName := rule.Name+vmGroupNameSuffix, rule.Name+hostGroupNameSuffix
+		updateGroup(&types.ClusterVmGroup{
+			ClusterGroupInfo: types.ClusterGroupInfo{Name: vmGroupName, UserCreated: types.NewBool(true)},
+			Vm:               vms,
+		})
+		updateGroup(&types.ClusterHostGroup{
+			ClusterGroupInfo: types.ClusterGroupInfo{Name: hostGroupName, UserCreated: types.NewBool(true)},
+			Host:             hosts,
+		})
+
+		info := &types.ClusterVmHostRuleInfo{
+			ClusterRuleInfo: types.ClusterRuleInfo{
+				Name:        rule.Name,
+				Enabled:     types.NewBool(true),
+				Mandatory:   types.NewBool(true),
+				UserCreated: types.NewBool(true),
+			},
+			VmGroupName: vmGroupName,
+		}
+		if rule.Affinity {
+			info.AffineHostGroupName = hostGroupName
+		} else {
+			info.AntiAffineHostGroupName = hostGroupName
+		}
+
+		if current, ok := existingRules[rule.Name]; ok {
+			delete(existingRules, rule.Name)
+
+			currentInfo, isVMHostRule := current.(*types.ClusterVmHostRuleInfo)
+			switch {
+			case isVMHostRule && isClusterVMHostRuleInfoEqual(currentInfo, info):
+			case isVMHostRule:
+				info.Key = currentInfo.Key
+				info.RuleUuid = currentInfo.RuleUuid
+				ruleSpecs = append(ruleSpecs, types.ClusterRuleSpec{
+					ArrayUpdateSpec: types.ArrayUpdateSpec{Operation: types.ArrayUpdateOperationEdit},
+					Info:            info,
+				})
+			default:
+				// Remove and add the rule since edit cannot change the type of the rule.
+				ruleSpecs = append(ruleSpecs, removeClusterRuleSpec(current))
+				ruleSpecs = append(ruleSpecs, types.ClusterRuleSpec{
+					ArrayUpdateSpec: types.ArrayUpdateSpec{Operation: types.ArrayUpdateOperationAdd},
+					Info:            info,
+				})
+			}
+			continue
+		}
+
+		ruleSpecs = append(ruleSpecs, types.ClusterRuleSpec{
+			ArrayUpdateSpec: types.ArrayUpdateSpec{Operation: types.ArrayUpdateOperationAdd},
+			Info:            info,
+		})
+	}
+
+	for _, rule := range existingRules {
+		ruleSpecs = append(ruleSpecs, removeClusterRuleSpec(rule))
+	}
+
+	for name := range existingGroups {
+		removeGroupSpecs = append(removeGroupSpecs, types.ClusterGroupSpec{
+			ArrayUpdateSpec: types.ArrayUpdateSpec{
+				Operation: types.ArrayUpdateOperationRemove,
+				RemoveKey: name,
+			},
+		})
+	}
+
+	for _, spec := range []*types.ClusterConfigSpecEx{
+		{GroupSpec: groupSpecs},
+		{RulesSpec: ruleSpecs},
+		{GroupSpec: removeGroupSpecs},
+	} {
+		if len(spec.GroupSpec) == 0 && len(spec.RulesSpec) == 0 {
+			continue
+		}
+
+		if err := reconfigureCluster(ctx, cluster, spec); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func reconfigureCluster(
+	ctx goctx.Context,
+	cluster *object.ClusterComputeResource,
+	spec *types.ClusterConfigSpecEx) error {
+
+	task, err := cluster.Reconfigure(ctx, spec, true)
+	if err != nil {
+		return errors.Wrapf(err, "failed to reconfigure cluster %s rules", cluster.Reference().Value)
+	}
+
+	if err := task.Wait(ctx); err != nil {
+		return errors.Wrapf(err, "reconfigure cluster %s rules task failed", cluster.Reference().Value)
+	}
+
+	return nil
+}
+
+func isClusterVMHostRuleInfoEqual(a, b *types.ClusterVmHostRuleInfo) bool {
+	return a.VmGroupName == b.VmGroupName &&
+		a.AffineHostGroupName == b.AffineHostGroupName &&
+		a.AntiAffineHostGroupName == b.AntiAffineHostGroupName &&
+		isTrue(a.Enabled) == isTrue(b.Enabled) &&
+		isTrue(a.Mandatory) == isTrue(b.Mandatory)
+}
+
+func isClusterGroupEqual(a, b types.BaseClusterGroupInfo) bool {
+	switch a := a.(type) {
+	case *types.ClusterVmGroup:
+		b, ok := b.(*types.ClusterVmGroup)
+		return ok && isMoRefSetEqual(a.Vm, b.Vm)
+	case *types.ClusterHostGroup:
+		b, ok := b.(*types.ClusterHostGroup)
+		return ok && isMoRefSetEqual(a.Host, b.Host)
+	}
+	return false
+}
+
+func isMoRefSetEqual(a, b []types.ManagedObjectReference) bool {
+	if len(a) != len(b) {
+		return false
+	}
+
+	refs := map[types.ManagedObjectReference]struct{}{}
+	for _, ref := range a {
+		refs[ref] = struct{}{}
+	}
+	for _, ref := range b {
+		if _, ok := refs[ref]; !ok {
+			return false
+		}
+	}
+	return true
+}
@@ -1,4 +1,4 @@
-// Copyright (c) 2022 VMware, Inc. All Rights Reserved.
+// Copyright (c) 2022-2023 VMware, Inc. All Rights Reserved.
 // SPDX-License-Identifier: Apache-2.0
 
 package vcenter
@@ -17,10 +17,13 @@ import (
 	"github.com/vmware-tanzu/vm-operator/pkg/topology"
 )
 
-// GetVirtualMachine gets the VM from VC, either by the MoID, UUID, or the inventory path.
+// GetVirtualMachine gets the VM from VC, either by the MoID, UUID, or the inventory path. The vimClient is
+// connected to the vCenter and Datacenter of vcRef, and zoneVCenterRefs are the vCenters of the zones.
 func GetVirtualMachine(
 	vmCtx context.VirtualMachineContext,
 	k8sClient ctrlclient.Client,
+	zoneVCenterRefs topology.ZoneVCenterRefs,
+	vcRef topology.VCenterRef,
 	vimClient *vim25.Client,
 	datacenter *object.Datacenter,
 	finder *find.Finder) (*object.VirtualMachine, error) {
@@ -40,7 +43,7 @@ func GetVirtualMachine(
 		}
 	*/
 
-	return findVMByInventory(vmCtx, k8sClient, vimClient, finder)
+	return findVMByInventory(vmCtx, k8sClient, zoneVCenterRefs, vcRef, vimClient, finder)
 }
 
 func findVMByMoID(
@@ -89,6 +92,8 @@ func findVMByUUID(
 func findVMByInventory(
 	vmCtx context.VirtualMachineContext,
 	k8sClient ctrlclient.Client,
+	zoneVCenterRefs topology.ZoneVCenterRefs,
+	vcRef topology.VCenterRef,
 	vimClient *vim25.Client,
 	finder *find.Finder) (*object.VirtualMachine, error) {
 
@@ -97,7 +102,7 @@ func findVMByInventory(
 	// if set, and we'll fetch these again as a part of createVirtualMachine(). For now, just re-fetch
 	// but we could pass the Folder MoID and ResourcePolicy to save a bit of duplicated work.
 
-	folderMoID, err := topology.GetNamespaceFolderMoID(vmCtx, k8sClient, vmCtx.VM.Namespace)
+	folderMoID, err := topology.GetNamespaceFolderMoIDForVCenter(vmCtx, k8sClient, vmCtx.VM.Namespace, zoneVCenterRefs, vcRef)
 	if err != nil {
 		return nil, err
 	}
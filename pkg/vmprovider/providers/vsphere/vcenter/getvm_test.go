@@ -1,4 +1,4 @@
-// Copyright (c) 2022 VMware, Inc. All Rights Reserved.
+// Copyright (c) 2022-2023 VMware, Inc. All Rights Reserved.
 // SPDX-License-Identifier: Apache-2.0
 
 package vcenter_test
@@ -12,6 +12,7 @@ import (
 	"k8s.io/apimachinery/pkg/types"
 
 	"github.com/vmware-tanzu/vm-operator/pkg/context"
+	"github.com/vmware-tanzu/vm-operator/pkg/topology"
 	"github.com/vmware-tanzu/vm-operator/pkg/vmprovider/providers/vsphere/vcenter"
 	"github.com/vmware-tanzu/vm-operator/test/builder"
 )
@@ -57,14 +58,14 @@ func getVM() {
 		})
 
 		It("returns success", func() {
-			vm, err := vcenter.GetVirtualMachine(vmCtx, ctx.Client, ctx.VCClient.Client, ctx.Datacenter, ctx.Finder)
+			vm, err := vcenter.GetVirtualMachine(vmCtx, ctx.Client, nil, topology.VCenterRef{}, ctx.VCClient.Client, ctx.Datacenter, ctx.Finder)
 			Expect(err).ToNot(HaveOccurred())
 			Expect(vm).ToNot(BeNil())
 		})
 
 		It("returns nil if VM does not exist", func() {
 			vmCtx.VM.Name = "bogus"
-			vm, err := vcenter.GetVirtualMachine(vmCtx, ctx.Client, ctx.VCClient.Client, ctx.Datacenter, ctx.Finder)
+			vm, err := vcenter.GetVirtualMachine(vmCtx, ctx.Client, nil, topology.VCenterRef{}, ctx.VCClient.Client, ctx.Datacenter, ctx.Finder)
 			Expect(err).ToNot(HaveOccurred())
 			Expect(vm).To(BeNil())
 		})
@@ -77,7 +78,7 @@ func getVM() {
 			})
 
 			It("returns error", func() {
-				vm, err := vcenter.GetVirtualMachine(vmCtx, ctx.Client, ctx.VCClient.Client, ctx.Datacenter, ctx.Finder)
+				vm, err := vcenter.GetVirtualMachine(vmCtx, ctx.Client, nil, topology.VCenterRef{}, ctx.VCClient.Client, ctx.Datacenter, ctx.Finder)
 				Expect(err).To(HaveOccurred())
 				Expect(err.Error()).To(HavePrefix("failed to get namespace Folder"))
 				Expect(vm).To(BeNil())
@@ -88,7 +89,7 @@ func getVM() {
 			// Expect fallback to inventory.
 			vmCtx.VM.Status.UniqueID = "vm-bogus"
 
-			vm, err := vcenter.GetVirtualMachine(vmCtx, ctx.Client, ctx.VCClient.Client, ctx.Datacenter, ctx.Finder)
+			vm, err := vcenter.GetVirtualMachine(vmCtx, ctx.Client, nil, topology.VCenterRef{}, ctx.VCClient.Client, ctx.Datacenter, ctx.Finder)
 			Expect(err).ToNot(HaveOccurred())
 			Expect(vm).ToNot(BeNil())
 		})
@@ -102,7 +103,7 @@ func getVM() {
 		})
 
 		It("returns success", func() {
-			vm, err := vcenter.GetVirtualMachine(vmCtx, ctx.Client, ctx.VCClient.Client, ctx.Datacenter, ctx.Finder)
+			vm, err := vcenter.GetVirtualMachine(vmCtx, ctx.Client, nil, topology.VCenterRef{}, ctx.VCClient.Client, ctx.Datacenter, ctx.Finder)
 			Expect(err).ToNot(HaveOccurred())
 			Expect(vm).ToNot(BeNil())
 			Expect(vm.Reference().Value).To(Equal(vmCtx.VM.Status.UniqueID))
@@ -121,7 +122,7 @@ func getVM() {
 		})
 
 		It("returns success", func() {
-			vm, err := vcenter.GetVirtualMachine(vmCtx, ctx.Client, ctx.VCClient.Client, ctx.Datacenter, ctx.Finder)
+			vm, err := vcenter.GetVirtualMachine(vmCtx, ctx.Client, nil, topology.VCenterRef{}, ctx.VCClient.Client, ctx.Datacenter, ctx.Finder)
 			Expect(err).ToNot(HaveOccurred())
 			Expect(vm).ToNot(BeNil())
 		})
@@ -141,7 +142,7 @@ func getVM() {
 		})
 
 		It("returns success", func() {
-			vm, err := vcenter.GetVirtualMachine(vmCtx, ctx.Client, ctx.VCClient.Client, ctx.Datacenter, ctx.Finder)
+			vm, err := vcenter.GetVirtualMachine(vmCtx, ctx.Client, nil, topology.VCenterRef{}, ctx.VCClient.Client, ctx.Datacenter, ctx.Finder)
 			Expect(err).ToNot(HaveOccurred())
 			Expect(vm).ToNot(BeNil())
 		})
@@ -149,7 +150,7 @@ func getVM() {
 		It("returns error when ResourcePolicy does not exist", func() {
 			vmCtx.VM.Spec.ResourcePolicyName = "bogus"
 
-			vm, err := vcenter.GetVirtualMachine(vmCtx, ctx.Client, ctx.VCClient.Client, ctx.Datacenter, ctx.Finder)
+			vm, err := vcenter.GetVirtualMachine(vmCtx, ctx.Client, nil, topology.VCenterRef{}, ctx.VCClient.Client, ctx.Datacenter, ctx.Finder)
 			Expect(err).To(HaveOccurred())
 			Expect(err.Error()).To(HavePrefix("failed to get VirtualMachineSetResourcePolicy"))
 			Expect(vm).To(BeNil())
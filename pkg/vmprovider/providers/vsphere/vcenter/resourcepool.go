@@ -1,4 +1,4 @@
-// Copyright (c) 2022 VMware, Inc. All Rights Reserved.
+// Copyright (c) 2022-2023 VMware, Inc. All Rights Reserved.
 // SPDX-License-Identifier: Apache-2.0
 
 package vcenter
@@ -10,9 +10,11 @@ import (
 	"github.com/vmware/govmomi/find"
 	"github.com/vmware/govmomi/object"
 	"github.com/vmware/govmomi/vim25"
+	"github.com/vmware/govmomi/vim25/mo"
 	"github.com/vmware/govmomi/vim25/types"
 
 	vmopv1 "github.com/vmware-tanzu/vm-operator/api/v1alpha1"
+	"github.com/vmware-tanzu/vm-operator/pkg/vmprovider/providers/vsphere/virtualmachine"
 )
 
 // GetResourcePoolByMoID returns the ResourcePool for the MoID.
@@ -81,34 +83,85 @@ func DoesChildResourcePoolExist(
 }
 
 // CreateOrUpdateChildResourcePool creates or updates the child ResourcePool under the parent ResourcePool.
+// The CPU reservation and limit of the rpSpec are converted to MHz with minCPUFreq. The returned bool is
+// true when the reservations or limits of an existing child ResourcePool did not match the rpSpec, for
+// example because they were edited in vCenter, and were updated.
 func CreateOrUpdateChildResourcePool(
 	ctx goctx.Context,
 	vimClient *vim25.Client,
 	parentRPMoID string,
-	rpSpec *vmopv1.ResourcePoolSpec) (string, error) {
+	rpSpec *vmopv1.ResourcePoolSpec,
+	minCPUFreq uint64) (string, bool, error) {
 
 	parentRP := object.NewResourcePool(vimClient,
 		types.ManagedObjectReference{Type: "ResourcePool", Value: parentRPMoID})
 
 	childRP, err := findChildRP(ctx, parentRP, rpSpec.Name)
 	if err != nil {
-		return "", err
+		return "", false, err
 	}
 
-	spec := types.DefaultResourceConfigSpec() // TODO Set reservations & limits from rpSpec
+	spec := ResourcePoolConfigSpec(rpSpec, minCPUFreq)
 
 	if childRP == nil {
 		rp, err := parentRP.Create(ctx, rpSpec.Name, spec)
 		if err != nil {
-			return "", err
+			return "", false, err
 		}
 
-		childRP = rp
-	} else { //nolint
-		// TODO: 		//       Finish this clause
+		return rp.Reference().Value, false, nil
 	}
 
-	return childRP.Reference().Value, nil
+	var o mo.ResourcePool
+	if err := childRP.Properties(ctx, childRP.Reference(), []string{"config"}, &o); err != nil {
+		return "", false, err
+	}
+
+	if resourceAllocationMatches(o.Config.CpuAllocation, spec.CpuAllocation) &&
+		resourceAllocationMatches(o.Config.MemoryAllocation, spec.MemoryAllocation) {
+		return childRP.Reference().Value, false, nil
+	}
+
+	if err := childRP.UpdateConfig(ctx, "", &spec); err != nil {
+		return "", false, err
+	}
+
+	return childRP.Reference().Value, true, nil
+}
+
+// ResourcePoolConfigSpec returns the ResourceConfigSpec with the reservations and limits of the rpSpec.
+// A zero reservation or limit in the rpSpec is no reservation or an unlimited limit.
+func ResourcePoolConfigSpec(rpSpec *vmopv1.ResourcePoolSpec, minCPUFreq uint64) types.ResourceConfigSpec {
+	spec := types.DefaultResourceConfigSpec()
+
+	if !rpSpec.Reservations.Cpu.IsZero() {
+		rsv := virtualmachine.CPUQuantityToMhz(rpSpec.Reservations.Cpu, minCPUFreq)
+		spec.CpuAllocation.Reservation = &rsv
+	}
+	if !rpSpec.Limits.Cpu.IsZero() {
+		lim := virtualmachine.CPUQuantityToMhz(rpSpec.Limits.Cpu, minCPUFreq)
+		spec.CpuAllocation.Limit = &lim
+	}
+	if !rpSpec.Reservations.Memory.IsZero() {
+		rsv := virtualmachine.MemoryQuantityToMb(rpSpec.Reservations.Memory)
+		spec.MemoryAllocation.Reservation = &rsv
+	}
+	if !rpSpec.Limits.Memory.IsZero() {
+		lim := virtualmachine.MemoryQuantityToMb(rpSpec.Limits.Memory)
+		spec.MemoryAllocation.Limit = &lim
+	}
+
+	return spec
+}
+
+// resourceAllocationMatches returns true if the reservation and limit of the actual allocation are
+// those of the desired allocation. The shares and expandable reservation are not compared.
+func resourceAllocationMatches(actual, desired types.ResourceAllocationInfo) bool {
+	int64Equal := func(a, b *int64) bool {
+		return (a == nil && b == nil) || (a != nil && b != nil && *a == *b)
+	}
+
+	return int64Equal(actual.Reservation, desired.Reservation) && int64Equal(actual.Limit, desired.Limit)
 }
 
 // DeleteChildResourcePool deletes the child ResourcePool under the parent ResourcePool.
@@ -1,4 +1,4 @@
-// Copyright (c) 2022 VMware, Inc. All Rights Reserved.
+// Copyright (c) 2022-2023 VMware, Inc. All Rights Reserved.
 // SPDX-License-Identifier: Apache-2.0
 
 package vcenter_test
@@ -8,6 +8,9 @@ import (
 	. "github.com/onsi/gomega"
 
 	"github.com/vmware/govmomi/object"
+	"github.com/vmware/govmomi/vim25/mo"
+	vimtypes "github.com/vmware/govmomi/vim25/types"
+	"k8s.io/apimachinery/pkg/api/resource"
 
 	vmopv1 "github.com/vmware-tanzu/vm-operator/api/v1alpha1"
 
@@ -106,6 +109,8 @@ func createDeleteExistResourcePoolChild() {
 		resourcePolicy *vmopv1.VirtualMachineSetResourcePolicy
 	)
 
+	const minCPUFreq = 1000
+
 	BeforeEach(func() {
 		ctx = suite.NewTestContextForVCSim(builder.VCSimTestConfig{})
 		nsInfo = ctx.CreateWorkloadNamespace()
@@ -127,14 +132,15 @@ func createDeleteExistResourcePoolChild() {
 
 	Context("CreateOrUpdateChildResourcePool", func() {
 		It("creates child ResourcePool", func() {
-			childMoID, err := vcenter.CreateOrUpdateChildResourcePool(ctx, ctx.VCClient.Client, parentRPMoID, &resourcePolicy.Spec.ResourcePool)
+			childMoID, _, err := vcenter.CreateOrUpdateChildResourcePool(ctx, ctx.VCClient.Client, parentRPMoID, &resourcePolicy.Spec.ResourcePool, minCPUFreq)
 			Expect(err).ToNot(HaveOccurred())
 			Expect(childMoID).ToNot(BeEmpty())
 
 			By("returns success when child ResourcePool already exists", func() {
-				moID, err := vcenter.CreateOrUpdateChildResourcePool(ctx, ctx.VCClient.Client, parentRPMoID, &resourcePolicy.Spec.ResourcePool)
+				moID, updated, err := vcenter.CreateOrUpdateChildResourcePool(ctx, ctx.VCClient.Client, parentRPMoID, &resourcePolicy.Spec.ResourcePool, minCPUFreq)
 				Expect(err).ToNot(HaveOccurred())
 				Expect(moID).To(Equal(childMoID))
+				Expect(updated).To(BeFalse())
 			})
 
 			By("child ResourcePool is found by MoID", func() {
@@ -144,8 +150,56 @@ func createDeleteExistResourcePoolChild() {
 			})
 		})
 
+		It("corrects the reservations and limits of the child ResourcePool", func() {
+			resourcePolicy.Spec.ResourcePool.Reservations = vmopv1.VirtualMachineResourceSpec{
+				Cpu:    resource.MustParse("500m"),
+				Memory: resource.MustParse("1Gi"),
+			}
+			resourcePolicy.Spec.ResourcePool.Limits = vmopv1.VirtualMachineResourceSpec{
+				Cpu: resource.MustParse("2"),
+			}
+
+			childMoID, updated, err := vcenter.CreateOrUpdateChildResourcePool(ctx, ctx.VCClient.Client, parentRPMoID, &resourcePolicy.Spec.ResourcePool, minCPUFreq)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(updated).To(BeTrue())
+
+			childRP, err := vcenter.GetResourcePoolByMoID(ctx, ctx.Finder, childMoID)
+			Expect(err).ToNot(HaveOccurred())
+
+			getConfig := func() vimtypes.ResourceConfigSpec {
+				var o mo.ResourcePool
+				Expect(childRP.Properties(ctx, childRP.Reference(), []string{"config"}, &o)).To(Succeed())
+				return o.Config
+			}
+
+			config := getConfig()
+			Expect(*config.CpuAllocation.Reservation).To(BeEquivalentTo(500))
+			Expect(*config.CpuAllocation.Limit).To(BeEquivalentTo(2000))
+			Expect(*config.MemoryAllocation.Reservation).To(BeEquivalentTo(1024))
+			Expect(*config.MemoryAllocation.Limit).To(BeEquivalentTo(-1))
+
+			By("returns false when the child ResourcePool has not drifted", func() {
+				_, updated, err := vcenter.CreateOrUpdateChildResourcePool(ctx, ctx.VCClient.Client, parentRPMoID, &resourcePolicy.Spec.ResourcePool, minCPUFreq)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(updated).To(BeFalse())
+			})
+
+			By("corrects the reservations and limits edited in vCenter", func() {
+				editSpec := vcenter.ResourcePoolConfigSpec(&vmopv1.ResourcePoolSpec{}, minCPUFreq)
+				Expect(childRP.UpdateConfig(ctx, "", &editSpec)).To(Succeed())
+
+				_, updated, err := vcenter.CreateOrUpdateChildResourcePool(ctx, ctx.VCClient.Client, parentRPMoID, &resourcePolicy.Spec.ResourcePool, minCPUFreq)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(updated).To(BeTrue())
+
+				config := getConfig()
+				Expect(*config.CpuAllocation.Reservation).To(BeEquivalentTo(500))
+				Expect(*config.MemoryAllocation.Reservation).To(BeEquivalentTo(1024))
+			})
+		})
+
 		It("returns error when when parent ResourcePool MoID does not exist", func() {
-			childMoID, err := vcenter.CreateOrUpdateChildResourcePool(ctx, ctx.VCClient.Client, "bogus", &resourcePolicy.Spec.ResourcePool)
+			childMoID, _, err := vcenter.CreateOrUpdateChildResourcePool(ctx, ctx.VCClient.Client, "bogus", &resourcePolicy.Spec.ResourcePool, minCPUFreq)
 			Expect(err).To(HaveOccurred())
 			Expect(childMoID).To(BeEmpty())
 		})
@@ -155,7 +209,7 @@ func createDeleteExistResourcePoolChild() {
 		It("returns true when child ResourcePool exists", func() {
 			childName := resourcePolicy.Spec.ResourcePool.Name
 
-			_, err := vcenter.CreateOrUpdateChildResourcePool(ctx, ctx.VCClient.Client, parentRPMoID, &resourcePolicy.Spec.ResourcePool)
+			_, _, err := vcenter.CreateOrUpdateChildResourcePool(ctx, ctx.VCClient.Client, parentRPMoID, &resourcePolicy.Spec.ResourcePool, minCPUFreq)
 			Expect(err).ToNot(HaveOccurred())
 
 			exists, err := vcenter.DoesChildResourcePoolExist(ctx, ctx.VCClient.Client, parentRPMoID, childName)
@@ -180,7 +234,7 @@ func createDeleteExistResourcePoolChild() {
 		It("deletes child ResourcePool", func() {
 			childName := resourcePolicy.Spec.ResourcePool.Name
 
-			childMoID, err := vcenter.CreateOrUpdateChildResourcePool(ctx, ctx.VCClient.Client, parentRPMoID, &resourcePolicy.Spec.ResourcePool)
+			childMoID, _, err := vcenter.CreateOrUpdateChildResourcePool(ctx, ctx.VCClient.Client, parentRPMoID, &resourcePolicy.Spec.ResourcePool, minCPUFreq)
 			Expect(err).ToNot(HaveOccurred())
 
 			err = vcenter.DeleteChildResourcePool(ctx, ctx.VCClient.Client, parentRPMoID, childName)
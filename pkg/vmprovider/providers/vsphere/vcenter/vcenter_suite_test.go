@@ -1,4 +1,4 @@
-// Copyright (c) 2022 VMware, Inc. All Rights Reserved.
+// Copyright (c) 2022-2023 VMware, Inc. All Rights Reserved.
 // SPDX-License-Identifier: Apache-2.0
 
 package vcenter_test
@@ -15,6 +15,7 @@ var suite = builder.NewTestSuite()
 
 func vcSimTests() {
 	Describe("Cluster", clusterTests)
+	Describe("DRSRules", drsRulesTests)
 	Describe("Folder", folderTests)
 	Describe("GetVM", getVMTests)
 	Describe("Host", hostTests)
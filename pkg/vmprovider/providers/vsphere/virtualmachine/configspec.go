@@ -11,10 +11,34 @@ import (
 	vmopv1 "github.com/vmware-tanzu/vm-operator/api/v1alpha1"
 	"github.com/vmware-tanzu/vm-operator/pkg/context"
 	"github.com/vmware-tanzu/vm-operator/pkg/lib"
+	"github.com/vmware-tanzu/vm-operator/pkg/util"
 	"github.com/vmware-tanzu/vm-operator/pkg/vmprovider/providers/vsphere/constants"
 	"github.com/vmware-tanzu/vm-operator/pkg/vmprovider/providers/vsphere/instancestorage"
 )
 
+// EffectiveClassConfigSpec returns the ConfigSpec that VMs of the VM Class are
+// created with: the class's sanitized ConfigSpec with the CPU and memory of the
+// class's hardware. It returns nil if the class does not have a ConfigSpec.
+func EffectiveClassConfigSpec(
+	vmClassSpec *vmopv1.VirtualMachineClassSpec) (*vimtypes.VirtualMachineConfigSpec, error) {
+
+	if len(vmClassSpec.ConfigSpec) == 0 {
+		return nil, nil
+	}
+
+	configSpec, err := util.UnmarshalConfigSpecFromJSON(vmClassSpec.ConfigSpec)
+	if err != nil {
+		return nil, err
+	}
+	util.SanitizeVMClassConfigSpec(configSpec)
+
+	// As in CreateConfigSpec, the class's hardware takes precedence.
+	configSpec.NumCPUs = int32(vmClassSpec.Hardware.Cpus)
+	configSpec.MemoryMB = MemoryQuantityToMb(vmClassSpec.Hardware.Memory)
+
+	return configSpec, nil
+}
+
 // CreateConfigSpec returns a ConfigSpec that is created by overlaying the base
 // ConfigSpec with VM Class spec and other arguments.
 func CreateConfigSpec(
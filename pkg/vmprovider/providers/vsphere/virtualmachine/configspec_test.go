@@ -13,10 +13,64 @@ import (
 	vmopv1 "github.com/vmware-tanzu/vm-operator/api/v1alpha1"
 	"github.com/vmware-tanzu/vm-operator/pkg/context"
 	"github.com/vmware-tanzu/vm-operator/pkg/lib"
+	"github.com/vmware-tanzu/vm-operator/pkg/util"
 	"github.com/vmware-tanzu/vm-operator/pkg/vmprovider/providers/vsphere/virtualmachine"
 	"github.com/vmware-tanzu/vm-operator/test/builder"
 )
 
+var _ = Describe("EffectiveClassConfigSpec", func() {
+
+	var vmClassSpec *vmopv1.VirtualMachineClassSpec
+
+	BeforeEach(func() {
+		vmClass := builder.DummyVirtualMachineClass()
+		vmClassSpec = &vmClass.Spec
+	})
+
+	It("returns nil without a class ConfigSpec", func() {
+		configSpec, err := virtualmachine.EffectiveClassConfigSpec(vmClassSpec)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(configSpec).To(BeNil())
+	})
+
+	It("returns an error for an invalid class ConfigSpec", func() {
+		vmClassSpec.ConfigSpec = []byte(`{"numCPUs": "two"}`)
+		_, err := virtualmachine.EffectiveClassConfigSpec(vmClassSpec)
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("sanitizes the class ConfigSpec and uses the class hardware", func() {
+		raw, err := util.MarshalConfigSpecToJSON(&vimtypes.VirtualMachineConfigSpec{
+			Uuid:     "dont-use-this-uuid",
+			NumCPUs:  8,
+			MemoryMB: 1024,
+			Firmware: "efi",
+			DeviceChange: []vimtypes.BaseVirtualDeviceConfigSpec{
+				&vimtypes.VirtualDeviceConfigSpec{
+					Operation: vimtypes.VirtualDeviceConfigSpecOperationAdd,
+					Device:    &vimtypes.VirtualDisk{},
+				},
+				&vimtypes.VirtualDeviceConfigSpec{
+					Operation: vimtypes.VirtualDeviceConfigSpecOperationAdd,
+					Device:    &vimtypes.VirtualPCIPassthrough{},
+				},
+			},
+		})
+		Expect(err).ToNot(HaveOccurred())
+		vmClassSpec.ConfigSpec = raw
+
+		configSpec, err := virtualmachine.EffectiveClassConfigSpec(vmClassSpec)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(configSpec).ToNot(BeNil())
+		Expect(configSpec.Uuid).To(BeEmpty())
+		Expect(configSpec.Firmware).To(Equal("efi"))
+		Expect(configSpec.NumCPUs).To(BeEquivalentTo(vmClassSpec.Hardware.Cpus))
+		Expect(configSpec.MemoryMB).To(BeEquivalentTo(4 * 1024))
+		Expect(configSpec.DeviceChange).To(HaveLen(1))
+		Expect(configSpec.DeviceChange[0].GetVirtualDeviceConfigSpec().Device).To(BeAssignableToTypeOf(&vimtypes.VirtualPCIPassthrough{}))
+	})
+})
+
 var _ = Describe("CreateConfigSpec", func() {
 	const vmName = "dummy-vm"
 
@@ -1,4 +1,4 @@
-// Copyright (c) 2022 VMware, Inc. All Rights Reserved.
+// Copyright (c) 2022-2023 VMware, Inc. All Rights Reserved.
 // SPDX-License-Identifier: Apache-2.0
 
 package virtualmachine
@@ -9,6 +9,7 @@ import (
 	"github.com/vmware/govmomi/vim25/types"
 
 	"github.com/vmware-tanzu/vm-operator/pkg/context"
+	"github.com/vmware-tanzu/vm-operator/pkg/vmprovider"
 )
 
 func DeleteVirtualMachine(
@@ -33,7 +34,10 @@ func DeleteVirtualMachine(
 		return err
 	}
 
-	if taskInfo, err := t.WaitForResult(vmCtx); err != nil {
+	if taskInfo, err := WaitForTask(vmCtx, t); err != nil {
+		if errors.As(err, &vmprovider.TaskInProgressError{}) {
+			return err
+		}
 		if taskInfo != nil {
 			vmCtx.Logger.V(5).Error(err, "destroy VM task failed", "taskInfo", taskInfo)
 		}
@@ -0,0 +1,152 @@
+// Copyright (c) 2023 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package virtualmachine
+
+import (
+	goctx "context"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/vmware/govmomi/vim25/types"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	vmopv1 "github.com/vmware-tanzu/vm-operator/api/v1alpha1"
+	"github.com/vmware-tanzu/vm-operator/pkg/context"
+	"github.com/vmware-tanzu/vm-operator/pkg/vmprovider"
+)
+
+// DeployLibraryItemDescriptionID is the DescriptionID of the status.lastTask of the deploy of a content
+// library item. The deploy is not a vSphere task, so it is tracked by VM Operator and the TaskRef of the
+// status.lastTask is the ID of the library item.
+const DeployLibraryItemDescriptionID = "com.vmware.vcenter.ovf.library_item.deploy"
+
+// DeployFunc deploys a content library item, and returns the deployed VM.
+type DeployFunc func(ctx goctx.Context) (*types.ManagedObjectReference, error)
+
+type deploy struct {
+	itemID    string
+	startTime time.Time
+	done      chan struct{}
+
+	// Set once done is closed.
+	vmRef        *types.ManagedObjectReference
+	err          error
+	completeTime time.Time
+}
+
+// deploys are the deploys of the VMs, by VM, that are running or whose result was not checked yet.
+var (
+	deploysLock sync.Mutex
+	deploys     = map[string]*deploy{}
+)
+
+// DeployLibraryItem deploys the content library item into the VM of vmCtx with deployFn, and records the
+// deploy in the VM's status.lastTask. The deploy runs in the background, so it is not canceled when the
+// reconcile completes. When it does not complete within the TaskWaitTimeout, a TaskInProgressError is returned
+// and the deploy is checked by CheckLastTask in the following reconciles of the VM.
+func DeployLibraryItem(
+	vmCtx context.VirtualMachineContext,
+	itemID string,
+	deployFn DeployFunc) (*types.ManagedObjectReference, error) {
+
+	key := vmCtx.VM.NamespacedName()
+
+	deploysLock.Lock()
+	d, ok := deploys[key]
+	if !ok {
+		d = &deploy{
+			itemID:    itemID,
+			startTime: time.Now(),
+			done:      make(chan struct{}),
+		}
+		deploys[key] = d
+
+		// The deploy outlives the reconcile, so only the operation ID of the reconcile's context is kept.
+		ctx := goctx.Background()
+		if opID, ok := vmCtx.Value(types.ID{}).(string); ok {
+			ctx = goctx.WithValue(ctx, types.ID{}, opID)
+		}
+
+		go func() {
+			vmRef, err := deployFn(ctx)
+
+			deploysLock.Lock()
+			d.vmRef, d.err, d.completeTime = vmRef, err, time.Now()
+			deploysLock.Unlock()
+			close(d.done)
+		}()
+	}
+	deploysLock.Unlock()
+
+	select {
+	case <-d.done:
+	case <-time.After(TaskWaitTimeout):
+	case <-vmCtx.Done():
+	}
+
+	if err := checkDeploy(vmCtx, d); err != nil {
+		return nil, err
+	}
+	return d.vmRef, nil
+}
+
+// checkLastDeploy checks the deploy of the VM's status.lastTask. When VM Operator was restarted while the
+// deploy ran, the result of the deploy is unknown and the status.lastTask is cleared.
+func checkLastDeploy(vmCtx context.VirtualMachineContext) error {
+	deploysLock.Lock()
+	d, ok := deploys[vmCtx.VM.NamespacedName()]
+	deploysLock.Unlock()
+
+	if !ok {
+		vmCtx.Logger.Info("Last deploy no longer exists", "itemID", vmCtx.VM.Status.LastTask.TaskRef)
+		vmCtx.VM.Status.LastTask = nil
+		return nil
+	}
+
+	if err := checkDeploy(vmCtx, d); err != nil {
+		return err
+	}
+
+	if d.vmRef != nil {
+		// The deployed VM is found by its UniqueID.
+		vmCtx.VM.Status.UniqueID = d.vmRef.Value
+	}
+	return nil
+}
+
+// checkDeploy records the deploy in the VM's status.lastTask. The deploy is forgotten once it completed.
+// A TaskInProgressError is returned while the deploy runs, and an error when the deploy failed.
+func checkDeploy(vmCtx context.VirtualMachineContext, d *deploy) error {
+	lastTask := &vmopv1.VirtualMachineTaskInfo{
+		TaskRef:       d.itemID,
+		DescriptionID: DeployLibraryItemDescriptionID,
+		State:         vmopv1.VirtualMachineTaskStateRunning,
+		StartTime:     &metav1.Time{Time: d.startTime},
+	}
+	vmCtx.VM.Status.LastTask = lastTask
+
+	select {
+	case <-d.done:
+	default:
+		vmCtx.Logger.Info("Deploy is still running, it will be checked again", "itemID", d.itemID)
+		return vmprovider.TaskInProgressError{TaskRef: d.itemID, RequeueAfter: TaskInProgressRequeueDelay}
+	}
+
+	deploysLock.Lock()
+	if deploys[vmCtx.VM.NamespacedName()] == d {
+		delete(deploys, vmCtx.VM.NamespacedName())
+	}
+	deploysLock.Unlock()
+
+	lastTask.CompleteTime = &metav1.Time{Time: d.completeTime}
+	if d.err != nil {
+		lastTask.State = vmopv1.VirtualMachineTaskStateError
+		lastTask.Fault = &vmopv1.VirtualMachineTaskFault{Message: d.err.Error()}
+		return errors.Wrapf(d.err, "deploy of library item %s failed", d.itemID)
+	}
+
+	lastTask.State = vmopv1.VirtualMachineTaskStateSuccess
+	return nil
+}
@@ -9,6 +9,7 @@ import (
 	"github.com/vmware/govmomi/vim25/types"
 
 	"github.com/vmware-tanzu/vm-operator/pkg/context"
+	"github.com/vmware-tanzu/vm-operator/pkg/vmprovider"
 )
 
 func ChangePowerState(
@@ -32,13 +33,16 @@ func ChangePowerState(
 		return errors.Wrapf(err, "failed task creation to change power state to %s", ps)
 	}
 
-	if taskInfo, err := t.WaitForResult(vmCtx); err != nil {
+	if taskInfo, err := WaitForTask(vmCtx, t); err != nil {
 		if te, ok := err.(task.Error); ok {
 			// Ignore error if VM was already in desired state.
 			if ips, ok := te.Fault().(*types.InvalidPowerState); ok && ips.ExistingState == ips.RequestedState {
 				return nil
 			}
 		}
+		if errors.As(err, &vmprovider.TaskInProgressError{}) {
+			return err
+		}
 
 		if taskInfo != nil {
 			vmCtx.Logger.V(5).Error(err, "Change power state task failed", "taskInfo", taskInfo)
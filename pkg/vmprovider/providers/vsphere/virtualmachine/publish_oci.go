@@ -0,0 +1,179 @@
+// Copyright (c) 2023 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package virtualmachine
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/vmware/govmomi/nfc"
+	"github.com/vmware/govmomi/object"
+	"github.com/vmware/govmomi/ovf"
+	"github.com/vmware/govmomi/vim25/progress"
+	"github.com/vmware/govmomi/vim25/soap"
+	"github.com/vmware/govmomi/vim25/types"
+
+	vmopv1 "github.com/vmware-tanzu/vm-operator/api/v1alpha1"
+
+	"github.com/vmware-tanzu/vm-operator/pkg/context"
+	"github.com/vmware-tanzu/vm-operator/pkg/ociregistry"
+)
+
+const (
+	// OCIMediaTypeOVFDescriptor is the media type of the OVF descriptor layer.
+	OCIMediaTypeOVFDescriptor = "application/vnd.vmware.vm.ovf.v1+xml"
+
+	// OCIMediaTypeDisk is the media type of a disk layer.
+	OCIMediaTypeDisk = "application/vnd.vmware.vm.disk.vmdk.v1"
+
+	// OCIMediaTypeConfig is the media type of the artifact's config blob.
+	OCIMediaTypeConfig = "application/vnd.vmware.vmoperator.vm.config.v1+json"
+)
+
+// ociConfig is the config blob of an artifact published from a VM.
+type ociConfig struct {
+	Name              string `json:"name"`
+	Namespace         string `json:"namespace"`
+	ClassName         string `json:"className,omitempty"`
+	ImageName         string `json:"imageName,omitempty"`
+	PublishRequestUID string `json:"publishRequestUID"`
+}
+
+// ExportToOCIRegistry exports the VM as an OVF and pushes the descriptor and
+// disks to the registry as an OCI artifact tagged with the target item name.
+// The digest of the pushed manifest is returned.
+func ExportToOCIRegistry(vmCtx context.VirtualMachineContext, vcVM *object.VirtualMachine,
+	registry *ociregistry.Client, vmPubReq *vmopv1.VirtualMachinePublishRequest) (string, error) {
+
+	itemName := vmPubReq.Status.TargetRef.Item.Name
+	vmCtx.Logger.Info("Exporting VM to OCI registry", "reference", registry.Repository().Reference(itemName))
+
+	lease, err := vcVM.Export(vmCtx)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to export VM")
+	}
+
+	info, err := lease.Wait(vmCtx, nil)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to wait for export lease")
+	}
+
+	layers, ovfFiles, err := pushLeaseItems(vmCtx, vcVM.Client().Client, lease, info, registry)
+	if err != nil {
+		fault := &types.LocalizedMethodFault{LocalizedMessage: err.Error()}
+		if abortErr := lease.Abort(vmCtx, fault); abortErr != nil {
+			vmCtx.Logger.Error(abortErr, "failed to abort export lease")
+		}
+		return "", err
+	}
+
+	if err := lease.Complete(vmCtx); err != nil {
+		return "", errors.Wrap(err, "failed to complete export lease")
+	}
+
+	descriptor, err := ovf.NewManager(vcVM.Client()).CreateDescriptor(vmCtx, vcVM, types.OvfCreateDescriptorParams{
+		Name:        itemName,
+		Description: vmPubReq.Status.TargetRef.Item.Description,
+		OvfFiles:    ovfFiles,
+	})
+	if err != nil {
+		return "", errors.Wrap(err, "failed to create OVF descriptor")
+	}
+	if len(descriptor.Error) > 0 {
+		return "", fmt.Errorf("failed to create OVF descriptor: %s", descriptor.Error[0].LocalizedMessage)
+	}
+
+	ovfLayer, err := registry.PushBlob(vmCtx, OCIMediaTypeOVFDescriptor, strings.NewReader(descriptor.OvfDescriptor))
+	if err != nil {
+		return "", errors.Wrap(err, "failed to push OVF descriptor")
+	}
+	ovfLayer.Annotations = map[string]string{ociregistry.AnnotationTitle: itemName + ".ovf"}
+
+	vm := vmCtx.VM
+	config, err := json.Marshal(ociConfig{
+		Name:              vm.Name,
+		Namespace:         vm.Namespace,
+		ClassName:         vm.Spec.ClassName,
+		ImageName:         vm.Spec.ImageName,
+		PublishRequestUID: string(vmPubReq.UID),
+	})
+	if err != nil {
+		return "", err
+	}
+	configDesc, err := registry.PushBlob(vmCtx, OCIMediaTypeConfig, bytes.NewReader(config))
+	if err != nil {
+		return "", errors.Wrap(err, "failed to push config")
+	}
+
+	manifest := ociregistry.Manifest{
+		SchemaVersion: 2,
+		MediaType:     ociregistry.MediaTypeImageManifest,
+		Config:        configDesc,
+		Layers:        append([]ociregistry.Descriptor{ovfLayer}, layers...),
+		Annotations: map[string]string{
+			ociregistry.AnnotationCreated:              time.Now().UTC().Format(time.RFC3339),
+			ociregistry.AnnotationTitle:                itemName,
+			ociregistry.AnnotationDescription:          vmPubReq.Status.TargetRef.Item.Description,
+			ociregistry.AnnotationPublishRequestUID:    string(vmPubReq.UID),
+			ociregistry.AnnotationSourceVirtualMachine: vm.NamespacedName(),
+		},
+	}
+
+	manifestDesc, err := registry.PushManifest(vmCtx, itemName, manifest)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to push manifest")
+	}
+
+	vmCtx.Logger.Info("Exported VM to OCI registry",
+		"reference", registry.Repository().Reference(itemName), "digest", manifestDesc.Digest)
+	return manifestDesc.Digest, nil
+}
+
+// pushLeaseItems streams each file of the export lease to the registry.
+func pushLeaseItems(vmCtx context.VirtualMachineContext, client *soap.Client, lease *nfc.Lease, info *nfc.LeaseInfo,
+	registry *ociregistry.Client) ([]ociregistry.Descriptor, []types.OvfFile, error) {
+
+	updater := lease.StartUpdater(vmCtx, info)
+	defer updater.Done()
+
+	layers := make([]ociregistry.Descriptor, 0, len(info.Items))
+	ovfFiles := make([]types.OvfFile, 0, len(info.Items))
+
+	for _, item := range info.Items {
+		desc, err := pushLeaseItem(vmCtx, client, item, registry)
+		if err != nil {
+			return nil, nil, errors.Wrapf(err, "failed to push %s", item.Path)
+		}
+		desc.Annotations = map[string]string{ociregistry.AnnotationTitle: item.Path}
+		layers = append(layers, desc)
+
+		file := item.File()
+		file.Size = desc.Size
+		ovfFiles = append(ovfFiles, file)
+	}
+
+	return layers, ovfFiles, nil
+}
+
+func pushLeaseItem(vmCtx context.VirtualMachineContext, client *soap.Client, item nfc.FileItem,
+	registry *ociregistry.Client) (desc ociregistry.Descriptor, err error) {
+
+	body, size, err := client.Download(vmCtx, item.URL, &soap.DefaultDownload)
+	if err != nil {
+		return desc, err
+	}
+	defer body.Close()
+
+	reader := progress.NewReader(vmCtx, item, body, size)
+	defer func() {
+		reader.Done(err)
+	}()
+
+	return registry.PushBlob(vmCtx, OCIMediaTypeDisk, reader)
+}
@@ -0,0 +1,194 @@
+// Copyright (c) 2023 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package virtualmachine
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/vmware/govmomi/object"
+	"github.com/vmware/govmomi/vim25/mo"
+	"github.com/vmware/govmomi/vim25/types"
+
+	"github.com/vmware-tanzu/vm-operator/pkg/context"
+)
+
+const (
+	// SerialConsoleServiceURIPrefix is the prefix of the service URI of the serial ports added for serial
+	// consoles. The service URI identifies the VM's stream to the virtual serial port concentrator, and
+	// ends with a random suffix so the stream of a VM cannot be claimed by guessing its service URI.
+	SerialConsoleServiceURIPrefix = "vm-operator-serial-console:"
+
+	serialConsoleServiceURIRandomLength = 16
+)
+
+// EnsureSerialConsole returns the service URI of the VM's serial port that connects to the virtual serial
+// port concentrator (vSPC) at proxyURI. The ESXi host of the VM connects to the vSPC, so the serial port
+// does not listen on a network port of the host. When the VM does not have such a serial port, one is added
+// to the powered off VM. A serial port that was disconnected by RemoveSerialConsole is connected again.
+func EnsureSerialConsole(
+	vmCtx context.VirtualMachineContext,
+	vm *object.VirtualMachine,
+	proxyURI string) (string, error) {
+
+	vmCtx.Logger.V(5).Info("EnsureSerialConsole")
+
+	if proxyURI == "" {
+		return "", errors.New("the serial console vSPC URI is not configured")
+	}
+
+	var moVM mo.VirtualMachine
+	if err := vm.Properties(vmCtx, vm.Reference(), []string{"config.hardware.device", "runtime"}, &moVM); err != nil {
+		return "", err
+	}
+
+	if moVM.Config == nil {
+		return "", fmt.Errorf("VM config is not available, connectionState=%s", moVM.Runtime.ConnectionState)
+	}
+
+	devices := object.VirtualDeviceList(moVM.Config.Hardware.Device)
+	poweredOff := moVM.Runtime.PowerState == types.VirtualMachinePowerStatePoweredOff
+
+	if serialPort := findSerialConsolePort(devices, proxyURI); serialPort != nil {
+		serviceURI := serialPort.Backing.(*types.VirtualSerialPortURIBackingInfo).ServiceURI
+
+		connectable := serialPort.Connectable
+		if connectable != nil && connectable.StartConnected && (poweredOff || connectable.Connected) {
+			return serviceURI, nil
+		}
+
+		serialPort.Connectable = &types.VirtualDeviceConnectInfo{
+			StartConnected:    true,
+			AllowGuestControl: true,
+			Connected:         !poweredOff,
+		}
+
+		vmCtx.Logger.Info("Connecting serial port of VM", "serviceURI", serviceURI)
+		if err := reconfigureSerialPort(vmCtx, vm, serialPort, types.VirtualDeviceConfigSpecOperationEdit); err != nil {
+			return "", err
+		}
+		return serviceURI, nil
+	}
+
+	if !poweredOff {
+		return "", errors.New("VM does not have a vSPC backed serial port, and one can only be added when the VM is powered off")
+	}
+
+	suffix := make([]byte, serialConsoleServiceURIRandomLength)
+	if _, err := rand.Read(suffix); err != nil {
+		return "", err
+	}
+
+	backing := &types.VirtualSerialPortURIBackingInfo{
+		VirtualDeviceURIBackingInfo: types.VirtualDeviceURIBackingInfo{
+			Direction:  string(types.VirtualDeviceURIBackingOptionDirectionServer),
+			ServiceURI: SerialConsoleServiceURIPrefix + hex.EncodeToString(suffix),
+			ProxyURI:   proxyURI,
+		},
+	}
+
+	serialPort := &types.VirtualSerialPort{
+		VirtualDevice: types.VirtualDevice{
+			Key:     devices.NewKey(),
+			Backing: backing,
+			Connectable: &types.VirtualDeviceConnectInfo{
+				StartConnected:    true,
+				AllowGuestControl: true,
+			},
+		},
+		YieldOnPoll: true,
+	}
+
+	vmCtx.Logger.Info("Adding serial port to VM", "serviceURI", backing.ServiceURI, "proxyURI", proxyURI)
+	if err := reconfigureSerialPort(vmCtx, vm, serialPort, types.VirtualDeviceConfigSpecOperationAdd); err != nil {
+		return "", err
+	}
+
+	return backing.ServiceURI, nil
+}
+
+// RemoveSerialConsole removes the VM's serial port that connects to the virtual serial port concentrator at
+// proxyURI. Since vSphere only allows removing a serial port from a powered off VM, the serial port of a VM
+// that is not powered off is disconnected instead, so the ESXi host closes the stream to the vSPC.
+func RemoveSerialConsole(
+	vmCtx context.VirtualMachineContext,
+	vm *object.VirtualMachine,
+	proxyURI string) error {
+
+	vmCtx.Logger.V(5).Info("RemoveSerialConsole")
+
+	if proxyURI == "" {
+		return nil
+	}
+
+	var moVM mo.VirtualMachine
+	if err := vm.Properties(vmCtx, vm.Reference(), []string{"config.hardware.device", "runtime"}, &moVM); err != nil {
+		return err
+	}
+
+	if moVM.Config == nil {
+		return fmt.Errorf("VM config is not available, connectionState=%s", moVM.Runtime.ConnectionState)
+	}
+
+	serialPort := findSerialConsolePort(moVM.Config.Hardware.Device, proxyURI)
+	if serialPort == nil {
+		return nil
+	}
+
+	serviceURI := serialPort.Backing.(*types.VirtualSerialPortURIBackingInfo).ServiceURI
+
+	if moVM.Runtime.PowerState == types.VirtualMachinePowerStatePoweredOff {
+		vmCtx.Logger.Info("Removing serial port from VM", "serviceURI", serviceURI)
+		return reconfigureSerialPort(vmCtx, vm, serialPort, types.VirtualDeviceConfigSpecOperationRemove)
+	}
+
+	if connectable := serialPort.Connectable; connectable != nil && !connectable.StartConnected && !connectable.Connected {
+		return nil
+	}
+
+	serialPort.Connectable = &types.VirtualDeviceConnectInfo{
+		AllowGuestControl: true,
+	}
+
+	vmCtx.Logger.Info("Disconnecting serial port of VM", "serviceURI", serviceURI)
+	return reconfigureSerialPort(vmCtx, vm, serialPort, types.VirtualDeviceConfigSpecOperationEdit)
+}
+
+// findSerialConsolePort returns the serial port added for serial consoles that connects to the virtual serial
+// port concentrator at proxyURI, or nil if the VM does not have one.
+func findSerialConsolePort(devices object.VirtualDeviceList, proxyURI string) *types.VirtualSerialPort {
+	for _, dev := range devices.SelectByType((*types.VirtualSerialPort)(nil)) {
+		serialPort := dev.(*types.VirtualSerialPort)
+		backing, ok := serialPort.Backing.(*types.VirtualSerialPortURIBackingInfo)
+		if ok && backing.ProxyURI == proxyURI && strings.HasPrefix(backing.ServiceURI, SerialConsoleServiceURIPrefix) {
+			return serialPort
+		}
+	}
+	return nil
+}
+
+func reconfigureSerialPort(
+	vmCtx context.VirtualMachineContext,
+	vm *object.VirtualMachine,
+	serialPort *types.VirtualSerialPort,
+	operation types.VirtualDeviceConfigSpecOperation) error {
+
+	configSpec := types.VirtualMachineConfigSpec{
+		DeviceChange: []types.BaseVirtualDeviceConfigSpec{
+			&types.VirtualDeviceConfigSpec{
+				Operation: operation,
+				Device:    serialPort,
+			},
+		},
+	}
+
+	task, err := vm.Reconfigure(vmCtx, configSpec)
+	if err != nil {
+		return err
+	}
+	return task.Wait(vmCtx)
+}
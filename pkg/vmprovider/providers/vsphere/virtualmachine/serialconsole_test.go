@@ -0,0 +1,182 @@
+// Copyright (c) 2023 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package virtualmachine_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/vmware/govmomi/object"
+	"github.com/vmware/govmomi/vim25/mo"
+	"github.com/vmware/govmomi/vim25/types"
+
+	"github.com/vmware-tanzu/vm-operator/pkg/context"
+	"github.com/vmware-tanzu/vm-operator/pkg/vmprovider/providers/vsphere/virtualmachine"
+	"github.com/vmware-tanzu/vm-operator/test/builder"
+)
+
+func serialConsoleTests() {
+
+	var (
+		ctx   *builder.TestContextForVCSim
+		vcVM  *object.VirtualMachine
+		vmCtx context.VirtualMachineContext
+	)
+
+	const proxyURI = "telnet://vspc.example.com:13370"
+
+	BeforeEach(func() {
+		ctx = suite.NewTestContextForVCSim(builder.VCSimTestConfig{})
+
+		var err error
+		vcVM, err = ctx.Finder.VirtualMachine(ctx, "DC0_C0_RP0_VM0")
+		Expect(err).ToNot(HaveOccurred())
+
+		vmCtx = context.VirtualMachineContext{
+			Context: ctx,
+			Logger:  suite.GetLogger().WithValues("vmName", vcVM.Name()),
+			VM:      builder.DummyVirtualMachine(),
+		}
+	})
+
+	AfterEach(func() {
+		ctx.AfterEach()
+		ctx = nil
+	})
+
+	getSerialPorts := func() object.VirtualDeviceList {
+		var o mo.VirtualMachine
+		ExpectWithOffset(1, vcVM.Properties(ctx, vcVM.Reference(), []string{"config.hardware.device"}, &o)).To(Succeed())
+		return object.VirtualDeviceList(o.Config.Hardware.Device).SelectByType((*types.VirtualSerialPort)(nil))
+	}
+
+	powerOff := func(vm *object.VirtualMachine) {
+		t, err := vm.PowerOff(ctx)
+		ExpectWithOffset(1, err).ToNot(HaveOccurred())
+		ExpectWithOffset(1, t.Wait(ctx)).To(Succeed())
+	}
+
+	powerOn := func(vm *object.VirtualMachine) {
+		t, err := vm.PowerOn(ctx)
+		ExpectWithOffset(1, err).ToNot(HaveOccurred())
+		ExpectWithOffset(1, t.Wait(ctx)).To(Succeed())
+	}
+
+	When("the vSPC URI is not configured", func() {
+		It("returns an error", func() {
+			_, err := virtualmachine.EnsureSerialConsole(vmCtx, vcVM, "")
+			Expect(err).To(MatchError(ContainSubstring("vSPC URI is not configured")))
+		})
+	})
+
+	When("the VM is powered on and does not have a serial port", func() {
+		It("returns an error", func() {
+			_, err := virtualmachine.EnsureSerialConsole(vmCtx, vcVM, proxyURI)
+			Expect(err).To(MatchError(ContainSubstring("can only be added when the VM is powered off")))
+			Expect(getSerialPorts()).To(BeEmpty())
+		})
+	})
+
+	When("the VM is powered off", func() {
+		BeforeEach(func() {
+			powerOff(vcVM)
+		})
+
+		It("adds a serial port that connects to the vSPC", func() {
+			serviceURI, err := virtualmachine.EnsureSerialConsole(vmCtx, vcVM, proxyURI)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(serviceURI).To(HavePrefix(virtualmachine.SerialConsoleServiceURIPrefix))
+			Expect(len(serviceURI)).To(BeNumerically(">", len(virtualmachine.SerialConsoleServiceURIPrefix)))
+
+			serialPorts := getSerialPorts()
+			Expect(serialPorts).To(HaveLen(1))
+			backing, ok := serialPorts[0].GetVirtualDevice().Backing.(*types.VirtualSerialPortURIBackingInfo)
+			Expect(ok).To(BeTrue())
+			Expect(backing.ServiceURI).To(Equal(serviceURI))
+			Expect(backing.ProxyURI).To(Equal(proxyURI))
+
+			By("returns the service URI of the existing serial port", func() {
+				serviceURI2, err := virtualmachine.EnsureSerialConsole(vmCtx, vcVM, proxyURI)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(serviceURI2).To(Equal(serviceURI))
+				Expect(getSerialPorts()).To(HaveLen(1))
+			})
+
+			By("removes the serial port", func() {
+				Expect(virtualmachine.RemoveSerialConsole(vmCtx, vcVM, proxyURI)).To(Succeed())
+				Expect(getSerialPorts()).To(BeEmpty())
+			})
+		})
+
+		When("the VM has a serial port that connects to another vSPC", func() {
+			BeforeEach(func() {
+				configSpec := types.VirtualMachineConfigSpec{
+					DeviceChange: []types.BaseVirtualDeviceConfigSpec{
+						&types.VirtualDeviceConfigSpec{
+							Operation: types.VirtualDeviceConfigSpecOperationAdd,
+							Device: &types.VirtualSerialPort{
+								VirtualDevice: types.VirtualDevice{
+									Key: -1,
+									Backing: &types.VirtualSerialPortURIBackingInfo{
+										VirtualDeviceURIBackingInfo: types.VirtualDeviceURIBackingInfo{
+											Direction:  string(types.VirtualDeviceURIBackingOptionDirectionServer),
+											ServiceURI: "vm-0",
+											ProxyURI:   "telnet://other-vspc.example.com:13370",
+										},
+									},
+								},
+							},
+						},
+					},
+				}
+				t, err := vcVM.Reconfigure(ctx, configSpec)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(t.Wait(ctx)).To(Succeed())
+			})
+
+			It("adds a serial port and only removes that serial port", func() {
+				_, err := virtualmachine.EnsureSerialConsole(vmCtx, vcVM, proxyURI)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(getSerialPorts()).To(HaveLen(2))
+
+				Expect(virtualmachine.RemoveSerialConsole(vmCtx, vcVM, proxyURI)).To(Succeed())
+				serialPorts := getSerialPorts()
+				Expect(serialPorts).To(HaveLen(1))
+				backing := serialPorts[0].GetVirtualDevice().Backing.(*types.VirtualSerialPortURIBackingInfo)
+				Expect(backing.ServiceURI).To(Equal("vm-0"))
+			})
+		})
+	})
+
+	When("the VM with a serial port is powered on", func() {
+		var (
+			serviceURI string
+		)
+
+		BeforeEach(func() {
+			powerOff(vcVM)
+			var err error
+			serviceURI, err = virtualmachine.EnsureSerialConsole(vmCtx, vcVM, proxyURI)
+			Expect(err).ToNot(HaveOccurred())
+			powerOn(vcVM)
+		})
+
+		It("disconnects the serial port instead of removing it, and connects it again", func() {
+			Expect(virtualmachine.RemoveSerialConsole(vmCtx, vcVM, proxyURI)).To(Succeed())
+			serialPorts := getSerialPorts()
+			Expect(serialPorts).To(HaveLen(1))
+			connectable := serialPorts[0].GetVirtualDevice().Connectable
+			Expect(connectable.StartConnected).To(BeFalse())
+			Expect(connectable.Connected).To(BeFalse())
+
+			serviceURI2, err := virtualmachine.EnsureSerialConsole(vmCtx, vcVM, proxyURI)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(serviceURI2).To(Equal(serviceURI))
+			serialPorts = getSerialPorts()
+			Expect(serialPorts).To(HaveLen(1))
+			connectable = serialPorts[0].GetVirtualDevice().Connectable
+			Expect(connectable.StartConnected).To(BeTrue())
+			Expect(connectable.Connected).To(BeTrue())
+		})
+	})
+}
@@ -0,0 +1,162 @@
+// Copyright (c) 2023 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package virtualmachine
+
+import (
+	goctx "context"
+	"reflect"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/vmware/govmomi/object"
+	"github.com/vmware/govmomi/vim25"
+	"github.com/vmware/govmomi/vim25/mo"
+	"github.com/vmware/govmomi/vim25/soap"
+	"github.com/vmware/govmomi/vim25/types"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	vmopv1 "github.com/vmware-tanzu/vm-operator/api/v1alpha1"
+	"github.com/vmware-tanzu/vm-operator/pkg/context"
+	"github.com/vmware-tanzu/vm-operator/pkg/vmprovider"
+)
+
+// TaskInProgressRequeueDelay is how long a VM whose task is still running waits before the task is checked again.
+const TaskInProgressRequeueDelay = 10 * time.Second
+
+// TaskWaitTimeout is how long a task is waited for before it is left to complete in the background. The task
+// is then checked by the following reconciles of the VM, so the reconcile is not blocked on long tasks.
+var TaskWaitTimeout = 10 * time.Second
+
+// WaitForTask waits for the task that was started on the VM to complete, and records the task in the VM's
+// status.lastTask. When the task does not complete within the TaskWaitTimeout, a TaskInProgressError is
+// returned and the task is checked by CheckLastTask in the following reconciles of the VM.
+//
+// Like object.Task.WaitForResult, the TaskInfo is returned with a task.Error when the task failed.
+func WaitForTask(vmCtx context.VirtualMachineContext, t *object.Task) (*types.TaskInfo, error) {
+	ctx, cancel := goctx.WithTimeout(vmCtx, TaskWaitTimeout)
+	defer cancel()
+
+	taskInfo, err := t.WaitForResult(ctx)
+	if err == nil || taskInfo != nil {
+		setLastTask(vmCtx.VM, taskInfo)
+		return taskInfo, err
+	}
+
+	if ctx.Err() == nil || vmCtx.Err() != nil {
+		return nil, err
+	}
+
+	// The task is still running.
+	var moTask mo.Task
+	if err := t.Properties(vmCtx, t.Reference(), []string{"info"}, &moTask); err != nil {
+		return nil, errors.Wrapf(err, "failed to get info of task %s", t.Reference().Value)
+	}
+	setLastTask(vmCtx.VM, &moTask.Info)
+
+	if !isTaskDone(moTask.Info.State) {
+		vmCtx.Logger.Info("Task is still running, it will be checked again",
+			"taskRef", moTask.Info.Task.Value, "descriptionID", moTask.Info.DescriptionId)
+		return nil, vmprovider.TaskInProgressError{TaskRef: moTask.Info.Task.Value, RequeueAfter: TaskInProgressRequeueDelay}
+	}
+
+	// The task completed while its info was being retrieved.
+	return t.WaitForResult(vmCtx)
+}
+
+// CheckLastTask updates the VM's status.lastTask when the task is still running. A TaskInProgressError is
+// returned while the task runs, so the VM is not changed until the task completes, and an error is returned
+// when the task failed since it was last checked.
+func CheckLastTask(vmCtx context.VirtualMachineContext, vimClient *vim25.Client) error {
+	lastTask := vmCtx.VM.Status.LastTask
+	if lastTask == nil || isLastTaskDone(lastTask.State) {
+		return nil
+	}
+
+	if lastTask.DescriptionID == DeployLibraryItemDescriptionID {
+		return checkLastDeploy(vmCtx)
+	}
+
+	t := object.NewTask(vimClient, types.ManagedObjectReference{Type: "Task", Value: lastTask.TaskRef})
+
+	var moTask mo.Task
+	if err := t.Properties(vmCtx, t.Reference(), []string{"info"}, &moTask); err != nil {
+		if soap.IsSoapFault(err) {
+			if _, ok := soap.ToSoapFault(err).VimFault().(types.ManagedObjectNotFound); ok {
+				// vSphere only keeps the recently completed tasks, so the result of the task is unknown.
+				vmCtx.Logger.Info("Last task no longer exists", "taskRef", lastTask.TaskRef)
+				vmCtx.VM.Status.LastTask = nil
+				return nil
+			}
+		}
+		return errors.Wrapf(err, "failed to get info of task %s", lastTask.TaskRef)
+	}
+	setLastTask(vmCtx.VM, &moTask.Info)
+
+	switch moTask.Info.State {
+	case types.TaskInfoStateSuccess:
+		vmCtx.Logger.Info("Last task completed", "taskRef", lastTask.TaskRef, "descriptionID", lastTask.DescriptionID)
+		return nil
+	case types.TaskInfoStateError:
+		msg := ""
+		if moTask.Info.Error != nil {
+			msg = moTask.Info.Error.LocalizedMessage
+		}
+		return errors.Errorf("task %s %s failed: %s", lastTask.TaskRef, lastTask.DescriptionID, msg)
+	default:
+		return vmprovider.TaskInProgressError{TaskRef: lastTask.TaskRef, RequeueAfter: TaskInProgressRequeueDelay}
+	}
+}
+
+func setLastTask(vm *vmopv1.VirtualMachine, taskInfo *types.TaskInfo) {
+	lastTask := &vmopv1.VirtualMachineTaskInfo{
+		TaskRef:       taskInfo.Task.Value,
+		DescriptionID: taskInfo.DescriptionId,
+		State:         taskState(taskInfo.State),
+		Progress:      taskInfo.Progress,
+	}
+
+	if taskInfo.Description != nil {
+		lastTask.Description = taskInfo.Description.Message
+	}
+	if taskInfo.StartTime != nil {
+		startTime := metav1.NewTime(*taskInfo.StartTime)
+		lastTask.StartTime = &startTime
+	}
+	if taskInfo.CompleteTime != nil {
+		completeTime := metav1.NewTime(*taskInfo.CompleteTime)
+		lastTask.CompleteTime = &completeTime
+	}
+
+	if taskErr := taskInfo.Error; taskErr != nil {
+		lastTask.Fault = &vmopv1.VirtualMachineTaskFault{
+			Message: taskErr.LocalizedMessage,
+		}
+		if fault := taskErr.Fault; fault != nil {
+			lastTask.Fault.Type = reflect.Indirect(reflect.ValueOf(fault)).Type().Name()
+		}
+	}
+
+	vm.Status.LastTask = lastTask
+}
+
+func taskState(state types.TaskInfoState) vmopv1.VirtualMachineTaskState {
+	switch state {
+	case types.TaskInfoStateQueued:
+		return vmopv1.VirtualMachineTaskStateQueued
+	case types.TaskInfoStateSuccess:
+		return vmopv1.VirtualMachineTaskStateSuccess
+	case types.TaskInfoStateError:
+		return vmopv1.VirtualMachineTaskStateError
+	default:
+		return vmopv1.VirtualMachineTaskStateRunning
+	}
+}
+
+func isTaskDone(state types.TaskInfoState) bool {
+	return state == types.TaskInfoStateSuccess || state == types.TaskInfoStateError
+}
+
+func isLastTaskDone(state vmopv1.VirtualMachineTaskState) bool {
+	return state == vmopv1.VirtualMachineTaskStateSuccess || state == vmopv1.VirtualMachineTaskStateError
+}
@@ -0,0 +1,209 @@
+// Copyright (c) 2023 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package virtualmachine_test
+
+import (
+	goctx "context"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/pkg/errors"
+	"github.com/vmware/govmomi/object"
+	"github.com/vmware/govmomi/simulator"
+	"github.com/vmware/govmomi/vim25/types"
+
+	vmopv1 "github.com/vmware-tanzu/vm-operator/api/v1alpha1"
+	"github.com/vmware-tanzu/vm-operator/pkg/context"
+	"github.com/vmware-tanzu/vm-operator/pkg/vmprovider"
+	"github.com/vmware-tanzu/vm-operator/pkg/vmprovider/providers/vsphere/virtualmachine"
+	"github.com/vmware-tanzu/vm-operator/test/builder"
+)
+
+func taskTests() {
+
+	var (
+		ctx   *builder.TestContextForVCSim
+		vcVM  *object.VirtualMachine
+		vmCtx context.VirtualMachineContext
+	)
+
+	BeforeEach(func() {
+		ctx = suite.NewTestContextForVCSim(builder.VCSimTestConfig{})
+
+		var err error
+		vcVM, err = ctx.Finder.VirtualMachine(ctx, "DC0_C0_RP0_VM0")
+		Expect(err).ToNot(HaveOccurred())
+
+		vmCtx = context.VirtualMachineContext{
+			Context: ctx,
+			Logger:  suite.GetLogger().WithValues("vmName", vcVM.Name()),
+			VM:      builder.DummyVirtualMachine(),
+		}
+	})
+
+	AfterEach(func() {
+		ctx.AfterEach()
+		ctx = nil
+	})
+
+	It("records a completed task", func() {
+		Expect(virtualmachine.ChangePowerState(vmCtx, vcVM, types.VirtualMachinePowerStatePoweredOff)).To(Succeed())
+
+		lastTask := vmCtx.VM.Status.LastTask
+		Expect(lastTask).ToNot(BeNil())
+		Expect(lastTask.TaskRef).ToNot(BeEmpty())
+		Expect(lastTask.DescriptionID).To(Equal("VirtualMachine.powerOff"))
+		Expect(lastTask.State).To(Equal(vmopv1.VirtualMachineTaskStateSuccess))
+		Expect(lastTask.StartTime).ToNot(BeNil())
+		Expect(lastTask.CompleteTime).ToNot(BeNil())
+		Expect(lastTask.Fault).To(BeNil())
+
+		Expect(virtualmachine.CheckLastTask(vmCtx, ctx.VCClient.Client)).To(Succeed())
+	})
+
+	It("records the fault of a failed task", func() {
+		Expect(virtualmachine.ChangePowerState(vmCtx, vcVM, types.VirtualMachinePowerStatePoweredOn)).To(Succeed())
+
+		lastTask := vmCtx.VM.Status.LastTask
+		Expect(lastTask).ToNot(BeNil())
+		Expect(lastTask.State).To(Equal(vmopv1.VirtualMachineTaskStateError))
+		Expect(lastTask.Fault).ToNot(BeNil())
+		Expect(lastTask.Fault.Type).To(Equal("InvalidPowerState"))
+	})
+
+	Context("when the task does not complete within the wait timeout", func() {
+		var waitTimeout time.Duration
+
+		BeforeEach(func() {
+			waitTimeout = virtualmachine.TaskWaitTimeout
+			virtualmachine.TaskWaitTimeout = 100 * time.Millisecond
+			simulator.TaskDelay.Delay = 1000
+		})
+
+		AfterEach(func() {
+			virtualmachine.TaskWaitTimeout = waitTimeout
+			simulator.TaskDelay.Delay = 0
+		})
+
+		It("checks the task in the following reconciles", func() {
+			err := virtualmachine.ChangePowerState(vmCtx, vcVM, types.VirtualMachinePowerStatePoweredOff)
+			Expect(errors.As(err, &vmprovider.TaskInProgressError{})).To(BeTrue())
+
+			lastTask := vmCtx.VM.Status.LastTask
+			Expect(lastTask).ToNot(BeNil())
+			Expect(err.Error()).To(ContainSubstring(lastTask.TaskRef))
+			Expect(lastTask.State).To(Equal(vmopv1.VirtualMachineTaskStateRunning))
+			Expect(lastTask.CompleteTime).To(BeNil())
+
+			err = virtualmachine.CheckLastTask(vmCtx, ctx.VCClient.Client)
+			Expect(errors.As(err, &vmprovider.TaskInProgressError{})).To(BeTrue())
+
+			Eventually(func() error {
+				return virtualmachine.CheckLastTask(vmCtx, ctx.VCClient.Client)
+			}, 5*time.Second, 100*time.Millisecond).Should(Succeed())
+			Expect(vmCtx.VM.Status.LastTask.State).To(Equal(vmopv1.VirtualMachineTaskStateSuccess))
+			Expect(vmCtx.VM.Status.LastTask.CompleteTime).ToNot(BeNil())
+
+			state, err := vcVM.PowerState(ctx)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(state).To(Equal(types.VirtualMachinePowerStatePoweredOff))
+		})
+
+		It("returns an error when the task failed", func() {
+			_, err := virtualmachine.WaitForTask(vmCtx, powerOn(vmCtx, vcVM))
+			Expect(errors.As(err, &vmprovider.TaskInProgressError{})).To(BeTrue())
+
+			Eventually(func() error {
+				return virtualmachine.CheckLastTask(vmCtx, ctx.VCClient.Client)
+			}, 5*time.Second, 100*time.Millisecond).Should(MatchError(ContainSubstring("failed")))
+			Expect(vmCtx.VM.Status.LastTask.State).To(Equal(vmopv1.VirtualMachineTaskStateError))
+			Expect(vmCtx.VM.Status.LastTask.Fault.Type).To(Equal("InvalidPowerState"))
+
+			// The failure is only returned once.
+			Expect(virtualmachine.CheckLastTask(vmCtx, ctx.VCClient.Client)).To(Succeed())
+		})
+	})
+
+	It("forgets a task that no longer exists", func() {
+		vmCtx.VM.Status.LastTask = &vmopv1.VirtualMachineTaskInfo{
+			TaskRef: "task-does-not-exist",
+			State:   vmopv1.VirtualMachineTaskStateRunning,
+		}
+
+		Expect(virtualmachine.CheckLastTask(vmCtx, ctx.VCClient.Client)).To(Succeed())
+		Expect(vmCtx.VM.Status.LastTask).To(BeNil())
+	})
+
+	Context("DeployLibraryItem", func() {
+		const itemID = "item-1"
+
+		var waitTimeout time.Duration
+
+		BeforeEach(func() {
+			waitTimeout = virtualmachine.TaskWaitTimeout
+			virtualmachine.TaskWaitTimeout = 100 * time.Millisecond
+		})
+
+		AfterEach(func() {
+			virtualmachine.TaskWaitTimeout = waitTimeout
+		})
+
+		It("checks the deploy in the following reconciles", func() {
+			deployed := make(chan struct{})
+			vmRef := vcVM.Reference()
+
+			_, err := virtualmachine.DeployLibraryItem(vmCtx, itemID,
+				func(goctx.Context) (*types.ManagedObjectReference, error) {
+					<-deployed
+					return &vmRef, nil
+				})
+			Expect(errors.As(err, &vmprovider.TaskInProgressError{})).To(BeTrue())
+
+			lastTask := vmCtx.VM.Status.LastTask
+			Expect(lastTask).ToNot(BeNil())
+			Expect(lastTask.TaskRef).To(Equal(itemID))
+			Expect(lastTask.DescriptionID).To(Equal(virtualmachine.DeployLibraryItemDescriptionID))
+			Expect(lastTask.State).To(Equal(vmopv1.VirtualMachineTaskStateRunning))
+
+			err = virtualmachine.CheckLastTask(vmCtx, ctx.VCClient.Client)
+			Expect(errors.As(err, &vmprovider.TaskInProgressError{})).To(BeTrue())
+
+			close(deployed)
+			Eventually(func() error {
+				return virtualmachine.CheckLastTask(vmCtx, ctx.VCClient.Client)
+			}, 5*time.Second, 100*time.Millisecond).Should(Succeed())
+			Expect(vmCtx.VM.Status.LastTask.State).To(Equal(vmopv1.VirtualMachineTaskStateSuccess))
+			Expect(vmCtx.VM.Status.LastTask.CompleteTime).ToNot(BeNil())
+			Expect(vmCtx.VM.Status.UniqueID).To(Equal(vmRef.Value))
+		})
+
+		It("returns an error when the deploy failed", func() {
+			_, err := virtualmachine.DeployLibraryItem(vmCtx, itemID,
+				func(goctx.Context) (*types.ManagedObjectReference, error) {
+					return nil, errors.New("no space left")
+				})
+			Expect(err).To(MatchError(ContainSubstring("no space left")))
+			Expect(vmCtx.VM.Status.LastTask.State).To(Equal(vmopv1.VirtualMachineTaskStateError))
+			Expect(vmCtx.VM.Status.LastTask.Fault.Message).To(Equal("no space left"))
+		})
+
+		It("forgets a deploy that no longer exists", func() {
+			vmCtx.VM.Status.LastTask = &vmopv1.VirtualMachineTaskInfo{
+				TaskRef:       itemID,
+				DescriptionID: virtualmachine.DeployLibraryItemDescriptionID,
+				State:         vmopv1.VirtualMachineTaskStateRunning,
+			}
+
+			Expect(virtualmachine.CheckLastTask(vmCtx, ctx.VCClient.Client)).To(Succeed())
+			Expect(vmCtx.VM.Status.LastTask).To(BeNil())
+		})
+	})
+}
+
+func powerOn(vmCtx context.VirtualMachineContext, vcVM *object.VirtualMachine) *object.Task {
+	t, err := vcVM.PowerOn(vmCtx)
+	ExpectWithOffset(1, err).ToNot(HaveOccurred())
+	return t
+}
@@ -1,4 +1,4 @@
-// Copyright (c) 2021 VMware, Inc. All Rights Reserved.
+// Copyright (c) 2021-2023 VMware, Inc. All Rights Reserved.
 // SPDX-License-Identifier: Apache-2.0
 
 package virtualmachine_test
@@ -16,6 +16,8 @@ func vcSimTests() {
 	Describe("Delete", deleteTests)
 	Describe("Power State", powerStateTests)
 	Describe("Publish", publishTests)
+	Describe("Serial Console", serialConsoleTests)
+	Describe("Task", taskTests)
 }
 
 var suite = builder.NewTestSuite()
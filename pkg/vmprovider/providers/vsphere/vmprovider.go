@@ -9,6 +9,7 @@ import (
 	"fmt"
 	"math/rand"
 	"os"
+	"sort"
 	"strings"
 	"sync"
 	"sync/atomic"
@@ -29,7 +30,9 @@ import (
 	imgregv1a1 "github.com/vmware-tanzu/vm-operator/external/image-registry/api/v1alpha1"
 
 	vmopv1 "github.com/vmware-tanzu/vm-operator/api/v1alpha1"
+	"github.com/vmware-tanzu/vm-operator/pkg/conditions"
 	"github.com/vmware-tanzu/vm-operator/pkg/context"
+	"github.com/vmware-tanzu/vm-operator/pkg/imagetrust"
 	"github.com/vmware-tanzu/vm-operator/pkg/lib"
 	"github.com/vmware-tanzu/vm-operator/pkg/record"
 	"github.com/vmware-tanzu/vm-operator/pkg/topology"
@@ -39,6 +42,8 @@ import (
 	vcconfig "github.com/vmware-tanzu/vm-operator/pkg/vmprovider/providers/vsphere/config"
 	"github.com/vmware-tanzu/vm-operator/pkg/vmprovider/providers/vsphere/constants"
 	"github.com/vmware-tanzu/vm-operator/pkg/vmprovider/providers/vsphere/contentlibrary"
+	"github.com/vmware-tanzu/vm-operator/pkg/vmprovider/providers/vsphere/placement"
+	"github.com/vmware-tanzu/vm-operator/pkg/vmprovider/providers/vsphere/scheduler"
 	"github.com/vmware-tanzu/vm-operator/pkg/vmprovider/providers/vsphere/vcenter"
 )
 
@@ -68,13 +73,37 @@ type vSphereVMProvider struct {
 	ovfCache          *util.Cache[VersionedOVFEnvelope]
 	ovfCacheLockPool  *util.LockPool[string, *sync.RWMutex]
 
-	vcClientLock sync.Mutex
-	vcClient     *vcclient.Client
+	// vcPools are the pools of the clients of the vCenters, by the vCenter and Datacenter they are connected to.
+	vcPoolsLock sync.Mutex
+	vcPools     map[topology.VCenterRef]*vcclient.Pool
+	poolOpts    vcclient.PoolOptions
+
+	// unreachableVCenters are the errors of the vCenters that are unreachable, from which the
+	// VCenterReachable condition is computed.
+	conditionsLock      sync.Mutex
+	unreachableVCenters map[topology.VCenterRef]error
+	vcReachable         *vmopv1.Condition
+
+	// schedulers limit the concurrent operations of each kind, and start the queued operations fairly.
+	schedulers map[scheduler.Operation]*scheduler.Scheduler
+
+	// runningCreates are the release funcs of the create operations whose clone or deploy task is still
+	// running, by VM, so the operation is counted by the create scheduler until the task completes.
+	runningCreatesLock sync.Mutex
+	runningCreates     map[string]func()
 }
 
 func NewVSphereVMProviderFromClient(
 	client ctrlruntime.Client,
 	recorder record.Recorder) vmprovider.VirtualMachineProviderInterface {
+	return NewVSphereVMProvider(client, recorder, vcclient.PoolOptions{})
+}
+
+// NewVSphereVMProvider returns a vSphere VM provider whose vCenter clients are pooled with poolOpts.
+func NewVSphereVMProvider(
+	client ctrlruntime.Client,
+	recorder record.Recorder,
+	poolOpts vcclient.PoolOptions) vmprovider.VirtualMachineProviderInterface {
 	ovfCache, ovfLockPool := InitOvfCacheAndLockPool(
 		ovfCacheItemExpiration, ovfCacheExpirationCheckInterval, ovfCacheMaxItem)
 
@@ -84,6 +113,8 @@ func NewVSphereVMProviderFromClient(
 		globalExtraConfig: getExtraConfig(),
 		ovfCache:          ovfCache,
 		ovfCacheLockPool:  ovfLockPool,
+		poolOpts:          poolOpts,
+		schedulers:        newSchedulers(),
 	}
 }
 
@@ -132,26 +163,298 @@ func getExtraConfig() map[string]string {
 	return ec
 }
 
+// getVcClient returns the client of the default vCenter.
 func (vs *vSphereVMProvider) getVcClient(ctx goctx.Context) (*vcclient.Client, error) {
-	vs.vcClientLock.Lock()
-	defer vs.vcClientLock.Unlock()
+	return vs.getVcClientForRef(ctx, topology.VCenterRef{})
+}
+
+// getVcClientForRef returns a client of the vCenter and Datacenter of vcRef, from their pool. The clients are
+// created from the provider ConfigMap of the vCenter, and are replaced by new clients when they need to
+// re-authenticate.
+func (vs *vSphereVMProvider) getVcClientForRef(
+	ctx goctx.Context,
+	vcRef topology.VCenterRef) (*vcclient.Client, error) {
+
+	return vs.getVcPool(vcRef).Get(ctx)
+}
+
+// getVcPool returns the pool of the clients of the vCenter and Datacenter of vcRef.
+func (vs *vSphereVMProvider) getVcPool(vcRef topology.VCenterRef) *vcclient.Pool {
+	vs.vcPoolsLock.Lock()
+	defer vs.vcPoolsLock.Unlock()
+
+	if pool, ok := vs.vcPools[vcRef]; ok {
+		return pool
+	}
+
+	getConfig := func(ctx goctx.Context) (*vcconfig.VSphereVMProviderConfig, error) {
+		config, err := vcconfig.GetProviderConfigForVCenter(ctx, vs.k8sClient, vcRef.VCenter)
+		if err != nil {
+			return nil, err
+		}
+		if vcRef.Datacenter != "" {
+			config.Datacenter = vcRef.Datacenter
+		}
+		return config, nil
+	}
+
+	pool := vcclient.NewPool(vcRef.VCenter, getConfig, vs.poolOpts, func(err error) {
+		vs.setVCenterReachable(vcRef, err)
+	})
+
+	if vs.vcPools == nil {
+		vs.vcPools = map[topology.VCenterRef]*vcclient.Pool{}
+	}
+	vs.vcPools[vcRef] = pool
+	return pool
+}
+
+// setVCenterReachable records whether the vCenter of vcRef is reachable, and updates the VCenterReachable
+// condition of the provider. err is nil when the vCenter is reachable.
+func (vs *vSphereVMProvider) setVCenterReachable(vcRef topology.VCenterRef, err error) {
+	vs.conditionsLock.Lock()
+	defer vs.conditionsLock.Unlock()
+
+	if err == nil {
+		delete(vs.unreachableVCenters, vcRef)
+	} else {
+		if vs.unreachableVCenters == nil {
+			vs.unreachableVCenters = map[topology.VCenterRef]error{}
+		}
+		vs.unreachableVCenters[vcRef] = err
+	}
+
+	vs.updateVCenterReachableCondition()
+}
 
-	if vs.vcClient != nil {
-		return vs.vcClient, nil
+// updateVCenterReachableCondition sets the VCenterReachable condition to false when any vCenter is
+// unreachable. The caller must hold conditionsLock.
+func (vs *vSphereVMProvider) updateVCenterReachableCondition() {
+	var condition *vmopv1.Condition
+	if len(vs.unreachableVCenters) == 0 {
+		condition = conditions.TrueCondition(vmopv1.VCenterReachableCondition)
+	} else {
+		messages := make([]string, 0, len(vs.unreachableVCenters))
+		for vcRef, err := range vs.unreachableVCenters {
+			name := vcRef.VCenter
+			if name == "" {
+				name = "default"
+			}
+			messages = append(messages, fmt.Sprintf("%s: %v", name, err))
+		}
+		sort.Strings(messages)
+		condition = conditions.FalseCondition(vmopv1.VCenterReachableCondition, vmopv1.VCenterUnreachableReason,
+			vmopv1.ConditionSeverityError, "%s", strings.Join(messages, "; "))
+	}
+
+	if vs.vcReachable != nil && vs.vcReachable.Status == condition.Status && vs.vcReachable.Message == condition.Message {
+		return
+	}
+	vs.vcReachable = condition
+}
+
+// GetConditions returns the provider-wide conditions. The VCenterReachable condition is absent until a
+// vCenter has been called.
+func (vs *vSphereVMProvider) GetConditions() vmopv1.Conditions {
+	vs.conditionsLock.Lock()
+	defer vs.conditionsLock.Unlock()
+
+	if vs.vcReachable == nil {
+		return nil
+	}
+	return vmopv1.Conditions{*vs.vcReachable}
+}
+
+// getZoneVCenterRefs returns the vCenter and Datacenter that manage each availability zone. Without the
+// WCP_FaultDomains FSS, the default zone is always managed by the default vCenter.
+func (vs *vSphereVMProvider) getZoneVCenterRefs(ctx goctx.Context) (topology.ZoneVCenterRefs, error) {
+	if !lib.IsWcpFaultDomainsFSSEnabled() {
+		return nil, nil
 	}
+	return vcconfig.GetZoneVCenterRefs(ctx, vs.k8sClient)
+}
 
-	config, err := vcconfig.GetProviderConfig(ctx, vs.k8sClient)
+// getVcClientForZone returns the client of the vCenter and Datacenter that manage the zone, and their
+// VCenterRef. An empty zone name is the default zone.
+func (vs *vSphereVMProvider) getVcClientForZone(
+	ctx goctx.Context,
+	zoneName string) (*vcclient.Client, topology.VCenterRef, error) {
+
+	zoneVCenterRefs, err := vs.getZoneVCenterRefs(ctx)
 	if err != nil {
-		return nil, err
+		return nil, topology.VCenterRef{}, err
 	}
 
-	vcClient, err := vcclient.NewClient(ctx, config)
+	vcRef, err := topology.LookupVCenterRefForZone(ctx, vs.k8sClient, zoneVCenterRefs, zoneName)
 	if err != nil {
-		return nil, err
+		return nil, topology.VCenterRef{}, err
+	}
+
+	vcClient, err := vs.getVcClientForRef(ctx, vcRef)
+	if err != nil {
+		return nil, topology.VCenterRef{}, err
 	}
 
-	vs.vcClient = vcClient
-	return vcClient, nil
+	return vcClient, vcRef, nil
+}
+
+// getVcClientForVM returns the client of the vCenter and Datacenter that manage the VM, and their VCenterRef.
+// A VM that has not been assigned a zone yet is on the vCenter saved in its PlacementVCenterAnnotationKey
+// annotation, if any, and otherwise on the vCenter of the first zone of its namespace that can be connected
+// to until placement selects its zone.
+func (vs *vSphereVMProvider) getVcClientForVM(
+	vmCtx context.VirtualMachineContext) (*vcclient.Client, topology.VCenterRef, error) {
+
+	zoneName := vmCtx.VM.Labels[topology.KubernetesTopologyZoneLabelKey]
+	if zoneName != "" || !lib.IsWcpFaultDomainsFSSEnabled() {
+		return vs.getVcClientForZone(vmCtx, zoneName)
+	}
+
+	if vcRef, ok := parsePlacementVCenter(vmCtx.VM.Annotations[constants.PlacementVCenterAnnotationKey]); ok {
+		vcClient, err := vs.getVcClientForRef(vmCtx, vcRef)
+		if err != nil {
+			return nil, topology.VCenterRef{}, err
+		}
+		return vcClient, vcRef, nil
+	}
+
+	zones, err := topology.GetAvailabilityZones(vmCtx, vs.k8sClient)
+	if err != nil {
+		return nil, topology.VCenterRef{}, err
+	}
+
+	zoneVCenterRefs, err := vs.getZoneVCenterRefs(vmCtx)
+	if err != nil {
+		return nil, topology.VCenterRef{}, err
+	}
+
+	sort.Slice(zones, func(i, j int) bool { return zones[i].Name < zones[j].Name })
+
+	vcRefs := []topology.VCenterRef{}
+	for _, zone := range zones {
+		if _, ok := zone.Spec.Namespaces[vmCtx.VM.Namespace]; ok {
+			vcRefs = append(vcRefs, zoneVCenterRefs[zone.Name])
+		}
+	}
+	if len(vcRefs) == 0 {
+		// Placement will fail since the namespace is not in any zone.
+		vcRefs = append(vcRefs, topology.VCenterRef{})
+	}
+
+	var vcErr error
+	for _, vcRef := range vcRefs {
+		vcClient, err := vs.getVcClientForRef(vmCtx, vcRef)
+		if err != nil {
+			vcErr = err
+			continue
+		}
+		return vcClient, vcRef, nil
+	}
+
+	return nil, topology.VCenterRef{}, vcErr
+}
+
+// getPlacementZoneClients returns the clients of the vCenters that manage the zones the VM may be placed
+// in, and the VCenterRef of each zone. When the zone is selected by placement, these are the zones of the
+// VM's namespace across all the vCenters, and multipleVCenters is true when the zones are managed by more
+// than one vCenter. The zones of the vCenters that cannot be connected to are left out, unless none of the
+// vCenters can be connected to. Otherwise, only the VM's zone is a candidate, and its vCenter is the one of
+// vcClient.
+func (vs *vSphereVMProvider) getPlacementZoneClients(
+	vmCtx context.VirtualMachineContext,
+	vcClient *vcclient.Client,
+	vcRef topology.VCenterRef) (zoneClients placement.ZoneClients, zoneVCenterRefs topology.ZoneVCenterRefs,
+	multipleVCenters bool, err error) {
+
+	zoneName := vmCtx.VM.Labels[topology.KubernetesTopologyZoneLabelKey]
+	if zoneName != "" || !lib.IsWcpFaultDomainsFSSEnabled() {
+		if zoneName == "" {
+			zoneName = topology.DefaultAvailabilityZoneName
+		}
+		return placement.ZoneClients{zoneName: vcClient.VimClient()},
+			topology.ZoneVCenterRefs{zoneName: vcRef}, false, nil
+	}
+
+	zones, err := topology.GetAvailabilityZones(vmCtx, vs.k8sClient)
+	if err != nil {
+		return nil, nil, false, err
+	}
+
+	zoneVCenterRefs, err = vs.getZoneVCenterRefs(vmCtx)
+	if err != nil {
+		return nil, nil, false, err
+	}
+
+	zoneClients = placement.ZoneClients{}
+	vcClients := map[topology.VCenterRef]*vcclient.Client{vcRef: vcClient}
+	vcRefs := map[topology.VCenterRef]struct{}{}
+	var vcErr error
+
+	for _, zone := range zones {
+		if _, ok := zone.Spec.Namespaces[vmCtx.VM.Namespace]; !ok {
+			continue
+		}
+
+		zoneVCRef := zoneVCenterRefs[zone.Name]
+		vcRefs[zoneVCRef] = struct{}{}
+
+		c, ok := vcClients[zoneVCRef]
+		if !ok {
+			c, err = vs.getVcClientForRef(vmCtx, zoneVCRef)
+			if err != nil {
+				vmCtx.Logger.Error(err, "Skipping the zones of vCenter for placement",
+					"vCenter", zoneVCRef.VCenter, "datacenter", zoneVCRef.Datacenter)
+				vcErr = err
+			}
+			vcClients[zoneVCRef] = c
+		}
+
+		if c != nil {
+			zoneClients[zone.Name] = c.VimClient()
+		}
+	}
+
+	if len(zoneClients) == 0 && vcErr != nil {
+		return nil, nil, false, vcErr
+	}
+
+	return zoneClients, zoneVCenterRefs, len(vcRefs) > 1, nil
+}
+
+// savePlacementVCenter patches the PlacementVCenterAnnotationKey annotation of the VM with the vCenter of
+// the zone selected by its placement. The VM is patched right away, instead of with the rest of the changes
+// of the reconcile, so that the selection is not lost when the VM is created but the reconcile fails.
+func (vs *vSphereVMProvider) savePlacementVCenter(
+	vmCtx context.VirtualMachineContext,
+	vcRef topology.VCenterRef) error {
+
+	value := vcRef.VCenter + "/" + vcRef.Datacenter
+
+	vm := vmCtx.VM.DeepCopy()
+	patch := ctrlruntime.MergeFrom(vm.DeepCopy())
+	if vm.Annotations == nil {
+		vm.Annotations = map[string]string{}
+	}
+	vm.Annotations[constants.PlacementVCenterAnnotationKey] = value
+
+	if err := vs.k8sClient.Patch(vmCtx, vm, patch); err != nil {
+		return errors.Wrap(err, "failed to save the vCenter selected for placement")
+	}
+
+	if vmCtx.VM.Annotations == nil {
+		vmCtx.VM.Annotations = map[string]string{}
+	}
+	vmCtx.VM.Annotations[constants.PlacementVCenterAnnotationKey] = value
+	return nil
+}
+
+// parsePlacementVCenter returns the VCenterRef of the value of the PlacementVCenterAnnotationKey annotation.
+func parsePlacementVCenter(value string) (topology.VCenterRef, bool) {
+	vCenter, datacenter, ok := strings.Cut(value, "/")
+	if !ok {
+		return topology.VCenterRef{}, false
+	}
+	return topology.VCenterRef{VCenter: vCenter, Datacenter: datacenter}, true
 }
 
 func (vs *vSphereVMProvider) UpdateVcPNID(ctx goctx.Context, vcPNID, vcPort string) error {
@@ -162,22 +465,48 @@ func (vs *vSphereVMProvider) UpdateVcPNID(ctx goctx.Context, vcPNID, vcPort stri
 
 	// Our controller-runtime client does not cache ConfigMaps & Secrets, so the next time
 	// getVcClient() is called, it will fetch newly updated CM.
-	vs.clearAndLogoutVcClient(ctx)
+	vs.clearAndLogoutVcClients(ctx, func(vcRef topology.VCenterRef) bool { return vcRef.VCenter == "" })
 	return nil
 }
 
-func (vs *vSphereVMProvider) ResetVcClient(ctx goctx.Context) {
-	vs.clearAndLogoutVcClient(ctx)
+// ResetVcClient is called when the credentials have changed. The clients are replaced by clients that are
+// logged in with the new credentials the next time they are used, and the replaced clients are logged out
+// after a delay so that the requests that are in-flight on them are not failed.
+func (vs *vSphereVMProvider) ResetVcClient(_ goctx.Context) {
+	vs.vcPoolsLock.Lock()
+	defer vs.vcPoolsLock.Unlock()
+
+	for _, pool := range vs.vcPools {
+		pool.CredentialsChanged()
+	}
 }
 
-func (vs *vSphereVMProvider) clearAndLogoutVcClient(ctx goctx.Context) {
-	vs.vcClientLock.Lock()
-	vcClient := vs.vcClient
-	vs.vcClient = nil
-	vs.vcClientLock.Unlock()
+// clearAndLogoutVcClients logs out and removes the clients of the vCenters that match.
+func (vs *vSphereVMProvider) clearAndLogoutVcClients(ctx goctx.Context, match func(topology.VCenterRef) bool) {
+	var pools []*vcclient.Pool
+
+	vs.vcPoolsLock.Lock()
+	for vcRef, pool := range vs.vcPools {
+		if match(vcRef) {
+			pools = append(pools, pool)
+			delete(vs.vcPools, vcRef)
+		}
+	}
+	vs.vcPoolsLock.Unlock()
+
+	vs.conditionsLock.Lock()
+	for vcRef := range vs.unreachableVCenters {
+		if match(vcRef) {
+			delete(vs.unreachableVCenters, vcRef)
+		}
+	}
+	if vs.vcReachable != nil {
+		vs.updateVCenterReachableCondition()
+	}
+	vs.conditionsLock.Unlock()
 
-	if vcClient != nil {
-		vcClient.Logout(ctx)
+	for _, pool := range pools {
+		pool.Logout(ctx)
 	}
 }
 
@@ -250,6 +579,31 @@ func (vs *vSphereVMProvider) SyncVirtualMachineImage(ctx goctx.Context, cli, vmi
 	return nil
 }
 
+// VerifyVirtualMachineImage verifies the library item of the given content library item against the image
+// trust policy.
+func (vs *vSphereVMProvider) VerifyVirtualMachineImage(ctx goctx.Context, cli ctrlruntime.Object,
+	policy *imagetrust.Policy) error {
+
+	var itemID string
+	switch cli := cli.(type) {
+	case *imgregv1a1.ContentLibraryItem:
+		itemID = cli.Spec.UUID
+	case *imgregv1a1.ClusterContentLibraryItem:
+		itemID = cli.Spec.UUID
+	default:
+		return errors.Errorf("unexpected content library item type %T", cli)
+	}
+
+	log.V(4).Info("Verify VirtualMachineImage", "cliName", cli.GetName(), "itemID", itemID)
+
+	client, err := vs.getVcClient(ctx)
+	if err != nil {
+		return err
+	}
+
+	return client.ContentLibClient().VerifyLibraryItem(ctx, itemID, policy)
+}
+
 // getOvfEnvelope gets the OVF envelope from the cache if it exists and matches version.
 // If not, it downloads the OVF envelope from vCenter and stores it in the cache.
 func (vs *vSphereVMProvider) getOvfEnvelope(
@@ -317,6 +671,67 @@ func (vs *vSphereVMProvider) UpdateContentLibraryItem(ctx goctx.Context, itemID,
 	return client.ContentLibClient().UpdateLibraryItem(ctx, itemID, newName, newDescription)
 }
 
+func (vs *vSphereVMProvider) DeleteContentLibraryItem(ctx goctx.Context, itemID string) error {
+	log.V(4).Info("Delete Content Library Item", "itemID", itemID)
+
+	client, err := vs.getVcClient(ctx)
+	if err != nil {
+		return err
+	}
+
+	return client.ContentLibClient().DeleteLibraryItem(ctx, itemID)
+}
+
+// ImportContentLibraryItemFromURL starts importing the OVA or OVF at sourceURL into a new item of the
+// content library. The IDs of the new item and of the update session that pulls its files are returned.
+func (vs *vSphereVMProvider) ImportContentLibraryItemFromURL(ctx goctx.Context, cl *imgregv1a1.ContentLibrary,
+	itemName, itemDescription, sourceURL string, checksum *library.Checksum) (string, string, error) {
+	log.V(4).Info("Import Content Library Item from URL",
+		"UUID", cl.Spec.UUID, "item name", itemName, "url", sourceURL)
+
+	client, err := vs.getVcClient(ctx)
+	if err != nil {
+		return "", "", err
+	}
+
+	return client.ContentLibClient().ImportLibraryItemFromURL(ctx, cl.Spec.UUID, itemName, itemDescription,
+		sourceURL, checksum)
+}
+
+func (vs *vSphereVMProvider) GetContentLibraryItemImportStatus(ctx goctx.Context,
+	sessionID string) (*vmprovider.ImportStatus, error) {
+	log.V(4).Info("Get Content Library Item import status", "sessionID", sessionID)
+
+	client, err := vs.getVcClient(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	status, err := client.ContentLibClient().GetLibraryItemImportStatus(ctx, sessionID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &vmprovider.ImportStatus{
+		// The phases have the same values.
+		Phase:            vmprovider.ImportPhase(status.Phase),
+		BytesTransferred: status.BytesTransferred,
+		Size:             status.Size,
+		Message:          status.Message,
+	}, nil
+}
+
+func (vs *vSphereVMProvider) CompleteContentLibraryItemImport(ctx goctx.Context, sessionID string) error {
+	log.V(4).Info("Complete Content Library Item import", "sessionID", sessionID)
+
+	client, err := vs.getVcClient(ctx)
+	if err != nil {
+		return err
+	}
+
+	return client.ContentLibClient().CompleteLibraryItemImport(ctx, sessionID)
+}
+
 func (vs *vSphereVMProvider) getOpID(vm *vmopv1.VirtualMachine, operation string) string {
 	const charset = "0123456789abcdef"
 
@@ -333,9 +748,16 @@ func (vs *vSphereVMProvider) getOpID(vm *vmopv1.VirtualMachine, operation string
 func (vs *vSphereVMProvider) getVM(
 	vmCtx context.VirtualMachineContext,
 	client *vcclient.Client,
+	vcRef topology.VCenterRef,
 	notFoundReturnErr bool) (*object.VirtualMachine, error) {
 
-	vcVM, err := vcenter.GetVirtualMachine(vmCtx, vs.k8sClient, client.VimClient(), client.Datacenter(), client.Finder())
+	zoneVCenterRefs, err := vs.getZoneVCenterRefs(vmCtx)
+	if err != nil {
+		return nil, err
+	}
+
+	vcVM, err := vcenter.GetVirtualMachine(vmCtx, vs.k8sClient, zoneVCenterRefs, vcRef, client.VimClient(),
+		client.Datacenter(), client.Finder())
 	if err != nil {
 		return nil, err
 	}
@@ -388,12 +810,12 @@ func (vs *vSphereVMProvider) computeCPUMinFrequency(ctx goctx.Context) (uint64,
 		return 0, err
 	}
 
-	client, err := vs.getVcClient(ctx)
-	if err != nil {
-		return 0, err
-	}
-
 	if !lib.IsWcpFaultDomainsFSSEnabled() {
+		client, err := vs.getVcClient(ctx)
+		if err != nil {
+			return 0, err
+		}
+
 		ccr, err := vcenter.GetResourcePoolOwnerMoRef(ctx, client.VimClient(), client.Config().ResourcePool)
 		if err != nil {
 			return 0, err
@@ -405,10 +827,21 @@ func (vs *vSphereVMProvider) computeCPUMinFrequency(ctx goctx.Context) (uint64,
 		}
 	}
 
+	zoneVCenterRefs, err := vs.getZoneVCenterRefs(ctx)
+	if err != nil {
+		return 0, err
+	}
+
 	var errs []error
 
 	var minFreq uint64
 	for _, az := range availabilityZones {
+		client, err := vs.getVcClientForRef(ctx, zoneVCenterRefs[az.Name])
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+
 		moIDs := az.Spec.ClusterComputeResourceMoIDs
 		if len(moIDs) == 0 {
 			moIDs = []string{az.Spec.ClusterComputeResourceMoId} // HA TEMP
@@ -479,12 +912,65 @@ func ResVMToVirtualMachineImage(ctx goctx.Context, vm *object.VirtualMachine) (*
 	}, nil
 }
 
-func (vs *vSphereVMProvider) GetTasksByActID(ctx goctx.Context, actID string) (tasksInfo []types.TaskInfo, retErr error) {
-	vcClient, err := vs.getVcClient(ctx)
+// GetTasksByActID returns the tasks with the activation ID from the vCenters of all the zones, since the
+// activation IDs are unique across the vCenters.
+func (vs *vSphereVMProvider) GetTasksByActID(ctx goctx.Context, actID string) ([]types.TaskInfo, error) {
+	vcRefs, err := vs.getVCenterRefs(ctx)
 	if err != nil {
 		return nil, err
 	}
 
+	var taskList []types.TaskInfo
+	for _, vcRef := range vcRefs {
+		vcClient, err := vs.getVcClientForRef(ctx, vcRef)
+		if err != nil {
+			return nil, err
+		}
+
+		tasks, err := getTasksByActID(ctx, vcClient, actID)
+		if err != nil {
+			return nil, err
+		}
+		taskList = append(taskList, tasks...)
+	}
+
+	log.V(5).Info("found tasks", "actID", actID, "tasks", taskList)
+	return taskList, nil
+}
+
+// getVCenterRefs returns a VCenterRef for each of the vCenters of the zones. The default vCenter is always
+// included.
+func (vs *vSphereVMProvider) getVCenterRefs(ctx goctx.Context) ([]topology.VCenterRef, error) {
+	vcRefs := []topology.VCenterRef{{}}
+
+	if !lib.IsWcpFaultDomainsFSSEnabled() {
+		return vcRefs, nil
+	}
+
+	availabilityZones, err := topology.GetAvailabilityZones(ctx, vs.k8sClient)
+	if err != nil {
+		return nil, err
+	}
+
+	zoneVCenterRefs, err := vs.getZoneVCenterRefs(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	seen := map[string]struct{}{"": {}}
+	for _, az := range availabilityZones {
+		if vCenter := zoneVCenterRefs[az.Name].VCenter; vCenter != "" {
+			if _, ok := seen[vCenter]; !ok {
+				seen[vCenter] = struct{}{}
+				vcRefs = append(vcRefs, topology.VCenterRef{VCenter: vCenter})
+			}
+		}
+	}
+
+	return vcRefs, nil
+}
+
+func getTasksByActID(ctx goctx.Context, vcClient *vcclient.Client, actID string) (tasksInfo []types.TaskInfo, retErr error) {
 	taskManager := task.NewManager(vcClient.VimClient())
 	filterSpec := types.TaskFilterSpec{
 		ActivationId: []string{actID},
@@ -514,6 +1000,31 @@ func (vs *vSphereVMProvider) GetTasksByActID(ctx goctx.Context, actID string) (t
 		taskList = append(taskList, nextTasks...)
 	}
 
-	log.V(5).Info("found tasks", "actID", actID, "tasks", taskList)
 	return taskList, nil
 }
+
+// GetTaskInfo returns the info of the vSphere task with the ID on the vCenter of the VM.
+func (vs *vSphereVMProvider) GetTaskInfo(
+	ctx goctx.Context,
+	vm *vmopv1.VirtualMachine,
+	taskID string) (*types.TaskInfo, error) {
+
+	vmCtx := context.VirtualMachineContext{
+		Context: ctx,
+		Logger:  log.WithValues("vmName", vm.NamespacedName()),
+		VM:      vm,
+	}
+
+	vcClient, _, err := vs.getVcClientForVM(vmCtx)
+	if err != nil {
+		return nil, err
+	}
+
+	var t mo.Task
+	taskRef := types.ManagedObjectReference{Type: "Task", Value: taskID}
+	if err := object.NewCommon(vcClient.VimClient(), taskRef).Properties(ctx, taskRef, []string{"info"}, &t); err != nil {
+		return nil, errors.Wrapf(err, "failed to get info of task %s", taskID)
+	}
+
+	return &t.Info, nil
+}
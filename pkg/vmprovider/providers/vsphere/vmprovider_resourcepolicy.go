@@ -1,4 +1,4 @@
-// Copyright (c) 2020-2021 VMware, Inc. All Rights Reserved.
+// Copyright (c) 2020-2023 VMware, Inc. All Rights Reserved.
 // SPDX-License-Identifier: Apache-2.0
 
 package vsphere
@@ -6,12 +6,18 @@ package vsphere
 import (
 	"context"
 	"fmt"
+	"sort"
 
+	"github.com/vmware/govmomi/object"
+	"github.com/vmware/govmomi/vim25"
 	vimtypes "github.com/vmware/govmomi/vim25/types"
 	k8serrors "k8s.io/apimachinery/pkg/util/errors"
+	ctrlclient "sigs.k8s.io/controller-runtime/pkg/client"
 
 	vmopv1 "github.com/vmware-tanzu/vm-operator/api/v1alpha1"
+	"github.com/vmware-tanzu/vm-operator/pkg/conditions"
 	"github.com/vmware-tanzu/vm-operator/pkg/topology"
+	vcclient "github.com/vmware-tanzu/vm-operator/pkg/vmprovider/providers/vsphere/client"
 	"github.com/vmware-tanzu/vm-operator/pkg/vmprovider/providers/vsphere/clustermodules"
 	"github.com/vmware-tanzu/vm-operator/pkg/vmprovider/providers/vsphere/vcenter"
 )
@@ -22,7 +28,7 @@ func (vs *vSphereVMProvider) IsVirtualMachineSetResourcePolicyReady(
 	azName string,
 	resourcePolicy *vmopv1.VirtualMachineSetResourcePolicy) (bool, error) {
 
-	client, err := vs.getVcClient(ctx)
+	client, _, err := vs.getVcClientForZone(ctx, azName)
 	if err != nil {
 		return false, err
 	}
@@ -62,75 +68,252 @@ func (vs *vSphereVMProvider) IsVirtualMachineSetResourcePolicyReady(
 }
 
 // CreateOrUpdateVirtualMachineSetResourcePolicy creates if a VirtualMachineSetResourcePolicy doesn't exist, updates otherwise.
+// The reservations and limits of the child ResourcePools that have been edited in vCenter are corrected, and the
+// readiness of the Folder, ResourcePools, and ClusterModules in each zone is reported in the status conditions.
 func (vs *vSphereVMProvider) CreateOrUpdateVirtualMachineSetResourcePolicy(
 	ctx context.Context,
 	resourcePolicy *vmopv1.VirtualMachineSetResourcePolicy) error {
 
-	folderMoID, rpMoIDs, err := vs.getNamespaceFolderAndRPMoIDs(ctx, resourcePolicy.Namespace)
+	availabilityZones, err := topology.GetAvailabilityZones(ctx, vs.k8sClient)
 	if err != nil {
 		return err
 	}
 
-	client, err := vs.getVcClient(ctx)
+	zoneVCenterRefs, err := vs.getZoneVCenterRefs(ctx)
 	if err != nil {
 		return err
 	}
 
-	vimClient := client.VimClient()
-	var errs []error
+	// The Folder is VC-scoped, so the namespace has a Folder on each vCenter of its zones.
+	folderMoIDs := map[topology.VCenterRef]string{}
+	for _, az := range availabilityZones {
+		if nsInfo, ok := az.Spec.Namespaces[resourcePolicy.Namespace]; ok {
+			if vcRef := zoneVCenterRefs[az.Name]; folderMoIDs[vcRef] == "" {
+				folderMoIDs[vcRef] = nsInfo.FolderMoId
+			}
+		}
+	}
+	if len(folderMoIDs) == 0 {
+		return fmt.Errorf("namespace %s not present in any AvailabilityZones", resourcePolicy.Namespace)
+	}
 
-	_, err = vcenter.CreateFolder(ctx, vimClient, folderMoID, resourcePolicy.Spec.Folder.Name)
+	minCPUFreq, err := vs.getOrComputeCPUMinFrequency(ctx)
 	if err != nil {
-		errs = append(errs, err)
+		return err
 	}
 
-	for _, rpMoID := range rpMoIDs {
-		_, err := vcenter.CreateOrUpdateChildResourcePool(ctx, vimClient, rpMoID, &resourcePolicy.Spec.ResourcePool)
+	var errs []error
+
+	vcClients := map[topology.VCenterRef]*vcclient.Client{}
+	vcClientErrs := map[topology.VCenterRef]error{}
+	folderErrs := map[topology.VCenterRef]error{}
+	for vcRef, folderMoID := range folderMoIDs {
+		client, err := vs.getVcClientForRef(ctx, vcRef)
 		if err != nil {
+			// The error is reported for each of the vCenter's zones below.
+			vcClientErrs[vcRef] = err
+			folderErrs[vcRef] = err
+			continue
+		}
+		vcClients[vcRef] = client
+
+		if _, err := vcenter.CreateFolder(ctx, client.VimClient(), folderMoID, resourcePolicy.Spec.Folder.Name); err != nil {
+			folderErrs[vcRef] = err
 			errs = append(errs, err)
 		}
+	}
 
-		clusterRef, err := vcenter.GetResourcePoolOwnerMoRef(ctx, vimClient, rpMoID)
-		if err == nil {
-			err = vs.createClusterModules(ctx, client.ClusterModuleClient(), clusterRef.Reference(), resourcePolicy)
+	zoneStatuses := make([]vmopv1.ResourcePolicyZoneStatus, 0, len(availabilityZones))
+	for _, az := range availabilityZones {
+		nsInfo, ok := az.Spec.Namespaces[resourcePolicy.Namespace]
+		if !ok {
+			continue
 		}
-		if err != nil {
-			errs = append(errs, err)
+
+		rpMoIDs := nsInfo.PoolMoIDs
+		if len(rpMoIDs) == 0 {
+			rpMoIDs = []string{nsInfo.PoolMoId}
+		}
+
+		vcRef := zoneVCenterRefs[az.Name]
+		client, clientErr, folderErr := vcClients[vcRef], vcClientErrs[vcRef], folderErrs[vcRef]
+
+		// Start from the zone's existing conditions so their LastTransitionTime is preserved.
+		zoneStatus := vmopv1.ResourcePolicyZoneStatus{Name: az.Name}
+		for _, zs := range resourcePolicy.Status.Zones {
+			if zs.Name == az.Name {
+				zoneStatus.Conditions = zs.Conditions
+				break
+			}
+		}
+		zone := &zoneConditions{VirtualMachineSetResourcePolicy: resourcePolicy, zone: &zoneStatus}
+
+		var rpErrs, moduleErrs []error
+		if clientErr != nil {
+			rpErrs = []error{clientErr}
+			moduleErrs = rpErrs
+		}
+
+		for _, rpMoID := range rpMoIDs {
+			if client == nil {
+				break
+			}
+			vimClient := client.VimClient()
+
+			childMoID, updated, err := vcenter.CreateOrUpdateChildResourcePool(ctx, vimClient, rpMoID,
+				&resourcePolicy.Spec.ResourcePool, minCPUFreq)
+			if err != nil {
+				rpErrs = append(rpErrs, err)
+			} else if updated {
+				vs.eventRecorder.Eventf(resourcePolicy, "ResourcePoolDriftCorrected",
+					"Corrected the reservations and limits of ResourcePool %s in zone %s", childMoID, az.Name)
+			}
+
+			clusterRef, err := vcenter.GetResourcePoolOwnerMoRef(ctx, vimClient, rpMoID)
+			if err == nil {
+				err = vs.createClusterModules(ctx, client.ClusterModuleClient(), clusterRef.Reference(), resourcePolicy)
+			}
+			if err == nil {
+				err = vs.updateClusterModuleRules(ctx, vimClient, clusterRef.Reference(), resourcePolicy)
+			}
+			if err != nil {
+				moduleErrs = append(moduleErrs, err)
+			}
 		}
+
+		if folderErr != nil {
+			conditions.MarkFalse(zone, vmopv1.ResourcePolicyFolderReadyCondition,
+				vmopv1.ResourcePolicyFolderFailedReason, vmopv1.ConditionSeverityError, "%v", folderErr)
+		} else {
+			conditions.MarkTrue(zone, vmopv1.ResourcePolicyFolderReadyCondition)
+		}
+
+		if err := k8serrors.NewAggregate(rpErrs); err != nil {
+			conditions.MarkFalse(zone, vmopv1.ResourcePolicyResourcePoolReadyCondition,
+				vmopv1.ResourcePolicyResourcePoolFailedReason, vmopv1.ConditionSeverityError, "%v", err)
+		} else {
+			conditions.MarkTrue(zone, vmopv1.ResourcePolicyResourcePoolReadyCondition)
+		}
+
+		if err := k8serrors.NewAggregate(moduleErrs); err != nil {
+			conditions.MarkFalse(zone, vmopv1.ResourcePolicyClusterModulesReadyCondition,
+				vmopv1.ResourcePolicyClusterModulesFailedReason, vmopv1.ConditionSeverityError, "%v", err)
+		} else {
+			conditions.MarkTrue(zone, vmopv1.ResourcePolicyClusterModulesReadyCondition)
+		}
+
+		conditions.SetSummary(zone)
+
+		zoneStatuses = append(zoneStatuses, zoneStatus)
+		errs = append(errs, rpErrs...)
+		if clientErr == nil {
+			errs = append(errs, moduleErrs...)
+		}
+	}
+
+	resourcePolicy.Status.Zones = zoneStatuses
+
+	zones := make([]conditions.Getter, 0, len(zoneStatuses))
+	for i := range zoneStatuses {
+		zones = append(zones, &zoneConditions{VirtualMachineSetResourcePolicy: resourcePolicy, zone: &zoneStatuses[i]})
 	}
+	conditions.SetAggregate(resourcePolicy, vmopv1.ReadyCondition, zones)
 
 	return k8serrors.NewAggregate(errs)
 }
 
+// zoneConditions gets and sets the conditions of a zone in the Status.Zones of a
+// VirtualMachineSetResourcePolicy, so the conditions package can be used for them.
+type zoneConditions struct {
+	*vmopv1.VirtualMachineSetResourcePolicy
+	zone *vmopv1.ResourcePolicyZoneStatus
+}
+
+func (z *zoneConditions) GetConditions() vmopv1.Conditions {
+	return z.zone.Conditions
+}
+
+func (z *zoneConditions) SetConditions(conditions vmopv1.Conditions) {
+	z.zone.Conditions = conditions
+}
+
 // DeleteVirtualMachineSetResourcePolicy deletes the VirtualMachineSetPolicy.
 func (vs *vSphereVMProvider) DeleteVirtualMachineSetResourcePolicy(
 	ctx context.Context,
 	resourcePolicy *vmopv1.VirtualMachineSetResourcePolicy) error {
 
-	folderMoID, rpMoIDs, err := vs.getNamespaceFolderAndRPMoIDs(ctx, resourcePolicy.Namespace)
+	availabilityZones, err := topology.GetAvailabilityZones(ctx, vs.k8sClient)
 	if err != nil {
 		return err
 	}
 
-	client, err := vs.getVcClient(ctx)
+	zoneVCenterRefs, err := vs.getZoneVCenterRefs(ctx)
 	if err != nil {
 		return err
 	}
 
-	vimClient := client.VimClient()
 	var errs []error
 
-	for _, rpMoID := range rpMoIDs {
-		err := vcenter.DeleteChildResourcePool(ctx, vimClient, rpMoID, resourcePolicy.Spec.ResourcePool.Name)
+	// The Folder is VC-scoped, so the namespace has a Folder on each vCenter of its zones.
+	folderMoIDs := map[topology.VCenterRef]string{}
+	// The cluster modules are deleted with the client of the vCenter of their cluster.
+	clusterModProviders := map[string]clustermodules.Provider{}
+
+	for _, az := range availabilityZones {
+		nsInfo, ok := az.Spec.Namespaces[resourcePolicy.Namespace]
+		if !ok {
+			continue
+		}
+
+		vcRef := zoneVCenterRefs[az.Name]
+		if folderMoIDs[vcRef] == "" {
+			folderMoIDs[vcRef] = nsInfo.FolderMoId
+		}
+
+		client, err := vs.getVcClientForRef(ctx, vcRef)
 		if err != nil {
 			errs = append(errs, err)
+			continue
+		}
+		vimClient := client.VimClient()
+
+		rpMoIDs := nsInfo.PoolMoIDs
+		if len(rpMoIDs) == 0 {
+			rpMoIDs = []string{nsInfo.PoolMoId}
+		}
+
+		for _, rpMoID := range rpMoIDs {
+			err := vcenter.DeleteChildResourcePool(ctx, vimClient, rpMoID, resourcePolicy.Spec.ResourcePool.Name)
+			if err != nil {
+				errs = append(errs, err)
+			}
+
+			clusterRef, err := vcenter.GetResourcePoolOwnerMoRef(ctx, vimClient, rpMoID)
+			if err == nil {
+				clusterModProviders[clusterRef.Value] = client.ClusterModuleClient()
+				cluster := object.NewClusterComputeResource(vimClient, clusterRef.Reference())
+				err = vcenter.UpdateClusterVMRules(ctx, cluster, clustermodules.RuleNamePrefix(resourcePolicy), nil)
+			}
+			if err != nil {
+				errs = append(errs, err)
+			}
 		}
 	}
 
-	errs = append(errs, vs.deleteClusterModules(ctx, client.ClusterModuleClient(), resourcePolicy)...)
+	if len(folderMoIDs) == 0 {
+		return fmt.Errorf("namespace %s not present in any AvailabilityZones", resourcePolicy.Namespace)
+	}
 
-	if err := vcenter.DeleteChildFolder(ctx, vimClient, folderMoID, resourcePolicy.Spec.Folder.Name); err != nil {
-		errs = append(errs, err)
+	errs = append(errs, vs.deleteClusterModules(ctx, clusterModProviders, resourcePolicy)...)
+
+	for vcRef, folderMoID := range folderMoIDs {
+		client, err := vs.getVcClientForRef(ctx, vcRef)
+		if err == nil {
+			err = vcenter.DeleteChildFolder(ctx, client.VimClient(), folderMoID, resourcePolicy.Spec.Folder.Name)
+		}
+		if err != nil {
+			errs = append(errs, err)
+		}
 	}
 
 	return k8serrors.NewAggregate(errs)
@@ -145,6 +328,10 @@ func (vs *vSphereVMProvider) doClusterModulesExist(
 	resourcePolicy *vmopv1.VirtualMachineSetResourcePolicy) (bool, error) {
 
 	for _, moduleSpec := range resourcePolicy.Spec.ClusterModules {
+		if !clustermodules.IsVCClusterModule(moduleSpec) {
+			continue
+		}
+
 		_, moduleID := clustermodules.FindClusterModuleUUID(moduleSpec.GroupName, clusterRef, resourcePolicy)
 		if moduleID == "" {
 			return false, nil
@@ -173,6 +360,10 @@ func (vs *vSphereVMProvider) createClusterModules(
 	// resort to using the status as the source of truth. This can result in orphaned
 	// modules if, for instance, we fail to update the resource policy k8s object.
 	for _, moduleSpec := range resourcePolicy.Spec.ClusterModules {
+		if !clustermodules.IsVCClusterModule(moduleSpec) {
+			continue
+		}
+
 		idx, moduleID := clustermodules.FindClusterModuleUUID(moduleSpec.GroupName, clusterRef, resourcePolicy)
 
 		if moduleID != "" {
@@ -223,16 +414,129 @@ func (vs *vSphereVMProvider) createClusterModules(
 	return k8serrors.NewAggregate(errs)
 }
 
+// updateClusterModuleRules updates the DRS VM-VM rules that realize the ClusterModule Groups of the
+// VirtualMachineSetResourcePolicy that are not VC cluster modules, and the members of all of its
+// groups in the cluster. The members of a group are the VMs of the resource policy in the cluster
+// that have the group in their ClusterModuleNameKey annotation.
+func (vs *vSphereVMProvider) updateClusterModuleRules(
+	ctx context.Context,
+	vimClient *vim25.Client,
+	clusterRef vimtypes.ManagedObjectReference,
+	resourcePolicy *vmopv1.VirtualMachineSetResourcePolicy) error {
+
+	if len(resourcePolicy.Spec.ClusterModules) == 0 {
+		return nil
+	}
+
+	vmList := &vmopv1.VirtualMachineList{}
+	if err := vs.k8sClient.List(ctx, vmList, ctrlclient.InNamespace(resourcePolicy.Namespace)); err != nil {
+		return err
+	}
+
+	cluster := object.NewClusterComputeResource(vimClient, clusterRef)
+	clusterVMs, err := vcenter.GetClusterVMs(ctx, cluster)
+	if err != nil {
+		return err
+	}
+
+	prefix := clustermodules.RuleNamePrefix(resourcePolicy)
+	var rules []vcenter.ClusterVMRule
+
+	for _, moduleSpec := range resourcePolicy.Spec.ClusterModules {
+		var members []string
+		var memberRefs []vimtypes.ManagedObjectReference
+
+		for i := range vmList.Items {
+			vm := &vmList.Items[i]
+			if vm.Spec.ResourcePolicyName != resourcePolicy.Name || vm.Status.UniqueID == "" ||
+				!clustermodules.IsMember(vm, moduleSpec.GroupName) {
+				continue
+			}
+
+			vmRef := vimtypes.ManagedObjectReference{Type: "VirtualMachine", Value: vm.Status.UniqueID}
+			if _, ok := clusterVMs[vmRef]; ok {
+				members = append(members, vm.Name)
+				memberRefs = append(memberRefs, vmRef)
+			}
+		}
+		sort.Strings(members)
+
+		status := vmopv1.ClusterModuleStatus{
+			GroupName:   moduleSpec.GroupName,
+			ClusterMoID: clusterRef.Value,
+		}
+
+		if !clustermodules.IsVCClusterModule(moduleSpec) {
+			rule := vcenter.ClusterVMRule{
+				Name:      prefix + moduleSpec.GroupName,
+				Affinity:  moduleSpec.Type == vmopv1.ClusterModuleTypeAffinity,
+				Preferred: moduleSpec.Enforcement != vmopv1.ClusterModuleEnforcementRequired,
+				VMs:       memberRefs,
+			}
+			rules = append(rules, rule)
+			status.RuleName = rule.Name
+		}
+
+		idx := findClusterModuleStatus(moduleSpec.GroupName, clusterRef, resourcePolicy)
+		if idx < 0 {
+			if status.RuleName == "" {
+				// The VC cluster module has not been created.
+				continue
+			}
+			resourcePolicy.Status.ClusterModules = append(resourcePolicy.Status.ClusterModules, status)
+			idx = len(resourcePolicy.Status.ClusterModules) - 1
+		}
+
+		resourcePolicy.Status.ClusterModules[idx].RuleName = status.RuleName
+		resourcePolicy.Status.ClusterModules[idx].Members = members
+	}
+
+	return vcenter.UpdateClusterVMRules(ctx, cluster, prefix, rules)
+}
+
+// findClusterModuleStatus returns the index in the Status.ClusterModules of the given groupName and
+// cluster reference, or -1.
+func findClusterModuleStatus(
+	groupName string,
+	clusterRef vimtypes.ManagedObjectReference,
+	resourcePolicy *vmopv1.VirtualMachineSetResourcePolicy) int {
+
+	for i, modStatus := range resourcePolicy.Status.ClusterModules {
+		if modStatus.GroupName == groupName && modStatus.ClusterMoID == clusterRef.Value {
+			return i
+		}
+	}
+	return -1
+}
+
 // deleteClusterModules deletes all the ClusterModules associated with a given VirtualMachineSetResourcePolicy in VC.
+// A ClusterModule is deleted with the provider of its cluster, or with the provider of the default vCenter when
+// the cluster is not known.
 func (vs *vSphereVMProvider) deleteClusterModules(
 	ctx context.Context,
-	clusterModProvider clustermodules.Provider,
+	clusterModProviders map[string]clustermodules.Provider,
 	resourcePolicy *vmopv1.VirtualMachineSetResourcePolicy) []error {
 
 	var errModStatus []vmopv1.ClusterModuleStatus
 	var errs []error
 
 	for _, moduleStatus := range resourcePolicy.Status.ClusterModules {
+		// The groups that are realized by DRS VM-VM rules do not have a module.
+		if moduleStatus.ModuleUuid == "" {
+			continue
+		}
+
+		clusterModProvider, ok := clusterModProviders[moduleStatus.ClusterMoID]
+		if !ok {
+			client, err := vs.getVcClient(ctx)
+			if err != nil {
+				errModStatus = append(errModStatus, moduleStatus)
+				errs = append(errs, err)
+				continue
+			}
+			clusterModProvider = client.ClusterModuleClient()
+		}
+
 		err := clusterModProvider.DeleteModule(ctx, moduleStatus.ModuleUuid)
 		if err != nil {
 			errModStatus = append(errModStatus, moduleStatus)
@@ -243,19 +547,3 @@ func (vs *vSphereVMProvider) deleteClusterModules(
 	resourcePolicy.Status.ClusterModules = errModStatus
 	return errs
 }
-
-func (vs *vSphereVMProvider) getNamespaceFolderAndRPMoIDs(
-	ctx context.Context,
-	namespace string) (string, []string, error) {
-
-	folderMoID, rpMoIDs, err := topology.GetNamespaceFolderAndRPMoIDs(ctx, vs.k8sClient, namespace)
-	if err != nil {
-		return "", nil, err
-	}
-
-	if folderMoID == "" {
-		return "", nil, fmt.Errorf("namespace %s not present in any AvailabilityZones", namespace)
-	}
-
-	return folderMoID, rpMoIDs, nil
-}
@@ -1,4 +1,4 @@
-// Copyright (c) 2021 VMware, Inc. All Rights Reserved.
+// Copyright (c) 2021-2023 VMware, Inc. All Rights Reserved.
 // SPDX-License-Identifier: Apache-2.0
 
 package vsphere_test
@@ -6,18 +6,27 @@ package vsphere_test
 import (
 	"fmt"
 	"path"
+	"strings"
 
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
 
 	"github.com/vmware/govmomi/object"
+	"github.com/vmware/govmomi/property"
+	"github.com/vmware/govmomi/vim25/mo"
+	vimtypes "github.com/vmware/govmomi/vim25/types"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	vmopv1 "github.com/vmware-tanzu/vm-operator/api/v1alpha1"
 
+	"github.com/vmware-tanzu/vm-operator/pkg"
+	"github.com/vmware-tanzu/vm-operator/pkg/conditions"
+	"github.com/vmware-tanzu/vm-operator/pkg/topology"
 	"github.com/vmware-tanzu/vm-operator/pkg/vmprovider"
 	"github.com/vmware-tanzu/vm-operator/pkg/vmprovider/providers/vsphere"
+	"github.com/vmware-tanzu/vm-operator/pkg/vmprovider/providers/vsphere/vcenter"
 	"github.com/vmware-tanzu/vm-operator/test/builder"
 )
 
@@ -112,6 +121,30 @@ func resourcePolicyTests() {
 					Expect(resourcePolicy.Status.ClusterModules).To(ContainElements(status.ClusterModules))
 				})
 
+				It("should correct the reservations and limits of the resource pool edited in vCenter", func() {
+					resourcePolicy.Spec.ResourcePool.Reservations.Memory = resource.MustParse("1Gi")
+					Expect(vmProvider.CreateOrUpdateVirtualMachineSetResourcePolicy(ctx, resourcePolicy)).To(Succeed())
+
+					nsRP := ctx.GetResourcePoolForNamespace(nsInfo.Namespace, "", "")
+					childRP, err := vcenter.GetChildResourcePool(ctx, nsRP, resourcePolicy.Spec.ResourcePool.Name)
+					Expect(err).ToNot(HaveOccurred())
+
+					getMemoryReservation := func() int64 {
+						var o mo.ResourcePool
+						Expect(childRP.Properties(ctx, childRP.Reference(), []string{"config"}, &o)).To(Succeed())
+						return *o.Config.MemoryAllocation.Reservation
+					}
+					Expect(getMemoryReservation()).To(BeEquivalentTo(1024))
+
+					editSpec := vimtypes.DefaultResourceConfigSpec()
+					Expect(childRP.UpdateConfig(ctx, "", &editSpec)).To(Succeed())
+					Expect(getMemoryReservation()).To(BeEquivalentTo(0))
+
+					Expect(vmProvider.CreateOrUpdateVirtualMachineSetResourcePolicy(ctx, resourcePolicy)).To(Succeed())
+					Expect(getMemoryReservation()).To(BeEquivalentTo(1024))
+					Expect(conditions.IsTrue(resourcePolicy, vmopv1.ReadyCondition)).To(BeTrue())
+				})
+
 				It("successfully able to find the resource policy", func() {
 					exists, err := vmProvider.IsVirtualMachineSetResourcePolicyReady(ctx, "", resourcePolicy)
 					Expect(err).NotTo(HaveOccurred())
@@ -139,6 +172,22 @@ func resourcePolicyTests() {
 				})
 			})
 
+			It("reports the resource policy is ready in the zone", func() {
+				Expect(conditions.IsTrue(resourcePolicy, vmopv1.ReadyCondition)).To(BeTrue())
+
+				Expect(resourcePolicy.Status.Zones).To(HaveLen(1))
+				zone := resourcePolicy.Status.Zones[0]
+				Expect(zone.Name).To(Equal(topology.DefaultAvailabilityZoneName))
+				for _, t := range []vmopv1.ConditionType{
+					vmopv1.ReadyCondition,
+					vmopv1.ResourcePolicyFolderReadyCondition,
+					vmopv1.ResourcePolicyResourcePoolReadyCondition,
+					vmopv1.ResourcePolicyClusterModulesReadyCondition,
+				} {
+					Expect(conditions.IsTrueFromConditions(zone.Conditions, t)).To(BeTrue(), string(t))
+				}
+			})
+
 			It("creates expected resource pool", func() {
 				rp, err := ctx.GetSingleClusterCompute().ResourcePool(ctx)
 				Expect(err).ToNot(HaveOccurred())
@@ -164,6 +213,17 @@ func resourcePolicyTests() {
 					testConfig.WithFaultDomains = true
 				})
 
+				It("reports the resource policy is ready in each zone", func() {
+					Expect(conditions.IsTrue(resourcePolicy, vmopv1.ReadyCondition)).To(BeTrue())
+
+					zoneNames := make([]string, 0, len(resourcePolicy.Status.Zones))
+					for _, zone := range resourcePolicy.Status.Zones {
+						zoneNames = append(zoneNames, zone.Name)
+						Expect(conditions.IsTrueFromConditions(zone.Conditions, vmopv1.ReadyCondition)).To(BeTrue())
+					}
+					Expect(zoneNames).To(ConsistOf(ctx.ZoneNames))
+				})
+
 				It("creates expected cluster modules for each cluster", func() {
 					moduleCount := len(resourcePolicy.Spec.ClusterModules)
 					Expect(moduleCount).To(Equal(2))
@@ -240,5 +300,116 @@ func resourcePolicyTests() {
 				})
 			})
 		})
+
+		Context("VirtualMachineSetResourcePolicy with DRS rule groups", func() {
+			const (
+				affinityGroup     = "affinity-group"
+				antiAffinityGroup = "anti-affinity-group"
+			)
+
+			var (
+				resourcePolicy *vmopv1.VirtualMachineSetResourcePolicy
+				cluster        *object.ClusterComputeResource
+				clusterVMs     []vimtypes.ManagedObjectReference
+			)
+
+			getRules := func() map[string]vimtypes.BaseClusterRuleInfo {
+				config, err := cluster.Configuration(ctx)
+				Expect(err).ToNot(HaveOccurred())
+
+				rules := map[string]vimtypes.BaseClusterRuleInfo{}
+				for _, rule := range config.Rule {
+					rules[rule.GetClusterRuleInfo().Name] = rule
+				}
+				return rules
+			}
+
+			JustBeforeEach(func() {
+				cluster = ctx.GetSingleClusterCompute()
+
+				var cr mo.ComputeResource
+				Expect(cluster.Properties(ctx, cluster.Reference(), []string{"host"}, &cr)).To(Succeed())
+				var hosts []mo.HostSystem
+				Expect(property.DefaultCollector(ctx.VCClient.Client).Retrieve(ctx, cr.Host, []string{"vm"}, &hosts)).To(Succeed())
+				clusterVMs = nil
+				for _, host := range hosts {
+					clusterVMs = append(clusterVMs, host.Vm...)
+				}
+				Expect(len(clusterVMs)).To(BeNumerically(">=", 2))
+
+				resourcePolicy = getVirtualMachineSetResourcePolicy("test-policy", nsInfo.Namespace)
+				resourcePolicy.Spec.ClusterModules = append(resourcePolicy.Spec.ClusterModules,
+					vmopv1.ClusterModuleSpec{
+						GroupName:   affinityGroup,
+						Type:        vmopv1.ClusterModuleTypeAffinity,
+						Enforcement: vmopv1.ClusterModuleEnforcementPreferred,
+					},
+					vmopv1.ClusterModuleSpec{
+						GroupName:   antiAffinityGroup,
+						Enforcement: vmopv1.ClusterModuleEnforcementRequired,
+					},
+				)
+
+				// The first two VMs of the cluster are members of several groups.
+				for i, vmRef := range clusterVMs[:2] {
+					vm := builder.DummyVirtualMachine()
+					vm.Name = fmt.Sprintf("vm-%d", i)
+					vm.Namespace = nsInfo.Namespace
+					vm.Spec.ResourcePolicyName = resourcePolicy.Name
+					vm.Annotations[pkg.ClusterModuleNameKey] = strings.Join(
+						[]string{"ControlPlane", affinityGroup, antiAffinityGroup}, ",")
+					vm.Status.UniqueID = vmRef.Value
+					Expect(ctx.Client.Create(ctx, vm)).To(Succeed())
+				}
+
+				Expect(vmProvider.CreateOrUpdateVirtualMachineSetResourcePolicy(ctx, resourcePolicy)).To(Succeed())
+			})
+
+			It("creates the DRS rules and reports the members of the groups", func() {
+				prefix := fmt.Sprintf("vmoperator:%s/resourcepolicy/%s:", nsInfo.Namespace, resourcePolicy.Name)
+
+				rules := getRules()
+				Expect(rules).To(HaveKey(prefix + affinityGroup))
+				affinityRule := rules[prefix+affinityGroup]
+				Expect(affinityRule).To(BeAssignableToTypeOf(&vimtypes.ClusterAffinityRuleSpec{}))
+				Expect(affinityRule.(*vimtypes.ClusterAffinityRuleSpec).Vm).To(ConsistOf(clusterVMs[:2]))
+				Expect(*affinityRule.GetClusterRuleInfo().Mandatory).To(BeFalse())
+
+				Expect(rules).To(HaveKey(prefix + antiAffinityGroup))
+				antiAffinityRule := rules[prefix+antiAffinityGroup]
+				Expect(antiAffinityRule).To(BeAssignableToTypeOf(&vimtypes.ClusterAntiAffinityRuleSpec{}))
+				Expect(*antiAffinityRule.GetClusterRuleInfo().Mandatory).To(BeTrue())
+
+				Expect(resourcePolicy.Status.ClusterModules).To(HaveLen(4))
+				for _, status := range resourcePolicy.Status.ClusterModules {
+					Expect(status.ClusterMoID).To(Equal(cluster.Reference().Value))
+
+					switch status.GroupName {
+					case "ControlPlane":
+						Expect(status.ModuleUuid).ToNot(BeEmpty())
+						Expect(status.RuleName).To(BeEmpty())
+						Expect(status.Members).To(Equal([]string{"vm-0", "vm-1"}))
+					case "NodeGroup1":
+						Expect(status.ModuleUuid).ToNot(BeEmpty())
+						Expect(status.Members).To(BeEmpty())
+					case affinityGroup, antiAffinityGroup:
+						Expect(status.ModuleUuid).To(BeEmpty())
+						Expect(status.RuleName).To(Equal(prefix + status.GroupName))
+						Expect(status.Members).To(Equal([]string{"vm-0", "vm-1"}))
+					}
+				}
+
+				exists, err := vmProvider.IsVirtualMachineSetResourcePolicyReady(ctx, "", resourcePolicy)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(exists).To(BeTrue())
+
+				By("removing the rules when the resource policy is deleted", func() {
+					Expect(vmProvider.DeleteVirtualMachineSetResourcePolicy(ctx, resourcePolicy)).To(Succeed())
+					Expect(resourcePolicy.Status.ClusterModules).To(BeEmpty())
+					Expect(getRules()).ToNot(HaveKey(prefix + affinityGroup))
+					Expect(getRules()).ToNot(HaveKey(prefix + antiAffinityGroup))
+				})
+			})
+		})
 	})
 }
@@ -0,0 +1,143 @@
+// Copyright (c) 2023 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package vsphere
+
+import (
+	goctx "context"
+	"strconv"
+	"time"
+
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	ctrlruntime "sigs.k8s.io/controller-runtime/pkg/client"
+
+	vmopv1 "github.com/vmware-tanzu/vm-operator/api/v1alpha1"
+	"github.com/vmware-tanzu/vm-operator/pkg/context"
+	"github.com/vmware-tanzu/vm-operator/pkg/lib"
+	"github.com/vmware-tanzu/vm-operator/pkg/vmprovider"
+	"github.com/vmware-tanzu/vm-operator/pkg/vmprovider/providers/vsphere/scheduler"
+)
+
+// operationQueuedRequeueDelay is how long a VM whose operation is queued waits before it is reconciled again.
+// This must be shorter than the scheduler's waiter TTL so the operation keeps its place in the queue.
+const operationQueuedRequeueDelay = 5 * time.Second
+
+func newSchedulers() map[scheduler.Operation]*scheduler.Scheduler {
+	schedulers := map[scheduler.Operation]*scheduler.Scheduler{}
+	for _, op := range []scheduler.Operation{
+		scheduler.OperationCreate,
+		scheduler.OperationReconfigure,
+		scheduler.OperationPower,
+		scheduler.OperationPublish,
+	} {
+		schedulers[op] = scheduler.New(op, scheduler.DefaultWaiterTTL)
+	}
+	return schedulers
+}
+
+// tryAcquireOperation starts the operation of the VM when its scheduler admits it, and returns an
+// OperationQueuedError otherwise. The returned func must be called once the operation completes.
+func (vs *vSphereVMProvider) tryAcquireOperation(
+	vmCtx context.VirtualMachineContext,
+	op scheduler.Operation,
+	capacity int) (func(), error) {
+
+	release, ok := vs.schedulers[op].TryAcquire(vs.operationRequest(vmCtx, vmCtx.VM, vmCtx.VM.NamespacedName()), capacity)
+	if !ok {
+		vmCtx.Logger.Info("Too many operations already occurring. Re-queueing request", "operation", op)
+		return nil, vmprovider.OperationQueuedError{Operation: string(op), RequeueAfter: operationQueuedRequeueDelay}
+	}
+
+	return release, nil
+}
+
+// vmOperationAcquirer returns the func the Session uses to start the reconfigure and power operations of the
+// VM. The operations are not limited when the maximum number of deploy threads is not in the context.
+func (vs *vSphereVMProvider) vmOperationAcquirer(
+	vmCtx context.VirtualMachineContext) func(scheduler.Operation) (func(), error) {
+
+	return func(op scheduler.Operation) (func(), error) {
+		maxDeployThreads, ok := vmCtx.Value(context.MaxDeployThreadsContextKey).(int)
+		if !ok {
+			return func() {}, nil
+		}
+		return vs.tryAcquireOperation(vmCtx, op, maxDeployThreads)
+	}
+}
+
+// acquirePublishOperation blocks until the publish of the VM is started. Publish requests are processed
+// asynchronously so, unlike the other operations, they wait for their turn.
+func (vs *vSphereVMProvider) acquirePublishOperation(
+	vmCtx context.VirtualMachineContext,
+	vmPub *vmopv1.VirtualMachinePublishRequest) (func(), error) {
+
+	req := vs.operationRequest(vmCtx, vmCtx.VM, vmPub.Namespace+"/"+vmPub.Name)
+	release, err := vs.schedulers[scheduler.OperationPublish].Acquire(vmCtx, req, lib.MaxConcurrentPublishesOnProvider())
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to wait for the publish operation to start")
+	}
+
+	return release, nil
+}
+
+// dequeueOperations removes the queued operations of the VM, and releases its running create operation.
+func (vs *vSphereVMProvider) dequeueOperations(vm *vmopv1.VirtualMachine) {
+	for _, s := range vs.schedulers {
+		s.Dequeue(vm.NamespacedName())
+	}
+	vs.releaseRunningCreate(vm)
+}
+
+// holdRunningCreate keeps the create operation of the VM running after the reconcile that started its task
+// returns, until releaseRunningCreate is called once the task completes.
+func (vs *vSphereVMProvider) holdRunningCreate(vm *vmopv1.VirtualMachine, release func()) {
+	vs.runningCreatesLock.Lock()
+	defer vs.runningCreatesLock.Unlock()
+
+	if vs.runningCreates == nil {
+		vs.runningCreates = map[string]func(){}
+	}
+	if oldRelease, ok := vs.runningCreates[vm.NamespacedName()]; ok {
+		oldRelease()
+	}
+	vs.runningCreates[vm.NamespacedName()] = release
+}
+
+// releaseRunningCreate releases the create operation of the VM held by holdRunningCreate, if any.
+func (vs *vSphereVMProvider) releaseRunningCreate(vm *vmopv1.VirtualMachine) {
+	vs.runningCreatesLock.Lock()
+	release, ok := vs.runningCreates[vm.NamespacedName()]
+	delete(vs.runningCreates, vm.NamespacedName())
+	vs.runningCreatesLock.Unlock()
+
+	if ok {
+		release()
+	}
+}
+
+func (vs *vSphereVMProvider) operationRequest(
+	ctx goctx.Context,
+	vm *vmopv1.VirtualMachine,
+	key string) scheduler.Request {
+
+	return scheduler.Request{
+		Key:           key,
+		Namespace:     vm.Namespace,
+		Shares:        vs.getNamespaceShares(ctx, vm.Namespace),
+		PriorityClass: vm.Annotations[vmopv1.PriorityClassAnnotation],
+	}
+}
+
+// getNamespaceShares returns the scheduling shares of the namespace, or 1 when they are not set.
+func (vs *vSphereVMProvider) getNamespaceShares(ctx goctx.Context, namespace string) int {
+	ns := &corev1.Namespace{}
+	if err := vs.k8sClient.Get(ctx, ctrlruntime.ObjectKey{Name: namespace}, ns); err != nil {
+		return 1
+	}
+
+	if shares, err := strconv.Atoi(ns.Annotations[vmopv1.SchedulingSharesAnnotation]); err == nil && shares > 0 {
+		return shares
+	}
+	return 1
+}
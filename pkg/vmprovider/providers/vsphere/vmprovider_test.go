@@ -1,15 +1,19 @@
-// Copyright (c) 2022 VMware, Inc. All Rights Reserved.
+// Copyright (c) 2022-2023 VMware, Inc. All Rights Reserved.
 // SPDX-License-Identifier: Apache-2.0
 
 package vsphere_test
 
 import (
+	"net"
+	"strconv"
 	"sync"
 	"time"
 
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
 
+	vmopv1 "github.com/vmware-tanzu/vm-operator/api/v1alpha1"
+	"github.com/vmware-tanzu/vm-operator/pkg/conditions"
 	"github.com/vmware-tanzu/vm-operator/pkg/util"
 	"github.com/vmware-tanzu/vm-operator/pkg/vmprovider"
 	"github.com/vmware-tanzu/vm-operator/pkg/vmprovider/providers/vsphere"
@@ -46,6 +50,69 @@ func cpuFreqTests() {
 	})
 }
 
+func vCenterReachableTests() {
+
+	var (
+		ctx        *builder.TestContextForVCSim
+		vmProvider vmprovider.VirtualMachineProviderInterface
+	)
+
+	BeforeEach(func() {
+		ctx = suite.NewTestContextForVCSim(builder.VCSimTestConfig{})
+		vmProvider = vsphere.NewVSphereVMProviderFromClient(ctx.Client, ctx.Recorder)
+	})
+
+	AfterEach(func() {
+		ctx.AfterEach()
+		ctx = nil
+		vmProvider = nil
+	})
+
+	It("does not have the condition before vCenter is called", func() {
+		Expect(vmProvider.GetConditions()).To(BeEmpty())
+	})
+
+	It("marks the condition true once vCenter is called", func() {
+		Expect(vmProvider.ComputeCPUMinFrequency(ctx)).To(Succeed())
+
+		c := conditions.TrueCondition(vmopv1.VCenterReachableCondition)
+		Expect(vmProvider.GetConditions()).To(ConsistOf(conditions.MatchCondition(*c)))
+	})
+
+	When("vCenter is unreachable", func() {
+
+		BeforeEach(func() {
+			Expect(vmProvider.ComputeCPUMinFrequency(ctx)).To(Succeed())
+
+			// Point the provider at a port that nothing listens on.
+			l, err := net.Listen("tcp", "127.0.0.1:0")
+			Expect(err).ToNot(HaveOccurred())
+			port := l.Addr().(*net.TCPAddr).Port
+			Expect(l.Close()).To(Succeed())
+			Expect(vmProvider.UpdateVcPNID(ctx, "127.0.0.1", strconv.Itoa(port))).To(Succeed())
+		})
+
+		It("marks the condition false and fails fast", func() {
+			for i := 0; i < 3; i++ {
+				err := vmProvider.ComputeCPUMinFrequency(ctx)
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).ToNot(ContainSubstring("is unreachable"))
+			}
+
+			providerConditions := vmProvider.GetConditions()
+			Expect(providerConditions).To(HaveLen(1))
+			Expect(providerConditions[0].Type).To(Equal(vmopv1.VCenterReachableCondition))
+			Expect(providerConditions[0].Status).To(BeEquivalentTo("False"))
+			Expect(providerConditions[0].Reason).To(Equal(vmopv1.VCenterUnreachableReason))
+			Expect(providerConditions[0].Message).To(ContainSubstring("connection refused"))
+
+			err := vmProvider.ComputeCPUMinFrequency(ctx)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("vCenter 127.0.0.1 is unreachable"))
+		})
+	})
+}
+
 func initOvfCacheAndLockPoolTests() {
 
 	var (
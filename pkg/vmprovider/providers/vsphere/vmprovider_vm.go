@@ -1,4 +1,4 @@
-// Copyright (c) 2022 VMware, Inc. All Rights Reserved.
+// Copyright (c) 2022-2023 VMware, Inc. All Rights Reserved.
 // SPDX-License-Identifier: Apache-2.0
 
 package vsphere
@@ -7,13 +7,13 @@ import (
 	goctx "context"
 	"fmt"
 	"strings"
-	"sync"
 	"text/template"
 
 	"github.com/pkg/errors"
 	"github.com/vmware/govmomi/object"
 	"github.com/vmware/govmomi/vim25/mo"
 	"github.com/vmware/govmomi/vim25/types"
+	"go.opentelemetry.io/otel/trace"
 
 	vmopv1 "github.com/vmware-tanzu/vm-operator/api/v1alpha1"
 
@@ -22,14 +22,19 @@ import (
 	"github.com/vmware-tanzu/vm-operator/pkg/conditions"
 	"github.com/vmware-tanzu/vm-operator/pkg/context"
 	"github.com/vmware-tanzu/vm-operator/pkg/lib"
+	"github.com/vmware-tanzu/vm-operator/pkg/metrics"
+	"github.com/vmware-tanzu/vm-operator/pkg/ociregistry"
 	"github.com/vmware-tanzu/vm-operator/pkg/topology"
+	"github.com/vmware-tanzu/vm-operator/pkg/tracing"
 	"github.com/vmware-tanzu/vm-operator/pkg/util"
+	"github.com/vmware-tanzu/vm-operator/pkg/vmprovider"
 	vcclient "github.com/vmware-tanzu/vm-operator/pkg/vmprovider/providers/vsphere/client"
 	"github.com/vmware-tanzu/vm-operator/pkg/vmprovider/providers/vsphere/constants"
 	"github.com/vmware-tanzu/vm-operator/pkg/vmprovider/providers/vsphere/contentlibrary"
 	"github.com/vmware-tanzu/vm-operator/pkg/vmprovider/providers/vsphere/instancestorage"
 	"github.com/vmware-tanzu/vm-operator/pkg/vmprovider/providers/vsphere/network"
 	"github.com/vmware-tanzu/vm-operator/pkg/vmprovider/providers/vsphere/placement"
+	"github.com/vmware-tanzu/vm-operator/pkg/vmprovider/providers/vsphere/scheduler"
 	"github.com/vmware-tanzu/vm-operator/pkg/vmprovider/providers/vsphere/session"
 	"github.com/vmware-tanzu/vm-operator/pkg/vmprovider/providers/vsphere/storage"
 	"github.com/vmware-tanzu/vm-operator/pkg/vmprovider/providers/vsphere/vcenter"
@@ -45,14 +50,9 @@ const (
 	FirstBootDoneAnnotation = "virtualmachine.vmoperator.vmware.com/first-boot-done"
 )
 
-var (
-	createCountLock       sync.Mutex
-	concurrentCreateCount int
-)
-
 func (vs *vSphereVMProvider) CreateOrUpdateVirtualMachine(
 	ctx goctx.Context,
-	vm *vmopv1.VirtualMachine) error {
+	vm *vmopv1.VirtualMachine) (err error) {
 
 	vmCtx := context.VirtualMachineContext{
 		Context: goctx.WithValue(ctx, types.ID{}, vs.getOpID(vm, "createOrUpdateVM")),
@@ -60,18 +60,32 @@ func (vs *vSphereVMProvider) CreateOrUpdateVirtualMachine(
 		VM:      vm,
 	}
 
-	client, err := vs.getVcClient(vmCtx)
+	vmCtx, span := startVMSpan(vmCtx, "CreateOrUpdateVirtualMachine")
+	defer func() {
+		tracing.EndSpanWithRequeue(span, err, vmprovider.RequeueAfter)
+	}()
+
+	client, vcRef, err := vs.getVcClientForVM(vmCtx)
 	if err != nil {
 		return err
 	}
 
-	vcVM, err := vs.getVM(vmCtx, client, false)
+	if err := virtualmachine.CheckLastTask(vmCtx, client.VimClient()); err != nil {
+		if !errors.As(err, &vmprovider.TaskInProgressError{}) {
+			vs.releaseRunningCreate(vm)
+		}
+		return err
+	}
+	// The clone or deploy task of the VM, if any, has completed.
+	vs.releaseRunningCreate(vm)
+
+	vcVM, err := vs.getVM(vmCtx, client, vcRef, false)
 	if err != nil {
 		return err
 	}
 
 	if vcVM == nil {
-		vcVM, err = vs.createVirtualMachine(vmCtx, client)
+		vcVM, err = vs.createVirtualMachine(vmCtx, client, vcRef)
 		if err != nil {
 			return err
 		}
@@ -88,7 +102,7 @@ func (vs *vSphereVMProvider) CreateOrUpdateVirtualMachine(
 
 func (vs *vSphereVMProvider) DeleteVirtualMachine(
 	ctx goctx.Context,
-	vm *vmopv1.VirtualMachine) error {
+	vm *vmopv1.VirtualMachine) (err error) {
 
 	vmCtx := context.VirtualMachineContext{
 		Context: goctx.WithValue(ctx, types.ID{}, vs.getOpID(vm, "deleteVM")),
@@ -96,12 +110,24 @@ func (vs *vSphereVMProvider) DeleteVirtualMachine(
 		VM:      vm,
 	}
 
-	client, err := vs.getVcClient(vmCtx)
+	vmCtx, span := startVMSpan(vmCtx, "DeleteVirtualMachine")
+	defer func() {
+		tracing.EndSpanWithRequeue(span, err, vmprovider.RequeueAfter)
+	}()
+
+	vs.dequeueOperations(vm)
+
+	client, vcRef, err := vs.getVcClientForVM(vmCtx)
 	if err != nil {
 		return err
 	}
 
-	vcVM, err := vs.getVM(vmCtx, client, false)
+	// Wait for the task that a previous reconcile started, such as the power off or destroy of the VM.
+	if err := virtualmachine.CheckLastTask(vmCtx, client.VimClient()); err != nil {
+		return err
+	}
+
+	vcVM, err := vs.getVM(vmCtx, client, vcRef, false)
 	if err != nil {
 		return err
 	} else if vcVM == nil {
@@ -109,9 +135,29 @@ func (vs *vSphereVMProvider) DeleteVirtualMachine(
 		return nil
 	}
 
+	// Remove the DRS rules and groups of the VM's affinity terms. A failure to remove them must not
+	// block the deletion of the VM.
+	if _, ok := vm.Annotations[session.AffinityRulesAnnotation]; ok {
+		if err := vs.removeAffinityRules(vmCtx, vcVM); err != nil {
+			vmCtx.Logger.Error(err, "Failed to remove the DRS rules of the VM's affinity terms")
+		}
+	}
+
 	return virtualmachine.DeleteVirtualMachine(vmCtx, vcVM)
 }
 
+func (vs *vSphereVMProvider) removeAffinityRules(
+	vmCtx context.VirtualMachineContext,
+	vcVM *object.VirtualMachine) error {
+
+	cluster, err := virtualmachine.GetVMClusterComputeResource(vmCtx, vcVM)
+	if err != nil {
+		return err
+	}
+
+	return vcenter.UpdateClusterVMHostRules(vmCtx, cluster, placement.AffinityRuleNamePrefix(vmCtx.VM), nil)
+}
+
 func (vs *vSphereVMProvider) PublishVirtualMachine(ctx goctx.Context, vm *vmopv1.VirtualMachine,
 	vmPub *vmopv1.VirtualMachinePublishRequest, cl *imgregv1a1.ContentLibrary, actID string) (string, error) {
 	vmCtx := context.VirtualMachineContext{
@@ -123,11 +169,24 @@ func (vs *vSphereVMProvider) PublishVirtualMachine(ctx goctx.Context, vm *vmopv1
 		VM: vm,
 	}
 
-	client, err := vs.getVcClient(ctx)
+	release, err := vs.acquirePublishOperation(vmCtx, vmPub)
+	if err != nil {
+		return "", err
+	}
+	defer release()
+
+	client, vcRef, err := vs.getVcClientForVM(vmCtx)
 	if err != nil {
 		return "", errors.Wrapf(err, "failed to get vCenter client")
 	}
 
+	// The target content library is on the default vCenter, and vCenter can only publish a VM to the
+	// libraries of its own vCenter.
+	if vcRef.VCenter != "" {
+		return "", errors.Errorf("VM %s is on vCenter %s and cannot be published to a content library of "+
+			"the default vCenter", vm.NamespacedName(), vcRef.VCenter)
+	}
+
 	itemID, err := virtualmachine.CreateOVF(vmCtx, client.RestClient(), vmPub, cl, actID)
 	if err != nil {
 		return "", err
@@ -135,6 +194,35 @@ func (vs *vSphereVMProvider) PublishVirtualMachine(ctx goctx.Context, vm *vmopv1
 	return itemID, nil
 }
 
+func (vs *vSphereVMProvider) PublishVirtualMachineToOCIRegistry(ctx goctx.Context, vm *vmopv1.VirtualMachine,
+	vmPub *vmopv1.VirtualMachinePublishRequest, registry *ociregistry.Client) (string, error) {
+	vmCtx := context.VirtualMachineContext{
+		Context: goctx.WithValue(ctx, types.ID{}, vs.getOpID(vm, "publishOCI")),
+		Logger: log.WithValues("vmName", vm.NamespacedName()).
+			WithValues("repository", registry.Repository().String()).
+			WithValues("vmPubName", fmt.Sprintf("%s/%s", vmPub.Namespace, vmPub.Name)),
+		VM: vm,
+	}
+
+	release, err := vs.acquirePublishOperation(vmCtx, vmPub)
+	if err != nil {
+		return "", err
+	}
+	defer release()
+
+	client, vcRef, err := vs.getVcClientForVM(vmCtx)
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to get vCenter client")
+	}
+
+	vcVM, err := vs.getVM(vmCtx, client, vcRef, true)
+	if err != nil {
+		return "", err
+	}
+
+	return virtualmachine.ExportToOCIRegistry(vmCtx, vcVM, registry, vmPub)
+}
+
 func (vs *vSphereVMProvider) GetVirtualMachineGuestHeartbeat(
 	ctx goctx.Context,
 	vm *vmopv1.VirtualMachine) (vmopv1.GuestHeartbeatStatus, error) {
@@ -145,12 +233,12 @@ func (vs *vSphereVMProvider) GetVirtualMachineGuestHeartbeat(
 		VM:      vm,
 	}
 
-	client, err := vs.getVcClient(vmCtx)
+	client, vcRef, err := vs.getVcClientForVM(vmCtx)
 	if err != nil {
 		return "", err
 	}
 
-	vcVM, err := vs.getVM(vmCtx, client, true)
+	vcVM, err := vs.getVM(vmCtx, client, vcRef, true)
 	if err != nil {
 		return "", err
 	}
@@ -174,12 +262,12 @@ func (vs *vSphereVMProvider) GetVirtualMachineWebMKSTicket(
 		VM:      vm,
 	}
 
-	client, err := vs.getVcClient(vmCtx)
+	client, vcRef, err := vs.getVcClientForVM(vmCtx)
 	if err != nil {
 		return "", err
 	}
 
-	vcVM, err := vs.getVM(vmCtx, client, true)
+	vcVM, err := vs.getVM(vmCtx, client, vcRef, true)
 	if err != nil {
 		return "", err
 	}
@@ -192,6 +280,55 @@ func (vs *vSphereVMProvider) GetVirtualMachineWebMKSTicket(
 	return ticket, nil
 }
 
+func (vs *vSphereVMProvider) EnsureVirtualMachineSerialConsole(
+	ctx goctx.Context,
+	vm *vmopv1.VirtualMachine) (string, error) {
+
+	vmCtx := context.VirtualMachineContext{
+		Context: goctx.WithValue(ctx, types.ID{}, vs.getOpID(vm, "serialconsole")),
+		Logger:  log.WithValues("vmName", vm.NamespacedName()),
+		VM:      vm,
+	}
+
+	client, vcRef, err := vs.getVcClientForVM(vmCtx)
+	if err != nil {
+		return "", err
+	}
+
+	vcVM, err := vs.getVM(vmCtx, client, vcRef, true)
+	if err != nil {
+		return "", err
+	}
+
+	return virtualmachine.EnsureSerialConsole(vmCtx, vcVM, lib.GetSerialConsoleVSPCURI())
+}
+
+func (vs *vSphereVMProvider) RemoveVirtualMachineSerialConsole(
+	ctx goctx.Context,
+	vm *vmopv1.VirtualMachine) error {
+
+	vmCtx := context.VirtualMachineContext{
+		Context: goctx.WithValue(ctx, types.ID{}, vs.getOpID(vm, "removeSerialConsole")),
+		Logger:  log.WithValues("vmName", vm.NamespacedName()),
+		VM:      vm,
+	}
+
+	client, vcRef, err := vs.getVcClientForVM(vmCtx)
+	if err != nil {
+		return err
+	}
+
+	vcVM, err := vs.getVM(vmCtx, client, vcRef, false)
+	if err != nil {
+		return err
+	} else if vcVM == nil {
+		// VM does not exist.
+		return nil
+	}
+
+	return virtualmachine.RemoveSerialConsole(vmCtx, vcVM, lib.GetSerialConsoleVSPCURI())
+}
+
 func (vs *vSphereVMProvider) GetVirtualMachineHardwareVersion(
 	ctx goctx.Context,
 	vm *vmopv1.VirtualMachine) (int32, error) {
@@ -202,12 +339,12 @@ func (vs *vSphereVMProvider) GetVirtualMachineHardwareVersion(
 		VM:      vm,
 	}
 
-	client, err := vs.getVcClient(vmCtx)
+	client, vcRef, err := vs.getVcClientForVM(vmCtx)
 	if err != nil {
 		return 0, err
 	}
 
-	vcVM, err := vs.getVM(vmCtx, client, true)
+	vcVM, err := vs.getVM(vmCtx, client, vcRef, true)
 	if err != nil {
 		return 0, err
 	}
@@ -221,11 +358,56 @@ func (vs *vSphereVMProvider) GetVirtualMachineHardwareVersion(
 	return contentlibrary.ParseVirtualHardwareVersion(o.Config.Version), nil
 }
 
+// MigrateVirtualMachine starts relocating the VM to the target zone, host and storage class, and
+// returns the ID of the relocation task.
+func (vs *vSphereVMProvider) MigrateVirtualMachine(
+	ctx goctx.Context,
+	vm *vmopv1.VirtualMachine,
+	target vmopv1.VirtualMachineMigrationRequestLocation) (string, error) {
+
+	vmCtx := context.VirtualMachineContext{
+		Context: goctx.WithValue(ctx, types.ID{}, vs.getOpID(vm, "migrateVM")),
+		Logger:  log.WithValues("vmName", vm.NamespacedName()),
+		VM:      vm,
+	}
+
+	client, vcRef, err := vs.getVcClientForVM(vmCtx)
+	if err != nil {
+		return "", err
+	}
+
+	vcVM, err := vs.getVM(vmCtx, client, vcRef, true)
+	if err != nil {
+		return "", err
+	}
+
+	relocateArgs, err := vs.vmMigrateGetArgs(vmCtx, client, vcRef, vcVM, target)
+	if err != nil {
+		return "", err
+	}
+
+	ses := &session.Session{
+		K8sClient: vs.k8sClient,
+		Client:    client,
+		Finder:    client.Finder(),
+	}
+
+	task, err := ses.RelocateVirtualMachine(vmCtx, vcVM, relocateArgs)
+	if err != nil {
+		return "", err
+	}
+
+	return task.Reference().Value, nil
+}
+
 func (vs *vSphereVMProvider) createVirtualMachine(
 	vmCtx context.VirtualMachineContext,
-	vcClient *vcclient.Client) (*object.VirtualMachine, error) {
+	vcClient *vcclient.Client,
+	vcRef topology.VCenterRef) (*object.VirtualMachine, error) {
 
-	createArgs, err := vs.vmCreateGetArgs(vmCtx, vcClient)
+	stepCtx, span := startVMSpan(vmCtx, "vmCreateGetArgs")
+	createArgs, err := vs.vmCreateGetArgs(stepCtx, vcClient)
+	tracing.EndSpanWithRequeue(span, err, vmprovider.RequeueAfter)
 	if err != nil {
 		return nil, err
 	}
@@ -234,17 +416,43 @@ func (vs *vSphereVMProvider) createVirtualMachine(
 	// are still several steps before then.
 	vmCtx.VM.Status.Phase = vmopv1.Creating
 
-	err = vs.vmCreateDoPlacement(vmCtx, vcClient, createArgs)
+	stepCtx, span = startVMSpan(vmCtx, "vmCreateDoPlacement")
+	placementVCRef, err := vs.vmCreateDoPlacement(stepCtx, vcClient, vcRef, createArgs)
+	tracing.EndSpanWithRequeue(span, err, vmprovider.RequeueAfter)
 	if err != nil {
 		return nil, err
 	}
 
-	err = vs.vmCreateGetFolderAndRPMoIDs(vmCtx, vcClient, createArgs)
+	if placementVCRef != vcRef {
+		// The zone selected by placement is managed by another vCenter, so create the VM there.
+		vcRef = placementVCRef
+		vcClient, err = vs.getVcClientForRef(vmCtx, vcRef)
+		if err != nil {
+			return nil, err
+		}
+
+		if err := vs.vmCreateGetStoragePrereqs(vmCtx, vcClient, createArgs); err != nil {
+			return nil, err
+		}
+	}
+
+	stepCtx, span = startVMSpan(vmCtx, "vmCreateGetFolderAndRPMoIDs")
+	err = vs.vmCreateGetFolderAndRPMoIDs(stepCtx, vcClient, createArgs)
+	tracing.EndSpanWithRequeue(span, err, vmprovider.RequeueAfter)
 	if err != nil {
 		return nil, err
 	}
 
-	err = vs.vmCreateIsReady(vmCtx, vcClient, createArgs)
+	stepCtx, span = startVMSpan(vmCtx, "vmCreateGetContentLibrary")
+	err = vs.vmCreateGetContentLibrary(stepCtx, vcClient, vcRef, createArgs)
+	tracing.EndSpanWithRequeue(span, err, vmprovider.RequeueAfter)
+	if err != nil {
+		return nil, err
+	}
+
+	stepCtx, span = startVMSpan(vmCtx, "vmCreateIsReady")
+	err = vs.vmCreateIsReady(stepCtx, vcClient, createArgs)
+	tracing.EndSpanWithRequeue(span, err, vmprovider.RequeueAfter)
 	if err != nil {
 		return nil, err
 	}
@@ -256,11 +464,16 @@ func (vs *vSphereVMProvider) createVirtualMachine(
 		return nil, fmt.Errorf("MaxDeployThreadsContextKey missing from context")
 	}
 
-	allowed, createDeferFn := vs.vmCreateConcurrentAllowed(vmCtx, maxDeployThreads)
-	if !allowed {
-		return nil, nil
+	release, err := vs.tryAcquireOperation(vmCtx, scheduler.OperationCreate, maxDeployThreads)
+	if err != nil {
+		return nil, err
 	}
-	defer createDeferFn()
+	holdRelease := false
+	defer func() {
+		if !holdRelease {
+			release()
+		}
+	}()
 
 	var vcVM *object.VirtualMachine
 	{
@@ -273,8 +486,18 @@ func (vs *vSphereVMProvider) createVirtualMachine(
 			Finder:    vcClient.Finder(),
 		}
 
-		vcVM, err = ses.CreateVirtualMachine(vmCtx, createArgs)
+		stepCtx, span = startVMSpan(vmCtx, "CreateVirtualMachine")
+		vcVM, err = ses.CreateVirtualMachine(stepCtx, createArgs)
+		tracing.EndSpanWithRequeue(span, err, vmprovider.RequeueAfter)
 		if err != nil {
+			if errors.As(err, &vmprovider.TaskInProgressError{}) {
+				// The VM is found by the following reconciles once the task completes. Until then, the
+				// task still counts against the concurrent creates.
+				vmCtx.VM.Status.ClassGeneration = createArgs.VMClass.Generation
+				vs.holdRunningCreate(vmCtx.VM, release)
+				holdRelease = true
+				return nil, err
+			}
 			vmCtx.Logger.Error(err, "CreateVirtualMachine failed")
 			return nil, err
 		}
@@ -283,6 +506,7 @@ func (vs *vSphereVMProvider) createVirtualMachine(
 		// updateVirtualMachine() next which will set it all.
 		vmCtx.VM.Status.Phase = vmopv1.Created
 		vmCtx.VM.Status.UniqueID = vcVM.Reference().Value
+		vmCtx.VM.Status.ClassGeneration = createArgs.VMClass.Generation
 	}
 
 	return vcVM, nil
@@ -303,18 +527,24 @@ func (vs *vSphereVMProvider) updateVirtualMachine(
 		}
 
 		ses := &session.Session{
-			K8sClient: vs.k8sClient,
-			Client:    vcClient,
-			Finder:    vcClient.Finder(),
-			Cluster:   cluster,
+			K8sClient:        vs.k8sClient,
+			Client:           vcClient,
+			Finder:           vcClient.Finder(),
+			Cluster:          cluster,
+			AcquireOperation: vs.vmOperationAcquirer(vmCtx),
 		}
 		ses.NetworkProvider = network.NewProvider(ses.K8sClient, ses.Client.VimClient(), ses.Finder, ses.Cluster)
 
 		getUpdateArgsFn := func() (*vmUpdateArgs, error) {
-			return vs.vmUpdateGetArgs(vmCtx)
+			stepCtx, span := startVMSpan(vmCtx, "vmUpdateGetArgs")
+			updateArgs, err := vs.vmUpdateGetArgs(stepCtx)
+			tracing.EndSpanWithRequeue(span, err, vmprovider.RequeueAfter)
+			return updateArgs, err
 		}
 
-		err = ses.UpdateVirtualMachine(vmCtx, vcVM, getUpdateArgsFn)
+		stepCtx, span := startVMSpan(vmCtx, "UpdateVirtualMachine")
+		err = ses.UpdateVirtualMachine(stepCtx, vcVM, getUpdateArgsFn)
+		tracing.EndSpanWithRequeue(span, err, vmprovider.RequeueAfter)
 		if err != nil {
 			return err
 		}
@@ -323,16 +553,159 @@ func (vs *vSphereVMProvider) updateVirtualMachine(
 	return nil
 }
 
-// vmCreateDoPlacement determines placement of the VM prior to creating the VM on VC.
+// vmMigrateGetArgs resolves the MoIDs of the ResourcePool, host and datastore the VM is relocated to. The
+// vcClient is connected to the vCenter and Datacenter of vcRef, and the VM cannot be relocated to a zone
+// of another vCenter or Datacenter.
+func (vs *vSphereVMProvider) vmMigrateGetArgs(
+	vmCtx context.VirtualMachineContext,
+	vcClient *vcclient.Client,
+	vcRef topology.VCenterRef,
+	vcVM *object.VirtualMachine,
+	target vmopv1.VirtualMachineMigrationRequestLocation) (*session.VMRelocateArgs, error) {
+
+	relocateArgs := &session.VMRelocateArgs{}
+
+	cluster, err := virtualmachine.GetVMClusterComputeResource(vmCtx, vcVM)
+	if err != nil {
+		return nil, err
+	}
+
+	if target.Zone != "" && target.Zone != vmCtx.VM.Labels[topology.KubernetesTopologyZoneLabelKey] {
+		zoneVCenterRefs, err := vs.getZoneVCenterRefs(vmCtx)
+		if err != nil {
+			return nil, err
+		}
+
+		targetVCRef, err := topology.LookupVCenterRefForZone(vmCtx, vs.k8sClient, zoneVCenterRefs, target.Zone)
+		if err != nil {
+			return nil, err
+		}
+		if targetVCRef != vcRef {
+			return nil, fmt.Errorf("cannot migrate VM to zone %s that is managed by another vCenter or Datacenter",
+				target.Zone)
+		}
+
+		_, rpMoID, err := topology.GetNamespaceFolderAndRPMoID(vmCtx, vs.k8sClient, target.Zone, vmCtx.VM.Namespace)
+		if err != nil {
+			return nil, err
+		}
+
+		// Keep the VM in its ResourcePolicy's child ResourcePool under the zone's ResourcePool.
+		if vmCtx.VM.Spec.ResourcePolicyName != "" {
+			resourcePolicy, err := GetVMSetResourcePolicy(vmCtx, vs.k8sClient)
+			if err != nil {
+				return nil, err
+			}
+
+			if childRPName := resourcePolicy.Spec.ResourcePool.Name; childRPName != "" {
+				parentRP := object.NewResourcePool(vcClient.VimClient(),
+					types.ManagedObjectReference{Type: "ResourcePool", Value: rpMoID})
+
+				childRP, err := vcenter.GetChildResourcePool(vmCtx, parentRP, childRPName)
+				if err != nil {
+					return nil, err
+				}
+
+				rpMoID = childRP.Reference().Value
+			}
+		}
+
+		ccrMoRef, err := vcenter.GetResourcePoolOwnerMoRef(vmCtx, vcClient.VimClient(), rpMoID)
+		if err != nil {
+			return nil, err
+		}
+
+		relocateArgs.ResourcePoolMoID = rpMoID
+		cluster = object.NewClusterComputeResource(vcClient.VimClient(), ccrMoRef)
+	}
+
+	var host *object.HostSystem
+	if target.Host != "" {
+		hosts, err := cluster.Hosts(vmCtx)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, h := range hosts {
+			name, err := h.ObjectName(vmCtx)
+			if err != nil {
+				return nil, err
+			}
+			if name == target.Host {
+				host = h
+				break
+			}
+		}
+
+		if host == nil {
+			return nil, fmt.Errorf("host %s is not in cluster %s", target.Host, cluster.Reference().Value)
+		}
+
+		relocateArgs.HostMoID = host.Reference().Value
+	}
+
+	if target.StorageClass != "" && target.StorageClass != vmCtx.VM.Spec.StorageClass {
+		storageProfileID, err := storage.GetStoragePolicyID(vmCtx, vs.k8sClient, target.StorageClass)
+		if err != nil {
+			return nil, err
+		}
+
+		// The datastore must be accessible from the host, or from the cluster when DRS selects the host.
+		var datastores []types.ManagedObjectReference
+		if host != nil {
+			var moHost mo.HostSystem
+			if err := host.Properties(vmCtx, host.Reference(), []string{"datastore"}, &moHost); err != nil {
+				return nil, err
+			}
+			datastores = moHost.Datastore
+		} else {
+			var moCluster mo.ClusterComputeResource
+			if err := cluster.Properties(vmCtx, cluster.Reference(), []string{"datastore"}, &moCluster); err != nil {
+				return nil, err
+			}
+			datastores = moCluster.Datastore
+		}
+
+		datastore, err := storage.SelectDatastoreForProfile(vmCtx, vcClient, storageProfileID, datastores)
+		if err != nil {
+			return nil, err
+		}
+
+		relocateArgs.DatastoreMoID = datastore.Value
+		relocateArgs.StorageProfileID = storageProfileID
+	}
+
+	return relocateArgs, nil
+}
+
+// vmCreateDoPlacement determines placement of the VM prior to creating the VM on VC. The vcClient is
+// connected to the vCenter and Datacenter of vcRef. The VCenterRef of the vCenter that manages the zone
+// selected by placement is returned.
 func (vs *vSphereVMProvider) vmCreateDoPlacement(
 	vmCtx context.VirtualMachineContext,
 	vcClient *vcclient.Client,
-	createArgs *vmCreateArgs) error {
+	vcRef topology.VCenterRef,
+	createArgs *vmCreateArgs) (topology.VCenterRef, error) {
 
-	result, err := placement.Placement(vmCtx, vs.k8sClient, vcClient.VimClient(),
-		createArgs.PlacementConfigSpec, createArgs.ChildResourcePoolName)
+	zoneClients, zoneVCenterRefs, multipleVCenters, err := vs.getPlacementZoneClients(vmCtx, vcClient, vcRef)
 	if err != nil {
-		return err
+		return topology.VCenterRef{}, err
+	}
+
+	result, err := placement.Placement(vmCtx, vs.k8sClient, zoneClients,
+		createArgs.PlacementConfigSpec, createArgs.ChildResourcePoolName,
+		vcClient.Config().PlacementScorerWeights)
+	if err != nil {
+		metrics.NewPlacementMetrics().CountDecision(vmCtx.VM.Labels[topology.KubernetesTopologyZoneLabelKey], err)
+		return topology.VCenterRef{}, err
+	}
+	metrics.NewPlacementMetrics().CountDecision(result.ZoneName, nil)
+
+	if result.Explanation != "" {
+		if vmCtx.VM.Annotations == nil {
+			vmCtx.VM.Annotations = map[string]string{}
+		}
+		vmCtx.VM.Annotations[constants.PlacementDecisionAnnotationKey] = result.Explanation
 	}
 
 	if result.PoolMoRef.Value != "" {
@@ -347,12 +720,17 @@ func (vs *vSphereVMProvider) vmCreateDoPlacement(
 		hostMoID := createArgs.HostMoID
 
 		if hostMoID == "" {
-			return fmt.Errorf("placement result missing host required for instance storage")
+			return topology.VCenterRef{}, fmt.Errorf("placement result missing host required for instance storage")
 		}
 
-		hostFQDN, err := vcenter.GetESXHostFQDN(vmCtx, vcClient.VimClient(), hostMoID)
+		hostClient := vcClient.VimClient()
+		if c, ok := zoneClients[result.ZoneName]; ok {
+			hostClient = c
+		}
+
+		hostFQDN, err := vcenter.GetESXHostFQDN(vmCtx, hostClient, hostMoID)
 		if err != nil {
-			return err
+			return topology.VCenterRef{}, err
 		}
 
 		if vmCtx.VM.Annotations == nil {
@@ -362,14 +740,25 @@ func (vs *vSphereVMProvider) vmCreateDoPlacement(
 		vmCtx.VM.Annotations[constants.InstanceStorageSelectedNodeAnnotationKey] = hostFQDN
 	}
 
+	placementVCRef := vcRef
+
 	if result.ZonePlacement {
+		placementVCRef = zoneVCenterRefs[result.ZoneName]
+
+		if multipleVCenters || placementVCRef != vcRef {
+			// Until the zone label is saved, the VM is looked up on the vCenter of this annotation.
+			if err := vs.savePlacementVCenter(vmCtx, placementVCRef); err != nil {
+				return topology.VCenterRef{}, err
+			}
+		}
+
 		if vmCtx.VM.Labels == nil {
 			vmCtx.VM.Labels = map[string]string{}
 		}
 		vmCtx.VM.Labels[topology.KubernetesTopologyZoneLabelKey] = result.ZoneName
 	}
 
-	return nil
+	return placementVCRef, nil
 }
 
 // vmCreateGetFolderAndRPMoIDs gets the MoIDs of the Folder and Resource Pool the VM will be created under.
@@ -405,8 +794,10 @@ func (vs *vSphereVMProvider) vmCreateGetFolderAndRPMoIDs(
 		createArgs.FolderMoID = nsFolderMoID
 
 	} else {
-		// Placement already selected the ResourcePool/Cluster, so we just need this namespace's folder.
-		nsFolderMoID, err := topology.GetNamespaceFolderMoID(vmCtx, vs.k8sClient, vmCtx.VM.Namespace)
+		// Placement already selected the ResourcePool/Cluster, so we just need this namespace's folder
+		// on the vCenter of the zone.
+		nsFolderMoID, _, err := topology.GetNamespaceFolderAndRPMoID(vmCtx, vs.k8sClient,
+			vmCtx.VM.Labels[topology.KubernetesTopologyZoneLabelKey], vmCtx.VM.Namespace)
 		if err != nil {
 			return err
 		}
@@ -431,6 +822,78 @@ func (vs *vSphereVMProvider) vmCreateGetFolderAndRPMoIDs(
 	return nil
 }
 
+// vmCreateGetContentLibrary sets the content library the VM is deployed from. The images are in the content
+// libraries of the default vCenter, so a VM placed on another vCenter is deployed from the library on that
+// vCenter that subscribes to the image's library.
+func (vs *vSphereVMProvider) vmCreateGetContentLibrary(
+	vmCtx context.VirtualMachineContext,
+	vcClient *vcclient.Client,
+	vcRef topology.VCenterRef,
+	createArgs *vmCreateArgs) error {
+
+	if vcRef.VCenter == "" || createArgs.ContentLibraryUUID == "" {
+		return nil
+	}
+
+	defaultClient, err := vs.getVcClient(vmCtx)
+	if err != nil {
+		return err
+	}
+
+	publishURL, err := defaultClient.ContentLibClient().GetLibraryPublishURL(vmCtx, createArgs.ContentLibraryUUID)
+	if err != nil {
+		return err
+	}
+
+	datastoreMoID := createArgs.DatastoreMoID
+	if datastoreMoID == "" {
+		datastoreMoID, err = getResourcePoolDatastoreMoID(vmCtx, vcClient, createArgs.ResourcePoolMoID)
+		if err != nil {
+			return err
+		}
+	}
+
+	libraryUUID, err := vcClient.ContentLibClient().GetOrCreateSubscribedLibrary(vmCtx,
+		createArgs.ContentLibraryUUID, publishURL, datastoreMoID)
+	if err != nil {
+		return err
+	}
+
+	vmCtx.Logger.V(4).Info("Deploying from subscribed content library",
+		"vCenter", vcRef.VCenter, "libraryUUID", libraryUUID, "sourceLibraryUUID", createArgs.ContentLibraryUUID)
+	createArgs.ContentLibraryUUID = libraryUUID
+
+	return nil
+}
+
+// getResourcePoolDatastoreMoID returns a datastore of the cluster of the ResourcePool.
+func getResourcePoolDatastoreMoID(
+	vmCtx context.VirtualMachineContext,
+	vcClient *vcclient.Client,
+	rpMoID string) (string, error) {
+
+	rp := object.NewResourcePool(vcClient.VimClient(), types.ManagedObjectReference{Type: "ResourcePool", Value: rpMoID})
+	owner, err := rp.Owner(vmCtx)
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to get the cluster of ResourcePool %s", rpMoID)
+	}
+
+	cluster, ok := owner.(*object.ClusterComputeResource)
+	if !ok {
+		return "", errors.Errorf("owner of ResourcePool %s is not a cluster but %T", rpMoID, owner)
+	}
+
+	datastores, err := cluster.Datastores(vmCtx)
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to get the datastores of cluster %s", cluster.Reference().Value)
+	}
+	if len(datastores) == 0 {
+		return "", errors.Errorf("cluster %s has no datastores", cluster.Reference().Value)
+	}
+
+	return datastores[0].Reference().Value, nil
+}
+
 func (vs *vSphereVMProvider) vmCreateIsReady(
 	vmCtx context.VirtualMachineContext,
 	vcClient *vcclient.Client,
@@ -464,29 +927,6 @@ func (vs *vSphereVMProvider) vmCreateIsReady(
 	return nil
 }
 
-func (vs *vSphereVMProvider) vmCreateConcurrentAllowed(
-	vmCtx context.VirtualMachineContext,
-	maxDeployThreads int) (bool, func()) {
-
-	createCountLock.Lock()
-	if concurrentCreateCount >= maxDeployThreads {
-		createCountLock.Unlock()
-		vmCtx.Logger.Info("Too many create VirtualMachine already occurring. Re-queueing request")
-		return false, nil
-	}
-
-	concurrentCreateCount++
-	createCountLock.Unlock()
-
-	decrementFn := func() {
-		createCountLock.Lock()
-		concurrentCreateCount--
-		createCountLock.Unlock()
-	}
-
-	return true, decrementFn
-}
-
 func (vs *vSphereVMProvider) vmCreateGetArgs(
 	vmCtx context.VirtualMachineContext,
 	vcClient *vcclient.Client) (*vmCreateArgs, error) {
@@ -682,6 +1122,18 @@ func (vs *vSphereVMProvider) vmCreateValidateArgs(
 	return nil
 }
 
+// isClassUpdatePending returns true if the VM was built from an older generation of its
+// VM Class and the class update policy allows the change to be applied to existing VMs.
+func isClassUpdatePending(vm *vmopv1.VirtualMachine, vmClass *vmopv1.VirtualMachineClass) bool {
+	switch vmClass.Spec.UpdatePolicy.Strategy {
+	case vmopv1.VirtualMachineClassUpdateStrategyOnNextPowerCycle,
+		vmopv1.VirtualMachineClassUpdateStrategyRollingRestart:
+		return vm.Status.ClassGeneration != vmClass.Generation
+	default:
+		return false
+	}
+}
+
 func (vs *vSphereVMProvider) vmUpdateGetArgs(
 	vmCtx context.VirtualMachineContext) (*vmUpdateArgs, error) {
 
@@ -704,6 +1156,11 @@ func (vs *vSphereVMProvider) vmUpdateGetArgs(
 	updateArgs.VMClass = vmClass
 	updateArgs.ResourcePolicy = resourcePolicy
 	updateArgs.VMMetadata = vmMD
+	if vmCtx.VM.Status.ClassGeneration == 0 {
+		// VMs created before the class generation was recorded are assumed to be up to date.
+		vmCtx.VM.Status.ClassGeneration = vmClass.Generation
+	}
+	updateArgs.ApplyClassHardware = !lib.IsVMClassAsConfigFSSDaynDateEnabled() || isClassUpdatePending(vmCtx.VM, vmClass)
 
 	// We're always ready - again - at this point since we've fetched the above objects. We really should
 	// not be touching this condition after creation but that is for another day.
@@ -781,3 +1238,11 @@ func isVMFirstBoot(vmCtx context.VirtualMachineContext) bool {
 
 	return true
 }
+
+// startVMSpan starts the span of an operation, or a step of an operation, on the VM. The returned vmCtx
+// carries the span so the spans of the vCenter calls of the step are its children.
+func startVMSpan(vmCtx context.VirtualMachineContext, name string) (context.VirtualMachineContext, trace.Span) {
+	ctx, span := tracing.StartSpan(vmCtx, name, tracing.VMKey.String(vmCtx.VM.NamespacedName()))
+	vmCtx.Context = ctx
+	return vmCtx, span
+}
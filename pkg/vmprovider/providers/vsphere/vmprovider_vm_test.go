@@ -10,13 +10,18 @@ import (
 	"fmt"
 	"math/rand"
 	"os"
+	"strings"
+	"time"
 
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
 	. "github.com/onsi/gomega/gstruct"
 
+	"github.com/pkg/errors"
 	"github.com/vmware/govmomi/object"
+	"github.com/vmware/govmomi/simulator"
 	"github.com/vmware/govmomi/vapi/cluster"
+	"github.com/vmware/govmomi/vapi/library"
 	gdj "github.com/vmware/govmomi/vim25/json"
 	"github.com/vmware/govmomi/vim25/mo"
 	"github.com/vmware/govmomi/vim25/types"
@@ -34,8 +39,12 @@ import (
 	"github.com/vmware-tanzu/vm-operator/pkg/topology"
 	"github.com/vmware-tanzu/vm-operator/pkg/vmprovider"
 	"github.com/vmware-tanzu/vm-operator/pkg/vmprovider/providers/vsphere"
+	"github.com/vmware-tanzu/vm-operator/pkg/vmprovider/providers/vsphere/clustermodules"
+	vcconfig "github.com/vmware-tanzu/vm-operator/pkg/vmprovider/providers/vsphere/config"
 	"github.com/vmware-tanzu/vm-operator/pkg/vmprovider/providers/vsphere/constants"
+	"github.com/vmware-tanzu/vm-operator/pkg/vmprovider/providers/vsphere/contentlibrary"
 	"github.com/vmware-tanzu/vm-operator/pkg/vmprovider/providers/vsphere/instancestorage"
+	"github.com/vmware-tanzu/vm-operator/pkg/vmprovider/providers/vsphere/session"
 	"github.com/vmware-tanzu/vm-operator/pkg/vmprovider/providers/vsphere/virtualmachine"
 	"github.com/vmware-tanzu/vm-operator/test/builder"
 )
@@ -247,6 +256,120 @@ func vmTests() {
 				})
 			})
 
+			Context("VM Class hardware is updated after the VM is created", func() {
+				var (
+					updatedClass *vmopv1.VirtualMachineClass
+				)
+
+				BeforeEach(func() {
+					// The fake client does not set the generation like the API server.
+					vmClass.Generation = 1
+				})
+
+				JustBeforeEach(func() {
+					updatedClass = &vmopv1.VirtualMachineClass{}
+					Expect(ctx.Client.Get(ctx, client.ObjectKey{Name: vm.Spec.ClassName}, updatedClass)).To(Succeed())
+					Expect(vm.Status.ClassGeneration).To(Equal(updatedClass.Generation))
+
+					updatedClass.Spec.Hardware.Cpus = 4
+					updatedClass.Generation++
+					Expect(ctx.Client.Update(ctx, updatedClass)).To(Succeed())
+				})
+
+				AfterEach(func() {
+					updatedClass = nil
+				})
+
+				getNumCPU := func() int32 {
+					var o mo.VirtualMachine
+					ExpectWithOffset(1, vcVM.Properties(ctx, vcVM.Reference(), nil, &o)).To(Succeed())
+					return o.Summary.Config.NumCpu
+				}
+
+				powerCycle := func() {
+					vm.Spec.PowerState = vmopv1.VirtualMachinePoweredOff
+					_, err := createOrUpdateAndGetVcVM(ctx, vm)
+					ExpectWithOffset(1, err).ToNot(HaveOccurred())
+					vm.Spec.PowerState = vmopv1.VirtualMachinePoweredOn
+					_, err = createOrUpdateAndGetVcVM(ctx, vm)
+					ExpectWithOffset(1, err).ToNot(HaveOccurred())
+				}
+
+				Context("Class update strategy is not set", func() {
+					It("VM hardware is not updated when the VM is power cycled", func() {
+						powerCycle()
+						Expect(getNumCPU()).To(BeEquivalentTo(2))
+						Expect(vm.Status.ClassGeneration).ToNot(Equal(updatedClass.Generation))
+					})
+				})
+
+				Context("Class update strategy is OnNextPowerCycle", func() {
+					JustBeforeEach(func() {
+						updatedClass.Spec.UpdatePolicy.Strategy = vmopv1.VirtualMachineClassUpdateStrategyOnNextPowerCycle
+						Expect(ctx.Client.Update(ctx, updatedClass)).To(Succeed())
+					})
+
+					It("VM hardware is not updated while the VM is powered on", func() {
+						_, err := createOrUpdateAndGetVcVM(ctx, vm)
+						Expect(err).ToNot(HaveOccurred())
+						Expect(getNumCPU()).To(BeEquivalentTo(2))
+					})
+
+					It("VM hardware is updated when the VM is power cycled", func() {
+						powerCycle()
+						Expect(getNumCPU()).To(BeEquivalentTo(4))
+						Expect(vm.Status.ClassGeneration).To(Equal(updatedClass.Generation))
+						Expect(conditions.IsTrue(vm, vmopv1.VirtualMachineClassUpToDateCondition)).To(BeTrue())
+					})
+				})
+
+				Context("Class update strategy is RollingRestart", func() {
+					JustBeforeEach(func() {
+						updatedClass.Spec.UpdatePolicy.Strategy = vmopv1.VirtualMachineClassUpdateStrategyRollingRestart
+						Expect(ctx.Client.Update(ctx, updatedClass)).To(Succeed())
+					})
+
+					It("VM is restarted with the updated hardware when it is annotated to be restarted", func() {
+						vm.Annotations[vmopv1.ClassUpdateRestartAnnotation] = ""
+						_, err := createOrUpdateAndGetVcVM(ctx, vm)
+						Expect(err).ToNot(HaveOccurred())
+
+						Expect(vm.Status.PowerState).To(Equal(vmopv1.VirtualMachinePoweredOn))
+						Expect(getNumCPU()).To(BeEquivalentTo(4))
+						Expect(vm.Status.ClassGeneration).To(Equal(updatedClass.Generation))
+						Expect(vm.Annotations).ToNot(HaveKey(vmopv1.ClassUpdateRestartAnnotation))
+						Expect(conditions.IsTrue(vm, vmopv1.VirtualMachineClassUpToDateCondition)).To(BeTrue())
+					})
+
+					It("VM is not powered off and is requeued while its guest OS is shutting down", func() {
+						requested := time.Now().UTC().Format(time.RFC3339)
+						vm.Annotations[vmopv1.ClassUpdateRestartAnnotation] = requested
+						err := vmProvider.CreateOrUpdateVirtualMachine(ctx, vm)
+						Expect(err).To(MatchError(vmprovider.GuestShutdownInProgressError{
+							RequeueAfter: session.ClassUpdateGuestShutdownRequeueDelay,
+						}))
+						requeueAfter, ok := vmprovider.RequeueAfter(err)
+						Expect(ok).To(BeTrue())
+						Expect(requeueAfter).To(Equal(session.ClassUpdateGuestShutdownRequeueDelay))
+
+						Expect(vm.Status.PowerState).To(Equal(vmopv1.VirtualMachinePoweredOn))
+						Expect(getNumCPU()).To(BeEquivalentTo(2))
+						Expect(vm.Annotations).To(HaveKeyWithValue(vmopv1.ClassUpdateRestartAnnotation, requested))
+					})
+
+					It("VM is powered off when its guest OS does not shut down in time", func() {
+						requested := time.Now().Add(-2 * session.ClassUpdateGuestShutdownTimeout)
+						vm.Annotations[vmopv1.ClassUpdateRestartAnnotation] = requested.UTC().Format(time.RFC3339)
+						_, err := createOrUpdateAndGetVcVM(ctx, vm)
+						Expect(err).ToNot(HaveOccurred())
+
+						Expect(vm.Status.PowerState).To(Equal(vmopv1.VirtualMachinePoweredOn))
+						Expect(getNumCPU()).To(BeEquivalentTo(4))
+						Expect(vm.Annotations).ToNot(HaveKey(vmopv1.ClassUpdateRestartAnnotation))
+					})
+				})
+			})
+
 			Context("VM Class spec CPU reservation & limits are non-zero and ConfigSpec specifies CPU reservation", func() {
 				BeforeEach(func() {
 					vmClass.Spec.Policies.Resources.Requests.Cpu = resource.MustParse("2")
@@ -457,6 +580,8 @@ func vmTests() {
 
 			Context("VM Class Spec and ConfigSpec both contain GPU and DirectPath devices", func() {
 				BeforeEach(func() {
+					testConfig.WithHostVGPUProfiles = []string{"profile-from-configspec"}
+					testConfig.WithHostPCIPassthroughDevices = []builder.HostPCIPassthroughDevice{{VendorID: 52, DeviceID: 53}}
 					vmClass.Spec.Hardware.Devices = vmopv1.VirtualDevices{
 						VGPUDevices: []vmopv1.VGPUDevice{
 							{
@@ -531,6 +656,9 @@ func vmTests() {
 
 			Context("VM Class Config specifies an ethCard, a GPU and a DDPIO device", func() {
 				BeforeEach(func() {
+					testConfig.WithHostVGPUProfiles = []string{"SampleProfile2"}
+					testConfig.WithHostPCIPassthroughDevices = []builder.HostPCIPassthroughDevice{{VendorID: 52, DeviceID: 53}}
+
 					// Create the ConfigSpec with an ethernet card, a GPU and a DDPIO device.
 					configSpec = &types.VirtualMachineConfigSpec{
 						Name: "dummy-VM",
@@ -821,6 +949,8 @@ func vmTests() {
 
 			Context("VM Class with PCI passthrough devices", func() {
 				BeforeEach(func() {
+					testConfig.WithHostVGPUProfiles = []string{"profile-from-class-without-class-as-config-fss"}
+					testConfig.WithHostPCIPassthroughDevices = []builder.HostPCIPassthroughDevice{{VendorID: 59, DeviceID: 60}}
 					vmClass.Spec.Hardware.Devices = vmopv1.VirtualDevices{
 						VGPUDevices: []vmopv1.VGPUDevice{
 							{
@@ -1027,6 +1157,41 @@ func vmTests() {
 
 					// TODO: More assertions!
 				})
+
+				Context("when the clone task does not complete within the wait timeout", func() {
+					var waitTimeout time.Duration
+
+					BeforeEach(func() {
+						waitTimeout = virtualmachine.TaskWaitTimeout
+						virtualmachine.TaskWaitTimeout = 100 * time.Millisecond
+						simulator.TaskDelay.Delay = 1000
+					})
+
+					AfterEach(func() {
+						virtualmachine.TaskWaitTimeout = waitTimeout
+						simulator.TaskDelay.Delay = 0
+					})
+
+					It("counts the clone task against the concurrent creates until it completes", func() {
+						ctx.Context = goctx.WithValue(ctx.Context, context.MaxDeployThreadsContextKey, 1)
+
+						vm2 := vm.DeepCopy()
+						vm2.Name += "-2"
+
+						err := vmProvider.CreateOrUpdateVirtualMachine(ctx, vm)
+						Expect(errors.As(err, &vmprovider.TaskInProgressError{})).To(BeTrue())
+
+						err = vmProvider.CreateOrUpdateVirtualMachine(ctx, vm2)
+						Expect(errors.As(err, &vmprovider.OperationQueuedError{})).To(BeTrue())
+
+						simulator.TaskDelay.Delay = 0
+						Eventually(func() error {
+							return vmProvider.CreateOrUpdateVirtualMachine(ctx, vm)
+						}, 10*time.Second, 100*time.Millisecond).Should(Succeed())
+
+						Expect(vmProvider.CreateOrUpdateVirtualMachine(ctx, vm2)).To(Succeed())
+					})
+				})
 			})
 
 			It("Create VM from VMTX in ContentLibrary", func() {
@@ -1052,6 +1217,8 @@ func vmTests() {
 					azName, ok := vm.Labels[topology.KubernetesTopologyZoneLabelKey]
 					Expect(ok).To(BeTrue())
 					Expect(azName).To(BeElementOf(ctx.ZoneNames))
+					Expect(vm.Annotations).To(HaveKeyWithValue(constants.PlacementDecisionAnnotationKey,
+						HavePrefix(fmt.Sprintf("zone=%q", azName))))
 
 					By("VM is created in the zone's ResourcePool", func() {
 						rp, err := vcVM.ResourcePool(ctx)
@@ -1077,6 +1244,109 @@ func vmTests() {
 						Expect(rp.Reference().Value).To(Equal(nsRP.Reference().Value))
 					})
 				})
+
+				// moveZonesToOtherVCenter makes the zones managed by another vCenter, which is the same vcsim
+				// instance under another name unless it has no provider ConfigMap.
+				moveZonesToOtherVCenter := func(vcName string, withConfig bool, azNames ...string) {
+					cm := &corev1.ConfigMap{}
+					Expect(ctx.Client.Get(ctx, client.ObjectKey{
+						Namespace: ctx.PodNamespace,
+						Name:      vcconfig.ProviderConfigMapNameForVCenter(""),
+					}, cm)).To(Succeed())
+
+					if withConfig {
+						vcCM := &corev1.ConfigMap{
+							ObjectMeta: metav1.ObjectMeta{
+								Namespace: ctx.PodNamespace,
+								Name:      vcconfig.ProviderConfigMapNameForVCenter(vcName),
+							},
+							Data: cm.Data,
+						}
+						Expect(ctx.Client.Create(ctx, vcCM)).To(Succeed())
+					}
+
+					pairs := make([]string, 0, len(azNames))
+					for _, azName := range azNames {
+						pairs = append(pairs, azName+"="+vcName)
+					}
+					cm.Data[vcconfig.ZoneVCentersKey] = strings.Join(pairs, ",")
+					Expect(ctx.Client.Update(ctx, cm)).To(Succeed())
+				}
+
+				It("saves the vCenter of the zone selected by placement before creating the VM", func() {
+					moveZonesToOtherVCenter("vc-2", true, ctx.ZoneNames[0])
+					Expect(ctx.Client.Create(ctx, vm)).To(Succeed())
+
+					_, err := createOrUpdateAndGetVcVM(ctx, vm)
+					Expect(err).ToNot(HaveOccurred())
+
+					zoneName := vm.Labels[topology.KubernetesTopologyZoneLabelKey]
+					Expect(ctx.ZoneNames).To(ContainElement(zoneName))
+					placementVCenter := "/"
+					if zoneName == ctx.ZoneNames[0] {
+						placementVCenter = "vc-2/"
+					}
+
+					savedVM := &vmopv1.VirtualMachine{}
+					Expect(ctx.Client.Get(ctx, client.ObjectKeyFromObject(vm), savedVM)).To(Succeed())
+					Expect(savedVM.Annotations).To(HaveKeyWithValue(constants.PlacementVCenterAnnotationKey, placementVCenter))
+				})
+
+				It("places VM on another vCenter when all the zones are managed by it", func() {
+					moveZonesToOtherVCenter("vc-2", true, ctx.ZoneNames...)
+					Expect(ctx.Client.Create(ctx, vm)).To(Succeed())
+
+					_, err := createOrUpdateAndGetVcVM(ctx, vm)
+					Expect(err).ToNot(HaveOccurred())
+					Expect(ctx.ZoneNames).To(ContainElement(vm.Labels[topology.KubernetesTopologyZoneLabelKey]))
+				})
+
+				It("does not save the vCenter of the zone selected by placement when there is one vCenter", func() {
+					Expect(ctx.Client.Create(ctx, vm)).To(Succeed())
+
+					_, err := createOrUpdateAndGetVcVM(ctx, vm)
+					Expect(err).ToNot(HaveOccurred())
+
+					savedVM := &vmopv1.VirtualMachine{}
+					Expect(ctx.Client.Get(ctx, client.ObjectKeyFromObject(vm), savedVM)).To(Succeed())
+					Expect(savedVM.Annotations).ToNot(HaveKey(constants.PlacementVCenterAnnotationKey))
+				})
+
+				It("places VM in the zones of the other vCenters when a vCenter cannot be connected to", func() {
+					// The minimum CPU frequency of the zones' clusters is computed before the vCenter is lost.
+					Expect(vmProvider.ComputeCPUMinFrequency(ctx)).To(Succeed())
+					moveZonesToOtherVCenter("vc-does-not-exist", false, ctx.ZoneNames[0])
+					Expect(ctx.Client.Create(ctx, vm)).To(Succeed())
+
+					_, err := createOrUpdateAndGetVcVM(ctx, vm)
+					Expect(err).ToNot(HaveOccurred())
+					Expect(vm.Labels).To(HaveKey(topology.KubernetesTopologyZoneLabelKey))
+					Expect(vm.Labels[topology.KubernetesTopologyZoneLabelKey]).ToNot(Equal(ctx.ZoneNames[0]))
+				})
+
+				It("creates VM from a subscribed content library when the assigned zone is on another vCenter", func() {
+					vm.Labels[topology.KubernetesTopologyZoneLabelKey] = ctx.ZoneNames[0]
+					moveZonesToOtherVCenter("vc-2", true, ctx.ZoneNames[0])
+
+					_, err := createOrUpdateAndGetVcVM(ctx, vm)
+					Expect(err).ToNot(HaveOccurred())
+
+					libIDs, err := library.NewManager(ctx.RestClient).FindLibrary(ctx, library.Find{
+						Name: contentlibrary.SubscribedLibraryNamePrefix + ctx.ContentLibraryID,
+						Type: "SUBSCRIBED",
+					})
+					Expect(err).ToNot(HaveOccurred())
+					Expect(libIDs).To(HaveLen(1))
+				})
+
+				It("returns error when the assigned zone's vCenter has no provider config", func() {
+					vm.Labels[topology.KubernetesTopologyZoneLabelKey] = ctx.ZoneNames[0]
+					moveZonesToOtherVCenter("vc-does-not-exist", false, ctx.ZoneNames[0])
+
+					err := vmProvider.CreateOrUpdateVirtualMachine(ctx, vm)
+					Expect(err).To(HaveOccurred())
+					Expect(err.Error()).To(ContainSubstring("vc-does-not-exist.vsphere.provider.config.vmoperator.vmware.com"))
+				})
 			})
 
 			Context("When Instance Storage FSS is enabled", func() {
@@ -1205,6 +1475,48 @@ func vmTests() {
 				Expect(o.Config.Modified).To(Equal(modified))
 			})
 
+			Context("Affinity rules", func() {
+				It("does not annotate a VM without terms required during execution", func() {
+					_, err := createOrUpdateAndGetVcVM(ctx, vm)
+					Expect(err).ToNot(HaveOccurred())
+					Expect(vm.Annotations).ToNot(HaveKey(session.AffinityRulesAnnotation))
+				})
+
+				When("the VM has a term required during execution", func() {
+					BeforeEach(func() {
+						vm.Spec.Affinity = &vmopv1.VirtualMachineAffinitySpec{
+							VMAntiAffinity: &vmopv1.VirtualMachineAffinityRules{
+								RequiredDuringSchedulingRequiredDuringExecution: []vmopv1.VirtualMachineAffinityTerm{
+									{
+										LabelSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "db"}},
+										TopologyKey:   vmopv1.VirtualMachineAffinityHostTopologyKey,
+									},
+								},
+							},
+						}
+					})
+
+					It("annotates the VM, and removes the annotation when the term is removed", func() {
+						_, err := createOrUpdateAndGetVcVM(ctx, vm)
+						Expect(err).ToNot(HaveOccurred())
+						Expect(vm.Annotations).To(HaveKey(session.AffinityRulesAnnotation))
+
+						vm.Spec.Affinity = nil
+						Expect(vmProvider.CreateOrUpdateVirtualMachine(ctx, vm)).To(Succeed())
+						Expect(vm.Annotations).ToNot(HaveKey(session.AffinityRulesAnnotation))
+					})
+
+					It("deletes the VM", func() {
+						_, err := createOrUpdateAndGetVcVM(ctx, vm)
+						Expect(err).ToNot(HaveOccurred())
+						uniqueID := vm.Status.UniqueID
+
+						Expect(vmProvider.DeleteVirtualMachine(ctx, vm)).To(Succeed())
+						Expect(ctx.GetVMFromMoID(uniqueID)).To(BeNil())
+					})
+				})
+			})
+
 			Context("VM Metadata", func() {
 
 				Context("ExtraConfig Transport", func() {
@@ -1564,6 +1876,50 @@ func vmTests() {
 				err := vmProvider.CreateOrUpdateVirtualMachine(ctx, vm)
 				Expect(err).To(MatchError("ClusterModule bogusClusterMod not found"))
 			})
+
+			When("the VM is in a group that is realized by a DRS rule", func() {
+				const groupName = "db"
+
+				JustBeforeEach(func() {
+					resourcePolicy.Spec.ClusterModules = append(resourcePolicy.Spec.ClusterModules, vmopv1.ClusterModuleSpec{
+						GroupName:   groupName,
+						Type:        vmopv1.ClusterModuleTypeAntiAffinity,
+						Enforcement: vmopv1.ClusterModuleEnforcementRequired,
+					})
+					Expect(ctx.Client.Update(ctx, resourcePolicy)).To(Succeed())
+
+					vm.Annotations["vsphere-cluster-module-group"] = groupName
+				})
+
+				It("adds the VM to the DRS rule before it is powered on", func() {
+					peer := vm.DeepCopy()
+					peer.Name = vm.Name + "-peer"
+					Expect(vmProvider.CreateOrUpdateVirtualMachine(ctx, peer)).To(Succeed())
+					Expect(ctx.Client.Create(ctx, peer)).To(Succeed())
+
+					vcVM, err := createOrUpdateAndGetVcVM(ctx, vm)
+					Expect(err).ToNot(HaveOccurred())
+					Expect(vm.Status.PowerState).To(Equal(vmopv1.VirtualMachinePoweredOn))
+
+					rp, err := vcVM.ResourcePool(ctx)
+					Expect(err).ToNot(HaveOccurred())
+					owner, err := rp.Owner(ctx)
+					Expect(err).ToNot(HaveOccurred())
+					config, err := object.NewClusterComputeResource(ctx.VCClient.Client, owner.Reference()).Configuration(ctx)
+					Expect(err).ToNot(HaveOccurred())
+
+					var rule *types.ClusterAntiAffinityRuleSpec
+					for _, r := range config.Rule {
+						if r.GetClusterRuleInfo().Name == clustermodules.RuleNamePrefix(resourcePolicy)+groupName {
+							rule, _ = r.(*types.ClusterAntiAffinityRuleSpec)
+						}
+					}
+					Expect(rule).ToNot(BeNil())
+					Expect(*rule.Mandatory).To(BeTrue())
+					Expect(rule.Vm).To(ConsistOf(vcVM.Reference(),
+						types.ManagedObjectReference{Type: "VirtualMachine", Value: peer.Status.UniqueID}))
+				})
+			})
 		})
 
 		Context("Delete VM", func() {
@@ -1663,6 +2019,56 @@ func vmTests() {
 			})
 		})
 
+		Context("Migrate VM", func() {
+			var vcVM *object.VirtualMachine
+
+			JustBeforeEach(func() {
+				var err error
+				vcVM, err = createOrUpdateAndGetVcVM(ctx, vm)
+				Expect(err).ToNot(HaveOccurred())
+			})
+
+			It("migrates the VM to another host", func() {
+				var o mo.VirtualMachine
+				Expect(vcVM.Properties(ctx, vcVM.Reference(), []string{"runtime.host"}, &o)).To(Succeed())
+
+				var srcHost mo.HostSystem
+				Expect(vcVM.Properties(ctx, *o.Runtime.Host, []string{"parent"}, &srcHost)).To(Succeed())
+				var cluster mo.ClusterComputeResource
+				Expect(vcVM.Properties(ctx, *srcHost.Parent, []string{"host"}, &cluster)).To(Succeed())
+
+				var dstHost mo.HostSystem
+				for _, h := range cluster.Host {
+					if h != *o.Runtime.Host {
+						Expect(vcVM.Properties(ctx, h, []string{"name"}, &dstHost)).To(Succeed())
+						break
+					}
+				}
+				Expect(dstHost.Name).ToNot(BeEmpty())
+
+				target := vmopv1.VirtualMachineMigrationRequestLocation{Host: dstHost.Name}
+				taskID, err := vmProvider.MigrateVirtualMachine(ctx, vm, target)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(taskID).ToNot(BeEmpty())
+
+				Eventually(func() types.TaskInfoState {
+					taskInfo, err := vmProvider.GetTaskInfo(ctx, vm, taskID)
+					Expect(err).ToNot(HaveOccurred())
+					return taskInfo.State
+				}).Should(Equal(types.TaskInfoStateSuccess))
+
+				Expect(vcVM.Properties(ctx, vcVM.Reference(), []string{"runtime.host"}, &o)).To(Succeed())
+				Expect(*o.Runtime.Host).To(Equal(dstHost.Reference()))
+			})
+
+			It("returns error when the host is not in the cluster", func() {
+				target := vmopv1.VirtualMachineMigrationRequestLocation{Host: "does-not-exist"}
+				_, err := vmProvider.MigrateVirtualMachine(ctx, vm, target)
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring("host does-not-exist is not in cluster"))
+			})
+		})
+
 		Context("VM hardware version", func() {
 			JustBeforeEach(func() {
 				Expect(vmProvider.CreateOrUpdateVirtualMachine(ctx, vm)).To(Succeed())
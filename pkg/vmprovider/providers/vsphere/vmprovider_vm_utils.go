@@ -372,6 +372,10 @@ func resolveVMImageStatus(
 	if !conditions.IsTrueFromConditions(imageStatus.Conditions, vmopv1.VirtualMachineImageSyncedCondition) {
 		imageNotReadyMsgs = append(imageNotReadyMsgs, fmt.Sprintf("VM's image content version is not synced: %s", imageName))
 	}
+	if lib.IsImageTrustPolicyEnabled() &&
+		!conditions.IsTrueFromConditions(imageStatus.Conditions, vmopv1.VirtualMachineImageTrustVerifiedCondition) {
+		imageNotReadyMsgs = append(imageNotReadyMsgs, fmt.Sprintf("VM's image is not verified by the image trust policy: %s", imageName))
+	}
 
 	if len(imageNotReadyMsgs) > 0 {
 		imageNotReadyMsg := strings.Join(imageNotReadyMsgs, "; ")
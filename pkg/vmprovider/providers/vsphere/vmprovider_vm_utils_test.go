@@ -480,6 +480,46 @@ func vmUtilTests() {
 				})
 			})
 
+			When("VM image exists but is not verified by the image trust policy", func() {
+				var oldIsImageTrustPolicyEnabledFunc func() bool
+
+				BeforeEach(func() {
+					oldIsImageTrustPolicyEnabledFunc = lib.IsImageTrustPolicyEnabled
+					lib.IsImageTrustPolicyEnabled = func() bool {
+						return true
+					}
+
+					conditions.MarkFalse(nsVMImage, vmopv1.VirtualMachineImageTrustVerifiedCondition,
+						vmopv1.VirtualMachineImageNotTrustedReason, vmopv1.ConditionSeverityError, "")
+					// Set other conditions true to verify the specific message in the MatchConditions function.
+					conditions.MarkTrue(nsVMImage, vmopv1.VirtualMachineImageProviderReadyCondition)
+					conditions.MarkTrue(nsVMImage, vmopv1.VirtualMachineImageProviderSecurityComplianceCondition)
+					conditions.MarkTrue(nsVMImage, vmopv1.VirtualMachineImageSyncedCondition)
+					initObjects = append(initObjects, cl, nsVMImage)
+					vmCtx.VM.Spec.ImageName = nsVMImage.Name
+				})
+
+				AfterEach(func() {
+					lib.IsImageTrustPolicyEnabled = oldIsImageTrustPolicyEnabledFunc
+				})
+
+				It("returns error and sets VM condition", func() {
+					_, _, err := vsphere.GetVMImageStatusAndContentLibraryUUID(vmCtx, k8sClient)
+					Expect(err).To(HaveOccurred())
+					expectedErrMsg := fmt.Sprintf("VM's image is not verified by the image trust policy: %s", vmCtx.VM.Spec.ImageName)
+					Expect(err.Error()).To(ContainSubstring(expectedErrMsg))
+
+					expectedCondition := vmopv1.Conditions{
+						*conditions.FalseCondition(
+							vmopv1.VirtualMachinePrereqReadyCondition,
+							vmopv1.VirtualMachineImageNotReadyReason,
+							vmopv1.ConditionSeverityError,
+							expectedErrMsg),
+					}
+					Expect(vmCtx.VM.Status.Conditions).To(conditions.MatchConditions(expectedCondition))
+				})
+			})
+
 			When("Namespace scoped VirtualMachineImage exists and ready", func() {
 				BeforeEach(func() {
 					conditions.MarkTrue(nsVMImage, vmopv1.VirtualMachineImageProviderReadyCondition)
@@ -0,0 +1,239 @@
+// Copyright (c) 2023 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+// Package vmquota computes the resources that VirtualMachines consume, as
+// derived from their VirtualMachineClasses, for the enforcement of
+// VirtualMachineQuotas.
+package vmquota
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	ctrlclient "sigs.k8s.io/controller-runtime/pkg/client"
+
+	vmopv1 "github.com/vmware-tanzu/vm-operator/api/v1alpha1"
+	"github.com/vmware-tanzu/vm-operator/pkg/lib"
+	"github.com/vmware-tanzu/vm-operator/pkg/util"
+)
+
+// ClassUsage returns the resources that a VirtualMachine of the
+// VirtualMachineClass consumes. A nil class, for example because the class
+// does not exist, only consumes the VirtualMachine itself.
+func ClassUsage(vmClass *vmopv1.VirtualMachineClass) corev1.ResourceList {
+	usage := corev1.ResourceList{
+		vmopv1.VirtualMachineQuotaResourceVirtualMachines: *resource.NewQuantity(1, resource.DecimalSI),
+	}
+	if vmClass == nil {
+		return usage
+	}
+
+	hw := vmClass.Spec.Hardware
+
+	instanceStorage := resource.NewQuantity(0, resource.BinarySI)
+	for _, volume := range hw.InstanceStorage.Volumes {
+		instanceStorage.Add(volume.Size)
+	}
+
+	// The CPU and memory of the class's ConfigSpec must be zero or equal to
+	// those of the class's hardware, but the vGPU devices of the ConfigSpec
+	// are added in addition to the ones of the class's hardware.
+	vGPUs := int64(len(hw.Devices.VGPUDevices))
+	if len(vmClass.Spec.ConfigSpec) > 0 {
+		// A ConfigSpec that cannot be unmarshaled is rejected by the class's
+		// validation webhook, so only classes created before may have one.
+		if configSpec, err := util.UnmarshalConfigSpecFromJSON(vmClass.Spec.ConfigSpec); err == nil {
+			for _, dev := range util.DevicesFromConfigSpec(configSpec) {
+				if util.IsDeviceVGPU(dev) {
+					vGPUs++
+				}
+			}
+		}
+	}
+
+	usage[vmopv1.VirtualMachineQuotaResourceCPU] = *resource.NewQuantity(hw.Cpus, resource.DecimalSI)
+	usage[vmopv1.VirtualMachineQuotaResourceMemory] = hw.Memory.DeepCopy()
+	usage[vmopv1.VirtualMachineQuotaResourceVGPUs] = *resource.NewQuantity(vGPUs, resource.DecimalSI)
+	usage[vmopv1.VirtualMachineQuotaResourceInstanceStorage] = *instanceStorage
+
+	return usage
+}
+
+// VirtualMachineUsage returns the resources that the VirtualMachine consumes.
+func VirtualMachineUsage(
+	ctx context.Context,
+	client ctrlclient.Client,
+	vm *vmopv1.VirtualMachine) (corev1.ResourceList, error) {
+
+	vmClass, err := getVirtualMachineClass(ctx, client, vm.Namespace, vm.Spec.ClassName)
+	if err != nil {
+		return nil, err
+	}
+
+	return ClassUsage(vmClass), nil
+}
+
+// NamespaceUsage returns the resources that the VirtualMachines of the
+// namespace consume together. The VirtualMachine with the name skipVMName,
+// if any, is not included.
+//
+// The VirtualMachines are listed from the client's cache, so the usage does
+// not include VirtualMachines that are being created concurrently, and the
+// enforcement of the quotas is best-effort.
+func NamespaceUsage(
+	ctx context.Context,
+	client ctrlclient.Client,
+	namespace, skipVMName string) (corev1.ResourceList, error) {
+
+	return namespaceUsage(ctx, client, namespace, skipVMName, map[string]corev1.ResourceList{})
+}
+
+// NamespaceUsageWithClass returns the resources that the VirtualMachines of
+// the namespace would consume together if their VirtualMachineClass with the
+// name of vmClass was vmClass, for example when the class is updated.
+func NamespaceUsageWithClass(
+	ctx context.Context,
+	client ctrlclient.Client,
+	namespace string,
+	vmClass *vmopv1.VirtualMachineClass) (corev1.ResourceList, error) {
+
+	return namespaceUsage(ctx, client, namespace, "", map[string]corev1.ResourceList{
+		vmClass.Name: ClassUsage(vmClass),
+	})
+}
+
+// namespaceUsage returns the resources that the VirtualMachines of the
+// namespace consume together. The usages of the classes are cached in
+// classUsages by name since many VMs share the same few classes.
+func namespaceUsage(
+	ctx context.Context,
+	client ctrlclient.Client,
+	namespace, skipVMName string,
+	classUsages map[string]corev1.ResourceList) (corev1.ResourceList, error) {
+
+	vmList := &vmopv1.VirtualMachineList{}
+	if err := client.List(ctx, vmList, ctrlclient.InNamespace(namespace)); err != nil {
+		return nil, err
+	}
+
+	used := corev1.ResourceList{}
+
+	for _, vm := range vmList.Items {
+		if vm.Name == skipVMName {
+			continue
+		}
+
+		usage, ok := classUsages[vm.Spec.ClassName]
+		if !ok {
+			vmClass, err := getVirtualMachineClass(ctx, client, namespace, vm.Spec.ClassName)
+			if err != nil {
+				return nil, err
+			}
+
+			usage = ClassUsage(vmClass)
+			classUsages[vm.Spec.ClassName] = usage
+		}
+
+		used = Add(used, usage)
+	}
+
+	return used, nil
+}
+
+// Increased returns the sorted names of the resources whose quantity in b is
+// larger than in a.
+func Increased(a, b corev1.ResourceList) []corev1.ResourceName {
+	var names []corev1.ResourceName
+	for name, quantity := range b {
+		if q := a[name]; quantity.Cmp(q) > 0 {
+			names = append(names, name)
+		}
+	}
+	sort.Slice(names, func(i, j int) bool { return names[i] < names[j] })
+	return names
+}
+
+// Add returns the sum of the resources of a and b.
+func Add(a, b corev1.ResourceList) corev1.ResourceList {
+	sum := corev1.ResourceList{}
+	for name, quantity := range a {
+		sum[name] = quantity.DeepCopy()
+	}
+	for name, quantity := range b {
+		if q, ok := sum[name]; ok {
+			q.Add(quantity)
+			sum[name] = q
+		} else {
+			sum[name] = quantity.DeepCopy()
+		}
+	}
+	return sum
+}
+
+// Mask returns the resources of list whose names are in the hard limits, with
+// a zero quantity for the ones that are not in list.
+func Mask(list, hard corev1.ResourceList) corev1.ResourceList {
+	masked := corev1.ResourceList{}
+	for name := range hard {
+		if quantity, ok := list[name]; ok {
+			masked[name] = quantity.DeepCopy()
+		} else {
+			masked[name] = *resource.NewQuantity(0, resource.DecimalSI)
+		}
+	}
+	return masked
+}
+
+// Exceeded returns the sorted names of the hard limits that the used
+// resources exceed.
+func Exceeded(hard, used corev1.ResourceList) []corev1.ResourceName {
+	var names []corev1.ResourceName
+	for name, limit := range hard {
+		if quantity, ok := used[name]; ok && quantity.Cmp(limit) > 0 {
+			names = append(names, name)
+		}
+	}
+	sort.Slice(names, func(i, j int) bool { return names[i] < names[j] })
+	return names
+}
+
+// Format returns the resources with the given names as a string such as
+// "cpu=4,memory=8Gi".
+func Format(list corev1.ResourceList, names []corev1.ResourceName) string {
+	parts := make([]string, 0, len(names))
+	for _, name := range names {
+		quantity := list[name]
+		parts = append(parts, fmt.Sprintf("%s=%s", name, quantity.String()))
+	}
+	return strings.Join(parts, ",")
+}
+
+func getVirtualMachineClass(
+	ctx context.Context,
+	client ctrlclient.Client,
+	namespace, className string) (*vmopv1.VirtualMachineClass, error) {
+
+	if className == "" {
+		return nil, nil
+	}
+
+	key := ctrlclient.ObjectKey{Name: className}
+	if lib.IsNamespacedClassAndWindowsFSSEnabled() {
+		key.Namespace = namespace
+	}
+
+	vmClass := &vmopv1.VirtualMachineClass{}
+	if err := client.Get(ctx, key, vmClass); err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return vmClass, nil
+}
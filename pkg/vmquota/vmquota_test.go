@@ -0,0 +1,242 @@
+// Copyright (c) 2023 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package vmquota_test
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	vimtypes "github.com/vmware/govmomi/vim25/types"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	vmopv1 "github.com/vmware-tanzu/vm-operator/api/v1alpha1"
+	"github.com/vmware-tanzu/vm-operator/pkg/lib"
+	"github.com/vmware-tanzu/vm-operator/pkg/util"
+	"github.com/vmware-tanzu/vm-operator/pkg/vmquota"
+	"github.com/vmware-tanzu/vm-operator/test/builder"
+)
+
+var _ = Describe("ClassUsage", func() {
+
+	It("only consumes the VM without a class", func() {
+		usage := vmquota.ClassUsage(nil)
+		Expect(usage).To(HaveLen(1))
+		vms := usage[vmopv1.VirtualMachineQuotaResourceVirtualMachines]
+		Expect(vms.Value()).To(BeEquivalentTo(1))
+	})
+
+	It("derives the resources from the class", func() {
+		vmClass := builder.DummyVirtualMachineClass()
+		vmClass.Spec.Hardware.Devices.VGPUDevices = []vmopv1.VGPUDevice{{ProfileName: "grid_p40-8q"}}
+		vmClass.Spec.Hardware.InstanceStorage.Volumes = []vmopv1.InstanceStorageVolume{
+			{Size: resource.MustParse("10Gi")},
+			{Size: resource.MustParse("20Gi")},
+		}
+
+		usage := vmquota.ClassUsage(vmClass)
+		Expect(vmquota.Format(usage, []corev1.ResourceName{
+			vmopv1.VirtualMachineQuotaResourceVirtualMachines,
+			vmopv1.VirtualMachineQuotaResourceCPU,
+			vmopv1.VirtualMachineQuotaResourceMemory,
+			vmopv1.VirtualMachineQuotaResourceVGPUs,
+			vmopv1.VirtualMachineQuotaResourceInstanceStorage,
+		})).To(Equal("virtualmachines=1,cpu=2,memory=4Gi,vgpus=1,instancestorage=30Gi"))
+	})
+
+	It("counts the vGPU devices of the class's ConfigSpec", func() {
+		vmClass := builder.DummyVirtualMachineClass()
+		vmClass.Spec.Hardware.Devices.VGPUDevices = []vmopv1.VGPUDevice{{ProfileName: "grid_p40-8q"}}
+
+		var err error
+		vmClass.Spec.ConfigSpec, err = util.MarshalConfigSpecToJSON(&vimtypes.VirtualMachineConfigSpec{
+			DeviceChange: []vimtypes.BaseVirtualDeviceConfigSpec{
+				&vimtypes.VirtualDeviceConfigSpec{
+					Operation: vimtypes.VirtualDeviceConfigSpecOperationAdd,
+					Device: &vimtypes.VirtualPCIPassthrough{
+						VirtualDevice: vimtypes.VirtualDevice{
+							Backing: &vimtypes.VirtualPCIPassthroughVmiopBackingInfo{Vgpu: "grid_p40-8q"},
+						},
+					},
+				},
+				&vimtypes.VirtualDeviceConfigSpec{
+					Operation: vimtypes.VirtualDeviceConfigSpecOperationAdd,
+					Device:    &vimtypes.VirtualMachineVideoCard{},
+				},
+			},
+		})
+		Expect(err).ToNot(HaveOccurred())
+
+		usage := vmquota.ClassUsage(vmClass)
+		vGPUs := usage[vmopv1.VirtualMachineQuotaResourceVGPUs]
+		Expect(vGPUs.Value()).To(BeEquivalentTo(2))
+	})
+})
+
+var _ = Describe("ResourceList helpers", func() {
+
+	var (
+		hard corev1.ResourceList
+		used corev1.ResourceList
+	)
+
+	BeforeEach(func() {
+		hard = corev1.ResourceList{
+			vmopv1.VirtualMachineQuotaResourceCPU:    resource.MustParse("4"),
+			vmopv1.VirtualMachineQuotaResourceMemory: resource.MustParse("8Gi"),
+			vmopv1.VirtualMachineQuotaResourceVGPUs:  resource.MustParse("1"),
+		}
+		used = corev1.ResourceList{
+			vmopv1.VirtualMachineQuotaResourceCPU:    resource.MustParse("6"),
+			vmopv1.VirtualMachineQuotaResourceMemory: resource.MustParse("8Gi"),
+		}
+	})
+
+	It("adds resource lists", func() {
+		sum := vmquota.Add(used, corev1.ResourceList{
+			vmopv1.VirtualMachineQuotaResourceCPU:   resource.MustParse("2"),
+			vmopv1.VirtualMachineQuotaResourceVGPUs: resource.MustParse("1"),
+		})
+		Expect(vmquota.Format(sum, []corev1.ResourceName{
+			vmopv1.VirtualMachineQuotaResourceCPU,
+			vmopv1.VirtualMachineQuotaResourceMemory,
+			vmopv1.VirtualMachineQuotaResourceVGPUs,
+		})).To(Equal("cpu=8,memory=8Gi,vgpus=1"))
+
+		By("not modifying the operands", func() {
+			cpu := used[vmopv1.VirtualMachineQuotaResourceCPU]
+			Expect(cpu.Value()).To(BeEquivalentTo(6))
+		})
+	})
+
+	It("masks a resource list with the hard limits", func() {
+		used[vmopv1.VirtualMachineQuotaResourceVirtualMachines] = resource.MustParse("3")
+		masked := vmquota.Mask(used, hard)
+		Expect(masked).To(HaveLen(3))
+		Expect(masked).ToNot(HaveKey(vmopv1.VirtualMachineQuotaResourceVirtualMachines))
+		vgpus := masked[vmopv1.VirtualMachineQuotaResourceVGPUs]
+		Expect(vgpus.IsZero()).To(BeTrue())
+	})
+
+	It("returns the increased resources", func() {
+		Expect(vmquota.Increased(hard, used)).To(Equal([]corev1.ResourceName{vmopv1.VirtualMachineQuotaResourceCPU}))
+		Expect(vmquota.Increased(used, hard)).To(Equal([]corev1.ResourceName{vmopv1.VirtualMachineQuotaResourceVGPUs}))
+	})
+
+	It("returns the exceeded hard limits", func() {
+		Expect(vmquota.Exceeded(hard, used)).To(Equal([]corev1.ResourceName{vmopv1.VirtualMachineQuotaResourceCPU}))
+
+		used[vmopv1.VirtualMachineQuotaResourceVGPUs] = resource.MustParse("2")
+		Expect(vmquota.Exceeded(hard, used)).To(Equal([]corev1.ResourceName{
+			vmopv1.VirtualMachineQuotaResourceCPU,
+			vmopv1.VirtualMachineQuotaResourceVGPUs,
+		}))
+	})
+})
+
+var _ = Describe("NamespaceUsage", func() {
+
+	const namespace = "dummy-ns"
+
+	var (
+		k8sClient   client.Client
+		initObjects []client.Object
+
+		vmClass *vmopv1.VirtualMachineClass
+		vm      *vmopv1.VirtualMachine
+	)
+
+	BeforeEach(func() {
+		vmClass = builder.DummyVirtualMachineClass()
+		vmClass.Name = "dummy-class"
+
+		vm = builder.DummyVirtualMachine()
+		vm.Name = "dummy-vm"
+		vm.Namespace = namespace
+		vm.Spec.ClassName = vmClass.Name
+
+		noClassVM := vm.DeepCopy()
+		noClassVM.Name = "no-class-vm"
+		noClassVM.Spec.ClassName = "missing-class"
+
+		otherNamespaceVM := vm.DeepCopy()
+		otherNamespaceVM.Namespace = "other-ns"
+
+		initObjects = []client.Object{vmClass, vm, noClassVM, otherNamespaceVM}
+	})
+
+	JustBeforeEach(func() {
+		k8sClient = builder.NewFakeClient(initObjects...)
+	})
+
+	It("sums the resources of the VMs of the namespace", func() {
+		used, err := vmquota.NamespaceUsage(context.Background(), k8sClient, namespace, "")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(vmquota.Format(used, []corev1.ResourceName{
+			vmopv1.VirtualMachineQuotaResourceVirtualMachines,
+			vmopv1.VirtualMachineQuotaResourceCPU,
+			vmopv1.VirtualMachineQuotaResourceMemory,
+		})).To(Equal("virtualmachines=2,cpu=2,memory=4Gi"))
+	})
+
+	It("skips the given VM", func() {
+		used, err := vmquota.NamespaceUsage(context.Background(), k8sClient, namespace, vm.Name)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(used).To(HaveLen(1))
+		vms := used[vmopv1.VirtualMachineQuotaResourceVirtualMachines]
+		Expect(vms.Value()).To(BeEquivalentTo(1))
+	})
+
+	It("uses the given class for the VMs of the class", func() {
+		updatedClass := vmClass.DeepCopy()
+		updatedClass.Spec.Hardware.Cpus = 8
+
+		used, err := vmquota.NamespaceUsageWithClass(context.Background(), k8sClient, namespace, updatedClass)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(vmquota.Format(used, []corev1.ResourceName{
+			vmopv1.VirtualMachineQuotaResourceVirtualMachines,
+			vmopv1.VirtualMachineQuotaResourceCPU,
+		})).To(Equal("virtualmachines=2,cpu=8"))
+	})
+
+	It("derives the resources of a VM from its class", func() {
+		usage, err := vmquota.VirtualMachineUsage(context.Background(), k8sClient, vm)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(usage).To(Equal(vmquota.ClassUsage(vmClass)))
+	})
+
+	When("the namespaced class FSS is enabled", func() {
+		var oldNamespacedClassFunc func() bool
+
+		BeforeEach(func() {
+			oldNamespacedClassFunc = lib.IsNamespacedClassAndWindowsFSSEnabled
+			lib.IsNamespacedClassAndWindowsFSSEnabled = func() bool { return true }
+		})
+
+		AfterEach(func() {
+			lib.IsNamespacedClassAndWindowsFSSEnabled = oldNamespacedClassFunc
+		})
+
+		It("does not use the cluster scoped class", func() {
+			usage, err := vmquota.VirtualMachineUsage(context.Background(), k8sClient, vm)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(usage).To(HaveLen(1))
+		})
+
+		When("the class is in the VM's namespace", func() {
+			BeforeEach(func() {
+				vmClass.Namespace = namespace
+			})
+
+			It("derives the resources of the VM from the namespaced class", func() {
+				usage, err := vmquota.VirtualMachineUsage(context.Background(), k8sClient, vm)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(usage).To(HaveKey(vmopv1.VirtualMachineQuotaResourceCPU))
+			})
+		})
+	})
+})
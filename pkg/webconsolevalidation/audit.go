@@ -0,0 +1,38 @@
+// Copyright (c) 2023 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package webconsolevalidation
+
+import (
+	"net/http"
+
+	ctrllog "sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// auditEvent describes the console that a request attempted to open.
+type auditEvent struct {
+	Kind           string
+	UUID           string
+	Namespace      string
+	Name           string
+	VirtualMachine string
+	Requester      string
+}
+
+// audit logs whether the request was allowed to open the console described by the event. Every attempt to open
+// a console is audited, and the log entries have the "audit" logger name so they can be collected separately.
+func audit(r *http.Request, event auditEvent, allowed bool, reason string) {
+	ctrllog.Log.WithName("audit").Info("Console access",
+		"kind", event.Kind,
+		"uuid", event.UUID,
+		"namespace", event.Namespace,
+		"name", event.Name,
+		"virtualMachine", event.VirtualMachine,
+		"requester", event.Requester,
+		"allowed", allowed,
+		"reason", reason,
+		"remoteAddr", r.RemoteAddr,
+		"forwardedFor", r.Header.Get("X-Forwarded-For"),
+		"userAgent", r.UserAgent(),
+	)
+}
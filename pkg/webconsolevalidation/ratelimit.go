@@ -0,0 +1,76 @@
+// Copyright (c) 2023 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package webconsolevalidation
+
+import (
+	"net"
+	"sync"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+// idleClientTimeout is how long the rate limiter of a client that makes no
+// requests is kept before it is dropped.
+const idleClientTimeout = 5 * time.Minute
+
+// ClientRateLimiter limits the rate of requests of each client separately, so
+// one client cannot exhaust the requests that the other clients may make.
+// Clients are identified by their source address.
+type ClientRateLimiter struct {
+	limit rate.Limit
+	burst int
+
+	mu          sync.Mutex
+	clients     map[string]*clientLimiter
+	lastCleanup time.Time
+}
+
+type clientLimiter struct {
+	limiter  *rate.Limiter
+	lastSeen time.Time
+}
+
+// NewClientRateLimiter returns a ClientRateLimiter that allows each client
+// requests at the given rate, with bursts of up to burst requests.
+func NewClientRateLimiter(limit rate.Limit, burst int) *ClientRateLimiter {
+	return &ClientRateLimiter{
+		limit:       limit,
+		burst:       burst,
+		clients:     map[string]*clientLimiter{},
+		lastCleanup: time.Now(),
+	}
+}
+
+// Allow returns whether a request from the client with the given address, in
+// the host:port form of http.Request's RemoteAddr, may be handled now.
+func (l *ClientRateLimiter) Allow(remoteAddr string) bool {
+	client := remoteAddr
+	if host, _, err := net.SplitHostPort(remoteAddr); err == nil {
+		client = host
+	}
+
+	now := time.Now()
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if now.Sub(l.lastCleanup) > idleClientTimeout {
+		for c, cl := range l.clients {
+			if now.Sub(cl.lastSeen) > idleClientTimeout {
+				delete(l.clients, c)
+			}
+		}
+		l.lastCleanup = now
+	}
+
+	cl, ok := l.clients[client]
+	if !ok {
+		cl = &clientLimiter{limiter: rate.NewLimiter(l.limit, l.burst)}
+		l.clients[client] = cl
+	}
+	cl.lastSeen = now
+
+	return cl.limiter.AllowN(now, 1)
+}
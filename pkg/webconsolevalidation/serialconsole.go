@@ -0,0 +1,165 @@
+// Copyright (c) 2023 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package webconsolevalidation
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"golang.org/x/net/websocket"
+	ctrlruntime "sigs.k8s.io/controller-runtime/pkg/client"
+	ctrllog "sigs.k8s.io/controller-runtime/pkg/log"
+
+	vmopv1 "github.com/vmware-tanzu/vm-operator/api/v1alpha1"
+	"github.com/vmware-tanzu/vm-operator/controllers/serialconsolerequest"
+)
+
+// HandleSerialConsole handles the serial console stream requests. A request is authenticated by the token from
+// the status.response of the SerialConsoleRequest with the given uuid and namespace, and is then upgraded to a
+// websocket that streams the VM's serial port, which is connected to the Concentrator, until the SerialConsoleRequest
+// expires.
+func HandleSerialConsole(w http.ResponseWriter, r *http.Request) {
+	uuid := r.URL.Query().Get("uuid")
+	if uuid == "" {
+		http.Error(w, "'uuid' param is empty", http.StatusBadRequest)
+		return
+	}
+
+	namespace := r.URL.Query().Get("namespace")
+	if namespace == "" {
+		http.Error(w, "'namespace' param is empty", http.StatusBadRequest)
+		return
+	}
+
+	// The token is only accepted from the Authorization header so it is not recorded in the URL, for
+	// example in access logs.
+	token := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if token == "" {
+		http.Error(w, "token is empty", http.StatusUnauthorized)
+		return
+	}
+
+	logger := ctrllog.Log.WithName(r.URL.Path).WithValues("uuid", uuid).WithValues("namespace", namespace)
+
+	scr, err := getSerialConsoleRequest(r.Context(), uuid, namespace)
+	if err != nil {
+		logger.Error(err, "Error occurred in finding a serialconsolerequest resource with the given params.")
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	event := auditEvent{
+		Kind:      "SerialConsoleRequest",
+		UUID:      uuid,
+		Namespace: namespace,
+	}
+
+	if scr == nil {
+		logger.Info("Didn't find a serialconsolerequest resource with the given params. Returning 403.")
+		audit(r, event, false, "NotFound")
+		w.WriteHeader(http.StatusForbidden)
+		return
+	}
+
+	event.Name = scr.Name
+	event.VirtualMachine = scr.Spec.VirtualMachineName
+
+	expiryTime := scr.Status.ExpiryTime.Time
+	if expiryTime.IsZero() || !time.Now().Before(expiryTime) {
+		logger.Info("The serialconsolerequest resource is expired or not ready. Returning 403.")
+		audit(r, event, false, "Expired")
+		w.WriteHeader(http.StatusForbidden)
+		return
+	}
+
+	tokenHash := serialconsolerequest.HashToken(token)
+	if subtle.ConstantTimeCompare([]byte(tokenHash), []byte(scr.Status.TokenHash)) != 1 {
+		logger.Info("The token does not match the serialconsolerequest resource. Returning 403.")
+		audit(r, event, false, "InvalidToken")
+		w.WriteHeader(http.StatusForbidden)
+		return
+	}
+
+	if Concentrator == nil {
+		logger.Info("The vSPC is not enabled. Returning 502.")
+		http.Error(w, "serial consoles are not enabled", http.StatusBadGateway)
+		return
+	}
+
+	serialConn, err := Concentrator.Attach(scr.Status.SerialPortURI)
+	if err != nil {
+		logger.Info("Failed to attach to the serial port", "serialPortURI", scr.Status.SerialPortURI, "reason", err.Error())
+		if errors.Is(err, ErrSerialPortInUse) {
+			http.Error(w, err.Error(), http.StatusConflict)
+		} else {
+			http.Error(w, err.Error(), http.StatusBadGateway)
+		}
+		return
+	}
+	defer serialConn.Close()
+
+	audit(r, event, true, "")
+	logger.Info("Streaming the serial console", "serialPortURI", scr.Status.SerialPortURI, "expiryTime", expiryTime)
+
+	server := websocket.Server{
+		// The token authenticates the request so the Origin header is not checked.
+		Handshake: func(*websocket.Config, *http.Request) error { return nil },
+		Handler: func(wsConn *websocket.Conn) {
+			defer wsConn.Close()
+			wsConn.PayloadType = websocket.BinaryFrame
+
+			// The stream is closed when the serialconsolerequest expires.
+			_ = wsConn.SetDeadline(expiryTime)
+			timer := time.AfterFunc(time.Until(expiryTime), func() { _ = serialConn.Close() })
+			defer timer.Stop()
+
+			errCh := make(chan error, 2)
+			go func() {
+				_, err := io.Copy(serialConn, wsConn)
+				errCh <- err
+			}()
+			go func() {
+				_, err := io.Copy(wsConn, serialConn)
+				errCh <- err
+			}()
+
+			if err := <-errCh; err != nil {
+				logger.Info("Serial console stream closed", "reason", err.Error())
+			}
+		},
+	}
+	server.ServeHTTP(w, r)
+}
+
+func getSerialConsoleRequest(goCtx context.Context, uuid, namespace string) (*vmopv1.SerialConsoleRequest, error) {
+	scrObjectList := &vmopv1.SerialConsoleRequestList{}
+	if err := K8sClient.List(goCtx, scrObjectList, ctrlruntime.InNamespace(namespace)); err != nil {
+		return nil, err
+	}
+
+	var unlabeled *vmopv1.SerialConsoleRequest
+	for i := range scrObjectList.Items {
+		scr := &scrObjectList.Items[i]
+		if scr.Labels[serialconsolerequest.UUIDLabelKey] == uuid {
+			return scr, nil
+		}
+		if string(scr.UID) == uuid {
+			unlabeled = scr
+		}
+	}
+
+	if unlabeled == nil {
+		return nil, nil
+	}
+
+	if err := getLatest(goCtx, unlabeled); err != nil {
+		return nil, err
+	}
+	return unlabeled, nil
+}
@@ -0,0 +1,284 @@
+// Copyright (c) 2023 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package webconsolevalidation_test
+
+import (
+	"bufio"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"golang.org/x/net/websocket"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	vmopv1 "github.com/vmware-tanzu/vm-operator/api/v1alpha1"
+	"github.com/vmware-tanzu/vm-operator/controllers/serialconsolerequest"
+	"github.com/vmware-tanzu/vm-operator/pkg/webconsolevalidation"
+	"github.com/vmware-tanzu/vm-operator/test/builder"
+)
+
+func serialConsoleUnitTests() {
+
+	Describe("serial console stream unit tests", func() {
+
+		const (
+			uuid      = "dummy-uuid-1234"
+			namespace = "dummy-namespace"
+			token     = "dummy-token"
+
+			serialPortURI = "vm-operator-serial-console:dummy"
+		)
+
+		var (
+			initObjects []client.Object
+			scr         *vmopv1.SerialConsoleRequest
+		)
+
+		BeforeEach(func() {
+			scr = &vmopv1.SerialConsoleRequest{}
+			scr.Name = "dummy-scr"
+			scr.Namespace = namespace
+			scr.Labels = map[string]string{
+				serialconsolerequest.UUIDLabelKey: uuid,
+			}
+			scr.Status.TokenHash = serialconsolerequest.HashToken(token)
+			scr.Status.ExpiryTime = metav1.NewTime(time.Now().Add(time.Minute))
+			scr.Status.SerialPortURI = serialPortURI
+		})
+
+		JustBeforeEach(func() {
+			initObjects = append(initObjects, scr)
+			webconsolevalidation.K8sClient = builder.NewFakeClient(initObjects...)
+		})
+
+		AfterEach(func() {
+			initObjects = nil
+			webconsolevalidation.K8sClient = nil
+		})
+
+		Context("requests with missing params", func() {
+
+			It("should return http.StatusBadRequest (400)", func() {
+				Expect(fakeSerialConsoleRequest("/", token)).To(Equal(http.StatusBadRequest))
+				Expect(fakeSerialConsoleRequest("/?uuid=123", token)).To(Equal(http.StatusBadRequest))
+				Expect(fakeSerialConsoleRequest("/?namespace=dummy", token)).To(Equal(http.StatusBadRequest))
+			})
+
+			It("should return http.StatusUnauthorized (401) without a token", func() {
+				url := "/?uuid=" + uuid + "&namespace=" + namespace
+				Expect(fakeSerialConsoleRequest(url, "")).To(Equal(http.StatusUnauthorized))
+			})
+
+			It("should return http.StatusUnauthorized (401) with the token in the query", func() {
+				url := "/?uuid=" + uuid + "&namespace=" + namespace + "&token=" + token
+				Expect(fakeSerialConsoleRequest(url, "")).To(Equal(http.StatusUnauthorized))
+			})
+		})
+
+		When("UUID doesn't match any SerialConsoleRequest resource", func() {
+
+			It("should return http.StatusForbidden (403)", func() {
+				url := "/?uuid=non-existent-uuid&namespace=" + namespace
+				Expect(fakeSerialConsoleRequest(url, token)).To(Equal(http.StatusForbidden))
+			})
+		})
+
+		When("the token doesn't match the SerialConsoleRequest resource", func() {
+
+			It("should return http.StatusForbidden (403)", func() {
+				url := "/?uuid=" + uuid + "&namespace=" + namespace
+				Expect(fakeSerialConsoleRequest(url, "wrong-token")).To(Equal(http.StatusForbidden))
+			})
+		})
+
+		When("the SerialConsoleRequest resource is expired", func() {
+
+			BeforeEach(func() {
+				scr.Status.ExpiryTime = metav1.NewTime(time.Now().Add(-time.Minute))
+			})
+
+			It("should return http.StatusForbidden (403)", func() {
+				url := "/?uuid=" + uuid + "&namespace=" + namespace
+				Expect(fakeSerialConsoleRequest(url, token)).To(Equal(http.StatusForbidden))
+			})
+		})
+
+		When("the vSPC is not enabled", func() {
+
+			It("should return http.StatusBadGateway (502)", func() {
+				url := "/?uuid=" + uuid + "&namespace=" + namespace
+				Expect(fakeSerialConsoleRequest(url, token)).To(Equal(http.StatusBadGateway))
+			})
+		})
+
+		When("the vSPC is enabled", func() {
+
+			var (
+				listener net.Listener
+			)
+
+			BeforeEach(func() {
+				var err error
+				listener, err = net.Listen("tcp", "127.0.0.1:0")
+				Expect(err).ToNot(HaveOccurred())
+
+				webconsolevalidation.Concentrator = webconsolevalidation.NewSerialPortConcentrator()
+				go func() {
+					_ = webconsolevalidation.Concentrator.Serve(listener)
+				}()
+			})
+
+			AfterEach(func() {
+				_ = listener.Close()
+				webconsolevalidation.Concentrator = nil
+			})
+
+			When("the serial port is not connected to the vSPC", func() {
+
+				It("should return http.StatusBadGateway (502)", func() {
+					url := "/?uuid=" + uuid + "&namespace=" + namespace
+					Expect(fakeSerialConsoleRequest(url, token)).To(Equal(http.StatusBadGateway))
+				})
+			})
+
+			When("the serial port is connected to the vSPC", func() {
+
+				var (
+					hostConn net.Conn
+				)
+
+				BeforeEach(func() {
+					hostConn = connectFakeSerialPort(listener.Addr().String(), serialPortURI)
+				})
+
+				AfterEach(func() {
+					_ = hostConn.Close()
+				})
+
+				It("streams the serial port over a websocket", func() {
+					server := httptest.NewServer(http.HandlerFunc(webconsolevalidation.HandleSerialConsole))
+					defer server.Close()
+
+					wsURL := "ws" + strings.TrimPrefix(server.URL, "http") + "/?uuid=" + uuid + "&namespace=" + namespace
+					config, err := websocket.NewConfig(wsURL, server.URL)
+					Expect(err).ToNot(HaveOccurred())
+					config.Header.Set("Authorization", "Bearer "+token)
+
+					wsConn, err := websocket.DialConfig(config)
+					Expect(err).ToNot(HaveOccurred())
+					defer wsConn.Close()
+
+					// The IAC byte is escaped to the host and unescaped from it.
+					Expect(websocket.Message.Send(wsConn, []byte("hello\xff"))).To(Succeed())
+
+					var msg []byte
+					Expect(websocket.Message.Receive(wsConn, &msg)).To(Succeed())
+					Expect(string(msg)).To(Equal("hello\xff"))
+				})
+
+				When("the serial port is already streamed", func() {
+
+					It("should return http.StatusConflict (409)", func() {
+						stream, err := webconsolevalidation.Concentrator.Attach(serialPortURI)
+						Expect(err).ToNot(HaveOccurred())
+						defer stream.Close()
+
+						url := "/?uuid=" + uuid + "&namespace=" + namespace
+						Expect(fakeSerialConsoleRequest(url, token)).To(Equal(http.StatusConflict))
+					})
+				})
+			})
+		})
+	})
+}
+
+// connectFakeSerialPort connects a fake ESXi host serial port with the given service URI to the vSPC at addr. The
+// fake serial port echoes its input back as its output. It returns once the vSPC accepted the serial port.
+func connectFakeSerialPort(addr, serviceURI string) net.Conn {
+	const (
+		se   = 240
+		sb   = 250
+		will = 251
+		dont = 254
+		iac  = 255
+
+		vmwareExt = 232
+		doProxy   = 70
+		willProxy = 71
+	)
+
+	conn, err := net.Dial("tcp", addr)
+	ExpectWithOffset(1, err).ToNot(HaveOccurred())
+
+	_, err = conn.Write(append(append([]byte{iac, will, vmwareExt, iac, sb, vmwareExt, doProxy, 'S'}, serviceURI...), iac, se))
+	ExpectWithOffset(1, err).ToNot(HaveOccurred())
+
+	connected := make(chan struct{})
+	go func() {
+		defer GinkgoRecover()
+		r := bufio.NewReader(conn)
+		for {
+			b, err := r.ReadByte()
+			if err != nil {
+				return
+			}
+			if b != iac {
+				_, _ = conn.Write([]byte{b})
+				continue
+			}
+
+			cmd, err := r.ReadByte()
+			if err != nil {
+				return
+			}
+			switch {
+			case cmd == iac:
+				_, _ = conn.Write([]byte{iac, iac})
+			case cmd >= will && cmd <= dont:
+				_, _ = r.ReadByte()
+			case cmd == sb:
+				var payload []byte
+				for {
+					b, err := r.ReadByte()
+					if err != nil {
+						return
+					}
+					if b == iac {
+						if b, _ = r.ReadByte(); b == se {
+							break
+						}
+					}
+					payload = append(payload, b)
+				}
+				if len(payload) >= 2 && payload[0] == vmwareExt && payload[1] == willProxy {
+					close(connected)
+				}
+			}
+		}
+	}()
+
+	EventuallyWithOffset(1, connected).Should(BeClosed())
+	return conn
+}
+
+// fakeSerialConsoleRequest is a helper function to make a fake serial console request.
+// It returns the response code from the server.
+func fakeSerialConsoleRequest(url, token string) int {
+	responseRecorder := httptest.NewRecorder()
+	testRequest, _ := http.NewRequest("GET", url, nil)
+	if token != "" {
+		testRequest.Header.Set("Authorization", "Bearer "+token)
+	}
+	webconsolevalidation.HandleSerialConsole(responseRecorder, testRequest)
+	response := responseRecorder.Result()
+	_ = response.Body.Close()
+
+	return response.StatusCode
+}
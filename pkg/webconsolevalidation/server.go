@@ -1,14 +1,21 @@
-// Copyright (c) 2022 VMware, Inc. All Rights Reserved.
+// Copyright (c) 2022-2023 VMware, Inc. All Rights Reserved.
 // SPDX-License-Identifier: Apache-2.0
 
 package webconsolevalidation
 
 import (
 	"context"
+	"fmt"
+	"net"
 	"net/http"
+	"strconv"
+	"time"
 
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/client-go/rest"
+	ctrlcache "sigs.k8s.io/controller-runtime/pkg/cache"
 	ctrlruntime "sigs.k8s.io/controller-runtime/pkg/client"
 	ctrllog "sigs.k8s.io/controller-runtime/pkg/log"
 
@@ -16,9 +23,22 @@ import (
 	"github.com/vmware-tanzu/vm-operator/controllers/webconsolerequest"
 )
 
-// K8sClient is used to get the webconsolerequest resource from UUID and namespace.
+// K8sClient is used to get the webconsolerequest resource from UUID and namespace. When initialized by InitServer,
+// reads are served from an informer cache.
 var K8sClient ctrlruntime.Client
 
+// APIReader reads directly from the API server. It is used to read a webconsolerequest resource that the informer
+// cache has observed, but not yet with its UUID label, since the cache has not observed the ticket that was issued
+// along with the label either. When nil, only K8sClient is used.
+var APIReader ctrlruntime.Reader
+
+// SingleUseTickets is whether a webconsolerequest ticket may only be validated once.
+var SingleUseTickets bool
+
+// RateLimiter limits the rate of requests handled by the server from each client. When nil, requests are not rate
+// limited.
+var RateLimiter *ClientRateLimiter
+
 // InitServer initializes a K8sClient used by the web-console validation server.
 func InitServer() error {
 	restConfig, err := rest.InClusterConfig()
@@ -36,18 +56,86 @@ func InitServer() error {
 		return err
 	}
 
-	K8sClient = ctrlruntimeClient
+	cache, err := ctrlcache.New(restConfig, ctrlcache.Options{Scheme: scheme})
+	if err != nil {
+		return err
+	}
+
+	// Only the metadata of the VMs is needed to check that the VM of a request exists.
+	vmMetadata := &metav1.PartialObjectMetadata{}
+	vmMetadata.SetGroupVersionKind(vmopv1.SchemeGroupVersion.WithKind("VirtualMachine"))
+
+	goCtx := context.Background()
+	for _, obj := range []ctrlruntime.Object{
+		&vmopv1.WebConsoleRequest{},
+		&vmopv1.SerialConsoleRequest{},
+		vmMetadata,
+	} {
+		if _, err := cache.GetInformer(goCtx, obj); err != nil {
+			return err
+		}
+	}
+
+	go func() {
+		if err := cache.Start(goCtx); err != nil {
+			ctrllog.Log.Error(err, "Informer cache stopped")
+		}
+	}()
+	if !cache.WaitForCacheSync(goCtx) {
+		return fmt.Errorf("failed to sync the informer cache")
+	}
+
+	cachedClient, err := ctrlruntime.NewDelegatingClient(ctrlruntime.NewDelegatingClientInput{
+		CacheReader: cache,
+		Client:      ctrlruntimeClient,
+	})
+	if err != nil {
+		return err
+	}
+
+	K8sClient = cachedClient
+	APIReader = ctrlruntimeClient
 	return nil
 }
 
-// RunServer runs the web-console validation server at the given addr and path.
-func RunServer(addr, path string) error {
+// RunServer runs the web-console validation server at the given addr and path, and serves the serial console
+// streams at the given serialConsolePath. When vspcAddr is not empty, the Concentrator that the ESXi hosts connect
+// the VMs' serial ports to listens on it.
+func RunServer(addr, path, serialConsolePath, vspcAddr string) error {
+	if vspcAddr != "" {
+		listener, err := net.Listen("tcp", vspcAddr)
+		if err != nil {
+			return err
+		}
+
+		Concentrator = NewSerialPortConcentrator()
+		go func() {
+			if err := Concentrator.Serve(listener); err != nil {
+				ctrllog.Log.Error(err, "The vSPC stopped")
+			}
+		}()
+	}
+
 	mux := http.NewServeMux()
-	mux.HandleFunc(path, HandleWebConsoleValidation)
+	mux.HandleFunc(path, WithRateLimit(HandleWebConsoleValidation))
+	mux.HandleFunc(serialConsolePath, WithRateLimit(HandleSerialConsole))
 
 	return http.ListenAndServe(addr, mux)
 }
 
+// WithRateLimit returns a handler that responds with http.StatusTooManyRequests (429) when the RateLimiter does
+// not allow the request from its client, and otherwise calls the given handler.
+func WithRateLimit(handler http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if RateLimiter != nil && !RateLimiter.Allow(r.RemoteAddr) {
+			w.Header().Set("Retry-After", "1")
+			http.Error(w, "too many requests", http.StatusTooManyRequests)
+			return
+		}
+		handler(w, r)
+	}
+}
+
 // HandleWebConsoleValidation handles the web-console validation server requests.
 func HandleWebConsoleValidation(w http.ResponseWriter, r *http.Request) {
 	uuid := r.URL.Query().Get("uuid")
@@ -64,32 +152,154 @@ func HandleWebConsoleValidation(w http.ResponseWriter, r *http.Request) {
 
 	logger := ctrllog.Log.WithName(r.URL.Path).WithValues("uuid", uuid).WithValues("namespace", namespace)
 
-	found, err := isResourceFound(r.Context(), uuid, namespace)
+	wcr, err := getWebConsoleRequest(r.Context(), uuid, namespace)
 	if err != nil {
 		logger.Error(err, "Error occurred in finding a webconsolerequest resource with the given params.")
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
 
-	if found {
-		logger.Info("Found a webconsolerequest resource with the given params. Returning 200.")
-		w.WriteHeader(http.StatusOK)
-	} else {
+	event := auditEvent{
+		Kind:      "WebConsoleRequest",
+		UUID:      uuid,
+		Namespace: namespace,
+	}
+
+	if wcr == nil {
 		logger.Info("Didn't find a webconsolerequest resource with the given params. Returning 403.")
+		audit(r, event, false, "NotFound")
 		w.WriteHeader(http.StatusForbidden)
+		return
+	}
+
+	event.Name = wcr.Name
+	event.VirtualMachine = wcr.Spec.VirtualMachineName
+	event.Requester = wcr.Annotations[webconsolerequest.RequesterAnnotationKey]
+
+	reason, err := validateWebConsoleRequest(r.Context(), wcr)
+	if err != nil {
+		logger.Error(err, "Error occurred in validating the webconsolerequest resource.")
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
 	}
+
+	if reason == "" && SingleUseTickets {
+		reason, err = markWebConsoleRequestUsed(r.Context(), wcr)
+		if err != nil {
+			logger.Error(err, "Error occurred in marking the webconsolerequest ticket as used.")
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+	}
+
+	if reason != "" {
+		logger.Info("The webconsolerequest resource is not valid. Returning 403.", "reason", reason)
+		audit(r, event, false, reason)
+		w.WriteHeader(http.StatusForbidden)
+		return
+	}
+
+	logger.Info("Found a webconsolerequest resource with the given params. Returning 200.")
+	audit(r, event, true, "")
+	w.WriteHeader(http.StatusOK)
 }
 
-func isResourceFound(goCtx context.Context, uuid, namespace string) (bool, error) {
-	labelSelector := ctrlruntime.MatchingLabels{
-		webconsolerequest.UUIDLabelKey: uuid,
+// validateWebConsoleRequest returns the reason the webconsolerequest's ticket may not be used, or an empty string
+// if it may be used.
+func validateWebConsoleRequest(goCtx context.Context, wcr *vmopv1.WebConsoleRequest) (string, error) {
+	expiryTime := wcr.Status.ExpiryTime.Time
+	if expiryTime.IsZero() {
+		return "NotReady", nil
+	}
+	if !time.Now().Before(expiryTime) {
+		return "Expired", nil
+	}
+
+	if SingleUseTickets {
+		// The annotation is the renewal count of the used ticket, so a renewed ticket may be used again.
+		if used, ok := wcr.Annotations[webconsolerequest.UsedAnnotationKey]; ok && used == usedAnnotationValue(wcr) {
+			return "AlreadyUsed", nil
+		}
+	}
+
+	vm := &metav1.PartialObjectMetadata{}
+	vm.SetGroupVersionKind(vmopv1.SchemeGroupVersion.WithKind("VirtualMachine"))
+	vmKey := ctrlruntime.ObjectKey{Namespace: wcr.Namespace, Name: wcr.Spec.VirtualMachineName}
+	if err := K8sClient.Get(goCtx, vmKey, vm); err != nil {
+		if apierrors.IsNotFound(err) {
+			return "VirtualMachineNotFound", nil
+		}
+		return "", err
+	}
+
+	// The ticket was issued for the VM that owns the webconsolerequest. A VM that was since deleted and
+	// recreated with the same name is a different VM.
+	for _, ownerRef := range wcr.OwnerReferences {
+		if ownerRef.Kind == "VirtualMachine" && ownerRef.UID != vm.UID {
+			return "VirtualMachineMismatch", nil
+		}
 	}
 
-	// TODO: Use an Informer to avoid hitting the API server for every request.
+	return "", nil
+}
+
+// markWebConsoleRequestUsed marks the webconsolerequest's ticket as used. The patch fails if the webconsolerequest
+// has changed since it was read, so a ticket is only used once even when validated concurrently. The reason the
+// ticket may not be used is returned if it was used by another request.
+func markWebConsoleRequestUsed(goCtx context.Context, wcr *vmopv1.WebConsoleRequest) (string, error) {
+	patch := ctrlruntime.MergeFromWithOptions(wcr.DeepCopy(), ctrlruntime.MergeFromWithOptimisticLock{})
+	if wcr.Annotations == nil {
+		wcr.Annotations = map[string]string{}
+	}
+	wcr.Annotations[webconsolerequest.UsedAnnotationKey] = usedAnnotationValue(wcr)
+
+	if err := K8sClient.Patch(goCtx, wcr, patch); err != nil {
+		if apierrors.IsConflict(err) {
+			return "AlreadyUsed", nil
+		}
+		return "", err
+	}
+
+	return "", nil
+}
+
+// usedAnnotationValue returns the value of the used annotation for the webconsolerequest's current ticket.
+func usedAnnotationValue(wcr *vmopv1.WebConsoleRequest) string {
+	return strconv.Itoa(int(wcr.Status.RenewalCount))
+}
+
+func getWebConsoleRequest(goCtx context.Context, uuid, namespace string) (*vmopv1.WebConsoleRequest, error) {
 	wcrObjectList := &vmopv1.WebConsoleRequestList{}
-	if err := K8sClient.List(goCtx, wcrObjectList, ctrlruntime.InNamespace(namespace), labelSelector); err != nil {
-		return false, err
+	if err := K8sClient.List(goCtx, wcrObjectList, ctrlruntime.InNamespace(namespace)); err != nil {
+		return nil, err
 	}
 
-	return len(wcrObjectList.Items) > 0, nil
+	var unlabeled *vmopv1.WebConsoleRequest
+	for i := range wcrObjectList.Items {
+		wcr := &wcrObjectList.Items[i]
+		if wcr.Labels[webconsolerequest.UUIDLabelKey] == uuid {
+			return wcr, nil
+		}
+		if string(wcr.UID) == uuid {
+			unlabeled = wcr
+		}
+	}
+
+	if unlabeled == nil {
+		return nil, nil
+	}
+
+	if err := getLatest(goCtx, unlabeled); err != nil {
+		return nil, err
+	}
+	return unlabeled, nil
+}
+
+// getLatest reads the object again with the APIReader, if any. The UUID label of a request is its UID, so the
+// API server is only read for requests that exist, and a request with an unknown UUID is answered from the cache.
+func getLatest(goCtx context.Context, obj ctrlruntime.Object) error {
+	if APIReader == nil {
+		return nil
+	}
+	return APIReader.Get(goCtx, ctrlruntime.ObjectKeyFromObject(obj), obj)
 }
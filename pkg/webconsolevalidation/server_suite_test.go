@@ -1,4 +1,4 @@
-// Copyright (c) 2022 VMware, Inc. All Rights Reserved.
+// Copyright (c) 2022-2023 VMware, Inc. All Rights Reserved.
 // SPDX-License-Identifier: Apache-2.0
 
 package webconsolevalidation_test
@@ -17,6 +17,11 @@ var _ = BeforeSuite(suite.BeforeSuite)
 
 var _ = AfterSuite(suite.AfterSuite)
 
+func unitTests() {
+	serverUnitTests()
+	serialConsoleUnitTests()
+}
+
 func TestWebConsoleValidationServer(t *testing.T) {
-	suite.Register(t, "web-console validation server test suite", nil, serverUnitTests)
+	suite.Register(t, "web-console validation server test suite", nil, unitTests)
 }
@@ -1,15 +1,21 @@
-// Copyright (c) 2022 VMware, Inc. All Rights Reserved.
+// Copyright (c) 2022-2023 VMware, Inc. All Rights Reserved.
 // SPDX-License-Identifier: Apache-2.0
 
 package webconsolevalidation_test
 
 import (
+	"context"
+	"errors"
 	"net/http"
 	"net/http/httptest"
+	"time"
 
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
 
+	"golang.org/x/time/rate"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	vmopv1 "github.com/vmware-tanzu/vm-operator/api/v1alpha1"
@@ -54,13 +60,35 @@ func serverUnitTests() {
 
 		Context("requests with a uuid param set", func() {
 
+			var (
+				wcr *vmopv1.WebConsoleRequest
+				vm  *vmopv1.VirtualMachine
+			)
+
 			BeforeEach(func() {
-				wcr := &vmopv1.WebConsoleRequest{}
+				vm = &vmopv1.VirtualMachine{}
+				vm.Name = "dummy-vm"
+				vm.Namespace = "dummy-namespace"
+				vm.UID = "dummy-vm-uid"
+
+				wcr = &vmopv1.WebConsoleRequest{}
+				wcr.Name = "dummy-wcr"
 				wcr.Namespace = "dummy-namespace"
 				wcr.Labels = map[string]string{
 					webconsolerequest.UUIDLabelKey: "dummy-uuid-1234",
 				}
-				initObjects = append(initObjects, wcr)
+				wcr.Spec.VirtualMachineName = vm.Name
+				wcr.Status.ExpiryTime = metav1.NewTime(time.Now().Add(time.Minute))
+				wcr.OwnerReferences = []metav1.OwnerReference{
+					{Kind: "VirtualMachine", Name: vm.Name, UID: vm.UID},
+				}
+			})
+
+			JustBeforeEach(func() {
+				Expect(webconsolevalidation.K8sClient.Create(context.Background(), wcr)).To(Succeed())
+				if vm != nil {
+					Expect(webconsolevalidation.K8sClient.Create(context.Background(), vm)).To(Succeed())
+				}
 			})
 
 			When("UUID matches an existing WebConsoleRequest resource", func() {
@@ -92,16 +120,184 @@ func serverUnitTests() {
 				})
 
 			})
+
+			When("the WebConsoleRequest resource doesn't have a ticket yet", func() {
+
+				BeforeEach(func() {
+					wcr.Status.ExpiryTime = metav1.Time{}
+				})
+
+				It("should return http.StatusForbidden (403)", func() {
+					url := "/?uuid=dummy-uuid-1234&namespace=dummy-namespace"
+					Expect(fakeValidationRequest(url)).To(Equal(http.StatusForbidden))
+				})
+
+			})
+
+			When("the WebConsoleRequest resource is expired", func() {
+
+				BeforeEach(func() {
+					wcr.Status.ExpiryTime = metav1.NewTime(time.Now().Add(-time.Minute))
+				})
+
+				It("should return http.StatusForbidden (403)", func() {
+					url := "/?uuid=dummy-uuid-1234&namespace=dummy-namespace"
+					Expect(fakeValidationRequest(url)).To(Equal(http.StatusForbidden))
+				})
+
+			})
+
+			When("the target VM doesn't exist", func() {
+
+				BeforeEach(func() {
+					vm = nil
+				})
+
+				It("should return http.StatusForbidden (403)", func() {
+					url := "/?uuid=dummy-uuid-1234&namespace=dummy-namespace"
+					Expect(fakeValidationRequest(url)).To(Equal(http.StatusForbidden))
+				})
+
+			})
+
+			When("the target VM was recreated", func() {
+
+				BeforeEach(func() {
+					vm.UID = types.UID("other-vm-uid")
+				})
+
+				It("should return http.StatusForbidden (403)", func() {
+					url := "/?uuid=dummy-uuid-1234&namespace=dummy-namespace"
+					Expect(fakeValidationRequest(url)).To(Equal(http.StatusForbidden))
+				})
+
+			})
+
+			When("single-use tickets are enabled", func() {
+
+				BeforeEach(func() {
+					webconsolevalidation.SingleUseTickets = true
+				})
+
+				AfterEach(func() {
+					webconsolevalidation.SingleUseTickets = false
+				})
+
+				It("should only return http.StatusOK (200) once", func() {
+					url := "/?uuid=dummy-uuid-1234&namespace=dummy-namespace"
+					Expect(fakeValidationRequest(url)).To(Equal(http.StatusOK))
+					Expect(fakeValidationRequest(url)).To(Equal(http.StatusForbidden))
+
+					Expect(webconsolevalidation.K8sClient.Get(context.Background(), client.ObjectKeyFromObject(wcr), wcr)).To(Succeed())
+					Expect(wcr.Annotations).To(HaveKeyWithValue(webconsolerequest.UsedAnnotationKey, "0"))
+				})
+
+				It("should return http.StatusOK (200) once for a renewed ticket", func() {
+					url := "/?uuid=dummy-uuid-1234&namespace=dummy-namespace"
+					Expect(fakeValidationRequest(url)).To(Equal(http.StatusOK))
+
+					Expect(webconsolevalidation.K8sClient.Get(context.Background(), client.ObjectKeyFromObject(wcr), wcr)).To(Succeed())
+					wcr.Spec.RenewalCount = 1
+					wcr.Status.RenewalCount = 1
+					Expect(webconsolevalidation.K8sClient.Update(context.Background(), wcr)).To(Succeed())
+
+					Expect(fakeValidationRequest(url)).To(Equal(http.StatusOK))
+					Expect(fakeValidationRequest(url)).To(Equal(http.StatusForbidden))
+				})
+
+			})
+
+			When("the rate limit is exceeded", func() {
+
+				BeforeEach(func() {
+					webconsolevalidation.RateLimiter = webconsolevalidation.NewClientRateLimiter(rate.Every(time.Hour), 1)
+				})
+
+				AfterEach(func() {
+					webconsolevalidation.RateLimiter = nil
+				})
+
+				It("should return http.StatusTooManyRequests (429) to that client only", func() {
+					url := "/?uuid=dummy-uuid-1234&namespace=dummy-namespace"
+					handler := webconsolevalidation.WithRateLimit(webconsolevalidation.HandleWebConsoleValidation)
+					Expect(fakeRequestFrom(handler, url, "192.0.2.1:1234")).To(Equal(http.StatusOK))
+					Expect(fakeRequestFrom(handler, url, "192.0.2.1:5678")).To(Equal(http.StatusTooManyRequests))
+					Expect(fakeRequestFrom(handler, url, "192.0.2.2:1234")).To(Equal(http.StatusOK))
+				})
+
+			})
+
+			When("the cache has not observed the UUID label of the WebConsoleRequest resource", func() {
+
+				var apiReader client.Client
+
+				BeforeEach(func() {
+					wcr.UID = "dummy-uuid-1234"
+					wcr.Labels = nil
+
+					labeled := wcr.DeepCopy()
+					labeled.Labels = map[string]string{
+						webconsolerequest.UUIDLabelKey: string(wcr.UID),
+					}
+					apiReader = builder.NewFakeClient(labeled)
+
+					wcr.Status.ExpiryTime = metav1.Time{}
+				})
+
+				JustBeforeEach(func() {
+					webconsolevalidation.APIReader = apiReader
+				})
+
+				AfterEach(func() {
+					webconsolevalidation.APIReader = nil
+				})
+
+				It("should read it from the API server and return http.StatusOK (200)", func() {
+					url := "/?uuid=dummy-uuid-1234&namespace=dummy-namespace"
+					Expect(fakeValidationRequest(url)).To(Equal(http.StatusOK))
+				})
+
+				It("should not read the API server for an unknown UUID", func() {
+					webconsolevalidation.APIReader = failingReader{}
+
+					url := "/?uuid=non-existent-uuid&namespace=dummy-namespace"
+					Expect(fakeValidationRequest(url)).To(Equal(http.StatusForbidden))
+				})
+
+			})
 		})
 	})
 }
 
+// failingReader is a client.Reader that fails every read.
+type failingReader struct{}
+
+func (failingReader) Get(context.Context, client.ObjectKey, client.Object, ...client.GetOption) error {
+	return errors.New("unexpected read")
+}
+
+func (failingReader) List(context.Context, client.ObjectList, ...client.ListOption) error {
+	return errors.New("unexpected read")
+}
+
 // fakeValidationRequest is a helper function to make a fake validation request.
 // It returns the response code from the server.
 func fakeValidationRequest(url string) int {
+	return fakeRequest(webconsolevalidation.HandleWebConsoleValidation, url)
+}
+
+// fakeRequest is a helper function to make a fake request to the handler.
+// It returns the response code from the handler.
+func fakeRequest(handler http.HandlerFunc, url string) int {
+	return fakeRequestFrom(handler, url, "")
+}
+
+// fakeRequestFrom is a helper function to make a fake request to the handler
+// from the given remote address. It returns the response code from the handler.
+func fakeRequestFrom(handler http.HandlerFunc, url, remoteAddr string) int {
 	responseRecorder := httptest.NewRecorder()
-	handler := http.HandlerFunc(webconsolevalidation.HandleWebConsoleValidation)
 	testRequest, _ := http.NewRequest("GET", url, nil)
+	testRequest.RemoteAddr = remoteAddr
 	handler.ServeHTTP(responseRecorder, testRequest)
 	response := responseRecorder.Result()
 	_ = response.Body.Close()
@@ -0,0 +1,413 @@
+// Copyright (c) 2023 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package webconsolevalidation
+
+import (
+	"bufio"
+	"crypto/rand"
+	"errors"
+	"io"
+	"net"
+	"sync"
+
+	ctrllog "sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// The telnet commands and options, and the VMware telnet extension suboptions, that an ESXi host uses to
+// connect the stream of a serial port to a virtual serial port concentrator (vSPC).
+const (
+	telnetSE   byte = 240
+	telnetSB   byte = 250
+	telnetWILL byte = 251
+	telnetWONT byte = 252
+	telnetDO   byte = 253
+	telnetDONT byte = 254
+	telnetIAC  byte = 255
+
+	telnetBinary byte = 0
+	telnetSGA    byte = 3
+
+	vmwareExt byte = 232
+
+	vmwareKnownSuboptions1 byte = 0
+	vmwareKnownSuboptions2 byte = 1
+	vmwareVMotionBegin     byte = 40
+	vmwareVMotionGoAhead   byte = 41
+	vmwareVMotionPeer      byte = 44
+	vmwareVMotionPeerOK    byte = 45
+	vmwareVMotionComplete  byte = 46
+	vmwareVMotionAbort     byte = 48
+	vmwareDoProxy          byte = 70
+	vmwareWillProxy        byte = 71
+
+	vmotionSecretLength = 4
+)
+
+var (
+	// ErrSerialPortNotConnected is returned by SerialPortConcentrator.Attach when no ESXi host has connected
+	// the serial port with the service URI, for example because the VM is powered off.
+	ErrSerialPortNotConnected = errors.New("the serial port is not connected to the vSPC")
+
+	// ErrSerialPortInUse is returned by SerialPortConcentrator.Attach when a stream is already attached to the
+	// serial port with the service URI.
+	ErrSerialPortInUse = errors.New("the serial port is already streamed")
+)
+
+// Concentrator is the vSPC whose serial ports are streamed by HandleSerialConsole. When nil, serial console
+// streams are not available.
+var Concentrator *SerialPortConcentrator
+
+// SerialPortConcentrator is a virtual serial port concentrator (vSPC). The ESXi hosts connect the VMs' serial
+// ports whose proxy URI is the address of the vSPC to it, and identify each serial port by its service URI.
+// A serial console stream is attached to a serial port by its service URI, so the serial ports do not listen
+// on a network port of the ESXi hosts.
+type SerialPortConcentrator struct {
+	mu       sync.Mutex
+	ports    map[string]*vspcConn
+	vmotions map[string]string
+}
+
+// NewSerialPortConcentrator returns a new SerialPortConcentrator.
+func NewSerialPortConcentrator() *SerialPortConcentrator {
+	return &SerialPortConcentrator{
+		ports:    map[string]*vspcConn{},
+		vmotions: map[string]string{},
+	}
+}
+
+// Serve accepts the connections of the ESXi hosts on the listener until it is closed.
+func (c *SerialPortConcentrator) Serve(listener net.Listener) error {
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			return err
+		}
+		go c.handleConn(conn)
+	}
+}
+
+// Attach returns a stream of the serial port with the given service URI. Reads return the output of the serial
+// port, and writes are the input of the serial port. The stream ends when the ESXi host closes the connection
+// of the serial port, such as when the VM is powered off or migrated to another host. Only one stream may be
+// attached to a serial port at a time, until it is closed.
+func (c *SerialPortConcentrator) Attach(serviceURI string) (io.ReadWriteCloser, error) {
+	c.mu.Lock()
+	vc := c.ports[serviceURI]
+	c.mu.Unlock()
+
+	if vc == nil {
+		return nil, ErrSerialPortNotConnected
+	}
+
+	vc.mu.Lock()
+	defer vc.mu.Unlock()
+
+	if vc.closed {
+		return nil, ErrSerialPortNotConnected
+	}
+	if vc.stream != nil {
+		return nil, ErrSerialPortInUse
+	}
+
+	pr, pw := io.Pipe()
+	vc.stream = pw
+	return &vspcStream{vc: vc, pr: pr, pw: pw}, nil
+}
+
+func (c *SerialPortConcentrator) register(vc *vspcConn, serviceURI string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	// The connection of the host a VM was migrated to replaces the connection of the host it was migrated from.
+	vc.serviceURI = serviceURI
+	c.ports[serviceURI] = vc
+}
+
+func (c *SerialPortConcentrator) unregister(vc *vspcConn) {
+	c.mu.Lock()
+	if vc.serviceURI != "" && c.ports[vc.serviceURI] == vc {
+		delete(c.ports, vc.serviceURI)
+	}
+	c.mu.Unlock()
+
+	vc.close()
+}
+
+func (c *SerialPortConcentrator) handleConn(conn net.Conn) {
+	logger := ctrllog.Log.WithName("vspc").WithValues("remoteAddr", conn.RemoteAddr().String())
+
+	vc := &vspcConn{conn: conn}
+	defer func() {
+		c.unregister(vc)
+		_ = conn.Close()
+	}()
+
+	if err := vc.writeCommands(
+		[]byte{telnetDO, vmwareExt},
+		[]byte{telnetWILL, telnetBinary},
+		[]byte{telnetDO, telnetBinary},
+		[]byte{telnetWILL, telnetSGA},
+		[]byte{telnetDO, telnetSGA}); err != nil {
+		return
+	}
+
+	r := bufio.NewReader(conn)
+	var data []byte
+
+	for {
+		if len(data) > 0 && r.Buffered() == 0 {
+			vc.forward(data)
+			data = data[:0]
+		}
+
+		b, err := r.ReadByte()
+		if err != nil {
+			return
+		}
+
+		if b != telnetIAC {
+			data = append(data, b)
+			continue
+		}
+
+		cmd, err := r.ReadByte()
+		if err != nil {
+			return
+		}
+
+		switch cmd {
+		case telnetIAC:
+			data = append(data, telnetIAC)
+
+		case telnetWILL, telnetWONT, telnetDO, telnetDONT:
+			opt, err := r.ReadByte()
+			if err != nil {
+				return
+			}
+			if err := vc.negotiate(cmd, opt); err != nil {
+				return
+			}
+
+		case telnetSB:
+			payload, err := readSubnegotiation(r)
+			if err != nil {
+				return
+			}
+			if len(payload) < 2 || payload[0] != vmwareExt {
+				continue
+			}
+			if err := c.handleVMwareExt(vc, payload[1], payload[2:]); err != nil {
+				return
+			}
+			if payload[1] == vmwareDoProxy {
+				logger.Info("Serial port connected", "serviceURI", vc.serviceURI)
+			}
+		}
+	}
+}
+
+// handleVMwareExt handles a VMware telnet extension suboption sent by the ESXi host.
+func (c *SerialPortConcentrator) handleVMwareExt(vc *vspcConn, subopt byte, data []byte) error {
+	switch subopt {
+	case vmwareDoProxy:
+		// The first byte is the direction of the serial port, and the rest is its service URI.
+		if len(data) < 2 {
+			return nil
+		}
+		c.register(vc, string(data[1:]))
+		return vc.writeVMwareExt(vmwareWillProxy, nil)
+
+	case vmwareVMotionBegin:
+		secret := make([]byte, vmotionSecretLength)
+		if _, err := rand.Read(secret); err != nil {
+			return err
+		}
+		reply := append(append([]byte{}, data...), secret...)
+
+		c.mu.Lock()
+		c.vmotions[string(reply)] = vc.serviceURI
+		c.mu.Unlock()
+
+		return vc.writeVMwareExt(vmwareVMotionGoAhead, reply)
+
+	case vmwareVMotionPeer:
+		// The host the VM is migrated to identifies the serial port by the sequence and secret of the migration.
+		c.mu.Lock()
+		serviceURI, ok := c.vmotions[string(data)]
+		delete(c.vmotions, string(data))
+		c.mu.Unlock()
+
+		if !ok {
+			return errors.New("unknown vMotion peer")
+		}
+		c.register(vc, serviceURI)
+		return vc.writeVMwareExt(vmwareVMotionPeerOK, data)
+	}
+
+	return nil
+}
+
+// readSubnegotiation returns the payload of a telnet subnegotiation, up to the IAC SE that ends it.
+func readSubnegotiation(r *bufio.Reader) ([]byte, error) {
+	var payload []byte
+	for {
+		b, err := r.ReadByte()
+		if err != nil {
+			return nil, err
+		}
+		if b != telnetIAC {
+			payload = append(payload, b)
+			continue
+		}
+
+		b, err = r.ReadByte()
+		if err != nil {
+			return nil, err
+		}
+		if b == telnetSE {
+			return payload, nil
+		}
+		payload = append(payload, b)
+	}
+}
+
+// vspcConn is the connection of an ESXi host for the stream of a serial port.
+type vspcConn struct {
+	conn       net.Conn
+	serviceURI string
+
+	writeMu sync.Mutex
+
+	mu     sync.Mutex
+	stream *io.PipeWriter
+	closed bool
+}
+
+// negotiate answers a telnet option negotiation of the ESXi host. The supported options were already requested
+// when the host connected, so only the unsupported options are refused.
+func (vc *vspcConn) negotiate(cmd, opt byte) error {
+	switch cmd {
+	case telnetWILL:
+		switch opt {
+		case vmwareExt:
+			return vc.writeVMwareExt(vmwareKnownSuboptions1, []byte{
+				vmwareKnownSuboptions1, vmwareKnownSuboptions2,
+				vmwareVMotionBegin, vmwareVMotionGoAhead, vmwareVMotionPeer, vmwareVMotionPeerOK,
+				vmwareVMotionComplete, vmwareVMotionAbort,
+				vmwareDoProxy, vmwareWillProxy,
+			})
+		case telnetBinary, telnetSGA:
+			return nil
+		}
+		return vc.writeCommands([]byte{telnetDONT, opt})
+
+	case telnetDO:
+		switch opt {
+		case telnetBinary, telnetSGA:
+			return nil
+		}
+		return vc.writeCommands([]byte{telnetWONT, opt})
+	}
+
+	return nil
+}
+
+// forward writes the output of the serial port to the attached stream, if any. The output is dropped when no
+// stream is attached.
+func (vc *vspcConn) forward(data []byte) {
+	vc.mu.Lock()
+	stream := vc.stream
+	vc.mu.Unlock()
+
+	if stream == nil {
+		return
+	}
+
+	if _, err := stream.Write(data); err != nil {
+		vc.detach(stream)
+	}
+}
+
+func (vc *vspcConn) detach(stream *io.PipeWriter) {
+	vc.mu.Lock()
+	defer vc.mu.Unlock()
+
+	if vc.stream == stream {
+		vc.stream = nil
+	}
+}
+
+func (vc *vspcConn) close() {
+	vc.mu.Lock()
+	defer vc.mu.Unlock()
+
+	vc.closed = true
+	if vc.stream != nil {
+		_ = vc.stream.Close()
+		vc.stream = nil
+	}
+}
+
+// writeData writes the input of the serial port, escaping the telnet IAC bytes.
+func (vc *vspcConn) writeData(data []byte) error {
+	escaped := make([]byte, 0, len(data))
+	for _, b := range data {
+		if b == telnetIAC {
+			escaped = append(escaped, telnetIAC)
+		}
+		escaped = append(escaped, b)
+	}
+	return vc.write(escaped)
+}
+
+func (vc *vspcConn) writeCommands(cmds ...[]byte) error {
+	var b []byte
+	for _, cmd := range cmds {
+		b = append(append(b, telnetIAC), cmd...)
+	}
+	return vc.write(b)
+}
+
+func (vc *vspcConn) writeVMwareExt(subopt byte, data []byte) error {
+	b := []byte{telnetIAC, telnetSB, vmwareExt, subopt}
+	for _, d := range data {
+		if d == telnetIAC {
+			b = append(b, telnetIAC)
+		}
+		b = append(b, d)
+	}
+	return vc.write(append(b, telnetIAC, telnetSE))
+}
+
+func (vc *vspcConn) write(b []byte) error {
+	vc.writeMu.Lock()
+	defer vc.writeMu.Unlock()
+
+	_, err := vc.conn.Write(b)
+	return err
+}
+
+// vspcStream is a stream attached to the serial port of a vspcConn.
+type vspcStream struct {
+	vc *vspcConn
+	pr *io.PipeReader
+	pw *io.PipeWriter
+}
+
+func (s *vspcStream) Read(p []byte) (int, error) {
+	return s.pr.Read(p)
+}
+
+func (s *vspcStream) Write(p []byte) (int, error) {
+	if err := s.vc.writeData(p); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+func (s *vspcStream) Close() error {
+	s.vc.detach(s.pw)
+	_ = s.pw.Close()
+	return s.pr.Close()
+}
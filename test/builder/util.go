@@ -1,4 +1,4 @@
-// Copyright (c) 2019-2022 VMware, Inc. All Rights Reserved.
+// Copyright (c) 2019-2023 VMware, Inc. All Rights Reserved.
 // SPDX-License-Identifier: Apache-2.0
 
 package builder
@@ -390,6 +390,18 @@ func DummyStorageClass() *storagev1.StorageClass {
 	}
 }
 
+func DummyVirtualMachineQuota(name, namespace string, hard corev1.ResourceList) *vmopv1.VirtualMachineQuota {
+	return &vmopv1.VirtualMachineQuota{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: namespace,
+		},
+		Spec: vmopv1.VirtualMachineQuotaSpec{
+			Hard: hard,
+		},
+	}
+}
+
 func DummyResourceQuota(namespace, rlName string) *corev1.ResourceQuota {
 	return &corev1.ResourceQuota{
 		ObjectMeta: metav1.ObjectMeta{
@@ -429,6 +441,19 @@ func DummyWebConsoleRequest(namespace, wcrName, vmName, pubKey string) *vmopv1.W
 	}
 }
 
+func DummySerialConsoleRequest(namespace, scrName, vmName, pubKey string) *vmopv1.SerialConsoleRequest {
+	return &vmopv1.SerialConsoleRequest{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      scrName,
+			Namespace: namespace,
+		},
+		Spec: vmopv1.SerialConsoleRequestSpec{
+			VirtualMachineName: vmName,
+			PublicKey:          pubKey,
+		},
+	}
+}
+
 func WebConsoleRequestKeyPair() (privateKey *rsa.PrivateKey, publicKeyPem string) {
 	privateKey, _ = rsa.GenerateKey(rand.Reader, 2048)
 	publicKey := privateKey.PublicKey
@@ -498,6 +523,72 @@ func DummyVirtualMachinePublishRequest(name, namespace, sourceName, itemName, cl
 	}
 }
 
+func DummyVirtualMachinePublishSchedule(name, namespace, sourceName, itemNamePrefix, clName string) *vmopv1.VirtualMachinePublishSchedule {
+	return &vmopv1.VirtualMachinePublishSchedule{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: namespace,
+		},
+		Spec: vmopv1.VirtualMachinePublishScheduleSpec{
+			Schedule: "0 * * * *",
+			Source: vmopv1.VirtualMachinePublishRequestSource{
+				Name:       sourceName,
+				APIVersion: "vmoperator.vmware.com/v1alpha1",
+				Kind:       "VirtualMachine",
+			},
+			Target: vmopv1.VirtualMachinePublishRequestTarget{
+				Item: vmopv1.VirtualMachinePublishRequestTargetItem{
+					Name: itemNamePrefix,
+				},
+				Location: vmopv1.VirtualMachinePublishRequestTargetLocation{
+					Name:       clName,
+					APIVersion: "imageregistry.vmware.com/v1alpha1",
+					Kind:       "ContentLibrary",
+				},
+			},
+		},
+	}
+}
+
+func DummyVirtualMachineImageImportRequest(name, namespace, url, itemName, clName string) *vmopv1.VirtualMachineImageImportRequest {
+	return &vmopv1.VirtualMachineImageImportRequest{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: namespace,
+		},
+		Spec: vmopv1.VirtualMachineImageImportRequestSpec{
+			Source: vmopv1.VirtualMachineImageImportRequestSource{
+				URL: url,
+			},
+			Target: vmopv1.VirtualMachineImageImportRequestTarget{
+				Item: vmopv1.VirtualMachineImageImportRequestTargetItem{
+					Name: itemName,
+				},
+				Location: vmopv1.VirtualMachineImageImportRequestTargetLocation{
+					Name:       clName,
+					APIVersion: "imageregistry.vmware.com/v1alpha1",
+					Kind:       "ContentLibrary",
+				},
+			},
+		},
+	}
+}
+
+func DummyVirtualMachineMigrationRequest(name, namespace, vmName, zone string) *vmopv1.VirtualMachineMigrationRequest {
+	return &vmopv1.VirtualMachineMigrationRequest{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: namespace,
+		},
+		Spec: vmopv1.VirtualMachineMigrationRequestSpec{
+			VMName: vmName,
+			Target: vmopv1.VirtualMachineMigrationRequestLocation{
+				Zone: zone,
+			},
+		},
+	}
+}
+
 func DummyContentLibrary(name, namespace, uuid string) *imgregv1a1.ContentLibrary {
 	return &imgregv1a1.ContentLibrary{
 		ObjectMeta: metav1.ObjectMeta{
@@ -1,4 +1,4 @@
-// Copyright (c) 2019-2021 VMware, Inc. All Rights Reserved.
+// Copyright (c) 2019-2023 VMware, Inc. All Rights Reserved.
 // SPDX-License-Identifier: Apache-2.0
 
 // Package builder is a comment just to silence the linter
@@ -98,6 +98,19 @@ type VCSimTestConfig struct {
 
 	// WithNetworkEnv is the network environment type.
 	WithNetworkEnv NetworkEnv
+
+	// WithHostVGPUProfiles are the vGPU profiles every host supports.
+	WithHostVGPUProfiles []string
+
+	// WithHostPCIPassthroughDevices are the passthrough enabled PCI devices
+	// added to every host.
+	WithHostPCIPassthroughDevices []HostPCIPassthroughDevice
+}
+
+// HostPCIPassthroughDevice is the vendor and device ID of a PCI device.
+type HostPCIPassthroughDevice struct {
+	VendorID int16
+	DeviceID int16
 }
 
 type TestContextForVCSim struct {
@@ -426,6 +439,29 @@ func (c *TestContextForVCSim) setupVCSim(config VCSimTestConfig) {
 		}
 	}
 
+	if len(config.WithHostVGPUProfiles) > 0 || len(config.WithHostPCIPassthroughDevices) > 0 {
+		for _, obj := range simulator.Map.All("HostSystem") {
+			host, ok := obj.(*simulator.HostSystem)
+			Expect(ok).To(BeTrue())
+
+			host.Config.SharedPassthruGpuTypes = config.WithHostVGPUProfiles
+
+			// The hosts share the backing array of the default PCI devices.
+			pciDevices := append([]types.HostPciDevice{}, host.Hardware.PciDevice...)
+			for i, d := range config.WithHostPCIPassthroughDevices {
+				id := fmt.Sprintf("0000:%02x:00.0", 0x80+i)
+				pciDevices = append(pciDevices, types.HostPciDevice{Id: id, VendorId: d.VendorID, DeviceId: d.DeviceID})
+				host.Config.PciPassthruInfo = append(host.Config.PciPassthruInfo, &types.HostPciPassthruInfo{
+					Id:              id,
+					PassthruEnabled: true,
+					PassthruCapable: true,
+					PassthruActive:  true,
+				})
+			}
+			host.Hardware.PciDevice = pciDevices
+		}
+	}
+
 	// For now just use a DVPG we get for free from vcsim. We can create our own later if needed.
 	c.NetworkRef, err = c.Finder.Network(c, "DC0_DVPG0")
 	Expect(err).ToNot(HaveOccurred())
@@ -449,6 +485,8 @@ func (c *TestContextForVCSim) setupContentLibrary(config VCSimTestConfig) {
 
 	libMgr := library.NewManager(c.RestClient)
 
+	// The library is published so the other vCenters can subscribe to it.
+	published := true
 	libSpec := library.Library{
 		Name: "vmop-content-library",
 		Type: "LOCAL",
@@ -458,6 +496,10 @@ func (c *TestContextForVCSim) setupContentLibrary(config VCSimTestConfig) {
 				Type:        "DATASTORE",
 			},
 		},
+		Publication: &library.Publication{
+			AuthenticationMethod: "NONE",
+			Published:            &published,
+		},
 	}
 
 	clID, err := libMgr.CreateLibrary(c, libSpec)
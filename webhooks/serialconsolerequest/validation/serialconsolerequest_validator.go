@@ -0,0 +1,171 @@
+// Copyright (c) 2023 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package validation
+
+import (
+	"crypto/x509"
+	"encoding/pem"
+	"net/http"
+	"reflect"
+
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/api/validation"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	ctrlmgr "sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	vmopv1 "github.com/vmware-tanzu/vm-operator/api/v1alpha1"
+
+	"github.com/vmware-tanzu/vm-operator/controllers/serialconsolerequest"
+	"github.com/vmware-tanzu/vm-operator/pkg/builder"
+	"github.com/vmware-tanzu/vm-operator/pkg/context"
+	"github.com/vmware-tanzu/vm-operator/webhooks/common"
+)
+
+const (
+	webHookName = "default"
+)
+
+// +kubebuilder:webhook:verbs=create;update,path=/default-validate-vmoperator-vmware-com-v1alpha1-serialconsolerequest,mutating=false,failurePolicy=fail,groups=vmoperator.vmware.com,resources=serialconsolerequests,versions=v1alpha1,name=default.validating.serialconsolerequest.vmoperator.vmware.com,sideEffects=None,admissionReviewVersions=v1;v1beta1
+// +kubebuilder:rbac:groups=vmoperator.vmware.com,resources=serialconsolerequests,verbs=get;list
+// +kubebuilder:rbac:groups=vmoperator.vmware.com,resources=serialconsolerequests/status,verbs=get
+
+// AddToManager adds the webhook to the provided manager.
+func AddToManager(ctx *context.ControllerManagerContext, mgr ctrlmgr.Manager) error {
+	hook, err := builder.NewValidatingWebhook(ctx, mgr, webHookName, NewValidator(mgr.GetClient()))
+	if err != nil {
+		return errors.Wrapf(err, "failed to create serialconsolerequest validation webhook")
+	}
+	mgr.GetWebhookServer().Register(hook.Path, hook)
+	return nil
+}
+
+// NewValidator returns the package's Validator.
+func NewValidator(_ client.Client) builder.Validator {
+	return validator{
+		converter: runtime.DefaultUnstructuredConverter,
+	}
+}
+
+type validator struct {
+	converter runtime.UnstructuredConverter
+}
+
+func (v validator) For() schema.GroupVersionKind {
+	return vmopv1.SchemeGroupVersion.WithKind(reflect.TypeOf(vmopv1.SerialConsoleRequest{}).Name())
+}
+
+func (v validator) ValidateCreate(ctx *context.WebhookRequestContext) admission.Response {
+	scr, err := v.serialConsoleRequestFromUnstructured(ctx.Obj)
+	if err != nil {
+		return webhook.Errored(http.StatusBadRequest, err)
+	}
+
+	var fieldErrs field.ErrorList
+	fieldErrs = append(fieldErrs, v.validateSpec(scr)...)
+
+	validationErrs := make([]string, 0, len(fieldErrs))
+	for _, fieldErr := range fieldErrs {
+		validationErrs = append(validationErrs, fieldErr.Error())
+	}
+
+	return common.BuildValidationResponse(ctx, validationErrs, nil)
+}
+
+func (v validator) ValidateDelete(*context.WebhookRequestContext) admission.Response {
+	return admission.Allowed("")
+}
+
+func (v validator) ValidateUpdate(ctx *context.WebhookRequestContext) admission.Response {
+	scr, err := v.serialConsoleRequestFromUnstructured(ctx.Obj)
+	if err != nil {
+		return webhook.Errored(http.StatusBadRequest, err)
+	}
+
+	oldscr, err := v.serialConsoleRequestFromUnstructured(ctx.OldObj)
+	if err != nil {
+		return webhook.Errored(http.StatusBadRequest, err)
+	}
+
+	var fieldErrs field.ErrorList
+	fieldErrs = append(fieldErrs, v.validateImmutableFields(scr, oldscr)...)
+	fieldErrs = append(fieldErrs, v.validateUUIDLabel(scr, oldscr)...)
+
+	validationErrs := make([]string, 0, len(fieldErrs))
+	for _, fieldErr := range fieldErrs {
+		validationErrs = append(validationErrs, fieldErr.Error())
+	}
+	return common.BuildValidationResponse(ctx, validationErrs, nil)
+}
+
+func (v validator) validateSpec(scr *vmopv1.SerialConsoleRequest) field.ErrorList {
+	var fieldErrs field.ErrorList
+	specPath := field.NewPath("spec")
+
+	if scr.Spec.VirtualMachineName == "" {
+		fieldErrs = append(fieldErrs, field.Required(specPath.Child("virtualMachineName"), ""))
+	}
+	fieldErrs = append(fieldErrs, v.validatePublicKey(specPath.Child("publicKey"), scr.Spec.PublicKey)...)
+
+	return fieldErrs
+}
+
+func (v validator) validatePublicKey(path *field.Path, publicKey string) field.ErrorList {
+	var allErrs field.ErrorList
+
+	if publicKey == "" {
+		allErrs = append(allErrs, field.Required(path, ""))
+		return allErrs
+	}
+
+	block, _ := pem.Decode([]byte(publicKey))
+	if block == nil || block.Type != "PUBLIC KEY" {
+		allErrs = append(allErrs, field.Invalid(path, "", "invalid public key format"))
+		return allErrs
+	}
+	_, err := x509.ParsePKCS1PublicKey(block.Bytes)
+	if err != nil {
+		allErrs = append(allErrs, field.Invalid(path, "", "invalid public key"))
+	}
+
+	return allErrs
+}
+
+func (v validator) validateImmutableFields(scr, oldscr *vmopv1.SerialConsoleRequest) field.ErrorList {
+	var allErrs field.ErrorList
+	specPath := field.NewPath("spec")
+
+	allErrs = append(allErrs, validation.ValidateImmutableField(scr.Spec.VirtualMachineName, oldscr.Spec.VirtualMachineName, specPath.Child("virtualMachineName"))...)
+	allErrs = append(allErrs, validation.ValidateImmutableField(scr.Spec.PublicKey, oldscr.Spec.PublicKey, specPath.Child("publicKey"))...)
+
+	return allErrs
+}
+
+func (v validator) validateUUIDLabel(scr, oldscr *vmopv1.SerialConsoleRequest) field.ErrorList {
+	var allErrs field.ErrorList
+
+	oldUUIDLabelVal := oldscr.Labels[serialconsolerequest.UUIDLabelKey]
+	if oldUUIDLabelVal == "" {
+		return allErrs
+	}
+
+	newUUIDLabelVal := scr.Labels[serialconsolerequest.UUIDLabelKey]
+	labelsPath := field.NewPath("metadata", "labels")
+	allErrs = append(allErrs, validation.ValidateImmutableField(newUUIDLabelVal, oldUUIDLabelVal, labelsPath.Key(serialconsolerequest.UUIDLabelKey))...)
+
+	return allErrs
+}
+
+// serialConsoleRequestFromUnstructured returns the SerialConsoleRequest from the unstructured object.
+func (v validator) serialConsoleRequestFromUnstructured(obj runtime.Unstructured) (*vmopv1.SerialConsoleRequest, error) {
+	scr := &vmopv1.SerialConsoleRequest{}
+	if err := v.converter.FromUnstructured(obj.UnstructuredContent(), scr); err != nil {
+		return nil, err
+	}
+	return scr, nil
+}
@@ -0,0 +1,179 @@
+// Copyright (c) 2023 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package validation_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	vmopv1 "github.com/vmware-tanzu/vm-operator/api/v1alpha1"
+
+	"github.com/vmware-tanzu/vm-operator/controllers/serialconsolerequest"
+	"github.com/vmware-tanzu/vm-operator/test/builder"
+)
+
+func unitTests() {
+	Describe("Invoking ValidateCreate", unitTestsValidateCreate)
+	Describe("Invoking ValidateUpdate", unitTestsValidateUpdate)
+	Describe("Invoking ValidateDelete", unitTestsValidateDelete)
+}
+
+type unitValidatingWebhookContext struct {
+	builder.UnitTestContextForValidatingWebhook
+	scr    *vmopv1.SerialConsoleRequest
+	oldScr *vmopv1.SerialConsoleRequest
+}
+
+func newUnitTestContextForValidatingWebhook(isUpdate bool) *unitValidatingWebhookContext {
+	_, publicKeyPem := builder.WebConsoleRequestKeyPair()
+
+	scr := builder.DummySerialConsoleRequest("some-namespace", "some-name", "some-vm-name", publicKeyPem)
+	scr.Labels = map[string]string{
+		serialconsolerequest.UUIDLabelKey: "some-uuid",
+	}
+	obj, err := builder.ToUnstructured(scr)
+	Expect(err).ToNot(HaveOccurred())
+
+	var oldScr *vmopv1.SerialConsoleRequest
+	var oldObj *unstructured.Unstructured
+
+	if isUpdate {
+		oldScr = scr.DeepCopy()
+		oldObj, err = builder.ToUnstructured(oldScr)
+		Expect(err).ToNot(HaveOccurred())
+	}
+
+	return &unitValidatingWebhookContext{
+		UnitTestContextForValidatingWebhook: *suite.NewUnitTestContextForValidatingWebhook(obj, oldObj),
+		scr:                                 scr,
+		oldScr:                              oldScr,
+	}
+}
+
+func unitTestsValidateCreate() {
+	var (
+		ctx *unitValidatingWebhookContext
+	)
+
+	type createArgs struct {
+		emptyVirtualMachineName bool
+		emptyPublicKey          bool
+		invalidPublicKey        bool
+	}
+
+	validateCreate := func(args createArgs, expectedAllowed bool, expectedReason string) {
+		var err error
+
+		if args.emptyVirtualMachineName {
+			ctx.scr.Spec.VirtualMachineName = ""
+		}
+		if args.emptyPublicKey {
+			ctx.scr.Spec.PublicKey = ""
+		}
+		if args.invalidPublicKey {
+			ctx.scr.Spec.PublicKey = "invalid-public-key"
+		}
+
+		ctx.WebhookRequestContext.Obj, err = builder.ToUnstructured(ctx.scr)
+		Expect(err).ToNot(HaveOccurred())
+
+		response := ctx.ValidateCreate(&ctx.WebhookRequestContext)
+		Expect(response.Allowed).To(Equal(expectedAllowed))
+		if expectedReason != "" {
+			Expect(string(response.Result.Reason)).To(ContainSubstring(expectedReason))
+		}
+	}
+
+	BeforeEach(func() {
+		ctx = newUnitTestContextForValidatingWebhook(false)
+	})
+	AfterEach(func() {
+		ctx = nil
+	})
+
+	DescribeTable("create table", validateCreate,
+		Entry("should allow valid", createArgs{}, true, ""),
+		Entry("should deny empty virtualmachinename", createArgs{emptyVirtualMachineName: true}, false, "spec.virtualMachineName: Required value"),
+		Entry("should deny empty publickey", createArgs{emptyPublicKey: true}, false, "spec.publicKey: Required value"),
+		Entry("should deny invalid publickey", createArgs{invalidPublicKey: true}, false, "spec.publicKey: Invalid value: \"\": invalid public key format"),
+	)
+}
+
+func unitTestsValidateUpdate() {
+	var (
+		ctx *unitValidatingWebhookContext
+	)
+
+	type updateArgs struct {
+		updateVirtualMachineName bool
+		updatePublicKey          bool
+		updateUUIDLabel          bool
+	}
+
+	validateUpdate := func(args updateArgs, expectedAllowed bool, expectedReason string) {
+		var err error
+
+		if args.updateVirtualMachineName {
+			ctx.scr.Spec.VirtualMachineName = "new-vm-name"
+		}
+		if args.updatePublicKey {
+			ctx.scr.Spec.PublicKey = "new-public-key"
+		}
+		if args.updateUUIDLabel {
+			ctx.scr.Labels[serialconsolerequest.UUIDLabelKey] = "new-uuid"
+		}
+
+		ctx.WebhookRequestContext.Obj, err = builder.ToUnstructured(ctx.scr)
+		Expect(err).ToNot(HaveOccurred())
+
+		response := ctx.ValidateUpdate(&ctx.WebhookRequestContext)
+		Expect(response.Allowed).To(Equal(expectedAllowed))
+		if expectedReason != "" {
+			Expect(string(response.Result.Reason)).To(Equal(expectedReason))
+		}
+	}
+
+	BeforeEach(func() {
+		ctx = newUnitTestContextForValidatingWebhook(true)
+	})
+	AfterEach(func() {
+		ctx = nil
+	})
+
+	DescribeTable("update table", validateUpdate,
+		Entry("should allow", updateArgs{}, true, ""),
+		Entry("should deny VirtualMachineName change", updateArgs{updateVirtualMachineName: true}, false, "spec.virtualMachineName: Invalid value: \"new-vm-name\": field is immutable"),
+		Entry("should deny PublicKey change", updateArgs{updatePublicKey: true}, false, "spec.publicKey: Invalid value: \"new-public-key\": field is immutable"),
+		Entry("should deny UUID label change", updateArgs{updateUUIDLabel: true}, false, "metadata.labels[vmoperator.vmware.com/serialconsolerequest-uuid]: Invalid value: \"new-uuid\": field is immutable"),
+	)
+}
+
+func unitTestsValidateDelete() {
+	var (
+		ctx      *unitValidatingWebhookContext
+		response admission.Response
+	)
+
+	BeforeEach(func() {
+		ctx = newUnitTestContextForValidatingWebhook(false)
+	})
+	AfterEach(func() {
+		ctx = nil
+	})
+
+	When("the delete is performed", func() {
+		JustBeforeEach(func() {
+			response = ctx.ValidateDelete(&ctx.WebhookRequestContext)
+		})
+
+		It("should allow the request", func() {
+			Expect(response.Allowed).To(BeTrue())
+			Expect(response.Result).ToNot(BeNil())
+		})
+	})
+}
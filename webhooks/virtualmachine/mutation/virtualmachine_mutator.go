@@ -23,6 +23,7 @@ import (
 	"github.com/vmware-tanzu/vm-operator/pkg/builder"
 	"github.com/vmware-tanzu/vm-operator/pkg/context"
 	"github.com/vmware-tanzu/vm-operator/pkg/lib"
+	"github.com/vmware-tanzu/vm-operator/pkg/vmimage"
 	"github.com/vmware-tanzu/vm-operator/pkg/vmprovider/providers/vsphere/config"
 	"github.com/vmware-tanzu/vm-operator/pkg/vmprovider/providers/vsphere/network"
 )
@@ -35,6 +36,7 @@ const (
 // +kubebuilder:webhook:path=/default-mutate-vmoperator-vmware-com-v1alpha1-virtualmachine,mutating=true,failurePolicy=fail,groups=vmoperator.vmware.com,resources=virtualmachines,verbs=create;update,versions=v1alpha1,name=default.mutating.virtualmachine.vmoperator.vmware.com,sideEffects=None,admissionReviewVersions=v1;v1beta1
 // +kubebuilder:rbac:groups=vmoperator.vmware.com,resources=virtualmachine,verbs=get;list
 // +kubebuilder:rbac:groups=vmoperator.vmware.com,resources=virtualmachine/status,verbs=get
+// +kubebuilder:rbac:groups=vmoperator.vmware.com,resources=virtualmachineimages;clustervirtualmachineimages,verbs=get;list
 
 // AddToManager adds the webhook to the provided manager.
 func AddToManager(ctx *context.ControllerManagerContext, mgr ctrlmgr.Manager) error {
@@ -80,6 +82,11 @@ func (m mutator) Mutate(ctx *context.WebhookRequestContext) admission.Response {
 		if AddDefaultNetworkInterface(ctx, m.client, modified) {
 			wasMutated = true
 		}
+		if ok, err := ResolveImageNameSelector(ctx, m.client, modified); err != nil {
+			return admission.Denied(err.Error())
+		} else if ok {
+			wasMutated = true
+		}
 	case admissionv1.Update:
 		// Prevent someone from setting the Spec.VmMetadata.SecretName
 		// field to an empty string if the field is already set to a
@@ -160,6 +167,25 @@ func AddDefaultNetworkInterface(ctx *context.WebhookRequestContext, client clien
 	return true
 }
 
+// ResolveImageNameSelector replaces a "<channel>@latest" selector in the VM's ImageName with the name of the
+// latest non-deprecated image that matches the selector.
+// Return true if the ImageName is replaced, otherwise return false.
+func ResolveImageNameSelector(ctx *context.WebhookRequestContext, client client.Client, vm *vmopv1.VirtualMachine) (bool, error) {
+	channel, ok := vmimage.ParseSelector(vm.Spec.ImageName)
+	if !ok {
+		return false, nil
+	}
+
+	imageName, err := vmimage.ResolveLatest(ctx, client, vm.Namespace, channel)
+	if err != nil {
+		return false, err
+	}
+
+	ctx.Logger.Info("Resolved image selector", "selector", vm.Spec.ImageName, "imageName", imageName)
+	vm.Spec.ImageName = imageName
+	return true, nil
+}
+
 // getProviderConfigMap is used in e2e tests.
 func getProviderConfigMap(
 	ctx *context.WebhookRequestContext, c client.Client) (string, error) {
@@ -18,6 +18,7 @@ import (
 
 	vmopv1 "github.com/vmware-tanzu/vm-operator/api/v1alpha1"
 	"github.com/vmware-tanzu/vm-operator/pkg/lib"
+	"github.com/vmware-tanzu/vm-operator/pkg/vmimage"
 	"github.com/vmware-tanzu/vm-operator/pkg/vmprovider/providers/vsphere/config"
 	"github.com/vmware-tanzu/vm-operator/test/builder"
 	"github.com/vmware-tanzu/vm-operator/webhooks/virtualmachine/mutation"
@@ -214,4 +215,92 @@ func unitTestsMutating() {
 			})
 		})
 	})
+
+	Describe("ResolveImageNameSelector", func() {
+
+		newImage := func(name, product, version string, labels map[string]string) *vmopv1.VirtualMachineImage {
+			img := &vmopv1.VirtualMachineImage{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:   name,
+					Labels: labels,
+				},
+				Spec: vmopv1.VirtualMachineImageSpec{
+					ProductInfo: vmopv1.VirtualMachineImageProductInfo{
+						Product:     product,
+						Version:     "22.04",
+						FullVersion: version,
+					},
+				},
+			}
+			vmimage.SetProductLabels(img, img.Spec.ProductInfo)
+			return img
+		}
+
+		BeforeEach(func() {
+			Expect(ctx.Client.Create(ctx, newImage("ubuntu-1", "ubuntu", "22.04.1", nil))).To(Succeed())
+			Expect(ctx.Client.Create(ctx, newImage("ubuntu-2", "ubuntu", "22.04.2", nil))).To(Succeed())
+			Expect(ctx.Client.Create(ctx, newImage("ubuntu-3", "ubuntu", "22.04.3",
+				map[string]string{vmopv1.VirtualMachineImageDeprecatedLabel: "true"}))).To(Succeed())
+		})
+
+		It("Should resolve the selector to the latest non-deprecated image", func() {
+			ctx.vm.Spec.ImageName = "ubuntu-22.04@latest"
+			ok, err := mutation.ResolveImageNameSelector(&ctx.WebhookRequestContext, ctx.Client, ctx.vm)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(ok).To(BeTrue())
+			Expect(ctx.vm.Spec.ImageName).To(Equal("ubuntu-2"))
+		})
+
+		It("Should not change an image name", func() {
+			ctx.vm.Spec.ImageName = "ubuntu-1"
+			ok, err := mutation.ResolveImageNameSelector(&ctx.WebhookRequestContext, ctx.Client, ctx.vm)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(ok).To(BeFalse())
+			Expect(ctx.vm.Spec.ImageName).To(Equal("ubuntu-1"))
+		})
+
+		It("Should return an error when no image matches the selector", func() {
+			ctx.vm.Spec.ImageName = "photon@latest"
+			_, err := mutation.ResolveImageNameSelector(&ctx.WebhookRequestContext, ctx.Client, ctx.vm)
+			Expect(err).To(HaveOccurred())
+		})
+
+		When("a VM is created with a selector", func() {
+			var response admission.Response
+
+			JustBeforeEach(func() {
+				ctx.WebhookRequestContext.Op = admissionv1.Create
+				obj, err := builder.ToUnstructured(ctx.vm)
+				Expect(err).ToNot(HaveOccurred())
+				ctx.WebhookRequestContext.Obj = obj
+				response = ctx.Mutate(&ctx.WebhookRequestContext)
+			})
+
+			Context("that matches an image", func() {
+				BeforeEach(func() {
+					ctx.vm.Spec.ImageName = "ubuntu@latest"
+				})
+
+				It("Should pin the image name", func() {
+					Expect(response.Allowed).To(BeTrue())
+					Expect(response.Patches).To(ContainElement(jsonpatch.Operation{
+						Operation: "replace",
+						Path:      "/spec/imageName",
+						Value:     "ubuntu-2",
+					}))
+				})
+			})
+
+			Context("that does not match an image", func() {
+				BeforeEach(func() {
+					ctx.vm.Spec.ImageName = "photon@latest"
+				})
+
+				It("Should deny the request", func() {
+					Expect(response.Allowed).To(BeFalse())
+					Expect(string(response.Result.Reason)).To(ContainSubstring(`no non-deprecated VirtualMachineImage matches "photon@latest"`))
+				})
+			})
+		})
+	})
 }
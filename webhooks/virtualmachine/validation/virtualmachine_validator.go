@@ -15,6 +15,7 @@ import (
 	"k8s.io/apimachinery/pkg/api/equality"
 	"k8s.io/apimachinery/pkg/api/resource"
 	"k8s.io/apimachinery/pkg/api/validation"
+	unversionedvalidation "k8s.io/apimachinery/pkg/apis/meta/v1/validation"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/util/validation/field"
@@ -34,9 +35,11 @@ import (
 	"github.com/vmware-tanzu/vm-operator/pkg/context"
 	"github.com/vmware-tanzu/vm-operator/pkg/lib"
 	"github.com/vmware-tanzu/vm-operator/pkg/topology"
+	"github.com/vmware-tanzu/vm-operator/pkg/vmimage"
 	"github.com/vmware-tanzu/vm-operator/pkg/vmprovider/providers/vsphere/config"
 	"github.com/vmware-tanzu/vm-operator/pkg/vmprovider/providers/vsphere/instancestorage"
 	"github.com/vmware-tanzu/vm-operator/pkg/vmprovider/providers/vsphere/network"
+	"github.com/vmware-tanzu/vm-operator/pkg/vmquota"
 	"github.com/vmware-tanzu/vm-operator/webhooks/common"
 )
 
@@ -57,11 +60,16 @@ const (
 	addingModifyingInstanceVolumesNotAllowed  = "adding or modifying instance storage volume claim(s) is not allowed"
 	metadataTransportResourcesInvalid         = "%s and %s cannot be specified simultaneously"
 	featureNotEnabled                         = "the %s feature is not enabled"
+	imageSelectorNotResolved                  = "image selector could not be resolved to a VirtualMachineImage"
+	imageDeprecatedWarningFmt                 = "VirtualMachineImage %s is deprecated"
+	quotaExceededFmt                          = "exceeded quota: %s, requested: %s, used: %s, limited: %s"
 )
 
 // +kubebuilder:webhook:verbs=create;update,path=/default-validate-vmoperator-vmware-com-v1alpha1-virtualmachine,mutating=false,failurePolicy=fail,groups=vmoperator.vmware.com,resources=virtualmachines,versions=v1alpha1,name=default.validating.virtualmachine.vmoperator.vmware.com,sideEffects=None,admissionReviewVersions=v1;v1beta1
 // +kubebuilder:rbac:groups=vmoperator.vmware.com,resources=virtualmachines,verbs=get;list
 // +kubebuilder:rbac:groups=vmoperator.vmware.com,resources=virtualmachines/status,verbs=get
+// +kubebuilder:rbac:groups=vmoperator.vmware.com,resources=virtualmachineimages;clustervirtualmachineimages,verbs=get;list
+// +kubebuilder:rbac:groups=vmoperator.vmware.com,resources=virtualmachineclasses;virtualmachinequotas,verbs=get;list
 
 // AddToManager adds the webhook to the provided manager.
 func AddToManager(ctx *context.ControllerManagerContext, mgr ctrlmgr.Manager) error {
@@ -104,19 +112,27 @@ func (v validator) ValidateCreate(ctx *context.WebhookRequestContext) admission.
 	fieldErrs = append(fieldErrs, v.validateAvailabilityZone(ctx, vm, nil)...)
 	fieldErrs = append(fieldErrs, v.validateImage(ctx, vm)...)
 	fieldErrs = append(fieldErrs, v.validateClass(ctx, vm)...)
+	fieldErrs = append(fieldErrs, v.validateQuota(ctx, vm)...)
 	fieldErrs = append(fieldErrs, v.validateStorageClass(ctx, vm)...)
 	fieldErrs = append(fieldErrs, v.validateNetwork(ctx, vm)...)
 	fieldErrs = append(fieldErrs, v.validateVolumes(ctx, vm)...)
 	fieldErrs = append(fieldErrs, v.validateVMVolumeProvisioningOptions(ctx, vm)...)
 	fieldErrs = append(fieldErrs, v.validateReadinessProbe(ctx, vm)...)
 	fieldErrs = append(fieldErrs, v.validateInstanceStorageVolumes(ctx, vm, nil)...)
+	fieldErrs = append(fieldErrs, v.validateAffinity(ctx, vm)...)
+	fieldErrs = append(fieldErrs, v.validateTopologySpreadConstraints(ctx, vm)...)
+	fieldErrs = append(fieldErrs, v.validatePriorityClass(ctx, vm, nil)...)
 
 	validationErrs := make([]string, 0, len(fieldErrs))
 	for _, fieldErr := range fieldErrs {
 		validationErrs = append(validationErrs, fieldErr.Error())
 	}
 
-	return common.BuildValidationResponse(ctx, validationErrs, nil)
+	response := common.BuildValidationResponse(ctx, validationErrs, nil)
+	if response.Allowed {
+		response.Warnings = append(response.Warnings, v.imageWarnings(ctx, vm)...)
+	}
+	return response
 }
 
 func (v validator) ValidateDelete(*context.WebhookRequestContext) admission.Response {
@@ -176,13 +192,18 @@ func (v validator) ValidateUpdate(ctx *context.WebhookRequestContext) admission.
 	fieldErrs = append(fieldErrs, v.validateVMVolumeProvisioningOptions(ctx, vm)...)
 	fieldErrs = append(fieldErrs, v.validateReadinessProbe(ctx, vm)...)
 	fieldErrs = append(fieldErrs, v.validateInstanceStorageVolumes(ctx, vm, oldVM)...)
+	fieldErrs = append(fieldErrs, v.validatePriorityClass(ctx, vm, oldVM)...)
 
 	validationErrs := make([]string, 0, len(fieldErrs))
 	for _, fieldErr := range fieldErrs {
 		validationErrs = append(validationErrs, fieldErr.Error())
 	}
 
-	return common.BuildValidationResponse(ctx, validationErrs, nil)
+	response := common.BuildValidationResponse(ctx, validationErrs, nil)
+	if response.Allowed {
+		response.Warnings = append(response.Warnings, v.imageWarnings(ctx, vm)...)
+	}
+	return response
 }
 
 func (v validator) validateMetadata(ctx *context.WebhookRequestContext, vm *vmopv1.VirtualMachine) field.ErrorList {
@@ -219,11 +240,33 @@ func (v validator) validateImage(ctx *context.WebhookRequestContext, vm *vmopv1.
 
 	if imageName == "" {
 		allErrs = append(allErrs, field.Required(imageNamePath, ""))
+	} else if _, ok := vmimage.ParseSelector(imageName); ok {
+		// The mutation webhook replaces the selector with the name of the image it resolves to.
+		allErrs = append(allErrs, field.Invalid(imageNamePath, imageName, imageSelectorNotResolved))
 	}
 
 	return allErrs
 }
 
+// imageWarnings returns the warnings about the VM's image, such as when the image is deprecated.
+func (v validator) imageWarnings(ctx *context.WebhookRequestContext, vm *vmopv1.VirtualMachine) []string {
+	if vm.Spec.ImageName == "" {
+		return nil
+	}
+
+	deprecated, err := vmimage.IsDeprecated(ctx, v.client, vm.Namespace, vm.Spec.ImageName)
+	if err != nil {
+		// The warning is advisory so do not fail the request.
+		ctx.Logger.Error(err, "Failed to check if the VirtualMachineImage is deprecated", "imageName", vm.Spec.ImageName)
+		return nil
+	}
+	if !deprecated {
+		return nil
+	}
+
+	return []string{fmt.Sprintf(imageDeprecatedWarningFmt, vm.Spec.ImageName)}
+}
+
 func (v validator) validateClass(ctx *context.WebhookRequestContext, vm *vmopv1.VirtualMachine) field.ErrorList {
 	var allErrs field.ErrorList
 
@@ -234,6 +277,52 @@ func (v validator) validateClass(ctx *context.WebhookRequestContext, vm *vmopv1.
 	return allErrs
 }
 
+// validateQuota checks that the resources the VM consumes, as derived from its class, do not make the resources
+// consumed by the VMs of the namespace exceed the hard limits of the namespace's VirtualMachineQuotas.
+func (v validator) validateQuota(ctx *context.WebhookRequestContext, vm *vmopv1.VirtualMachine) field.ErrorList {
+	var allErrs field.ErrorList
+
+	classPath := field.NewPath("spec", "className")
+
+	quotas := &vmopv1.VirtualMachineQuotaList{}
+	if err := v.client.List(ctx, quotas, client.InNamespace(vm.Namespace)); err != nil {
+		return append(allErrs, field.InternalError(classPath, err))
+	}
+	if len(quotas.Items) == 0 {
+		return allErrs
+	}
+
+	requested, err := vmquota.VirtualMachineUsage(ctx, v.client, vm)
+	if err != nil {
+		return append(allErrs, field.InternalError(classPath, err))
+	}
+
+	used, err := vmquota.NamespaceUsage(ctx, v.client, vm.Namespace, vm.Name)
+	if err != nil {
+		return append(allErrs, field.InternalError(classPath, err))
+	}
+
+	total := vmquota.Add(used, requested)
+	for _, quota := range quotas.Items {
+		// Only the resources the VM consumes are checked so a VM that consumes none of the resources
+		// of a quota that is already exceeded, for example because it was lowered, can be created.
+		var exceeded []corev1.ResourceName
+		for _, name := range vmquota.Exceeded(quota.Spec.Hard, total) {
+			if quantity := requested[name]; !quantity.IsZero() {
+				exceeded = append(exceeded, name)
+			}
+		}
+
+		if len(exceeded) > 0 {
+			allErrs = append(allErrs, field.Forbidden(classPath, fmt.Sprintf(quotaExceededFmt, quota.Name,
+				vmquota.Format(requested, exceeded), vmquota.Format(used, exceeded),
+				vmquota.Format(quota.Spec.Hard, exceeded))))
+		}
+	}
+
+	return allErrs
+}
+
 func (v validator) validateStorageClass(ctx *context.WebhookRequestContext, vm *vmopv1.VirtualMachine) field.ErrorList {
 	var allErrs field.ErrorList
 
@@ -475,6 +564,101 @@ func (v validator) validateReadinessProbe(ctx *context.WebhookRequestContext, vm
 	return allErrs
 }
 
+func (v validator) validateAffinity(ctx *context.WebhookRequestContext, vm *vmopv1.VirtualMachine) field.ErrorList {
+	var allErrs field.ErrorList
+
+	if vm.Spec.Affinity == nil {
+		return allErrs
+	}
+
+	affinityPath := field.NewPath("spec", "affinity")
+	allErrs = append(allErrs, v.validateAffinityRules(vm.Spec.Affinity.VMAffinity, affinityPath.Child("vmAffinity"))...)
+	allErrs = append(allErrs, v.validateAffinityRules(vm.Spec.Affinity.VMAntiAffinity, affinityPath.Child("vmAntiAffinity"))...)
+
+	return allErrs
+}
+
+func (v validator) validateAffinityRules(rules *vmopv1.VirtualMachineAffinityRules, rulesPath *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+
+	if rules == nil {
+		return allErrs
+	}
+
+	for i, term := range rules.RequiredDuringSchedulingIgnoredDuringExecution {
+		termPath := rulesPath.Child("requiredDuringSchedulingIgnoredDuringExecution").Index(i)
+		allErrs = append(allErrs, v.validateAffinityTerm(term, termPath)...)
+	}
+
+	for i, term := range rules.RequiredDuringSchedulingRequiredDuringExecution {
+		termPath := rulesPath.Child("requiredDuringSchedulingRequiredDuringExecution").Index(i)
+		allErrs = append(allErrs, v.validateAffinityTerm(term, termPath)...)
+		// The zone of a VM does not change after it is placed so only host terms
+		// can be enforced by DRS.
+		if term.TopologyKey == vmopv1.VirtualMachineAffinityZoneTopologyKey {
+			allErrs = append(allErrs, field.NotSupported(termPath.Child("topologyKey"), term.TopologyKey,
+				[]string{vmopv1.VirtualMachineAffinityHostTopologyKey}))
+		}
+	}
+
+	for i, wt := range rules.PreferredDuringSchedulingIgnoredDuringExecution {
+		termPath := rulesPath.Child("preferredDuringSchedulingIgnoredDuringExecution").Index(i)
+		if wt.Weight < 1 || wt.Weight > 100 {
+			allErrs = append(allErrs, field.Invalid(termPath.Child("weight"), wt.Weight, "must be in the range 1-100"))
+		}
+		allErrs = append(allErrs, v.validateAffinityTerm(wt.AffinityTerm, termPath.Child("affinityTerm"))...)
+	}
+
+	return allErrs
+}
+
+func (v validator) validateAffinityTerm(term vmopv1.VirtualMachineAffinityTerm, termPath *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+
+	switch term.TopologyKey {
+	case vmopv1.VirtualMachineAffinityZoneTopologyKey, vmopv1.VirtualMachineAffinityHostTopologyKey:
+	default:
+		allErrs = append(allErrs, field.NotSupported(termPath.Child("topologyKey"), term.TopologyKey,
+			[]string{vmopv1.VirtualMachineAffinityZoneTopologyKey, vmopv1.VirtualMachineAffinityHostTopologyKey}))
+	}
+
+	allErrs = append(allErrs, unversionedvalidation.ValidateLabelSelector(term.LabelSelector,
+		unversionedvalidation.LabelSelectorValidationOptions{}, termPath.Child("labelSelector"))...)
+
+	return allErrs
+}
+
+func (v validator) validateTopologySpreadConstraints(ctx *context.WebhookRequestContext, vm *vmopv1.VirtualMachine) field.ErrorList {
+	var allErrs field.ErrorList
+
+	constraintsPath := field.NewPath("spec", "topologySpreadConstraints")
+
+	for i, constraint := range vm.Spec.TopologySpreadConstraints {
+		constraintPath := constraintsPath.Index(i)
+
+		if constraint.MaxSkew < 1 {
+			allErrs = append(allErrs, field.Invalid(constraintPath.Child("maxSkew"), constraint.MaxSkew, "must be greater than zero"))
+		}
+
+		if constraint.TopologyKey != vmopv1.VirtualMachineAffinityZoneTopologyKey {
+			allErrs = append(allErrs, field.NotSupported(constraintPath.Child("topologyKey"), constraint.TopologyKey,
+				[]string{vmopv1.VirtualMachineAffinityZoneTopologyKey}))
+		}
+
+		switch constraint.WhenUnsatisfiable {
+		case vmopv1.VirtualMachineDoNotSchedule, vmopv1.VirtualMachineScheduleAnyway:
+		default:
+			allErrs = append(allErrs, field.NotSupported(constraintPath.Child("whenUnsatisfiable"), constraint.WhenUnsatisfiable,
+				[]string{string(vmopv1.VirtualMachineDoNotSchedule), string(vmopv1.VirtualMachineScheduleAnyway)}))
+		}
+
+		allErrs = append(allErrs, unversionedvalidation.ValidateLabelSelector(constraint.LabelSelector,
+			unversionedvalidation.LabelSelectorValidationOptions{}, constraintPath.Child("labelSelector"))...)
+	}
+
+	return allErrs
+}
+
 func (v validator) validateUpdatesWhenPoweredOn(ctx *context.WebhookRequestContext, vm, oldVM *vmopv1.VirtualMachine) field.ErrorList {
 	var allErrs field.ErrorList
 
@@ -560,8 +744,13 @@ func (v validator) validateImmutableFields(ctx *context.WebhookRequestContext, v
 
 	allErrs = append(allErrs, validation.ValidateImmutableField(vm.Spec.ImageName, oldVM.Spec.ImageName, specPath.Child("imageName"))...)
 	allErrs = append(allErrs, validation.ValidateImmutableField(vm.Spec.ClassName, oldVM.Spec.ClassName, specPath.Child("className"))...)
-	allErrs = append(allErrs, validation.ValidateImmutableField(vm.Spec.StorageClass, oldVM.Spec.StorageClass, specPath.Child("storageClass"))...)
+	// The StorageClass is updated by a privileged account when the VM is migrated to another StorageClass.
+	if !ctx.IsPrivilegedAccount {
+		allErrs = append(allErrs, validation.ValidateImmutableField(vm.Spec.StorageClass, oldVM.Spec.StorageClass, specPath.Child("storageClass"))...)
+	}
 	allErrs = append(allErrs, validation.ValidateImmutableField(vm.Spec.ResourcePolicyName, oldVM.Spec.ResourcePolicyName, specPath.Child("resourcePolicyName"))...)
+	allErrs = append(allErrs, validation.ValidateImmutableField(vm.Spec.Affinity, oldVM.Spec.Affinity, specPath.Child("affinity"))...)
+	allErrs = append(allErrs, validation.ValidateImmutableField(vm.Spec.TopologySpreadConstraints, oldVM.Spec.TopologySpreadConstraints, specPath.Child("topologySpreadConstraints"))...)
 
 	return allErrs
 }
@@ -575,8 +764,9 @@ func (v validator) validateAvailabilityZone(ctx *context.WebhookRequestContext,
 
 	zoneLabelPath := field.NewPath("metadata", "labels").Key(topology.KubernetesTopologyZoneLabelKey)
 
-	if oldVM != nil {
-		// Once the zone has been set then make sure the field is immutable.
+	// Once the zone has been set then make sure the field is immutable, except to a privileged
+	// account that updates the zone of a migrated VM.
+	if oldVM != nil && !ctx.IsPrivilegedAccount {
 		if oldVal := oldVM.Labels[topology.KubernetesTopologyZoneLabelKey]; oldVal != "" {
 			newVal := vm.Labels[topology.KubernetesTopologyZoneLabelKey]
 			return append(allErrs, validation.ValidateImmutableField(newVal, oldVal, zoneLabelPath)...)
@@ -593,6 +783,36 @@ func (v validator) validateAvailabilityZone(ctx *context.WebhookRequestContext,
 	return allErrs
 }
 
+// validatePriorityClass validates the PriorityClassAnnotation. The priority class orders the queued
+// operations of the VM ahead of the operations of the VMs of other namespaces, so only a privileged
+// account may set or change it.
+func (v validator) validatePriorityClass(ctx *context.WebhookRequestContext, vm, oldVM *vmopv1.VirtualMachine) field.ErrorList {
+	var allErrs field.ErrorList
+
+	annotationPath := field.NewPath("metadata", "annotations").Key(vmopv1.PriorityClassAnnotation)
+
+	value, ok := vm.Annotations[vmopv1.PriorityClassAnnotation]
+	oldValue, oldOk := "", false
+	if oldVM != nil {
+		oldValue, oldOk = oldVM.Annotations[vmopv1.PriorityClassAnnotation]
+	}
+
+	if (value != oldValue || ok != oldOk) && !ctx.IsPrivilegedAccount {
+		return append(allErrs, field.Forbidden(annotationPath, "setting or modifying the priority class is not allowed"))
+	}
+
+	if ok {
+		switch value {
+		case vmopv1.PriorityClassHigh, vmopv1.PriorityClassNormal, vmopv1.PriorityClassLow:
+		default:
+			allErrs = append(allErrs, field.NotSupported(annotationPath, value,
+				[]string{vmopv1.PriorityClassHigh, vmopv1.PriorityClassNormal, vmopv1.PriorityClassLow}))
+		}
+	}
+
+	return allErrs
+}
+
 // vmFromUnstructured returns the VirtualMachine from the unstructured object.
 func (v validator) vmFromUnstructured(obj runtime.Unstructured) (*vmopv1.VirtualMachine, error) {
 	vm := &vmopv1.VirtualMachine{}
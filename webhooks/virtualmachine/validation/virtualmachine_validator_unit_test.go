@@ -182,6 +182,7 @@ func unitTestsValidateCreate() {
 
 	type createArgs struct {
 		invalidImageName                  bool
+		imageSelector                     bool
 		imageNotFound                     bool
 		namespaceImage                    bool
 		clusterImage                      bool
@@ -219,6 +220,16 @@ func unitTestsValidateCreate() {
 		isNamedNetworkProviderEnabled     bool
 		isSysprepFeatureEnabled           bool
 		isSysprepTransportUsed            bool
+		validAffinity                     bool
+		invalidAffinityTopologyKey        bool
+		invalidAffinityWeight             bool
+		invalidAffinityLabelSelector      bool
+		zoneAffinityDuringExecution       bool
+		validTopologySpread               bool
+		invalidTopologySpreadMaxSkew      bool
+		invalidTopologySpreadKey          bool
+		invalidTopologySpreadAction       bool
+		setPriorityClass                  string
 	}
 
 	validateCreate := func(args createArgs, expectedAllowed bool, expectedReason string, expectedErr error) {
@@ -230,6 +241,9 @@ func unitTestsValidateCreate() {
 		if args.invalidImageName {
 			ctx.vm.Spec.ImageName = ""
 		}
+		if args.imageSelector {
+			ctx.vm.Spec.ImageName = "ubuntu@latest"
+		}
 		if args.imageNotFound {
 			ctx.vm.Spec.ImageName = "image-does-not-exist"
 		}
@@ -283,6 +297,58 @@ func unitTestsValidateCreate() {
 			ctx.vm.Spec.VmMetadata.ConfigMapName = "foo"
 			ctx.vm.Spec.VmMetadata.SecretName = "bar"
 		}
+		if args.validAffinity || args.invalidAffinityTopologyKey || args.invalidAffinityWeight ||
+			args.invalidAffinityLabelSelector || args.zoneAffinityDuringExecution {
+			term := vmopv1.VirtualMachineAffinityTerm{
+				LabelSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "db"}},
+				TopologyKey:   vmopv1.VirtualMachineAffinityZoneTopologyKey,
+			}
+			ctx.vm.Spec.Affinity = &vmopv1.VirtualMachineAffinitySpec{
+				VMAntiAffinity: &vmopv1.VirtualMachineAffinityRules{
+					RequiredDuringSchedulingIgnoredDuringExecution: []vmopv1.VirtualMachineAffinityTerm{term},
+					RequiredDuringSchedulingRequiredDuringExecution: []vmopv1.VirtualMachineAffinityTerm{
+						{LabelSelector: term.LabelSelector, TopologyKey: vmopv1.VirtualMachineAffinityHostTopologyKey},
+					},
+					PreferredDuringSchedulingIgnoredDuringExecution: []vmopv1.VirtualMachineWeightedAffinityTerm{
+						{Weight: 50, AffinityTerm: term},
+					},
+				},
+			}
+			rules := ctx.vm.Spec.Affinity.VMAntiAffinity
+			if args.invalidAffinityTopologyKey {
+				rules.RequiredDuringSchedulingIgnoredDuringExecution[0].TopologyKey = "foo"
+			}
+			if args.invalidAffinityWeight {
+				rules.PreferredDuringSchedulingIgnoredDuringExecution[0].Weight = 101
+			}
+			if args.invalidAffinityLabelSelector {
+				rules.RequiredDuringSchedulingIgnoredDuringExecution[0].LabelSelector = &metav1.LabelSelector{
+					MatchExpressions: []metav1.LabelSelectorRequirement{{Key: "app", Operator: "Foo"}},
+				}
+			}
+			if args.zoneAffinityDuringExecution {
+				rules.RequiredDuringSchedulingRequiredDuringExecution[0].TopologyKey = vmopv1.VirtualMachineAffinityZoneTopologyKey
+			}
+		}
+		if args.validTopologySpread || args.invalidTopologySpreadMaxSkew || args.invalidTopologySpreadKey || args.invalidTopologySpreadAction {
+			ctx.vm.Spec.TopologySpreadConstraints = []vmopv1.VirtualMachineTopologySpreadConstraint{
+				{
+					MaxSkew:           1,
+					TopologyKey:       vmopv1.VirtualMachineAffinityZoneTopologyKey,
+					WhenUnsatisfiable: vmopv1.VirtualMachineDoNotSchedule,
+					LabelSelector:     &metav1.LabelSelector{MatchLabels: map[string]string{"app": "db"}},
+				},
+			}
+			if args.invalidTopologySpreadMaxSkew {
+				ctx.vm.Spec.TopologySpreadConstraints[0].MaxSkew = 0
+			}
+			if args.invalidTopologySpreadKey {
+				ctx.vm.Spec.TopologySpreadConstraints[0].TopologyKey = vmopv1.VirtualMachineAffinityHostTopologyKey
+			}
+			if args.invalidTopologySpreadAction {
+				ctx.vm.Spec.TopologySpreadConstraints[0].WhenUnsatisfiable = "Foo"
+			}
+		}
 		if args.invalidVsphereVolumeSource {
 			ctx.vm.Spec.Volumes[0].PersistentVolumeClaim = nil
 			deviceKey := 2000
@@ -338,6 +404,9 @@ func unitTestsValidateCreate() {
 		if args.isServiceUser {
 			ctx.IsPrivilegedAccount = true
 		}
+		if args.setPriorityClass != "" {
+			ctx.vm.Annotations[vmopv1.PriorityClassAnnotation] = args.setPriorityClass
+		}
 		if args.addInstanceStorageVolumes {
 			instanceStorageVolume := builder.DummyInstanceStorageVirtualMachineVolumes()
 			ctx.vm.Spec.Volumes = append(ctx.vm.Spec.Volumes, instanceStorageVolume...)
@@ -405,6 +474,7 @@ func unitTestsValidateCreate() {
 	})
 
 	specPath := field.NewPath("spec")
+	priorityClassPath := field.NewPath("metadata", "annotations").Key(vmopv1.PriorityClassAnnotation)
 	netIntPath := specPath.Child("networkInterfaces")
 	volPath := specPath.Child("volumes")
 
@@ -414,6 +484,8 @@ func unitTestsValidateCreate() {
 			field.Required(specPath.Child("className"), "").Error(), nil),
 		Entry("should deny invalid image name", createArgs{invalidImageName: true}, false,
 			field.Required(specPath.Child("imageName"), "").Error(), nil),
+		Entry("should deny unresolved image selector", createArgs{imageSelector: true}, false,
+			field.Invalid(specPath.Child("imageName"), "ubuntu@latest", "image selector could not be resolved to a VirtualMachineImage").Error(), nil),
 		Entry("should allow namespace image that exists, when ImageRegistry FSS is enabled", createArgs{isWCPVMImageRegistryEnabled: true, namespaceImage: true}, true, nil, nil),
 		Entry("should allow cluster image that exists, when ImageRegistry FSS is enabled", createArgs{isWCPVMImageRegistryEnabled: true, clusterImage: true}, true, nil, nil),
 		Entry("should fail when Readiness probe has multiple actions", createArgs{invalidReadinessProbe: true}, false,
@@ -479,18 +551,135 @@ func unitTestsValidateCreate() {
 			field.Forbidden(volPath, "adding or modifying instance storage volume claim(s) is not allowed").Error(), nil),
 		Entry("should allow when there are instance storage volumes and user is service user", createArgs{addInstanceStorageVolumes: true, isServiceUser: true}, true, nil, nil),
 
+		Entry("should deny priority class, when user is SSO user", createArgs{setPriorityClass: vmopv1.PriorityClassHigh}, false,
+			field.Forbidden(priorityClassPath, "setting or modifying the priority class is not allowed").Error(), nil),
+		Entry("should allow priority class, when user type is service user", createArgs{setPriorityClass: vmopv1.PriorityClassHigh, isServiceUser: true}, true, nil, nil),
+		Entry("should deny invalid priority class, when user type is service user", createArgs{setPriorityClass: "Urgent", isServiceUser: true}, false,
+			field.NotSupported(priorityClassPath, "Urgent", []string{vmopv1.PriorityClassHigh, vmopv1.PriorityClassNormal, vmopv1.PriorityClassLow}).Error(), nil),
+
 		Entry("should allow empty network type when named networking enabled", createArgs{isNamedNetworkProviderUsed: true, isNamedNetworkProviderEnabled: true}, true, nil, nil),
 		Entry("should disallow empty network type when named networking disabled", createArgs{isNamedNetworkProviderUsed: true, isNamedNetworkProviderEnabled: false}, false, errInvalidNetworkProviderTypeNamed.Error(), nil),
 		Entry("should allow sysprep when FSS is enabled", createArgs{isSysprepFeatureEnabled: true, isSysprepTransportUsed: true}, true, nil, nil),
 		Entry("should disallow sysprep when FSS is disabled", createArgs{isSysprepFeatureEnabled: false, isSysprepTransportUsed: true}, false,
 			field.Invalid(specPath.Child("vmMetadata", "transport"), "Sysprep", "the Sysprep feature is not enabled").Error(), nil),
 		Entry("should not error if sysprep FSS is disabled when sysprep is not used", createArgs{isSysprepFeatureEnabled: false, isSysprepTransportUsed: false}, true, nil, nil),
+
+		Entry("should allow valid affinity", createArgs{validAffinity: true}, true, nil, nil),
+		Entry("should deny invalid affinity topology key", createArgs{invalidAffinityTopologyKey: true}, false,
+			field.NotSupported(specPath.Child("affinity", "vmAntiAffinity", "requiredDuringSchedulingIgnoredDuringExecution").Index(0).Child("topologyKey"), "foo",
+				[]string{vmopv1.VirtualMachineAffinityZoneTopologyKey, vmopv1.VirtualMachineAffinityHostTopologyKey}).Error(), nil),
+		Entry("should deny invalid affinity weight", createArgs{invalidAffinityWeight: true}, false,
+			field.Invalid(specPath.Child("affinity", "vmAntiAffinity", "preferredDuringSchedulingIgnoredDuringExecution").Index(0).Child("weight"), 101, "must be in the range 1-100").Error(), nil),
+		Entry("should deny invalid affinity label selector", createArgs{invalidAffinityLabelSelector: true}, false,
+			"spec.affinity.vmAntiAffinity.requiredDuringSchedulingIgnoredDuringExecution[0].labelSelector.matchExpressions[0].operator", nil),
+		Entry("should deny zone affinity that is required during execution", createArgs{zoneAffinityDuringExecution: true}, false,
+			field.NotSupported(specPath.Child("affinity", "vmAntiAffinity", "requiredDuringSchedulingRequiredDuringExecution").Index(0).Child("topologyKey"), vmopv1.VirtualMachineAffinityZoneTopologyKey,
+				[]string{vmopv1.VirtualMachineAffinityHostTopologyKey}).Error(), nil),
+
+		Entry("should allow valid topology spread constraints", createArgs{validTopologySpread: true}, true, nil, nil),
+		Entry("should deny invalid topology spread constraint max skew", createArgs{invalidTopologySpreadMaxSkew: true}, false,
+			field.Invalid(specPath.Child("topologySpreadConstraints").Index(0).Child("maxSkew"), 0, "must be greater than zero").Error(), nil),
+		Entry("should deny invalid topology spread constraint topology key", createArgs{invalidTopologySpreadKey: true}, false,
+			field.NotSupported(specPath.Child("topologySpreadConstraints").Index(0).Child("topologyKey"), vmopv1.VirtualMachineAffinityHostTopologyKey,
+				[]string{vmopv1.VirtualMachineAffinityZoneTopologyKey}).Error(), nil),
+		Entry("should deny invalid topology spread constraint action", createArgs{invalidTopologySpreadAction: true}, false,
+			field.NotSupported(specPath.Child("topologySpreadConstraints").Index(0).Child("whenUnsatisfiable"), "Foo",
+				[]string{"DoNotSchedule", "ScheduleAnyway"}).Error(), nil),
 	)
+
+	When("the image is deprecated", func() {
+		BeforeEach(func() {
+			ctx.vmImage.Labels = map[string]string{vmopv1.VirtualMachineImageDeprecatedLabel: "true"}
+			Expect(ctx.Client.Update(ctx, ctx.vmImage)).To(Succeed())
+		})
+
+		It("should allow the request with a warning", func() {
+			response := ctx.ValidateCreate(&ctx.WebhookRequestContext)
+			Expect(response.Allowed).To(BeTrue())
+			Expect(response.Warnings).To(ConsistOf("VirtualMachineImage " + ctx.vm.Spec.ImageName + " is deprecated"))
+		})
+	})
+
+	When("the image is not deprecated", func() {
+		It("should allow the request without a warning", func() {
+			response := ctx.ValidateCreate(&ctx.WebhookRequestContext)
+			Expect(response.Allowed).To(BeTrue())
+			Expect(response.Warnings).To(BeEmpty())
+		})
+	})
+
+	When("the namespace has a VirtualMachineQuota", func() {
+		var vmQuota *vmopv1.VirtualMachineQuota
+
+		BeforeEach(func() {
+			vmClass := builder.DummyVirtualMachineClass()
+			vmClass.Name = ctx.vm.Spec.ClassName
+			Expect(ctx.Client.Create(ctx, vmClass)).To(Succeed())
+
+			otherVM := builder.DummyVirtualMachine()
+			otherVM.Name = "other-vm"
+			otherVM.Namespace = ctx.vm.Namespace
+			Expect(ctx.Client.Create(ctx, otherVM)).To(Succeed())
+
+			vmQuota = builder.DummyVirtualMachineQuota("dummy-quota", ctx.vm.Namespace, corev1.ResourceList{
+				vmopv1.VirtualMachineQuotaResourceCPU:    resource.MustParse("4"),
+				vmopv1.VirtualMachineQuotaResourceMemory: resource.MustParse("8Gi"),
+			})
+		})
+
+		JustBeforeEach(func() {
+			Expect(ctx.Client.Create(ctx, vmQuota)).To(Succeed())
+		})
+
+		It("should allow the request when the quota is not exceeded", func() {
+			response := ctx.ValidateCreate(&ctx.WebhookRequestContext)
+			Expect(response.Allowed).To(BeTrue())
+		})
+
+		When("the quota is exceeded", func() {
+			BeforeEach(func() {
+				vmQuota.Spec.Hard[vmopv1.VirtualMachineQuotaResourceCPU] = resource.MustParse("3")
+				vmQuota.Spec.Hard[vmopv1.VirtualMachineQuotaResourceMemory] = resource.MustParse("6Gi")
+			})
+
+			It("should deny the request", func() {
+				response := ctx.ValidateCreate(&ctx.WebhookRequestContext)
+				Expect(response.Allowed).To(BeFalse())
+				Expect(string(response.Result.Reason)).To(Equal(field.Forbidden(specPath.Child("className"),
+					"exceeded quota: dummy-quota, requested: cpu=2,memory=4Gi, used: cpu=2,memory=4Gi, limited: cpu=3,memory=6Gi").Error()))
+			})
+		})
+
+		When("the quota of a resource the VM does not consume is exceeded", func() {
+			BeforeEach(func() {
+				vGPUClass := builder.DummyVirtualMachineClass()
+				vGPUClass.Name = "vgpu-class"
+				vGPUClass.Spec.Hardware.Devices.VGPUDevices = []vmopv1.VGPUDevice{{ProfileName: "grid_p40-8q"}}
+				Expect(ctx.Client.Create(ctx, vGPUClass)).To(Succeed())
+
+				vGPUVM := builder.DummyVirtualMachine()
+				vGPUVM.Name = "vgpu-vm"
+				vGPUVM.Namespace = ctx.vm.Namespace
+				vGPUVM.Spec.ClassName = vGPUClass.Name
+				Expect(ctx.Client.Create(ctx, vGPUVM)).To(Succeed())
+
+				vmQuota.Spec.Hard[vmopv1.VirtualMachineQuotaResourceCPU] = resource.MustParse("8")
+				vmQuota.Spec.Hard[vmopv1.VirtualMachineQuotaResourceMemory] = resource.MustParse("16Gi")
+				vmQuota.Spec.Hard[vmopv1.VirtualMachineQuotaResourceVGPUs] = resource.MustParse("0")
+			})
+
+			It("should allow the request", func() {
+				response := ctx.ValidateCreate(&ctx.WebhookRequestContext)
+				Expect(response.Allowed).To(BeTrue())
+			})
+		})
+	})
 }
 
 func unitTestsValidateUpdate() {
 	var (
-		ctx *unitValidatingWebhookContext
+		ctx                 *unitValidatingWebhookContext
+		oldFaultDomainsFunc func() bool
 	)
 
 	type updateArgs struct {
@@ -507,6 +696,10 @@ func unitTestsValidateUpdate() {
 		isNamedNetworkProviderEnabled   bool
 		isSysprepFeatureEnabled         bool
 		isSysprepTransportUsed          bool
+		changeAffinity                  bool
+		changeTopologySpread            bool
+		isWCPFaultDomainsFSSEnabled     bool
+		changePriorityClass             bool
 	}
 
 	validateUpdate := func(args updateArgs, expectedAllowed bool, expectedReason string, expectedErr error) {
@@ -524,17 +717,44 @@ func unitTestsValidateUpdate() {
 		if args.changeResourcePolicy {
 			ctx.vm.Spec.ResourcePolicyName = updateSuffix
 		}
+		if args.changeTopologySpread {
+			ctx.vm.Spec.TopologySpreadConstraints = []vmopv1.VirtualMachineTopologySpreadConstraint{
+				{
+					MaxSkew:           1,
+					TopologyKey:       vmopv1.VirtualMachineAffinityZoneTopologyKey,
+					WhenUnsatisfiable: vmopv1.VirtualMachineScheduleAnyway,
+				},
+			}
+		}
+		if args.changeAffinity {
+			ctx.vm.Spec.Affinity = &vmopv1.VirtualMachineAffinitySpec{
+				VMAffinity: &vmopv1.VirtualMachineAffinityRules{
+					RequiredDuringSchedulingIgnoredDuringExecution: []vmopv1.VirtualMachineAffinityTerm{
+						{TopologyKey: vmopv1.VirtualMachineAffinityZoneTopologyKey},
+					},
+				},
+			}
+		}
 		if args.assignZoneName {
 			ctx.vm.Labels[topology.KubernetesTopologyZoneLabelKey] = builder.DummyAvailabilityZoneName
 		}
 		if args.changeZoneName {
-			ctx.oldVM.Labels[topology.KubernetesTopologyZoneLabelKey] = builder.DummyAvailabilityZoneName
-			ctx.vm.Labels[topology.KubernetesTopologyZoneLabelKey] = builder.DummyAvailabilityZoneName + updateSuffix
+			ctx.oldVM.Labels[topology.KubernetesTopologyZoneLabelKey] = builder.DummyAvailabilityZoneName + updateSuffix
+			ctx.vm.Labels[topology.KubernetesTopologyZoneLabelKey] = builder.DummyAvailabilityZoneName
+		}
+
+		if args.changePriorityClass {
+			ctx.oldVM.Annotations[vmopv1.PriorityClassAnnotation] = vmopv1.PriorityClassLow
+			ctx.vm.Annotations[vmopv1.PriorityClassAnnotation] = vmopv1.PriorityClassHigh
 		}
 
 		if args.isServiceUser {
 			ctx.IsPrivilegedAccount = true
 		}
+		// Please note this prevents the unit tests from running safely in parallel.
+		lib.IsWcpFaultDomainsFSSEnabled = func() bool {
+			return args.isWCPFaultDomainsFSSEnabled
+		}
 		if args.addInstanceStorageVolume {
 			instanceStorageVolumes := builder.DummyInstanceStorageVirtualMachineVolumes()
 			ctx.vm.Spec.Volumes = append(ctx.vm.Spec.Volumes, instanceStorageVolumes...)
@@ -576,14 +796,17 @@ func unitTestsValidateUpdate() {
 
 	BeforeEach(func() {
 		ctx = newUnitTestContextForValidatingWebhook(true)
+		oldFaultDomainsFunc = lib.IsWcpFaultDomainsFSSEnabled
 	})
 
 	AfterEach(func() {
+		lib.IsWcpFaultDomainsFSSEnabled = oldFaultDomainsFunc
 		ctx = nil
 	})
 
 	msg := "field is immutable"
 	volumesPath := field.NewPath("spec", "volumes")
+	priorityClassPath := field.NewPath("metadata", "annotations").Key(vmopv1.PriorityClassAnnotation)
 
 	DescribeTable("update table", validateUpdate,
 		// Immutable Fields
@@ -592,14 +815,22 @@ func unitTestsValidateUpdate() {
 		Entry("should deny image name change", updateArgs{changeImageName: true}, false, msg, nil),
 		Entry("should deny storageClass change", updateArgs{changeStorageClass: true}, false, msg, nil),
 		Entry("should deny resourcePolicy change", updateArgs{changeResourcePolicy: true}, false, msg, nil),
+		Entry("should deny affinity change", updateArgs{changeAffinity: true}, false, msg, nil),
+		Entry("should deny topology spread constraints change", updateArgs{changeTopologySpread: true}, false, msg, nil),
 		Entry("should allow initial zone assignment", updateArgs{assignZoneName: true}, true, nil, nil),
 		Entry("should allow zone name change when WCP FaultDomains FSS is disabled", updateArgs{changeZoneName: true}, true, nil, nil),
+		Entry("should deny zone name change when WCP FaultDomains FSS is enabled", updateArgs{changeZoneName: true, isWCPFaultDomainsFSSEnabled: true}, false, msg, nil),
+		Entry("should allow zone name change when WCP FaultDomains FSS is enabled, when user type is service user", updateArgs{changeZoneName: true, isWCPFaultDomainsFSSEnabled: true, isServiceUser: true}, true, nil, nil),
+		Entry("should allow storageClass change, when user type is service user", updateArgs{changeStorageClass: true, isServiceUser: true}, true, nil, nil),
 		Entry("should deny instance storage volume name change, when user is SSO user", updateArgs{changeInstanceStorageVolumeName: true}, false,
 			field.Forbidden(volumesPath, "adding or modifying instance storage volume claim(s) is not allowed").Error(), nil),
 		Entry("should deny adding new instance storage volume, when user is SSO user", updateArgs{addInstanceStorageVolume: true}, false,
 			field.Forbidden(volumesPath, "adding or modifying instance storage volume claim(s) is not allowed").Error(), nil),
 		Entry("should allow adding new instance storage volume, when user type is service user", updateArgs{addInstanceStorageVolume: true, isServiceUser: true}, true, nil, nil),
 		Entry("should allow instance storage volume name change, when user type is service user", updateArgs{changeInstanceStorageVolumeName: true, isServiceUser: true}, true, nil, nil),
+		Entry("should deny priority class change, when user is SSO user", updateArgs{changePriorityClass: true}, false,
+			field.Forbidden(priorityClassPath, "setting or modifying the priority class is not allowed").Error(), nil),
+		Entry("should allow priority class change, when user type is service user", updateArgs{changePriorityClass: true, isServiceUser: true}, true, nil, nil),
 
 		Entry("should allow empty network type when named networking enabled", updateArgs{isNamedNetworkProviderUsed: true, isNamedNetworkProviderEnabled: true}, true, nil, nil),
 		Entry("should disallow empty network type when named networking disabled", updateArgs{isNamedNetworkProviderUsed: true, isNamedNetworkProviderEnabled: false}, false, errInvalidNetworkProviderTypeNamed.Error(), nil),
@@ -618,6 +849,19 @@ func unitTestsValidateUpdate() {
 			Expect(response.Result).ToNot(BeNil())
 		})
 	})
+
+	When("the image is deprecated", func() {
+		BeforeEach(func() {
+			ctx.vmImage.Labels = map[string]string{vmopv1.VirtualMachineImageDeprecatedLabel: "true"}
+			Expect(ctx.Client.Update(ctx, ctx.vmImage)).To(Succeed())
+		})
+
+		It("should allow the request with a warning", func() {
+			response := ctx.ValidateUpdate(&ctx.WebhookRequestContext)
+			Expect(response.Allowed).To(BeTrue())
+			Expect(response.Warnings).To(ConsistOf("VirtualMachineImage " + ctx.vm.Spec.ImageName + " is deprecated"))
+		})
+	})
 }
 
 func unitTestsValidateDelete() {
@@ -1,12 +1,16 @@
-// Copyright (c) 2019-2021 VMware, Inc. All Rights Reserved.
+// Copyright (c) 2019-2023 VMware, Inc. All Rights Reserved.
 // SPDX-License-Identifier: Apache-2.0
 
 package validation
 
 import (
+	"bytes"
+	"fmt"
 	"net/http"
 	"reflect"
+	"sort"
 
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
@@ -19,10 +23,16 @@ import (
 
 	"github.com/pkg/errors"
 
+	vimtypes "github.com/vmware/govmomi/vim25/types"
+
 	vmopv1 "github.com/vmware-tanzu/vm-operator/api/v1alpha1"
 
 	"github.com/vmware-tanzu/vm-operator/pkg/builder"
 	"github.com/vmware-tanzu/vm-operator/pkg/context"
+	"github.com/vmware-tanzu/vm-operator/pkg/lib"
+	"github.com/vmware-tanzu/vm-operator/pkg/util"
+	"github.com/vmware-tanzu/vm-operator/pkg/vmprovider/providers/vsphere/virtualmachine"
+	"github.com/vmware-tanzu/vm-operator/pkg/vmquota"
 	"github.com/vmware-tanzu/vm-operator/webhooks/common"
 )
 
@@ -31,11 +41,38 @@ const (
 
 	invalidCPUReqMsg    = "CPU request must not be larger than the CPU limit"
 	invalidMemoryReqMsg = "memory request must not be larger than the memory limit"
+
+	invalidConfigSpecFmt       = "invalid ConfigSpec: %v"
+	configSpecDisksMsg         = "disks are not allowed, use the VirtualMachine's volumes"
+	configSpecNICsMsg          = "network interfaces are not allowed, use the VirtualMachine's network interfaces"
+	configSpecHardwareMismatch = "must be zero or equal to %s of spec.hardware"
+	configSpecCoresPerSocket   = "must be a divisor of the number of CPUs"
+
+	quotaExceededFmt = "exceeded quota: %s/%s, used: %s, limited: %s"
 )
 
+// allowedConfigSpecDeviceTypes are the types of the devices that may be added
+// by a class ConfigSpec. Disk controllers are allowed, but are removed from the
+// ConfigSpec that VMs are created with.
+var allowedConfigSpecDeviceTypes = map[reflect.Type]struct{}{
+	reflect.TypeOf(&vimtypes.VirtualPCIPassthrough{}):        {},
+	reflect.TypeOf(&vimtypes.VirtualMachineVideoCard{}):      {},
+	reflect.TypeOf(&vimtypes.VirtualUSBController{}):         {},
+	reflect.TypeOf(&vimtypes.VirtualUSBXHCIController{}):     {},
+	reflect.TypeOf(&vimtypes.VirtualMachineVMCIDevice{}):     {},
+	reflect.TypeOf(&vimtypes.VirtualIDEController{}):         {},
+	reflect.TypeOf(&vimtypes.VirtualNVMEController{}):        {},
+	reflect.TypeOf(&vimtypes.VirtualAHCIController{}):        {},
+	reflect.TypeOf(&vimtypes.ParaVirtualSCSIController{}):    {},
+	reflect.TypeOf(&vimtypes.VirtualLsiLogicController{}):    {},
+	reflect.TypeOf(&vimtypes.VirtualLsiLogicSASController{}): {},
+	reflect.TypeOf(&vimtypes.VirtualBusLogicController{}):    {},
+}
+
 // +kubebuilder:webhook:verbs=create;update,path=/default-validate-vmoperator-vmware-com-v1alpha1-virtualmachineclass,mutating=false,failurePolicy=fail,groups=vmoperator.vmware.com,resources=virtualmachineclasses,versions=v1alpha1,name=default.validating.virtualmachineclass.vmoperator.vmware.com,sideEffects=None,admissionReviewVersions=v1;v1beta1
 // +kubebuilder:rbac:groups=vmoperator.vmware.com,resources=virtualmachineclasses,verbs=get;list
 // +kubebuilder:rbac:groups=vmoperator.vmware.com,resources=virtualmachineclasses/status,verbs=get
+// +kubebuilder:rbac:groups=vmoperator.vmware.com,resources=virtualmachines;virtualmachinequotas,verbs=get;list
 
 // AddToManager adds the webhook to the provided manager.
 func AddToManager(ctx *context.ControllerManagerContext, mgr ctrlmgr.Manager) error {
@@ -49,13 +86,15 @@ func AddToManager(ctx *context.ControllerManagerContext, mgr ctrlmgr.Manager) er
 }
 
 // NewValidator returns the package's Validator.
-func NewValidator(_ client.Client) builder.Validator {
+func NewValidator(client client.Client) builder.Validator {
 	return validator{
+		client:    client,
 		converter: runtime.DefaultUnstructuredConverter,
 	}
 }
 
 type validator struct {
+	client    client.Client
 	converter runtime.UnstructuredConverter
 }
 
@@ -72,6 +111,7 @@ func (v validator) ValidateCreate(ctx *context.WebhookRequestContext) admission.
 	var fieldErrs field.ErrorList
 
 	fieldErrs = append(fieldErrs, v.validatePolicies(ctx, vmClass, field.NewPath("spec", "policies"))...)
+	fieldErrs = append(fieldErrs, v.validateConfigSpec(ctx, vmClass, field.NewPath("spec", "configSpec"))...)
 
 	validationErrs := make([]string, 0, len(fieldErrs))
 	for _, fieldErr := range fieldErrs {
@@ -86,7 +126,27 @@ func (v validator) ValidateDelete(*context.WebhookRequestContext) admission.Resp
 }
 
 func (v validator) ValidateUpdate(ctx *context.WebhookRequestContext) admission.Response {
+	vmClass, err := v.vmClassFromUnstructured(ctx.Obj)
+	if err != nil {
+		return webhook.Errored(http.StatusBadRequest, err)
+	}
+
+	oldVMClass, err := v.vmClassFromUnstructured(ctx.OldObj)
+	if err != nil {
+		return webhook.Errored(http.StatusBadRequest, err)
+	}
+
 	var fieldErrs field.ErrorList
+
+	// The ConfigSpec is only validated when it, or the hardware it must be consistent with, changes so that the
+	// other fields of a class whose ConfigSpec was stored before it was validated can still be updated.
+	if !bytes.Equal(vmClass.Spec.ConfigSpec, oldVMClass.Spec.ConfigSpec) ||
+		vmClass.Spec.Hardware.Cpus != oldVMClass.Spec.Hardware.Cpus ||
+		!vmClass.Spec.Hardware.Memory.Equal(oldVMClass.Spec.Hardware.Memory) {
+		fieldErrs = append(fieldErrs, v.validateConfigSpec(ctx, vmClass, field.NewPath("spec", "configSpec"))...)
+	}
+	fieldErrs = append(fieldErrs, v.validateQuota(ctx, vmClass, oldVMClass)...)
+
 	validationErrs := make([]string, 0, len(fieldErrs))
 	for _, fieldErr := range fieldErrs {
 		validationErrs = append(validationErrs, fieldErr.Error())
@@ -117,6 +177,138 @@ func (v validator) validatePolicies(ctx *context.WebhookRequestContext, vmClass
 	return allErrs
 }
 
+// validateConfigSpec validates that the class's ConfigSpec can be unmarshaled, only adds allowed devices, and that
+// its CPU and memory are consistent with the class's hardware.
+func (v validator) validateConfigSpec(_ *context.WebhookRequestContext, vmClass *vmopv1.VirtualMachineClass,
+	csPath *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+
+	if len(vmClass.Spec.ConfigSpec) == 0 {
+		return allErrs
+	}
+
+	configSpec, err := util.UnmarshalConfigSpecFromJSON(vmClass.Spec.ConfigSpec)
+	if err != nil {
+		return append(allErrs, field.Invalid(csPath, "", fmt.Sprintf(invalidConfigSpecFmt, err)))
+	}
+
+	for i, devChange := range configSpec.DeviceChange {
+		devSpec := devChange.GetVirtualDeviceConfigSpec()
+		if devSpec == nil || devSpec.Device == nil {
+			continue
+		}
+
+		devPath := csPath.Child("deviceChange").Index(i).Child("device")
+		switch dev := devSpec.Device; {
+		case isDisk(dev):
+			allErrs = append(allErrs, field.Forbidden(devPath, configSpecDisksMsg))
+		case util.IsEthernetCard(dev):
+			allErrs = append(allErrs, field.Forbidden(devPath, configSpecNICsMsg))
+		default:
+			if _, ok := allowedConfigSpecDeviceTypes[reflect.TypeOf(dev)]; !ok {
+				allErrs = append(allErrs, field.NotSupported(devPath, reflect.TypeOf(dev).Elem().Name(),
+					allowedConfigSpecDeviceTypeNames()))
+			}
+		}
+	}
+
+	hw := vmClass.Spec.Hardware
+	if configSpec.NumCPUs < 0 || (configSpec.NumCPUs > 0 && int64(configSpec.NumCPUs) != hw.Cpus) {
+		allErrs = append(allErrs, field.Invalid(csPath.Child("numCPUs"), configSpec.NumCPUs,
+			fmt.Sprintf(configSpecHardwareMismatch, "cpus")))
+	}
+	if configSpec.MemoryMB < 0 ||
+		(configSpec.MemoryMB > 0 && configSpec.MemoryMB != virtualmachine.MemoryQuantityToMb(hw.Memory)) {
+		allErrs = append(allErrs, field.Invalid(csPath.Child("memoryMB"), configSpec.MemoryMB,
+			fmt.Sprintf(configSpecHardwareMismatch, "memory")))
+	}
+	if cores := configSpec.NumCoresPerSocket; cores < 0 || (cores > 0 && hw.Cpus > 0 && hw.Cpus%int64(cores) != 0) {
+		allErrs = append(allErrs, field.Invalid(csPath.Child("numCoresPerSocket"), cores, configSpecCoresPerSocket))
+	}
+
+	return allErrs
+}
+
+// validateQuota checks that an update of the class that increases the resources its VMs consume, which the VMs
+// are updated to, does not make the resources consumed by the VMs of a namespace exceed the hard limits of the
+// namespace's VirtualMachineQuotas.
+func (v validator) validateQuota(ctx *context.WebhookRequestContext, vmClass, oldVMClass *vmopv1.VirtualMachineClass) field.ErrorList {
+	var allErrs field.ErrorList
+
+	hwPath := field.NewPath("spec", "hardware")
+
+	increased := vmquota.Increased(vmquota.ClassUsage(oldVMClass), vmquota.ClassUsage(vmClass))
+	if len(increased) == 0 {
+		return allErrs
+	}
+
+	var listOpts []client.ListOption
+	if lib.IsNamespacedClassAndWindowsFSSEnabled() {
+		listOpts = append(listOpts, client.InNamespace(vmClass.Namespace))
+	}
+
+	quotas := &vmopv1.VirtualMachineQuotaList{}
+	if err := v.client.List(ctx, quotas, listOpts...); err != nil {
+		return append(allErrs, field.InternalError(hwPath, err))
+	}
+
+	type namespaceUsages struct {
+		used, oldUsed corev1.ResourceList
+	}
+	usages := map[string]namespaceUsages{}
+
+	for _, quota := range quotas.Items {
+		usage, ok := usages[quota.Namespace]
+		if !ok {
+			used, err := vmquota.NamespaceUsageWithClass(ctx, v.client, quota.Namespace, vmClass)
+			if err != nil {
+				return append(allErrs, field.InternalError(hwPath, err))
+			}
+			oldUsed, err := vmquota.NamespaceUsage(ctx, v.client, quota.Namespace, "")
+			if err != nil {
+				return append(allErrs, field.InternalError(hwPath, err))
+			}
+			usage = namespaceUsages{used: used, oldUsed: oldUsed}
+			usages[quota.Namespace] = usage
+		}
+
+		// Only the resources that the update increases in the namespace are checked so a class can be
+		// updated in a namespace whose quota is already exceeded, or that has no VMs of the class.
+		increasedInNamespace := map[corev1.ResourceName]struct{}{}
+		for _, name := range vmquota.Increased(usage.oldUsed, usage.used) {
+			increasedInNamespace[name] = struct{}{}
+		}
+
+		var exceeded []corev1.ResourceName
+		for _, name := range vmquota.Exceeded(quota.Spec.Hard, usage.used) {
+			if _, ok := increasedInNamespace[name]; ok {
+				exceeded = append(exceeded, name)
+			}
+		}
+
+		if len(exceeded) > 0 {
+			allErrs = append(allErrs, field.Forbidden(hwPath, fmt.Sprintf(quotaExceededFmt, quota.Namespace, quota.Name,
+				vmquota.Format(usage.used, exceeded), vmquota.Format(quota.Spec.Hard, exceeded))))
+		}
+	}
+
+	return allErrs
+}
+
+func isDisk(dev vimtypes.BaseVirtualDevice) bool {
+	_, ok := dev.(*vimtypes.VirtualDisk)
+	return ok
+}
+
+func allowedConfigSpecDeviceTypeNames() []string {
+	names := make([]string, 0, len(allowedConfigSpecDeviceTypes))
+	for t := range allowedConfigSpecDeviceTypes {
+		names = append(names, t.Elem().Name())
+	}
+	sort.Strings(names)
+	return names
+}
+
 // vmClassFromUnstructured returns the VirtualMachineClass from the unstructured object.
 func (v validator) vmClassFromUnstructured(obj runtime.Unstructured) (*vmopv1.VirtualMachineClass, error) {
 	vmClass := &vmopv1.VirtualMachineClass{}
@@ -1,12 +1,15 @@
-// Copyright (c) 2019 VMware, Inc. All Rights Reserved.
+// Copyright (c) 2019-2023 VMware, Inc. All Rights Reserved.
 // SPDX-License-Identifier: Apache-2.0
 
 package validation_test
 
 import (
+	"fmt"
+
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/ginkgo/extensions/table"
 	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
@@ -14,11 +17,28 @@ import (
 
 	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
 
+	vimtypes "github.com/vmware/govmomi/vim25/types"
+
 	vmopv1 "github.com/vmware-tanzu/vm-operator/api/v1alpha1"
 
+	"github.com/vmware-tanzu/vm-operator/pkg/util"
 	"github.com/vmware-tanzu/vm-operator/test/builder"
 )
 
+func configSpecWithDevice(configSpec *vimtypes.VirtualMachineConfigSpec, dev vimtypes.BaseVirtualDevice) []byte {
+	if dev != nil {
+		configSpec.DeviceChange = []vimtypes.BaseVirtualDeviceConfigSpec{
+			&vimtypes.VirtualDeviceConfigSpec{
+				Operation: vimtypes.VirtualDeviceConfigSpecOperationAdd,
+				Device:    dev,
+			},
+		}
+	}
+	raw, err := util.MarshalConfigSpecToJSON(configSpec)
+	Expect(err).ToNot(HaveOccurred())
+	return raw
+}
+
 func unitTests() {
 	Describe("Invoking ValidateCreate", unitTestsValidateCreate)
 	Describe("Invoking ValidateUpdate", unitTestsValidateUpdate)
@@ -62,6 +82,15 @@ func unitTestsValidateCreate() {
 		invalidMemoryRequest bool
 		noCPULimit           bool
 		noMemoryLimit        bool
+
+		validConfigSpec               bool
+		invalidConfigSpecJSON         bool
+		configSpecDisk                bool
+		configSpecNIC                 bool
+		configSpecUnsupportedDevice   bool
+		configSpecCPUMismatch         bool
+		configSpecMemoryMismatch      bool
+		configSpecInvalidCoresPerSock bool
 	}
 
 	validateCreate := func(args createArgs, expectedAllowed bool, expectedReason string, expectedErr error) {
@@ -81,6 +110,39 @@ func unitTestsValidateCreate() {
 		if args.noMemoryLimit {
 			ctx.vmClass.Spec.Policies.Resources.Limits.Memory = resource.MustParse("0")
 		}
+		if args.validConfigSpec {
+			ctx.vmClass.Spec.ConfigSpec = configSpecWithDevice(&vimtypes.VirtualMachineConfigSpec{
+				NumCPUs:           2,
+				NumCoresPerSocket: 2,
+				MemoryMB:          4 * 1024,
+				Firmware:          "efi",
+			}, &vimtypes.VirtualPCIPassthrough{
+				VirtualDevice: vimtypes.VirtualDevice{
+					Backing: &vimtypes.VirtualPCIPassthroughVmiopBackingInfo{Vgpu: "grid_p40-8q"},
+				},
+			})
+		}
+		if args.invalidConfigSpecJSON {
+			ctx.vmClass.Spec.ConfigSpec = []byte(`{"numCPUs": "two"}`)
+		}
+		if args.configSpecDisk {
+			ctx.vmClass.Spec.ConfigSpec = configSpecWithDevice(&vimtypes.VirtualMachineConfigSpec{}, &vimtypes.VirtualDisk{})
+		}
+		if args.configSpecNIC {
+			ctx.vmClass.Spec.ConfigSpec = configSpecWithDevice(&vimtypes.VirtualMachineConfigSpec{}, &vimtypes.VirtualVmxnet3{})
+		}
+		if args.configSpecUnsupportedDevice {
+			ctx.vmClass.Spec.ConfigSpec = configSpecWithDevice(&vimtypes.VirtualMachineConfigSpec{}, &vimtypes.VirtualCdrom{})
+		}
+		if args.configSpecCPUMismatch {
+			ctx.vmClass.Spec.ConfigSpec = configSpecWithDevice(&vimtypes.VirtualMachineConfigSpec{NumCPUs: 4}, nil)
+		}
+		if args.configSpecMemoryMismatch {
+			ctx.vmClass.Spec.ConfigSpec = configSpecWithDevice(&vimtypes.VirtualMachineConfigSpec{MemoryMB: 1024}, nil)
+		}
+		if args.configSpecInvalidCoresPerSock {
+			ctx.vmClass.Spec.ConfigSpec = configSpecWithDevice(&vimtypes.VirtualMachineConfigSpec{NumCoresPerSocket: 3}, nil)
+		}
 
 		ctx.WebhookRequestContext.Obj, err = builder.ToUnstructured(ctx.vmClass)
 		Expect(err).ToNot(HaveOccurred())
@@ -88,7 +150,7 @@ func unitTestsValidateCreate() {
 		response := ctx.ValidateCreate(&ctx.WebhookRequestContext)
 		Expect(response.Allowed).To(Equal(expectedAllowed))
 		if expectedReason != "" {
-			Expect(string(response.Result.Reason)).To(Equal(expectedReason))
+			Expect(string(response.Result.Reason)).To(ContainSubstring(expectedReason))
 		}
 		if expectedErr != nil {
 			Expect(response.Result.Message).To(Equal(expectedErr.Error()))
@@ -105,12 +167,30 @@ func unitTestsValidateCreate() {
 	reqPath := field.NewPath("spec", "policies", "resources", "requests")
 	invalidCPUField := field.Invalid(reqPath.Child("cpu"), "2Gi", "CPU request must not be larger than the CPU limit")
 	invalidMemField := field.Invalid(reqPath.Child("memory"), "2Gi", "memory request must not be larger than the memory limit")
+	csPath := field.NewPath("spec", "configSpec")
+	devPath := csPath.Child("deviceChange").Index(0).Child("device")
 	DescribeTable("create table", validateCreate,
 		Entry("should allow valid", createArgs{}, true, nil, nil),
 		Entry("should allow no cpu limit", createArgs{noCPULimit: true}, true, nil, nil),
 		Entry("should allow no memory limit", createArgs{noMemoryLimit: true}, true, nil, nil),
 		Entry("should deny invalid cpu request", createArgs{invalidCPURequest: true}, false, invalidCPUField.Error(), nil),
 		Entry("should deny invalid memory request", createArgs{invalidMemoryRequest: true}, false, invalidMemField.Error(), nil),
+
+		Entry("should allow valid ConfigSpec", createArgs{validConfigSpec: true}, true, nil, nil),
+		Entry("should deny ConfigSpec that cannot be unmarshaled", createArgs{invalidConfigSpecJSON: true}, false,
+			"spec.configSpec: Invalid value: \"\": invalid ConfigSpec", nil),
+		Entry("should deny ConfigSpec with disk", createArgs{configSpecDisk: true}, false,
+			field.Forbidden(devPath, "disks are not allowed, use the VirtualMachine's volumes").Error(), nil),
+		Entry("should deny ConfigSpec with network interface", createArgs{configSpecNIC: true}, false,
+			field.Forbidden(devPath, "network interfaces are not allowed, use the VirtualMachine's network interfaces").Error(), nil),
+		Entry("should deny ConfigSpec with unsupported device", createArgs{configSpecUnsupportedDevice: true}, false,
+			"spec.configSpec.deviceChange[0].device: Unsupported value: \"VirtualCdrom\"", nil),
+		Entry("should deny ConfigSpec with CPUs that differ from the hardware", createArgs{configSpecCPUMismatch: true}, false,
+			field.Invalid(csPath.Child("numCPUs"), 4, fmt.Sprintf("must be zero or equal to %s of spec.hardware", "cpus")).Error(), nil),
+		Entry("should deny ConfigSpec with memory that differs from the hardware", createArgs{configSpecMemoryMismatch: true}, false,
+			field.Invalid(csPath.Child("memoryMB"), 1024, fmt.Sprintf("must be zero or equal to %s of spec.hardware", "memory")).Error(), nil),
+		Entry("should deny ConfigSpec with cores per socket that do not divide the CPUs", createArgs{configSpecInvalidCoresPerSock: true}, false,
+			field.Invalid(csPath.Child("numCoresPerSocket"), 3, "must be a divisor of the number of CPUs").Error(), nil),
 	)
 }
 
@@ -125,6 +205,7 @@ func unitTestsValidateUpdate() {
 		changeHwMemory bool
 		changeCPU      bool
 		changeMemory   bool
+		addConfigSpec  bool
 	}
 
 	validateUpdate := func(args updateArgs, expectedAllowed bool, expectedReason string, expectedErr error) {
@@ -144,6 +225,9 @@ func unitTestsValidateUpdate() {
 			ctx.vmClass.Spec.Policies.Resources.Requests.Memory = resource.MustParse("5Gi")
 			ctx.vmClass.Spec.Policies.Resources.Limits.Memory = resource.MustParse("10Gi")
 		}
+		if args.addConfigSpec {
+			ctx.vmClass.Spec.ConfigSpec = configSpecWithDevice(&vimtypes.VirtualMachineConfigSpec{Firmware: "efi"}, nil)
+		}
 
 		ctx.WebhookRequestContext.Obj, err = builder.ToUnstructured(ctx.vmClass)
 		Expect(err).ToNot(HaveOccurred())
@@ -173,6 +257,7 @@ func unitTestsValidateUpdate() {
 		Entry("should allow hw memory change", updateArgs{changeHwMemory: true}, true, nil, nil),
 		Entry("should allow policy cpu change", updateArgs{changeCPU: true}, true, nil, nil),
 		Entry("should allow policy memory change", updateArgs{changeMemory: true}, true, nil, nil),
+		Entry("should allow valid ConfigSpec change", updateArgs{addConfigSpec: true}, true, nil, nil),
 	)
 
 	DescribeTable("update table", validateUpdate,
@@ -183,6 +268,127 @@ func unitTestsValidateUpdate() {
 		Entry("should deny policy memory change", updateArgs{changeMemory: true}, true, nil, nil),
 	)
 
+	When("the ConfigSpec is updated with a disk", func() {
+		JustBeforeEach(func() {
+			var err error
+			ctx.vmClass.Spec.ConfigSpec = configSpecWithDevice(&vimtypes.VirtualMachineConfigSpec{}, &vimtypes.VirtualDisk{})
+			ctx.WebhookRequestContext.Obj, err = builder.ToUnstructured(ctx.vmClass)
+			Expect(err).ToNot(HaveOccurred())
+			response = ctx.ValidateUpdate(&ctx.WebhookRequestContext)
+		})
+
+		It("should deny the request", func() {
+			Expect(response.Allowed).To(BeFalse())
+			Expect(string(response.Result.Reason)).To(ContainSubstring("disks are not allowed"))
+		})
+	})
+
+	When("the class already has an invalid ConfigSpec", func() {
+		BeforeEach(func() {
+			ctx.oldVMClass.Spec.ConfigSpec = configSpecWithDevice(&vimtypes.VirtualMachineConfigSpec{}, &vimtypes.VirtualDisk{})
+			ctx.vmClass.Spec.ConfigSpec = ctx.oldVMClass.Spec.ConfigSpec
+
+			var err error
+			ctx.WebhookRequestContext.OldObj, err = builder.ToUnstructured(ctx.oldVMClass)
+			Expect(err).ToNot(HaveOccurred())
+		})
+
+		JustBeforeEach(func() {
+			var err error
+			ctx.WebhookRequestContext.Obj, err = builder.ToUnstructured(ctx.vmClass)
+			Expect(err).ToNot(HaveOccurred())
+			response = ctx.ValidateUpdate(&ctx.WebhookRequestContext)
+		})
+
+		When("the ConfigSpec is not changed", func() {
+			BeforeEach(func() {
+				ctx.vmClass.Labels = map[string]string{"foo": "bar"}
+			})
+
+			It("should allow the request", func() {
+				Expect(response.Allowed).To(BeTrue())
+			})
+		})
+
+		When("the hardware is changed", func() {
+			BeforeEach(func() {
+				ctx.vmClass.Spec.Hardware.Cpus = 4
+			})
+
+			It("should deny the request", func() {
+				Expect(response.Allowed).To(BeFalse())
+				Expect(string(response.Result.Reason)).To(ContainSubstring("disks are not allowed"))
+			})
+		})
+	})
+
+	When("the namespace of a VM of the class has a VirtualMachineQuota", func() {
+		var vmQuota *vmopv1.VirtualMachineQuota
+
+		BeforeEach(func() {
+			ctx.vmClass.Name = "dummy-class"
+			ctx.oldVMClass.Name = ctx.vmClass.Name
+			Expect(ctx.Client.Create(ctx, ctx.oldVMClass.DeepCopy())).To(Succeed())
+
+			vm := builder.DummyVirtualMachine()
+			vm.Name = "dummy-vm"
+			vm.Namespace = "dummy-ns"
+			vm.Spec.ClassName = ctx.vmClass.Name
+			Expect(ctx.Client.Create(ctx, vm)).To(Succeed())
+
+			vmQuota = builder.DummyVirtualMachineQuota("dummy-quota", vm.Namespace, corev1.ResourceList{
+				vmopv1.VirtualMachineQuotaResourceCPU:    resource.MustParse("4"),
+				vmopv1.VirtualMachineQuotaResourceMemory: resource.MustParse("8Gi"),
+			})
+			Expect(ctx.Client.Create(ctx, vmQuota)).To(Succeed())
+
+			var err error
+			ctx.WebhookRequestContext.OldObj, err = builder.ToUnstructured(ctx.oldVMClass)
+			Expect(err).ToNot(HaveOccurred())
+		})
+
+		JustBeforeEach(func() {
+			var err error
+			ctx.WebhookRequestContext.Obj, err = builder.ToUnstructured(ctx.vmClass)
+			Expect(err).ToNot(HaveOccurred())
+			response = ctx.ValidateUpdate(&ctx.WebhookRequestContext)
+		})
+
+		When("the update does not exceed the quota", func() {
+			BeforeEach(func() {
+				ctx.vmClass.Spec.Hardware.Cpus = 4
+			})
+
+			It("should allow the request", func() {
+				Expect(response.Allowed).To(BeTrue())
+			})
+		})
+
+		When("the update exceeds the quota", func() {
+			BeforeEach(func() {
+				ctx.vmClass.Spec.Hardware.Cpus = 8
+			})
+
+			It("should deny the request", func() {
+				Expect(response.Allowed).To(BeFalse())
+				Expect(string(response.Result.Reason)).To(Equal(field.Forbidden(field.NewPath("spec", "hardware"),
+					"exceeded quota: dummy-ns/dummy-quota, used: cpu=8, limited: cpu=4").Error()))
+			})
+		})
+
+		When("the update decreases the resources in a namespace whose quota is exceeded", func() {
+			BeforeEach(func() {
+				vmQuota.Spec.Hard[vmopv1.VirtualMachineQuotaResourceCPU] = resource.MustParse("1")
+				Expect(ctx.Client.Update(ctx, vmQuota)).To(Succeed())
+				ctx.vmClass.Spec.Hardware.Memory = resource.MustParse("2Gi")
+			})
+
+			It("should allow the request", func() {
+				Expect(response.Allowed).To(BeTrue())
+			})
+		})
+	})
+
 	When("the update is performed while object deletion", func() {
 		JustBeforeEach(func() {
 			t := metav1.Now()
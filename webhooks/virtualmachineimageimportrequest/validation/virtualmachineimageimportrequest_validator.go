@@ -0,0 +1,213 @@
+// Copyright (c) 2023 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package validation
+
+import (
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"net/url"
+	"path"
+	"reflect"
+	"strings"
+
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/api/validation"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	ctrlmgr "sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	vmopv1 "github.com/vmware-tanzu/vm-operator/api/v1alpha1"
+
+	imgregv1a1 "github.com/vmware-tanzu/vm-operator/external/image-registry/api/v1alpha1"
+
+	"github.com/vmware-tanzu/vm-operator/pkg/builder"
+	"github.com/vmware-tanzu/vm-operator/pkg/context"
+	"github.com/vmware-tanzu/vm-operator/pkg/lib"
+	"github.com/vmware-tanzu/vm-operator/webhooks/common"
+)
+
+const (
+	webHookName = "default"
+)
+
+// checksumLengths maps the supported checksum algorithms to the length of their hex-encoded checksums.
+var checksumLengths = map[string]int{
+	"SHA1":   40,
+	"SHA256": 64,
+	"SHA512": 128,
+	"MD5":    32,
+}
+
+// +kubebuilder:webhook:verbs=create;update,path=/default-validate-vmoperator-vmware-com-v1alpha1-virtualmachineimageimportrequest,mutating=false,failurePolicy=fail,groups=vmoperator.vmware.com,resources=virtualmachineimageimportrequests,versions=v1alpha1,name=default.validating.virtualmachineimageimportrequest.vmoperator.vmware.com,sideEffects=None,admissionReviewVersions=v1;v1beta1
+// +kubebuilder:rbac:groups=vmoperator.vmware.com,resources=virtualmachineimageimportrequests,verbs=get;list
+// +kubebuilder:rbac:groups=vmoperator.vmware.com,resources=virtualmachineimageimportrequests/status,verbs=get
+
+// AddToManager adds the webhook to the provided manager.
+func AddToManager(ctx *context.ControllerManagerContext, mgr ctrlmgr.Manager) error {
+	hook, err := builder.NewValidatingWebhook(ctx, mgr, webHookName, NewValidator(mgr.GetClient()))
+	if err != nil {
+		return errors.Wrapf(err, "failed to create VirtualMachineImageImportRequest validation webhook")
+	}
+	mgr.GetWebhookServer().Register(hook.Path, hook)
+
+	return nil
+}
+
+// NewValidator returns the package's Validator.
+func NewValidator(client client.Client) builder.Validator {
+	return validator{
+		client:    client,
+		converter: runtime.DefaultUnstructuredConverter,
+	}
+}
+
+type validator struct {
+	client    client.Client
+	converter runtime.UnstructuredConverter
+}
+
+func (v validator) For() schema.GroupVersionKind {
+	return vmopv1.SchemeGroupVersion.WithKind(reflect.TypeOf(vmopv1.VirtualMachineImageImportRequest{}).Name())
+}
+
+func (v validator) ValidateCreate(ctx *context.WebhookRequestContext) admission.Response {
+	if !lib.IsWCPVMImageRegistryEnabled() {
+		return common.BuildValidationResponse(ctx, []string{"WCP_VM_Image_Registry feature not enabled"}, nil)
+	}
+
+	vmImportReq, err := v.vmImageImportRequestFromUnstructured(ctx.Obj)
+	if err != nil {
+		return webhook.Errored(http.StatusBadRequest, err)
+	}
+
+	var fieldErrs field.ErrorList
+
+	fieldErrs = append(fieldErrs, v.validateSource(vmImportReq)...)
+	fieldErrs = append(fieldErrs, v.validateTargetLocation(vmImportReq)...)
+
+	validationErrs := make([]string, 0, len(fieldErrs))
+	for _, fieldErr := range fieldErrs {
+		validationErrs = append(validationErrs, fieldErr.Error())
+	}
+
+	return common.BuildValidationResponse(ctx, validationErrs, nil)
+}
+
+func (v validator) ValidateDelete(*context.WebhookRequestContext) admission.Response {
+	return admission.Allowed("")
+}
+
+func (v validator) ValidateUpdate(ctx *context.WebhookRequestContext) admission.Response {
+	vmImportReq, err := v.vmImageImportRequestFromUnstructured(ctx.Obj)
+	if err != nil {
+		return webhook.Errored(http.StatusBadRequest, err)
+	}
+
+	oldVMImportReq, err := v.vmImageImportRequestFromUnstructured(ctx.OldObj)
+	if err != nil {
+		return webhook.Errored(http.StatusBadRequest, err)
+	}
+
+	var fieldErrs field.ErrorList
+
+	fieldErrs = append(fieldErrs, v.validateImmutableFields(vmImportReq, oldVMImportReq)...)
+
+	validationErrs := make([]string, 0, len(fieldErrs))
+	for _, fieldErr := range fieldErrs {
+		validationErrs = append(validationErrs, fieldErr.Error())
+	}
+
+	return common.BuildValidationResponse(ctx, validationErrs, nil)
+}
+
+func (v validator) validateSource(vmImportReq *vmopv1.VirtualMachineImageImportRequest) field.ErrorList {
+	var allErrs field.ErrorList
+
+	sourcePath := field.NewPath("spec").Child("source")
+	source := vmImportReq.Spec.Source
+
+	if source.URL == "" {
+		allErrs = append(allErrs, field.Required(sourcePath.Child("url"), ""))
+	} else if u, err := url.Parse(source.URL); err != nil {
+		allErrs = append(allErrs, field.Invalid(sourcePath.Child("url"), source.URL, err.Error()))
+	} else {
+		if u.Scheme != "http" && u.Scheme != "https" {
+			allErrs = append(allErrs, field.NotSupported(sourcePath.Child("url"), u.Scheme, []string{"http", "https"}))
+		}
+		if u.Host == "" {
+			allErrs = append(allErrs, field.Invalid(sourcePath.Child("url"), source.URL, "must include a host"))
+		}
+		if ext := strings.ToLower(path.Ext(u.Path)); ext != ".ova" && ext != ".ovf" {
+			allErrs = append(allErrs, field.Invalid(sourcePath.Child("url"), source.URL,
+				"must refer to a file with an .ova or .ovf extension"))
+		}
+	}
+
+	if checksum := source.Checksum; checksum != nil {
+		checksumPath := sourcePath.Child("checksum")
+		algorithm := checksum.Algorithm
+		if algorithm == "" {
+			algorithm = "SHA256"
+		}
+
+		if length, ok := checksumLengths[algorithm]; !ok {
+			allErrs = append(allErrs, field.NotSupported(checksumPath.Child("algorithm"), checksum.Algorithm,
+				[]string{"SHA1", "SHA256", "SHA512", "MD5"}))
+		} else if _, err := hex.DecodeString(checksum.Value); err != nil || len(checksum.Value) != length {
+			allErrs = append(allErrs, field.Invalid(checksumPath.Child("value"), checksum.Value,
+				fmt.Sprintf("must be a %d character hex-encoded %s checksum", length, algorithm)))
+		}
+	}
+
+	return allErrs
+}
+
+func (v validator) validateTargetLocation(vmImportReq *vmopv1.VirtualMachineImageImportRequest) field.ErrorList {
+	var allErrs field.ErrorList
+
+	targetLocationPath := field.NewPath("spec").Child("target").Child("location")
+	location := vmImportReq.Spec.Target.Location
+
+	if location.Name == "" {
+		allErrs = append(allErrs, field.Required(targetLocationPath.Child("name"), ""))
+	}
+
+	if location.APIVersion != imgregv1a1.GroupVersion.String() && location.APIVersion != "" {
+		allErrs = append(allErrs, field.NotSupported(targetLocationPath.Child("apiVersion"),
+			location.APIVersion, []string{imgregv1a1.GroupVersion.String(), ""}))
+	}
+
+	if location.Kind != reflect.TypeOf(imgregv1a1.ContentLibrary{}).Name() && location.Kind != "" {
+		allErrs = append(allErrs, field.NotSupported(targetLocationPath.Child("kind"),
+			location.Kind, []string{reflect.TypeOf(imgregv1a1.ContentLibrary{}).Name(), ""}))
+	}
+
+	return allErrs
+}
+
+func (v validator) validateImmutableFields(vmImportReq, oldVMImportReq *vmopv1.VirtualMachineImageImportRequest) field.ErrorList {
+	var allErrs field.ErrorList
+	specPath := field.NewPath("spec")
+
+	allErrs = append(allErrs, validation.ValidateImmutableField(vmImportReq.Spec.Source,
+		oldVMImportReq.Spec.Source, specPath.Child("source"))...)
+	allErrs = append(allErrs, validation.ValidateImmutableField(vmImportReq.Spec.Target,
+		oldVMImportReq.Spec.Target, specPath.Child("target"))...)
+
+	return allErrs
+}
+
+// vmImageImportRequestFromUnstructured returns the VirtualMachineImageImportRequest from the unstructured object.
+func (v validator) vmImageImportRequestFromUnstructured(obj runtime.Unstructured) (*vmopv1.VirtualMachineImageImportRequest, error) {
+	vmImportReq := &vmopv1.VirtualMachineImageImportRequest{}
+	if err := v.converter.FromUnstructured(obj.UnstructuredContent(), vmImportReq); err != nil {
+		return nil, err
+	}
+	return vmImportReq, nil
+}
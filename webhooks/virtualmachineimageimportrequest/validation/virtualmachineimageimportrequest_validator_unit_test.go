@@ -0,0 +1,266 @@
+// Copyright (c) 2023 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package validation_test
+
+import (
+	"strings"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	vmopv1 "github.com/vmware-tanzu/vm-operator/api/v1alpha1"
+
+	"github.com/vmware-tanzu/vm-operator/pkg/lib"
+	"github.com/vmware-tanzu/vm-operator/test/builder"
+)
+
+func unitTests() {
+	Describe("Invoking ValidateCreate", unitTestsValidateCreate)
+	Describe("Invoking ValidateUpdate", unitTestsValidateUpdate)
+	Describe("Invoking ValidateDelete", unitTestsValidateDelete)
+}
+
+type unitValidatingWebhookContext struct {
+	builder.UnitTestContextForValidatingWebhook
+	vmImportReq    *vmopv1.VirtualMachineImageImportRequest
+	oldVMImportReq *vmopv1.VirtualMachineImageImportRequest
+}
+
+func newUnitTestContextForValidatingWebhook(isUpdate bool) *unitValidatingWebhookContext {
+	vmImportReq := builder.DummyVirtualMachineImageImportRequest("dummy-import", "dummy-ns",
+		"https://example.com/images/photon.ova", "dummy-item", "dummy-cl")
+	obj, err := builder.ToUnstructured(vmImportReq)
+	Expect(err).ToNot(HaveOccurred())
+
+	var oldVMImportReq *vmopv1.VirtualMachineImageImportRequest
+	var oldObj *unstructured.Unstructured
+
+	if isUpdate {
+		oldVMImportReq = vmImportReq.DeepCopy()
+		oldObj, err = builder.ToUnstructured(oldVMImportReq)
+		Expect(err).ToNot(HaveOccurred())
+	}
+
+	return &unitValidatingWebhookContext{
+		UnitTestContextForValidatingWebhook: *suite.NewUnitTestContextForValidatingWebhook(obj, oldObj),
+		vmImportReq:                         vmImportReq,
+		oldVMImportReq:                      oldVMImportReq,
+	}
+}
+
+func unitTestsValidateCreate() {
+	var (
+		ctx *unitValidatingWebhookContext
+		err error
+
+		invalidAPIVersion = "vmoperator.vmware.com/v1"
+		sha256Checksum    = strings.Repeat("a1", 32)
+	)
+
+	type createArgs struct {
+		emptyURL                        bool
+		unsupportedScheme               bool
+		unsupportedExtension            bool
+		ovfURL                          bool
+		validChecksum                   bool
+		unsupportedChecksumAlgorithm    bool
+		invalidChecksumValue            bool
+		invalidTargetLocationAPIVersion bool
+		targetLocationNameEmpty         bool
+	}
+
+	validateCreate := func(args createArgs, expectedAllowed bool, expectedReason string, expectedErr error) {
+		if args.emptyURL {
+			ctx.vmImportReq.Spec.Source.URL = ""
+		}
+
+		if args.unsupportedScheme {
+			ctx.vmImportReq.Spec.Source.URL = "ftp://example.com/images/photon.ova"
+		}
+
+		if args.unsupportedExtension {
+			ctx.vmImportReq.Spec.Source.URL = "https://example.com/images/photon.iso"
+		}
+
+		if args.ovfURL {
+			ctx.vmImportReq.Spec.Source.URL = "http://example.com/images/photon.OVF"
+		}
+
+		if args.validChecksum {
+			ctx.vmImportReq.Spec.Source.Checksum = &vmopv1.VirtualMachineImageImportRequestChecksum{
+				Value: sha256Checksum,
+			}
+		}
+
+		if args.unsupportedChecksumAlgorithm {
+			ctx.vmImportReq.Spec.Source.Checksum = &vmopv1.VirtualMachineImageImportRequestChecksum{
+				Algorithm: "CRC32",
+				Value:     "abcdef",
+			}
+		}
+
+		if args.invalidChecksumValue {
+			ctx.vmImportReq.Spec.Source.Checksum = &vmopv1.VirtualMachineImageImportRequestChecksum{
+				Algorithm: "SHA1",
+				Value:     sha256Checksum,
+			}
+		}
+
+		if args.invalidTargetLocationAPIVersion {
+			ctx.vmImportReq.Spec.Target.Location.APIVersion = invalidAPIVersion
+		}
+
+		if args.targetLocationNameEmpty {
+			ctx.vmImportReq.Spec.Target.Location.Name = ""
+		}
+
+		ctx.WebhookRequestContext.Obj, err = builder.ToUnstructured(ctx.vmImportReq)
+		Expect(err).ToNot(HaveOccurred())
+
+		response := ctx.ValidateCreate(&ctx.WebhookRequestContext)
+		Expect(response.Allowed).To(Equal(expectedAllowed))
+		if expectedReason != "" {
+			Expect(string(response.Result.Reason)).To(ContainSubstring(expectedReason))
+		}
+		if expectedErr != nil {
+			Expect(response.Result.Message).To(Equal(expectedErr.Error()))
+		}
+	}
+
+	BeforeEach(func() {
+		ctx = newUnitTestContextForValidatingWebhook(false)
+		lib.IsWCPVMImageRegistryEnabled = func() bool {
+			return true
+		}
+	})
+
+	AfterEach(func() {
+		ctx = nil
+	})
+
+	sourcePath := field.NewPath("spec", "source")
+	targetLocationPath := field.NewPath("spec", "target", "location")
+	DescribeTable("create table", validateCreate,
+		Entry("should allow valid", createArgs{}, true, nil, nil),
+		Entry("should allow OVF URL", createArgs{ovfURL: true}, true, nil, nil),
+		Entry("should allow valid checksum", createArgs{validChecksum: true}, true, nil, nil),
+		Entry("should deny empty URL", createArgs{emptyURL: true}, false,
+			field.Required(sourcePath.Child("url"), "").Error(), nil),
+		Entry("should deny unsupported URL scheme", createArgs{unsupportedScheme: true}, false,
+			field.NotSupported(sourcePath.Child("url"), "ftp", []string{"http", "https"}).Error(), nil),
+		Entry("should deny unsupported file extension", createArgs{unsupportedExtension: true}, false,
+			"must refer to a file with an .ova or .ovf extension", nil),
+		Entry("should deny unsupported checksum algorithm", createArgs{unsupportedChecksumAlgorithm: true}, false,
+			field.NotSupported(sourcePath.Child("checksum", "algorithm"), "CRC32",
+				[]string{"SHA1", "SHA256", "SHA512", "MD5"}).Error(), nil),
+		Entry("should deny checksum value that does not match the algorithm", createArgs{invalidChecksumValue: true}, false,
+			"must be a 40 character hex-encoded SHA1 checksum", nil),
+		Entry("should deny invalid target location API version", createArgs{invalidTargetLocationAPIVersion: true}, false,
+			field.NotSupported(targetLocationPath.Child("apiVersion"), invalidAPIVersion,
+				[]string{"imageregistry.vmware.com/v1alpha1", ""}).Error(), nil),
+		Entry("should deny if target location name is empty", createArgs{targetLocationNameEmpty: true}, false,
+			field.Required(targetLocationPath.Child("name"), "").Error(), nil),
+	)
+
+	When("the feature is not enabled", func() {
+		BeforeEach(func() {
+			lib.IsWCPVMImageRegistryEnabled = func() bool {
+				return false
+			}
+		})
+
+		It("should not allow the request", func() {
+			response := ctx.ValidateCreate(&ctx.WebhookRequestContext)
+			Expect(response.Allowed).To(BeFalse())
+		})
+	})
+}
+
+func unitTestsValidateUpdate() {
+	var (
+		ctx      *unitValidatingWebhookContext
+		response admission.Response
+	)
+
+	BeforeEach(func() {
+		ctx = newUnitTestContextForValidatingWebhook(true)
+	})
+
+	AfterEach(func() {
+		ctx = nil
+	})
+
+	JustBeforeEach(func() {
+		var err error
+		ctx.WebhookRequestContext.Obj, err = builder.ToUnstructured(ctx.vmImportReq)
+		Expect(err).ToNot(HaveOccurred())
+		response = ctx.ValidateUpdate(&ctx.WebhookRequestContext)
+	})
+
+	Context("TTLSecondsAfterFinished is updated", func() {
+		BeforeEach(func() {
+			ttl := int64(60)
+			ctx.vmImportReq.Spec.TTLSecondsAfterFinished = &ttl
+		})
+
+		It("should allow the request", func() {
+			Expect(response.Allowed).To(BeTrue())
+		})
+	})
+
+	Context("Source URL is updated", func() {
+		BeforeEach(func() {
+			ctx.vmImportReq.Spec.Source.URL = "https://example.com/images/ubuntu.ova"
+		})
+
+		It("should not allow the request", func() {
+			Expect(response.Allowed).To(BeFalse())
+			Expect(response.Result).ToNot(BeNil())
+			Expect(string(response.Result.Reason)).To(ContainSubstring("spec.source: Invalid value"))
+		})
+	})
+
+	Context("Target item name is updated", func() {
+		BeforeEach(func() {
+			ctx.vmImportReq.Spec.Target.Item.Name = "updated-item"
+		})
+
+		It("should not allow the request", func() {
+			Expect(response.Allowed).To(BeFalse())
+			Expect(response.Result).ToNot(BeNil())
+			Expect(string(response.Result.Reason)).To(ContainSubstring("field is immutable"))
+		})
+	})
+}
+
+func unitTestsValidateDelete() {
+	var (
+		ctx      *unitValidatingWebhookContext
+		response admission.Response
+	)
+
+	BeforeEach(func() {
+		ctx = newUnitTestContextForValidatingWebhook(false)
+	})
+
+	AfterEach(func() {
+		ctx = nil
+	})
+
+	When("the delete is performed", func() {
+		JustBeforeEach(func() {
+			response = ctx.ValidateDelete(&ctx.WebhookRequestContext)
+		})
+
+		It("should allow the request", func() {
+			Expect(response.Allowed).To(BeTrue())
+			Expect(response.Result).ToNot(BeNil())
+		})
+	})
+}
@@ -0,0 +1,232 @@
+// Copyright (c) 2023 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package validation
+
+import (
+	"fmt"
+	"net/http"
+	"reflect"
+	"strings"
+
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/validation"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	ctrlmgr "sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	vmopv1 "github.com/vmware-tanzu/vm-operator/api/v1alpha1"
+
+	"github.com/vmware-tanzu/vm-operator/pkg/builder"
+	"github.com/vmware-tanzu/vm-operator/pkg/context"
+	"github.com/vmware-tanzu/vm-operator/pkg/lib"
+	"github.com/vmware-tanzu/vm-operator/pkg/topology"
+	"github.com/vmware-tanzu/vm-operator/webhooks/common"
+)
+
+const (
+	webHookName = "default"
+
+	targetRequired                 = "at least one of zone, host or storageClass must be specified"
+	zoneNotSupported               = "zones are not supported"
+	storageClassNotFoundFmt        = "Storage policy is not associated with the namespace %s"
+	storageClassNotAssignedFmt     = "Storage policy is not associated with the namespace %s"
+	storageResourceQuotaStrPattern = ".storageclass.storage.k8s.io/"
+	storageClassWithVolumes        = "the VM has PersistentVolumeClaim volumes that cannot be migrated to another storage class"
+)
+
+// +kubebuilder:webhook:verbs=create;update,path=/default-validate-vmoperator-vmware-com-v1alpha1-virtualmachinemigrationrequest,mutating=false,failurePolicy=fail,groups=vmoperator.vmware.com,resources=virtualmachinemigrationrequests,versions=v1alpha1,name=default.validating.virtualmachinemigrationrequest.vmoperator.vmware.com,sideEffects=None,admissionReviewVersions=v1;v1beta1
+// +kubebuilder:rbac:groups=vmoperator.vmware.com,resources=virtualmachinemigrationrequests,verbs=get;list
+// +kubebuilder:rbac:groups=vmoperator.vmware.com,resources=virtualmachinemigrationrequests/status,verbs=get
+
+// AddToManager adds the webhook to the provided manager.
+func AddToManager(ctx *context.ControllerManagerContext, mgr ctrlmgr.Manager) error {
+	hook, err := builder.NewValidatingWebhook(ctx, mgr, webHookName, NewValidator(mgr.GetClient()))
+	if err != nil {
+		return errors.Wrapf(err, "failed to create VirtualMachineMigrationRequest validation webhook")
+	}
+	mgr.GetWebhookServer().Register(hook.Path, hook)
+
+	return nil
+}
+
+// NewValidator returns the package's Validator.
+func NewValidator(client client.Client) builder.Validator {
+	return validator{
+		client:    client,
+		converter: runtime.DefaultUnstructuredConverter,
+	}
+}
+
+type validator struct {
+	client    client.Client
+	converter runtime.UnstructuredConverter
+}
+
+func (v validator) For() schema.GroupVersionKind {
+	return vmopv1.SchemeGroupVersion.WithKind(reflect.TypeOf(vmopv1.VirtualMachineMigrationRequest{}).Name())
+}
+
+func (v validator) ValidateCreate(ctx *context.WebhookRequestContext) admission.Response {
+	vmMigrateReq, err := v.vmMigrationRequestFromUnstructured(ctx.Obj)
+	if err != nil {
+		return webhook.Errored(http.StatusBadRequest, err)
+	}
+
+	var fieldErrs field.ErrorList
+
+	if vmMigrateReq.Spec.VMName == "" {
+		fieldErrs = append(fieldErrs, field.Required(field.NewPath("spec", "vmName"), ""))
+	}
+	fieldErrs = append(fieldErrs, v.validateTarget(ctx, vmMigrateReq)...)
+
+	validationErrs := make([]string, 0, len(fieldErrs))
+	for _, fieldErr := range fieldErrs {
+		validationErrs = append(validationErrs, fieldErr.Error())
+	}
+
+	return common.BuildValidationResponse(ctx, validationErrs, nil)
+}
+
+func (v validator) ValidateDelete(*context.WebhookRequestContext) admission.Response {
+	return admission.Allowed("")
+}
+
+func (v validator) ValidateUpdate(ctx *context.WebhookRequestContext) admission.Response {
+	vmMigrateReq, err := v.vmMigrationRequestFromUnstructured(ctx.Obj)
+	if err != nil {
+		return webhook.Errored(http.StatusBadRequest, err)
+	}
+
+	oldVMMigrateReq, err := v.vmMigrationRequestFromUnstructured(ctx.OldObj)
+	if err != nil {
+		return webhook.Errored(http.StatusBadRequest, err)
+	}
+
+	var fieldErrs field.ErrorList
+
+	fieldErrs = append(fieldErrs, v.validateImmutableFields(vmMigrateReq, oldVMMigrateReq)...)
+
+	validationErrs := make([]string, 0, len(fieldErrs))
+	for _, fieldErr := range fieldErrs {
+		validationErrs = append(validationErrs, fieldErr.Error())
+	}
+
+	return common.BuildValidationResponse(ctx, validationErrs, nil)
+}
+
+func (v validator) validateTarget(
+	ctx *context.WebhookRequestContext,
+	vmMigrateReq *vmopv1.VirtualMachineMigrationRequest) field.ErrorList {
+
+	var allErrs field.ErrorList
+
+	targetPath := field.NewPath("spec", "target")
+	target := vmMigrateReq.Spec.Target
+
+	if target == (vmopv1.VirtualMachineMigrationRequestLocation{}) {
+		return append(allErrs, field.Required(targetPath, targetRequired))
+	}
+
+	if zone := target.Zone; zone != "" {
+		if !lib.IsWcpFaultDomainsFSSEnabled() {
+			allErrs = append(allErrs, field.Forbidden(targetPath.Child("zone"), zoneNotSupported))
+		} else if _, err := topology.GetAvailabilityZone(ctx, v.client, zone); err != nil {
+			allErrs = append(allErrs, field.Invalid(targetPath.Child("zone"), zone, err.Error()))
+		}
+	}
+
+	if scName := target.StorageClass; scName != "" {
+		allErrs = append(allErrs, v.validateStorageClass(ctx, targetPath.Child("storageClass"), scName,
+			vmMigrateReq.Namespace)...)
+		allErrs = append(allErrs, v.validateVMVolumes(ctx, targetPath.Child("storageClass"), vmMigrateReq)...)
+	}
+
+	return allErrs
+}
+
+// validateStorageClass validates the StorageClass exists and is assigned to the namespace.
+func (v validator) validateStorageClass(
+	ctx *context.WebhookRequestContext,
+	scPath *field.Path,
+	scName, namespace string) field.ErrorList {
+
+	var allErrs field.ErrorList
+
+	sc := &storagev1.StorageClass{}
+	if err := v.client.Get(ctx, client.ObjectKey{Name: scName}, sc); err != nil {
+		return append(allErrs, field.Invalid(scPath, scName,
+			fmt.Sprintf(storageClassNotFoundFmt, namespace)))
+	}
+
+	resourceQuotas := &corev1.ResourceQuotaList{}
+	if err := v.client.List(ctx, resourceQuotas, client.InNamespace(namespace)); err != nil {
+		return append(allErrs, field.Invalid(scPath, scName, err.Error()))
+	}
+
+	prefix := scName + storageResourceQuotaStrPattern
+	for _, resourceQuota := range resourceQuotas.Items {
+		for resourceName := range resourceQuota.Spec.Hard {
+			if strings.HasPrefix(resourceName.String(), prefix) {
+				return nil
+			}
+		}
+	}
+
+	return append(allErrs, field.Invalid(scPath, scName,
+		fmt.Sprintf(storageClassNotAssignedFmt, namespace)))
+}
+
+// validateVMVolumes validates the VM does not have PVC volumes since they are not migrated with the
+// VM to the target StorageClass. A VM that does not exist yet is validated when it is migrated.
+func (v validator) validateVMVolumes(
+	ctx *context.WebhookRequestContext,
+	scPath *field.Path,
+	vmMigrateReq *vmopv1.VirtualMachineMigrationRequest) field.ErrorList {
+
+	var allErrs field.ErrorList
+
+	vm := &vmopv1.VirtualMachine{}
+	if err := v.client.Get(ctx, client.ObjectKey{Name: vmMigrateReq.Spec.VMName, Namespace: vmMigrateReq.Namespace}, vm); err != nil {
+		if !apierrors.IsNotFound(err) {
+			allErrs = append(allErrs, field.InternalError(scPath, err))
+		}
+		return allErrs
+	}
+
+	for _, vol := range vm.Spec.Volumes {
+		if vol.PersistentVolumeClaim != nil {
+			return append(allErrs, field.Forbidden(scPath, storageClassWithVolumes))
+		}
+	}
+
+	return allErrs
+}
+
+func (v validator) validateImmutableFields(vmMigrateReq, oldVMMigrateReq *vmopv1.VirtualMachineMigrationRequest) field.ErrorList {
+	var allErrs field.ErrorList
+	specPath := field.NewPath("spec")
+
+	allErrs = append(allErrs, validation.ValidateImmutableField(vmMigrateReq.Spec.VMName,
+		oldVMMigrateReq.Spec.VMName, specPath.Child("vmName"))...)
+	allErrs = append(allErrs, validation.ValidateImmutableField(vmMigrateReq.Spec.Target,
+		oldVMMigrateReq.Spec.Target, specPath.Child("target"))...)
+
+	return allErrs
+}
+
+// vmMigrationRequestFromUnstructured returns the VirtualMachineMigrationRequest from the unstructured object.
+func (v validator) vmMigrationRequestFromUnstructured(obj runtime.Unstructured) (*vmopv1.VirtualMachineMigrationRequest, error) {
+	vmMigrateReq := &vmopv1.VirtualMachineMigrationRequest{}
+	if err := v.converter.FromUnstructured(obj.UnstructuredContent(), vmMigrateReq); err != nil {
+		return nil, err
+	}
+	return vmMigrateReq, nil
+}
@@ -0,0 +1,266 @@
+// Copyright (c) 2023 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package validation_test
+
+import (
+	"fmt"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	vmopv1 "github.com/vmware-tanzu/vm-operator/api/v1alpha1"
+
+	"github.com/vmware-tanzu/vm-operator/pkg/lib"
+	"github.com/vmware-tanzu/vm-operator/test/builder"
+)
+
+func unitTests() {
+	Describe("Invoking ValidateCreate", unitTestsValidateCreate)
+	Describe("Invoking ValidateUpdate", unitTestsValidateUpdate)
+	Describe("Invoking ValidateDelete", unitTestsValidateDelete)
+}
+
+type unitValidatingWebhookContext struct {
+	builder.UnitTestContextForValidatingWebhook
+	vmMigrateReq    *vmopv1.VirtualMachineMigrationRequest
+	oldVMMigrateReq *vmopv1.VirtualMachineMigrationRequest
+}
+
+func newUnitTestContextForValidatingWebhook(isUpdate bool) *unitValidatingWebhookContext {
+	vmMigrateReq := builder.DummyVirtualMachineMigrationRequest("dummy-migrate", "dummy-ns", "dummy-vm",
+		builder.DummyAvailabilityZoneName)
+	obj, err := builder.ToUnstructured(vmMigrateReq)
+	Expect(err).ToNot(HaveOccurred())
+
+	var oldVMMigrateReq *vmopv1.VirtualMachineMigrationRequest
+	var oldObj *unstructured.Unstructured
+
+	if isUpdate {
+		oldVMMigrateReq = vmMigrateReq.DeepCopy()
+		oldObj, err = builder.ToUnstructured(oldVMMigrateReq)
+		Expect(err).ToNot(HaveOccurred())
+	}
+
+	az := builder.DummyAvailabilityZone()
+	storageClass := builder.DummyStorageClass()
+	resourceQuota := builder.DummyResourceQuota(vmMigrateReq.Namespace,
+		storageClass.Name+".storageclass.storage.k8s.io/persistentvolumeclaims")
+	initObjects := []client.Object{az, storageClass, resourceQuota}
+
+	return &unitValidatingWebhookContext{
+		UnitTestContextForValidatingWebhook: *suite.NewUnitTestContextForValidatingWebhook(obj, oldObj, initObjects...),
+		vmMigrateReq:                        vmMigrateReq,
+		oldVMMigrateReq:                     oldVMMigrateReq,
+	}
+}
+
+func unitTestsValidateCreate() {
+	var (
+		ctx *unitValidatingWebhookContext
+		err error
+
+		oldFaultDomainsFunc func() bool
+	)
+
+	type createArgs struct {
+		emptyVMName             bool
+		emptyTarget             bool
+		zonesDisabled           bool
+		invalidZone             bool
+		validStorageClass       bool
+		invalidStorageClass     bool
+		unassignedStorageClass  bool
+		hostAndStorageClassOnly bool
+		vmWithVolumes           bool
+		vmWithoutVolumes        bool
+	}
+
+	validateCreate := func(args createArgs, expectedAllowed bool, expectedReason string, expectedErr error) {
+		if args.emptyVMName {
+			ctx.vmMigrateReq.Spec.VMName = ""
+		}
+
+		if args.emptyTarget {
+			ctx.vmMigrateReq.Spec.Target = vmopv1.VirtualMachineMigrationRequestLocation{}
+		}
+
+		if args.zonesDisabled {
+			lib.IsWcpFaultDomainsFSSEnabled = func() bool { return false }
+		}
+
+		if args.invalidZone {
+			ctx.vmMigrateReq.Spec.Target.Zone = "invalid-zone"
+		}
+
+		if args.validStorageClass {
+			ctx.vmMigrateReq.Spec.Target.StorageClass = builder.DummyStorageClassName
+		}
+
+		if args.invalidStorageClass {
+			ctx.vmMigrateReq.Spec.Target.StorageClass = "invalid-sc"
+		}
+
+		if args.unassignedStorageClass {
+			Expect(ctx.Client.DeleteAllOf(ctx, &corev1.ResourceQuota{}, client.InNamespace(ctx.vmMigrateReq.Namespace))).To(Succeed())
+			ctx.vmMigrateReq.Spec.Target.StorageClass = builder.DummyStorageClassName
+		}
+
+		if args.hostAndStorageClassOnly {
+			ctx.vmMigrateReq.Spec.Target = vmopv1.VirtualMachineMigrationRequestLocation{
+				Host:         "esx-1",
+				StorageClass: builder.DummyStorageClassName,
+			}
+		}
+
+		if args.vmWithVolumes || args.vmWithoutVolumes {
+			vm := builder.DummyVirtualMachine()
+			vm.Name = ctx.vmMigrateReq.Spec.VMName
+			vm.Namespace = ctx.vmMigrateReq.Namespace
+			if args.vmWithoutVolumes {
+				vm.Spec.Volumes = nil
+			}
+			Expect(ctx.Client.Create(ctx, vm)).To(Succeed())
+			ctx.vmMigrateReq.Spec.Target.StorageClass = builder.DummyStorageClassName
+		}
+
+		ctx.WebhookRequestContext.Obj, err = builder.ToUnstructured(ctx.vmMigrateReq)
+		Expect(err).ToNot(HaveOccurred())
+
+		response := ctx.ValidateCreate(&ctx.WebhookRequestContext)
+		Expect(response.Allowed).To(Equal(expectedAllowed))
+		if expectedReason != "" {
+			Expect(string(response.Result.Reason)).To(ContainSubstring(expectedReason))
+		}
+		if expectedErr != nil {
+			Expect(response.Result.Message).To(Equal(expectedErr.Error()))
+		}
+	}
+
+	BeforeEach(func() {
+		ctx = newUnitTestContextForValidatingWebhook(false)
+		oldFaultDomainsFunc = lib.IsWcpFaultDomainsFSSEnabled
+		lib.IsWcpFaultDomainsFSSEnabled = func() bool { return true }
+	})
+
+	AfterEach(func() {
+		ctx = nil
+		lib.IsWcpFaultDomainsFSSEnabled = oldFaultDomainsFunc
+	})
+
+	targetPath := field.NewPath("spec", "target")
+	DescribeTable("create table", validateCreate,
+		Entry("should allow valid", createArgs{}, true, nil, nil),
+		Entry("should allow valid storage class", createArgs{validStorageClass: true}, true, nil, nil),
+		Entry("should allow host and storage class without zone", createArgs{zonesDisabled: true, hostAndStorageClassOnly: true},
+			true, nil, nil),
+		Entry("should allow storage class for a VM without volumes", createArgs{vmWithoutVolumes: true}, true, nil, nil),
+		Entry("should deny storage class for a VM with volumes", createArgs{vmWithVolumes: true}, false,
+			field.Forbidden(targetPath.Child("storageClass"),
+				"the VM has PersistentVolumeClaim volumes that cannot be migrated to another storage class").Error(), nil),
+		Entry("should deny empty vmName", createArgs{emptyVMName: true}, false,
+			field.Required(field.NewPath("spec", "vmName"), "").Error(), nil),
+		Entry("should deny empty target", createArgs{emptyTarget: true}, false,
+			field.Required(targetPath, "at least one of zone, host or storageClass must be specified").Error(), nil),
+		Entry("should deny zone when zones are not supported", createArgs{zonesDisabled: true}, false,
+			field.Forbidden(targetPath.Child("zone"), "zones are not supported").Error(), nil),
+		Entry("should deny invalid zone", createArgs{invalidZone: true}, false,
+			"spec.target.zone: Invalid value: \"invalid-zone\"", nil),
+		Entry("should deny invalid storage class", createArgs{invalidStorageClass: true}, false,
+			field.Invalid(targetPath.Child("storageClass"), "invalid-sc",
+				fmt.Sprintf("Storage policy is not associated with the namespace %s", "dummy-ns")).Error(), nil),
+		Entry("should deny storage class not assigned to the namespace", createArgs{unassignedStorageClass: true}, false,
+			field.Invalid(targetPath.Child("storageClass"), builder.DummyStorageClassName,
+				fmt.Sprintf("Storage policy is not associated with the namespace %s", "dummy-ns")).Error(), nil),
+	)
+}
+
+func unitTestsValidateUpdate() {
+	var (
+		ctx      *unitValidatingWebhookContext
+		response admission.Response
+	)
+
+	BeforeEach(func() {
+		ctx = newUnitTestContextForValidatingWebhook(true)
+	})
+
+	AfterEach(func() {
+		ctx = nil
+	})
+
+	JustBeforeEach(func() {
+		var err error
+		ctx.WebhookRequestContext.Obj, err = builder.ToUnstructured(ctx.vmMigrateReq)
+		Expect(err).ToNot(HaveOccurred())
+		response = ctx.ValidateUpdate(&ctx.WebhookRequestContext)
+	})
+
+	Context("TTLSecondsAfterFinished is updated", func() {
+		BeforeEach(func() {
+			ttl := int64(60)
+			ctx.vmMigrateReq.Spec.TTLSecondsAfterFinished = &ttl
+		})
+
+		It("should allow the request", func() {
+			Expect(response.Allowed).To(BeTrue())
+		})
+	})
+
+	Context("VMName is updated", func() {
+		BeforeEach(func() {
+			ctx.vmMigrateReq.Spec.VMName = "updated-vm"
+		})
+
+		It("should not allow the request", func() {
+			Expect(response.Allowed).To(BeFalse())
+			Expect(response.Result).ToNot(BeNil())
+			Expect(string(response.Result.Reason)).To(ContainSubstring("spec.vmName: Invalid value"))
+		})
+	})
+
+	Context("Target is updated", func() {
+		BeforeEach(func() {
+			ctx.vmMigrateReq.Spec.Target.Host = "esx-2"
+		})
+
+		It("should not allow the request", func() {
+			Expect(response.Allowed).To(BeFalse())
+			Expect(response.Result).ToNot(BeNil())
+			Expect(string(response.Result.Reason)).To(ContainSubstring("field is immutable"))
+		})
+	})
+}
+
+func unitTestsValidateDelete() {
+	var (
+		ctx      *unitValidatingWebhookContext
+		response admission.Response
+	)
+
+	BeforeEach(func() {
+		ctx = newUnitTestContextForValidatingWebhook(false)
+	})
+
+	AfterEach(func() {
+		ctx = nil
+	})
+
+	When("the delete is performed", func() {
+		JustBeforeEach(func() {
+			response = ctx.ValidateDelete(&ctx.WebhookRequestContext)
+		})
+
+		It("should allow the request", func() {
+			Expect(response.Allowed).To(BeTrue())
+			Expect(response.Result).ToNot(BeNil())
+		})
+	})
+}
@@ -25,6 +25,7 @@ import (
 
 	"github.com/vmware-tanzu/vm-operator/pkg/builder"
 	"github.com/vmware-tanzu/vm-operator/pkg/context"
+	"github.com/vmware-tanzu/vm-operator/pkg/ociregistry"
 	"github.com/vmware-tanzu/vm-operator/webhooks/common"
 )
 
@@ -138,6 +139,10 @@ func (v validator) validateTargetLocation(ctx *context.WebhookRequestContext, vm
 
 	targetLocationPath := field.NewPath("spec").Child("target").
 		Child("location")
+	if vmpub.Spec.Target.Location.OCI != nil {
+		return v.validateOCITargetLocation(vmpub)
+	}
+
 	targetLocationName := vmpub.Spec.Target.Location.Name
 	targetLocationNamePath := targetLocationPath.Child("name")
 	if targetLocationName == "" {
@@ -157,6 +162,39 @@ func (v validator) validateTargetLocation(ctx *context.WebhookRequestContext, vm
 	return allErrs
 }
 
+func (v validator) validateOCITargetLocation(vmpub *vmopv1.VirtualMachinePublishRequest) field.ErrorList {
+	var allErrs field.ErrorList
+
+	targetPath := field.NewPath("spec").Child("target")
+	targetLocationPath := targetPath.Child("location")
+	if vmpub.Spec.Target.Location.Name != "" {
+		allErrs = append(allErrs, field.Forbidden(targetLocationPath.Child("name"),
+			"name must be empty when publishing to an OCI registry"))
+	}
+
+	oci := vmpub.Spec.Target.Location.OCI
+	ociPath := targetLocationPath.Child("oci")
+	if oci.Repository == "" {
+		allErrs = append(allErrs, field.Required(ociPath.Child("repository"), ""))
+	} else if _, err := ociregistry.ParseRepository(oci.Repository); err != nil {
+		allErrs = append(allErrs, field.Invalid(ociPath.Child("repository"), oci.Repository, err.Error()))
+	}
+
+	if oci.SecretName != "" {
+		for _, msg := range validation.NameIsDNSSubdomain(oci.SecretName, false) {
+			allErrs = append(allErrs, field.Invalid(ociPath.Child("secretName"), oci.SecretName, msg))
+		}
+	}
+
+	// The artifact is tagged with the item name.
+	if itemName := vmpub.Spec.Target.Item.Name; itemName != "" && !ociregistry.IsValidTag(itemName) {
+		allErrs = append(allErrs, field.Invalid(targetPath.Child("item").Child("name"), itemName,
+			"must be a valid OCI tag when publishing to an OCI registry"))
+	}
+
+	return allErrs
+}
+
 func (v validator) validateImmutableFields(vmpub, oldvmpub *vmopv1.VirtualMachinePublishRequest) field.ErrorList {
 	var allErrs field.ErrorList
 	specPath := field.NewPath("spec")
@@ -84,6 +84,12 @@ func unitTestsValidateCreate() {
 		targetLocationNameEmpty         bool
 		targetLocationNotFound          bool
 		targetItemAlreadyExists         bool
+		ociTarget                       bool
+		ociTargetWithName               bool
+		ociRepositoryEmpty              bool
+		ociRepositoryInvalid            bool
+		ociSecretNameInvalid            bool
+		ociItemNameInvalid              bool
 	}
 
 	validateCreate := func(args createArgs, expectedAllowed bool, expectedReason string, expectedErr error) {
@@ -138,6 +144,35 @@ func unitTestsValidateCreate() {
 			Expect(ctx.Client.Status().Update(ctx, clItem)).To(Succeed())
 		}
 
+		if args.ociTarget {
+			ctx.vmPub.Spec.Target.Location = vmopv1.VirtualMachinePublishRequestTargetLocation{
+				OCI: &vmopv1.VirtualMachinePublishRequestTargetOCILocation{
+					Repository: "registry.example.com/vms/dummy",
+					SecretName: "dummy-secret",
+				},
+			}
+		}
+
+		if args.ociTargetWithName {
+			ctx.vmPub.Spec.Target.Location.Name = ctx.cl.Name
+		}
+
+		if args.ociRepositoryEmpty {
+			ctx.vmPub.Spec.Target.Location.OCI.Repository = ""
+		}
+
+		if args.ociRepositoryInvalid {
+			ctx.vmPub.Spec.Target.Location.OCI.Repository = "registry.example.com/vms/dummy:v1"
+		}
+
+		if args.ociSecretNameInvalid {
+			ctx.vmPub.Spec.Target.Location.OCI.SecretName = "Dummy_Secret"
+		}
+
+		if args.ociItemNameInvalid {
+			ctx.vmPub.Spec.Target.Item.Name = "dummy/item"
+		}
+
 		ctx.WebhookRequestContext.Obj, err = builder.ToUnstructured(ctx.vmPub)
 		Expect(err).ToNot(HaveOccurred())
 
@@ -164,6 +199,7 @@ func unitTestsValidateCreate() {
 
 	sourcePath := field.NewPath("spec").Child("source")
 	targetLocationPath := field.NewPath("spec").Child("target", "location")
+	ociPath := targetLocationPath.Child("oci")
 	DescribeTable("create table", validateCreate,
 		Entry("should allow valid", createArgs{}, true, nil, nil),
 		Entry("should deny invalid source API version", createArgs{invalidSourceAPIVersion: true}, false,
@@ -180,6 +216,18 @@ func unitTestsValidateCreate() {
 				[]string{"ContentLibrary", ""}).Error(), nil),
 		Entry("should deny if target location name is empty", createArgs{targetLocationNameEmpty: true}, false,
 			field.Required(targetLocationPath.Child("name"), "").Error(), nil),
+		Entry("should allow valid OCI target", createArgs{ociTarget: true}, true, nil, nil),
+		Entry("should deny OCI target with location name", createArgs{ociTarget: true, ociTargetWithName: true}, false,
+			field.Forbidden(targetLocationPath.Child("name"),
+				"name must be empty when publishing to an OCI registry").Error(), nil),
+		Entry("should deny OCI target with empty repository", createArgs{ociTarget: true, ociRepositoryEmpty: true}, false,
+			field.Required(ociPath.Child("repository"), "").Error(), nil),
+		Entry("should deny OCI target with invalid repository", createArgs{ociTarget: true, ociRepositoryInvalid: true}, false,
+			"spec.target.location.oci.repository: Invalid value", nil),
+		Entry("should deny OCI target with invalid secret name", createArgs{ociTarget: true, ociSecretNameInvalid: true}, false,
+			"spec.target.location.oci.secretName: Invalid value", nil),
+		Entry("should deny OCI target with invalid item name", createArgs{ociTarget: true, ociItemNameInvalid: true}, false,
+			"spec.target.item.name: Invalid value", nil),
 	)
 }
 
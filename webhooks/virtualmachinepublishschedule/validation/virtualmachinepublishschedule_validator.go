@@ -0,0 +1,218 @@
+// Copyright (c) 2023 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package validation
+
+import (
+	"fmt"
+	"net/http"
+	"reflect"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/robfig/cron/v3"
+	"k8s.io/apimachinery/pkg/api/validation"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	ctrlmgr "sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	vmopv1 "github.com/vmware-tanzu/vm-operator/api/v1alpha1"
+
+	imgregv1a1 "github.com/vmware-tanzu/vm-operator/external/image-registry/api/v1alpha1"
+
+	"github.com/vmware-tanzu/vm-operator/controllers/virtualmachinepublishschedule"
+	"github.com/vmware-tanzu/vm-operator/pkg/builder"
+	"github.com/vmware-tanzu/vm-operator/pkg/context"
+	"github.com/vmware-tanzu/vm-operator/pkg/lib"
+	"github.com/vmware-tanzu/vm-operator/webhooks/common"
+)
+
+const (
+	webHookName = "default"
+)
+
+// +kubebuilder:webhook:verbs=create;update,path=/default-validate-vmoperator-vmware-com-v1alpha1-virtualmachinepublishschedule,mutating=false,failurePolicy=fail,groups=vmoperator.vmware.com,resources=virtualmachinepublishschedules,versions=v1alpha1,name=default.validating.virtualmachinepublishschedule.vmoperator.vmware.com,sideEffects=None,admissionReviewVersions=v1;v1beta1
+// +kubebuilder:rbac:groups=vmoperator.vmware.com,resources=virtualmachinepublishschedules,verbs=get;list
+// +kubebuilder:rbac:groups=vmoperator.vmware.com,resources=virtualmachinepublishschedules/status,verbs=get
+
+// AddToManager adds the webhook to the provided manager.
+func AddToManager(ctx *context.ControllerManagerContext, mgr ctrlmgr.Manager) error {
+	hook, err := builder.NewValidatingWebhook(ctx, mgr, webHookName, NewValidator(mgr.GetClient()))
+	if err != nil {
+		return errors.Wrapf(err, "failed to create VirtualMachinePublishSchedule validation webhook")
+	}
+	mgr.GetWebhookServer().Register(hook.Path, hook)
+
+	return nil
+}
+
+// NewValidator returns the package's Validator.
+func NewValidator(client client.Client) builder.Validator {
+	return validator{
+		client:    client,
+		converter: runtime.DefaultUnstructuredConverter,
+	}
+}
+
+type validator struct {
+	client    client.Client
+	converter runtime.UnstructuredConverter
+}
+
+func (v validator) For() schema.GroupVersionKind {
+	return vmopv1.SchemeGroupVersion.WithKind(reflect.TypeOf(vmopv1.VirtualMachinePublishSchedule{}).Name())
+}
+
+func (v validator) ValidateCreate(ctx *context.WebhookRequestContext) admission.Response {
+	if !lib.IsWCPVMImageRegistryEnabled() {
+		return common.BuildValidationResponse(ctx, []string{"WCP_VM_Image_Registry feature not enabled"}, nil)
+	}
+
+	vmPubSched, err := v.vmPublishScheduleFromUnstructured(ctx.Obj)
+	if err != nil {
+		return webhook.Errored(http.StatusBadRequest, err)
+	}
+
+	var fieldErrs field.ErrorList
+
+	fieldErrs = append(fieldErrs, v.validateName(vmPubSched)...)
+	fieldErrs = append(fieldErrs, v.validateSpec(vmPubSched)...)
+
+	validationErrs := make([]string, 0, len(fieldErrs))
+	for _, fieldErr := range fieldErrs {
+		validationErrs = append(validationErrs, fieldErr.Error())
+	}
+
+	return common.BuildValidationResponse(ctx, validationErrs, nil)
+}
+
+func (v validator) ValidateDelete(*context.WebhookRequestContext) admission.Response {
+	return admission.Allowed("")
+}
+
+func (v validator) ValidateUpdate(ctx *context.WebhookRequestContext) admission.Response {
+	vmPubSched, err := v.vmPublishScheduleFromUnstructured(ctx.Obj)
+	if err != nil {
+		return webhook.Errored(http.StatusBadRequest, err)
+	}
+
+	oldVMPubSched, err := v.vmPublishScheduleFromUnstructured(ctx.OldObj)
+	if err != nil {
+		return webhook.Errored(http.StatusBadRequest, err)
+	}
+
+	var fieldErrs field.ErrorList
+
+	fieldErrs = append(fieldErrs, v.validateSpec(vmPubSched)...)
+	fieldErrs = append(fieldErrs, v.validateImmutableFields(vmPubSched, oldVMPubSched)...)
+
+	validationErrs := make([]string, 0, len(fieldErrs))
+	for _, fieldErr := range fieldErrs {
+		validationErrs = append(validationErrs, fieldErr.Error())
+	}
+
+	return common.BuildValidationResponse(ctx, validationErrs, nil)
+}
+
+// validateName validates that the names of the spawned VirtualMachinePublishRequests, which are
+// suffixed with the time the publication was scheduled, are valid.
+func (v validator) validateName(vmPubSched *vmopv1.VirtualMachinePublishSchedule) field.ErrorList {
+	var allErrs field.ErrorList
+
+	pubReqName := fmt.Sprintf("%s-%s", vmPubSched.Name, virtualmachinepublishschedule.ItemNameTimeFormat)
+	if msgs := validation.NameIsDNSSubdomain(pubReqName, false); len(msgs) > 0 {
+		allErrs = append(allErrs, field.Invalid(field.NewPath("metadata", "name"), vmPubSched.Name,
+			fmt.Sprintf("must be a valid name when suffixed with the scheduled time: %s", strings.Join(msgs, ", "))))
+	}
+
+	return allErrs
+}
+
+func (v validator) validateSpec(vmPubSched *vmopv1.VirtualMachinePublishSchedule) field.ErrorList {
+	var allErrs field.ErrorList
+	specPath := field.NewPath("spec")
+
+	if _, err := cron.ParseStandard(vmPubSched.Spec.Schedule); err != nil {
+		allErrs = append(allErrs, field.Invalid(specPath.Child("schedule"), vmPubSched.Spec.Schedule, err.Error()))
+	}
+
+	if retentionCount := vmPubSched.Spec.RetentionCount; retentionCount != nil && *retentionCount < 1 {
+		allErrs = append(allErrs, field.Invalid(specPath.Child("retentionCount"), *retentionCount,
+			"must be greater than or equal to 1"))
+	}
+
+	allErrs = append(allErrs, v.validateSource(vmPubSched)...)
+	allErrs = append(allErrs, v.validateTargetLocation(vmPubSched)...)
+
+	return allErrs
+}
+
+func (v validator) validateSource(vmPubSched *vmopv1.VirtualMachinePublishSchedule) field.ErrorList {
+	var allErrs field.ErrorList
+
+	sourcePath := field.NewPath("spec").Child("source")
+	if apiVersion := vmPubSched.Spec.Source.APIVersion; apiVersion != vmopv1.SchemeGroupVersion.String() && apiVersion != "" {
+		allErrs = append(allErrs, field.NotSupported(sourcePath.Child("apiVersion"),
+			vmPubSched.Spec.Source.APIVersion, []string{vmopv1.SchemeGroupVersion.String(), ""}))
+	}
+
+	if kind := vmPubSched.Spec.Source.Kind; kind != reflect.TypeOf(vmopv1.VirtualMachine{}).Name() && kind != "" {
+		allErrs = append(allErrs, field.NotSupported(sourcePath.Child("kind"),
+			vmPubSched.Spec.Source.Kind, []string{reflect.TypeOf(vmopv1.VirtualMachine{}).Name(), ""}))
+	}
+
+	return allErrs
+}
+
+func (v validator) validateTargetLocation(vmPubSched *vmopv1.VirtualMachinePublishSchedule) field.ErrorList {
+	var allErrs field.ErrorList
+
+	targetLocationPath := field.NewPath("spec").Child("target").Child("location")
+	location := vmPubSched.Spec.Target.Location
+
+	// Older items cannot be garbage collected from a registry, so only content libraries are supported.
+	if location.OCI != nil {
+		allErrs = append(allErrs, field.Forbidden(targetLocationPath.Child("oci"),
+			"publishing to an OCI registry is not supported by VirtualMachinePublishSchedule"))
+	}
+
+	if location.Name == "" {
+		allErrs = append(allErrs, field.Required(targetLocationPath.Child("name"), ""))
+	}
+
+	if location.APIVersion != imgregv1a1.GroupVersion.String() {
+		allErrs = append(allErrs, field.NotSupported(targetLocationPath.Child("apiVersion"),
+			location.APIVersion, []string{imgregv1a1.GroupVersion.String()}))
+	}
+
+	if location.Kind != reflect.TypeOf(imgregv1a1.ContentLibrary{}).Name() {
+		allErrs = append(allErrs, field.NotSupported(targetLocationPath.Child("kind"),
+			location.Kind, []string{reflect.TypeOf(imgregv1a1.ContentLibrary{}).Name()}))
+	}
+
+	return allErrs
+}
+
+func (v validator) validateImmutableFields(vmPubSched, oldVMPubSched *vmopv1.VirtualMachinePublishSchedule) field.ErrorList {
+	var allErrs field.ErrorList
+	targetPath := field.NewPath("spec").Child("target")
+
+	// The previously published items are deleted from the target location, so it cannot be updated.
+	allErrs = append(allErrs, validation.ValidateImmutableField(vmPubSched.Spec.Target.Location,
+		oldVMPubSched.Spec.Target.Location, targetPath.Child("location"))...)
+
+	return allErrs
+}
+
+// vmPublishScheduleFromUnstructured returns the VirtualMachinePublishSchedule from the unstructured object.
+func (v validator) vmPublishScheduleFromUnstructured(obj runtime.Unstructured) (*vmopv1.VirtualMachinePublishSchedule, error) {
+	vmPubSched := &vmopv1.VirtualMachinePublishSchedule{}
+	if err := v.converter.FromUnstructured(obj.UnstructuredContent(), vmPubSched); err != nil {
+		return nil, err
+	}
+	return vmPubSched, nil
+}
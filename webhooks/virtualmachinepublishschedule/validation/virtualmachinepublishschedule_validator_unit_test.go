@@ -0,0 +1,246 @@
+// Copyright (c) 2023 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package validation_test
+
+import (
+	"strings"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	vmopv1 "github.com/vmware-tanzu/vm-operator/api/v1alpha1"
+
+	"github.com/vmware-tanzu/vm-operator/pkg/lib"
+	"github.com/vmware-tanzu/vm-operator/test/builder"
+)
+
+func unitTests() {
+	Describe("Invoking ValidateCreate", unitTestsValidateCreate)
+	Describe("Invoking ValidateUpdate", unitTestsValidateUpdate)
+	Describe("Invoking ValidateDelete", unitTestsValidateDelete)
+}
+
+type unitValidatingWebhookContext struct {
+	builder.UnitTestContextForValidatingWebhook
+	vmPubSched    *vmopv1.VirtualMachinePublishSchedule
+	oldVMPubSched *vmopv1.VirtualMachinePublishSchedule
+}
+
+func newUnitTestContextForValidatingWebhook(isUpdate bool) *unitValidatingWebhookContext {
+	vmPubSched := builder.DummyVirtualMachinePublishSchedule("dummy-sched", "dummy-ns", "dummy-vm",
+		"dummy-item", "dummy-cl")
+	obj, err := builder.ToUnstructured(vmPubSched)
+	Expect(err).ToNot(HaveOccurred())
+
+	var oldVMPubSched *vmopv1.VirtualMachinePublishSchedule
+	var oldObj *unstructured.Unstructured
+
+	if isUpdate {
+		oldVMPubSched = vmPubSched.DeepCopy()
+		oldObj, err = builder.ToUnstructured(oldVMPubSched)
+		Expect(err).ToNot(HaveOccurred())
+	}
+
+	return &unitValidatingWebhookContext{
+		UnitTestContextForValidatingWebhook: *suite.NewUnitTestContextForValidatingWebhook(obj, oldObj),
+		vmPubSched:                          vmPubSched,
+		oldVMPubSched:                       oldVMPubSched,
+	}
+}
+
+func unitTestsValidateCreate() {
+	var (
+		ctx *unitValidatingWebhookContext
+		err error
+
+		invalidAPIVersion = "vmoperator.vmware.com/v1"
+	)
+
+	type createArgs struct {
+		nameTooLong                     bool
+		invalidSchedule                 bool
+		invalidRetentionCount           bool
+		invalidSourceKind               bool
+		invalidTargetLocationAPIVersion bool
+		targetLocationKindEmpty         bool
+		targetLocationNameEmpty         bool
+		ociTarget                       bool
+	}
+
+	validateCreate := func(args createArgs, expectedAllowed bool, expectedReason string, expectedErr error) {
+		if args.nameTooLong {
+			ctx.vmPubSched.Name = strings.Repeat("a", 250)
+		}
+
+		if args.invalidSchedule {
+			ctx.vmPubSched.Spec.Schedule = "0 * *"
+		}
+
+		if args.invalidRetentionCount {
+			retentionCount := int32(0)
+			ctx.vmPubSched.Spec.RetentionCount = &retentionCount
+		}
+
+		if args.invalidSourceKind {
+			ctx.vmPubSched.Spec.Source.Kind = "Machine"
+		}
+
+		if args.invalidTargetLocationAPIVersion {
+			ctx.vmPubSched.Spec.Target.Location.APIVersion = invalidAPIVersion
+		}
+
+		if args.targetLocationKindEmpty {
+			ctx.vmPubSched.Spec.Target.Location.Kind = ""
+		}
+
+		if args.targetLocationNameEmpty {
+			ctx.vmPubSched.Spec.Target.Location.Name = ""
+		}
+
+		if args.ociTarget {
+			ctx.vmPubSched.Spec.Target.Location.OCI = &vmopv1.VirtualMachinePublishRequestTargetOCILocation{
+				Repository: "registry.example.com/vms/dummy",
+			}
+		}
+
+		ctx.WebhookRequestContext.Obj, err = builder.ToUnstructured(ctx.vmPubSched)
+		Expect(err).ToNot(HaveOccurred())
+
+		response := ctx.ValidateCreate(&ctx.WebhookRequestContext)
+		Expect(response.Allowed).To(Equal(expectedAllowed))
+		if expectedReason != "" {
+			Expect(string(response.Result.Reason)).To(ContainSubstring(expectedReason))
+		}
+		if expectedErr != nil {
+			Expect(response.Result.Message).To(Equal(expectedErr.Error()))
+		}
+	}
+
+	BeforeEach(func() {
+		ctx = newUnitTestContextForValidatingWebhook(false)
+		lib.IsWCPVMImageRegistryEnabled = func() bool {
+			return true
+		}
+	})
+
+	AfterEach(func() {
+		ctx = nil
+	})
+
+	specPath := field.NewPath("spec")
+	targetLocationPath := specPath.Child("target", "location")
+	DescribeTable("create table", validateCreate,
+		Entry("should allow valid", createArgs{}, true, nil, nil),
+		Entry("should deny name that is too long", createArgs{nameTooLong: true}, false,
+			"metadata.name: Invalid value", nil),
+		Entry("should deny invalid schedule", createArgs{invalidSchedule: true}, false,
+			"spec.schedule: Invalid value", nil),
+		Entry("should deny invalid retention count", createArgs{invalidRetentionCount: true}, false,
+			field.Invalid(specPath.Child("retentionCount"), int32(0), "must be greater than or equal to 1").Error(), nil),
+		Entry("should deny invalid source kind", createArgs{invalidSourceKind: true}, false,
+			field.NotSupported(specPath.Child("source", "kind"), "Machine",
+				[]string{"VirtualMachine", ""}).Error(), nil),
+		Entry("should deny invalid target location API version", createArgs{invalidTargetLocationAPIVersion: true}, false,
+			field.NotSupported(targetLocationPath.Child("apiVersion"), invalidAPIVersion,
+				[]string{"imageregistry.vmware.com/v1alpha1"}).Error(), nil),
+		Entry("should deny empty target location kind", createArgs{targetLocationKindEmpty: true}, false,
+			field.NotSupported(targetLocationPath.Child("kind"), "",
+				[]string{"ContentLibrary"}).Error(), nil),
+		Entry("should deny if target location name is empty", createArgs{targetLocationNameEmpty: true}, false,
+			field.Required(targetLocationPath.Child("name"), "").Error(), nil),
+		Entry("should deny OCI target", createArgs{ociTarget: true}, false,
+			field.Forbidden(targetLocationPath.Child("oci"),
+				"publishing to an OCI registry is not supported by VirtualMachinePublishSchedule").Error(), nil),
+	)
+}
+
+func unitTestsValidateUpdate() {
+	var (
+		ctx      *unitValidatingWebhookContext
+		response admission.Response
+	)
+
+	BeforeEach(func() {
+		ctx = newUnitTestContextForValidatingWebhook(true)
+	})
+
+	AfterEach(func() {
+		ctx = nil
+	})
+
+	JustBeforeEach(func() {
+		var err error
+		ctx.WebhookRequestContext.Obj, err = builder.ToUnstructured(ctx.vmPubSched)
+		Expect(err).ToNot(HaveOccurred())
+		response = ctx.ValidateUpdate(&ctx.WebhookRequestContext)
+	})
+
+	Context("Schedule and retention count are updated", func() {
+		BeforeEach(func() {
+			retentionCount := int32(5)
+			ctx.vmPubSched.Spec.Schedule = "@daily"
+			ctx.vmPubSched.Spec.RetentionCount = &retentionCount
+			ctx.vmPubSched.Spec.Suspend = true
+		})
+
+		It("should allow the request", func() {
+			Expect(response.Allowed).To(BeTrue())
+		})
+	})
+
+	Context("Schedule is updated to an invalid value", func() {
+		BeforeEach(func() {
+			ctx.vmPubSched.Spec.Schedule = "every day"
+		})
+
+		It("should not allow the request", func() {
+			Expect(response.Allowed).To(BeFalse())
+			Expect(response.Result).ToNot(BeNil())
+			Expect(string(response.Result.Reason)).To(ContainSubstring("spec.schedule: Invalid value"))
+		})
+	})
+
+	Context("Target location is updated", func() {
+		BeforeEach(func() {
+			ctx.vmPubSched.Spec.Target.Location.Name = "updated-cl"
+		})
+
+		It("should not allow the request", func() {
+			Expect(response.Allowed).To(BeFalse())
+			Expect(response.Result).ToNot(BeNil())
+			Expect(string(response.Result.Reason)).To(ContainSubstring("field is immutable"))
+		})
+	})
+}
+
+func unitTestsValidateDelete() {
+	var (
+		ctx      *unitValidatingWebhookContext
+		response admission.Response
+	)
+
+	BeforeEach(func() {
+		ctx = newUnitTestContextForValidatingWebhook(false)
+	})
+
+	AfterEach(func() {
+		ctx = nil
+	})
+
+	When("the delete is performed", func() {
+		JustBeforeEach(func() {
+			response = ctx.ValidateDelete(&ctx.WebhookRequestContext)
+		})
+
+		It("should allow the request", func() {
+			Expect(response.Allowed).To(BeTrue())
+			Expect(response.Result).ToNot(BeNil())
+		})
+	})
+}
@@ -1,4 +1,4 @@
-// Copyright (c) 2019-2021 VMware, Inc. All Rights Reserved.
+// Copyright (c) 2019-2023 VMware, Inc. All Rights Reserved.
 // SPDX-License-Identifier: Apache-2.0
 
 package validation
@@ -6,6 +6,7 @@ package validation
 import (
 	"net/http"
 	"reflect"
+	"strings"
 
 	"k8s.io/apimachinery/pkg/api/resource"
 	"k8s.io/apimachinery/pkg/api/validation"
@@ -137,6 +138,12 @@ func (v validator) validateClusterModules(ctx *context.WebhookRequestContext, fl
 
 	groupNames := map[string]struct{}{}
 	for i, module := range clusterModules {
+		// The groups of a VM are separated by commas in its cluster module annotation.
+		if strings.Contains(module.GroupName, ",") {
+			fieldErrs = append(fieldErrs, field.Invalid(fldPath.Index(i).Child("groupname"), module.GroupName,
+				"must not contain a comma"))
+			continue
+		}
 		if _, ok := groupNames[module.GroupName]; ok {
 			fieldErrs = append(fieldErrs, field.Duplicate(fldPath.Index(i).Child("groupname"), module.GroupName))
 			continue
@@ -1,4 +1,4 @@
-// Copyright (c) 2019 VMware, Inc. All Rights Reserved.
+// Copyright (c) 2019-2023 VMware, Inc. All Rights Reserved.
 // SPDX-License-Identifier: Apache-2.0
 
 package validation_test
@@ -63,6 +63,8 @@ func unitTestsValidateCreate() {
 		noMemoryLimit        bool
 		invalidCPURequest    bool
 		invalidMemoryRequest bool
+		commaInGroupName     bool
+		affinityGroup        bool
 	}
 
 	validateCreate := func(args createArgs, expectedAllowed bool, expectedReason string, expectedErr error) {
@@ -82,6 +84,16 @@ func unitTestsValidateCreate() {
 			ctx.vmRP.Spec.ResourcePool.Reservations.Memory = resource.MustParse("4Gi")
 			ctx.vmRP.Spec.ResourcePool.Limits.Memory = resource.MustParse("1Gi")
 		}
+		if args.commaInGroupName {
+			ctx.vmRP.Spec.ClusterModules = []vmopv1.ClusterModuleSpec{{GroupName: "group-a,group-b"}}
+		}
+		if args.affinityGroup {
+			ctx.vmRP.Spec.ClusterModules = append(ctx.vmRP.Spec.ClusterModules, vmopv1.ClusterModuleSpec{
+				GroupName:   "affinity-group",
+				Type:        vmopv1.ClusterModuleTypeAffinity,
+				Enforcement: vmopv1.ClusterModuleEnforcementRequired,
+			})
+		}
 
 		ctx.WebhookRequestContext.Obj, err = builder.ToUnstructured(ctx.vmRP)
 		Expect(err).ToNot(HaveOccurred())
@@ -113,6 +125,10 @@ func unitTestsValidateCreate() {
 			field.Invalid(reservationsPath.Child("cpu"), "2Gi", detailMsg).Error(), nil),
 		Entry("should deny invalid memory reservation", createArgs{invalidMemoryRequest: true}, false,
 			field.Invalid(reservationsPath.Child("memory"), "4Gi", detailMsg).Error(), nil),
+		Entry("should allow affinity group", createArgs{affinityGroup: true}, true, nil, nil),
+		Entry("should deny group name with a comma", createArgs{commaInGroupName: true}, false,
+			field.Invalid(field.NewPath("spec", "clustermodules").Index(0).Child("groupname"), "group-a,group-b",
+				"must not contain a comma").Error(), nil),
 	)
 }
 
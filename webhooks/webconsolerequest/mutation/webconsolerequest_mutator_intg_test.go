@@ -0,0 +1,68 @@
+// Copyright (c) 2023 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package mutation_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	vmopv1 "github.com/vmware-tanzu/vm-operator/api/v1alpha1"
+
+	"github.com/vmware-tanzu/vm-operator/controllers/webconsolerequest"
+	"github.com/vmware-tanzu/vm-operator/test/builder"
+)
+
+func intgTests() {
+	Describe("Invoking Mutation", intgTestsMutating)
+}
+
+type intgMutatingWebhookContext struct {
+	builder.IntegrationTestContext
+	wcr *vmopv1.WebConsoleRequest
+}
+
+func newIntgMutatingWebhookContext() *intgMutatingWebhookContext {
+	ctx := &intgMutatingWebhookContext{
+		IntegrationTestContext: *suite.NewIntegrationTestContext(),
+	}
+
+	_, publicKeyPem := builder.WebConsoleRequestKeyPair()
+	ctx.wcr = builder.DummyWebConsoleRequest(ctx.Namespace, "some-name", "some-vm-name", publicKeyPem)
+
+	return ctx
+}
+
+func intgTestsMutating() {
+	var (
+		ctx *intgMutatingWebhookContext
+		wcr *vmopv1.WebConsoleRequest
+	)
+
+	BeforeEach(func() {
+		ctx = newIntgMutatingWebhookContext()
+		wcr = ctx.wcr.DeepCopy()
+	})
+	AfterEach(func() {
+		ctx = nil
+	})
+
+	Describe("mutate", func() {
+		Context("requester annotation", func() {
+			BeforeEach(func() {
+				wcr.Annotations = map[string]string{webconsolerequest.RequesterAnnotationKey: "someone-else"}
+			})
+
+			It("should be set to the user that created the WebConsoleRequest", func() {
+				Expect(ctx.Client.Create(ctx, wcr)).To(Succeed())
+
+				modified := &vmopv1.WebConsoleRequest{}
+				Expect(ctx.Client.Get(ctx, client.ObjectKeyFromObject(wcr), modified)).To(Succeed())
+				Expect(modified.Annotations).To(HaveKey(webconsolerequest.RequesterAnnotationKey))
+				Expect(modified.Annotations[webconsolerequest.RequesterAnnotationKey]).ToNot(Equal("someone-else"))
+				Expect(modified.Annotations[webconsolerequest.RequesterAnnotationKey]).ToNot(BeEmpty())
+			})
+		})
+	})
+}
@@ -0,0 +1,87 @@
+// Copyright (c) 2023 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package mutation_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	admissionv1 "k8s.io/api/admission/v1"
+
+	vmopv1 "github.com/vmware-tanzu/vm-operator/api/v1alpha1"
+
+	"github.com/vmware-tanzu/vm-operator/controllers/webconsolerequest"
+	"github.com/vmware-tanzu/vm-operator/test/builder"
+	"github.com/vmware-tanzu/vm-operator/webhooks/webconsolerequest/mutation"
+)
+
+func uniTests() {
+	Describe("Invoking Mutate", unitTestsMutating)
+}
+
+type unitMutationWebhookContext struct {
+	builder.UnitTestContextForMutatingWebhook
+	wcr *vmopv1.WebConsoleRequest
+}
+
+func newUnitTestContextForMutatingWebhook() *unitMutationWebhookContext {
+	_, publicKeyPem := builder.WebConsoleRequestKeyPair()
+	wcr := builder.DummyWebConsoleRequest("some-namespace", "some-name", "some-vm-name", publicKeyPem)
+	obj, err := builder.ToUnstructured(wcr)
+	Expect(err).ToNot(HaveOccurred())
+
+	return &unitMutationWebhookContext{
+		UnitTestContextForMutatingWebhook: *suite.NewUnitTestContextForMutatingWebhook(obj),
+		wcr:                               wcr,
+	}
+}
+
+func unitTestsMutating() {
+	var (
+		ctx *unitMutationWebhookContext
+	)
+
+	BeforeEach(func() {
+		ctx = newUnitTestContextForMutatingWebhook()
+		ctx.UserInfo.Username = "some-user"
+	})
+
+	AfterEach(func() {
+		ctx = nil
+	})
+
+	Describe("Mutate", func() {
+		It("should set the requester annotation on create", func() {
+			ctx.Op = admissionv1.Create
+			response := ctx.Mutate(&ctx.WebhookRequestContext)
+			Expect(response.Allowed).To(BeTrue())
+			Expect(response.Patches).To(HaveLen(1))
+		})
+
+		It("should not mutate on update", func() {
+			ctx.Op = admissionv1.Update
+			response := ctx.Mutate(&ctx.WebhookRequestContext)
+			Expect(response.Allowed).To(BeTrue())
+			Expect(response.Patches).To(BeEmpty())
+		})
+	})
+
+	Describe("SetRequester", func() {
+		It("should set the requester annotation to the user", func() {
+			Expect(mutation.SetRequester(&ctx.WebhookRequestContext, ctx.wcr)).To(BeTrue())
+			Expect(ctx.wcr.Annotations).To(HaveKeyWithValue(webconsolerequest.RequesterAnnotationKey, "some-user"))
+		})
+
+		It("should replace a requester annotation set by the user", func() {
+			ctx.wcr.Annotations = map[string]string{webconsolerequest.RequesterAnnotationKey: "someone-else"}
+			Expect(mutation.SetRequester(&ctx.WebhookRequestContext, ctx.wcr)).To(BeTrue())
+			Expect(ctx.wcr.Annotations).To(HaveKeyWithValue(webconsolerequest.RequesterAnnotationKey, "some-user"))
+		})
+
+		It("should not change a requester annotation that is already the user", func() {
+			ctx.wcr.Annotations = map[string]string{webconsolerequest.RequesterAnnotationKey: "some-user"}
+			Expect(mutation.SetRequester(&ctx.WebhookRequestContext, ctx.wcr)).To(BeFalse())
+		})
+	})
+}
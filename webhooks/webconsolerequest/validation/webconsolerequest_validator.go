@@ -1,4 +1,4 @@
-// Copyright (c) 2022 VMware, Inc. All Rights Reserved.
+// Copyright (c) 2022-2023 VMware, Inc. All Rights Reserved.
 // SPDX-License-Identifier: Apache-2.0
 
 package validation
@@ -8,6 +8,7 @@ import (
 	"encoding/pem"
 	"net/http"
 	"reflect"
+	"time"
 
 	"github.com/pkg/errors"
 	"k8s.io/apimachinery/pkg/api/validation"
@@ -24,11 +25,14 @@ import (
 	"github.com/vmware-tanzu/vm-operator/controllers/webconsolerequest"
 	"github.com/vmware-tanzu/vm-operator/pkg/builder"
 	"github.com/vmware-tanzu/vm-operator/pkg/context"
+	"github.com/vmware-tanzu/vm-operator/pkg/lib"
 	"github.com/vmware-tanzu/vm-operator/webhooks/common"
 )
 
 const (
 	webHookName = "default"
+
+	maxLifetimePassed = "the maximum lifetime of the WebConsoleRequest has passed"
 )
 
 // +kubebuilder:webhook:verbs=create;update,path=/default-validate-vmoperator-vmware-com-v1alpha1-webconsolerequest,mutating=false,failurePolicy=fail,groups=vmoperator.vmware.com,resources=webconsolerequests,versions=v1alpha1,name=default.validating.webconsolerequest.vmoperator.vmware.com,sideEffects=None,admissionReviewVersions=v1;v1beta1
@@ -95,8 +99,10 @@ func (v validator) ValidateUpdate(ctx *context.WebhookRequestContext) admission.
 	}
 
 	var fieldErrs field.ErrorList
+	fieldErrs = append(fieldErrs, v.validateDuration(field.NewPath("spec", "duration"), wcr)...)
 	fieldErrs = append(fieldErrs, v.validateImmutableFields(wcr, oldwcr)...)
 	fieldErrs = append(fieldErrs, v.validateUUIDLabel(wcr, oldwcr)...)
+	fieldErrs = append(fieldErrs, v.validateAnnotations(ctx, wcr, oldwcr)...)
 
 	validationErrs := make([]string, 0, len(fieldErrs))
 	for _, fieldErr := range fieldErrs {
@@ -116,10 +122,22 @@ func (v validator) validateSpec(ctx *context.WebhookRequestContext, wcr *vmopv1.
 
 	fieldErrs = append(fieldErrs, v.validateVirtualMachineName(specPath.Child("virtualMachineName"), wcr)...)
 	fieldErrs = append(fieldErrs, v.validatePublicKey(specPath.Child("publicKey"), wcr.Spec.PublicKey)...)
+	fieldErrs = append(fieldErrs, v.validateDuration(specPath.Child("duration"), wcr)...)
+	fieldErrs = append(fieldErrs, validation.ValidateNonnegativeField(int64(wcr.Spec.RenewalCount), specPath.Child("renewalCount"))...)
 
 	return fieldErrs
 }
 
+func (v validator) validateDuration(path *field.Path, wcr *vmopv1.WebConsoleRequest) field.ErrorList {
+	var allErrs field.ErrorList
+
+	if wcr.Spec.Duration != nil && wcr.Spec.Duration.Duration <= 0 {
+		allErrs = append(allErrs, field.Invalid(path, wcr.Spec.Duration.Duration.String(), "must be greater than zero"))
+	}
+
+	return allErrs
+}
+
 func (v validator) validateVirtualMachineName(path *field.Path, wcr *vmopv1.WebConsoleRequest) field.ErrorList {
 	var allErrs field.ErrorList
 
@@ -162,6 +180,13 @@ func (v validator) validateImmutableFields(wcr, oldwcr *vmopv1.WebConsoleRequest
 	allErrs = append(allErrs, validation.ValidateImmutableField(wcr.Spec.VirtualMachineName, oldwcr.Spec.VirtualMachineName, specPath.Child("virtualMachineName"))...)
 	allErrs = append(allErrs, validation.ValidateImmutableField(wcr.Spec.PublicKey, oldwcr.Spec.PublicKey, specPath.Child("publicKey"))...)
 
+	if wcr.Spec.RenewalCount < oldwcr.Spec.RenewalCount {
+		allErrs = append(allErrs, field.Invalid(specPath.Child("renewalCount"), wcr.Spec.RenewalCount, "must not be decreased"))
+	} else if wcr.Spec.RenewalCount > oldwcr.Spec.RenewalCount && !oldwcr.CreationTimestamp.IsZero() &&
+		!time.Now().Before(oldwcr.CreationTimestamp.Add(lib.GetWebConsoleMaxLifetime())) {
+		allErrs = append(allErrs, field.Forbidden(specPath.Child("renewalCount"), maxLifetimePassed))
+	}
+
 	return allErrs
 }
 
@@ -188,3 +213,24 @@ func (v validator) validateUUIDLabel(wcr, oldwcr *vmopv1.WebConsoleRequest) fiel
 
 	return allErrs
 }
+
+func (v validator) validateAnnotations(ctx *context.WebhookRequestContext, wcr, oldwcr *vmopv1.WebConsoleRequest) field.ErrorList {
+	var allErrs field.ErrorList
+	annotationsPath := field.NewPath("metadata", "annotations")
+
+	// The requester is set by the mutation webhook when the WebConsoleRequest is created, and is audited.
+	if oldVal, ok := oldwcr.Annotations[webconsolerequest.RequesterAnnotationKey]; ok {
+		allErrs = append(allErrs, validation.ValidateImmutableField(wcr.Annotations[webconsolerequest.RequesterAnnotationKey],
+			oldVal, annotationsPath.Key(webconsolerequest.RequesterAnnotationKey))...)
+	}
+
+	// Only the web-console validation server marks a single-use ticket as used, so a used ticket cannot be made
+	// usable again.
+	oldUsed, oldOK := oldwcr.Annotations[webconsolerequest.UsedAnnotationKey]
+	newUsed, newOK := wcr.Annotations[webconsolerequest.UsedAnnotationKey]
+	if (oldOK != newOK || oldUsed != newUsed) && !ctx.IsPrivilegedAccount {
+		allErrs = append(allErrs, field.Forbidden(annotationsPath.Key(webconsolerequest.UsedAnnotationKey), "modifying this annotation is not allowed"))
+	}
+
+	return allErrs
+}
@@ -1,10 +1,11 @@
-// Copyright (c) 2022 VMware, Inc. All Rights Reserved.
+// Copyright (c) 2022-2023 VMware, Inc. All Rights Reserved.
 // SPDX-License-Identifier: Apache-2.0
 
 package validation_test
 
 import (
 	"crypto/rsa"
+	"time"
 
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/ginkgo/extensions/table"
@@ -17,6 +18,7 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
 
 	"github.com/vmware-tanzu/vm-operator/controllers/webconsolerequest"
+	"github.com/vmware-tanzu/vm-operator/pkg/lib"
 	"github.com/vmware-tanzu/vm-operator/test/builder"
 )
 
@@ -40,6 +42,9 @@ func newUnitTestContextForValidatingWebhook(isUpdate bool) *unitValidatingWebhoo
 	wcr.Labels = map[string]string{
 		webconsolerequest.UUIDLabelKey: "some-uuid",
 	}
+	wcr.Annotations = map[string]string{
+		webconsolerequest.RequesterAnnotationKey: "some-user",
+	}
 	obj, err := builder.ToUnstructured(wcr)
 	Expect(err).ToNot(HaveOccurred())
 
@@ -69,6 +74,8 @@ func unitTestsValidateCreate() {
 		emptyVirtualMachineName bool
 		emptyPublicKey          bool
 		invalidPublicKey        bool
+		invalidDuration         bool
+		invalidRenewalCount     bool
 	}
 
 	validateCreate := func(args createArgs, expectedAllowed bool, expectedReason string, expectedErr error) {
@@ -83,6 +90,12 @@ func unitTestsValidateCreate() {
 		if args.invalidPublicKey {
 			ctx.wcr.Spec.PublicKey = "invalid-public-key"
 		}
+		if args.invalidDuration {
+			ctx.wcr.Spec.Duration = &metav1.Duration{}
+		}
+		if args.invalidRenewalCount {
+			ctx.wcr.Spec.RenewalCount = -1
+		}
 
 		ctx.WebhookRequestContext.Obj, err = builder.ToUnstructured(ctx.wcr)
 		Expect(err).ToNot(HaveOccurred())
@@ -109,6 +122,8 @@ func unitTestsValidateCreate() {
 		Entry("should deny empty virtualmachinename", createArgs{emptyVirtualMachineName: true}, false, "spec.virtualMachineName: Required value", nil),
 		Entry("should deny empty publickey", createArgs{emptyPublicKey: true}, false, "spec.publicKey: Required value", nil),
 		Entry("should deny invalid publickey", createArgs{invalidPublicKey: true}, false, "spec.publicKey: Invalid value: \"\": invalid public key format", nil),
+		Entry("should deny zero duration", createArgs{invalidDuration: true}, false, "spec.duration: Invalid value: \"0s\": must be greater than zero", nil),
+		Entry("should deny negative renewal count", createArgs{invalidRenewalCount: true}, false, "spec.renewalCount: Invalid value: -1: must be greater than or equal to 0", nil),
 	)
 }
 
@@ -122,6 +137,14 @@ func unitTestsValidateUpdate() {
 		updateVirtualMachineName bool
 		updatePublicKey          bool
 		updateUUIDLabel          bool
+		updateRequester          bool
+		addUsed                  bool
+		removeUsed               bool
+		isPrivilegedAccount      bool
+		incrementRenewalCount    bool
+		decrementRenewalCount    bool
+		createdAgo               time.Duration
+		duration                 time.Duration
 	}
 
 	validateUpdate := func(args updateArgs, expectedAllowed bool, expectedReason string, expectedErr error) {
@@ -139,6 +162,42 @@ func unitTestsValidateUpdate() {
 			ctx.wcr.Labels[webconsolerequest.UUIDLabelKey] = "new-uuid"
 		}
 
+		if args.updateRequester {
+			ctx.wcr.Annotations[webconsolerequest.RequesterAnnotationKey] = "new-requester"
+		}
+
+		if args.addUsed {
+			ctx.wcr.Annotations[webconsolerequest.UsedAnnotationKey] = "0"
+		}
+
+		if args.removeUsed {
+			ctx.oldWcr.Annotations[webconsolerequest.UsedAnnotationKey] = "0"
+			ctx.WebhookRequestContext.OldObj, err = builder.ToUnstructured(ctx.oldWcr)
+			Expect(err).ToNot(HaveOccurred())
+		}
+
+		if args.incrementRenewalCount {
+			ctx.wcr.Spec.RenewalCount = 1
+		}
+
+		if args.decrementRenewalCount {
+			ctx.oldWcr.Spec.RenewalCount = 1
+			ctx.WebhookRequestContext.OldObj, err = builder.ToUnstructured(ctx.oldWcr)
+			Expect(err).ToNot(HaveOccurred())
+		}
+
+		if args.createdAgo != 0 {
+			ctx.oldWcr.CreationTimestamp = metav1.NewTime(time.Now().Add(-args.createdAgo))
+			ctx.WebhookRequestContext.OldObj, err = builder.ToUnstructured(ctx.oldWcr)
+			Expect(err).ToNot(HaveOccurred())
+		}
+
+		if args.duration != 0 {
+			ctx.wcr.Spec.Duration = &metav1.Duration{Duration: args.duration}
+		}
+
+		ctx.IsPrivilegedAccount = args.isPrivilegedAccount
+
 		ctx.WebhookRequestContext.Obj, err = builder.ToUnstructured((ctx.wcr))
 		Expect(err).ToNot(HaveOccurred())
 
@@ -164,6 +223,16 @@ func unitTestsValidateUpdate() {
 		Entry("should deny VirtualmachineName change", updateArgs{updateVirtualMachineName: true}, false, "spec.virtualMachineName: Invalid value: \"new-vm-name\": field is immutable", nil),
 		Entry("should deny PublicKey change", updateArgs{updatePublicKey: true}, false, "spec.publicKey: Invalid value: \"new-public-key\": field is immutable", nil),
 		Entry("should deny UUID label change", updateArgs{updateUUIDLabel: true}, false, "metadata.labels[vmoperator.vmware.com/webconsolerequest-uuid]: Invalid value: \"new-uuid\": field is immutable", nil),
+		Entry("should deny requester annotation change", updateArgs{updateRequester: true}, false, "metadata.annotations[vmoperator.vmware.com/webconsolerequest-requester]: Invalid value: \"new-requester\": field is immutable", nil),
+		Entry("should deny used annotation added by user", updateArgs{addUsed: true}, false, "metadata.annotations[vmoperator.vmware.com/webconsolerequest-used]: Forbidden: modifying this annotation is not allowed", nil),
+		Entry("should allow used annotation added by privileged account", updateArgs{addUsed: true, isPrivilegedAccount: true}, true, nil, nil),
+		Entry("should deny used annotation removal by user", updateArgs{removeUsed: true}, false, "metadata.annotations[vmoperator.vmware.com/webconsolerequest-used]: Forbidden: modifying this annotation is not allowed", nil),
+		Entry("should allow renewal", updateArgs{incrementRenewalCount: true}, true, nil, nil),
+		Entry("should allow renewal within the maximum lifetime", updateArgs{incrementRenewalCount: true, createdAgo: lib.DefaultWebConsoleMaxLifetime / 2}, true, nil, nil),
+		Entry("should deny renewal after the maximum lifetime", updateArgs{incrementRenewalCount: true, createdAgo: lib.DefaultWebConsoleMaxLifetime + time.Minute}, false, "spec.renewalCount: Forbidden: the maximum lifetime of the WebConsoleRequest has passed", nil),
+		Entry("should deny renewal count decrease", updateArgs{decrementRenewalCount: true}, false, "spec.renewalCount: Invalid value: 0: must not be decreased", nil),
+		Entry("should allow duration change", updateArgs{duration: 5 * time.Minute}, true, nil, nil),
+		Entry("should deny non-positive duration", updateArgs{duration: -time.Minute}, false, "spec.duration: Invalid value: \"-1m0s\": must be greater than zero", nil),
 	)
 
 	When("the update is performed while object deletion", func() {
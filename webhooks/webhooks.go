@@ -1,4 +1,4 @@
-// Copyright (c) 2019-2022 VMware, Inc. All Rights Reserved.
+// Copyright (c) 2019-2023 VMware, Inc. All Rights Reserved.
 // SPDX-License-Identifier: Apache-2.0
 
 package webhooks
@@ -9,9 +9,13 @@ import (
 
 	"github.com/vmware-tanzu/vm-operator/pkg/context"
 	"github.com/vmware-tanzu/vm-operator/webhooks/persistentvolumeclaim"
+	"github.com/vmware-tanzu/vm-operator/webhooks/serialconsolerequest"
 	"github.com/vmware-tanzu/vm-operator/webhooks/virtualmachine"
 	"github.com/vmware-tanzu/vm-operator/webhooks/virtualmachineclass"
+	"github.com/vmware-tanzu/vm-operator/webhooks/virtualmachineimageimportrequest"
+	"github.com/vmware-tanzu/vm-operator/webhooks/virtualmachinemigrationrequest"
 	"github.com/vmware-tanzu/vm-operator/webhooks/virtualmachinepublishrequest"
+	"github.com/vmware-tanzu/vm-operator/webhooks/virtualmachinepublishschedule"
 	"github.com/vmware-tanzu/vm-operator/webhooks/virtualmachineservice"
 	"github.com/vmware-tanzu/vm-operator/webhooks/virtualmachinesetresourcepolicy"
 	"github.com/vmware-tanzu/vm-operator/webhooks/webconsolerequest"
@@ -28,9 +32,18 @@ func AddToManager(ctx *context.ControllerManagerContext, mgr ctrlmgr.Manager) er
 	if err := virtualmachineclass.AddToManager(ctx, mgr); err != nil {
 		return errors.Wrap(err, "failed to initialize VirtualMachineClass webhooks")
 	}
+	if err := virtualmachineimageimportrequest.AddToManager(ctx, mgr); err != nil {
+		return errors.Wrap(err, "failed to initialize VirtualMachineImageImportRequest webhooks")
+	}
+	if err := virtualmachinemigrationrequest.AddToManager(ctx, mgr); err != nil {
+		return errors.Wrap(err, "failed to initialize VirtualMachineMigrationRequest webhooks")
+	}
 	if err := virtualmachinepublishrequest.AddToManager(ctx, mgr); err != nil {
 		return errors.Wrap(err, "failed to initialize VirtualMachinePublishRequest webhooks")
 	}
+	if err := virtualmachinepublishschedule.AddToManager(ctx, mgr); err != nil {
+		return errors.Wrap(err, "failed to initialize VirtualMachinePublishSchedule webhooks")
+	}
 	if err := virtualmachineservice.AddToManager(ctx, mgr); err != nil {
 		return errors.Wrap(err, "failed to initialize VirtualMachineService webhooks")
 	}
@@ -40,5 +53,8 @@ func AddToManager(ctx *context.ControllerManagerContext, mgr ctrlmgr.Manager) er
 	if err := webconsolerequest.AddToManager(ctx, mgr); err != nil {
 		return errors.Wrap(err, "failed to initialize WebConsoleRequest webhooks")
 	}
+	if err := serialconsolerequest.AddToManager(ctx, mgr); err != nil {
+		return errors.Wrap(err, "failed to initialize SerialConsoleRequest webhooks")
+	}
 	return nil
 }